@@ -46,6 +46,10 @@ func (a *loopTestAdapter) Stream(ctx context.Context, req llm.Request) (<-chan l
 	return nil, fmt.Errorf("streaming not implemented in test adapter")
 }
 
+func (a *loopTestAdapter) Capabilities() llm.AdapterCapabilities {
+	return llm.AdapterCapabilities{Tools: true, Thinking: true, ReasoningEffort: true, Streaming: true, Images: true, JSONMode: true}
+}
+
 func (a *loopTestAdapter) Close() error { return nil }
 
 func (a *loopTestAdapter) getCalls() []llm.Request {
@@ -45,6 +45,10 @@ func (a *subagentTestAdapter) Stream(ctx context.Context, req llm.Request) (<-ch
 	return nil, fmt.Errorf("streaming not implemented in subagent test adapter")
 }
 
+func (a *subagentTestAdapter) Capabilities() llm.AdapterCapabilities {
+	return llm.AdapterCapabilities{Tools: true, Thinking: true, ReasoningEffort: true, Streaming: true, Images: true, JSONMode: true}
+}
+
 func (a *subagentTestAdapter) Close() error { return nil }
 
 // subagentTestEnv implements ExecutionEnvironment for subagent testing.
@@ -0,0 +1,118 @@
+// ABOUTME: Validates freeform human gate answers against a node's answer_pattern regex.
+// ABOUTME: Hook wraps the wait.human handler to re-ask, with the reason, until the answer matches.
+package answerpattern
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// Attr is the node attribute holding the regular expression a freeform
+// answer must match, e.g. answer_pattern="^v\\d+\\.\\d+\\.\\d+$".
+const Attr = "answer_pattern"
+
+// MaxAttempts bounds how many answers a gate asks for before failing the
+// node, so an interviewer that always gives the same answer (such as
+// auto-approve) cannot loop forever.
+const MaxAttempts = 5
+
+// humanHandler is the name of the handler that runs human gates.
+const humanHandler = "wait.human"
+
+// Check reports whether answer matches pattern. The answer is compared with
+// surrounding whitespace trimmed; an empty pattern accepts anything.
+func Check(pattern, answer string) error {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %w", Attr, pattern, err)
+	}
+	if !re.MatchString(strings.TrimSpace(answer)) {
+		return &MismatchError{Pattern: pattern, Answer: answer}
+	}
+	return nil
+}
+
+// MismatchError is returned by Check for an answer that does not match.
+type MismatchError struct {
+	Pattern string
+	Answer  string
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("answer %q does not match the expected format %s", e.Answer, e.Pattern)
+}
+
+// Hook wraps the wait.human handler when any node in g declares an
+// answer_pattern. Freeform answers that do not match are asked for again
+// with the reason prepended to the question.
+func Hook(g *pipeline.Graph) func(*pipeline.HandlerRegistry) {
+	return func(registry *pipeline.HandlerRegistry) {
+		for _, n := range g.Nodes {
+			if n.Handler != humanHandler || strings.TrimSpace(n.Attrs[Attr]) == "" {
+				continue
+			}
+			if inner := registry.Get(humanHandler); inner != nil {
+				registry.Register(&checkedHandler{inner: inner})
+			}
+			return
+		}
+	}
+}
+
+// checkedHandler re-runs the wrapped human gate until its freeform answer
+// matches the node's answer_pattern. Choice gates and nodes without a
+// pattern pass through.
+type checkedHandler struct {
+	inner pipeline.Handler
+}
+
+func (h *checkedHandler) Name() string { return h.inner.Name() }
+
+func (h *checkedHandler) Execute(ctx context.Context, node *pipeline.Node, pctx *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	pattern := node.Attrs[Attr]
+	if strings.TrimSpace(pattern) == "" || node.Attrs["mode"] != "freeform" {
+		return h.inner.Execute(ctx, node, pctx)
+	}
+	if _, err := regexp.Compile(strings.TrimSpace(pattern)); err != nil {
+		return pipeline.Outcome{}, fmt.Errorf("node %q: invalid %s: %w", node.ID, Attr, err)
+	}
+
+	asked := node
+	for attempt := 1; ; attempt++ {
+		out, err := h.inner.Execute(ctx, asked, pctx)
+		if err != nil {
+			return out, err
+		}
+		mismatch := Check(pattern, out.ContextUpdates[pipeline.ContextKeyHumanResponse])
+		if mismatch == nil {
+			return out, nil
+		}
+		if attempt >= MaxAttempts {
+			return pipeline.Outcome{}, fmt.Errorf("node %q: %w after %d attempts", node.ID, mismatch, attempt)
+		}
+		if err := ctx.Err(); err != nil {
+			return pipeline.Outcome{}, err
+		}
+		asked = reprompt(node, mismatch)
+	}
+}
+
+// reprompt returns a copy of node whose question starts with why the last
+// answer was rejected.
+func reprompt(node *pipeline.Node, reason error) *pipeline.Node {
+	label := node.Label
+	if label == "" {
+		label = fmt.Sprintf("Human gate: %s", node.ID)
+	}
+	retry := *node
+	retry.Label = fmt.Sprintf("Invalid answer: %v. Please try again.\n\n%s", reason, label)
+	return &retry
+}
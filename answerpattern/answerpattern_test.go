@@ -0,0 +1,118 @@
+// ABOUTME: Tests for answer_pattern validation of freeform human gate answers.
+// ABOUTME: Drives the real wait.human handler with a scripted interviewer to check acceptance, re-prompts, and the attempt cap.
+package answerpattern
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/2389-research/tracker/pipeline"
+	"github.com/2389-research/tracker/pipeline/handlers"
+)
+
+// scriptedInterviewer answers freeform questions from a fixed list, repeating
+// the last answer once the list runs out, and records every prompt.
+type scriptedInterviewer struct {
+	answers []string
+	prompts []string
+}
+
+func (s *scriptedInterviewer) Ask(prompt string, choices []string, _ string) (string, error) {
+	s.prompts = append(s.prompts, prompt)
+	return choices[0], nil
+}
+
+func (s *scriptedInterviewer) AskFreeform(prompt string) (string, error) {
+	s.prompts = append(s.prompts, prompt)
+	answer := s.answers[0]
+	if len(s.answers) > 1 {
+		s.answers = s.answers[1:]
+	}
+	return answer, nil
+}
+
+const versionGate = `digraph release {
+	start [shape=Mdiamond]
+	version [shape=hexagon, mode="freeform", label="Which version?", answer_pattern="^v\\d+\\.\\d+\\.\\d+$"]
+	done [shape=Msquare]
+	start -> version -> done
+}`
+
+// runGate executes the version gate with the hook installed.
+func runGate(t *testing.T, iv *scriptedInterviewer) (pipeline.Outcome, error) {
+	t.Helper()
+	g, err := pipeline.ParseDOT(versionGate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := handlers.NewDefaultRegistry(g, handlers.WithInterviewer(iv, g))
+	Hook(g)(registry)
+	return registry.Execute(context.Background(), g.Nodes["version"], pipeline.NewPipelineContext())
+}
+
+func TestValidAnswerAccepted(t *testing.T) {
+	iv := &scriptedInterviewer{answers: []string{"v1.2.3"}}
+	out, err := runGate(t, iv)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := out.ContextUpdates[pipeline.ContextKeyHumanResponse]; got != "v1.2.3" {
+		t.Errorf("human_response = %q, want v1.2.3", got)
+	}
+	if len(iv.prompts) != 1 {
+		t.Errorf("asked %d times, want once", len(iv.prompts))
+	}
+}
+
+func TestInvalidAnswerReprompted(t *testing.T) {
+	iv := &scriptedInterviewer{answers: []string{"1.2", "v1.2.3"}}
+	out, err := runGate(t, iv)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := out.ContextUpdates[pipeline.ContextKeyHumanResponse]; got != "v1.2.3" {
+		t.Errorf("human_response = %q, want the second, valid answer", got)
+	}
+	if len(iv.prompts) != 2 {
+		t.Fatalf("asked %d times, want twice", len(iv.prompts))
+	}
+	retry := iv.prompts[1]
+	if !strings.HasPrefix(retry, `Invalid answer: answer "1.2" does not match`) || !strings.Contains(retry, "Which version?") {
+		t.Errorf("re-prompt = %q, want the reason followed by the question", retry)
+	}
+}
+
+func TestAttemptsCapped(t *testing.T) {
+	iv := &scriptedInterviewer{answers: []string{"auto-approved"}}
+	_, err := runGate(t, iv)
+	var mismatch *MismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("error = %v, want a MismatchError", err)
+	}
+	if len(iv.prompts) != MaxAttempts {
+		t.Errorf("asked %d times, want %d", len(iv.prompts), MaxAttempts)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		pattern, answer string
+		ok              bool
+	}{
+		{`^v\d+\.\d+\.\d+$`, "v1.0.0", true},
+		{`^v\d+\.\d+\.\d+$`, "  v1.0.0\n", true},
+		{`^v\d+\.\d+\.\d+$`, "1.0.0", false},
+		{`^(yes|no)$`, "maybe", false},
+		{"", "anything", true},
+	}
+	for _, tt := range tests {
+		if err := Check(tt.pattern, tt.answer); (err == nil) != tt.ok {
+			t.Errorf("Check(%q, %q) = %v, want ok=%v", tt.pattern, tt.answer, err, tt.ok)
+		}
+	}
+	if err := Check("(", "x"); err == nil || errors.As(err, new(*MismatchError)) {
+		t.Errorf("invalid pattern: err = %v, want a compile error", err)
+	}
+}
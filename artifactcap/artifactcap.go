@@ -0,0 +1,173 @@
+// ABOUTME: Per-run artifact size cap (-max-artifact-bytes, max_artifact_bytes node attribute).
+// ABOUTME: The run's working directory is measured while nodes write, and a node crossing a cap is stopped with a clear reason.
+package artifactcap
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/2389-research/mammoth/runstate"
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// capHandlers lists the handler names whose writes are measured against the
+// artifact cap.
+var capHandlers = []string{"codergen", "tool"}
+
+// pollInterval is how often a running node's writes are measured, so a node
+// that keeps writing is stopped shortly after crossing a cap instead of only
+// once it finishes.
+var pollInterval = 250 * time.Millisecond
+
+// budget is the run-wide cap on one run's working directory.
+type budget struct {
+	workDir  string
+	limit    int64 // run-wide cap in bytes; 0 means unlimited
+	baseline int64 // size of workDir before the run, when limit is set
+}
+
+// Hook wraps the output-producing handlers so every execution is checked
+// against the run-wide limit (0 for none) and the node's max_artifact_bytes
+// attribute. Writes are measured in workDir, the directory the run's agents
+// and tools write into, which also holds the engine's artifact tree. The
+// run-wide limit covers everything the run adds to workDir from now on.
+func Hook(workDir string, limit int64) func(*pipeline.HandlerRegistry) {
+	b := &budget{workDir: workDir, limit: limit}
+	if limit > 0 {
+		b.baseline = dirSize(workDir)
+	}
+	return func(registry *pipeline.HandlerRegistry) {
+		for _, name := range capHandlers {
+			if inner := registry.Get(name); inner != nil {
+				registry.Register(&capHandler{inner: inner, budget: b})
+			}
+		}
+	}
+}
+
+// capHandler measures the working directory while the wrapped handler runs,
+// cancelling it and failing the node once its writes exceed a cap. Nodes
+// running in parallel share the directory, so each one's measure includes
+// the others' concurrent writes.
+type capHandler struct {
+	inner  pipeline.Handler
+	budget *budget
+}
+
+func (h *capHandler) Name() string { return h.inner.Name() }
+
+func (h *capHandler) Execute(ctx context.Context, node *pipeline.Node, pctx *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	nodeLimit, err := nodeArtifactLimit(node)
+	if err != nil {
+		return pipeline.Outcome{}, err
+	}
+	if h.budget.workDir == "" || (nodeLimit == 0 && h.budget.limit == 0) {
+		return h.inner.Execute(ctx, node, pctx)
+	}
+
+	before := dirSize(h.budget.workDir)
+	exceeded := func() string {
+		size := dirSize(h.budget.workDir)
+		return h.budget.exceeded(node.ID, max(size-before, 0), nodeLimit, max(size-h.budget.baseline, 0))
+	}
+
+	nodeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	tripped := make(chan string, 1)
+	stop := make(chan struct{})
+	watched := make(chan struct{})
+	go func() {
+		defer close(watched)
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if reason := exceeded(); reason != "" {
+					tripped <- reason
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	out, err := h.inner.Execute(nodeCtx, node, pctx)
+	close(stop)
+	<-watched
+
+	reason := exceeded()
+	select {
+	case r := <-tripped:
+		// The node was stopped mid-write; its partial output may be under
+		// the cap, but the write that crossed it is the reason it failed.
+		if reason == "" {
+			reason = r
+		}
+	default:
+		if err != nil {
+			return out, err
+		}
+	}
+	if reason == "" {
+		return out, nil
+	}
+	updates := maps.Clone(out.ContextUpdates)
+	if updates == nil {
+		updates = map[string]string{}
+	}
+	updates[runstate.FailureReasonKey] = reason
+	return pipeline.Outcome{Status: pipeline.OutcomeFail, ContextUpdates: updates}, nil
+}
+
+// exceeded returns the failure reason for a node that wrote written bytes,
+// bringing the run to total, or "" if both caps hold.
+func (b *budget) exceeded(nodeID string, written, nodeLimit, total int64) string {
+	if nodeLimit > 0 && written > nodeLimit {
+		return fmt.Sprintf("node %q wrote %d artifact bytes, exceeding its max_artifact_bytes of %d", nodeID, written, nodeLimit)
+	}
+	if b.limit > 0 && total > b.limit {
+		return fmt.Sprintf("node %q pushed run artifacts to %d bytes, exceeding the cap of %d", nodeID, total, b.limit)
+	}
+	return ""
+}
+
+// nodeArtifactLimit parses the node's max_artifact_bytes attribute. Returns 0
+// when unset.
+func nodeArtifactLimit(node *pipeline.Node) (int64, error) {
+	raw := strings.TrimSpace(node.Attrs["max_artifact_bytes"])
+	if raw == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("node %q: max_artifact_bytes must be a positive integer, got %q", node.ID, raw)
+	}
+	return n, nil
+}
+
+// dirSize returns the total size in bytes of the regular files under dir.
+// Unreadable entries are skipped.
+func dirSize(dir string) int64 {
+	var total int64
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total
+}
@@ -0,0 +1,198 @@
+// ABOUTME: Tests for the per-run artifact size cap on codergen and tool nodes.
+// ABOUTME: Stub handlers write into the run's working directory, as agents do, and the cap must reject oversized or runaway writes.
+package artifactcap
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/2389-research/mammoth/runstate"
+	"github.com/2389-research/tracker/pipeline"
+	"github.com/2389-research/tracker/pipeline/handlers"
+)
+
+const artifactCapDOT = `digraph cap {
+    start [shape=Mdiamond]
+    first [shape=box, prompt="write"]
+    second [shape=box, prompt="write more"]
+    done [shape=Msquare]
+    start -> first -> second -> done
+}`
+
+// writingHandler is a codergen stand-in that writes size bytes into the
+// working directory, in a file named after the node.
+type writingHandler struct {
+	workDir string
+	size    int
+}
+
+func (h *writingHandler) Name() string { return "codergen" }
+
+func (h *writingHandler) Execute(_ context.Context, node *pipeline.Node, _ *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	if err := os.WriteFile(filepath.Join(h.workDir, node.ID+".bin"), make([]byte, h.size), 0o644); err != nil {
+		return pipeline.Outcome{}, err
+	}
+	return pipeline.Outcome{Status: pipeline.OutcomeSuccess}, nil
+}
+
+// runWithArtifactCap runs source in a fresh working directory, which also
+// holds the engine's artifact tree as it does in every runner, with the
+// handler newHandler builds for that directory.
+func runWithArtifactCap(t *testing.T, source string, newHandler func(workDir string) pipeline.Handler, limit int64) *pipeline.EngineResult {
+	t.Helper()
+	g, err := pipeline.ParseDOT(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	workDir := t.TempDir()
+	registry := handlers.NewDefaultRegistry(g)
+	registry.Register(newHandler(workDir))
+	Hook(workDir, limit)(registry)
+	result, err := pipeline.NewEngine(g, registry, pipeline.WithArtifactDir(workDir)).Run(context.Background())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	return result
+}
+
+// writing returns a newHandler for runWithArtifactCap that writes size bytes
+// per node.
+func writing(size int) func(string) pipeline.Handler {
+	return func(workDir string) pipeline.Handler { return &writingHandler{workDir: workDir, size: size} }
+}
+
+// nodeStatus returns the status the trace recorded for nodeID.
+func nodeStatus(result *pipeline.EngineResult, nodeID string) string {
+	for _, entry := range result.Trace.Entries {
+		if entry.NodeID == nodeID {
+			return entry.Status
+		}
+	}
+	return ""
+}
+
+func TestArtifactCapCumulativePerRun(t *testing.T) {
+	// Each node fits on its own, but the second pushes the run over the cap.
+	result := runWithArtifactCap(t, artifactCapDOT, writing(600), 1000)
+	if got := nodeStatus(result, "first"); got != pipeline.OutcomeSuccess {
+		t.Errorf("first status = %q, want success within budget", got)
+	}
+	if got := nodeStatus(result, "second"); got != pipeline.OutcomeFail {
+		t.Errorf("second status = %q, want fail over budget", got)
+	}
+	reason := result.Context[runstate.FailureReasonKey]
+	if !strings.Contains(reason, `node "second"`) || !strings.Contains(reason, "cap of 1000") {
+		t.Errorf("failure reason = %q, want it to name the node and the cap", reason)
+	}
+}
+
+func TestArtifactCapWithinBudget(t *testing.T) {
+	result := runWithArtifactCap(t, artifactCapDOT, writing(100), 1000)
+	if got := nodeStatus(result, "second"); got != pipeline.OutcomeSuccess {
+		t.Errorf("second status = %q, want success", got)
+	}
+	if _, ok := result.Context[runstate.FailureReasonKey]; ok {
+		t.Error("unexpected failure reason within budget")
+	}
+}
+
+func TestArtifactCapNodeAttribute(t *testing.T) {
+	source := strings.Replace(artifactCapDOT, `prompt="write"]`, `prompt="write", max_artifact_bytes="50"]`, 1)
+	result := runWithArtifactCap(t, source, writing(100), 0)
+	if got := nodeStatus(result, "first"); got != pipeline.OutcomeFail {
+		t.Errorf("first status = %q, want fail", got)
+	}
+	if reason := result.Context[runstate.FailureReasonKey]; !strings.Contains(reason, "max_artifact_bytes of 50") {
+		t.Errorf("failure reason = %q, want the per-node cap", reason)
+	}
+}
+
+func TestArtifactCapCountsNestedWorkdirWrites(t *testing.T) {
+	// Agents write anywhere under the working directory, not only into the
+	// engine's per-node artifact directory.
+	source := strings.Replace(artifactCapDOT, `prompt="write"]`, `prompt="write", max_artifact_bytes="50"]`, 1)
+	result := runWithArtifactCap(t, source, func(workDir string) pipeline.Handler {
+		dir := filepath.Join(workDir, "src", "pkg")
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		return &writingHandler{workDir: dir, size: 100}
+	}, 0)
+	if got := nodeStatus(result, "first"); got != pipeline.OutcomeFail {
+		t.Errorf("first status = %q, want fail", got)
+	}
+}
+
+// appendingHandler is a codergen stand-in that keeps appending chunks to a
+// file in the working directory until its context is cancelled or it has
+// written max bytes, recording how much each node wrote.
+type appendingHandler struct {
+	workDir    string
+	chunk, max int
+	written    map[string]int
+}
+
+func (h *appendingHandler) Name() string { return "codergen" }
+
+func (h *appendingHandler) Execute(ctx context.Context, node *pipeline.Node, _ *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	var data []byte
+	defer func() { h.written[node.ID] = len(data) }()
+	for len(data) < h.max {
+		data = append(data, make([]byte, h.chunk)...)
+		if err := os.WriteFile(filepath.Join(h.workDir, node.ID+".bin"), data, 0o644); err != nil {
+			return pipeline.Outcome{}, err
+		}
+		select {
+		case <-ctx.Done():
+			return pipeline.Outcome{}, ctx.Err()
+		case <-time.After(time.Millisecond):
+		}
+	}
+	return pipeline.Outcome{Status: pipeline.OutcomeSuccess}, nil
+}
+
+func TestArtifactCapStopsNodeWhileWriting(t *testing.T) {
+	defer func(d time.Duration) { pollInterval = d }(pollInterval)
+	pollInterval = 5 * time.Millisecond
+
+	source := strings.Replace(artifactCapDOT, `prompt="write"]`, `prompt="write", max_artifact_bytes="1000"]`, 1)
+	h := &appendingHandler{chunk: 100, max: 20_000, written: map[string]int{}}
+	result := runWithArtifactCap(t, source, func(workDir string) pipeline.Handler {
+		h.workDir = workDir
+		return h
+	}, 0)
+	if got := nodeStatus(result, "first"); got != pipeline.OutcomeFail {
+		t.Errorf("first status = %q, want fail", got)
+	}
+	if h.written["first"] >= h.max {
+		t.Errorf("first wrote %d bytes, want it stopped soon after crossing the cap", h.written["first"])
+	}
+	if reason := result.Context[runstate.FailureReasonKey]; !strings.Contains(reason, "max_artifact_bytes of 1000") {
+		t.Errorf("failure reason = %q, want the per-node cap", reason)
+	}
+}
+
+func TestArtifactCapIgnoresFilesFromBeforeTheRun(t *testing.T) {
+	// A working directory that already holds a large checkout does not count
+	// against the run.
+	result := runWithArtifactCap(t, artifactCapDOT, func(workDir string) pipeline.Handler {
+		if err := os.WriteFile(filepath.Join(workDir, "existing.bin"), make([]byte, 5000), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return &writingHandler{workDir: workDir, size: 100}
+	}, 1000)
+	if got := nodeStatus(result, "second"); got != pipeline.OutcomeSuccess {
+		t.Errorf("second status = %q (%s), want success", got, result.Context[runstate.FailureReasonKey])
+	}
+}
+
+func TestNodeArtifactLimitInvalid(t *testing.T) {
+	node := &pipeline.Node{ID: "n", Attrs: map[string]string{"max_artifact_bytes": "lots"}}
+	if _, err := nodeArtifactLimit(node); err == nil {
+		t.Error("expected error for non-numeric max_artifact_bytes")
+	}
+}
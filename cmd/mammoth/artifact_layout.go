@@ -0,0 +1,124 @@
+// ABOUTME: Configurable artifact directory layout for pipeline runs (-artifact-layout).
+// ABOUTME: Expands {date}, {timestamp}, {pipeline}, and {run_id} placeholders beneath the -artifact-dir base.
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/2389-research/mammoth/dot"
+)
+
+// artifactLayoutMeta holds the run metadata available to layout placeholders.
+type artifactLayoutMeta struct {
+	RunID     string
+	Pipeline  string
+	StartedAt time.Time
+}
+
+// timestampedArtifactLayout is the layout -timestamped applies: one
+// subdirectory per run, ordered by start time and kept unique by the run ID
+// so back-to-back runs within the same second do not collide.
+const timestampedArtifactLayout = "{timestamp}-{run_id}"
+
+// layoutPlaceholder matches a {name} placeholder in a layout template.
+var layoutPlaceholder = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// expandArtifactLayout expands a layout template such as
+// "{date}/{pipeline}/{run_id}" into a relative path. Placeholder values are
+// sanitized so they cannot introduce extra path segments. Unknown
+// placeholders and absolute or escaping templates are rejected.
+func expandArtifactLayout(layout string, meta artifactLayoutMeta) (string, error) {
+	values := map[string]string{
+		"date":      meta.StartedAt.Format("2006-01-02"),
+		"timestamp": meta.StartedAt.UTC().Format("20060102T150405Z"),
+		"pipeline":  meta.Pipeline,
+		"run_id":    meta.RunID,
+	}
+
+	var unknown []string
+	expanded := layoutPlaceholder.ReplaceAllStringFunc(layout, func(m string) string {
+		name := m[1 : len(m)-1]
+		v, ok := values[name]
+		if !ok {
+			unknown = append(unknown, m)
+			return m
+		}
+		return sanitizeLayoutSegment(v)
+	})
+	if len(unknown) > 0 {
+		return "", fmt.Errorf("artifact layout %q: unknown placeholder(s) %s (supported: {date}, {timestamp}, {pipeline}, {run_id})", layout, strings.Join(unknown, ", "))
+	}
+
+	rel := filepath.Clean(filepath.FromSlash(expanded))
+	if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("artifact layout %q must stay within the artifact directory", layout)
+	}
+	return rel, nil
+}
+
+// effectiveArtifactLayout returns the layout a run should use. -timestamped
+// selects timestampedArtifactLayout and cannot be combined with an explicit
+// -artifact-layout, which can place {timestamp} itself.
+func effectiveArtifactLayout(layout string, timestamped bool) (string, error) {
+	if !timestamped {
+		return layout, nil
+	}
+	if strings.TrimSpace(layout) != "" {
+		return "", fmt.Errorf("-timestamped cannot be combined with -artifact-layout; use {timestamp} in the layout instead")
+	}
+	return timestampedArtifactLayout, nil
+}
+
+// sanitizeLayoutSegment makes a placeholder value safe to use as a single
+// path segment.
+func sanitizeLayoutSegment(v string) string {
+	v = strings.TrimSpace(v)
+	v = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', ' ':
+			return '_'
+		}
+		return r
+	}, v)
+	if v == "" || v == "." || v == ".." {
+		return "_"
+	}
+	return v
+}
+
+// pipelineLayoutName returns the name used for {pipeline}: the DOT graph
+// name, falling back to the pipeline file's base name without extension.
+func pipelineLayoutName(graph *dot.Graph, pipelineFile string) string {
+	if graph != nil && strings.TrimSpace(graph.Name) != "" {
+		return graph.Name
+	}
+	base := filepath.Base(pipelineFile)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// resolveArtifactDir returns the directory a run should use for its
+// artifacts and working directory. With no layout it returns base unchanged,
+// preserving the default behavior; otherwise it expands the layout beneath
+// base and creates the directory.
+func resolveArtifactDir(base, layout string, meta artifactLayoutMeta) (string, error) {
+	if strings.TrimSpace(layout) == "" {
+		return base, nil
+	}
+	rel, err := expandArtifactLayout(layout, meta)
+	if err != nil {
+		return "", err
+	}
+	if base == "" {
+		base, _ = os.Getwd()
+	}
+	dir := filepath.Join(base, rel)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("create artifact directory: %w", err)
+	}
+	return dir, nil
+}
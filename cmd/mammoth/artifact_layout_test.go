@@ -0,0 +1,184 @@
+// ABOUTME: Tests for the -artifact-layout template expansion and artifact directory resolution.
+// ABOUTME: Verifies placeholder expansion, sanitization, rejection of escaping templates, and run wiring.
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/2389-research/mammoth/dot"
+	"github.com/2389-research/tracker/pipeline"
+)
+
+func TestExpandArtifactLayout(t *testing.T) {
+	meta := artifactLayoutMeta{
+		RunID:     "abc123",
+		Pipeline:  "build app",
+		StartedAt: time.Date(2026, 3, 14, 15, 9, 26, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name    string
+		layout  string
+		want    string
+		wantErr bool
+	}{
+		{name: "date pipeline run", layout: "{date}/{pipeline}/{run_id}", want: filepath.Join("2026-03-14", "build_app", "abc123")},
+		{name: "literal prefix", layout: "runs/{run_id}", want: filepath.Join("runs", "abc123")},
+		{name: "mixed segment", layout: "{pipeline}-{date}", want: "build_app-2026-03-14"},
+		{name: "timestamped", layout: timestampedArtifactLayout, want: "20260314T150926Z-abc123"},
+		{name: "unknown placeholder", layout: "{user}/{run_id}", wantErr: true},
+		{name: "absolute", layout: "/tmp/{run_id}", wantErr: true},
+		{name: "escapes base", layout: "../{run_id}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandArtifactLayout(tt.layout, meta)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("expandArtifactLayout(%q) = %q, want %q", tt.layout, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandArtifactLayoutSanitizesValues(t *testing.T) {
+	got, err := expandArtifactLayout("{pipeline}", artifactLayoutMeta{Pipeline: "../../etc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != ".._.._etc" {
+		t.Errorf("got %q, want path separators replaced", got)
+	}
+}
+
+func TestResolveArtifactDirDefaultUnchanged(t *testing.T) {
+	base := t.TempDir()
+	got, err := resolveArtifactDir(base, "", artifactLayoutMeta{RunID: "r1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != base {
+		t.Errorf("resolveArtifactDir with no layout = %q, want %q", got, base)
+	}
+}
+
+func TestEffectiveArtifactLayout(t *testing.T) {
+	if got, err := effectiveArtifactLayout("{run_id}", false); err != nil || got != "{run_id}" {
+		t.Errorf("without -timestamped: got %q, %v", got, err)
+	}
+	if got, err := effectiveArtifactLayout("", true); err != nil || got != timestampedArtifactLayout {
+		t.Errorf("with -timestamped: got %q, %v", got, err)
+	}
+	if _, err := effectiveArtifactLayout("{run_id}", true); err == nil {
+		t.Error("expected -timestamped with -artifact-layout to be rejected")
+	}
+}
+
+func TestTimestampedRunsUseDistinctDirs(t *testing.T) {
+	dotFile := writeTempDOT(t, `digraph stamp {
+    start [shape=Mdiamond]
+    mark [shape=parallelogram, tool_command="pwd > workdir.txt"]
+    done [shape=Msquare]
+    start -> mark
+    mark -> done
+}`)
+	base := t.TempDir()
+	cfg := config{
+		pipelineFile: dotFile,
+		retryPolicy:  "none",
+		artifactDir:  base,
+		dataDir:      t.TempDir(),
+		timestamped:  true,
+	}
+	for i := 0; i < 2; i++ {
+		if code := run(cfg); code != 0 {
+			t.Fatalf("run %d: exit code %d", i+1, code)
+		}
+	}
+
+	entries, err := os.ReadDir(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Name() == entries[1].Name() {
+		t.Fatalf("expected two distinct run subdirectories under %s, got %v", base, entries)
+	}
+	for _, e := range entries {
+		dir := filepath.Join(base, e.Name())
+		data, err := os.ReadFile(filepath.Join(dir, "workdir.txt"))
+		if err != nil {
+			t.Fatalf("expected the tool node to run inside %s: %v", dir, err)
+		}
+		got, _ := filepath.EvalSymlinks(strings.TrimSpace(string(data)))
+		want, _ := filepath.EvalSymlinks(dir)
+		if got != want {
+			t.Errorf("working directory = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestPipelineLayoutName(t *testing.T) {
+	if got := pipelineLayoutName(&dot.Graph{Name: "deploy"}, "x/other.dot"); got != "deploy" {
+		t.Errorf("graph name: got %q", got)
+	}
+	if got := pipelineLayoutName(&dot.Graph{}, "x/other.dot"); got != "other" {
+		t.Errorf("file fallback: got %q", got)
+	}
+}
+
+func TestArtifactLayoutRunUsesTemplatedDir(t *testing.T) {
+	base := t.TempDir()
+	started := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	dir, err := resolveArtifactDir(base, "{date}/{pipeline}/{run_id}", artifactLayoutMeta{
+		RunID:     "run42",
+		Pipeline:  "replay",
+		StartedAt: started,
+	})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	want := filepath.Join(base, "2026-01-02", "replay", "run42")
+	if dir != want {
+		t.Fatalf("artifact dir = %q, want %q", dir, want)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Fatalf("expected templated directory to exist: %v", err)
+	}
+
+	var workDirSeen string
+	capture := func(r *pipeline.HandlerRegistry) {
+		r.Register(&workDirCapturingHandler{seen: &workDirSeen})
+	}
+	engine, _, err := buildPipelineEngine(replayDOT, dir, nil, "", dir, "", nil, nil, capture)
+	if err != nil {
+		t.Fatalf("build engine: %v", err)
+	}
+	if _, err := engine.Run(context.Background()); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if filepath.Dir(workDirSeen) != dir {
+		t.Errorf("node artifact dir = %q, want it under %q", workDirSeen, dir)
+	}
+}
+
+// workDirCapturingHandler is a codergen stand-in that records the run
+// artifact directory the engine hands to handlers.
+type workDirCapturingHandler struct {
+	seen *string
+}
+
+func (h *workDirCapturingHandler) Name() string { return "codergen" }
+
+func (h *workDirCapturingHandler) Execute(_ context.Context, _ *pipeline.Node, pctx *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	*h.seen, _ = pctx.GetInternal(pipeline.InternalKeyArtifactDir)
+	return pipeline.Outcome{Status: pipeline.OutcomeSuccess}, nil
+}
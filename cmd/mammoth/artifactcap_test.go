@@ -0,0 +1,47 @@
+// ABOUTME: Tests that the artifact size cap sees what CLI runs write into their working directory.
+// ABOUTME: A real tool node writes into the workdir and must be failed once it crosses max_artifact_bytes.
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/2389-research/mammoth/artifactcap"
+	"github.com/2389-research/mammoth/runstate"
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// nodeStatus returns the status the trace recorded for nodeID.
+func nodeStatus(result *pipeline.EngineResult, nodeID string) string {
+	for _, entry := range result.Trace.Entries {
+		if entry.NodeID == nodeID {
+			return entry.Status
+		}
+	}
+	return ""
+}
+
+func TestArtifactCapMeasuresToolWritesInWorkdir(t *testing.T) {
+	source := `digraph cap {
+		start [shape=Mdiamond]
+		write [shape=parallelogram, tool_command="head -c 2000 /dev/zero > out.bin", max_artifact_bytes="1000"]
+		done [shape=Msquare]
+		start -> write -> done
+	}`
+	workDir := t.TempDir()
+	engine, _, err := buildPipelineEngine(source, workDir, nil, "", workDir, "", nil, nil, artifactcap.Hook(workDir, 0))
+	if err != nil {
+		t.Fatalf("build engine: %v", err)
+	}
+	result, err := engine.Run(context.Background())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if got := nodeStatus(result, "write"); got != pipeline.OutcomeFail {
+		t.Errorf("write status = %q, want fail", got)
+	}
+	if reason := result.Context[runstate.FailureReasonKey]; !strings.Contains(reason, "max_artifact_bytes of 1000") {
+		t.Errorf("failure reason = %q, want the per-node cap", reason)
+	}
+}
@@ -153,19 +153,11 @@ func buildAuditContext(state *runstate.RunState, events []runstate.RunEvent, gra
 // linearizeGraph walks the graph from start via BFS and returns a
 // human-readable flow string like "start -> build -> verify -> exit".
 func linearizeGraph(g *dot.Graph) string {
-	// Find start node by shape=Mdiamond, node_type=start, or type=start.
-	var startID string
-	for id, n := range g.Nodes {
-		if n.Attrs["shape"] == "Mdiamond" ||
-			n.Attrs["node_type"] == "start" ||
-			n.Attrs["type"] == "start" {
-			startID = id
-			break
-		}
-	}
-	if startID == "" {
+	start := g.FindStartNode()
+	if start == nil {
 		return "(no start node found)"
 	}
+	startID := start.ID
 
 	// Build adjacency for BFS
 	adj := map[string][]string{}
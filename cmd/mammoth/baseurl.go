@@ -0,0 +1,98 @@
+// ABOUTME: Per-provider LLM base URL resolution and tracker adapter construction.
+// ABOUTME: Merges -base-urls, <PROVIDER>_BASE_URL env vars, and the -base-url fallback into one map.
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	trackerllm "github.com/2389-research/tracker/llm"
+	"github.com/2389-research/tracker/llm/anthropic"
+	"github.com/2389-research/tracker/llm/google"
+	"github.com/2389-research/tracker/llm/openai"
+)
+
+// providerBaseURLEnv maps provider names to the environment variable holding
+// that provider's base URL override.
+var providerBaseURLEnv = map[string]string{
+	"anthropic": "ANTHROPIC_BASE_URL",
+	"openai":    "OPENAI_BASE_URL",
+	"gemini":    "GEMINI_BASE_URL",
+}
+
+// resolveBaseURLs returns the base URL for each provider that has one. For
+// each provider the -base-urls entry wins, then its <PROVIDER>_BASE_URL
+// environment variable, then the single -base-url fallback. Providers with
+// none of these are omitted and keep their adapter's default endpoint.
+func resolveBaseURLs(overrides, fallback string) (map[string]string, error) {
+	explicit := map[string]string{}
+	for _, pair := range strings.Split(overrides, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		provider, url, ok := strings.Cut(pair, "=")
+		provider = strings.ToLower(strings.TrimSpace(provider))
+		url = strings.TrimSpace(url)
+		if !ok || provider == "" || url == "" {
+			return nil, fmt.Errorf("invalid base URL %q (want provider=url)", pair)
+		}
+		if _, known := providerBaseURLEnv[provider]; !known {
+			return nil, fmt.Errorf("invalid base URL %q: unknown provider %q (want %s)", pair, provider, strings.Join(baseURLProviders(), ", "))
+		}
+		explicit[provider] = url
+	}
+
+	urls := map[string]string{}
+	for provider, env := range providerBaseURLEnv {
+		switch {
+		case explicit[provider] != "":
+			urls[provider] = explicit[provider]
+		case os.Getenv(env) != "":
+			urls[provider] = os.Getenv(env)
+		case fallback != "":
+			urls[provider] = fallback
+		}
+	}
+	return urls, nil
+}
+
+// baseURLProviders returns the provider names accepted by -base-urls, sorted.
+func baseURLProviders() []string {
+	names := make([]string, 0, len(providerBaseURLEnv))
+	for name := range providerBaseURLEnv {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// trackerAdapterConstructors returns the tracker provider constructors, each
+// pointed at its provider's entry in baseURLs when one is set.
+func trackerAdapterConstructors(baseURLs map[string]string) map[string]func(string) (trackerllm.ProviderAdapter, error) {
+	return map[string]func(string) (trackerllm.ProviderAdapter, error){
+		"anthropic": func(key string) (trackerllm.ProviderAdapter, error) {
+			var opts []anthropic.Option
+			if base := baseURLs["anthropic"]; base != "" {
+				opts = append(opts, anthropic.WithBaseURL(base))
+			}
+			return anthropic.New(key, opts...), nil
+		},
+		"openai": func(key string) (trackerllm.ProviderAdapter, error) {
+			var opts []openai.Option
+			if base := baseURLs["openai"]; base != "" {
+				opts = append(opts, openai.WithBaseURL(base))
+			}
+			return openai.New(key, opts...), nil
+		},
+		"gemini": func(key string) (trackerllm.ProviderAdapter, error) {
+			var opts []google.Option
+			if base := baseURLs["gemini"]; base != "" {
+				opts = append(opts, google.WithBaseURL(base))
+			}
+			return google.New(key, opts...), nil
+		},
+	}
+}
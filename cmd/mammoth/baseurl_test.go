@@ -0,0 +1,112 @@
+// ABOUTME: Tests for per-provider base URL resolution and adapter construction.
+// ABOUTME: Covers -base-urls > <PROVIDER>_BASE_URL > -base-url precedence and that each adapter calls its own URL.
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	trackerllm "github.com/2389-research/tracker/llm"
+)
+
+func clearBaseURLEnv(t *testing.T) {
+	t.Helper()
+	for _, env := range providerBaseURLEnv {
+		t.Setenv(env, "")
+	}
+}
+
+func TestResolveBaseURLsPrecedence(t *testing.T) {
+	clearBaseURLEnv(t)
+	t.Setenv("OPENAI_BASE_URL", "https://openai.env")
+	t.Setenv("GEMINI_BASE_URL", "https://gemini.env")
+
+	urls, err := resolveBaseURLs("anthropic=https://anthropic.flag, gemini=https://gemini.flag", "https://fallback")
+	if err != nil {
+		t.Fatalf("resolveBaseURLs: %v", err)
+	}
+	want := map[string]string{
+		"anthropic": "https://anthropic.flag",
+		"openai":    "https://openai.env",
+		"gemini":    "https://gemini.flag",
+	}
+	for provider, url := range want {
+		if urls[provider] != url {
+			t.Errorf("%s = %q, want %q", provider, urls[provider], url)
+		}
+	}
+
+	// The fallback covers only providers with nothing more specific.
+	urls, err = resolveBaseURLs("", "https://fallback")
+	if err != nil {
+		t.Fatalf("resolveBaseURLs: %v", err)
+	}
+	if urls["anthropic"] != "https://fallback" || urls["openai"] != "https://openai.env" {
+		t.Errorf("fallback resolution = %v", urls)
+	}
+
+	clearBaseURLEnv(t)
+	urls, _ = resolveBaseURLs("", "")
+	if len(urls) != 0 {
+		t.Errorf("expected no overrides, got %v", urls)
+	}
+}
+
+func TestResolveBaseURLsInvalid(t *testing.T) {
+	for _, spec := range []string{"anthropic", "anthropic=", "=https://x", "mistral=https://x"} {
+		if _, err := resolveBaseURLs(spec, ""); err == nil {
+			t.Errorf("resolveBaseURLs(%q): expected error", spec)
+		}
+	}
+}
+
+func TestTrackerAdaptersUseProviderBaseURL(t *testing.T) {
+	hits := map[string]*atomic.Int32{}
+	baseURLs := map[string]string{}
+	for provider := range providerBaseURLEnv {
+		count := &atomic.Int32{}
+		hits[provider] = count
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			count.Add(1)
+			http.Error(w, `{"error":{"message":"stub"}}`, http.StatusBadRequest)
+		}))
+		t.Cleanup(srv.Close)
+		baseURLs[provider] = srv.URL
+	}
+
+	constructors := trackerAdapterConstructors(baseURLs)
+	for provider := range providerBaseURLEnv {
+		adapter, err := constructors[provider]("test-key")
+		if err != nil {
+			t.Fatalf("%s: construct adapter: %v", provider, err)
+		}
+		req := &trackerllm.Request{Model: "m", Messages: []trackerllm.Message{trackerllm.UserMessage("hi")}}
+		_, _ = adapter.Complete(context.Background(), req)
+	}
+
+	for provider, count := range hits {
+		if count.Load() != 1 {
+			t.Errorf("%s: its base URL got %d requests, want 1", provider, count.Load())
+		}
+	}
+}
+
+func TestLoadConfigFileFlattensMaps(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mammoth.yaml")
+	content := "base-urls:\n  openai: https://o.proxy\n  anthropic: https://a.proxy\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	values, err := loadConfigFile(path)
+	if err != nil {
+		t.Fatalf("loadConfigFile: %v", err)
+	}
+	if got, want := values["base-urls"], "anthropic=https://a.proxy,openai=https://o.proxy"; got != want {
+		t.Errorf("base-urls = %q, want %q", got, want)
+	}
+}
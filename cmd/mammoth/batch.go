@@ -0,0 +1,100 @@
+// ABOUTME: Batch mode: run several pipeline files in one invocation, at most -concurrency at a time.
+// ABOUTME: Each file is its own run with its own run ID and artifact directory; an aggregate summary sets the exit code.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// batchResult is the exit code of one file in a batch.
+type batchResult struct {
+	file string
+	code int
+}
+
+// checkBatchFlags rejects flags that cannot apply to several runs at once.
+func checkBatchFlags(cfg config) error {
+	if cfg.concurrency < 1 {
+		return fmt.Errorf("-concurrency must be at least 1")
+	}
+	if cfg.tuiMode {
+		return fmt.Errorf("-tui runs a single pipeline; drop it to run %d files", len(cfg.pipelineFiles))
+	}
+	if cfg.recordPath != "" || cfg.replayPath != "" {
+		return fmt.Errorf("-record and -replay run a single pipeline; drop them to run %d files", len(cfg.pipelineFiles))
+	}
+	return nil
+}
+
+// runBatch runs runOne for every file in cfg.pipelineFiles, at most
+// cfg.concurrency at a time, then prints a summary to w. Each file gets a
+// copy of cfg with pipelineFile set; without an -artifact-layout each run
+// writes to its own timestamped subdirectory of -artifact-dir so concurrent
+// runs never share one. Returns 1 if any file failed.
+func runBatch(w io.Writer, cfg config, runOne func(config) int) int {
+	if cfg.artifactLayout == "" {
+		cfg.artifactLayout = timestampedArtifactLayout
+	}
+
+	results := make([]batchResult, len(cfg.pipelineFiles))
+	sem := make(chan struct{}, cfg.concurrency)
+	var wg sync.WaitGroup
+	for i, file := range cfg.pipelineFiles {
+		fileCfg := cfg
+		fileCfg.pipelineFile = file
+		fileCfg.pipelineFiles = nil
+		fileCfg.batch = true
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = batchResult{file: file, code: runOne(fileCfg)}
+		}()
+	}
+	wg.Wait()
+
+	return printBatchSummary(w, results)
+}
+
+// printBatchSummary writes one line per file and the succeeded/failed
+// counts, and returns the batch's exit code.
+func printBatchSummary(w io.Writer, results []batchResult) int {
+	failed := 0
+	fmt.Fprintln(w)
+	for _, r := range results {
+		status := "ok  "
+		if r.code != 0 {
+			status = "FAIL"
+			failed++
+		}
+		fmt.Fprintf(w, "  %s  %s\n", status, r.file)
+	}
+	fmt.Fprintf(w, "Batch: %d succeeded, %d failed (%d pipelines)\n", len(results)-failed, failed, len(results))
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// parseInterspersed parses args with fs, allowing flags after positional
+// arguments (mammoth run a.dot b.dot -concurrency 2), and returns the
+// positional arguments in order.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
@@ -0,0 +1,157 @@
+// ABOUTME: Tests for batch mode: several pipeline files run with bounded concurrency and an aggregate exit code.
+// ABOUTME: Covers distinct run directories per file, the summary counts, flag conflicts, and flags after file arguments.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const batchDOT = `digraph batch {
+    start [shape=Mdiamond]
+    mark [shape=parallelogram, tool_command="pwd > workdir.txt"]
+    done [shape=Msquare]
+    start -> mark
+    mark -> done
+}`
+
+func TestRunBatchConcurrentFiles(t *testing.T) {
+	a, b := writeTempDOT(t, batchDOT), writeTempDOT(t, batchDOT)
+	base := t.TempDir()
+	cfg := config{
+		pipelineFile:  a,
+		pipelineFiles: []string{a, b},
+		concurrency:   2,
+		retryPolicy:   "none",
+		artifactDir:   base,
+		dataDir:       t.TempDir(),
+	}
+	if code := run(cfg); code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+
+	entries, err := os.ReadDir(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Name() == entries[1].Name() {
+		t.Fatalf("expected two distinct run directories under %s, got %v", base, entries)
+	}
+	for _, e := range entries {
+		if _, err := os.Stat(filepath.Join(base, e.Name(), "workdir.txt")); err != nil {
+			t.Errorf("run directory %s: %v", e.Name(), err)
+		}
+	}
+}
+
+func TestRunBatchFailsWhenAnyFileFails(t *testing.T) {
+	good := writeTempDOT(t, batchDOT)
+	missing := filepath.Join(t.TempDir(), "missing.dot")
+	cfg := config{
+		pipelineFile:  good,
+		pipelineFiles: []string{good, missing},
+		concurrency:   2,
+		retryPolicy:   "none",
+		artifactDir:   t.TempDir(),
+		dataDir:       t.TempDir(),
+	}
+	if code := run(cfg); code != 1 {
+		t.Fatalf("exit code = %d, want 1 when one file fails", code)
+	}
+}
+
+func TestRunBatchBoundsConcurrencyAndSummarizes(t *testing.T) {
+	var running, peak atomic.Int32
+	runOne := func(c config) int {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		running.Add(-1)
+		if strings.HasPrefix(c.pipelineFile, "bad") {
+			return 1
+		}
+		if !c.batch || c.artifactLayout != timestampedArtifactLayout {
+			t.Errorf("per-file config = batch %v, layout %q", c.batch, c.artifactLayout)
+		}
+		return 0
+	}
+
+	var out bytes.Buffer
+	cfg := config{pipelineFiles: []string{"a.dot", "bad.dot", "c.dot", "d.dot"}, concurrency: 2}
+	if code := runBatch(&out, cfg, runOne); code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+	if got := peak.Load(); got > 2 {
+		t.Errorf("%d files ran at once, want at most 2", got)
+	}
+	if !strings.Contains(out.String(), "Batch: 3 succeeded, 1 failed (4 pipelines)") || !strings.Contains(out.String(), "FAIL  bad.dot") {
+		t.Errorf("summary = %q", out.String())
+	}
+}
+
+func TestCheckBatchFlags(t *testing.T) {
+	files := []string{"a.dot", "b.dot"}
+	tests := []struct {
+		name string
+		cfg  config
+	}{
+		{"zero concurrency", config{pipelineFiles: files}},
+		{"tui", config{pipelineFiles: files, concurrency: 1, tuiMode: true}},
+		{"record", config{pipelineFiles: files, concurrency: 1, recordPath: "out.json"}},
+	}
+	for _, tt := range tests {
+		if err := checkBatchFlags(tt.cfg); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+	if err := checkBatchFlags(config{pipelineFiles: files, concurrency: 3}); err != nil {
+		t.Errorf("valid batch: %v", err)
+	}
+}
+
+func TestParseInterspersed(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	concurrency := fs.Int("concurrency", 1, "")
+	verbose := fs.Bool("verbose", false, "")
+	got, err := parseInterspersed(fs, []string{"-verbose", "run", "a.dot", "b.dot", "-concurrency", "2", "c.dot"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"run", "a.dot", "b.dot", "c.dot"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("positional = %v, want %v", got, want)
+	}
+	if *concurrency != 2 || !*verbose {
+		t.Errorf("concurrency = %d, verbose = %v", *concurrency, *verbose)
+	}
+}
+
+func TestParseFlagsMultipleFiles(t *testing.T) {
+	origArgs := os.Args
+	defer func() { os.Args = origArgs }()
+
+	os.Args = []string{"mammoth", "run", "a.dot", "b.dot", "c.dot", "--concurrency", "2"}
+	cfg := parseFlags()
+	if cfg.pipelineFile != "a.dot" || !reflect.DeepEqual(cfg.pipelineFiles, []string{"a.dot", "b.dot", "c.dot"}) {
+		t.Errorf("pipelineFile = %q, pipelineFiles = %v", cfg.pipelineFile, cfg.pipelineFiles)
+	}
+	if cfg.concurrency != 2 {
+		t.Errorf("concurrency = %d, want 2", cfg.concurrency)
+	}
+
+	os.Args = []string{"mammoth", "pipeline.dot"}
+	if cfg := parseFlags(); cfg.pipelineFiles != nil || cfg.concurrency != 1 {
+		t.Errorf("single file: pipelineFiles = %v, concurrency = %d", cfg.pipelineFiles, cfg.concurrency)
+	}
+}
@@ -0,0 +1,127 @@
+// ABOUTME: Cancellation grace window (-cancel-grace) for in-flight agent nodes on SIGINT/SIGTERM.
+// ABOUTME: The running node records a partial "cancelled" outcome and checkpoint before the engine hard-cancels.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// outcomeCancelled is the status recorded for an agent node that was stopped
+// by a run cancellation. It is written to the node's status.json artifact;
+// the engine itself sees a cancellation error, so the checkpoint keeps the
+// node pending and a resumed run executes it again.
+const outcomeCancelled = "cancelled"
+
+// cancelGrace coordinates a two-phase cancellation: Request first stops the
+// running agent node, which gets the grace window to return whatever output
+// it has, and only then cancels the engine's context.
+type cancelGrace struct {
+	window time.Duration
+
+	once      sync.Once
+	requested chan struct{}
+}
+
+// newCancelGrace returns a grace coordinator, or nil when window is not
+// positive (cancellation is then immediate, as without -cancel-grace).
+func newCancelGrace(window time.Duration) *cancelGrace {
+	if window <= 0 {
+		return nil
+	}
+	return &cancelGrace{window: window, requested: make(chan struct{})}
+}
+
+// Request begins cancellation and calls hard once the grace window has
+// passed. On a nil receiver it calls hard immediately.
+func (g *cancelGrace) Request(hard context.CancelFunc) {
+	if g == nil {
+		hard()
+		return
+	}
+	g.once.Do(func() {
+		close(g.requested)
+		time.AfterFunc(g.window, hard)
+	})
+}
+
+// cancelGraceHook wraps the codergen handler so in-flight agent nodes observe
+// a grace cancellation. A nil grace installs nothing.
+func cancelGraceHook(g *cancelGrace) func(*pipeline.HandlerRegistry) {
+	return func(registry *pipeline.HandlerRegistry) {
+		if g == nil {
+			return
+		}
+		if inner := registry.Get("codergen"); inner != nil {
+			registry.Register(&cancelGraceHandler{inner: inner, grace: g})
+		}
+	}
+}
+
+// cancelGraceHandler runs the wrapped handler under its own context so a
+// grace cancellation can stop the node while the engine keeps running long
+// enough to record the result.
+type cancelGraceHandler struct {
+	inner pipeline.Handler
+	grace *cancelGrace
+}
+
+func (h *cancelGraceHandler) Name() string { return h.inner.Name() }
+
+func (h *cancelGraceHandler) Execute(ctx context.Context, node *pipeline.Node, pctx *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	select {
+	case <-h.grace.requested:
+		return h.recordCancelled(node, pctx, pipeline.Outcome{})
+	default:
+	}
+
+	nodeCtx, cancelNode := context.WithCancel(ctx)
+	defer cancelNode()
+
+	type result struct {
+		outcome pipeline.Outcome
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		outcome, err := h.inner.Execute(nodeCtx, node, pctx)
+		done <- result{outcome, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.outcome, r.err
+	case <-h.grace.requested:
+	}
+
+	// Stop the node and give it the window to hand back partial output.
+	cancelNode()
+	var partial pipeline.Outcome
+	select {
+	case r := <-done:
+		partial = r.outcome
+	case <-time.After(h.grace.window):
+	}
+	return h.recordCancelled(node, pctx, partial)
+}
+
+// recordCancelled stores partial as the node's cancelled outcome: its context
+// updates go into the pipeline context (and so the checkpoint) and its status
+// artifact is written to the run's artifact directory, next to the status
+// files the engine writes for completed nodes. The returned error makes the engine save a checkpoint
+// without marking the node completed.
+func (h *cancelGraceHandler) recordCancelled(node *pipeline.Node, pctx *pipeline.PipelineContext, partial pipeline.Outcome) (pipeline.Outcome, error) {
+	partial.Status = outcomeCancelled
+	pctx.Merge(partial.ContextUpdates)
+	if runDir, ok := pctx.GetInternal(pipeline.InternalKeyArtifactDir); ok && runDir != "" {
+		if err := pipeline.WriteStatusArtifact(runDir, node.ID, partial); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: could not record cancelled outcome for %s: %v\n", node.ID, err)
+		}
+	}
+	return partial, fmt.Errorf("node %q cancelled: %w", node.ID, context.Canceled)
+}
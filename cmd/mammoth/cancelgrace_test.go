@@ -0,0 +1,110 @@
+// ABOUTME: Tests for -cancel-grace: cancelling mid-node records a partial cancelled outcome and checkpoint.
+// ABOUTME: Uses a stub codergen handler that blocks until its context is cancelled.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// blockingAgent stands in for codergen: it signals when it starts, then
+// waits for cancellation and returns the output it had so far.
+type blockingAgent struct {
+	started chan struct{}
+}
+
+func (h *blockingAgent) Name() string { return "codergen" }
+
+func (h *blockingAgent) Execute(ctx context.Context, _ *pipeline.Node, _ *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	close(h.started)
+	<-ctx.Done()
+	return pipeline.Outcome{ContextUpdates: map[string]string{"last_response": "half-written plan"}}, ctx.Err()
+}
+
+func TestCancelGraceRecordsPartialOutcome(t *testing.T) {
+	source := `digraph p {
+		start [shape=Mdiamond]
+		work [shape=box, prompt="plan"]
+		done [shape=Msquare]
+		start -> work -> done
+	}`
+	dir := t.TempDir()
+	cpPath := filepath.Join(dir, "checkpoint.json")
+	grace := newCancelGrace(5 * time.Second)
+	agent := &blockingAgent{started: make(chan struct{})}
+	install := func(r *pipeline.HandlerRegistry) { r.Register(agent) }
+
+	engine, _, err := buildPipelineEngine(source, dir, nil, cpPath, dir, "", nil, nil, install, cancelGraceHook(grace))
+	if err != nil {
+		t.Fatalf("build engine: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errc := make(chan error, 1)
+	go func() {
+		_, err := engine.Run(ctx)
+		errc <- err
+	}()
+
+	<-agent.started
+	grace.Request(cancel)
+	var runErr error
+	select {
+	case runErr = <-errc:
+	case <-time.After(3 * time.Second):
+		t.Fatal("run did not stop within the grace window")
+	}
+	if !errors.Is(runErr, context.Canceled) {
+		t.Fatalf("run error = %v, want context.Canceled", runErr)
+	}
+	if ctx.Err() != nil {
+		t.Error("the engine context should not be hard-cancelled before the grace window ends")
+	}
+
+	cp, err := pipeline.LoadCheckpoint(cpPath)
+	if err != nil {
+		t.Fatalf("load checkpoint: %v", err)
+	}
+	// The engine keeps each run's artifacts under <artifact-dir>/<run-id>.
+	data, err := os.ReadFile(filepath.Join(dir, cp.RunID, "work", "status.json"))
+	if err != nil {
+		t.Fatalf("read status artifact: %v", err)
+	}
+	var status struct {
+		Outcome        string            `json:"outcome"`
+		ContextUpdates map[string]string `json:"context_updates"`
+	}
+	if err := json.Unmarshal(data, &status); err != nil {
+		t.Fatalf("decode status artifact: %v", err)
+	}
+	if status.Outcome != outcomeCancelled || status.ContextUpdates["last_response"] != "half-written plan" {
+		t.Errorf("status.json = %s, want a cancelled outcome with the partial output", data)
+	}
+
+	if cp.Context["last_response"] != "half-written plan" {
+		t.Errorf("checkpoint context = %v, want the partial output", cp.Context)
+	}
+	if slices.Contains(cp.CompletedNodes, "work") || cp.CurrentNode != "work" {
+		t.Errorf("checkpoint current=%q completed=%v: work should stay pending for resume", cp.CurrentNode, cp.CompletedNodes)
+	}
+}
+
+func TestCancelGraceDisabled(t *testing.T) {
+	if g := newCancelGrace(0); g != nil {
+		t.Fatal("a zero window should disable the grace period")
+	}
+	called := false
+	var g *cancelGrace
+	g.Request(func() { called = true })
+	if !called {
+		t.Error("Request on a nil grace should cancel immediately")
+	}
+}
@@ -0,0 +1,70 @@
+// ABOUTME: Post-run artifact handling: clean-on-success pruning and content-addressed deduplication.
+// ABOUTME: Pruning is enabled by -clean-on-success or the clean_on_success graph attribute; dedup by -dedup-artifacts.
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/2389-research/mammoth/dot"
+	"github.com/2389-research/mammoth/runstate"
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// artifactBlobDir is the content-addressed blob directory, under the
+// artifact dir, shared by every run deduplicated there.
+const artifactBlobDir = ".blobs"
+
+// finishRunArtifacts applies the configured post-run artifact handling to
+// the engine's per-run artifact directory: a successful run is pruned when
+// clean-on-success is enabled, and otherwise its artifacts are deduplicated
+// when -dedup-artifacts is set.
+func finishRunArtifacts(cfg config, g *dot.Graph, artifactDir string, result *pipeline.EngineResult, runErr error) {
+	if cleanOnSuccessEnabled(cfg, g) && cleanRunArtifacts(artifactDir, result, runErr) {
+		return
+	}
+	if cfg.dedupArtifacts {
+		dedupRunArtifacts(artifactDir, result)
+	}
+}
+
+// dedupRunArtifacts content-addresses the run's artifacts into the shared
+// blob directory so identical files across nodes and runs are stored once.
+func dedupRunArtifacts(artifactDir string, result *pipeline.EngineResult) {
+	if artifactDir == "" || result == nil || result.RunID == "" {
+		return
+	}
+	runDir := filepath.Join(artifactDir, result.RunID)
+	if _, err := runstate.DedupArtifacts(runDir, filepath.Join(artifactDir, artifactBlobDir)); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not deduplicate artifacts: %v\n", err)
+	}
+}
+
+// cleanOnSuccessEnabled reports whether the run's artifacts should be pruned
+// after a successful run, via the CLI flag or clean_on_success="true" on the
+// graph.
+func cleanOnSuccessEnabled(cfg config, g *dot.Graph) bool {
+	if cfg.cleanOnSuccess {
+		return true
+	}
+	return g != nil && strings.EqualFold(strings.TrimSpace(g.Attrs["clean_on_success"]), "true")
+}
+
+// cleanRunArtifacts prunes the engine's per-run artifact directory
+// (<artifactDir>/<runID>) when the run succeeded, leaving only a manifest of
+// what was removed. The working directory itself is never touched. Failed,
+// cancelled, or errored runs keep their artifacts for debugging. Reports
+// whether the artifacts were pruned.
+func cleanRunArtifacts(artifactDir string, result *pipeline.EngineResult, runErr error) bool {
+	if artifactDir == "" || runErr != nil || result == nil || result.RunID == "" || result.Status != pipeline.OutcomeSuccess {
+		return false
+	}
+	runDir := filepath.Join(artifactDir, result.RunID)
+	if err := runstate.PruneArtifacts(runDir); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not clean artifacts: %v\n", err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,103 @@
+// ABOUTME: Tests for clean-on-success artifact pruning after pipeline runs.
+// ABOUTME: Successful runs leave only a manifest; failing runs keep their artifacts.
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/2389-research/mammoth/dot"
+	"github.com/2389-research/mammoth/runstate"
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// runForCleanup executes a single tool-node pipeline with the given command
+// and applies clean-on-success. Returns the engine's per-run artifact dir.
+func runForCleanup(t *testing.T, command string) string {
+	t.Helper()
+	source := `digraph clean {
+    start [shape=Mdiamond]
+    check [shape=parallelogram, tool_command="` + command + `"]
+    done [shape=Msquare]
+    start -> check
+    check -> done [condition="outcome=success"]
+}`
+	artifactDir := t.TempDir()
+	engine, _, err := buildPipelineEngine(source, artifactDir, nil, "", artifactDir, "", nil, nil)
+	if err != nil {
+		t.Fatalf("build engine: %v", err)
+	}
+	result, runErr := engine.Run(context.Background())
+	cleanRunArtifacts(artifactDir, result, runErr)
+
+	entries, err := os.ReadDir(artifactDir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected one run dir under %s, got %v (err %v)", artifactDir, entries, err)
+	}
+	return filepath.Join(artifactDir, entries[0].Name())
+}
+
+func TestCleanRunArtifactsAfterSuccess(t *testing.T) {
+	runDir := runForCleanup(t, "echo ok")
+
+	entries, err := os.ReadDir(runDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != runstate.ArtifactManifestFile {
+		t.Errorf("run dir entries = %v, want only the manifest", entries)
+	}
+}
+
+func TestCleanRunArtifactsKeptAfterFailure(t *testing.T) {
+	runDir := runForCleanup(t, "exit 1")
+
+	entries, err := os.ReadDir(runDir)
+	if err != nil || len(entries) == 0 {
+		t.Fatalf("expected failed run artifacts to be kept, got %v (err %v)", entries, err)
+	}
+	if _, err := os.Stat(filepath.Join(runDir, runstate.ArtifactManifestFile)); !os.IsNotExist(err) {
+		t.Errorf("expected no manifest after a failed run, stat err = %v", err)
+	}
+}
+
+func TestCleanOnSuccessEnabled(t *testing.T) {
+	g := &dot.Graph{Attrs: map[string]string{"clean_on_success": "true"}}
+	if !cleanOnSuccessEnabled(config{}, g) {
+		t.Error("expected graph attribute to enable cleanup")
+	}
+	if !cleanOnSuccessEnabled(config{cleanOnSuccess: true}, &dot.Graph{}) {
+		t.Error("expected flag to enable cleanup")
+	}
+	if cleanOnSuccessEnabled(config{}, &dot.Graph{}) {
+		t.Error("expected cleanup off by default")
+	}
+}
+
+func TestFinishRunArtifactsDedup(t *testing.T) {
+	artifactDir := t.TempDir()
+	for _, node := range []string{"plan", "review"} {
+		dir := filepath.Join(artifactDir, "run1", node)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "response.md"), []byte("identical"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	result := &pipeline.EngineResult{RunID: "run1", Status: pipeline.OutcomeSuccess}
+
+	finishRunArtifacts(config{dedupArtifacts: true}, &dot.Graph{}, artifactDir, result, nil)
+
+	a, _ := os.Stat(filepath.Join(artifactDir, "run1", "plan", "response.md"))
+	b, _ := os.Stat(filepath.Join(artifactDir, "run1", "review", "response.md"))
+	if a == nil || b == nil || !os.SameFile(a, b) {
+		t.Error("expected identical artifacts to share one blob")
+	}
+	blobs, _ := filepath.Glob(filepath.Join(artifactDir, artifactBlobDir, "*", "*"))
+	if len(blobs) != 1 {
+		t.Errorf("blobs = %v, want exactly one", blobs)
+	}
+}
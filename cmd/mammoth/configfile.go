@@ -0,0 +1,157 @@
+// ABOUTME: Structured CLI defaults from a mammoth.yaml config file and MAMMOTH_* environment variables.
+// ABOUTME: Precedence is explicit flag > environment variable > config file > built-in default.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigFiles are auto-discovered in the working directory, in order,
+// when -config is not given.
+var defaultConfigFiles = []string{"mammoth.yaml", "mammoth.yml"}
+
+// configFlagName is the flag that selects the config file; it cannot be set
+// from the file or the environment.
+const configFlagName = "config"
+
+// flagEnvExceptions maps flags whose derived MAMMOTH_<FLAG> name already
+// means something else to the variable they read instead ("" for none).
+// MAMMOTH_DEFAULT_MODEL is a bare model name read by the spec server and the
+// DOT fixer, while -default-model takes provider=model pairs and falls back to
+// MAMMOTH_DEFAULT_MODELS on its own.
+var flagEnvExceptions = map[string]string{
+	"default-model": "",
+}
+
+// flagEnvVar returns the environment variable that supplies a default for the
+// named flag, e.g. "artifact-dir" → "MAMMOTH_ARTIFACT_DIR", or "" when the
+// flag has no environment default.
+func flagEnvVar(name string) string {
+	if env, ok := flagEnvExceptions[name]; ok {
+		return env
+	}
+	return "MAMMOTH_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// resolveConfigFile returns the config file to load: the explicit path if one
+// was given, otherwise the first default file present in the working
+// directory, otherwise "".
+func resolveConfigFile(explicit string) string {
+	if explicit != "" {
+		return explicit
+	}
+	for _, name := range defaultConfigFiles {
+		if info, err := os.Stat(name); err == nil && !info.IsDir() {
+			return name
+		}
+	}
+	return ""
+}
+
+// loadConfigFile reads a YAML mapping of flag names to values. Scalars of any
+// type are accepted and converted to their flag string form; a nested map of
+// scalars is flattened to "key=value,..." for provider-keyed flags.
+func loadConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config %s: %w", path, err)
+	}
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+	values := make(map[string]string, len(raw))
+	for key, v := range raw {
+		switch v := v.(type) {
+		case map[string]any:
+			// Maps become the key=value,... form used by flags such as
+			// -base-urls and -default-model.
+			pairs := make([]string, 0, len(v))
+			for k, val := range v {
+				if !isScalar(val) {
+					return nil, fmt.Errorf("config %s: %q.%s must be a scalar value", path, key, k)
+				}
+				pairs = append(pairs, k+"="+fmt.Sprint(val))
+			}
+			sort.Strings(pairs)
+			values[key] = strings.Join(pairs, ",")
+		case []any:
+			return nil, fmt.Errorf("config %s: %q must be a scalar value or a map", path, key)
+		case nil:
+			continue
+		default:
+			values[key] = fmt.Sprint(v)
+		}
+	}
+	return values, nil
+}
+
+// isScalar reports whether a decoded YAML value is a plain scalar.
+func isScalar(v any) bool {
+	switch v.(type) {
+	case map[string]any, []any, nil:
+		return false
+	}
+	return true
+}
+
+// applyFlagDefaults fills in every flag not set on the command line, first
+// from its MAMMOTH_* environment variable and then from the config file
+// values. Config keys that do not name a flag are rejected so typos surface.
+func applyFlagDefaults(fs *flag.FlagSet, fileValues map[string]string, getenv func(string) string) error {
+	explicit := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	var unknown []string
+	for key := range fileValues {
+		if fs.Lookup(key) == nil || key == configFlagName {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown config keys: %s", strings.Join(unknown, ", "))
+	}
+
+	var errs []error
+	fs.VisitAll(func(f *flag.Flag) {
+		if explicit[f.Name] || f.Name == configFlagName {
+			return
+		}
+		source, value := flagEnvVar(f.Name), ""
+		if source != "" {
+			value = getenv(source)
+		}
+		if value == "" {
+			v, ok := fileValues[f.Name]
+			if !ok {
+				return
+			}
+			source, value = "config key "+f.Name, v
+		}
+		if err := fs.Set(f.Name, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", source, err))
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// applyConfigDefaults loads the selected config file (if any) and applies it
+// together with the environment to the flags left unset on the command line.
+func applyConfigDefaults(fs *flag.FlagSet, configPath string) error {
+	var fileValues map[string]string
+	if path := resolveConfigFile(configPath); path != "" {
+		var err error
+		if fileValues, err = loadConfigFile(path); err != nil {
+			return err
+		}
+	}
+	return applyFlagDefaults(fs, fileValues, os.Getenv)
+}
@@ -0,0 +1,161 @@
+// ABOUTME: Tests for CLI defaults from mammoth.yaml and MAMMOTH_* environment variables.
+// ABOUTME: Covers flag > env > config file > built-in default precedence and config discovery.
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newConfigTestFlags() (*flag.FlagSet, *config) {
+	var cfg config
+	fs := flag.NewFlagSet("mammoth", flag.ContinueOnError)
+	fs.StringVar(&cfg.retryPolicy, "retry", "none", "")
+	fs.StringVar(&cfg.artifactDir, "artifact-dir", ".", "")
+	fs.StringVar(&cfg.dataDir, "data-dir", "", "")
+	fs.StringVar(&cfg.entry, "entry", "", "")
+	fs.BoolVar(&cfg.verbose, "verbose", false, "")
+	fs.StringVar(&cfg.defaultModels, "default-model", "", "")
+	fs.StringVar(&cfg.configPath, configFlagName, "", "")
+	return fs, &cfg
+}
+
+func TestApplyFlagDefaultsPrecedence(t *testing.T) {
+	fs, cfg := newConfigTestFlags()
+	if err := fs.Parse([]string{"-retry", "aggressive"}); err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{
+		"MAMMOTH_RETRY":        "linear",
+		"MAMMOTH_ARTIFACT_DIR": "/env/artifacts",
+	}
+	file := map[string]string{
+		"retry":        "patient",
+		"artifact-dir": "/file/artifacts",
+		"data-dir":     "/file/data",
+		"verbose":      "true",
+	}
+	if err := applyFlagDefaults(fs, file, func(k string) string { return env[k] }); err != nil {
+		t.Fatalf("applyFlagDefaults: %v", err)
+	}
+
+	if cfg.retryPolicy != "aggressive" {
+		t.Errorf("retry = %q, want flag value aggressive", cfg.retryPolicy)
+	}
+	if cfg.artifactDir != "/env/artifacts" {
+		t.Errorf("artifact-dir = %q, want env value", cfg.artifactDir)
+	}
+	if cfg.dataDir != "/file/data" {
+		t.Errorf("data-dir = %q, want config file value", cfg.dataDir)
+	}
+	if !cfg.verbose {
+		t.Error("verbose should come from the config file")
+	}
+	if cfg.entry != "" {
+		t.Errorf("entry = %q, want built-in default", cfg.entry)
+	}
+}
+
+func TestApplyFlagDefaultsSkipsCollidingEnvVars(t *testing.T) {
+	fs, cfg := newConfigTestFlags()
+	if err := fs.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	// MAMMOTH_DEFAULT_MODEL is the spec server's bare model name, not a
+	// provider=model list for -default-model.
+	env := map[string]string{"MAMMOTH_DEFAULT_MODEL": "claude-sonnet-4-5"}
+	file := map[string]string{"default-model": "openai=gpt-5"}
+	if err := applyFlagDefaults(fs, file, func(k string) string { return env[k] }); err != nil {
+		t.Fatalf("applyFlagDefaults: %v", err)
+	}
+	if cfg.defaultModels != "openai=gpt-5" {
+		t.Errorf("default-model = %q, want the config file value", cfg.defaultModels)
+	}
+	if got := flagEnvVar("default-model"); got != "" {
+		t.Errorf("flagEnvVar(default-model) = %q, want none", got)
+	}
+}
+
+func TestApplyFlagDefaultsErrors(t *testing.T) {
+	noEnv := func(string) string { return "" }
+
+	fs, _ := newConfigTestFlags()
+	_ = fs.Parse(nil)
+	err := applyFlagDefaults(fs, map[string]string{"retyr": "patient", "config": "x.yaml"}, noEnv)
+	if err == nil || !strings.Contains(err.Error(), "config, retyr") {
+		t.Errorf("expected unknown key error naming config and retyr, got %v", err)
+	}
+
+	fs, _ = newConfigTestFlags()
+	_ = fs.Parse(nil)
+	err = applyFlagDefaults(fs, nil, func(k string) string {
+		if k == "MAMMOTH_VERBOSE" {
+			return "sometimes"
+		}
+		return ""
+	})
+	if err == nil || !strings.Contains(err.Error(), "MAMMOTH_VERBOSE") {
+		t.Errorf("expected invalid env value error, got %v", err)
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mammoth.yaml")
+	content := "retry: patient\nmax-artifact-bytes: 1048576\nverbose: true\nentry:\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	values, err := loadConfigFile(path)
+	if err != nil {
+		t.Fatalf("loadConfigFile: %v", err)
+	}
+	want := map[string]string{"retry": "patient", "max-artifact-bytes": "1048576", "verbose": "true"}
+	if len(values) != len(want) {
+		t.Fatalf("values = %v, want %v", values, want)
+	}
+	for k, v := range want {
+		if values[k] != v {
+			t.Errorf("%s = %q, want %q", k, values[k], v)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte("retry: [a, b]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadConfigFile(path); err == nil {
+		t.Error("expected error for non-scalar value")
+	}
+}
+
+func TestParseFlagsConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.WriteFile("mammoth.yaml", []byte("retry: patient\ndata-dir: /file/data\nartifact-dir: /file/artifacts\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MAMMOTH_DATA_DIR", "/env/data")
+
+	origArgs := os.Args
+	defer func() { os.Args = origArgs }()
+
+	// Auto-discovered ./mammoth.yaml.
+	os.Args = []string{"mammoth", "-artifact-dir", "/flag/artifacts", "pipeline.dot"}
+	cfg := parseFlags()
+	if cfg.retryPolicy != "patient" || cfg.dataDir != "/env/data" || cfg.artifactDir != "/flag/artifacts" {
+		t.Errorf("discovered config: retry=%q data-dir=%q artifact-dir=%q", cfg.retryPolicy, cfg.dataDir, cfg.artifactDir)
+	}
+
+	// Explicit -config takes the place of the discovered file.
+	other := filepath.Join(dir, "ci.yaml")
+	if err := os.WriteFile(other, []byte("retry: standard\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	os.Args = []string{"mammoth", "-config", other, "pipeline.dot"}
+	cfg = parseFlags()
+	if cfg.retryPolicy != "standard" || cfg.artifactDir != "." {
+		t.Errorf("explicit config: retry=%q artifact-dir=%q", cfg.retryPolicy, cfg.artifactDir)
+	}
+}
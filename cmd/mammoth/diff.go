@@ -0,0 +1,294 @@
+// ABOUTME: "mammoth diff" subcommand comparing two stored pipeline runs.
+// ABOUTME: Reports differences in completed nodes, per-node outcomes, final context, and token usage.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/2389-research/mammoth/runstate"
+)
+
+// diffConfig holds configuration for the "mammoth diff" subcommand.
+type diffConfig struct {
+	runA    string
+	runB    string
+	jsonOut bool
+	dataDir string
+}
+
+// parseDiffArgs checks whether args starts with the "diff" subcommand and,
+// if so, parses diff-specific flags. Returns the config and true if "diff"
+// was detected, or a zero value and false otherwise.
+func parseDiffArgs(args []string) (diffConfig, bool) {
+	if len(args) == 0 || args[0] != "diff" {
+		return diffConfig{}, false
+	}
+
+	var cfg diffConfig
+	fs := flag.NewFlagSet("mammoth diff", flag.ContinueOnError)
+	fs.BoolVar(&cfg.jsonOut, "json", false, "Print the comparison as JSON")
+	fs.StringVar(&cfg.dataDir, "data-dir", "", "Data directory (default: .mammoth/ in CWD)")
+
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: mammoth diff [flags] <runA> <runB>")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Compare two pipeline runs: completed nodes, per-node outcomes,")
+		fmt.Fprintln(os.Stderr, "final context, and token usage.")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Flags:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		os.Exit(2)
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	cfg.runA, cfg.runB = fs.Arg(0), fs.Arg(1)
+
+	return cfg, true
+}
+
+// runDiff loads two runs from the run store and prints their differences.
+// Returns 0 when the comparison was printed, 1 on error.
+func runDiff(cfg diffConfig) int {
+	dataDir := cfg.dataDir
+	if dataDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return 1
+		}
+		dataDir = filepath.Join(cwd, ".mammoth")
+	}
+
+	store, err := runstate.NewFSRunStateStore(filepath.Join(dataDir, "runs"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: could not open run store: %v\n", err)
+		return 1
+	}
+	a, err := store.Get(cfg.runA)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: could not load run %s: %v\n", cfg.runA, err)
+		return 1
+	}
+	b, err := store.Get(cfg.runB)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: could not load run %s: %v\n", cfg.runB, err)
+		return 1
+	}
+
+	cmp := compareRuns(a, b)
+	if cfg.jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(cmp); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	cmp.writeText(os.Stdout)
+	return 0
+}
+
+// runComparison is the difference between two runs. Only differing entries
+// are listed.
+type runComparison struct {
+	RunA       string          `json:"run_a"`
+	RunB       string          `json:"run_b"`
+	StatusA    string          `json:"status_a"`
+	StatusB    string          `json:"status_b"`
+	SameSource bool            `json:"same_source"`
+	OnlyInA    []string        `json:"completed_only_in_a"`
+	OnlyInB    []string        `json:"completed_only_in_b"`
+	Outcomes   []valueDiff     `json:"outcomes"`
+	Context    []valueDiff     `json:"context"`
+	Tokens     tokenComparison `json:"tokens"`
+}
+
+// valueDiff is a key whose value differs between the runs. An empty side
+// means the key is absent from that run.
+type valueDiff struct {
+	Key string `json:"key"`
+	A   string `json:"a"`
+	B   string `json:"b"`
+}
+
+// tokenComparison holds the total token usage of each run.
+type tokenComparison struct {
+	A int `json:"a"`
+	B int `json:"b"`
+}
+
+// Identical reports whether the runs show no differences.
+func (c runComparison) Identical() bool {
+	return c.StatusA == c.StatusB && len(c.OnlyInA) == 0 && len(c.OnlyInB) == 0 &&
+		len(c.Outcomes) == 0 && len(c.Context) == 0 && c.Tokens.A == c.Tokens.B
+}
+
+// compareRuns computes the differences between runs a and b.
+func compareRuns(a, b *runstate.RunState) runComparison {
+	cmp := runComparison{
+		RunA:       a.ID,
+		RunB:       b.ID,
+		StatusA:    a.Status,
+		StatusB:    b.Status,
+		SameSource: a.SourceHash != "" && a.SourceHash == b.SourceHash,
+		OnlyInA:    []string{},
+		OnlyInB:    []string{},
+		Tokens:     tokenComparison{A: runTokenUsage(a.Events), B: runTokenUsage(b.Events)},
+	}
+
+	completedA, completedB := stringSet(a.CompletedNodes), stringSet(b.CompletedNodes)
+	for id := range completedA {
+		if !completedB[id] {
+			cmp.OnlyInA = append(cmp.OnlyInA, id)
+		}
+	}
+	for id := range completedB {
+		if !completedA[id] {
+			cmp.OnlyInB = append(cmp.OnlyInB, id)
+		}
+	}
+	sort.Strings(cmp.OnlyInA)
+	sort.Strings(cmp.OnlyInB)
+
+	cmp.Outcomes = diffMaps(nodeOutcomes(a.Events), nodeOutcomes(b.Events))
+	cmp.Context = diffMaps(a.Context, b.Context)
+	return cmp
+}
+
+// nodeOutcomes returns each node's final stage outcome ("success", "fail",
+// or "retrying") as recorded in the run's events.
+func nodeOutcomes(events []runstate.RunEvent) map[string]string {
+	outcomes := make(map[string]string)
+	for _, evt := range events {
+		if evt.NodeID == "" {
+			continue
+		}
+		switch normalizeEventType(evt.Type) {
+		case "stage_completed":
+			outcomes[evt.NodeID] = "success"
+		case "stage_failed":
+			outcomes[evt.NodeID] = "fail"
+		case "stage_retrying":
+			outcomes[evt.NodeID] = "retrying"
+		}
+	}
+	return outcomes
+}
+
+// runTokenUsage sums total_tokens across the run's LLM turn events.
+func runTokenUsage(events []runstate.RunEvent) int {
+	total := 0
+	for _, evt := range events {
+		if normalizeEventType(evt.Type) != "turn_metrics" {
+			continue
+		}
+		switch n := evt.Data["total_tokens"].(type) {
+		case float64:
+			total += int(n)
+		case int:
+			total += n
+		}
+	}
+	return total
+}
+
+// diffMaps lists the keys whose values differ between a and b, sorted by key.
+func diffMaps(a, b map[string]string) []valueDiff {
+	diffs := []valueDiff{}
+	for k, va := range a {
+		if vb, ok := b[k]; !ok || va != vb {
+			diffs = append(diffs, valueDiff{Key: k, A: va, B: b[k]})
+		}
+	}
+	for k, vb := range b {
+		if _, ok := a[k]; !ok {
+			diffs = append(diffs, valueDiff{Key: k, B: vb})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Key < diffs[j].Key })
+	return diffs
+}
+
+func stringSet(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, s := range items {
+		set[s] = true
+	}
+	return set
+}
+
+// writeText prints the comparison in a readable, diff-like form: lines
+// starting with "-" describe run A and lines starting with "+" run B.
+func (c runComparison) writeText(w io.Writer) {
+	fmt.Fprintf(w, "--- %s (%s)\n", c.RunA, c.StatusA)
+	fmt.Fprintf(w, "+++ %s (%s)\n", c.RunB, c.StatusB)
+	if !c.SameSource {
+		fmt.Fprintln(w, "note: runs were started from different pipeline sources")
+	}
+	if c.Identical() {
+		fmt.Fprintln(w, "\nno differences")
+		return
+	}
+
+	if len(c.OnlyInA) > 0 || len(c.OnlyInB) > 0 {
+		fmt.Fprintln(w, "\nCompleted nodes:")
+		for _, id := range c.OnlyInA {
+			fmt.Fprintf(w, "- %s\n", id)
+		}
+		for _, id := range c.OnlyInB {
+			fmt.Fprintf(w, "+ %s\n", id)
+		}
+	}
+	if len(c.Outcomes) > 0 {
+		fmt.Fprintln(w, "\nNode outcomes:")
+		for _, d := range c.Outcomes {
+			fmt.Fprintf(w, "  %s: %s -> %s\n", d.Key, orNone(d.A), orNone(d.B))
+		}
+	}
+	if len(c.Context) > 0 {
+		fmt.Fprintln(w, "\nFinal context:")
+		for _, d := range c.Context {
+			fmt.Fprintf(w, "  %s:\n", d.Key)
+			fmt.Fprintf(w, "  - %s\n", oneLine(d.A))
+			fmt.Fprintf(w, "  + %s\n", oneLine(d.B))
+		}
+	}
+	if c.Tokens.A != c.Tokens.B {
+		fmt.Fprintf(w, "\nTokens: %d -> %d (%+d)\n", c.Tokens.A, c.Tokens.B, c.Tokens.B-c.Tokens.A)
+	}
+}
+
+// orNone renders an absent value.
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+// oneLine collapses newlines and truncates long values for display.
+func oneLine(s string) string {
+	const maxLen = 120
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	if r := []rune(s); len(r) > maxLen {
+		return string(r[:maxLen]) + "…"
+	}
+	return orNone(s)
+}
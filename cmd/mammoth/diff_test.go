@@ -0,0 +1,157 @@
+// ABOUTME: Tests for the "mammoth diff" subcommand comparing two stored runs.
+// ABOUTME: Uses hand-constructed run states to assert node, outcome, context, and token differences.
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/2389-research/mammoth/runstate"
+)
+
+// diffTestRuns returns two runs of the same pipeline where "build" succeeded
+// in A but failed in B, so B never reached "ship".
+func diffTestRuns() (*runstate.RunState, *runstate.RunState) {
+	now := time.Now()
+	a := &runstate.RunState{
+		ID:             "run-a",
+		Status:         "completed",
+		SourceHash:     "abc",
+		CompletedNodes: []string{"start", "build", "ship"},
+		Context:        map[string]string{"outcome": "success", "last_response": "shipped"},
+		Events: []runstate.RunEvent{
+			{Type: "stage.completed", NodeID: "build", Timestamp: now},
+			{Type: "stage_completed", NodeID: "ship", Timestamp: now},
+			{Type: "agent.llm_turn", NodeID: "build", Data: map[string]any{"total_tokens": float64(1200)}, Timestamp: now},
+		},
+	}
+	b := &runstate.RunState{
+		ID:             "run-b",
+		Status:         "failed",
+		SourceHash:     "abc",
+		CompletedNodes: []string{"start"},
+		Context:        map[string]string{"outcome": "fail", "error.build": "compile error"},
+		Events: []runstate.RunEvent{
+			{Type: "stage_retrying", NodeID: "build", Timestamp: now},
+			{Type: "stage_failed", NodeID: "build", Timestamp: now},
+			{Type: "turn_metrics", NodeID: "build", Data: map[string]any{"total_tokens": 800}, Timestamp: now},
+			{Type: "turn_metrics", NodeID: "build", Data: map[string]any{"total_tokens": 700}, Timestamp: now},
+		},
+	}
+	return a, b
+}
+
+func TestCompareRuns(t *testing.T) {
+	a, b := diffTestRuns()
+	cmp := compareRuns(a, b)
+
+	if cmp.StatusA != "completed" || cmp.StatusB != "failed" {
+		t.Errorf("statuses = %q/%q", cmp.StatusA, cmp.StatusB)
+	}
+	if !cmp.SameSource {
+		t.Error("expected SameSource for matching source hashes")
+	}
+	if !reflect.DeepEqual(cmp.OnlyInA, []string{"build", "ship"}) || len(cmp.OnlyInB) != 0 {
+		t.Errorf("completed diff = %v / %v", cmp.OnlyInA, cmp.OnlyInB)
+	}
+	wantOutcomes := []valueDiff{
+		{Key: "build", A: "success", B: "fail"},
+		{Key: "ship", A: "success"},
+	}
+	if !reflect.DeepEqual(cmp.Outcomes, wantOutcomes) {
+		t.Errorf("outcomes = %+v, want %+v", cmp.Outcomes, wantOutcomes)
+	}
+	wantContext := []valueDiff{
+		{Key: "error.build", B: "compile error"},
+		{Key: "last_response", A: "shipped"},
+		{Key: "outcome", A: "success", B: "fail"},
+	}
+	if !reflect.DeepEqual(cmp.Context, wantContext) {
+		t.Errorf("context = %+v, want %+v", cmp.Context, wantContext)
+	}
+	if cmp.Tokens != (tokenComparison{A: 1200, B: 1500}) {
+		t.Errorf("tokens = %+v", cmp.Tokens)
+	}
+	if cmp.Identical() {
+		t.Error("expected differences")
+	}
+}
+
+func TestCompareRunsIdentical(t *testing.T) {
+	a, _ := diffTestRuns()
+	cmp := compareRuns(a, a)
+	if !cmp.Identical() {
+		t.Fatalf("expected identical comparison, got %+v", cmp)
+	}
+	var buf bytes.Buffer
+	cmp.writeText(&buf)
+	if !strings.Contains(buf.String(), "no differences") {
+		t.Errorf("output = %q", buf.String())
+	}
+}
+
+func TestRunComparisonWriteText(t *testing.T) {
+	a, b := diffTestRuns()
+	var buf bytes.Buffer
+	compareRuns(a, b).writeText(&buf)
+	out := buf.String()
+
+	for _, want := range []string{
+		"--- run-a (completed)",
+		"+++ run-b (failed)",
+		"- build",
+		"build: success -> fail",
+		"ship: success -> (none)",
+		"  - success\n  + fail",
+		"Tokens: 1200 -> 1500 (+300)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRunDiffFromStore(t *testing.T) {
+	dataDir := t.TempDir()
+	store, err := runstate.NewFSRunStateStore(dataDir + "/runs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := diffTestRuns()
+	for _, run := range []*runstate.RunState{a, b} {
+		events := run.Events
+		run.Events = nil
+		run.StartedAt = time.Now()
+		if err := store.Create(run); err != nil {
+			t.Fatalf("create %s: %v", run.ID, err)
+		}
+		for _, evt := range events {
+			if err := store.AddEvent(run.ID, evt); err != nil {
+				t.Fatalf("add event: %v", err)
+			}
+		}
+	}
+
+	if code := runDiff(diffConfig{runA: "run-a", runB: "run-b", dataDir: dataDir, jsonOut: true}); code != 0 {
+		t.Errorf("runDiff exit = %d, want 0", code)
+	}
+	if code := runDiff(diffConfig{runA: "run-a", runB: "missing", dataDir: dataDir}); code != 1 {
+		t.Errorf("runDiff with missing run exit = %d, want 1", code)
+	}
+}
+
+func TestParseDiffArgs(t *testing.T) {
+	cfg, ok := parseDiffArgs([]string{"diff", "--json", "--data-dir", "/tmp/x", "a", "b"})
+	if !ok {
+		t.Fatal("expected diff to be detected")
+	}
+	if cfg.runA != "a" || cfg.runB != "b" || !cfg.jsonOut || cfg.dataDir != "/tmp/x" {
+		t.Errorf("cfg = %+v", cfg)
+	}
+	if _, ok := parseDiffArgs([]string{"audit", "a"}); ok {
+		t.Error("expected non-diff args to be ignored")
+	}
+}
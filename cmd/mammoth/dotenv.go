@@ -1,14 +1,19 @@
 // ABOUTME: Loads environment variables from .env files and XDG config at startup.
 // ABOUTME: Sets variables only when not already present in the environment (no clobber).
+// ABOUTME: --env-file replaces auto-discovery with explicit files, later files winning over earlier ones.
 package main
 
 import (
 	"bufio"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 )
 
+// envFileFlag names the global flag selecting dotenv files explicitly.
+const envFileFlag = "env-file"
+
 // loadDotEnv reads a .env file and sets any variables not already in the environment.
 // Missing files are silently ignored. Lines starting with # are comments.
 // Supports KEY=VALUE, KEY="VALUE", KEY='VALUE', and export KEY=VALUE.
@@ -92,3 +97,54 @@ func loadDotEnvAuto() {
 		addPath(filepath.Join(cfgDir, "config.env"))
 	}
 }
+
+// splitEnvFileArgs removes every -env-file/--env-file flag (as "flag path"
+// or "flag=path") from args and returns the paths in order with the
+// remaining arguments. The flag is read before any subcommand parses its own
+// flags, since the environment must be loaded before backend detection.
+// Arguments after "--" and "mammoth setup", whose -env-file names the file
+// it writes, are left alone.
+func splitEnvFileArgs(args []string) (files, rest []string, err error) {
+	if len(args) > 0 && args[0] == "setup" {
+		return nil, args, nil
+	}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != envFileFlag {
+			rest = append(rest, arg)
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, nil, fmt.Errorf("flag needs an argument: -%s", envFileFlag)
+			}
+			i++
+			value = args[i]
+		}
+		files = append(files, value)
+	}
+	return files, rest, nil
+}
+
+// loadEnvFiles loads the given dotenv files in place of auto-discovery.
+// Later files override earlier ones; variables already in the environment
+// still win over every file. Unlike auto-discovered files, a missing file is
+// an error.
+func loadEnvFiles(paths []string) error {
+	for _, p := range paths {
+		if _, err := os.Stat(p); err != nil {
+			return fmt.Errorf("-%s: %w", envFileFlag, err)
+		}
+	}
+	// loadDotEnv never overwrites a set variable, so loading the last file
+	// first lets it win.
+	for i := len(paths) - 1; i >= 0; i-- {
+		loadDotEnv(paths[i])
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ package main
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 )
 
@@ -144,3 +145,66 @@ func TestLoadDotEnvAutoLoadsXDGConfig(t *testing.T) {
 		t.Errorf("expected TEST_XDG_AUTO_LOAD=from_xdg, got %q", got)
 	}
 }
+
+func TestSplitEnvFileArgs(t *testing.T) {
+	tests := []struct {
+		args      []string
+		wantFiles []string
+		wantRest  []string
+	}{
+		{[]string{"pipeline.dot"}, nil, []string{"pipeline.dot"}},
+		{[]string{"--env-file", ".env.staging", "-tui", "p.dot"}, []string{".env.staging"}, []string{"-tui", "p.dot"}},
+		{[]string{"-env-file=a.env", "serve", "--env-file", "b.env", "--port", "3000"}, []string{"a.env", "b.env"}, []string{"serve", "--port", "3000"}},
+		{[]string{"p.dot", "--", "--env-file", "x"}, nil, []string{"p.dot", "--", "--env-file", "x"}},
+		{[]string{"setup", "--env-file", "out.env"}, nil, []string{"setup", "--env-file", "out.env"}},
+		{[]string{"--env-filex", "y"}, nil, []string{"--env-filex", "y"}},
+	}
+	for _, tt := range tests {
+		files, rest, err := splitEnvFileArgs(tt.args)
+		if err != nil {
+			t.Errorf("splitEnvFileArgs(%q): %v", tt.args, err)
+			continue
+		}
+		if !slices.Equal(files, tt.wantFiles) || !slices.Equal(rest, tt.wantRest) {
+			t.Errorf("splitEnvFileArgs(%q) = %q, %q; want %q, %q", tt.args, files, rest, tt.wantFiles, tt.wantRest)
+		}
+	}
+
+	if _, _, err := splitEnvFileArgs([]string{"p.dot", "--env-file"}); err == nil {
+		t.Error("expected an error for --env-file without a path")
+	}
+}
+
+func TestLoadEnvFilesLaterFilesWin(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, ".env")
+	staging := filepath.Join(dir, ".env.staging")
+	if err := os.WriteFile(base, []byte("TEST_ENVFILE_HOST=localhost\nTEST_ENVFILE_USER=dev\nTEST_ENVFILE_SET=from-file\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(staging, []byte("TEST_ENVFILE_HOST=staging.example.com\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"TEST_ENVFILE_HOST", "TEST_ENVFILE_USER"} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+	t.Setenv("TEST_ENVFILE_SET", "from-env")
+
+	if err := loadEnvFiles([]string{base, staging}); err != nil {
+		t.Fatal(err)
+	}
+	if got := os.Getenv("TEST_ENVFILE_HOST"); got != "staging.example.com" {
+		t.Errorf("TEST_ENVFILE_HOST = %q, want the later file's value", got)
+	}
+	if got := os.Getenv("TEST_ENVFILE_USER"); got != "dev" {
+		t.Errorf("TEST_ENVFILE_USER = %q, want dev from the earlier file", got)
+	}
+	if got := os.Getenv("TEST_ENVFILE_SET"); got != "from-env" {
+		t.Errorf("TEST_ENVFILE_SET = %q, want the environment to win", got)
+	}
+
+	if err := loadEnvFiles([]string{filepath.Join(dir, "missing.env")}); err == nil {
+		t.Error("expected an error for a missing --env-file")
+	}
+}
@@ -0,0 +1,183 @@
+// ABOUTME: Ordered backend fallback chain (-backend-chain, backend_chain node attribute) for codergen nodes.
+// ABOUTME: A Completer wrapper fails over to the next provider on server errors or exhausted rate limits.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/2389-research/tracker/agent"
+	trackerllm "github.com/2389-research/tracker/llm"
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// servedByContextPrefix prefixes the context key recording which provider
+// served a node run with a fallback chain: "served_by.<nodeID>".
+const servedByContextPrefix = "served_by."
+
+// backendEntry is one step of a fallback chain. An empty model keeps the
+// provider's default model, or the request's model when the entry is the
+// node's own provider; other providers must have a model to fall back to.
+type backendEntry struct {
+	provider string
+	model    string
+}
+
+// parseBackendChain parses a comma-separated, ordered list of providers,
+// each optionally pinned to a model, e.g. "anthropic,openai=gpt-4o".
+// Provider names are lowercased. An empty spec yields a nil chain.
+func parseBackendChain(spec string) ([]backendEntry, error) {
+	var chain []backendEntry
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		provider, model, hasModel := strings.Cut(item, "=")
+		provider = strings.ToLower(strings.TrimSpace(provider))
+		model = strings.TrimSpace(model)
+		if provider == "" || (hasModel && model == "") {
+			return nil, fmt.Errorf("invalid backend %q (want provider or provider=model)", item)
+		}
+		chain = append(chain, backendEntry{provider: provider, model: model})
+	}
+	return chain, nil
+}
+
+// backendFallback carries a node's chain through the context and records the
+// provider that served its last request.
+type backendFallback struct {
+	chain    []backendEntry
+	servedBy string
+}
+
+type backendFallbackKey struct{}
+
+// resolveBackendChain fills in each entry's model from defaults (see
+// -default-model). An entry for a provider other than nodeProvider that still
+// has no model is an error: the node's model names a model of nodeProvider,
+// and sending it to another provider would fail or pick the wrong model.
+func resolveBackendChain(chain []backendEntry, nodeProvider string, defaults map[string]string) ([]backendEntry, error) {
+	resolved := make([]backendEntry, len(chain))
+	for i, entry := range chain {
+		if entry.model == "" {
+			entry.model = defaults[entry.provider]
+		}
+		if entry.model == "" && entry.provider != nodeProvider {
+			return nil, fmt.Errorf("backend %q needs a model to serve a %s node: use %s=<model> or -default-model %s=<model>", entry.provider, providerLabel(nodeProvider), entry.provider, entry.provider)
+		}
+		resolved[i] = entry
+	}
+	return resolved, nil
+}
+
+// providerLabel names a node's provider in messages.
+func providerLabel(provider string) string {
+	if provider == "" {
+		return "default-provider"
+	}
+	return provider
+}
+
+// backendChainHook returns a registry hook that wraps the codergen handler so
+// each node runs with its fallback chain: the node's backend_chain attribute
+// when set, otherwise chain. provider is the provider of nodes without an
+// llm_provider attribute, and defaults supplies the model for entries that do
+// not pin one (see -default-model).
+func backendChainHook(provider string, chain []backendEntry, defaults map[string]string) func(*pipeline.HandlerRegistry) {
+	return func(registry *pipeline.HandlerRegistry) {
+		if inner := registry.Get("codergen"); inner != nil {
+			registry.Register(&backendChainHandler{inner: inner, provider: provider, chain: chain, defaults: defaults})
+		}
+	}
+}
+
+// backendChainHandler attaches a backendFallback to the context of nodes with
+// a chain and records the serving provider in the node's context updates.
+type backendChainHandler struct {
+	inner    pipeline.Handler
+	provider string
+	chain    []backendEntry
+	defaults map[string]string
+}
+
+func (h *backendChainHandler) Name() string { return h.inner.Name() }
+
+func (h *backendChainHandler) Execute(ctx context.Context, node *pipeline.Node, pctx *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	chain := h.chain
+	if raw, ok := node.Attrs["backend_chain"]; ok {
+		var err error
+		if chain, err = parseBackendChain(raw); err != nil {
+			return pipeline.Outcome{}, fmt.Errorf("node %q: %w", node.ID, err)
+		}
+	}
+	if len(chain) == 0 {
+		return h.inner.Execute(ctx, node, pctx)
+	}
+
+	nodeProvider := strings.ToLower(strings.TrimSpace(node.Attrs["llm_provider"]))
+	if nodeProvider == "" {
+		nodeProvider = h.provider
+	}
+	resolved, err := resolveBackendChain(chain, nodeProvider, h.defaults)
+	if err != nil {
+		return pipeline.Outcome{}, fmt.Errorf("node %q: %w", node.ID, err)
+	}
+	fb := &backendFallback{chain: resolved}
+	outcome, err := h.inner.Execute(context.WithValue(ctx, backendFallbackKey{}, fb), node, pctx)
+	if fb.servedBy != "" {
+		if outcome.ContextUpdates == nil {
+			outcome.ContextUpdates = map[string]string{}
+		}
+		outcome.ContextUpdates[servedByContextPrefix+node.ID] = fb.servedBy
+	}
+	return outcome, err
+}
+
+// fallbackCompleter sends requests from nodes with a fallback chain to each
+// provider in turn, moving on only when a provider answers with a server
+// error or stays rate-limited after the client's retries. Other errors are
+// returned as-is. Requests without a chain pass straight through.
+type fallbackCompleter struct {
+	inner agent.Completer
+}
+
+func (c *fallbackCompleter) Complete(ctx context.Context, req *trackerllm.Request) (*trackerllm.Response, error) {
+	fb, ok := ctx.Value(backendFallbackKey{}).(*backendFallback)
+	if !ok {
+		return c.inner.Complete(ctx, req)
+	}
+
+	var lastErr error
+	for i, entry := range fb.chain {
+		attempt := *req
+		attempt.Provider = entry.provider
+		if entry.model != "" {
+			attempt.Model = entry.model
+		}
+		resp, err := c.inner.Complete(ctx, &attempt)
+		if err == nil {
+			fb.servedBy = entry.provider
+			return resp, nil
+		}
+		if !isFailoverError(err) {
+			return nil, err
+		}
+		lastErr = err
+		if i+1 < len(fb.chain) {
+			fmt.Fprintf(os.Stderr, "warning: %s failed (%v); falling back to %s\n", entry.provider, err, fb.chain[i+1].provider)
+		}
+	}
+	return nil, fmt.Errorf("every backend in the chain failed: %w", lastErr)
+}
+
+// isFailoverError reports whether err should move a request to the next
+// backend in the chain.
+func isFailoverError(err error) bool {
+	var serverErr *trackerllm.ServerError
+	var rateErr *trackerllm.RateLimitError
+	return errors.As(err, &serverErr) || errors.As(err, &rateErr)
+}
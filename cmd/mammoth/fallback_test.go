@@ -0,0 +1,179 @@
+// ABOUTME: Tests for the backend fallback chain: parsing, failover on server errors, and the served_by record.
+// ABOUTME: Uses a stub completer whose providers either always 503 or answer.
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	trackerllm "github.com/2389-research/tracker/llm"
+)
+
+// providerStubCompleter answers as req.Provider, failing with errs[provider]
+// when set, and records the provider/model of every request.
+type providerStubCompleter struct {
+	errs map[string]error
+
+	mu    sync.Mutex
+	calls []string
+}
+
+func (c *providerStubCompleter) Complete(_ context.Context, req *trackerllm.Request) (*trackerllm.Response, error) {
+	c.mu.Lock()
+	c.calls = append(c.calls, req.Provider+"/"+req.Model)
+	c.mu.Unlock()
+	if err := c.errs[req.Provider]; err != nil {
+		return nil, err
+	}
+	return &trackerllm.Response{
+		Provider:     req.Provider,
+		Message:      trackerllm.AssistantMessage("done"),
+		FinishReason: trackerllm.FinishReason{Reason: "stop"},
+	}, nil
+}
+
+func unavailable(provider string) error {
+	return &trackerllm.ServerError{ProviderError: trackerllm.ProviderError{
+		SDKError:   trackerllm.SDKError{Msg: "service unavailable"},
+		Provider:   provider,
+		StatusCode: 503,
+	}}
+}
+
+func TestParseBackendChain(t *testing.T) {
+	chain, err := parseBackendChain(" Anthropic , openai=gpt-4o,")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []backendEntry{{provider: "anthropic"}, {provider: "openai", model: "gpt-4o"}}
+	if !slices.Equal(chain, want) {
+		t.Errorf("chain = %+v, want %+v", chain, want)
+	}
+	if chain, err := parseBackendChain(""); err != nil || chain != nil {
+		t.Errorf("empty spec = %v, %v; want nil, nil", chain, err)
+	}
+	for _, bad := range []string{"=gpt-4o", "openai="} {
+		if _, err := parseBackendChain(bad); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestBackendChainFailsOverOnServerError(t *testing.T) {
+	source := `digraph p {
+		start [shape=Mdiamond]
+		work [shape=box, prompt="plan"]
+		done [shape=Msquare]
+		start -> work -> done
+	}`
+	client := &providerStubCompleter{errs: map[string]error{"anthropic": unavailable("anthropic")}}
+	chain := []backendEntry{{provider: "anthropic"}, {provider: "openai", model: "gpt-4o"}}
+	engine, _, err := buildPipelineEngine(source, t.TempDir(), client, "", "", "", nil, nil, backendChainHook("anthropic", chain, nil))
+	if err != nil {
+		t.Fatalf("build engine: %v", err)
+	}
+	result, err := engine.Run(context.Background())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if got := result.Context[servedByContextPrefix+"work"]; got != "openai" {
+		t.Errorf("served_by.work = %q, want openai", got)
+	}
+	if len(client.calls) < 2 || !strings.HasPrefix(client.calls[0], "anthropic/") || client.calls[1] != "openai/gpt-4o" {
+		t.Errorf("calls = %v, want anthropic then openai/gpt-4o", client.calls)
+	}
+}
+
+func TestBackendChainNodeOverrideAndNonRetryableError(t *testing.T) {
+	source := `digraph p {
+		start [shape=Mdiamond]
+		work [shape=box, prompt="plan", llm_provider="gemini", backend_chain="gemini,openai"]
+		done [shape=Msquare]
+		start -> work -> done
+	}`
+	authErr := &trackerllm.AuthenticationError{ProviderError: trackerllm.ProviderError{
+		SDKError: trackerllm.SDKError{Msg: "bad key"}, Provider: "gemini", StatusCode: 401,
+	}}
+	client := &providerStubCompleter{errs: map[string]error{"gemini": authErr}}
+	fb := &backendFallback{chain: []backendEntry{{provider: "gemini"}, {provider: "openai"}}}
+	ctx := context.WithValue(context.Background(), backendFallbackKey{}, fb)
+
+	_, err := (&fallbackCompleter{inner: client}).Complete(ctx, &trackerllm.Request{})
+	if !errors.Is(err, authErr) {
+		t.Errorf("err = %v, want the authentication error unchanged", err)
+	}
+	if len(client.calls) != 1 {
+		t.Errorf("calls = %v: a non-retryable error must not fail over", client.calls)
+	}
+
+	// The node's attribute replaces the default chain.
+	client = &providerStubCompleter{errs: map[string]error{"gemini": unavailable("gemini")}}
+	engine, _, err := buildPipelineEngine(source, t.TempDir(), client, "", "", "", nil, nil,
+		backendChainHook("anthropic", []backendEntry{{provider: "anthropic"}}, map[string]string{"openai": "gpt-4o"}))
+	if err != nil {
+		t.Fatalf("build engine: %v", err)
+	}
+	result, err := engine.Run(context.Background())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if got := result.Context[servedByContextPrefix+"work"]; got != "openai" {
+		t.Errorf("served_by.work = %q, want openai", got)
+	}
+	if !strings.HasPrefix(client.calls[0], "gemini/") || client.calls[1] != "openai/gpt-4o" {
+		t.Errorf("calls = %v, want gemini then openai with its default model", client.calls)
+	}
+}
+
+func TestBackendChainRequiresModelForOtherProviders(t *testing.T) {
+	chain := []backendEntry{{provider: "anthropic"}, {provider: "openai"}}
+	_, err := resolveBackendChain(chain, "anthropic", nil)
+	if err == nil || !strings.Contains(err.Error(), "openai=<model>") {
+		t.Errorf("err = %v, want openai to need a model for an anthropic node", err)
+	}
+	resolved, err := resolveBackendChain(chain, "anthropic", map[string]string{"openai": "gpt-4o"})
+	if err != nil {
+		t.Fatalf("resolve with defaults: %v", err)
+	}
+	want := []backendEntry{{provider: "anthropic"}, {provider: "openai", model: "gpt-4o"}}
+	if !slices.Equal(resolved, want) {
+		t.Errorf("resolved = %+v, want %+v", resolved, want)
+	}
+
+	// A node pinned to another provider is checked when it runs, before any
+	// request reaches a backend.
+	source := `digraph p {
+		start [shape=Mdiamond]
+		work [shape=box, prompt="plan", llm_provider="openai", llm_model="gpt-4o"]
+		done [shape=Msquare]
+		start -> work -> done
+	}`
+	client := &providerStubCompleter{}
+	engine, _, err := buildPipelineEngine(source, t.TempDir(), client, "", "", "", nil, nil,
+		backendChainHook("anthropic", []backendEntry{{provider: "openai"}, {provider: "anthropic"}}, nil))
+	if err != nil {
+		t.Fatalf("build engine: %v", err)
+	}
+	if _, err := engine.Run(context.Background()); err == nil || !strings.Contains(err.Error(), `backend "anthropic" needs a model`) {
+		t.Errorf("run err = %v, want the unresolved anthropic entry reported", err)
+	}
+	if len(client.calls) != 0 {
+		t.Errorf("calls = %v, want none", client.calls)
+	}
+}
+
+func TestFallbackCompleterWithoutChainPassesThrough(t *testing.T) {
+	client := &providerStubCompleter{}
+	req := &trackerllm.Request{Provider: "anthropic", Model: "m"}
+	if _, err := (&fallbackCompleter{inner: client}).Complete(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(client.calls, []string{"anthropic/m"}) {
+		t.Errorf("calls = %v, want the request unchanged", client.calls)
+	}
+}
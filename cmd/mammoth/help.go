@@ -40,35 +40,77 @@ func printHelp(w io.Writer, ver string) {
 
 	fmt.Fprintln(w, "Usage:")
 	fmt.Fprintln(w, "  mammoth [run] <pipeline.dot>        Run a pipeline")
+	fmt.Fprintln(w, "  mammoth run <a.dot> <b.dot> ...     Run several pipelines as a batch (-concurrency n)")
 	fmt.Fprintln(w, "  mammoth -validate <pipeline.dot>    Validate without executing")
 	fmt.Fprintln(w, "  mammoth -validate -fix <file.dot>   Auto-fix validation warnings")
 	fmt.Fprintln(w, "  mammoth serve              Start web UI (local mode: CWD is project root)")
 	fmt.Fprintln(w, "  mammoth serve --global     Start web UI (global mode: ~/.local/share/mammoth)")
+	fmt.Fprintln(w, "  mammoth init <pipeline.dot>         Write a commented starter pipeline")
 	fmt.Fprintln(w, "  mammoth setup                       Interactive setup wizard (XDG config)")
 	fmt.Fprintln(w, "  mammoth audit [runID]               Audit a pipeline run")
+	fmt.Fprintln(w, "  mammoth diff <runA> <runB>          Compare two pipeline runs")
+	fmt.Fprintln(w, "  mammoth top [--server <url>]        Live dashboard of a server's runs")
+	fmt.Fprintln(w, "  mammoth logs [--since t] [--tail n] [runID]  Print a run's events")
+	fmt.Fprintln(w, "  mammoth version [--json]            Print the version (--json: with commit, build date, Go version)")
 	fmt.Fprintln(w)
 
 	fmt.Fprintln(w, "Pipeline Flags:")
 	fmt.Fprintln(w, "  -retry <policy>       none, standard, aggressive, linear, patient (default: none)")
 	fmt.Fprintln(w, "  -artifact-dir <dir>   Directory for artifact storage (default: current directory)")
+	fmt.Fprintln(w, "  -artifact-layout <t>  Artifact subdirectory template: {date}, {timestamp}, {pipeline}, {run_id}")
+	fmt.Fprintln(w, "  -timestamped          Put each run in its own timestamped subdirectory of -artifact-dir")
 	fmt.Fprintln(w, "  -data-dir <dir>       Persistent state directory (default: .mammoth/ in CWD)")
 	fmt.Fprintln(w, "  -tui                  Run with interactive terminal UI")
+	fmt.Fprintln(w, "  -concurrency <n>      With several pipeline files, how many run at once (default: 1)")
+	fmt.Fprintln(w, "  -entry <node>         Start node to begin from when the graph has several")
 	fmt.Fprintln(w, "  -verbose              Verbose output")
+	fmt.Fprintln(w, "  -verbose-level <n>    Verbose detail: 1 lifecycle, 2 adds retries/loops/steering, 3 adds tool calls and turns")
+	fmt.Fprintln(w, "  -record <file>        Record backend and human-gate outcomes to a JSON file")
+	fmt.Fprintln(w, "  -replay <file>        Replay recorded outcomes instead of calling the backend")
+	fmt.Fprintln(w, "  -only <nodes>         Execute only these nodes (comma-separated); others count as satisfied")
+	fmt.Fprintln(w, "  -skip <nodes>         Treat these nodes (comma-separated) as satisfied without executing")
+	fmt.Fprintln(w, "  -clean-on-success     Delete the run's artifacts after success, keeping a manifest")
+	fmt.Fprintln(w, "  -dedup-artifacts      Store identical artifact files once (content-addressed)")
+	fmt.Fprintln(w, "  -max-artifact-bytes  Fail nodes that push run artifacts past this many bytes (0: unlimited)")
+	fmt.Fprintln(w, "  -default-model <map>  Per-provider default models (provider=model,...)")
+	fmt.Fprintln(w, "  -base-url <url>       LLM API base URL for providers without a specific override")
+	fmt.Fprintln(w, "  -base-urls <map>      Per-provider LLM API base URLs (provider=url,...)")
+	fmt.Fprintln(w, "  -max-runtime <dur>    Cancel the run, checkpointed and resumable, after this much wall-clock time")
+	fmt.Fprintln(w, "  -cancel-grace <dur>   On interrupt, let the running agent node record a partial outcome first")
+	fmt.Fprintln(w, "  -backend <name>       agent (default, from API keys) or stub (deterministic, offline)")
+	fmt.Fprintln(w, "  -backend-chain <list> Providers to fail over through on server errors (provider[=model],...)")
+	fmt.Fprintln(w, "  -preflight            Check the LLM provider is reachable before running; fail fast if not")
+	fmt.Fprintln(w, "  -preflight-timeout    How long -preflight waits for a response (default 5s)")
+	fmt.Fprintln(w, "  -cache-dir <dir>      Cache LLM responses and serve identical requests from disk")
+	fmt.Fprintln(w, "  -seed <n>             Sampling seed for providers that accept one (recorded in run state)")
+	fmt.Fprintln(w, "  -set <key=value>      Seed the pipeline context before the start node runs (repeatable)")
+	fmt.Fprintln(w, "  -profile <file>       Write a CPU profile (pprof) of the run")
+	fmt.Fprintln(w, "  -trace <file>         Write a runtime execution trace of the run")
 	fmt.Fprintln(w)
 
 	fmt.Fprintln(w, "Serve Flags:")
 	fmt.Fprintln(w, "  -port <port>          Server port (default: 2389)")
+	fmt.Fprintln(w, "  -max-llm-concurrency  Max in-flight LLM requests across all runs; excess queue (0: unlimited)")
+	fmt.Fprintln(w, "  -max-event-history    Events each build keeps in memory for live replay (default: 300)")
+	fmt.Fprintln(w, "  -node-webhook <url>   POST a JSON callback as each node starts and completes")
+	fmt.Fprintln(w, "  -audit-decisions      Record every human gate answer in a per-run decisions.jsonl audit log")
+	fmt.Fprintln(w, "  -trusted-proxy <ips>  Proxies (addresses or CIDRs) whose X-Forwarded-User names who answered a gate")
+	fmt.Fprintln(w, "  -show-reasoning       Show the model's redacted reasoning in build events (privacy-sensitive; off by default)")
 	fmt.Fprintln(w)
 
 	fmt.Fprintln(w, "Other:")
 	fmt.Fprintln(w, "  -validate             Validate pipeline without executing")
 	fmt.Fprintln(w, "  -fix                  Auto-fix validation warnings (use with -validate)")
+	fmt.Fprintln(w, "  -fail-on <severity>   Lowest severity that fails -validate: error, warning, info")
 	fmt.Fprintln(w, "  -verbose              Include full tool call details (audit)")
+	fmt.Fprintln(w, "  -config <file>        YAML file of flag defaults (default: ./mammoth.yaml if present)")
+	fmt.Fprintln(w, "  -env-file <file>      Load this dotenv file instead of auto-discovered .env files (repeatable; later files win)")
 	fmt.Fprintln(w, "  -version              Print version and exit")
 	fmt.Fprintln(w, "  -help                 Show this help")
 	fmt.Fprintln(w)
 
 	fmt.Fprintln(w, "Examples:")
+	fmt.Fprintln(w, "  mammoth init -template deploy deploy.dot")
 	fmt.Fprintln(w, "  mammoth examples/simple.dot")
 	fmt.Fprintln(w, "  mammoth -validate my_pipeline.dot")
 	fmt.Fprintln(w, "  mammoth -validate -fix my_pipeline.dot")
@@ -79,6 +121,8 @@ func printHelp(w io.Writer, ver string) {
 	fmt.Fprintln(w, "  mammoth serve --global --port 3000")
 	fmt.Fprintln(w, "  mammoth audit")
 	fmt.Fprintln(w, "  mammoth audit --verbose ebbe59cd241c09df")
+	fmt.Fprintln(w, "  mammoth diff --json ebbe59cd241c09df 4f1c2a9e0b7d3385")
+	fmt.Fprintln(w, "  mammoth logs --since 2025-06-15T10:00:00Z --tail 20 ebbe59cd241c09df")
 	fmt.Fprintln(w)
 
 	fmt.Fprintln(w, "Setup:")
@@ -89,6 +133,10 @@ func printHelp(w io.Writer, ver string) {
 	fmt.Fprintln(w, "  Config is stored in ~/.config/mammoth/config.env (XDG_CONFIG_HOME).")
 	fmt.Fprintln(w, "  Local .env files and environment variables take priority over XDG config.")
 	fmt.Fprintln(w)
+	fmt.Fprintln(w, "  Any pipeline flag can default from MAMMOTH_<FLAG> (e.g. MAMMOTH_ARTIFACT_DIR)")
+	fmt.Fprintln(w, "  or a mammoth.yaml key (e.g. artifact-dir: ./out). Precedence: flag > env > file.")
+	fmt.Fprintln(w, "  -default-model reads MAMMOTH_DEFAULT_MODELS, not MAMMOTH_DEFAULT_MODEL.")
+	fmt.Fprintln(w)
 
 	fmt.Fprintln(w, "Environment:")
 	fmt.Fprintf(w, "  ANTHROPIC_API_KEY     %s\n", envStatus("ANTHROPIC_API_KEY"))
@@ -97,8 +145,9 @@ func printHelp(w io.Writer, ver string) {
 	fmt.Fprintf(w, "  ANTHROPIC_BASE_URL    %s\n", envStatus("ANTHROPIC_BASE_URL"))
 	fmt.Fprintf(w, "  OPENAI_BASE_URL       %s\n", envStatus("OPENAI_BASE_URL"))
 	fmt.Fprintf(w, "  GEMINI_BASE_URL       %s\n", envStatus("GEMINI_BASE_URL"))
+	fmt.Fprintf(w, "  MAMMOTH_DEFAULT_MODELS %s\n", envStatus("MAMMOTH_DEFAULT_MODELS"))
 	fmt.Fprintln(w)
-	fmt.Fprintln(w, "  At least one API key is required for pipeline execution.")
+	fmt.Fprintln(w, "  At least one API key is required for pipelines with LLM (codergen) nodes.")
 	fmt.Fprintln(w)
 
 	fmt.Fprintln(w, "Docs: https://github.com/2389-research/mammoth")
@@ -0,0 +1,119 @@
+// ABOUTME: "mammoth init" subcommand writing a commented starter pipeline to edit.
+// ABOUTME: Templates (minimal, review, deploy) are embedded DOT files; existing files need -force.
+package main
+
+import (
+	"embed"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+//go:embed templates/*.dot
+var pipelineTemplates embed.FS
+
+// defaultInitTemplate shows every common node kind: start, agent, human gate,
+// conditional branch, goal gate, and exit.
+const defaultInitTemplate = "review"
+
+// initConfig holds configuration for the "mammoth init" subcommand.
+type initConfig struct {
+	path     string
+	template string
+	force    bool
+}
+
+// parseInitArgs checks whether args starts with the "init" subcommand and,
+// if so, parses init-specific flags. Returns the config and true if "init"
+// was detected, or a zero value and false otherwise.
+func parseInitArgs(args []string) (initConfig, bool) {
+	if len(args) == 0 || args[0] != "init" {
+		return initConfig{}, false
+	}
+
+	var cfg initConfig
+	fs := flag.NewFlagSet("mammoth init", flag.ContinueOnError)
+	fs.StringVar(&cfg.template, "template", defaultInitTemplate, "Starter template: "+strings.Join(initTemplateNames(), ", "))
+	fs.BoolVar(&cfg.force, "force", false, "Overwrite the file if it already exists")
+
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: mammoth init [flags] <pipeline.dot>")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Write a commented starter pipeline, ready to edit and run.")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Flags:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		os.Exit(2)
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	cfg.path = fs.Arg(0)
+
+	return cfg, true
+}
+
+// initTemplateNames returns the available template names, sorted.
+func initTemplateNames() []string {
+	entries, _ := pipelineTemplates.ReadDir("templates")
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), ".dot"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// initTemplate returns the DOT source of the named template.
+func initTemplate(name string) ([]byte, error) {
+	data, err := pipelineTemplates.ReadFile("templates/" + name + ".dot")
+	if err != nil {
+		return nil, fmt.Errorf("unknown template %q (want %s)", name, strings.Join(initTemplateNames(), ", "))
+	}
+	return data, nil
+}
+
+// writeStarterPipeline writes the named template to path. It refuses to
+// replace an existing file unless force is set.
+func writeStarterPipeline(path, template string, force bool) error {
+	data, err := initTemplate(template)
+	if err != nil {
+		return err
+	}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	}
+	f, err := os.OpenFile(path, flags, 0644)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("%s already exists (use -force to overwrite)", path)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// runInit writes the starter pipeline. Returns 0 on success, 1 on error.
+func runInit(cfg initConfig) int {
+	if err := writeStarterPipeline(cfg.path, cfg.template, cfg.force); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Wrote %s (%s template). Edit the goal and prompts, then run: mammoth %s\n", cfg.path, cfg.template, cfg.path)
+	return 0
+}
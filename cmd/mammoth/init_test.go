@@ -0,0 +1,112 @@
+// ABOUTME: Tests for "mammoth init": every template parses and validates cleanly, and files are not clobbered.
+// ABOUTME: Checks both the dot/ linter and the tracker runtime parser.
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/2389-research/mammoth/dot"
+	"github.com/2389-research/mammoth/dot/validator"
+	"github.com/2389-research/tracker/pipeline"
+)
+
+func TestInitTemplatesValidate(t *testing.T) {
+	names := initTemplateNames()
+	if !slices.Equal(names, []string{"deploy", "minimal", "review"}) {
+		t.Fatalf("templates = %v, want deploy, minimal, review", names)
+	}
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name+".dot")
+			if err := writeStarterPipeline(path, name, false); err != nil {
+				t.Fatalf("writeStarterPipeline: %v", err)
+			}
+			source, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read: %v", err)
+			}
+
+			g, err := dot.Parse(string(source))
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			for _, d := range validator.Lint(g) {
+				t.Errorf("[%s] %s (%s)", d.Severity, d.Message, d.Rule)
+			}
+			if _, err := pipeline.ParseDOT(string(source)); err != nil {
+				t.Errorf("tracker parse: %v", err)
+			}
+		})
+	}
+}
+
+func TestInitDefaultTemplateCoversNodeKinds(t *testing.T) {
+	source, err := initTemplate(defaultInitTemplate)
+	if err != nil {
+		t.Fatalf("initTemplate: %v", err)
+	}
+	g, err := dot.Parse(string(source))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	kinds := map[string]bool{}
+	goalGate := false
+	for _, n := range g.Nodes {
+		kinds[dot.NodeType(n)] = true
+		goalGate = goalGate || n.Attrs["goal_gate"] == "true"
+	}
+	for _, want := range []string{"start", "codergen", "wait.human", "conditional", "exit"} {
+		if !kinds[want] {
+			t.Errorf("default template has no %s node", want)
+		}
+	}
+	if !goalGate {
+		t.Error("default template has no goal gate")
+	}
+}
+
+func TestInitRefusesToOverwrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "existing.dot")
+	if err := os.WriteFile(path, []byte("keep me"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := writeStarterPipeline(path, "minimal", false)
+	if err == nil || !strings.Contains(err.Error(), "-force") {
+		t.Fatalf("err = %v, want a refusal mentioning -force", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "keep me" {
+		t.Errorf("existing file was modified: %q", data)
+	}
+
+	if err := writeStarterPipeline(path, "minimal", true); err != nil {
+		t.Fatalf("with force: %v", err)
+	}
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), "digraph Minimal") {
+		t.Errorf("force did not write the template: %q", data)
+	}
+}
+
+func TestInitUnknownTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "p.dot")
+	if err := writeStarterPipeline(path, "nope", false); err == nil {
+		t.Error("expected an error for an unknown template")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("no file should be written for an unknown template")
+	}
+}
+
+func TestParseInitArgs(t *testing.T) {
+	if _, ok := parseInitArgs([]string{"run", "p.dot"}); ok {
+		t.Error("non-init args should not parse as init")
+	}
+	cfg, ok := parseInitArgs([]string{"init", "-template", "deploy", "-force", "p.dot"})
+	if !ok || cfg.path != "p.dot" || cfg.template != "deploy" || !cfg.force {
+		t.Errorf("parseInitArgs = %+v, %v", cfg, ok)
+	}
+}
@@ -0,0 +1,41 @@
+// ABOUTME: Initial pipeline context from repeatable -set key=value flags, seeded before the start node runs.
+// ABOUTME: Values are typed by runstate.ParseContextValue, so bools and numbers reach every node in canonical form.
+package main
+
+import (
+	"strings"
+
+	"github.com/2389-research/mammoth/runstate"
+)
+
+// contextAssignments collects the repeatable -set flag, one key=value per use.
+type contextAssignments []string
+
+func (a *contextAssignments) String() string {
+	if a == nil {
+		return ""
+	}
+	return strings.Join(*a, " ")
+}
+
+func (a *contextAssignments) Set(s string) error {
+	if _, _, err := runstate.ParseContextAssignment(s); err != nil {
+		return err
+	}
+	*a = append(*a, s)
+	return nil
+}
+
+// parseInitialContext turns -set assignments into the context the run
+// starts with. A key set twice takes its last value.
+func parseInitialContext(sets []string) (map[string]string, error) {
+	values := make(map[string]any, len(sets))
+	for _, s := range sets {
+		key, value, err := runstate.ParseContextAssignment(s)
+		if err != nil {
+			return nil, err
+		}
+		values[key] = value
+	}
+	return runstate.InitialContext(values)
+}
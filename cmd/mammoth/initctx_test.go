@@ -0,0 +1,76 @@
+// ABOUTME: Tests for -set initial context: flag parsing, typing, and seeding the run before the start node.
+// ABOUTME: Checks injected values reach the first codergen node and remain in the final context snapshot.
+package main
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/2389-research/mammoth/redact"
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// contextProbe is a codergen stand-in that records the context each node saw.
+type contextProbe struct {
+	seen map[string]map[string]string
+}
+
+func (contextProbe) Name() string { return "codergen" }
+
+func (p *contextProbe) Execute(_ context.Context, node *pipeline.Node, pctx *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	p.seen[node.ID] = pctx.Snapshot()
+	return pipeline.Outcome{Status: pipeline.OutcomeSuccess}, nil
+}
+
+func TestParseFlagsSetRepeatable(t *testing.T) {
+	origArgs := os.Args
+	defer func() { os.Args = origArgs }()
+
+	os.Args = []string{"mammoth", "-set", "ticket=ENG-12", "-set", "dry_run=TRUE", "pipeline.dot"}
+	cfg := parseFlags()
+	if want := (contextAssignments{"ticket=ENG-12", "dry_run=TRUE"}); !reflect.DeepEqual(cfg.sets, want) {
+		t.Errorf("sets = %v, want %v", cfg.sets, want)
+	}
+}
+
+func TestParseInitialContext(t *testing.T) {
+	got, err := parseInitialContext([]string{"ticket=ENG-12", "retries=3", "dry_run=TRUE", "retries=4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"ticket": "ENG-12", "retries": "4", "dry_run": "true"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("initial context = %v, want %v", got, want)
+	}
+	if _, err := parseInitialContext([]string{"graph.goal=x"}); err == nil {
+		t.Error("graph.* keys should be rejected")
+	}
+}
+
+func TestInitialContextReachesFirstCodergenNode(t *testing.T) {
+	clearLLMKeys(t)
+	initial, err := parseInitialContext([]string{"ticket=ENG-12", "branch=main", "dry_run=true"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	probe := &contextProbe{seen: map[string]map[string]string{}}
+	hook := func(r *pipeline.HandlerRegistry) { r.Register(probe) }
+	engine, _, err := buildSeededPipelineEngine(initial, redact.Default(), stubDOT, t.TempDir(), nil, "", "", "", nil, nil, hook)
+	if err != nil {
+		t.Fatalf("build engine: %v", err)
+	}
+	result, err := engine.Run(context.Background())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	for key, want := range initial {
+		if got := probe.seen["plan"][key]; got != want {
+			t.Errorf("first codergen node saw %s = %q, want %q", key, got, want)
+		}
+		if got := result.Context[key]; got != want {
+			t.Errorf("final context %s = %q, want %q", key, got, want)
+		}
+	}
+}
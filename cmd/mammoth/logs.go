@@ -0,0 +1,184 @@
+// ABOUTME: "mammoth logs" subcommand printing a stored run's events, like kubectl logs.
+// ABOUTME: --since keeps events at or after a timestamp (or within a duration of now); --tail keeps the last N.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/2389-research/mammoth/runstate"
+)
+
+// logsConfig holds configuration for the "mammoth logs" subcommand.
+type logsConfig struct {
+	runID   string
+	since   string
+	tail    int
+	jsonOut bool
+	dataDir string
+}
+
+// parseLogsArgs checks whether args starts with the "logs" subcommand and,
+// if so, parses logs-specific flags. Returns the config and true if "logs"
+// was detected, or a zero value and false otherwise.
+func parseLogsArgs(args []string) (logsConfig, bool) {
+	if len(args) == 0 || args[0] != "logs" {
+		return logsConfig{}, false
+	}
+
+	var cfg logsConfig
+	fs := flag.NewFlagSet("mammoth logs", flag.ContinueOnError)
+	fs.StringVar(&cfg.since, "since", "", "Only events at or after this RFC 3339 timestamp, or within this duration of now (e.g. 10m)")
+	fs.IntVar(&cfg.tail, "tail", -1, "Only the last N events (-1: all)")
+	fs.BoolVar(&cfg.jsonOut, "json", false, "Print events as JSON lines")
+	fs.StringVar(&cfg.dataDir, "data-dir", "", "Data directory (default: .mammoth/ in CWD)")
+
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: mammoth logs [flags] [runID]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Print a pipeline run's events in order.")
+		fmt.Fprintln(os.Stderr, "With no runID, shows the most recent run.")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Flags:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		os.Exit(2)
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	cfg.runID = fs.Arg(0)
+
+	return cfg, true
+}
+
+// runLogs prints the filtered events of a stored run. Returns 0 when the
+// events were printed, 1 on error.
+func runLogs(cfg logsConfig) int {
+	return writeRunLogs(os.Stdout, cfg, time.Now())
+}
+
+// writeRunLogs does the work of runLogs, writing to w and resolving a
+// relative --since against now.
+func writeRunLogs(w io.Writer, cfg logsConfig, now time.Time) int {
+	since, err := parseSince(cfg.since, now)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+
+	dataDir := cfg.dataDir
+	if dataDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return 1
+		}
+		dataDir = filepath.Join(cwd, ".mammoth")
+	}
+	runsDir := filepath.Join(dataDir, "runs")
+	store, err := runstate.NewFSRunStateStore(runsDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: could not open run store: %v\n", err)
+		return 1
+	}
+
+	runID := cfg.runID
+	if runID == "" {
+		states, err := store.List()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return 1
+		}
+		if len(states) == 0 {
+			fmt.Fprintln(os.Stderr, "error: no runs found in", runsDir)
+			return 1
+		}
+		sort.Slice(states, func(i, j int) bool { return states[i].StartedAt.After(states[j].StartedAt) })
+		runID = states[0].ID
+	}
+	state, err := store.Get(runID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: could not load run %s: %v\n", runID, err)
+		return 1
+	}
+
+	events := filterRunEvents(state.Events, since, cfg.tail)
+	if cfg.jsonOut {
+		enc := json.NewEncoder(w)
+		for _, evt := range events {
+			if err := enc.Encode(evt); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				return 1
+			}
+		}
+		return 0
+	}
+	for _, evt := range events {
+		fmt.Fprintln(w, formatRunEvent(evt))
+	}
+	return 0
+}
+
+// parseSince reads a --since value: an RFC 3339 timestamp, or a duration
+// counted back from now. An empty value means no lower bound.
+func parseSince(raw string, now time.Time) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(raw); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: want an RFC 3339 timestamp or a duration like 10m", raw)
+}
+
+// filterRunEvents keeps the events at or after since (events without a
+// timestamp are dropped once a bound is set), then the last tail of those.
+// A negative tail keeps them all. Order is preserved.
+func filterRunEvents(events []runstate.RunEvent, since time.Time, tail int) []runstate.RunEvent {
+	var out []runstate.RunEvent
+	for _, evt := range events {
+		if !since.IsZero() && (evt.Timestamp.IsZero() || evt.Timestamp.Before(since)) {
+			continue
+		}
+		out = append(out, evt)
+	}
+	if tail >= 0 && len(out) > tail {
+		out = out[len(out)-tail:]
+	}
+	return out
+}
+
+// formatRunEvent renders one event as a log line: timestamp, type, node,
+// and message when present.
+func formatRunEvent(evt runstate.RunEvent) string {
+	var b strings.Builder
+	b.WriteString(evt.Timestamp.Format(time.RFC3339Nano))
+	b.WriteString("  ")
+	b.WriteString(evt.Type)
+	if evt.NodeID != "" {
+		b.WriteString("  node=" + evt.NodeID)
+	}
+	if msg, ok := evt.Data["message"]; ok {
+		b.WriteString(fmt.Sprintf("  %v", msg))
+	}
+	return b.String()
+}
@@ -0,0 +1,123 @@
+// ABOUTME: Tests for the "mammoth logs" subcommand printing a stored run's events.
+// ABOUTME: Uses a pre-populated run store to assert --since and --tail filtering and ordering.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/2389-research/mammoth/runstate"
+)
+
+var logsBase = time.Date(2025, 6, 15, 10, 0, 0, 0, time.UTC)
+
+// seedLogsStore writes a run with one event per minute from 09:58 to 10:02
+// and returns its data directory.
+func seedLogsStore(t *testing.T) string {
+	t.Helper()
+	dataDir := t.TempDir()
+	store, err := runstate.NewFSRunStateStore(filepath.Join(dataDir, "runs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := &runstate.RunState{ID: "run-1", Status: "completed", StartedAt: logsBase, Context: map[string]string{}}
+	if err := store.Create(state); err != nil {
+		t.Fatal(err)
+	}
+	for i, node := range []string{"a", "b", "c", "d", "e"} {
+		evt := runstate.RunEvent{
+			Type:      "stage_completed",
+			NodeID:    node,
+			Timestamp: logsBase.Add(time.Duration(i-2) * time.Minute),
+			Data:      map[string]any{"message": "node " + node + " completed"},
+		}
+		if err := store.AddEvent(state.ID, evt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dataDir
+}
+
+// logNodes runs the logs subcommand with JSON output and returns the node
+// IDs of the printed events, in order.
+func logNodes(t *testing.T, cfg logsConfig) []string {
+	t.Helper()
+	cfg.jsonOut = true
+	var out bytes.Buffer
+	if code := writeRunLogs(&out, cfg, logsBase); code != 0 {
+		t.Fatalf("logs exit code = %d", code)
+	}
+	var nodes []string
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var evt runstate.RunEvent
+		if err := dec.Decode(&evt); err != nil {
+			t.Fatal(err)
+		}
+		nodes = append(nodes, evt.NodeID)
+	}
+	return nodes
+}
+
+func TestLogsSince(t *testing.T) {
+	dataDir := seedLogsStore(t)
+	tests := []struct {
+		since string
+		want  []string
+	}{
+		{"", []string{"a", "b", "c", "d", "e"}},
+		{"2025-06-15T10:00:00Z", []string{"c", "d", "e"}},
+		{"2025-06-15T10:01:30Z", []string{"e"}},
+		{"2025-06-15T12:00:00+02:00", []string{"c", "d", "e"}},
+		{"90s", []string{"b", "c", "d", "e"}},
+		{"2025-06-15T11:00:00Z", nil},
+	}
+	for _, tt := range tests {
+		got := logNodes(t, logsConfig{runID: "run-1", since: tt.since, tail: -1, dataDir: dataDir})
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("--since %q: nodes = %v, want %v", tt.since, got, tt.want)
+		}
+	}
+
+	var out bytes.Buffer
+	if code := writeRunLogs(&out, logsConfig{runID: "run-1", since: "yesterday", tail: -1, dataDir: dataDir}, logsBase); code == 0 {
+		t.Error("expected an invalid --since to fail")
+	}
+}
+
+func TestLogsTail(t *testing.T) {
+	dataDir := seedLogsStore(t)
+	if got := logNodes(t, logsConfig{runID: "run-1", tail: 2, dataDir: dataDir}); !reflect.DeepEqual(got, []string{"d", "e"}) {
+		t.Errorf("--tail 2: nodes = %v, want [d e]", got)
+	}
+	if got := logNodes(t, logsConfig{runID: "run-1", tail: 10, dataDir: dataDir}); len(got) != 5 {
+		t.Errorf("--tail 10: nodes = %v, want all 5", got)
+	}
+	if got := logNodes(t, logsConfig{runID: "run-1", tail: 0, dataDir: dataDir}); len(got) != 0 {
+		t.Errorf("--tail 0: nodes = %v, want none", got)
+	}
+	// --tail applies after --since.
+	got := logNodes(t, logsConfig{since: "2025-06-15T09:59:00Z", tail: 2, dataDir: dataDir})
+	if !reflect.DeepEqual(got, []string{"d", "e"}) {
+		t.Errorf("--since with --tail on the latest run: nodes = %v, want [d e]", got)
+	}
+}
+
+func TestLogsTextOutput(t *testing.T) {
+	dataDir := seedLogsStore(t)
+	var out bytes.Buffer
+	if code := writeRunLogs(&out, logsConfig{runID: "run-1", tail: 1, dataDir: dataDir}, logsBase); code != 0 {
+		t.Fatalf("logs exit code = %d", code)
+	}
+	want := "2025-06-15T10:02:00Z  stage_completed  node=e  node e completed\n"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+	if code := writeRunLogs(&out, logsConfig{runID: "missing", tail: -1, dataDir: dataDir}, logsBase); code == 0 {
+		t.Error("expected an unknown run to fail")
+	}
+}
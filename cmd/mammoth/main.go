@@ -7,26 +7,42 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"maps"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
+	"github.com/2389-research/mammoth/answerpattern"
+	"github.com/2389-research/mammoth/artifactcap"
 	"github.com/2389-research/mammoth/dot"
 	"github.com/2389-research/mammoth/dot/validator"
+	"github.com/2389-research/mammoth/genparams"
 	"github.com/2389-research/mammoth/llm"
+	"github.com/2389-research/mammoth/nextnode"
+	"github.com/2389-research/mammoth/nodelock"
+	"github.com/2389-research/mammoth/providerpin"
+	"github.com/2389-research/mammoth/redact"
+	"github.com/2389-research/mammoth/retrybackoff"
 	"github.com/2389-research/mammoth/runstate"
+	"github.com/2389-research/mammoth/shapemap"
+	"github.com/2389-research/mammoth/skipif"
+	"github.com/2389-research/mammoth/streaming"
+	"github.com/2389-research/mammoth/successif"
+	"github.com/2389-research/mammoth/tokenbudget"
+	"github.com/2389-research/mammoth/toolguard"
 	"github.com/2389-research/mammoth/tui"
 	"github.com/2389-research/mammoth/web"
 	"github.com/2389-research/tracker/agent"
 	"github.com/2389-research/tracker/agent/exec"
 	trackerllm "github.com/2389-research/tracker/llm"
-	"github.com/2389-research/tracker/llm/anthropic"
-	"github.com/2389-research/tracker/llm/google"
-	"github.com/2389-research/tracker/llm/openai"
 	"github.com/2389-research/tracker/pipeline"
 	"github.com/2389-research/tracker/pipeline/handlers"
 
@@ -37,28 +53,93 @@ var version = "dev"
 
 // config holds all CLI configuration parsed from flags and positional arguments.
 type config struct {
-	port          int
-	validateOnly  bool
-	fixMode       bool
-	tuiMode       bool
-	fresh         bool
-	artifactDir string
-	dataDir       string
-	retryPolicy   string
-	verbose       bool
-	showVersion   bool
-	pipelineFile  string
+	port           int
+	validateOnly   bool
+	fixMode        bool
+	tuiMode        bool
+	fresh          bool
+	artifactDir    string
+	artifactLayout string
+	timestamped    bool
+	dataDir        string
+	retryPolicy    string
+	verbose        bool
+	verboseLevel   int
+	showVersion    bool
+	pipelineFile   string
+	pipelineFiles  []string
+	concurrency    int
+	recordPath     string
+	replayPath     string
+	defaultModels  string
+	entry          string
+	onlyNodes      string
+	skipNodes      string
+	cpuProfile     string
+	tracePath      string
+	maxArtifacts   int64
+	cleanOnSuccess bool
+	dedupArtifacts bool
+	failOn         string
+	configPath     string
+	baseURL        string
+	baseURLs       string
+	seed           string
+	cancelGrace    time.Duration
+	maxRuntime     time.Duration
+	backendChain   string
+	cacheDir       string
+	backend        string
+	sets           contextAssignments
+	preflight      bool
+	preflightWait  time.Duration
+
+	// providerURLs is the per-provider base URL map resolved from baseURLs,
+	// the <PROVIDER>_BASE_URL env vars, and baseURL by run().
+	providerURLs map[string]string
+	// runSeed is the parsed seed, or nil when -seed is unset.
+	runSeed *int64
+	// initialContext is the context parsed from sets, seeded into every run.
+	initialContext map[string]string
+	// grace coordinates -cancel-grace for the current run; nil when unset.
+	grace *cancelGrace
+	// retryEvents receives the retries of nodes with their own retry
+	// backoff; nil when the run reports no events.
+	retryEvents pipeline.PipelineEventHandler
+	// batch is set on each run of a multi-file batch, which always uses
+	// direct (non-TUI) execution so concurrent runs do not share a terminal.
+	batch bool
 }
 
 // serveConfig holds configuration for the "mammoth serve" subcommand.
 type serveConfig struct {
-	port    int
-	dataDir string
-	global  bool
+	port             int
+	dataDir          string
+	global           bool
+	maxConcurrentLLM int
+	maxEventHistory  int
+	nodeWebhook      string
+	auditDecisions   bool
+	trustedProxies   string
+	showReasoning    bool
 }
 
 func main() {
-	loadDotEnvAuto()
+	envFiles, args, err := splitEnvFileArgs(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(2)
+	}
+	os.Args = append(os.Args[:1], args...)
+	if len(envFiles) > 0 {
+		if err := loadEnvFiles(envFiles); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		loadDotEnvAuto()
+	}
+	llm.UserAgent = "mammoth/" + version
 
 	// Check for subcommands before regular flag parsing, since they use
 	// their own flag sets and don't share flags with pipeline mode.
@@ -66,19 +147,33 @@ func main() {
 		if scfg, ok := parseServeArgs(os.Args[1:]); ok {
 			os.Exit(runServe(scfg))
 		}
+		if icfg, ok := parseInitArgs(os.Args[1:]); ok {
+			os.Exit(runInit(icfg))
+		}
 		if scfg, ok := parseSetupArgs(os.Args[1:]); ok {
 			os.Exit(runSetup(scfg))
 		}
 		if acfg, ok := parseAuditArgs(os.Args[1:]); ok {
 			os.Exit(runAudit(acfg))
 		}
+		if dcfg, ok := parseDiffArgs(os.Args[1:]); ok {
+			os.Exit(runDiff(dcfg))
+		}
+		if tcfg, ok := parseTopArgs(os.Args[1:]); ok {
+			os.Exit(runTop(tcfg))
+		}
+		if lcfg, ok := parseLogsArgs(os.Args[1:]); ok {
+			os.Exit(runLogs(lcfg))
+		}
+		if vcfg, ok := parseVersionArgs(os.Args[1:]); ok {
+			os.Exit(runVersion(os.Stdout, vcfg))
+		}
 	}
 
 	cfg := parseFlags()
 
 	if cfg.showVersion {
-		fmt.Printf("mammoth %s\n", version)
-		os.Exit(0)
+		os.Exit(runVersion(os.Stdout, versionConfig{}))
 	}
 
 	os.Exit(run(cfg))
@@ -92,33 +187,70 @@ func parseFlags() config {
 	fs.IntVar(&cfg.port, "port", 2389, "Server port (default: 2389)")
 	fs.BoolVar(&cfg.validateOnly, "validate", false, "Validate pipeline without executing")
 	fs.BoolVar(&cfg.fixMode, "fix", false, "Auto-fix validation warnings (use with -validate)")
+	fs.StringVar(&cfg.failOn, "fail-on", "error", "Lowest diagnostic severity that fails -validate: error, warning, info")
 	fs.StringVar(&cfg.artifactDir, "artifact-dir", ".", "Directory for artifact storage (default: current directory)")
+	fs.StringVar(&cfg.artifactLayout, "artifact-layout", "", "Artifact subdirectory template under -artifact-dir, e.g. {date}/{pipeline}/{run_id}")
+	fs.BoolVar(&cfg.timestamped, "timestamped", false, "Give each run its own timestamped subdirectory under -artifact-dir")
 	fs.StringVar(&cfg.dataDir, "data-dir", "", "Data directory for persistent state (default: .mammoth/ in CWD)")
 	fs.StringVar(&cfg.retryPolicy, "retry", "none", "Default retry policy: none, standard, aggressive, linear, patient")
 	fs.BoolVar(&cfg.tuiMode, "tui", false, "Run with interactive terminal UI")
 	fs.BoolVar(&cfg.fresh, "fresh", false, "Force a fresh run, skip auto-resume")
 	fs.BoolVar(&cfg.verbose, "verbose", false, "Verbose output")
+	fs.IntVar(&cfg.verboseLevel, "verbose-level", 0, "Verbose event detail: 1 pipeline and stage lifecycle, 2 adds retries, loops, and steering, 3 adds every tool call and LLM turn (implies -verbose)")
 	fs.BoolVar(&cfg.showVersion, "version", false, "Print version and exit")
+	fs.StringVar(&cfg.recordPath, "record", "", "Record backend and human-gate outcomes to a JSON file")
+	fs.StringVar(&cfg.replayPath, "replay", "", "Replay outcomes from a recording instead of calling the backend")
+	fs.StringVar(&cfg.onlyNodes, "only", "", "Comma-separated node IDs to execute; other nodes are treated as satisfied")
+	fs.StringVar(&cfg.skipNodes, "skip", "", "Comma-separated node IDs to treat as satisfied without executing")
+	fs.StringVar(&cfg.entry, "entry", "", "Start node to begin from when the graph has several (shape=Mdiamond, or per shape_map)")
+	fs.StringVar(&cfg.cpuProfile, "profile", "", "Write a CPU profile (pprof) of the run to this file")
+	fs.StringVar(&cfg.tracePath, "trace", "", "Write a runtime execution trace of the run to this file")
+	fs.BoolVar(&cfg.cleanOnSuccess, "clean-on-success", false, "Delete the run's artifacts after a successful run, keeping a manifest")
+	fs.BoolVar(&cfg.dedupArtifacts, "dedup-artifacts", false, "Store identical artifact files once, hard-linked from a content-addressed blob directory")
+	fs.Int64Var(&cfg.maxArtifacts, "max-artifact-bytes", 0, "Fail nodes whose writes push the run's artifacts past this many bytes (0: unlimited)")
+	fs.StringVar(&cfg.defaultModels, "default-model", "", "Per-provider default models, e.g. anthropic=claude-sonnet-4-5,openai=gpt-4o")
+	fs.StringVar(&cfg.baseURL, "base-url", "", "LLM API base URL for every provider without a more specific override")
+	fs.StringVar(&cfg.baseURLs, "base-urls", "", "Per-provider LLM API base URLs, e.g. anthropic=https://a.proxy,openai=https://o.proxy")
+	fs.DurationVar(&cfg.maxRuntime, "max-runtime", 0, "Cancel the run, checkpointing first, once it has run this long (0: unlimited)")
+	fs.DurationVar(&cfg.cancelGrace, "cancel-grace", 0, "On interrupt, give the running agent node this long to record a partial outcome before hard-cancelling (0: cancel immediately)")
+	fs.StringVar(&cfg.backendChain, "backend-chain", "", "Ordered providers to fail over through on server errors, e.g. anthropic,openai=gpt-4o")
+	fs.StringVar(&cfg.backend, "backend", "", "Backend for codergen nodes: agent (default; the provider whose API key is set) or stub (deterministic offline output)")
+	fs.BoolVar(&cfg.preflight, "preflight", false, "Before running, check the LLM provider is reachable and fail fast if not")
+	fs.DurationVar(&cfg.preflightWait, "preflight-timeout", defaultPreflightTimeout, "How long -preflight waits for the provider to respond")
+	fs.StringVar(&cfg.cacheDir, "cache-dir", "", "Cache LLM responses in this directory and serve identical requests from it")
+	fs.StringVar(&cfg.seed, "seed", "", "Sampling seed for reproducible runs, sent to providers that accept one (recorded in the run state)")
+	fs.Var(&cfg.sets, "set", "Seed the pipeline context with key=value before the start node runs (repeatable; true/false and numbers are typed)")
+	fs.IntVar(&cfg.concurrency, "concurrency", 1, "When given several pipeline files, how many to run at once")
+	fs.StringVar(&cfg.configPath, configFlagName, "", "YAML file of flag defaults (default: ./mammoth.yaml if present)")
 
 	fs.Usage = func() {
 		printHelp(os.Stderr, version)
 	}
 
-	if err := fs.Parse(os.Args[1:]); err != nil {
+	positional, err := parseInterspersed(fs, os.Args[1:])
+	if err != nil {
 		if errors.Is(err, flag.ErrHelp) {
 			os.Exit(0)
 		}
 		os.Exit(2)
 	}
 
+	// Flags left unset fall back to MAMMOTH_* env vars, then the config file.
+	if err := applyConfigDefaults(fs, cfg.configPath); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(2)
+	}
+
 	// Accept optional "run" subcommand: `mammoth run pipeline.dot` is equivalent
-	// to `mammoth pipeline.dot`.
-	argIdx := 0
-	if fs.NArg() > 0 && fs.Arg(0) == "run" {
-		argIdx = 1
+	// to `mammoth pipeline.dot`. Several files run as a batch.
+	if len(positional) > 0 && positional[0] == "run" {
+		positional = positional[1:]
+	}
+	if len(positional) > 0 {
+		cfg.pipelineFile = positional[0]
 	}
-	if fs.NArg() > argIdx {
-		cfg.pipelineFile = fs.Arg(argIdx)
+	if len(positional) > 1 {
+		cfg.pipelineFiles = positional
 	}
 
 	return cfg
@@ -132,10 +264,95 @@ func run(cfg config) int {
 		return 1
 	}
 
+	batch := len(cfg.pipelineFiles) > 1
+	if batch {
+		if err := checkBatchFlags(cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return 1
+		}
+	}
+
 	if cfg.validateOnly {
+		if batch {
+			return runBatch(os.Stdout, cfg, validatePipeline)
+		}
 		return validatePipeline(cfg)
 	}
 
+	if cfg.recordPath != "" && cfg.replayPath != "" {
+		fmt.Fprintln(os.Stderr, "error: -record and -replay cannot be used together")
+		return 1
+	}
+	if cfg.maxArtifacts < 0 {
+		fmt.Fprintln(os.Stderr, "error: -max-artifact-bytes must not be negative")
+		return 1
+	}
+	if _, ok := retrybackoff.Policies[cfg.retryPolicy]; !ok && cfg.retryPolicy != "" {
+		fmt.Fprintf(os.Stderr, "error: unknown -retry policy %q (want none, standard, aggressive, linear, or patient)\n", cfg.retryPolicy)
+		return 1
+	}
+	if cfg.maxRuntime < 0 {
+		fmt.Fprintln(os.Stderr, "error: -max-runtime must not be negative")
+		return 1
+	}
+	if cfg.verboseLevel < 0 || cfg.verboseLevel > verboseAll {
+		fmt.Fprintf(os.Stderr, "error: -verbose-level must be 1, 2, or 3, got %d\n", cfg.verboseLevel)
+		return 1
+	}
+	if cfg.verboseLevel > 0 {
+		cfg.verbose = true
+	} else if cfg.verbose {
+		cfg.verboseLevel = verboseAll
+	}
+	layout, err := effectiveArtifactLayout(cfg.artifactLayout, cfg.timestamped)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	cfg.artifactLayout = layout
+	if cfg.backend, err = detectBackend(cfg.backend); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	providerURLs, err := resolveBaseURLs(cfg.baseURLs, cfg.baseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	cfg.providerURLs = providerURLs
+	if cfg.preflight {
+		if cfg.preflightWait <= 0 {
+			fmt.Fprintln(os.Stderr, "error: -preflight-timeout must be positive")
+			return 1
+		}
+		if err := preflightBackend(context.Background(), cfg.backend, cfg.providerURLs, cfg.preflightWait); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return 1
+		}
+	}
+	if cfg.runSeed, err = parseSeed(cfg.seed); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	if cfg.initialContext, err = parseInitialContext(cfg.sets); err != nil {
+		fmt.Fprintf(os.Stderr, "error: -set: %v\n", err)
+		return 1
+	}
+
+	stopProfiling, err := startProfiling(cfg.cpuProfile, cfg.tracePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	defer func() {
+		if err := stopProfiling(); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+	}()
+
+	if batch {
+		return runBatch(os.Stdout, cfg, runPipeline)
+	}
 	if cfg.tuiMode {
 		return runPipelineWithTUI(cfg)
 	}
@@ -143,34 +360,11 @@ func run(cfg config) int {
 	return runPipeline(cfg)
 }
 
-// buildTrackerLLMClient constructs a tracker LLM client from environment variables.
+// buildTrackerLLMClient constructs a tracker LLM client from environment variables,
+// pointing each provider at its entry in baseURLs (see resolveBaseURLs).
 // Returns nil, nil when no API keys are set (rather than an error).
-func buildTrackerLLMClient() (*trackerllm.Client, error) {
-	constructors := map[string]func(string) (trackerllm.ProviderAdapter, error){
-		"anthropic": func(key string) (trackerllm.ProviderAdapter, error) {
-			var opts []anthropic.Option
-			if base := os.Getenv("ANTHROPIC_BASE_URL"); base != "" {
-				opts = append(opts, anthropic.WithBaseURL(base))
-			}
-			return anthropic.New(key, opts...), nil
-		},
-		"openai": func(key string) (trackerllm.ProviderAdapter, error) {
-			var opts []openai.Option
-			if base := os.Getenv("OPENAI_BASE_URL"); base != "" {
-				opts = append(opts, openai.WithBaseURL(base))
-			}
-			return openai.New(key, opts...), nil
-		},
-		"gemini": func(key string) (trackerllm.ProviderAdapter, error) {
-			var opts []google.Option
-			if base := os.Getenv("GEMINI_BASE_URL"); base != "" {
-				opts = append(opts, google.WithBaseURL(base))
-			}
-			return google.New(key, opts...), nil
-		},
-	}
-
-	client, err := trackerllm.NewClientFromEnv(constructors)
+func buildTrackerLLMClient(baseURLs map[string]string) (*trackerllm.Client, error) {
+	client, err := trackerllm.NewClientFromEnv(trackerAdapterConstructors(baseURLs))
 	if err != nil {
 		// If no API keys are configured, return nil client (not an error).
 		// The caller decides whether a nil client is acceptable.
@@ -191,6 +385,24 @@ func buildTrackerLLMClient() (*trackerllm.Client, error) {
 	return client, nil
 }
 
+// buildBackendClient builds the LLM client for the configured backend. The
+// stub backend needs none, so it returns nil without reading API keys.
+func buildBackendClient(cfg config) (*trackerllm.Client, error) {
+	if cfg.backend == stubBackend {
+		return nil, nil
+	}
+	return buildTrackerLLMClient(cfg.providerURLs)
+}
+
+// completerOrNil converts a possibly-nil tracker client into an
+// agent.Completer, avoiding a non-nil interface wrapping a nil pointer.
+func completerOrNil(client *trackerllm.Client) agent.Completer {
+	if client == nil {
+		return nil
+	}
+	return client
+}
+
 // hasLLMKeys returns true if at least one LLM API key is set in the environment.
 func hasLLMKeys() bool {
 	for _, k := range []string{"ANTHROPIC_API_KEY", "OPENAI_API_KEY", "GEMINI_API_KEY"} {
@@ -203,30 +415,111 @@ func hasLLMKeys() bool {
 
 // buildPipelineEngine constructs a tracker pipeline.Engine from DOT source, wiring
 // the handler registry with LLM client, execution environment, and event handlers.
+// When the graph has several start nodes, entry selects which one to begin
+// from (see selectEntryNode). Optional registry hooks run after the default handlers are registered and may
+// wrap or replace them (e.g. for record/replay).
 func buildPipelineEngine(
 	source string,
 	workDir string,
 	llmClient agent.Completer,
 	checkpointPath string,
 	artifactDir string,
+	entry string,
+	pipelineHandler pipeline.PipelineEventHandler,
+	agentHandler agent.EventHandler,
+	registryHooks ...func(*pipeline.HandlerRegistry),
+) (*pipeline.Engine, *pipeline.Graph, error) {
+	return buildSeededPipelineEngine(nil, redact.Default(), source, workDir, llmClient, checkpointPath, artifactDir, entry, pipelineHandler, agentHandler, registryHooks...)
+}
+
+// buildSeededPipelineEngine is buildPipelineEngine for a run whose context
+// starts with initialContext (from -set). A resumed run's checkpoint context
+// wins over it. redactor masks secrets in events and outcomes; callers use the
+// same one for the run state they persist.
+func buildSeededPipelineEngine(
+	initialContext map[string]string,
+	redactor *redact.Redactor,
+	source string,
+	workDir string,
+	llmClient agent.Completer,
+	checkpointPath string,
+	artifactDir string,
+	entry string,
 	pipelineHandler pipeline.PipelineEventHandler,
 	agentHandler agent.EventHandler,
+	registryHooks ...func(*pipeline.HandlerRegistry),
 ) (*pipeline.Engine, *pipeline.Graph, error) {
 	trackerGraph, err := pipeline.ParseDOT(source)
 	if err != nil {
 		return nil, nil, fmt.Errorf("parse pipeline: %w", err)
 	}
+	if err := shapemap.Apply(trackerGraph); err != nil {
+		return nil, nil, fmt.Errorf("parse pipeline: %w", err)
+	}
+	if err := selectEntryNode(trackerGraph, entry); err != nil {
+		return nil, nil, err
+	}
+	// A pinned provider is checked only when the run talks to a real backend;
+	// -replay and other hooks supplying codergen need no credentials.
+	if llmClient != nil {
+		if err := providerpin.Check(trackerGraph); err != nil {
+			return nil, nil, err
+		}
+	}
+	providerpin.Apply(trackerGraph)
+	tokenAllocs, err := tokenbudget.Allocations(trackerGraph)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	var registryOpts []handlers.RegistryOption
+	// Events reach logs, persisted run state, and the TUI, so secrets are
+	// masked before any handler sees them.
+	pipelineHandler = redact.PipelineHandler(redactor, pipelineHandler)
+	agentHandler = redact.AgentHandler(redactor, agentHandler)
+
+	// Tool nodes only need a local shell, so the exec environment is always
+	// available; the LLM backend is optional for pipelines without codergen nodes.
+	registryOpts := []handlers.RegistryOption{
+		handlers.WithExecEnvironment(toolguard.Environment(exec.NewLocalEnvironment(workDir))),
+	}
 	if llmClient != nil {
-		registryOpts = append(registryOpts, handlers.WithLLMClient(llmClient, workDir))
-		registryOpts = append(registryOpts, handlers.WithExecEnvironment(exec.NewLocalEnvironment(workDir)))
+		registryOpts = append(registryOpts, handlers.WithLLMClient(toolguard.Completer(tokenbudget.Completer(&usageCompleter{inner: &fallbackCompleter{inner: genparams.Completer(streaming.Completer(llmClient))}}), agentHandler), workDir))
 	}
 	if agentHandler != nil {
 		registryOpts = append(registryOpts, handlers.WithAgentEventHandler(agentHandler))
 	}
 
 	registry := handlers.NewDefaultRegistry(trackerGraph, registryOpts...)
+	genparams.Hook(registry)
+	streaming.Hook(registry)
+	for _, hook := range registryHooks {
+		if hook != nil {
+			hook(registry)
+		}
+	}
+	// A human gate's answer_pattern re-asks until the answer matches, before
+	// any other hook sees the outcome. Token sub-budgets fail an
+	// over-spending node before success_if judges the outcome every other
+	// hook produced, including a replayed one;
+	// skip_if wraps so a skipped node never reaches the backend, a
+	// recording, or the artifact cap. A node's mutex is held around all of
+	// that but only once skip_if has decided the node runs. next_node wraps
+	// so even a skipped node clears the previous node's choice, and secrets
+	// are masked outermost, in whatever outcome the other hooks settled on.
+	answerpattern.Hook(trackerGraph)(registry)
+	tokenbudget.Hook(tokenAllocs)(registry)
+	toolguard.Hook(trackerGraph)(registry)
+	successif.Hook(trackerGraph)(registry)
+	nodelock.Hook(trackerGraph, nodelock.New())(registry)
+	skipif.Hook(trackerGraph)(registry)
+	nextnode.Hook(trackerGraph)(registry)
+	redact.Hook(trackerGraph, redactor)(registry)
+	if err := checkBackendAvailable(trackerGraph, registry); err != nil {
+		return nil, nil, err
+	}
+	if err := checkHandlersRegistered(trackerGraph, registry); err != nil {
+		return nil, nil, err
+	}
 
 	var engineOpts []pipeline.EngineOption
 	if checkpointPath != "" {
@@ -238,11 +531,144 @@ func buildPipelineEngine(
 	if pipelineHandler != nil {
 		engineOpts = append(engineOpts, pipeline.WithPipelineEventHandler(pipelineHandler))
 	}
+	if len(initialContext) > 0 {
+		engineOpts = append(engineOpts, pipeline.WithInitialContext(initialContext))
+	}
 
 	engine := pipeline.NewEngine(trackerGraph, registry, engineOpts...)
 	return engine, trackerGraph, nil
 }
 
+// checkBackendAvailable fails fast when the graph contains codergen nodes but
+// no codergen handler is registered (no LLM API key and no hook supplying
+// one, such as -replay). Pipelines without codergen nodes run without a backend.
+func checkBackendAvailable(g *pipeline.Graph, registry *pipeline.HandlerRegistry) error {
+	if registry.Has("codergen") {
+		return nil
+	}
+	var needs []string
+	for id, n := range g.Nodes {
+		if n.Handler == "codergen" {
+			needs = append(needs, id)
+		}
+	}
+	if len(needs) == 0 {
+		return nil
+	}
+	sort.Strings(needs)
+	return fmt.Errorf("no LLM API key found: codergen node(s) %s need a backend (set ANTHROPIC_API_KEY, OPENAI_API_KEY, or GEMINI_API_KEY, or run offline with -backend stub)", strings.Join(needs, ", "))
+}
+
+// checkHandlersRegistered fails fast when a node's handler (from its type
+// attribute or its shape, as resolved on the parsed graph) is not in the
+// registry, such as a custom type="..." with no hook registering it.
+func checkHandlersRegistered(g *pipeline.Graph, registry *pipeline.HandlerRegistry) error {
+	var msgs []string
+	for _, id := range slices.Sorted(maps.Keys(g.Nodes)) {
+		n := g.Nodes[id]
+		if registry.Has(n.Handler) {
+			continue
+		}
+		msgs = append(msgs, fmt.Sprintf("node %q resolves to type %q but no handler is registered for it", id, n.Handler))
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("unregistered handlers: %s", strings.Join(msgs, "; "))
+}
+
+// selectEntryNode points the graph's start node at entry. With no entry, a
+// graph with a single start node is left unchanged and a graph with several
+// is rejected, listing the available start nodes.
+func selectEntryNode(g *pipeline.Graph, entry string) error {
+	shapes, err := dot.GraphShapeMapping(g.Attrs)
+	if err != nil {
+		return err
+	}
+	var starts []string
+	for id, n := range g.Nodes {
+		if shapes[n.Shape] == "start" || n.Handler == "start" {
+			starts = append(starts, id)
+		}
+	}
+	sort.Strings(starts)
+
+	if entry == "" {
+		if len(starts) > 1 {
+			return fmt.Errorf("graph has %d start nodes (%s); choose one with -entry", len(starts), strings.Join(starts, ", "))
+		}
+		return nil
+	}
+	if !slices.Contains(starts, entry) {
+		if _, ok := g.Nodes[entry]; !ok {
+			return fmt.Errorf("entry node %q not found (start nodes: %s)", entry, strings.Join(starts, ", "))
+		}
+		return fmt.Errorf("entry node %q is not a start node (start nodes: %s)", entry, strings.Join(starts, ", "))
+	}
+	g.StartNode = entry
+	return nil
+}
+
+// registryHooks builds the handler registry hooks requested by the CLI config:
+// the stub backend when selected, per-provider default models and the
+// backend fallback chain first, then record/replay so the recording captures
+// exactly what the wrapped backend returned, then the -only/-skip node
+// filter, per-node retry backoff, and the artifact size cap on workDir
+// outermost so capped failures are never recorded as backend outcomes.
+func registryHooks(cfg config, workDir string) ([]func(*pipeline.HandlerRegistry), error) {
+	defaults, err := resolveDefaultModels(cfg.defaultModels)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := parseNodeFilter(cfg.onlyNodes, cfg.skipNodes)
+	if err != nil {
+		return nil, err
+	}
+	chain, err := parseBackendChain(cfg.backendChain)
+	if err != nil {
+		return nil, fmt.Errorf("-backend-chain: %w", err)
+	}
+	// Catch chains that cannot serve the active provider's nodes before any
+	// node runs; nodes pinned to another provider are checked as they run.
+	provider := activeProvider()
+	if _, err := resolveBackendChain(chain, provider, defaults); err != nil {
+		return nil, fmt.Errorf("-backend-chain: %w", err)
+	}
+
+	// With a node filter, a replay recording supplies the outcomes of the
+	// filtered-out nodes while the selected nodes run against the backend.
+	if filter != nil && cfg.replayPath != "" {
+		prior, err := loadOutcomeReplayer(cfg.replayPath)
+		if err != nil {
+			return nil, err
+		}
+		return []func(*pipeline.HandlerRegistry){
+			stubBackendHook(cfg.backend),
+			defaultModelHook(provider, defaults),
+			backendChainHook(provider, chain, defaults),
+			nodeFilterHook(filter, prior),
+			retrybackoff.Hook(cfg.retryPolicy, cfg.retryEvents),
+			artifactcap.Hook(workDir, cfg.maxArtifacts),
+			cancelGraceHook(cfg.grace),
+		}, nil
+	}
+
+	recordHook, err := recordReplayHook(cfg.recordPath, cfg.replayPath)
+	if err != nil {
+		return nil, err
+	}
+	return []func(*pipeline.HandlerRegistry){
+		stubBackendHook(cfg.backend),
+		defaultModelHook(provider, defaults),
+		backendChainHook(provider, chain, defaults),
+		recordHook,
+		nodeFilterHook(filter, nil),
+		retrybackoff.Hook(cfg.retryPolicy, cfg.retryEvents),
+		artifactcap.Hook(workDir, cfg.maxArtifacts),
+		cancelGraceHook(cfg.grace),
+	}, nil
+}
+
 // runPipeline reads a DOT file and executes the pipeline. When a TTY is
 // available, it uses an inline Bubble Tea progress display. Otherwise it
 // falls back to direct execution with optional verbose event logging.
@@ -273,6 +699,11 @@ func runPipeline(cfg config) int {
 		return 1
 	}
 
+	if err := checkNodeFilterFlags(cfg, graph); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+
 	// Compute content hash for auto-resume matching
 	sourceHash := runstate.SourceHash(string(source))
 
@@ -299,8 +730,9 @@ func runPipeline(cfg config) int {
 		}
 	}
 
-	// Auto-resume: check for a previous failed/interrupted run with the same source hash
-	if store != nil && !cfg.fresh {
+	// Auto-resume: check for a previous failed/interrupted run with the same source hash.
+	// Replays always start fresh so every recorded outcome is consumed in order.
+	if store != nil && !cfg.fresh && cfg.replayPath == "" {
 		resumeState, findErr := store.FindResumable(sourceHash)
 		if findErr != nil {
 			fmt.Fprintf(os.Stderr, "warning: could not check for resumable runs: %v\n", findErr)
@@ -325,13 +757,22 @@ func runPipelineResume(
 	cpPath := store.CheckpointPath(resumeState.ID)
 
 	// Build the LLM client from environment
-	llmClient, err := buildTrackerLLMClient()
+	llmClient, err := buildBackendClient(cfg)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		return 1
 	}
 
-	workDir := cfg.artifactDir
+	artifactDir, err := resolveArtifactDir(cfg.artifactDir, cfg.artifactLayout, artifactLayoutMeta{
+		RunID:     resumeState.ID,
+		Pipeline:  pipelineLayoutName(graph, cfg.pipelineFile),
+		StartedAt: resumeState.StartedAt,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	workDir := artifactDir
 	if workDir == "" {
 		workDir, _ = os.Getwd()
 	}
@@ -340,25 +781,49 @@ func runPipelineResume(
 	// handlers can be wired after the tea.Program is created.
 	relay := &deferredEventRelay{}
 	persistHandler := buildPersistenceHandler(store, resumeState.ID)
+	attempts := runstate.NewAttemptLog(resumeState.NodeAttempts)
+	usage := runstate.NewUsageLog(resumeState.NodeUsage)
 	var verboseHandler pipeline.PipelineEventHandlerFunc
 	if cfg.verbose {
-		verboseHandler = verbosePipelineHandler
+		verboseHandler = verbosePipelineHandler(os.Stderr, cfg.verboseLevel)
 	}
-	pipelineHandler := combinePipelineHandlers(persistHandler, verboseHandler, relay.PipelineHandler())
+	pipelineHandler := combinePipelineHandlers(persistHandler, attempts.Observe, verboseHandler, relay.PipelineHandler())
 
 	var verboseAgentFn agent.EventHandlerFunc
 	if cfg.verbose {
-		verboseAgentFn = verboseAgentHandler
+		verboseAgentFn = verboseAgentHandler(os.Stderr, cfg.verboseLevel)
 	}
 	agentEvtHandler := combineAgentHandlers(verboseAgentFn, relay.AgentHandler())
 
-	engine, _, err := buildPipelineEngine(source, workDir, llmClient, cpPath, cfg.artifactDir, pipelineHandler, agentEvtHandler)
+	cfg.grace = newCancelGrace(cfg.cancelGrace)
+	cfg.retryEvents = pipelineHandler
+	hooks, err := registryHooks(cfg, workDir)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		return 1
 	}
+	outputs := &runstate.OutputLog{}
+	hooks = append(hooks, usageHook(usage), outputs.Hook)
 
-	ctx, cancel := context.WithCancel(context.Background())
+	cached, err := withResponseCache(completerOrNil(llmClient), cfg.cacheDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	completer, seeded := withSeed(cached, cfg.runSeed)
+	redactor := redact.Default()
+	// Edited graph attributes apply to the resumed run; the rest of the
+	// checkpoint's context is kept.
+	if err := runstate.RefreshCheckpointContext(cpPath, graph.Attrs); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not refresh checkpoint context: %v\n", err)
+	}
+	engine, trackerGraph, err := buildSeededPipelineEngine(cfg.initialContext, redactor, source, workDir, completer, cpPath, artifactDir, cfg.entry, pipelineHandler, agentEvtHandler, hooks...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+
+	ctx, cancel := withMaxRuntime(context.Background(), cfg.maxRuntime, cfg.grace)
 	defer cancel()
 
 	// Update the existing run state to "running" and clear any previous error
@@ -371,33 +836,33 @@ func runPipelineResume(
 	var result *pipeline.EngineResult
 	var runErr error
 
-	if isTerminal() {
+	if isTerminal() && !cfg.batch {
 		result, runErr = runPipelineResumeWithStream(cfg, graph, engine, ctx, cpPath, resumeState, relay)
 	} else {
 		result, runErr = runPipelineResumeDirect(cfg, engine, ctx, cpPath)
 	}
+	runErr = maxRuntimeError(ctx, cfg.maxRuntime, runErr)
+	var resultNode, primaryOutput string
+	if runErr == nil {
+		resultNode, primaryOutput = outputs.Primary(trackerGraph, result)
+		primaryOutput = redactor.String(primaryOutput)
+		printPrimaryOutput(os.Stdout, resultNode, primaryOutput)
+	}
 
 	// Persist final run state
 	now := time.Now()
 	resumeState.CompletedAt = &now
 	resumeState.SourceHash = sourceHash
-	if runErr != nil {
-		if errors.Is(runErr, context.Canceled) {
-			resumeState.Status = "cancelled"
-		} else {
-			resumeState.Status = "failed"
-		}
-		resumeState.Error = runErr.Error()
-	} else {
-		resumeState.Status = "completed"
-		if result != nil {
-			resumeState.CompletedNodes = result.CompletedNodes
-			resumeState.Context = result.Context
-		}
-	}
+	resumeState.NodeAttempts = attempts.Records()
+	resumeState.Usage, resumeState.NodeUsage = usage.Total(), usage.Nodes()
+	resumeState.ResultNode, resumeState.PrimaryOutput = resultNode, primaryOutput
+	recordSeed(resumeState, cfg.runSeed, seeded)
+	warnSeedUnsupported(os.Stderr, resumeState)
+	settleRunState(resumeState, result, runErr, redactor)
 	if err := store.Update(resumeState); err != nil {
 		fmt.Fprintf(os.Stderr, "warning: could not persist final state: %v\n", err)
 	}
+	finishRunArtifacts(cfg, graph, artifactDir, result, runErr)
 
 	if runErr != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", runErr)
@@ -407,6 +872,26 @@ func runPipelineResume(
 	return 0
 }
 
+// settleRunState records a finished run's outcome in state: its status, and
+// either its error or its completed and skipped nodes and final context. The
+// error and context are masked with redactor because run state is persisted.
+func settleRunState(state *runstate.RunState, result *pipeline.EngineResult, runErr error, redactor *redact.Redactor) {
+	if runErr != nil {
+		if errors.Is(runErr, context.Canceled) {
+			state.Status = "cancelled"
+		} else {
+			state.Status = "failed"
+		}
+		state.Error = redactor.String(runErr.Error())
+		return
+	}
+	state.Status = "completed"
+	if result != nil {
+		state.CompletedNodes, state.SkippedNodes = skipif.Split(result)
+		state.Context = redactor.StringMap(result.Context)
+	}
+}
+
 // runPipelineResumeWithStream resumes pipeline execution using the inline Bubble Tea display.
 func runPipelineResumeWithStream(
 	cfg config,
@@ -418,7 +903,7 @@ func runPipelineResumeWithStream(
 	relay *deferredEventRelay,
 ) (*pipeline.EngineResult, error) {
 	// Load checkpoint to find which node we're resuming from
-	cp, err := pipeline.LoadCheckpoint(cpPath)
+	cp, err := runstate.LoadCheckpoint(cpPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load checkpoint: %w", err)
 	}
@@ -428,7 +913,8 @@ func runPipelineResumeWithStream(
 		PreviousNodes: cp.CompletedNodes,
 	}
 
-	model := tui.NewStreamModel(graph, engine, cfg.pipelineFile, ctx, cfg.verbose, tui.WithResumeInfo(resumeInfo))
+	model := tui.NewStreamModel(graph, engine, cfg.pipelineFile, ctx, cfg.verbose,
+		tui.WithResumeInfo(resumeInfo), tui.WithPause(cfg.grace.Request, cpPath))
 
 	p := tea.NewProgram(model)
 
@@ -464,7 +950,7 @@ func runPipelineResumeDirect(
 	go func() {
 		<-sigChan
 		fmt.Fprintln(os.Stderr, "\nInterrupted, shutting down...")
-		cancel()
+		cfg.grace.Request(cancel)
 	}()
 
 	fmt.Fprintf(os.Stderr, "Resuming pipeline from checkpoint...\n")
@@ -501,13 +987,23 @@ func runPipelineFresh(
 	}
 
 	// Build the LLM client from environment
-	llmClient, llmErr := buildTrackerLLMClient()
+	llmClient, llmErr := buildBackendClient(cfg)
 	if llmErr != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", llmErr)
 		return 1
 	}
 
-	workDir := cfg.artifactDir
+	startTime := time.Now()
+	artifactDir, err := resolveArtifactDir(cfg.artifactDir, cfg.artifactLayout, artifactLayoutMeta{
+		RunID:     runID,
+		Pipeline:  pipelineLayoutName(graph, cfg.pipelineFile),
+		StartedAt: startTime,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	workDir := artifactDir
 	if workDir == "" {
 		workDir, _ = os.Getwd()
 	}
@@ -516,30 +1012,48 @@ func runPipelineFresh(
 	// handlers can be wired after the tea.Program is created.
 	relay := &deferredEventRelay{}
 	persistHandler := buildPersistenceHandler(store, runID)
+	attempts := &runstate.AttemptLog{}
+	usage := &runstate.UsageLog{}
 	var verboseHandler pipeline.PipelineEventHandlerFunc
 	if cfg.verbose {
-		verboseHandler = verbosePipelineHandler
+		verboseHandler = verbosePipelineHandler(os.Stderr, cfg.verboseLevel)
 	}
-	pipelineHandler := combinePipelineHandlers(persistHandler, verboseHandler, relay.PipelineHandler())
+	pipelineHandler := combinePipelineHandlers(persistHandler, attempts.Observe, verboseHandler, relay.PipelineHandler())
 
 	var verboseAgentFn agent.EventHandlerFunc
 	if cfg.verbose {
-		verboseAgentFn = verboseAgentHandler
+		verboseAgentFn = verboseAgentHandler(os.Stderr, cfg.verboseLevel)
 	}
 	agentEvtHandler := combineAgentHandlers(verboseAgentFn, relay.AgentHandler())
 
-	engine, _, err := buildPipelineEngine(source, workDir, llmClient, autoCheckpointPath, cfg.artifactDir, pipelineHandler, agentEvtHandler)
+	cfg.grace = newCancelGrace(cfg.cancelGrace)
+	cfg.retryEvents = pipelineHandler
+	hooks, err := registryHooks(cfg, workDir)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		return 1
 	}
+	outputs := &runstate.OutputLog{}
+	hooks = append(hooks, usageHook(usage), outputs.Hook)
 
-	// Create a cancellable context.
-	ctx, cancel := context.WithCancel(context.Background())
+	cached, err := withResponseCache(completerOrNil(llmClient), cfg.cacheDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	completer, seeded := withSeed(cached, cfg.runSeed)
+	redactor := redact.Default()
+	engine, trackerGraph, err := buildSeededPipelineEngine(cfg.initialContext, redactor, source, workDir, completer, autoCheckpointPath, artifactDir, cfg.entry, pipelineHandler, agentEvtHandler, hooks...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+
+	// Create a cancellable context, bounded by -max-runtime when set.
+	ctx, cancel := withMaxRuntime(context.Background(), cfg.maxRuntime, cfg.grace)
 	defer cancel()
 
 	// Persist initial run state
-	startTime := time.Now()
 	if store != nil {
 		initialState := &runstate.RunState{
 			ID:             runID,
@@ -551,6 +1065,7 @@ func runPipelineFresh(
 			CompletedNodes: []string{},
 			Context:        map[string]string{},
 			Events:         []runstate.RunEvent{},
+			Seed:           cfg.runSeed,
 		}
 		if err := store.Create(initialState); err != nil {
 			fmt.Fprintf(os.Stderr, "warning: could not persist initial state: %v\n", err)
@@ -562,11 +1077,18 @@ func runPipelineFresh(
 	var result *pipeline.EngineResult
 	var runErr error
 
-	if isTerminal() {
-		result, runErr = runPipelineWithStream(cfg, graph, engine, ctx, source, relay)
+	if isTerminal() && !cfg.batch {
+		result, runErr = runPipelineWithStream(cfg, graph, engine, ctx, autoCheckpointPath, relay)
 	} else {
 		result, runErr = runPipelineDirect(cfg, engine, ctx, source)
 	}
+	runErr = maxRuntimeError(ctx, cfg.maxRuntime, runErr)
+	var resultNode, primaryOutput string
+	if runErr == nil {
+		resultNode, primaryOutput = outputs.Primary(trackerGraph, result)
+		primaryOutput = redactor.String(primaryOutput)
+		printPrimaryOutput(os.Stdout, resultNode, primaryOutput)
+	}
 
 	// Persist final run state
 	if store != nil {
@@ -580,25 +1102,21 @@ func runPipelineFresh(
 			SourceHash:   sourceHash,
 			Context:      map[string]string{},
 			Events:       []runstate.RunEvent{},
+			NodeAttempts: attempts.Records(),
+			Usage:        usage.Total(),
+			NodeUsage:    usage.Nodes(),
+
+			ResultNode:    resultNode,
+			PrimaryOutput: primaryOutput,
 		}
-		if runErr != nil {
-			if errors.Is(runErr, context.Canceled) {
-				finalState.Status = "cancelled"
-			} else {
-				finalState.Status = "failed"
-			}
-			finalState.Error = runErr.Error()
-		} else {
-			finalState.Status = "completed"
-			if result != nil {
-				finalState.CompletedNodes = result.CompletedNodes
-				finalState.Context = result.Context
-			}
-		}
+		recordSeed(finalState, cfg.runSeed, seeded)
+		warnSeedUnsupported(os.Stderr, finalState)
+		settleRunState(finalState, result, runErr, redactor)
 		if err := store.Update(finalState); err != nil {
 			fmt.Fprintf(os.Stderr, "warning: could not persist final state: %v\n", err)
 		}
 	}
+	finishRunArtifacts(cfg, graph, artifactDir, result, runErr)
 
 	if runErr != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", runErr)
@@ -615,10 +1133,12 @@ func runPipelineWithStream(
 	graph *dot.Graph,
 	engine *pipeline.Engine,
 	ctx context.Context,
-	source string,
+	cpPath string,
 	relay *deferredEventRelay,
 ) (*pipeline.EngineResult, error) {
-	model := tui.NewStreamModel(graph, engine, cfg.pipelineFile, ctx, cfg.verbose)
+	// The first Ctrl+C pauses: the engine checkpoints to cpPath (after the
+	// -cancel-grace window, if set) so the run can be resumed.
+	model := tui.NewStreamModel(graph, engine, cfg.pipelineFile, ctx, cfg.verbose, tui.WithPause(cfg.grace.Request, cpPath))
 
 	p := tea.NewProgram(model)
 
@@ -657,7 +1177,7 @@ func runPipelineDirect(
 	go func() {
 		<-sigChan
 		fmt.Fprintln(os.Stderr, "\nInterrupted, shutting down...")
-		cancel()
+		cfg.grace.Request(cancel)
 	}()
 
 	result, runErr := engine.Run(ctx)
@@ -671,6 +1191,19 @@ func runPipelineDirect(
 	return result, nil
 }
 
+// printPrimaryOutput prints the run's primary output, naming the node that
+// produced it, or nothing when the run produced none.
+func printPrimaryOutput(w io.Writer, resultNode, output string) {
+	if output == "" {
+		return
+	}
+	if resultNode != "" {
+		fmt.Fprintf(w, "Result (%s):\n%s\n", resultNode, output)
+	} else {
+		fmt.Fprintf(w, "Result:\n%s\n", output)
+	}
+}
+
 // printPipelineResult prints a summary of the completed pipeline run.
 func printPipelineResult(result *pipeline.EngineResult, suffix string) {
 	if suffix != "" {
@@ -679,7 +1212,11 @@ func printPipelineResult(result *pipeline.EngineResult, suffix string) {
 		fmt.Printf("Pipeline completed successfully.\n")
 	}
 	if result != nil {
-		fmt.Printf("Completed nodes: %v\n", result.CompletedNodes)
+		completed, skipped := skipif.Split(result)
+		fmt.Printf("Completed nodes: %v\n", completed)
+		if len(skipped) > 0 {
+			fmt.Printf("Skipped nodes: %v\n", skipped)
+		}
 		if result.Status != "" {
 			fmt.Printf("Final status: %s\n", result.Status)
 		}
@@ -716,14 +1253,36 @@ func runPipelineWithTUI(cfg config) int {
 		return 1
 	}
 
+	if err := checkNodeFilterFlags(cfg, graph); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+
 	// Build the LLM client from environment
-	llmClient, llmErr := buildTrackerLLMClient()
+	llmClient, llmErr := buildBackendClient(cfg)
 	if llmErr != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", llmErr)
 		return 1
 	}
 
-	workDir := cfg.artifactDir
+	artifactDir := cfg.artifactDir
+	if cfg.artifactLayout != "" {
+		runID, err := runstate.GenerateRunID()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return 1
+		}
+		artifactDir, err = resolveArtifactDir(cfg.artifactDir, cfg.artifactLayout, artifactLayoutMeta{
+			RunID:     runID,
+			Pipeline:  pipelineLayoutName(graph, cfg.pipelineFile),
+			StartedAt: time.Now(),
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return 1
+		}
+	}
+	workDir := artifactDir
 	if workDir == "" {
 		workDir, _ = os.Getwd()
 	}
@@ -731,14 +1290,27 @@ func runPipelineWithTUI(cfg config) int {
 	// Create a deferred relay so bridge handlers can be wired after the
 	// tea.Program is created (which requires the model, which requires the engine).
 	relay := &deferredEventRelay{}
-	engine, _, err := buildPipelineEngine(string(source), workDir, llmClient, "", cfg.artifactDir, relay.PipelineHandler(), relay.AgentHandler())
+	cfg.retryEvents = relay.PipelineHandler()
+	hooks, err := registryHooks(cfg, workDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+
+	cached, err := withResponseCache(completerOrNil(llmClient), cfg.cacheDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	completer, _ := withSeed(cached, cfg.runSeed)
+	engine, _, err := buildSeededPipelineEngine(cfg.initialContext, redact.Default(), string(source), workDir, completer, "", artifactDir, cfg.entry, relay.PipelineHandler(), relay.AgentHandler(), hooks...)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		return 1
 	}
 
 	// Create a cancellable context so quitting the TUI stops the engine.
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := withMaxRuntime(context.Background(), cfg.maxRuntime, cfg.grace)
 	defer cancel()
 
 	// Create the TUI app model.
@@ -781,6 +1353,12 @@ func parseServeArgs(args []string) (serveConfig, bool) {
 	fs.IntVar(&scfg.port, "port", 2389, "Server port (default: 2389)")
 	fs.StringVar(&scfg.dataDir, "data-dir", "", "Data directory for projects (overrides --global)")
 	fs.BoolVar(&scfg.global, "global", false, "Use global data directory (~/.local/share/mammoth) instead of local .mammoth/")
+	fs.IntVar(&scfg.maxConcurrentLLM, "max-llm-concurrency", 0, "Max in-flight LLM requests across all runs; excess requests queue (0: unlimited)")
+	fs.IntVar(&scfg.maxEventHistory, "max-event-history", web.DefaultMaxEventHistory, "Events each build keeps in memory for live replay; older agent events are dropped first (the full log stays in progress.ndjson)")
+	fs.StringVar(&scfg.nodeWebhook, "node-webhook", "", "URL that receives a JSON POST as each pipeline node starts and completes")
+	fs.BoolVar(&scfg.auditDecisions, "audit-decisions", false, "Record every human gate answer in a per-run decisions.jsonl audit log")
+	fs.StringVar(&scfg.trustedProxies, "trusted-proxy", "", "Comma-separated addresses or CIDR ranges of authenticating proxies whose X-Forwarded-User names who answered a gate")
+	fs.BoolVar(&scfg.showReasoning, "show-reasoning", false, "Include the model's (redacted) reasoning text in build events and the build console")
 
 	fs.Usage = func() {
 		fmt.Fprintln(os.Stderr, "Usage: mammoth serve [flags]")
@@ -828,13 +1406,20 @@ func buildWebServer(scfg serveConfig) (*web.Server, error) {
 	}
 
 	// Build tracker LLM client for pipeline execution in the web server.
-	llmClient, _ := buildTrackerLLMClient()
+	baseURLs, _ := resolveBaseURLs("", "")
+	llmClient, _ := buildTrackerLLMClient(baseURLs)
 
 	addr := fmt.Sprintf("127.0.0.1:%d", scfg.port)
 	srv, err := web.NewServer(web.ServerConfig{
-		Addr:      addr,
-		Workspace: ws,
-		LLMClient: llmClient,
+		Addr:             addr,
+		Workspace:        ws,
+		LLMClient:        completerOrNil(llmClient),
+		MaxConcurrentLLM: scfg.maxConcurrentLLM,
+		MaxEventHistory:  scfg.maxEventHistory,
+		NodeWebhook:      scfg.nodeWebhook,
+		AuditDecisions:   scfg.auditDecisions,
+		TrustedProxies:   strings.Split(scfg.trustedProxies, ","),
+		ShowReasoning:    scfg.showReasoning,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create web server: %w", err)
@@ -842,8 +1427,13 @@ func buildWebServer(scfg serveConfig) (*web.Server, error) {
 	return srv, nil
 }
 
+// serveShutdownTimeout bounds how long "mammoth serve" waits for in-flight
+// builds to finish on shutdown before cancelling them.
+const serveShutdownTimeout = 30 * time.Second
+
 // runServe starts the unified web server for the mammoth wizard flow. It
-// listens on the configured port and blocks until SIGINT or SIGTERM.
+// listens on the configured port and blocks until SIGINT or SIGTERM, then
+// drains in-flight builds.
 func runServe(scfg serveConfig) int {
 	srv, err := buildWebServer(scfg)
 	if err != nil {
@@ -889,6 +1479,14 @@ func runServe(scfg serveConfig) int {
 		return 1
 	}
 
+	// Let in-flight builds finish (or checkpoint and cancel at the deadline)
+	// before exiting.
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+	defer stopCancel()
+	if err := srv.Stop(stopCtx); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+
 	return 0
 }
 
@@ -905,15 +1503,26 @@ func validatePipeline(cfg config) int {
 		return 1
 	}
 
-	graph, err := dot.Parse(string(source))
+	_, diags, err := validator.ParseAndValidate(string(source))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		var pe *dot.ParseError
+		if errors.As(err, &pe) && pe.Snippet() != "" {
+			fmt.Fprintf(os.Stderr, "%s:%d:%d\n%s\n", cfg.pipelineFile, pe.Line(), pe.Col(), pe.Snippet())
+		}
 		return 1
 	}
 
-	diags := validator.Lint(graph)
+	failOn := cfg.failOn
+	if failOn == "" {
+		failOn = "error"
+	}
+	if !validator.IsSeverity(failOn) {
+		fmt.Fprintf(os.Stderr, "error: invalid -fail-on %q (want error, warning, or info)\n", cfg.failOn)
+		return 1
+	}
 
-	hasErrors := false
+	failed := false
 	for _, d := range diags {
 		fmt.Fprintf(os.Stderr, "[%s] %s", d.Severity, d.Message)
 		if d.NodeID != "" {
@@ -921,12 +1530,12 @@ func validatePipeline(cfg config) int {
 		}
 		fmt.Fprintln(os.Stderr)
 
-		if d.Severity == "error" {
-			hasErrors = true
+		if validator.SeverityAtLeast(d.Severity, failOn) {
+			failed = true
 		}
 	}
 
-	if hasErrors {
+	if failed {
 		fmt.Fprintf(os.Stderr, "Validation failed.\n")
 		return 1
 	}
@@ -971,55 +1580,98 @@ func combinePipelineHandlers(handlers ...pipeline.PipelineEventHandlerFunc) pipe
 	return pipeline.PipelineMultiHandler(active...)
 }
 
-// verbosePipelineHandler prints pipeline lifecycle events to stderr.
-func verbosePipelineHandler(evt pipeline.PipelineEvent) {
-	switch evt.Type {
-	case pipeline.EventPipelineStarted:
-		fmt.Fprintf(os.Stderr, "[pipeline] started\n")
-	case pipeline.EventStageStarted:
-		fmt.Fprintf(os.Stderr, "[stage] %s started\n", evt.NodeID)
-	case pipeline.EventStageCompleted:
-		fmt.Fprintf(os.Stderr, "[stage] %s completed\n", evt.NodeID)
-	case pipeline.EventStageFailed:
-		if evt.Err != nil {
-			fmt.Fprintf(os.Stderr, "[stage] %s failed: %v\n", evt.NodeID, evt.Err)
-		} else {
-			fmt.Fprintf(os.Stderr, "[stage] %s failed\n", evt.NodeID)
-		}
-	case pipeline.EventStageRetrying:
-		fmt.Fprintf(os.Stderr, "[stage] %s retrying\n", evt.NodeID)
-	case pipeline.EventPipelineCompleted:
-		fmt.Fprintf(os.Stderr, "[pipeline] completed\n")
-	case pipeline.EventPipelineFailed:
-		if evt.Err != nil {
-			fmt.Fprintf(os.Stderr, "[pipeline] failed: %v\n", evt.Err)
-		} else {
-			fmt.Fprintf(os.Stderr, "[pipeline] failed\n")
+// Verbosity levels for -verbose-level. -verbose on its own means verboseAll.
+const (
+	verboseLifecycle = 1 // pipeline and stage start, completion, and failure
+	verboseControl   = 2 // adds retries, loop restarts, steering, and checkpoints
+	verboseAll       = 3 // adds every tool call, LLM turn, and streamed text
+)
+
+// pipelineEventLevel is the lowest verbosity level that prints each pipeline
+// event type; unlisted types are never printed.
+var pipelineEventLevel = map[pipeline.PipelineEventType]int{
+	pipeline.EventPipelineStarted:   verboseLifecycle,
+	pipeline.EventPipelineCompleted: verboseLifecycle,
+	pipeline.EventPipelineFailed:    verboseLifecycle,
+	pipeline.EventStageStarted:      verboseLifecycle,
+	pipeline.EventStageCompleted:    verboseLifecycle,
+	pipeline.EventStageFailed:       verboseLifecycle,
+	pipeline.EventStageRetrying:     verboseControl,
+	pipeline.EventLoopRestart:       verboseControl,
+	pipeline.EventCheckpointSaved:   verboseControl,
+}
+
+// agentEventLevel is the lowest verbosity level that prints each agent event
+// type; unlisted types are never printed.
+var agentEventLevel = map[agent.EventType]int{
+	agent.EventSteeringInjected: verboseControl,
+	agent.EventTextDelta:        verboseAll,
+	agent.EventToolCallStart:    verboseAll,
+	agent.EventToolCallEnd:      verboseAll,
+	agent.EventTurnEnd:          verboseAll,
+}
+
+// verbosePipelineHandler prints pipeline events at or below level to w.
+func verbosePipelineHandler(w io.Writer, level int) pipeline.PipelineEventHandlerFunc {
+	return func(evt pipeline.PipelineEvent) {
+		if at, ok := pipelineEventLevel[evt.Type]; !ok || at > level {
+			return
+		}
+		switch evt.Type {
+		case pipeline.EventPipelineStarted:
+			fmt.Fprintf(w, "[pipeline] started\n")
+		case pipeline.EventStageStarted:
+			fmt.Fprintf(w, "[stage] %s started\n", evt.NodeID)
+		case pipeline.EventStageCompleted:
+			fmt.Fprintf(w, "[stage] %s completed\n", evt.NodeID)
+		case pipeline.EventStageFailed:
+			if evt.Err != nil {
+				fmt.Fprintf(w, "[stage] %s failed: %v\n", evt.NodeID, evt.Err)
+			} else {
+				fmt.Fprintf(w, "[stage] %s failed\n", evt.NodeID)
+			}
+		case pipeline.EventStageRetrying:
+			fmt.Fprintf(w, "[stage] %s retrying\n", evt.NodeID)
+		case pipeline.EventLoopRestart:
+			fmt.Fprintf(w, "[pipeline] loop restart at %s\n", evt.NodeID)
+		case pipeline.EventPipelineCompleted:
+			fmt.Fprintf(w, "[pipeline] completed\n")
+		case pipeline.EventPipelineFailed:
+			if evt.Err != nil {
+				fmt.Fprintf(w, "[pipeline] failed: %v\n", evt.Err)
+			} else {
+				fmt.Fprintf(w, "[pipeline] failed\n")
+			}
+		case pipeline.EventCheckpointSaved:
+			fmt.Fprintf(w, "[checkpoint] saved at %s\n", evt.NodeID)
 		}
-	case pipeline.EventCheckpointSaved:
-		fmt.Fprintf(os.Stderr, "[checkpoint] saved at %s\n", evt.NodeID)
 	}
 }
 
-// verboseAgentHandler prints agent session events to stderr.
-func verboseAgentHandler(evt agent.Event) {
-	switch evt.Type {
-	case agent.EventTextDelta:
-		if evt.Text != "" {
-			fmt.Fprint(os.Stderr, evt.Text)
+// verboseAgentHandler prints agent session events at or below level to w.
+func verboseAgentHandler(w io.Writer, level int) agent.EventHandlerFunc {
+	return func(evt agent.Event) {
+		if at, ok := agentEventLevel[evt.Type]; !ok || at > level {
+			return
 		}
-	case agent.EventToolCallStart:
-		if evt.ToolInput != "" {
-			fmt.Fprintf(os.Stderr, "\n[agent] tool %s(%s)\n", evt.ToolName, evt.ToolInput)
-		} else {
-			fmt.Fprintf(os.Stderr, "\n[agent] tool %s\n", evt.ToolName)
+		switch evt.Type {
+		case agent.EventTextDelta:
+			if evt.Text != "" {
+				fmt.Fprint(w, evt.Text)
+			}
+		case agent.EventToolCallStart:
+			if evt.ToolInput != "" {
+				fmt.Fprintf(w, "\n[agent] tool %s(%s)\n", evt.ToolName, evt.ToolInput)
+			} else {
+				fmt.Fprintf(w, "\n[agent] tool %s\n", evt.ToolName)
+			}
+		case agent.EventToolCallEnd:
+			fmt.Fprintf(w, "[agent] tool %s done\n", evt.ToolName)
+		case agent.EventTurnEnd:
+			fmt.Fprintf(w, "[agent] turn %d complete (in:%d out:%d)\n", evt.Turn, evt.Usage.InputTokens, evt.Usage.OutputTokens)
+		case agent.EventSteeringInjected:
+			fmt.Fprintf(w, "[agent] steering: %v\n", evt.Text)
 		}
-	case agent.EventToolCallEnd:
-		fmt.Fprintf(os.Stderr, "[agent] tool %s done\n", evt.ToolName)
-	case agent.EventTurnEnd:
-		fmt.Fprintf(os.Stderr, "[agent] turn %d complete (in:%d out:%d)\n", evt.Turn, evt.Usage.InputTokens, evt.Usage.OutputTokens)
-	case agent.EventSteeringInjected:
-		fmt.Fprintf(os.Stderr, "[agent] steering: %v\n", evt.Text)
 	}
 }
 
@@ -1206,4 +1858,3 @@ func runAudit(cfg auditConfig) int {
 	fmt.Println(report.Narrative)
 	return 0
 }
-
@@ -3,17 +3,24 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/2389-research/mammoth/dot"
 	"github.com/2389-research/mammoth/dot/validator"
 	"github.com/2389-research/mammoth/runstate"
+	"github.com/2389-research/mammoth/web"
 	"github.com/2389-research/tracker/agent"
+	trackerllm "github.com/2389-research/tracker/llm"
 	"github.com/2389-research/tracker/pipeline"
 )
 
@@ -282,6 +289,13 @@ func TestValidatePipelineInvalid(t *testing.T) {
 	}
 }
 
+func TestValidatePipelineMultiStart(t *testing.T) {
+	dotFile := writeTempDOT(t, multiStartDOT)
+	if code := validatePipeline(config{pipelineFile: dotFile}); code != 0 {
+		t.Errorf("expected exit code 0 for a graph with several start nodes, got %d", code)
+	}
+}
+
 func TestValidatePipelineNonexistentFile(t *testing.T) {
 	cfg := config{
 		pipelineFile: "/tmp/this-file-does-not-exist-at-all.dot",
@@ -292,6 +306,28 @@ func TestValidatePipelineNonexistentFile(t *testing.T) {
 	}
 }
 
+func TestValidatePipelineFailOnWarning(t *testing.T) {
+	// validDOT has no goal attribute, which is a warning only.
+	dotFile := writeTempDOT(t, validDOT)
+
+	if code := validatePipeline(config{pipelineFile: dotFile}); code != 0 {
+		t.Errorf("default -fail-on: expected exit code 0 for warnings only, got %d", code)
+	}
+	if code := validatePipeline(config{pipelineFile: dotFile, failOn: "error"}); code != 0 {
+		t.Errorf("-fail-on error: expected exit code 0 for warnings only, got %d", code)
+	}
+	if code := validatePipeline(config{pipelineFile: dotFile, failOn: "warning"}); code != 1 {
+		t.Errorf("-fail-on warning: expected exit code 1 for warnings, got %d", code)
+	}
+}
+
+func TestValidatePipelineFailOnInvalid(t *testing.T) {
+	dotFile := writeTempDOT(t, validDOT)
+	if code := validatePipeline(config{pipelineFile: dotFile, failOn: "fatal"}); code != 1 {
+		t.Errorf("expected exit code 1 for unknown -fail-on severity, got %d", code)
+	}
+}
+
 // --- runPipeline tests ---
 
 func TestRunPipelineSuccess(t *testing.T) {
@@ -483,7 +519,7 @@ func TestBuildTrackerLLMClientNoKeys(t *testing.T) {
 	t.Setenv("OPENAI_API_KEY", "")
 	t.Setenv("GEMINI_API_KEY", "")
 
-	client, err := buildTrackerLLMClient()
+	client, err := buildTrackerLLMClient(nil)
 	if err != nil {
 		t.Fatalf("expected no error without API keys, got: %v", err)
 	}
@@ -529,7 +565,7 @@ func TestVerbosePipelineHandler(t *testing.T) {
 					t.Errorf("verbosePipelineHandler panicked on %s: %v", evt.Type, r)
 				}
 			}()
-			verbosePipelineHandler(evt)
+			verbosePipelineHandler(io.Discard, verboseAll)(evt)
 		}()
 	}
 }
@@ -551,11 +587,68 @@ func TestVerboseAgentHandler(t *testing.T) {
 					t.Errorf("verboseAgentHandler panicked on %s: %v", evt.Type, r)
 				}
 			}()
-			verboseAgentHandler(evt)
+			verboseAgentHandler(io.Discard, verboseAll)(evt)
 		}()
 	}
 }
 
+func TestVerboseLevelsFilterEvents(t *testing.T) {
+	pipelineEvents := []pipeline.PipelineEvent{
+		{Type: pipeline.EventPipelineStarted},
+		{Type: pipeline.EventStageStarted, NodeID: "build"},
+		{Type: pipeline.EventStageRetrying, NodeID: "build"},
+		{Type: pipeline.EventStageCompleted, NodeID: "build"},
+		{Type: pipeline.EventCheckpointSaved, NodeID: "build"},
+		{Type: pipeline.EventLoopRestart, NodeID: "start"},
+		{Type: pipeline.EventPipelineCompleted},
+	}
+	agentEvents := []agent.Event{
+		{Type: agent.EventSteeringInjected, Text: "focus"},
+		{Type: agent.EventToolCallStart, ToolName: "file_write"},
+		{Type: agent.EventToolCallEnd, ToolName: "file_write"},
+		{Type: agent.EventTurnEnd, Turn: 1},
+	}
+	tests := []struct {
+		level int
+		want  []string
+	}{
+		{verboseLifecycle, []string{"[pipeline] started", "[stage] build started", "[stage] build completed", "[pipeline] completed"}},
+		{verboseControl, []string{"[pipeline] started", "[stage] build started", "[stage] build retrying", "[stage] build completed", "[checkpoint] saved at build", "[pipeline] loop restart at start", "[pipeline] completed", "[agent] steering: focus"}},
+		{verboseAll, []string{"[pipeline] started", "[stage] build started", "[stage] build retrying", "[stage] build completed", "[checkpoint] saved at build", "[pipeline] loop restart at start", "[pipeline] completed", "[agent] steering: focus", "[agent] tool file_write", "[agent] tool file_write done", "[agent] turn 1 complete (in:0 out:0)"}},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		for _, evt := range pipelineEvents {
+			verbosePipelineHandler(&out, tt.level)(evt)
+		}
+		for _, evt := range agentEvents {
+			verboseAgentHandler(&out, tt.level)(evt)
+		}
+		var got []string
+		for _, line := range strings.Split(out.String(), "\n") {
+			if line != "" {
+				got = append(got, line)
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("level %d printed:\n%s\nwant:\n%s", tt.level, strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+		}
+	}
+}
+
+func TestRunRejectsInvalidVerboseLevel(t *testing.T) {
+	cfg := config{
+		pipelineFile: writeTempDOT(t, `digraph g { start [shape=Mdiamond]; done [shape=Msquare]; start -> done }`),
+		retryPolicy:  "none",
+		artifactDir:  t.TempDir(),
+		dataDir:      t.TempDir(),
+		verboseLevel: 4,
+	}
+	if code := run(cfg); code != 1 {
+		t.Errorf("exit code = %d, want 1 for -verbose-level 4", code)
+	}
+}
+
 // --- example DOT files test ---
 
 func TestExampleDOTFilesParseAndValidate(t *testing.T) {
@@ -785,6 +878,44 @@ func TestParseServeArgsGlobal(t *testing.T) {
 	}
 }
 
+func TestParseServeArgsMaxLLMConcurrency(t *testing.T) {
+	scfg, ok := parseServeArgs([]string{"serve", "-max-llm-concurrency", "4"})
+	if !ok {
+		t.Fatal("expected serve subcommand to be detected")
+	}
+	if scfg.maxConcurrentLLM != 4 {
+		t.Fatalf("maxConcurrentLLM = %d, want 4", scfg.maxConcurrentLLM)
+	}
+}
+
+func TestParseServeArgsMaxEventHistory(t *testing.T) {
+	scfg, _ := parseServeArgs([]string{"serve"})
+	if scfg.maxEventHistory != web.DefaultMaxEventHistory {
+		t.Fatalf("default maxEventHistory = %d, want %d", scfg.maxEventHistory, web.DefaultMaxEventHistory)
+	}
+	scfg, _ = parseServeArgs([]string{"serve", "-max-event-history", "1000"})
+	if scfg.maxEventHistory != 1000 {
+		t.Fatalf("maxEventHistory = %d, want 1000", scfg.maxEventHistory)
+	}
+}
+
+func TestParseServeArgsNodeWebhook(t *testing.T) {
+	scfg, _ := parseServeArgs([]string{"serve", "-node-webhook", "http://127.0.0.1:9000/hook"})
+	if scfg.nodeWebhook != "http://127.0.0.1:9000/hook" {
+		t.Fatalf("nodeWebhook = %q, want the flag value", scfg.nodeWebhook)
+	}
+}
+
+func TestBuildWebServerRejectsBadTrustedProxy(t *testing.T) {
+	scfg, _ := parseServeArgs([]string{"serve", "-data-dir", t.TempDir(), "-trusted-proxy", "127.0.0.1,proxy.internal"})
+	if scfg.trustedProxies != "127.0.0.1,proxy.internal" {
+		t.Fatalf("trustedProxies = %q, want the flag value", scfg.trustedProxies)
+	}
+	if _, err := buildWebServer(scfg); err == nil || !strings.Contains(err.Error(), "proxy.internal") {
+		t.Fatalf("err = %v, want the bad proxy named", err)
+	}
+}
+
 func TestParseServeArgsDefaultLocal(t *testing.T) {
 	scfg, ok := parseServeArgs([]string{"serve"})
 	if !ok {
@@ -933,7 +1064,7 @@ func TestRunServeResolvesDefaultDataDir(t *testing.T) {
 // --- buildPipelineEngine tests ---
 
 func TestBuildPipelineEngineSimple(t *testing.T) {
-	engine, graph, err := buildPipelineEngine(validDOT, t.TempDir(), nil, "", "", nil, nil)
+	engine, graph, err := buildPipelineEngine(validDOT, t.TempDir(), nil, "", "", "", nil, nil)
 	if err != nil {
 		t.Fatalf("buildPipelineEngine failed: %v", err)
 	}
@@ -946,12 +1077,198 @@ func TestBuildPipelineEngineSimple(t *testing.T) {
 }
 
 func TestBuildPipelineEngineInvalidDOT(t *testing.T) {
-	_, _, err := buildPipelineEngine("not valid DOT {{{", t.TempDir(), nil, "", "", nil, nil)
+	_, _, err := buildPipelineEngine("not valid DOT {{{", t.TempDir(), nil, "", "", "", nil, nil)
 	if err == nil {
 		t.Fatal("expected error for invalid DOT")
 	}
 }
 
+const multiStartDOT = `digraph multi {
+    start_build [shape=Mdiamond]
+    start_deploy [shape=Mdiamond]
+    build [shape=box, prompt="build"]
+    deploy [shape=box, prompt="deploy"]
+    finish [shape=Msquare]
+    start_build -> build -> finish
+    start_deploy -> deploy -> finish
+}`
+
+func TestBuildPipelineEngineWithoutBackend(t *testing.T) {
+	const shellDOT = `digraph shell {
+    start [shape=Mdiamond]
+    greet [shape=parallelogram, tool_command="echo hello"]
+    finish [shape=Msquare]
+    start -> greet -> finish
+}`
+	engine, _, err := buildPipelineEngine(shellDOT, t.TempDir(), nil, "", "", "", nil, nil)
+	if err != nil {
+		t.Fatalf("codergen-free pipeline should build without a backend: %v", err)
+	}
+	result, err := engine.Run(context.Background())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if !slices.Contains(result.CompletedNodes, "greet") {
+		t.Errorf("completed nodes = %v, want greet to have run", result.CompletedNodes)
+	}
+}
+
+func TestBuildPipelineEngineCodergenRequiresBackend(t *testing.T) {
+	_, _, err := buildPipelineEngine(replayDOT, t.TempDir(), nil, "", "", "", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "no LLM API key found") || !strings.Contains(err.Error(), "build, plan") {
+		t.Fatalf("err = %v, want preflight failure naming the codergen nodes", err)
+	}
+
+	// A hook that supplies a codergen handler (e.g. -replay) satisfies the preflight.
+	stubBackend := func(r *pipeline.HandlerRegistry) { r.Register(&countingHandler{}) }
+	if _, _, err := buildPipelineEngine(replayDOT, t.TempDir(), nil, "", "", "", nil, nil, stubBackend); err != nil {
+		t.Errorf("unexpected error with stub backend: %v", err)
+	}
+}
+
+// reviewerHandler is a custom handler type selected with type="reviewer".
+type reviewerHandler struct{ calls int }
+
+func (h *reviewerHandler) Name() string { return "reviewer" }
+func (h *reviewerHandler) Execute(context.Context, *pipeline.Node, *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	h.calls++
+	return pipeline.Outcome{Status: pipeline.OutcomeSuccess}, nil
+}
+
+func TestBuildPipelineEngineTypeOverride(t *testing.T) {
+	source := `digraph p {
+		start [shape=Mdiamond]
+		review [shape=box, type="reviewer", prompt="look it over"]
+		end [shape=Msquare]
+		start -> review -> end
+	}`
+
+	_, _, err := buildPipelineEngine(source, t.TempDir(), nil, "", "", "", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), `node "review" resolves to type "reviewer"`) {
+		t.Fatalf("err = %v, want unregistered handler error for review", err)
+	}
+
+	// The box node runs the custom handler instead of codergen, so no LLM
+	// backend is needed.
+	reviewer := &reviewerHandler{}
+	hook := func(r *pipeline.HandlerRegistry) { r.Register(reviewer) }
+	engine, _, err := buildPipelineEngine(source, t.TempDir(), nil, "", "", "", nil, nil, hook)
+	if err != nil {
+		t.Fatalf("build engine: %v", err)
+	}
+	if _, err := engine.Run(context.Background()); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if reviewer.calls != 1 {
+		t.Errorf("reviewer handler calls = %d, want 1", reviewer.calls)
+	}
+}
+
+func TestBuildPipelineEngineEntrySelection(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    string
+		entry     string
+		wantStart string
+		wantErr   string
+	}{
+		{name: "single start unchanged", source: validDOT, wantStart: "start"},
+		{name: "single start explicit", source: validDOT, entry: "start", wantStart: "start"},
+		{name: "multi start explicit entry", source: multiStartDOT, entry: "start_deploy", wantStart: "start_deploy"},
+		{name: "multi start without entry", source: multiStartDOT, wantErr: "start_build, start_deploy"},
+		{name: "entry is not a start node", source: multiStartDOT, entry: "deploy", wantErr: `"deploy" is not a start node`},
+		{name: "entry does not exist", source: multiStartDOT, entry: "nope", wantErr: `"nope" not found`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubBackend := func(r *pipeline.HandlerRegistry) { r.Register(&countingHandler{}) }
+			_, graph, err := buildPipelineEngine(tt.source, t.TempDir(), nil, "", "", tt.entry, nil, nil, stubBackend)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildPipelineEngine failed: %v", err)
+			}
+			if graph.StartNode != tt.wantStart {
+				t.Errorf("StartNode = %q, want %q", graph.StartNode, tt.wantStart)
+			}
+		})
+	}
+}
+
+func TestMultiStartRunFollowsEntry(t *testing.T) {
+	backend := &countingHandler{}
+	install := func(r *pipeline.HandlerRegistry) { r.Register(backend) }
+	engine, _, err := buildPipelineEngine(multiStartDOT, t.TempDir(), nil, "", "", "start_deploy", nil, nil, install)
+	if err != nil {
+		t.Fatalf("buildPipelineEngine failed: %v", err)
+	}
+	result, err := engine.Run(context.Background())
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	for _, id := range result.CompletedNodes {
+		if id == "start_build" || id == "build" {
+			t.Errorf("completed nodes %v include the unselected entry flow", result.CompletedNodes)
+		}
+	}
+	if len(result.CompletedNodes) == 0 || result.CompletedNodes[0] != "start_deploy" {
+		t.Errorf("completed nodes = %v, want run to begin at start_deploy", result.CompletedNodes)
+	}
+	if got := backend.calls.Load(); got != 1 {
+		t.Errorf("backend calls = %d, want 1 (deploy only)", got)
+	}
+}
+
+func TestCustomShapeMappingRunsFromCustomStart(t *testing.T) {
+	source := `digraph p {
+		graph [shape_map="doublecircle=start, Mdiamond=, ellipse=codergen"]
+		begin [shape=doublecircle]
+		build [shape=ellipse, prompt="build it"]
+		end [shape=Msquare]
+		begin -> build -> end
+	}`
+
+	if _, diags, err := validator.ParseAndValidate(source); err != nil {
+		t.Fatal(err)
+	} else {
+		for _, d := range diags {
+			if d.Severity == "error" {
+				t.Errorf("validation error under the custom mapping: %s", d.Message)
+			}
+		}
+	}
+
+	backend := &countingHandler{}
+	install := func(r *pipeline.HandlerRegistry) { r.Register(backend) }
+	engine, graph, err := buildPipelineEngine(source, t.TempDir(), nil, "", "", "", nil, nil, install)
+	if err != nil {
+		t.Fatalf("buildPipelineEngine failed: %v", err)
+	}
+	if graph.StartNode != "begin" {
+		t.Errorf("StartNode = %q, want begin", graph.StartNode)
+	}
+	result, err := engine.Run(context.Background())
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if len(result.CompletedNodes) == 0 || result.CompletedNodes[0] != "begin" {
+		t.Errorf("completed nodes = %v, want run to begin at begin", result.CompletedNodes)
+	}
+	if got := backend.calls.Load(); got != 1 {
+		t.Errorf("backend calls = %d, want 1 (the ellipse node)", got)
+	}
+
+	bad := strings.Replace(source, "doublecircle=start,", "doublecircle,", 1)
+	if _, _, err := buildPipelineEngine(bad, t.TempDir(), nil, "", "", "", nil, nil, install); err == nil || !strings.Contains(err.Error(), "shape_map") {
+		t.Errorf("err = %v, want a shape_map error", err)
+	}
+}
+
 // --- printPipelineResult test ---
 
 func TestPrintPipelineResult(t *testing.T) {
@@ -963,3 +1280,47 @@ func TestPrintPipelineResult(t *testing.T) {
 		Context:        map[string]string{"_workdir": "/tmp/test"},
 	}, "(resumed)")
 }
+
+// requestCapturingCompleter records every request and answers with plain text.
+type requestCapturingCompleter struct {
+	mu       sync.Mutex
+	requests []trackerllm.Request
+}
+
+func (c *requestCapturingCompleter) Complete(_ context.Context, req *trackerllm.Request) (*trackerllm.Response, error) {
+	c.mu.Lock()
+	c.requests = append(c.requests, *req)
+	c.mu.Unlock()
+	return &trackerllm.Response{
+		Message:      trackerllm.AssistantMessage("done"),
+		FinishReason: trackerllm.FinishReason{Reason: "stop"},
+	}, nil
+}
+
+func TestDescriptionNotSentToLLM(t *testing.T) {
+	source := `digraph p {
+		start [shape=Mdiamond]
+		write [shape=box, label="Write", prompt="write the code", description="ZEBRA internal documentation"]
+		end [shape=Msquare]
+		start -> write -> end
+	}`
+	client := &requestCapturingCompleter{}
+	engine, _, err := buildPipelineEngine(source, t.TempDir(), client, "", "", "", nil, nil)
+	if err != nil {
+		t.Fatalf("build engine: %v", err)
+	}
+	if _, err := engine.Run(context.Background()); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if len(client.requests) == 0 {
+		t.Fatal("expected a codergen request")
+	}
+	for _, req := range client.requests {
+		for _, msg := range req.Messages {
+			if strings.Contains(msg.Text(), "ZEBRA") {
+				t.Errorf("description leaked into a %s message: %q", msg.Role, msg.Text())
+			}
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// ABOUTME: Wall-clock cap for a whole pipeline run (-max-runtime).
+// ABOUTME: Cancels the run with a distinct cause once the limit elapses, after the engine has checkpointed.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// errMaxRuntime is the cancellation cause recorded when a run outlives
+// -max-runtime.
+var errMaxRuntime = errors.New("pipeline exceeded max runtime")
+
+// withMaxRuntime returns a context that is cancelled with errMaxRuntime once
+// limit has elapsed. The cancellation goes through grace, so with
+// -cancel-grace the running node records a partial outcome first; either way
+// the engine saves a checkpoint and the run stays resumable. A non-positive
+// limit leaves the run unbounded.
+func withMaxRuntime(parent context.Context, limit time.Duration, grace *cancelGrace) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	if limit <= 0 {
+		return ctx, func() { cancel(context.Canceled) }
+	}
+	timer := time.AfterFunc(limit, func() {
+		grace.Request(func() { cancel(errMaxRuntime) })
+	})
+	return ctx, func() {
+		timer.Stop()
+		cancel(context.Canceled)
+	}
+}
+
+// maxRuntimeError rewrites runErr to name the max-runtime cause when the run
+// was stopped by -max-runtime. The result still matches context.Canceled, so
+// the run is persisted as cancelled and picked up by auto-resume.
+func maxRuntimeError(ctx context.Context, limit time.Duration, runErr error) error {
+	if runErr == nil || !errors.Is(context.Cause(ctx), errMaxRuntime) {
+		return runErr
+	}
+	if !errors.Is(runErr, context.Canceled) {
+		runErr = fmt.Errorf("%w: %w", context.Canceled, runErr)
+	}
+	return fmt.Errorf("%w (%s): %w", errMaxRuntime, limit, runErr)
+}
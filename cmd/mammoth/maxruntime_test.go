@@ -0,0 +1,74 @@
+// ABOUTME: Tests for -max-runtime: a run that outlives the cap aborts with the max-runtime cause.
+// ABOUTME: Verifies the checkpoint survives so the run can be resumed.
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/2389-research/tracker/pipeline"
+)
+
+func TestMaxRuntimeAbortsWithCauseAndCheckpoint(t *testing.T) {
+	source := `digraph slow {
+		start [shape=Mdiamond]
+		fast [shape=parallelogram, tool_command="echo ready"]
+		slow [shape=parallelogram, tool_command="sleep 10"]
+		after [shape=parallelogram, tool_command="echo never"]
+		done [shape=Msquare]
+		start -> fast -> slow -> after -> done
+	}`
+	dir := t.TempDir()
+	cpPath := filepath.Join(dir, "checkpoint.json")
+	engine, _, err := buildPipelineEngine(source, dir, nil, cpPath, dir, "", nil, nil)
+	if err != nil {
+		t.Fatalf("build engine: %v", err)
+	}
+
+	limit := 300 * time.Millisecond
+	ctx, cancel := withMaxRuntime(context.Background(), limit, nil)
+	defer cancel()
+
+	began := time.Now()
+	_, runErr := engine.Run(ctx)
+	runErr = maxRuntimeError(ctx, limit, runErr)
+	if elapsed := time.Since(began); elapsed > 5*time.Second {
+		t.Fatalf("run took %s; -max-runtime did not stop it", elapsed)
+	}
+	if !errors.Is(runErr, errMaxRuntime) {
+		t.Fatalf("run error = %v, want the max-runtime cause", runErr)
+	}
+	if !errors.Is(runErr, context.Canceled) {
+		t.Errorf("run error = %v, want it to stay a cancellation so the run is resumable", runErr)
+	}
+	if !strings.Contains(runErr.Error(), "pipeline exceeded max runtime") {
+		t.Errorf("run error = %q, want it to name the cause", runErr)
+	}
+
+	cp, err := pipeline.LoadCheckpoint(cpPath)
+	if err != nil {
+		t.Fatalf("load checkpoint: %v", err)
+	}
+	if !slices.Contains(cp.CompletedNodes, "fast") || slices.Contains(cp.CompletedNodes, "after") {
+		t.Errorf("checkpoint completed = %v, want fast done and after still pending", cp.CompletedNodes)
+	}
+}
+
+func TestMaxRuntimeUnset(t *testing.T) {
+	ctx, cancel := withMaxRuntime(context.Background(), 0, nil)
+	time.Sleep(10 * time.Millisecond)
+	if ctx.Err() != nil {
+		t.Fatal("a zero -max-runtime should leave the run unbounded")
+	}
+	cancel()
+
+	runErr := errors.New("boom")
+	if got := maxRuntimeError(ctx, 0, runErr); got != runErr {
+		t.Errorf("maxRuntimeError = %v, want the run error unchanged", got)
+	}
+}
@@ -0,0 +1,116 @@
+// ABOUTME: Per-provider default model resolution for codergen nodes that omit llm_model.
+// ABOUTME: Parses -default-model / MAMMOTH_DEFAULT_MODELS and wraps the codergen handler to fill in the model.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/2389-research/mammoth/providerpin"
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// activeProvider returns the provider the tracker LLM client will use by
+// default: the first provider, in priority order, with an API key set.
+// Returns "" when no keys are configured.
+func activeProvider() string {
+	for _, p := range providerpin.KeyEnv {
+		for _, env := range p.EnvVars {
+			if os.Getenv(env) != "" {
+				return p.Provider
+			}
+		}
+	}
+	return ""
+}
+
+// parseDefaultModels parses a comma-separated list of provider=model pairs,
+// e.g. "anthropic=claude-sonnet-4-5,openai=gpt-4o". Provider names are
+// lowercased. An empty spec yields a nil map.
+func parseDefaultModels(spec string) (map[string]string, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+	models := make(map[string]string)
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		provider, model, ok := strings.Cut(pair, "=")
+		provider = strings.ToLower(strings.TrimSpace(provider))
+		model = strings.TrimSpace(model)
+		if !ok || provider == "" || model == "" {
+			return nil, fmt.Errorf("invalid default model %q (want provider=model)", pair)
+		}
+		models[provider] = model
+	}
+	return models, nil
+}
+
+// resolveDefaultModels returns the provider→model map from the -default-model
+// flag value, falling back to the MAMMOTH_DEFAULT_MODELS environment variable.
+func resolveDefaultModels(flagValue string) (map[string]string, error) {
+	if strings.TrimSpace(flagValue) == "" {
+		flagValue = os.Getenv("MAMMOTH_DEFAULT_MODELS")
+	}
+	return parseDefaultModels(flagValue)
+}
+
+// defaultModelHook returns a registry hook that wraps the codergen handler so
+// nodes without an llm_model attribute use the default model for their
+// provider. A node's own llm_provider wins over the active provider, and a
+// node's own llm_model is never overridden. Returns nil when there are no
+// defaults to apply.
+func defaultModelHook(provider string, defaults map[string]string) func(*pipeline.HandlerRegistry) {
+	if len(defaults) == 0 {
+		return nil
+	}
+	return func(registry *pipeline.HandlerRegistry) {
+		if inner := registry.Get("codergen"); inner != nil {
+			registry.Register(&defaultModelHandler{inner: inner, provider: provider, defaults: defaults})
+		}
+	}
+}
+
+// defaultModelHandler fills in llm_model/llm_provider before delegating to
+// the wrapped codergen handler.
+type defaultModelHandler struct {
+	inner    pipeline.Handler
+	provider string
+	defaults map[string]string
+}
+
+func (h *defaultModelHandler) Name() string { return h.inner.Name() }
+
+func (h *defaultModelHandler) Execute(ctx context.Context, node *pipeline.Node, pctx *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	return h.inner.Execute(ctx, h.resolve(node), pctx)
+}
+
+// resolve returns node unchanged when it already names a model or no default
+// applies; otherwise it returns a copy with the default model filled in.
+func (h *defaultModelHandler) resolve(node *pipeline.Node) *pipeline.Node {
+	if strings.TrimSpace(node.Attrs["llm_model"]) != "" {
+		return node
+	}
+	provider := strings.TrimSpace(node.Attrs["llm_provider"])
+	if provider == "" {
+		provider = h.provider
+	}
+	model := h.defaults[strings.ToLower(provider)]
+	if model == "" {
+		return node
+	}
+
+	resolved := *node
+	resolved.Attrs = make(map[string]string, len(node.Attrs)+2)
+	for k, v := range node.Attrs {
+		resolved.Attrs[k] = v
+	}
+	resolved.Attrs["llm_model"] = model
+	resolved.Attrs["llm_provider"] = provider
+	return &resolved
+}
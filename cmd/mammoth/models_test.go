@@ -0,0 +1,148 @@
+// ABOUTME: Tests for per-provider default model resolution on codergen nodes.
+// ABOUTME: Covers spec parsing, active provider detection, and the model reaching the codergen handler.
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/2389-research/tracker/pipeline"
+)
+
+func TestParseDefaultModels(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "empty", spec: "", want: nil},
+		{name: "single", spec: "anthropic=claude-sonnet-4-5", want: map[string]string{"anthropic": "claude-sonnet-4-5"}},
+		{
+			name: "multiple with spaces and case",
+			spec: " Anthropic = claude-sonnet-4-5 , openai=gpt-4o,",
+			want: map[string]string{"anthropic": "claude-sonnet-4-5", "openai": "gpt-4o"},
+		},
+		{name: "missing equals", spec: "anthropic", wantErr: true},
+		{name: "missing model", spec: "openai=", wantErr: true},
+		{name: "missing provider", spec: "=gpt-4o", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDefaultModels(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("models[%q] = %q, want %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveDefaultModelsFallsBackToEnv(t *testing.T) {
+	t.Setenv("MAMMOTH_DEFAULT_MODELS", "gemini=gemini-2.5-pro")
+
+	got, err := resolveDefaultModels("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["gemini"] != "gemini-2.5-pro" {
+		t.Errorf("expected env fallback, got %v", got)
+	}
+
+	got, err = resolveDefaultModels("openai=gpt-4o")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["openai"] != "gpt-4o" || got["gemini"] != "" {
+		t.Errorf("expected flag to take precedence over env, got %v", got)
+	}
+}
+
+func TestActiveProvider(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{name: "none", env: nil, want: ""},
+		{name: "openai only", env: map[string]string{"OPENAI_API_KEY": "k"}, want: "openai"},
+		{name: "anthropic wins priority", env: map[string]string{"OPENAI_API_KEY": "k", "ANTHROPIC_API_KEY": "k"}, want: "anthropic"},
+		{name: "google key means gemini", env: map[string]string{"GOOGLE_API_KEY": "k"}, want: "gemini"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, k := range []string{"ANTHROPIC_API_KEY", "OPENAI_API_KEY", "GEMINI_API_KEY", "GOOGLE_API_KEY"} {
+				t.Setenv(k, tt.env[k])
+			}
+			if got := activeProvider(); got != tt.want {
+				t.Errorf("activeProvider() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// attrCapturingHandler is a codergen stand-in that records the model and
+// provider attributes it was invoked with, per node.
+type attrCapturingHandler struct {
+	mu        sync.Mutex
+	models    map[string]string
+	providers map[string]string
+}
+
+func (h *attrCapturingHandler) Name() string { return "codergen" }
+
+func (h *attrCapturingHandler) Execute(_ context.Context, node *pipeline.Node, _ *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.models[node.ID] = node.Attrs["llm_model"]
+	h.providers[node.ID] = node.Attrs["llm_provider"]
+	return pipeline.Outcome{Status: pipeline.OutcomeSuccess}, nil
+}
+
+func TestDefaultModelReachesCodergen(t *testing.T) {
+	const source = `digraph models {
+    start [shape=Mdiamond]
+    implicit [shape=box, prompt="uses the default"]
+    pinned [shape=box, prompt="pinned", llm_model="claude-opus-4"]
+    other [shape=box, prompt="other provider", llm_provider="gemini"]
+    finish [shape=Msquare]
+    start -> implicit -> pinned -> other -> finish
+}`
+	defaults := map[string]string{"openai": "gpt-4o", "gemini": "gemini-2.5-pro"}
+	backend := &attrCapturingHandler{models: map[string]string{}, providers: map[string]string{}}
+	install := func(r *pipeline.HandlerRegistry) { r.Register(backend) }
+
+	engine, _, err := buildPipelineEngine(source, t.TempDir(), nil, "", "", "", nil, nil, install, defaultModelHook("openai", defaults))
+	if err != nil {
+		t.Fatalf("build engine: %v", err)
+	}
+	if _, err := engine.Run(context.Background()); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if backend.models["implicit"] != "gpt-4o" || backend.providers["implicit"] != "openai" {
+		t.Errorf("implicit node got model=%q provider=%q, want gpt-4o/openai", backend.models["implicit"], backend.providers["implicit"])
+	}
+	if backend.models["pinned"] != "claude-opus-4" {
+		t.Errorf("pinned node model = %q, want node attribute to win", backend.models["pinned"])
+	}
+	if backend.models["other"] != "gemini-2.5-pro" || backend.providers["other"] != "gemini" {
+		t.Errorf("other node got model=%q provider=%q, want gemini-2.5-pro/gemini", backend.models["other"], backend.providers["other"])
+	}
+}
+
+func TestDefaultModelHookNoDefaults(t *testing.T) {
+	if defaultModelHook("anthropic", nil) != nil {
+		t.Error("expected nil hook when no defaults are configured")
+	}
+}
@@ -0,0 +1,134 @@
+// ABOUTME: Node execution filter for targeted reruns (-only / -skip).
+// ABOUTME: Filtered-out nodes are treated as satisfied, carrying forward recorded outcomes when replaying.
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/2389-research/mammoth/dot"
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// filteredHandlers lists the handler names subject to -only / -skip. Structural
+// handlers (start, exit, conditional, parallel fan-out/in) always run so that
+// routing through the graph is preserved.
+var filteredHandlers = []string{"codergen", "wait.human", "tool"}
+
+// nodeFilter decides which nodes execute during a targeted rerun.
+type nodeFilter struct {
+	only map[string]bool
+	skip map[string]bool
+}
+
+// parseNodeFilter builds a filter from comma-separated -only and -skip node
+// lists. Returns nil when both are empty. A node may not appear in both lists.
+func parseNodeFilter(only, skip string) (*nodeFilter, error) {
+	f := &nodeFilter{only: splitNodeList(only), skip: splitNodeList(skip)}
+	if len(f.only) == 0 && len(f.skip) == 0 {
+		return nil, nil
+	}
+	var both []string
+	for id := range f.only {
+		if f.skip[id] {
+			both = append(both, id)
+		}
+	}
+	if len(both) > 0 {
+		sort.Strings(both)
+		return nil, fmt.Errorf("node(s) %s listed in both -only and -skip", strings.Join(both, ", "))
+	}
+	return f, nil
+}
+
+// splitNodeList parses a comma-separated list of node IDs into a set.
+func splitNodeList(list string) map[string]bool {
+	set := make(map[string]bool)
+	for _, id := range strings.Split(list, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			set[id] = true
+		}
+	}
+	return set
+}
+
+// runs reports whether the node should execute its real handler.
+func (f *nodeFilter) runs(nodeID string) bool {
+	if f == nil {
+		return true
+	}
+	if f.skip[nodeID] {
+		return false
+	}
+	return len(f.only) == 0 || f.only[nodeID]
+}
+
+// checkNodes returns an error naming any filtered node IDs not in the graph,
+// so a typo doesn't silently turn a targeted rerun into a no-op.
+func (f *nodeFilter) checkNodes(g *dot.Graph) error {
+	if f == nil || g == nil {
+		return nil
+	}
+	var unknown []string
+	for _, set := range []map[string]bool{f.only, f.skip} {
+		for id := range set {
+			if _, ok := g.Nodes[id]; !ok {
+				unknown = append(unknown, id)
+			}
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown node(s) in -only/-skip: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// checkNodeFilterFlags validates the -only / -skip flags against the graph.
+func checkNodeFilterFlags(cfg config, g *dot.Graph) error {
+	filter, err := parseNodeFilter(cfg.onlyNodes, cfg.skipNodes)
+	if err != nil {
+		return err
+	}
+	return filter.checkNodes(g)
+}
+
+// nodeFilterHook returns a registry hook that wraps the filterable handlers
+// so only the nodes selected by filter execute. When prior is non-nil,
+// filtered-out nodes return their recorded outcome (including context
+// updates); otherwise they succeed without changing the context, leaving any
+// values restored from a checkpoint in place. Returns nil when filter is nil.
+func nodeFilterHook(filter *nodeFilter, prior *outcomeReplayer) func(*pipeline.HandlerRegistry) {
+	if filter == nil {
+		return nil
+	}
+	return func(registry *pipeline.HandlerRegistry) {
+		for _, name := range filteredHandlers {
+			if inner := registry.Get(name); inner != nil {
+				registry.Register(&filteredHandler{inner: inner, filter: filter, prior: prior})
+			}
+		}
+	}
+}
+
+// filteredHandler runs the wrapped handler for selected nodes and treats all
+// other nodes as already satisfied.
+type filteredHandler struct {
+	inner  pipeline.Handler
+	filter *nodeFilter
+	prior  *outcomeReplayer
+}
+
+func (h *filteredHandler) Name() string { return h.inner.Name() }
+
+func (h *filteredHandler) Execute(ctx context.Context, node *pipeline.Node, pctx *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	if h.filter.runs(node.ID) {
+		return h.inner.Execute(ctx, node, pctx)
+	}
+	if h.prior != nil {
+		return h.prior.next(node.ID)
+	}
+	return pipeline.Outcome{Status: pipeline.OutcomeSuccess}, nil
+}
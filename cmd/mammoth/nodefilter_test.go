@@ -0,0 +1,142 @@
+// ABOUTME: Tests for the -only / -skip node execution filter used for targeted reruns.
+// ABOUTME: Verifies filtered-out nodes don't execute and that recorded outcomes are carried forward.
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/2389-research/mammoth/dot"
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// nodeLoggingHandler is a codergen stand-in that records which nodes ran.
+type nodeLoggingHandler struct {
+	mu  sync.Mutex
+	ran []string
+}
+
+func (h *nodeLoggingHandler) Name() string { return "codergen" }
+
+func (h *nodeLoggingHandler) Execute(_ context.Context, node *pipeline.Node, _ *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.ran = append(h.ran, node.ID)
+	return pipeline.Outcome{
+		Status:         pipeline.OutcomeSuccess,
+		ContextUpdates: map[string]string{"out." + node.ID: "live " + node.ID},
+	}, nil
+}
+
+func TestParseNodeFilter(t *testing.T) {
+	f, err := parseNodeFilter("", " ")
+	if err != nil || f != nil {
+		t.Fatalf("empty lists = (%v, %v), want (nil, nil)", f, err)
+	}
+
+	f, err = parseNodeFilter("a, b", "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, want := range map[string]bool{"a": true, "b": true, "c": false, "d": false} {
+		if got := f.runs(id); got != want {
+			t.Errorf("runs(%q) = %v, want %v", id, got, want)
+		}
+	}
+
+	f, err = parseNodeFilter("", "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.runs("a") || f.runs("c") {
+		t.Errorf("skip-only filter: runs(a)=%v runs(c)=%v, want true/false", f.runs("a"), f.runs("c"))
+	}
+
+	if _, err := parseNodeFilter("a", "a"); err == nil {
+		t.Error("expected error when a node is in both -only and -skip")
+	}
+}
+
+func TestNodeFilterCheckNodes(t *testing.T) {
+	g, err := dot.Parse(replayDOT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkNodeFilterFlags(config{onlyNodes: "build"}, g); err != nil {
+		t.Errorf("known node: %v", err)
+	}
+	if err := checkNodeFilterFlags(config{skipNodes: "biuld"}, g); err == nil {
+		t.Error("expected error for unknown node in -skip")
+	}
+}
+
+func TestSkipNodesAreNotExecuted(t *testing.T) {
+	backend := &nodeLoggingHandler{}
+	install := func(r *pipeline.HandlerRegistry) { r.Register(backend) }
+	filter, err := parseNodeFilter("", "plan")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine, _, err := buildPipelineEngine(replayDOT, t.TempDir(), nil, "", "", "", nil, nil, install, nodeFilterHook(filter, nil))
+	if err != nil {
+		t.Fatalf("build engine: %v", err)
+	}
+	result, err := engine.Run(context.Background())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if !reflect.DeepEqual(backend.ran, []string{"build"}) {
+		t.Errorf("executed nodes = %v, want [build]", backend.ran)
+	}
+	completed := append([]string(nil), result.CompletedNodes...)
+	sort.Strings(completed)
+	if !reflect.DeepEqual(completed, []string{"build", "finish", "plan", "start"}) {
+		t.Errorf("completed nodes = %v, want routing preserved through skipped node", result.CompletedNodes)
+	}
+}
+
+func TestOnlyNodesCarryForwardRecordedOutcomes(t *testing.T) {
+	recPath := filepath.Join(t.TempDir(), "run.json")
+	recorder := newOutcomeRecorder(recPath)
+	for _, id := range []string{"plan", "build"} {
+		out := pipeline.Outcome{
+			Status:         pipeline.OutcomeSuccess,
+			ContextUpdates: map[string]string{"out." + id: "recorded " + id},
+		}
+		if err := recorder.record("codergen", id, out, nil); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+
+	hooks, err := registryHooks(config{onlyNodes: "build", replayPath: recPath}, t.TempDir())
+	if err != nil {
+		t.Fatalf("registry hooks: %v", err)
+	}
+	backend := &nodeLoggingHandler{}
+	install := func(r *pipeline.HandlerRegistry) { r.Register(backend) }
+
+	engine, _, err := buildPipelineEngine(replayDOT, t.TempDir(), nil, "", "", "", nil, nil, append([]func(*pipeline.HandlerRegistry){install}, hooks...)...)
+	if err != nil {
+		t.Fatalf("build engine: %v", err)
+	}
+	result, err := engine.Run(context.Background())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if !reflect.DeepEqual(backend.ran, []string{"build"}) {
+		t.Errorf("executed nodes = %v, want [build]", backend.ran)
+	}
+	if got := result.Context["out.plan"]; got != "recorded plan" {
+		t.Errorf("out.plan = %q, want prior outcome carried forward", got)
+	}
+	if got := result.Context["out.build"]; got != "live build" {
+		t.Errorf("out.build = %q, want live result", got)
+	}
+}
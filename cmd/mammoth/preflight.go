@@ -0,0 +1,57 @@
+// ABOUTME: Optional -preflight connectivity check against the selected LLM provider before a run starts.
+// ABOUTME: A misconfigured network fails within -preflight-timeout with "cannot reach <provider>" instead of hanging on the first node.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// defaultPreflightTimeout bounds the -preflight request when
+// -preflight-timeout is not given.
+const defaultPreflightTimeout = 5 * time.Second
+
+// providerDefaultBaseURLs are the endpoints the tracker adapters use when no
+// base URL override is configured.
+var providerDefaultBaseURLs = map[string]string{
+	"anthropic": "https://api.anthropic.com",
+	"openai":    "https://api.openai.com",
+	"gemini":    "https://generativelanguage.googleapis.com",
+}
+
+// preflightBackend checks that provider's API endpoint (its entry in
+// baseURLs, or the adapter default) answers within timeout. Any HTTP
+// response counts: the check is for connectivity, so it sends no API key and
+// spends no tokens. The stub backend and runs with no provider selected
+// have nothing to reach and always pass.
+func preflightBackend(ctx context.Context, provider string, baseURLs map[string]string, timeout time.Duration) error {
+	if provider == "" || provider == stubBackend {
+		return nil
+	}
+	base := baseURLs[provider]
+	if base == "" {
+		base = providerDefaultBaseURLs[provider]
+	}
+	if base == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, base, nil)
+	if err != nil {
+		return fmt.Errorf("cannot reach %s: invalid base URL %q: %w", provider, base, err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("cannot reach %s at %s: no response within %s", provider, base, timeout)
+		}
+		return fmt.Errorf("cannot reach %s at %s: %w", provider, base, err)
+	}
+	resp.Body.Close()
+	return nil
+}
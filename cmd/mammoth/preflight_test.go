@@ -0,0 +1,99 @@
+// ABOUTME: Tests for the -preflight connectivity check: reachable, refused, and silent endpoints.
+// ABOUTME: A listener that never answers must fail within the preflight timeout rather than the HTTP default.
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPreflightBackendReachable(t *testing.T) {
+	// Any response counts, even one rejecting the missing API key.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	if err := preflightBackend(context.Background(), "anthropic", map[string]string{"anthropic": srv.URL}, time.Second); err != nil {
+		t.Errorf("preflight against a live server: %v", err)
+	}
+}
+
+func TestPreflightBackendUnresponsiveFailsFast(t *testing.T) {
+	// The listener completes TCP handshakes from its backlog but never
+	// serves them, like a black-holed proxy.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	start := time.Now()
+	err = preflightBackend(context.Background(), "openai", map[string]string{"openai": "http://" + ln.Addr().String()}, 200*time.Millisecond)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("preflight took %s, want it bounded by the 200ms timeout", elapsed)
+	}
+	if err == nil || !strings.Contains(err.Error(), "cannot reach openai") || !strings.Contains(err.Error(), "no response within 200ms") {
+		t.Errorf("err = %v, want a cannot reach openai timeout", err)
+	}
+}
+
+func TestPreflightBackendRefused(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	err = preflightBackend(context.Background(), "gemini", map[string]string{"gemini": "http://" + addr}, time.Second)
+	if err == nil || !strings.Contains(err.Error(), "cannot reach gemini at http://"+addr) {
+		t.Errorf("err = %v, want a cannot reach gemini error", err)
+	}
+}
+
+func TestPreflightBackendSkipsWithoutProvider(t *testing.T) {
+	unreachable := map[string]string{"anthropic": "http://127.0.0.1:1"}
+	for _, provider := range []string{"", stubBackend} {
+		if err := preflightBackend(context.Background(), provider, unreachable, time.Millisecond); err != nil {
+			t.Errorf("preflight for %q: %v", provider, err)
+		}
+	}
+}
+
+func TestRunPreflightFailsBeforePipeline(t *testing.T) {
+	clearLLMKeys(t)
+	t.Setenv("ANTHROPIC_API_KEY", "test-key")
+	t.Setenv("ANTHROPIC_BASE_URL", "")
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	dir := t.TempDir()
+	path := dir + "/p.dot"
+	if err := os.WriteFile(path, []byte(stubDOT), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config{
+		pipelineFile:  path,
+		baseURL:       "http://" + ln.Addr().String(),
+		preflight:     true,
+		preflightWait: 200 * time.Millisecond,
+		dataDir:       dir,
+	}
+	start := time.Now()
+	if code := run(cfg); code != 1 {
+		t.Errorf("run exit code = %d, want 1", code)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("run took %s, want preflight to fail within its timeout", elapsed)
+	}
+}
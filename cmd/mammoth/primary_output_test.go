@@ -0,0 +1,51 @@
+// ABOUTME: Tests that CLI runs print their primary output and persist it with the run state.
+// ABOUTME: A tool-only pipeline runs through runPipeline, so no LLM backend is needed.
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/2389-research/mammoth/runstate"
+)
+
+func TestRunPipelinePersistsPrimaryOutput(t *testing.T) {
+	dotFile := writeTempDOT(t, `digraph result {
+    start [shape=Mdiamond]
+    answer [shape=parallelogram, tool_command="echo 42"]
+    finish [shape=Msquare]
+    start -> answer -> finish
+}`)
+	dataDir := t.TempDir()
+	if code := runPipeline(config{pipelineFile: dotFile, retryPolicy: "none", dataDir: dataDir, artifactDir: t.TempDir()}); code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+
+	store, err := runstate.NewFSRunStateStore(filepath.Join(dataDir, "runs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	runs, err := store.List()
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("runs = %v, err = %v; want one run", runs, err)
+	}
+	if runs[0].ResultNode != "answer" || strings.TrimSpace(runs[0].PrimaryOutput) != "42" {
+		t.Errorf("persisted (%q, %q), want the terminal tool's output", runs[0].ResultNode, runs[0].PrimaryOutput)
+	}
+}
+
+func TestPrintPrimaryOutput(t *testing.T) {
+	var buf bytes.Buffer
+	printPrimaryOutput(&buf, "answer", "42")
+	if got := buf.String(); got != "Result (answer):\n42\n" {
+		t.Errorf("output = %q, want the node and its output", got)
+	}
+
+	buf.Reset()
+	printPrimaryOutput(&buf, "", "")
+	if buf.Len() != 0 {
+		t.Errorf("output = %q, want nothing for a run without output", buf.String())
+	}
+}
@@ -0,0 +1,65 @@
+// ABOUTME: Tests for -profile / -trace: profile and trace files are written around a pipeline run.
+// ABOUTME: Verifies output is flushed after both successful and failing runs.
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunWritesProfileAndTrace(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		wantExit int
+	}{
+		{name: "successful run", source: validDOT, wantExit: 0},
+		{name: "failing run", source: "this is not valid DOT at all {{{", wantExit: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			cfg := config{
+				pipelineFile: writeTempDOT(t, tt.source),
+				retryPolicy:  "none",
+				dataDir:      filepath.Join(dir, "data"),
+				cpuProfile:   filepath.Join(dir, "cpu.prof"),
+				tracePath:    filepath.Join(dir, "trace.out"),
+			}
+			if got := run(cfg); got != tt.wantExit {
+				t.Fatalf("exit code = %d, want %d", got, tt.wantExit)
+			}
+			for _, path := range []string{cfg.cpuProfile, cfg.tracePath} {
+				info, err := os.Stat(path)
+				if err != nil {
+					t.Fatalf("expected %s to exist: %v", filepath.Base(path), err)
+				}
+				if info.Size() == 0 {
+					t.Errorf("%s is empty", filepath.Base(path))
+				}
+			}
+		})
+	}
+}
+
+func TestStartProfilingBadPath(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "no", "such", "dir", "cpu.prof")
+	if _, err := startProfiling(missing, ""); err == nil {
+		t.Error("expected error for unwritable profile path")
+	}
+
+	// A failed trace setup must release the CPU profiler it already started.
+	ok := filepath.Join(t.TempDir(), "cpu.prof")
+	if _, err := startProfiling(ok, missing); err == nil {
+		t.Fatal("expected error for unwritable trace path")
+	}
+	stop, err := startProfiling(ok, "")
+	if err != nil {
+		t.Fatalf("CPU profiler should be free after failed setup: %v", err)
+	}
+	if err := stop(); err != nil {
+		t.Errorf("stop: %v", err)
+	}
+}
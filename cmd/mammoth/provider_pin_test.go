@@ -0,0 +1,64 @@
+// ABOUTME: Tests for the graph-level provider pin: pinned nodes use it, node attributes win, preflight checks keys.
+// ABOUTME: Uses the provider-recording stub completer from the fallback tests.
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestProviderPinSetsNodeProviders(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "sk-test")
+	source := `digraph p {
+		graph [provider="OpenAI"]
+		start [shape=Mdiamond]
+		pinned [shape=box, prompt="a"]
+		own_llm [shape=box, prompt="b", llm_provider="gemini"]
+		own_short [shape=box, prompt="c", provider="anthropic"]
+		done [shape=Msquare]
+		start -> pinned -> own_llm -> own_short -> done
+	}`
+	client := &providerStubCompleter{}
+	engine, _, err := buildPipelineEngine(source, t.TempDir(), client, "", "", "", nil, nil)
+	if err != nil {
+		t.Fatalf("build engine: %v", err)
+	}
+	if _, err := engine.Run(context.Background()); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	var providers []string
+	for _, call := range client.calls {
+		providers = append(providers, strings.SplitN(call, "/", 2)[0])
+	}
+	want := []string{"openai", "gemini", "anthropic"}
+	if strings.Join(providers, ",") != strings.Join(want, ",") {
+		t.Errorf("providers = %v, want %v", providers, want)
+	}
+}
+
+func TestProviderPinMissingCredentialsFailsPreflight(t *testing.T) {
+	t.Setenv("GEMINI_API_KEY", "")
+	t.Setenv("GOOGLE_API_KEY", "")
+	source := `digraph p {
+		graph [provider="gemini"]
+		start [shape=Mdiamond]
+		work [shape=box, prompt="a"]
+		done [shape=Msquare]
+		start -> work -> done
+	}`
+	client := &providerStubCompleter{}
+	_, _, err := buildPipelineEngine(source, t.TempDir(), client, "", "", "", nil, nil)
+	if err == nil {
+		t.Fatal("expected preflight to fail without gemini credentials")
+	}
+	for _, want := range []string{`"gemini"`, "GEMINI_API_KEY or GOOGLE_API_KEY", "mammoth setup"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+	if len(client.calls) != 0 {
+		t.Errorf("backend called %d times before preflight failed", len(client.calls))
+	}
+}
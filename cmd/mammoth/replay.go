@@ -0,0 +1,200 @@
+// ABOUTME: Record/replay support for pipeline runs: captures backend and human-gate outcomes per node.
+// ABOUTME: Replay feeds recorded outcomes back instead of calling the LLM backend or prompting a human.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// recordingVersion is the on-disk format version for run recordings.
+const recordingVersion = 1
+
+// recordedHandlers lists the handler names whose outcomes are captured and
+// replayed. These are the handlers that call out to an LLM backend or a human.
+var recordedHandlers = []string{"codergen", "wait.human"}
+
+// runRecording is the JSON document written by --record and read by --replay.
+// Outcomes are keyed by node ID and stored in execution order, so nodes that
+// run more than once (retries, loops) replay each attempt in sequence.
+type runRecording struct {
+	Version int                          `json:"version"`
+	Nodes   map[string][]recordedOutcome `json:"nodes"`
+}
+
+// recordedOutcome is a serializable snapshot of a handler's Outcome and error.
+type recordedOutcome struct {
+	Handler            string            `json:"handler"`
+	Status             string            `json:"status"`
+	ContextUpdates     map[string]string `json:"context_updates,omitempty"`
+	PreferredLabel     string            `json:"preferred_label,omitempty"`
+	SuggestedNextNodes []string          `json:"suggested_next_nodes,omitempty"`
+	Error              string            `json:"error,omitempty"`
+}
+
+// outcome converts the recorded snapshot back into a pipeline Outcome and error.
+func (r recordedOutcome) outcome() (pipeline.Outcome, error) {
+	out := pipeline.Outcome{
+		Status:             r.Status,
+		ContextUpdates:     r.ContextUpdates,
+		PreferredLabel:     r.PreferredLabel,
+		SuggestedNextNodes: r.SuggestedNextNodes,
+	}
+	if r.Error != "" {
+		return out, errors.New(r.Error)
+	}
+	return out, nil
+}
+
+// outcomeRecorder accumulates handler outcomes and rewrites the recording
+// file after each one, so a crashed or cancelled run still leaves a usable
+// recording behind.
+type outcomeRecorder struct {
+	mu        sync.Mutex
+	path      string
+	recording runRecording
+}
+
+// newOutcomeRecorder creates a recorder that writes to path.
+func newOutcomeRecorder(path string) *outcomeRecorder {
+	return &outcomeRecorder{
+		path: path,
+		recording: runRecording{
+			Version: recordingVersion,
+			Nodes:   make(map[string][]recordedOutcome),
+		},
+	}
+}
+
+// record appends an outcome for nodeID and flushes the recording to disk.
+func (r *outcomeRecorder) record(handler, nodeID string, out pipeline.Outcome, err error) error {
+	entry := recordedOutcome{
+		Handler:            handler,
+		Status:             out.Status,
+		ContextUpdates:     out.ContextUpdates,
+		PreferredLabel:     out.PreferredLabel,
+		SuggestedNextNodes: out.SuggestedNextNodes,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.recording.Nodes[nodeID] = append(r.recording.Nodes[nodeID], entry)
+	data, mErr := json.MarshalIndent(r.recording, "", "  ")
+	if mErr != nil {
+		return fmt.Errorf("marshal recording: %w", mErr)
+	}
+	if wErr := os.WriteFile(r.path, data, 0644); wErr != nil {
+		return fmt.Errorf("write recording: %w", wErr)
+	}
+	return nil
+}
+
+// outcomeReplayer serves recorded outcomes back in the order they were captured.
+type outcomeReplayer struct {
+	mu      sync.Mutex
+	nodes   map[string][]recordedOutcome
+	cursors map[string]int
+}
+
+// loadOutcomeReplayer reads a recording file written by outcomeRecorder.
+func loadOutcomeReplayer(path string) (*outcomeReplayer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read recording: %w", err)
+	}
+	var rec runRecording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("parse recording %s: %w", path, err)
+	}
+	if rec.Version != recordingVersion {
+		return nil, fmt.Errorf("unsupported recording version %d (want %d)", rec.Version, recordingVersion)
+	}
+	if rec.Nodes == nil {
+		rec.Nodes = make(map[string][]recordedOutcome)
+	}
+	return &outcomeReplayer{nodes: rec.Nodes, cursors: make(map[string]int)}, nil
+}
+
+// next returns the next recorded outcome for nodeID.
+func (r *outcomeReplayer) next(nodeID string) (pipeline.Outcome, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries := r.nodes[nodeID]
+	idx := r.cursors[nodeID]
+	if idx >= len(entries) {
+		return pipeline.Outcome{}, fmt.Errorf("replay: no recorded outcome for node %q (attempt %d)", nodeID, idx+1)
+	}
+	r.cursors[nodeID] = idx + 1
+	return entries[idx].outcome()
+}
+
+// recordingHandler wraps a handler and records every outcome it produces.
+type recordingHandler struct {
+	inner    pipeline.Handler
+	recorder *outcomeRecorder
+}
+
+func (h *recordingHandler) Name() string { return h.inner.Name() }
+
+func (h *recordingHandler) Execute(ctx context.Context, node *pipeline.Node, pctx *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	out, err := h.inner.Execute(ctx, node, pctx)
+	if recErr := h.recorder.record(h.inner.Name(), node.ID, out, err); recErr != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", recErr)
+	}
+	return out, err
+}
+
+// replayHandler stands in for a backend handler and returns recorded outcomes.
+type replayHandler struct {
+	name     string
+	replayer *outcomeReplayer
+}
+
+func (h *replayHandler) Name() string { return h.name }
+
+func (h *replayHandler) Execute(ctx context.Context, node *pipeline.Node, _ *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	if err := ctx.Err(); err != nil {
+		return pipeline.Outcome{}, err
+	}
+	return h.replayer.next(node.ID)
+}
+
+// recordReplayHook returns a registry hook implementing --record or --replay,
+// or nil when neither is set. The two flags are mutually exclusive.
+func recordReplayHook(recordPath, replayPath string) (func(*pipeline.HandlerRegistry), error) {
+	switch {
+	case recordPath != "" && replayPath != "":
+		return nil, errors.New("-record and -replay cannot be used together")
+	case recordPath != "":
+		recorder := newOutcomeRecorder(recordPath)
+		return func(registry *pipeline.HandlerRegistry) {
+			for _, name := range recordedHandlers {
+				if inner := registry.Get(name); inner != nil {
+					registry.Register(&recordingHandler{inner: inner, recorder: recorder})
+				}
+			}
+		}, nil
+	case replayPath != "":
+		replayer, err := loadOutcomeReplayer(replayPath)
+		if err != nil {
+			return nil, err
+		}
+		return func(registry *pipeline.HandlerRegistry) {
+			for _, name := range recordedHandlers {
+				registry.Register(&replayHandler{name: name, replayer: replayer})
+			}
+		}, nil
+	}
+	return nil, nil
+}
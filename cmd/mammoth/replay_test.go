@@ -0,0 +1,134 @@
+// ABOUTME: Tests for pipeline record/replay: recording handler outcomes to JSON and replaying them.
+// ABOUTME: Verifies a replayed run completes the same nodes without invoking the backend handler.
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/2389-research/tracker/pipeline"
+)
+
+const replayDOT = `digraph replay {
+    start [shape=Mdiamond]
+    plan [shape=box, prompt="plan it"]
+    build [shape=box, prompt="build it"]
+    finish [shape=Msquare]
+    start -> plan -> build -> finish
+}`
+
+// countingHandler is a codergen stand-in that counts invocations and writes
+// a per-node context value.
+type countingHandler struct {
+	calls atomic.Int32
+}
+
+func (h *countingHandler) Name() string { return "codergen" }
+
+func (h *countingHandler) Execute(_ context.Context, node *pipeline.Node, _ *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	h.calls.Add(1)
+	return pipeline.Outcome{
+		Status:         pipeline.OutcomeSuccess,
+		ContextUpdates: map[string]string{"out." + node.ID: "done " + node.ID},
+	}, nil
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	recPath := filepath.Join(t.TempDir(), "run.json")
+	backend := &countingHandler{}
+	installBackend := func(r *pipeline.HandlerRegistry) { r.Register(backend) }
+
+	recordHook, err := recordReplayHook(recPath, "")
+	if err != nil {
+		t.Fatalf("record hook: %v", err)
+	}
+	engine, _, err := buildPipelineEngine(replayDOT, t.TempDir(), nil, "", "", "", nil, nil, installBackend, recordHook)
+	if err != nil {
+		t.Fatalf("build engine: %v", err)
+	}
+	recorded, err := engine.Run(context.Background())
+	if err != nil {
+		t.Fatalf("record run: %v", err)
+	}
+	if got := backend.calls.Load(); got != 2 {
+		t.Fatalf("backend calls during record = %d, want 2", got)
+	}
+
+	replayHook, err := recordReplayHook("", recPath)
+	if err != nil {
+		t.Fatalf("replay hook: %v", err)
+	}
+	engine, _, err = buildPipelineEngine(replayDOT, t.TempDir(), nil, "", "", "", nil, nil, installBackend, replayHook)
+	if err != nil {
+		t.Fatalf("build engine: %v", err)
+	}
+	replayed, err := engine.Run(context.Background())
+	if err != nil {
+		t.Fatalf("replay run: %v", err)
+	}
+
+	if got := backend.calls.Load(); got != 2 {
+		t.Errorf("backend calls after replay = %d, want 2 (replay must not call the backend)", got)
+	}
+	if !reflect.DeepEqual(replayed.CompletedNodes, recorded.CompletedNodes) {
+		t.Errorf("replayed completed nodes = %v, want %v", replayed.CompletedNodes, recorded.CompletedNodes)
+	}
+	if replayed.Context["out.build"] != "done build" {
+		t.Errorf("replayed context out.build = %q, want %q", replayed.Context["out.build"], "done build")
+	}
+}
+
+func TestReplayMissingOutcomeFails(t *testing.T) {
+	recPath := filepath.Join(t.TempDir(), "empty.json")
+	recorder := newOutcomeRecorder(recPath)
+	if err := recorder.record("codergen", "plan", pipeline.Outcome{Status: pipeline.OutcomeSuccess}, nil); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+
+	replayer, err := loadOutcomeReplayer(recPath)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if _, err := replayer.next("plan"); err != nil {
+		t.Fatalf("first replay of plan: %v", err)
+	}
+	_, err = replayer.next("plan")
+	if err == nil || !strings.Contains(err.Error(), `no recorded outcome for node "plan"`) {
+		t.Errorf("second replay of plan error = %v, want missing outcome error", err)
+	}
+}
+
+func TestReplayReproducesRecordedError(t *testing.T) {
+	recPath := filepath.Join(t.TempDir(), "fail.json")
+	recorder := newOutcomeRecorder(recPath)
+	if err := recorder.record("codergen", "build", pipeline.Outcome{Status: pipeline.OutcomeFail}, errors.New("backend exploded")); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+
+	replayer, err := loadOutcomeReplayer(recPath)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	out, err := replayer.next("build")
+	if err == nil || err.Error() != "backend exploded" {
+		t.Errorf("replayed error = %v, want %q", err, "backend exploded")
+	}
+	if out.Status != pipeline.OutcomeFail {
+		t.Errorf("replayed status = %q, want %q", out.Status, pipeline.OutcomeFail)
+	}
+}
+
+func TestRecordReplayHookMutuallyExclusive(t *testing.T) {
+	if _, err := recordReplayHook("a.json", "b.json"); err == nil {
+		t.Error("expected error when both record and replay are set")
+	}
+	hook, err := recordReplayHook("", "")
+	if err != nil || hook != nil {
+		t.Errorf("recordReplayHook with no paths = (%v, %v), want (nil, nil)", hook != nil, err)
+	}
+}
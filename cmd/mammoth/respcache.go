@@ -0,0 +1,147 @@
+// ABOUTME: Opt-in filesystem cache of LLM responses (-cache-dir) so identical codergen requests are not paid for twice.
+// ABOUTME: Entries are keyed by a hash of the full request and record the provider and model that produced them.
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/2389-research/mammoth/streaming"
+	"github.com/2389-research/tracker/agent"
+	trackerllm "github.com/2389-research/tracker/llm"
+)
+
+// cacheEntry is the on-disk form of one cached response.
+type cacheEntry struct {
+	Provider string              `json:"provider"`
+	Model    string              `json:"model"`
+	Response trackerllm.Response `json:"response"`
+}
+
+// cachingCompleter serves requests from a directory of cached responses,
+// calling the wrapped backend only on a miss. Only successful responses are
+// stored. Requests are keyed after every other wrapper has shaped them, so
+// changing the prompt, tools, parameters, provider, or seed misses the cache.
+type cachingCompleter struct {
+	inner agent.Completer
+	dir   string
+}
+
+// withResponseCache wraps c with a response cache in dir. It returns c
+// unchanged when dir is empty or there is no client.
+func withResponseCache(c agent.Completer, dir string) (agent.Completer, error) {
+	if c == nil || dir == "" {
+		return c, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create cache dir: %w", err)
+	}
+	return &cachingCompleter{inner: c, dir: dir}, nil
+}
+
+func (c *cachingCompleter) Complete(ctx context.Context, req *trackerllm.Request) (*trackerllm.Response, error) {
+	key, err := requestCacheKey(req)
+	if err != nil {
+		return c.inner.Complete(ctx, req)
+	}
+	path := filepath.Join(c.dir, key+".json")
+	if resp, ok := c.load(path, req); ok {
+		return resp, nil
+	}
+
+	resp, err := c.inner.Complete(ctx, req)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+	c.store(path, req, resp)
+	return resp, nil
+}
+
+// Stream serves a cache hit as a replayed stream and caches the accumulated
+// response of a successful backend stream, so streaming nodes share the cache
+// with Complete.
+func (c *cachingCompleter) Stream(ctx context.Context, req *trackerllm.Request) <-chan trackerllm.StreamEvent {
+	s, canStream := c.inner.(streaming.Streamer)
+	if !canStream {
+		return streaming.ResponseStream(c.Complete(ctx, req))
+	}
+	key, err := requestCacheKey(req)
+	if err != nil {
+		return s.Stream(ctx, req)
+	}
+	path := filepath.Join(c.dir, key+".json")
+	if resp, ok := c.load(path, req); ok {
+		return streaming.ResponseStream(resp, nil)
+	}
+
+	acc := trackerllm.NewStreamAccumulator()
+	var full *trackerllm.Response
+	failed := false
+	return streaming.TeeStream(s.Stream(ctx, req), func(evt trackerllm.StreamEvent) {
+		if evt.Err != nil || evt.Type == trackerllm.EventError {
+			failed = true
+		}
+		if evt.FullResponse != nil {
+			full = evt.FullResponse
+		}
+		acc.Process(evt)
+	}, func() {
+		if failed || ctx.Err() != nil {
+			return
+		}
+		resp := acc.Response()
+		if full != nil {
+			resp = *full
+		}
+		c.store(path, req, &resp)
+	})
+}
+
+// load returns the cached response at path. An entry made for a different
+// model than the request asks for is stale and counts as a miss.
+func (c *cachingCompleter) load(path string, req *trackerllm.Request) (*trackerllm.Response, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	if entry.Model != req.Model {
+		return nil, false
+	}
+	return &entry.Response, true
+}
+
+// store writes resp to path. Failures only cost a future cache hit, so they
+// are reported as warnings.
+func (c *cachingCompleter) store(path string, req *trackerllm.Request, resp *trackerllm.Response) {
+	provider := resp.Provider
+	if provider == "" {
+		provider = req.Provider
+	}
+	data, err := json.MarshalIndent(cacheEntry{Provider: provider, Model: req.Model, Response: *resp}, "", "  ")
+	if err == nil {
+		err = os.WriteFile(path, data, 0644)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not cache response: %v\n", err)
+	}
+}
+
+// requestCacheKey hashes the full request: model, provider, messages, tools,
+// and every generation parameter.
+func requestCacheKey(req *trackerllm.Request) (string, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
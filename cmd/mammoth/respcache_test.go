@@ -0,0 +1,121 @@
+// ABOUTME: Tests for the -cache-dir response cache: hits skip the backend, misses and stale entries call it.
+// ABOUTME: Uses a counting stub completer in place of a real provider.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	trackerllm "github.com/2389-research/tracker/llm"
+)
+
+func TestResponseCacheServesIdenticalRequest(t *testing.T) {
+	dir := t.TempDir()
+	backend := &providerStubCompleter{}
+	c, err := withResponseCache(backend, dir)
+	if err != nil {
+		t.Fatalf("withResponseCache: %v", err)
+	}
+	newReq := func() *trackerllm.Request {
+		return &trackerllm.Request{
+			Model:    "gpt-4o",
+			Provider: "openai",
+			Messages: []trackerllm.Message{trackerllm.UserMessage("write a plan")},
+		}
+	}
+
+	first, err := c.Complete(context.Background(), newReq())
+	if err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	second, err := c.Complete(context.Background(), newReq())
+	if err != nil {
+		t.Fatalf("second call: %v", err)
+	}
+	if len(backend.calls) != 1 {
+		t.Fatalf("backend called %d times, want 1 (second call served from cache)", len(backend.calls))
+	}
+	if second.Text() != first.Text() || second.Provider != "openai" {
+		t.Errorf("cached response = %+v, want %+v", second, first)
+	}
+
+	different := newReq()
+	different.Messages = append(different.Messages, trackerllm.UserMessage("and test it"))
+	if _, err := c.Complete(context.Background(), different); err != nil {
+		t.Fatalf("third call: %v", err)
+	}
+	if len(backend.calls) != 2 {
+		t.Errorf("a different request should miss the cache; backend calls = %d", len(backend.calls))
+	}
+}
+
+func TestResponseCacheRecordsProviderAndInvalidatesOnModel(t *testing.T) {
+	dir := t.TempDir()
+	backend := &providerStubCompleter{}
+	c, err := withResponseCache(backend, dir)
+	if err != nil {
+		t.Fatalf("withResponseCache: %v", err)
+	}
+	req := &trackerllm.Request{Model: "gpt-4o", Provider: "openai", Messages: []trackerllm.Message{trackerllm.UserMessage("hi")}}
+	if _, err := c.Complete(context.Background(), req); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+
+	key, err := requestCacheKey(req)
+	if err != nil {
+		t.Fatalf("requestCacheKey: %v", err)
+	}
+	path := filepath.Join(dir, key+".json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read cache entry: %v", err)
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatalf("decode cache entry: %v", err)
+	}
+	if entry.Provider != "openai" || entry.Model != "gpt-4o" {
+		t.Errorf("entry provider/model = %q/%q, want openai/gpt-4o", entry.Provider, entry.Model)
+	}
+
+	// An entry produced by another model is stale even under the same key.
+	entry.Model = "gpt-3.5-turbo"
+	data, _ = json.Marshal(entry)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("rewrite cache entry: %v", err)
+	}
+	if _, err := c.Complete(context.Background(), req); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if len(backend.calls) != 2 {
+		t.Errorf("stale entry should call the backend; calls = %d", len(backend.calls))
+	}
+}
+
+func TestResponseCacheSkipsErrors(t *testing.T) {
+	backend := &providerStubCompleter{errs: map[string]error{"openai": unavailable("openai")}}
+	c, err := withResponseCache(backend, t.TempDir())
+	if err != nil {
+		t.Fatalf("withResponseCache: %v", err)
+	}
+	req := &trackerllm.Request{Model: "gpt-4o", Provider: "openai"}
+	for range 2 {
+		if _, err := c.Complete(context.Background(), req); err == nil {
+			t.Fatal("expected the backend error")
+		}
+	}
+	if len(backend.calls) != 2 {
+		t.Errorf("errors must not be cached; backend calls = %d", len(backend.calls))
+	}
+}
+
+func TestResponseCacheDisabled(t *testing.T) {
+	backend := &providerStubCompleter{}
+	c, err := withResponseCache(backend, "")
+	if err != nil || c != backend {
+		t.Errorf("empty dir should return the backend unchanged, got %T, %v", c, err)
+	}
+}
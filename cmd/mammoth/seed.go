@@ -0,0 +1,145 @@
+// ABOUTME: Per-run sampling seed (-seed) applied to every LLM request for reproducible agent behavior.
+// ABOUTME: Only providers whose APIs accept a seed receive it; the run state records, and the CLI warns about, those that did not.
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/2389-research/mammoth/runstate"
+	"github.com/2389-research/mammoth/streaming"
+	"github.com/2389-research/tracker/agent"
+	trackerllm "github.com/2389-research/tracker/llm"
+)
+
+// seedProviders lists the providers whose APIs accept a sampling seed. The
+// seed travels in the provider's ProviderOptions entry, which the tracker
+// adapter merges into the request body. None qualifies today: OpenAI is
+// served through the Responses API, which has no seed parameter and rejects
+// unknown fields, and the Anthropic and Gemini adapters send none. Every
+// provider that serves a seeded run is therefore recorded as unsupported.
+var seedProviders = map[string]bool{}
+
+// parseSeed parses the -seed flag value; "" means no seed.
+func parseSeed(raw string) (*int64, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	n, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -seed %q: want an integer", raw)
+	}
+	return &n, nil
+}
+
+// seedCompleter sets the run's seed on every request and remembers which
+// providers served requests without seed support.
+type seedCompleter struct {
+	inner agent.Completer
+	seed  int64
+
+	mu          sync.Mutex
+	unsupported map[string]bool
+}
+
+// withSeed wraps c so every request carries seed. It returns c unchanged
+// (and a nil seedCompleter) when there is no seed or no client.
+func withSeed(c agent.Completer, seed *int64) (agent.Completer, *seedCompleter) {
+	if c == nil || seed == nil {
+		return c, nil
+	}
+	sc := &seedCompleter{inner: c, seed: *seed, unsupported: map[string]bool{}}
+	return sc, sc
+}
+
+func (c *seedCompleter) Complete(ctx context.Context, req *trackerllm.Request) (*trackerllm.Response, error) {
+	c.applySeed(req)
+	resp, err := c.inner.Complete(ctx, req)
+	if resp != nil {
+		c.observe(resp.Provider)
+	}
+	return resp, err
+}
+
+// Stream seeds the request like Complete, so streaming nodes stay
+// reproducible. Backends without Stream are completed and replayed.
+func (c *seedCompleter) Stream(ctx context.Context, req *trackerllm.Request) <-chan trackerllm.StreamEvent {
+	c.applySeed(req)
+	s, ok := c.inner.(streaming.Streamer)
+	if !ok {
+		resp, err := c.inner.Complete(ctx, req)
+		if resp != nil {
+			c.observe(resp.Provider)
+		}
+		return streaming.ResponseStream(resp, err)
+	}
+	provider := req.Provider
+	return streaming.TeeStream(s.Stream(ctx, req), func(evt trackerllm.StreamEvent) {
+		if evt.FullResponse != nil && evt.FullResponse.Provider != "" {
+			provider = evt.FullResponse.Provider
+		}
+	}, func() { c.observe(provider) })
+}
+
+// applySeed sets the seed in every seed-capable provider's options.
+func (c *seedCompleter) applySeed(req *trackerllm.Request) {
+	// Copy the option maps rather than mutating ones the caller may share.
+	opts := maps.Clone(req.ProviderOptions)
+	if opts == nil {
+		opts = map[string]any{}
+	}
+	for provider := range seedProviders {
+		providerOpts, _ := opts[provider].(map[string]any)
+		providerOpts = maps.Clone(providerOpts)
+		if providerOpts == nil {
+			providerOpts = map[string]any{}
+		}
+		providerOpts["seed"] = c.seed
+		opts[provider] = providerOpts
+	}
+	req.ProviderOptions = opts
+}
+
+// observe remembers provider if it served a request without seed support.
+func (c *seedCompleter) observe(provider string) {
+	if provider == "" || seedProviders[provider] {
+		return
+	}
+	c.mu.Lock()
+	c.unsupported[provider] = true
+	c.mu.Unlock()
+}
+
+// unsupportedProviders returns the providers that served requests but ignore
+// the seed, sorted. Safe on a nil receiver.
+func (c *seedCompleter) unsupportedProviders() []string {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Sorted(maps.Keys(c.unsupported))
+}
+
+// recordSeed stores the run's seed, and the providers that could not honor
+// it, in the run state.
+func recordSeed(state *runstate.RunState, seed *int64, sc *seedCompleter) {
+	state.Seed = seed
+	state.SeedUnsupported = sc.unsupportedProviders()
+}
+
+// warnSeedUnsupported tells the user when the run's seed did not reach the
+// providers that served it, so its output is not reproducible.
+func warnSeedUnsupported(w io.Writer, state *runstate.RunState) {
+	if state.Seed != nil && len(state.SeedUnsupported) > 0 {
+		fmt.Fprintf(w, "warning: -seed was not applied: %s accept no seed, so sampled output is not reproducible\n",
+			strings.Join(state.SeedUnsupported, ", "))
+	}
+}
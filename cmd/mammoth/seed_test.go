@@ -0,0 +1,135 @@
+// ABOUTME: Tests for the -seed flag: parsing, the OpenAI request body, run state recording, and the warning.
+// ABOUTME: Asserts the seed stays out of the OpenAI Responses request body and unsupported providers are recorded.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/2389-research/mammoth/runstate"
+	trackerllm "github.com/2389-research/tracker/llm"
+)
+
+func TestParseSeed(t *testing.T) {
+	if seed, err := parseSeed(""); err != nil || seed != nil {
+		t.Errorf("parseSeed(\"\") = %v, %v; want nil, nil", seed, err)
+	}
+	if seed, err := parseSeed(" 42 "); err != nil || seed == nil || *seed != 42 {
+		t.Errorf("parseSeed(42) = %v, %v", seed, err)
+	}
+	if _, err := parseSeed("abc"); err == nil {
+		t.Error("expected error for non-integer seed")
+	}
+}
+
+func TestSeedCompleterOmitsSeedForOpenAI(t *testing.T) {
+	// OpenAI is served through the Responses API, which has no seed
+	// parameter, so the request body must not carry one.
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		http.Error(w, `{"error":{"message":"stub"}}`, http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	adapter, err := trackerAdapterConstructors(map[string]string{"openai": srv.URL})["openai"]("test-key")
+	if err != nil {
+		t.Fatalf("construct adapter: %v", err)
+	}
+	seed := int64(42)
+	completer, _ := withSeed(adapter, &seed)
+
+	req := &trackerllm.Request{
+		Model:           "gpt-4o",
+		Messages:        []trackerllm.Message{trackerllm.UserMessage("hi")},
+		ProviderOptions: map[string]any{"openai": map[string]any{"store": false}},
+	}
+	_, _ = completer.Complete(context.Background(), req)
+
+	if body == nil {
+		t.Fatal("expected the request to reach the backend")
+	}
+	if _, ok := body["seed"]; ok {
+		t.Errorf("request body seed = %v, want none", body["seed"])
+	}
+	if body["store"] != false {
+		t.Errorf("existing openai provider options should be kept, body = %v", body)
+	}
+}
+
+func TestSeedCompleterRecordsUnsupportedProviders(t *testing.T) {
+	var provider string
+	inner := completerFunc(func(_ context.Context, req *trackerllm.Request) (*trackerllm.Response, error) {
+		return &trackerllm.Response{Provider: provider}, nil
+	})
+	seed := int64(7)
+	completer, seeded := withSeed(inner, &seed)
+
+	for _, provider = range []string{"anthropic", "openai", "gemini", "anthropic"} {
+		if _, err := completer.Complete(context.Background(), &trackerllm.Request{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := seeded.unsupportedProviders(), []string{"anthropic", "gemini", "openai"}; !slices.Equal(got, want) {
+		t.Errorf("unsupported = %v, want %v", got, want)
+	}
+
+	if c, sc := withSeed(inner, nil); sc != nil || c == nil {
+		t.Error("withSeed without a seed should return the completer unwrapped")
+	}
+}
+
+func TestRecordSeedPersistsInRunState(t *testing.T) {
+	store, err := runstate.NewFSRunStateStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed := int64(1234)
+	_, seeded := withSeed(completerFunc(func(context.Context, *trackerllm.Request) (*trackerllm.Response, error) {
+		return &trackerllm.Response{Provider: "anthropic"}, nil
+	}), &seed)
+	_, _ = seeded.Complete(context.Background(), &trackerllm.Request{})
+
+	state := &runstate.RunState{ID: "seeded-run", Status: "completed", StartedAt: time.Now()}
+	recordSeed(state, &seed, seeded)
+	if err := store.Create(state); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	got, err := store.Get("seeded-run")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Seed == nil || *got.Seed != 1234 {
+		t.Errorf("stored seed = %v, want 1234", got.Seed)
+	}
+	if !slices.Equal(got.SeedUnsupported, []string{"anthropic"}) {
+		t.Errorf("stored seed_unsupported = %v, want [anthropic]", got.SeedUnsupported)
+	}
+
+	var warning strings.Builder
+	warnSeedUnsupported(&warning, got)
+	if !strings.Contains(warning.String(), "-seed was not applied: anthropic") {
+		t.Errorf("warning = %q, want it to name anthropic", warning.String())
+	}
+	warning.Reset()
+	warnSeedUnsupported(&warning, &runstate.RunState{})
+	if warning.Len() != 0 {
+		t.Errorf("unseeded run warned: %q", warning.String())
+	}
+}
+
+// completerFunc adapts a function to agent.Completer.
+type completerFunc func(context.Context, *trackerllm.Request) (*trackerllm.Response, error)
+
+func (f completerFunc) Complete(ctx context.Context, req *trackerllm.Request) (*trackerllm.Response, error) {
+	return f(ctx, req)
+}
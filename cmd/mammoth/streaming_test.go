@@ -0,0 +1,77 @@
+// ABOUTME: Tests that streaming nodes keep working through the CLI's seed and response cache wrappers.
+// ABOUTME: A streaming stub backend checks a seeded stream is served and a repeat is replayed from the cache.
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	trackerllm "github.com/2389-research/tracker/llm"
+)
+
+// streamingStub is a backend that streams "Hel", "lo" and records the last
+// request it streamed.
+type streamingStub struct {
+	requestCapturingCompleter
+
+	mu        sync.Mutex
+	streamed  int
+	streamReq *trackerllm.Request
+}
+
+func (s *streamingStub) Stream(_ context.Context, req *trackerllm.Request) <-chan trackerllm.StreamEvent {
+	s.mu.Lock()
+	s.streamed++
+	s.streamReq = req
+	s.mu.Unlock()
+
+	ch := make(chan trackerllm.StreamEvent, 5)
+	defer close(ch)
+	ch <- trackerllm.StreamEvent{Type: trackerllm.EventTextStart, TextID: "t"}
+	ch <- trackerllm.StreamEvent{Type: trackerllm.EventTextDelta, TextID: "t", Delta: "Hel"}
+	ch <- trackerllm.StreamEvent{Type: trackerllm.EventTextDelta, TextID: "t", Delta: "lo"}
+	ch <- trackerllm.StreamEvent{Type: trackerllm.EventTextEnd, TextID: "t"}
+	ch <- trackerllm.StreamEvent{Type: trackerllm.EventFinish, FinishReason: &trackerllm.FinishReason{Reason: "stop"}}
+	return ch
+}
+
+func TestStreamingNodeStreamsThroughSeedAndCache(t *testing.T) {
+	source := `digraph p {
+		start [shape=Mdiamond]
+		writer [shape=box, prompt="write", llm_provider="openai", llm_model="gpt-4o", stream="true"]
+		end [shape=Msquare]
+		start -> writer -> end
+	}`
+	stub := &streamingStub{}
+	cached, err := withResponseCache(stub, t.TempDir())
+	if err != nil {
+		t.Fatalf("withResponseCache: %v", err)
+	}
+	seed := int64(7)
+	seeded, _ := withSeed(cached, &seed)
+
+	run := func() string {
+		t.Helper()
+		engine, _, err := buildPipelineEngine(source, t.TempDir(), seeded, "", "", "", nil, nil)
+		if err != nil {
+			t.Fatalf("build engine: %v", err)
+		}
+		result, err := engine.Run(context.Background())
+		if err != nil {
+			t.Fatalf("run: %v", err)
+		}
+		return result.Context["stream.writer"]
+	}
+
+	if published := run(); stub.streamed != 1 || published != "Hello" {
+		t.Fatalf("first run: streamed=%d published=%q, want one backend stream", stub.streamed, published)
+	}
+	if opts, _ := stub.streamReq.ProviderOptions["openai"].(map[string]any); opts["seed"] != nil {
+		t.Errorf("streamed request options = %v, want no seed for the Responses API", stub.streamReq.ProviderOptions)
+	}
+
+	if published := run(); stub.streamed != 1 || published != "Hello" {
+		t.Errorf("second run: streamed=%d published=%q, want the repeat served from the cache", stub.streamed, published)
+	}
+}
@@ -0,0 +1,121 @@
+// ABOUTME: Tests for the offline stub backend selected with -backend stub.
+// ABOUTME: Verifies backend detection, deterministic per-node output, and an end-to-end run with no API keys.
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/2389-research/tracker/pipeline"
+)
+
+const stubDOT = `digraph demo {
+    start [shape=Mdiamond]
+    plan [shape=box, prompt="Plan the work"]
+    review [shape=box, prompt="Review the plan", stub_response="LGTM"]
+    record [shape=parallelogram, tool_command="echo recorded > review.txt"]
+    done [shape=Msquare]
+    start -> plan -> review -> record -> done
+}`
+
+// clearLLMKeys unsets every provider API key for the duration of the test.
+func clearLLMKeys(t *testing.T) {
+	t.Helper()
+	for _, k := range []string{"ANTHROPIC_API_KEY", "OPENAI_API_KEY", "GEMINI_API_KEY"} {
+		t.Setenv(k, "")
+	}
+}
+
+func TestDetectBackend(t *testing.T) {
+	clearLLMKeys(t)
+	for _, in := range []string{"stub", " STUB "} {
+		if got, err := detectBackend(in); err != nil || got != stubBackend {
+			t.Errorf("detectBackend(%q) = %q, %v; want stub", in, got, err)
+		}
+	}
+	for _, in := range []string{"", "auto", "agent"} {
+		if got, err := detectBackend(in); err != nil || got != "" {
+			t.Errorf("detectBackend(%q) with no keys = %q, %v; want empty", in, got, err)
+		}
+	}
+	t.Setenv("ANTHROPIC_API_KEY", "test-key")
+	if got, _ := detectBackend(""); got != "anthropic" {
+		t.Errorf("detectBackend with an Anthropic key = %q, want anthropic", got)
+	}
+	if _, err := detectBackend("carrier-pigeon"); err == nil {
+		t.Error("expected an error for an unknown backend")
+	}
+}
+
+func TestStubBackendDeterministicOutput(t *testing.T) {
+	clearLLMKeys(t)
+	var contexts []map[string]string
+	for i := 0; i < 2; i++ {
+		engine, _, err := buildPipelineEngine(stubDOT, t.TempDir(), nil, "", "", "", nil, nil, stubBackendHook(stubBackend))
+		if err != nil {
+			t.Fatalf("build engine: %v", err)
+		}
+		result, err := engine.Run(context.Background())
+		if err != nil {
+			t.Fatalf("run: %v", err)
+		}
+		if got := nodeStatus(result, "plan"); got != pipeline.OutcomeSuccess {
+			t.Fatalf("plan status = %q, want success", got)
+		}
+		contexts = append(contexts, result.Context)
+	}
+
+	first := contexts[0]
+	if got := first[pipeline.ContextKeyLastResponse]; got != "LGTM" {
+		t.Errorf("last_response = %q, want the review node's stub_response", got)
+	}
+	for _, key := range []string{"codergen.provider", "codergen.model", "codergen.input_tokens", "codergen.output_tokens", "codergen.total_tokens"} {
+		if first[key] == "" {
+			t.Errorf("context missing %s", key)
+		}
+		if first[key] != contexts[1][key] {
+			t.Errorf("%s differs between runs: %q vs %q", key, first[key], contexts[1][key])
+		}
+	}
+}
+
+func TestStubHandlerDefaultResponse(t *testing.T) {
+	out, err := stubCodergenHandler{}.Execute(context.Background(), &pipeline.Node{ID: "plan", Attrs: map[string]string{"prompt": "Plan the work"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := out.ContextUpdates[pipeline.ContextKeyLastResponse]; got != "[stub output for node plan]" {
+		t.Errorf("response = %q", got)
+	}
+	if got := out.ContextUpdates["codergen.input_tokens"]; got != "4" {
+		t.Errorf("input tokens = %q, want 4 for a 13-character prompt", got)
+	}
+}
+
+func TestRunWithStubBackendNeedsNoCredentials(t *testing.T) {
+	clearLLMKeys(t)
+	artifactDir := t.TempDir()
+	cfg := config{
+		pipelineFile: writeTempDOT(t, stubDOT),
+		retryPolicy:  "none",
+		artifactDir:  artifactDir,
+		dataDir:      t.TempDir(),
+		fresh:        true,
+		backend:      stubBackend,
+	}
+	if code := run(cfg); code != 0 {
+		t.Fatalf("run exit code = %d, want 0", code)
+	}
+	data, err := os.ReadFile(filepath.Join(artifactDir, "review.txt"))
+	if err != nil || strings.TrimSpace(string(data)) != "recorded" {
+		t.Errorf("expected the tool node after the stubbed nodes to run: %q, %v", data, err)
+	}
+
+	cfg.backend = ""
+	if code := run(cfg); code == 0 {
+		t.Error("expected a run without keys or the stub backend to fail")
+	}
+}
@@ -0,0 +1,226 @@
+// ABOUTME: "mammoth top" subcommand: a live terminal dashboard of a mammoth server's runs.
+// ABOUTME: Polls the server's REST API and lists runs with status, current node, tokens, and elapsed time.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/2389-research/mammoth/tui"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// topConfig holds configuration for the "mammoth top" subcommand.
+type topConfig struct {
+	server   string
+	interval time.Duration
+}
+
+// parseTopArgs checks whether args starts with the "top" subcommand and, if
+// so, parses top-specific flags. Returns the config and true if "top" was
+// detected, or a zero value and false otherwise.
+func parseTopArgs(args []string) (topConfig, bool) {
+	if len(args) == 0 || args[0] != "top" {
+		return topConfig{}, false
+	}
+
+	var cfg topConfig
+	fs := flag.NewFlagSet("mammoth top", flag.ContinueOnError)
+	fs.StringVar(&cfg.server, "server", "http://localhost:2389", "Base URL of the mammoth server")
+	fs.DurationVar(&cfg.interval, "interval", 2*time.Second, "Refresh interval")
+
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: mammoth top [flags]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Live dashboard of a mammoth server's runs. Use ↑/↓ to select a run,")
+		fmt.Fprintln(os.Stderr, "enter to show its recent events, q to quit.")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Flags:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		os.Exit(2)
+	}
+	if cfg.interval <= 0 {
+		cfg.interval = 2 * time.Second
+	}
+	return cfg, true
+}
+
+// runTop runs the dashboard until the user quits. Returns an exit code.
+func runTop(cfg topConfig) int {
+	model := newTopModel(newTopClient(cfg.server), cfg.interval)
+	if _, err := tea.NewProgram(model, tea.WithAltScreen()).Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// maxTopEvents limits the events shown for the selected run.
+const maxTopEvents = 15
+
+// topRunsMsg carries the result of one poll.
+type topRunsMsg struct {
+	runs []topRun
+	err  error
+	at   time.Time
+}
+
+// topTickMsg triggers the next poll.
+type topTickMsg struct{}
+
+// topModel is the Bubble Tea model for the dashboard.
+type topModel struct {
+	client   *topClient
+	interval time.Duration
+
+	runs     []topRun
+	selected int
+	expanded bool
+	err      error
+	updated  time.Time
+}
+
+func newTopModel(client *topClient, interval time.Duration) topModel {
+	return topModel{client: client, interval: interval}
+}
+
+// poll fetches the runs from the server.
+func (m topModel) poll() tea.Msg {
+	ctx, cancel := context.WithTimeout(context.Background(), m.interval+5*time.Second)
+	defer cancel()
+	runs, err := m.client.FetchRuns(ctx)
+	return topRunsMsg{runs: runs, err: err, at: time.Now()}
+}
+
+func (m topModel) Init() tea.Cmd {
+	return m.poll
+}
+
+func (m topModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case topRunsMsg:
+		m.err = msg.err
+		if msg.err == nil {
+			m.selectRun(msg.runs)
+			m.updated = msg.at
+		}
+		return m, tea.Tick(m.interval, func(time.Time) tea.Msg { return topTickMsg{} })
+	case topTickMsg:
+		return m, m.poll
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return m, tea.Quit
+		case "up", "k":
+			if m.selected > 0 {
+				m.selected--
+			}
+		case "down", "j":
+			if m.selected < len(m.runs)-1 {
+				m.selected++
+			}
+		case "enter", " ":
+			m.expanded = !m.expanded
+		}
+	}
+	return m, nil
+}
+
+// selectRun replaces the run list, keeping the cursor on the same run if it
+// is still listed.
+func (m *topModel) selectRun(runs []topRun) {
+	var current string
+	if m.selected < len(m.runs) {
+		current = m.runs[m.selected].RunID
+	}
+	m.runs = runs
+	m.selected = 0
+	for i, r := range runs {
+		if r.RunID == current {
+			m.selected = i
+			break
+		}
+	}
+}
+
+func (m topModel) View() string {
+	var b strings.Builder
+	b.WriteString(tui.TitleStyle.Render("mammoth top — "+m.client.baseURL) + "\n")
+	switch {
+	case m.err != nil:
+		b.WriteString(tui.FailedStyle.Render("error: "+m.err.Error()) + "\n")
+	case !m.updated.IsZero():
+		b.WriteString(tui.PendingStyle.Render(fmt.Sprintf("%d runs · updated %s", len(m.runs), m.updated.Format("15:04:05"))) + "\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString(tui.LabelStyle.Render(fmt.Sprintf("  %-24s %-16s %-10s %-20s %8s %9s", "PROJECT", "RUN", "STATUS", "NODE", "TOKENS", "ELAPSED")) + "\n")
+	now := time.Now()
+	for i, r := range m.runs {
+		cursor := "  "
+		if i == m.selected {
+			cursor = "> "
+		}
+		line := fmt.Sprintf("%s%-24s %-16s %-10s %-20s %8d %9s",
+			cursor, truncate(r.Project, 24), truncate(r.RunID, 16), r.Status,
+			truncate(r.CurrentNode, 20), r.Tokens, r.Elapsed(now).Round(time.Second))
+		b.WriteString(topStatusStyle(r.Status).Render(line) + "\n")
+	}
+
+	if m.expanded && m.selected < len(m.runs) {
+		r := m.runs[m.selected]
+		b.WriteString("\n" + tui.TitleStyle.Render("Recent events — "+r.Project) + "\n")
+		events := r.Events
+		if len(events) > maxTopEvents {
+			events = events[len(events)-maxTopEvents:]
+		}
+		for _, evt := range events {
+			b.WriteString(tui.LogEventStyle.Render(evt.Type))
+			if node, ok := evt.Data["node_id"].(string); ok && node != "" {
+				b.WriteString(" " + node)
+			}
+			if msg, ok := evt.Data["message"].(string); ok && msg != "" {
+				b.WriteString(" " + tui.ValueStyle.Render(truncate(msg, 80)))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n" + tui.StatusBarStyle.Render("↑/↓ select · enter events · q quit") + "\n")
+	return b.String()
+}
+
+// topStatusStyle colours a run row by its status.
+func topStatusStyle(status string) lipgloss.Style {
+	switch status {
+	case "running":
+		return tui.RunningStyle
+	case "completed":
+		return tui.CompletedStyle
+	case "failed":
+		return tui.FailedStyle
+	default:
+		return tui.PendingStyle
+	}
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
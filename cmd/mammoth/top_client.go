@@ -0,0 +1,173 @@
+// ABOUTME: HTTP polling client for "mammoth top": reads runs from a mammoth server's REST API.
+// ABOUTME: Lists projects, fetches each build's state and recent events, and summarises status, node, tokens, and elapsed time.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// topRun is one run shown on the dashboard.
+type topRun struct {
+	ProjectID   string
+	Project     string
+	RunID       string
+	Status      string
+	CurrentNode string
+	Tokens      int
+	StartedAt   time.Time
+	CompletedAt *time.Time
+	Events      []topEvent
+}
+
+// topEvent is one recent build event of a run.
+type topEvent struct {
+	Type string
+	Data map[string]any
+}
+
+// Elapsed returns how long the run has been going, or took if finished.
+func (r topRun) Elapsed(now time.Time) time.Duration {
+	if r.StartedAt.IsZero() {
+		return 0
+	}
+	end := now
+	if r.CompletedAt != nil {
+		end = *r.CompletedAt
+	}
+	return end.Sub(r.StartedAt)
+}
+
+// topClient polls a mammoth server for run state.
+type topClient struct {
+	baseURL string
+	http    *http.Client
+}
+
+// newTopClient returns a client for the server at baseURL.
+func newTopClient(baseURL string) *topClient {
+	return &topClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// topProject mirrors the fields of the server's project list entries used here.
+type topProject struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	RunID string `json:"run_id"`
+}
+
+// topBuildState mirrors the server's GET /projects/{id}/build/state response.
+type topBuildState struct {
+	RunID    string `json:"run_id"`
+	Status   string `json:"status"`
+	RunState *struct {
+		ID          string     `json:"id"`
+		Status      string     `json:"status"`
+		StartedAt   time.Time  `json:"started_at"`
+		CompletedAt *time.Time `json:"completed_at"`
+		CurrentNode string     `json:"current_node"`
+	} `json:"run_state"`
+	Recent []struct {
+		Event string
+		Data  string
+	} `json:"recent_events"`
+}
+
+// FetchRuns lists every project with a run and returns their current state,
+// running builds first and then most recently started.
+func (c *topClient) FetchRuns(ctx context.Context) ([]topRun, error) {
+	var projects []topProject
+	if err := c.getJSON(ctx, "/projects", &projects); err != nil {
+		return nil, err
+	}
+
+	var runs []topRun
+	for _, p := range projects {
+		if p.RunID == "" {
+			continue
+		}
+		var state topBuildState
+		if err := c.getJSON(ctx, "/projects/"+url.PathEscape(p.ID)+"/build/state", &state); err != nil {
+			return nil, err
+		}
+		runs = append(runs, state.toRun(p))
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		ri, rj := runs[i].Status == "running", runs[j].Status == "running"
+		if ri != rj {
+			return ri
+		}
+		return runs[i].StartedAt.After(runs[j].StartedAt)
+	})
+	return runs, nil
+}
+
+// toRun summarises a build state response for project p.
+func (s topBuildState) toRun(p topProject) topRun {
+	run := topRun{
+		ProjectID: p.ID,
+		Project:   p.Name,
+		RunID:     p.RunID,
+		Status:    s.Status,
+	}
+	if s.RunState != nil {
+		run.RunID = s.RunState.ID
+		run.CurrentNode = s.RunState.CurrentNode
+		run.StartedAt = s.RunState.StartedAt
+		run.CompletedAt = s.RunState.CompletedAt
+	}
+	for _, raw := range s.Recent {
+		evt := topEvent{Type: raw.Event}
+		_ = json.Unmarshal([]byte(raw.Data), &evt.Data)
+		if evt.Type == "agent.llm_turn" {
+			run.Tokens += intFromData(evt.Data, "total_tokens")
+		}
+		run.Events = append(run.Events, evt)
+	}
+	return run
+}
+
+// intFromData reads an integer event field that may be encoded as a JSON
+// number or a numeric string.
+func intFromData(data map[string]any, key string) int {
+	switch v := data[key].(type) {
+	case float64:
+		return int(v)
+	case string:
+		n, _ := strconv.Atoi(v)
+		return n
+	}
+	return 0
+}
+
+// getJSON GETs path from the server and decodes the JSON response into v.
+func (c *topClient) getJSON(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", path, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("GET %s: decode: %w", path, err)
+	}
+	return nil
+}
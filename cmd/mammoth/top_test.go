@@ -0,0 +1,137 @@
+// ABOUTME: Tests for the "mammoth top" polling client and dashboard model.
+// ABOUTME: Uses an httptest server mimicking the project list and build state endpoints.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newTopTestServer serves a project list with one finished run, one running
+// run, and one project that has never run.
+func newTopTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	started := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	finished := started.Add(90 * time.Second)
+
+	mux := http.NewServeMux()
+	writeJSON := func(w http.ResponseWriter, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+	}
+	mux.HandleFunc("/projects", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "application/json" {
+			t.Errorf("Accept = %q, want application/json", r.Header.Get("Accept"))
+		}
+		writeJSON(w, []map[string]any{
+			{"id": "p-done", "name": "Done project", "run_id": "run-done"},
+			{"id": "p-live", "name": "Live project", "run_id": "run-live"},
+			{"id": "p-idle", "name": "Idle project"},
+		})
+	})
+	mux.HandleFunc("/projects/p-done/build/state", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{
+			"status": "completed",
+			"run_state": map[string]any{
+				"id": "run-done", "status": "completed", "current_node": "done",
+				"started_at": started, "completed_at": finished,
+			},
+		})
+	})
+	mux.HandleFunc("/projects/p-live/build/state", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{
+			"status": "running",
+			"run_state": map[string]any{
+				"id": "run-live", "status": "running", "current_node": "build",
+				"started_at": started.Add(-time.Hour),
+			},
+			"recent_events": []map[string]string{
+				{"Event": "stage.started", "Data": `{"node_id":"build"}`},
+				{"Event": "agent.llm_turn", "Data": `{"node_id":"build","total_tokens":1200}`},
+				{"Event": "agent.llm_turn", "Data": `{"node_id":"build","total_tokens":"300"}`},
+			},
+		})
+	})
+	mux.HandleFunc("/projects/p-idle/build/state", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("idle project without a run should not be polled")
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestTopClientFetchRuns(t *testing.T) {
+	srv := newTopTestServer(t)
+	runs, err := newTopClient(srv.URL + "/").FetchRuns(context.Background())
+	if err != nil {
+		t.Fatalf("FetchRuns: %v", err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("got %d runs, want 2: %+v", len(runs), runs)
+	}
+
+	live, done := runs[0], runs[1]
+	if live.RunID != "run-live" || live.Status != "running" || live.CurrentNode != "build" {
+		t.Errorf("first run = %+v, want the running build listed first", live)
+	}
+	if live.Tokens != 1500 {
+		t.Errorf("live tokens = %d, want 1500 summed from LLM turns", live.Tokens)
+	}
+	if len(live.Events) != 3 || live.Events[0].Type != "stage.started" {
+		t.Errorf("live events = %+v", live.Events)
+	}
+	if done.Project != "Done project" || done.Elapsed(time.Now()) != 90*time.Second {
+		t.Errorf("done run = %+v, elapsed %s; want 90s", done, done.Elapsed(time.Now()))
+	}
+}
+
+func TestTopClientServerError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	if _, err := newTopClient(srv.URL).FetchRuns(context.Background()); err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("err = %v, want status error", err)
+	}
+}
+
+func TestTopModelPollsAndSelects(t *testing.T) {
+	srv := newTopTestServer(t)
+	m := newTopModel(newTopClient(srv.URL), time.Second)
+
+	msg := m.Init()()
+	next, cmd := m.Update(msg)
+	m = next.(topModel)
+	if cmd == nil {
+		t.Fatal("expected a tick to schedule the next poll")
+	}
+	if len(m.runs) != 2 || m.err != nil {
+		t.Fatalf("runs = %+v, err = %v", m.runs, m.err)
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = next.(topModel)
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(topModel)
+	if m.selected != 1 || !m.expanded {
+		t.Fatalf("selected = %d, expanded = %v", m.selected, m.expanded)
+	}
+
+	// A refresh keeps the cursor on the same run.
+	next, _ = m.Update(m.poll())
+	m = next.(topModel)
+	if m.runs[m.selected].RunID != "run-done" {
+		t.Errorf("selection moved to %s after refresh", m.runs[m.selected].RunID)
+	}
+	if view := m.View(); !strings.Contains(view, "Recent events — Done project") || !strings.Contains(view, "Live project") {
+		t.Errorf("unexpected view:\n%s", view)
+	}
+}
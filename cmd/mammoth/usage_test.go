@@ -0,0 +1,171 @@
+// ABOUTME: Tests for run-wide token usage: the codergen usage hook and the response meter behind it.
+// ABOUTME: Checks summed tokens match per-node codergen.total_tokens, and turn and tool call counts match the agent's events.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/2389-research/mammoth/runstate"
+	"github.com/2389-research/tracker/agent"
+	trackerllm "github.com/2389-research/tracker/llm"
+	"github.com/2389-research/tracker/pipeline"
+)
+
+func TestUsageHookSumsStubRun(t *testing.T) {
+	clearLLMKeys(t)
+	log := &runstate.UsageLog{}
+	engine, _, err := buildPipelineEngine(stubDOT, t.TempDir(), nil, "", "", "", nil, nil, stubBackendHook(stubBackend), usageHook(log))
+	if err != nil {
+		t.Fatalf("build engine: %v", err)
+	}
+	if _, err := engine.Run(context.Background()); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	var want int64
+	for _, id := range []string{"plan", "review"} {
+		node := &pipeline.Node{ID: id, Attrs: map[string]string{}}
+		switch id {
+		case "plan":
+			node.Attrs["prompt"] = "Plan the work"
+		case "review":
+			node.Attrs["prompt"], node.Attrs["stub_response"] = "Review the plan", "LGTM"
+		}
+		out, err := stubCodergenHandler{}.Execute(context.Background(), node, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, _ := strconv.ParseInt(out.ContextUpdates["codergen.total_tokens"], 10, 64)
+		if got := log.Nodes()[id].TotalTokens; got != n {
+			t.Errorf("%s usage = %d, want its codergen.total_tokens %d", id, got, n)
+		}
+		want += n
+	}
+	if total := log.Total(); total == nil || total.TotalTokens != want {
+		t.Errorf("run usage = %+v, want TotalTokens %d", total, want)
+	}
+	if _, ok := log.Nodes()["record"]; ok {
+		t.Error("tool nodes should carry no usage")
+	}
+}
+
+// usageFakeHandler stands in for the real codergen handler: it makes two
+// completions through the client and reports no codergen.*_tokens keys.
+type usageFakeHandler struct {
+	client *usageCompleter
+}
+
+func (usageFakeHandler) Name() string { return "codergen" }
+
+func (h usageFakeHandler) Execute(ctx context.Context, _ *pipeline.Node, _ *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	for i := 0; i < 2; i++ {
+		if _, err := h.client.Complete(ctx, &trackerllm.Request{}); err != nil {
+			return pipeline.Outcome{}, err
+		}
+	}
+	return pipeline.Outcome{Status: pipeline.OutcomeSuccess, ContextUpdates: map[string]string{"last_response": "done"}}, nil
+}
+
+func TestUsageHandlerMetersResponses(t *testing.T) {
+	cacheRead := 50
+	client := &usageCompleter{inner: completerFunc(func(context.Context, *trackerllm.Request) (*trackerllm.Response, error) {
+		return &trackerllm.Response{Usage: trackerllm.Usage{InputTokens: 100, OutputTokens: 20, CacheReadTokens: &cacheRead}}, nil
+	})}
+	log := &runstate.UsageLog{}
+	h := &usageHandler{inner: usageFakeHandler{client: client}, log: log}
+
+	out, err := h.Execute(context.Background(), &pipeline.Node{ID: "build"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := out.ContextUpdates["codergen.total_tokens"]; got != "240" {
+		t.Errorf("codergen.total_tokens = %q, want 240 from two metered responses", got)
+	}
+	if got := out.ContextUpdates["last_response"]; got != "done" {
+		t.Errorf("handler context lost: last_response = %q", got)
+	}
+	want := runstate.Usage{InputTokens: 200, OutputTokens: 40, TotalTokens: 240, CacheReadTokens: 100, LLMTurns: 2}
+	if total := log.Total(); total == nil || *total != want {
+		t.Errorf("run usage = %+v, want %+v", total, want)
+	}
+}
+
+// toolThenAnswer is a backend whose sessions each request two bash tool
+// calls and then answer.
+func toolThenAnswer() completerFunc {
+	var mu sync.Mutex
+	calls := 0
+	return func(context.Context, *trackerllm.Request) (*trackerllm.Response, error) {
+		mu.Lock()
+		calls++
+		n := calls
+		mu.Unlock()
+		usage := trackerllm.Usage{InputTokens: 10, OutputTokens: 5}
+		if n%2 == 0 {
+			return &trackerllm.Response{Message: trackerllm.AssistantMessage("done"), FinishReason: trackerllm.FinishReason{Reason: "stop"}, Usage: usage}, nil
+		}
+		var parts []trackerllm.ContentPart
+		for i := 0; i < 2; i++ {
+			parts = append(parts, trackerllm.ContentPart{Kind: trackerllm.KindToolCall, ToolCall: &trackerllm.ToolCallData{
+				ID:        fmt.Sprintf("call_%d_%d", n, i),
+				Name:      "bash",
+				Arguments: json.RawMessage(`{"command":"true"}`),
+			}})
+		}
+		return &trackerllm.Response{
+			Message:      trackerllm.Message{Role: trackerllm.RoleAssistant, Content: parts},
+			FinishReason: trackerllm.FinishReason{Reason: "tool_calls"},
+			Usage:        usage,
+		}, nil
+	}
+}
+
+func TestUsageCountsMatchAgentEvents(t *testing.T) {
+	clearLLMKeys(t)
+	var mu sync.Mutex
+	turns, toolCalls := 0, 0
+	events := agent.EventHandlerFunc(func(evt agent.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch evt.Type {
+		case agent.EventTurnEnd:
+			turns++
+		case agent.EventToolCallStart:
+			toolCalls++
+		}
+	})
+
+	source := `digraph p {
+		start [shape=Mdiamond]
+		plan [shape=box, prompt="Plan"]
+		build [shape=box, prompt="Build"]
+		done [shape=Msquare]
+		start -> plan -> build -> done
+	}`
+	log := &runstate.UsageLog{}
+	engine, _, err := buildPipelineEngine(source, t.TempDir(), toolThenAnswer(), "", "", "", nil, events, usageHook(log))
+	if err != nil {
+		t.Fatalf("build engine: %v", err)
+	}
+	if _, err := engine.Run(context.Background()); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	total := log.Total()
+	if total == nil || total.LLMTurns != int64(turns) || total.ToolCalls != int64(toolCalls) {
+		t.Fatalf("run usage = %+v, want %d turns and %d tool calls from the agent events", total, turns, toolCalls)
+	}
+	if turns != 4 || toolCalls != 4 {
+		t.Errorf("agent emitted %d turns and %d tool calls, want 4 and 4", turns, toolCalls)
+	}
+	for _, id := range []string{"plan", "build"} {
+		if u := log.Nodes()[id]; u.LLMTurns != 2 || u.ToolCalls != 2 {
+			t.Errorf("%s usage = %+v, want 2 turns and 2 tool calls", id, u)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// ABOUTME: "mammoth version" subcommand printing the build's version, and with --json its commit, date, and Go version.
+// ABOUTME: Release builds set version, commit, and date via -ldflags; other builds fall back to runtime/debug build info.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/debug"
+)
+
+// commit and date are set at release time, like version:
+// -X main.commit=<sha> -X main.date=<RFC 3339 time>.
+var (
+	commit = ""
+	date   = ""
+)
+
+// unknownBuildField fills build info fields nothing could supply.
+const unknownBuildField = "unknown"
+
+// versionConfig holds configuration for the "mammoth version" subcommand.
+type versionConfig struct {
+	jsonOut bool
+}
+
+// buildInfo is the JSON printed by "mammoth version --json".
+type buildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	GoVersion string `json:"go_version"`
+	Modified  bool   `json:"modified,omitempty"`
+}
+
+// parseVersionArgs checks whether args starts with the "version" subcommand
+// and, if so, parses its flags. Returns the config and true if "version" was
+// detected, or a zero value and false otherwise.
+func parseVersionArgs(args []string) (versionConfig, bool) {
+	if len(args) == 0 || args[0] != "version" {
+		return versionConfig{}, false
+	}
+
+	var cfg versionConfig
+	fs := flag.NewFlagSet("mammoth version", flag.ContinueOnError)
+	fs.BoolVar(&cfg.jsonOut, "json", false, "Print version, commit, build date, and Go version as JSON")
+
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: mammoth version [--json]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Print the mammoth version.")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Flags:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		os.Exit(2)
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	return cfg, true
+}
+
+// runVersion prints the version: the same line as -version, or the build
+// info as JSON. Returns 0 on success, 1 if the JSON cannot be written.
+func runVersion(w io.Writer, cfg versionConfig) int {
+	if !cfg.jsonOut {
+		fmt.Fprintf(w, "mammoth %s\n", version)
+		return 0
+	}
+	info, _ := debug.ReadBuildInfo()
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(currentBuildInfo(info)); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// currentBuildInfo combines the -ldflags values with the module build info
+// (which may be nil). Values set with -ldflags win; otherwise the commit and
+// date come from the VCS stamp Go records when building from a checkout, and
+// a module version from go install stands in for a dev version.
+func currentBuildInfo(info *debug.BuildInfo) buildInfo {
+	b := buildInfo{Version: version, Commit: commit, Date: date, GoVersion: runtime.Version()}
+	if info != nil {
+		if b.Version == "dev" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+			b.Version = info.Main.Version
+		}
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if b.Commit == "" {
+					b.Commit = s.Value
+				}
+			case "vcs.time":
+				if b.Date == "" {
+					b.Date = s.Value
+				}
+			case "vcs.modified":
+				b.Modified = s.Value == "true"
+			}
+		}
+	}
+	if b.Commit == "" {
+		b.Commit = unknownBuildField
+	}
+	if b.Date == "" {
+		b.Date = unknownBuildField
+	}
+	return b
+}
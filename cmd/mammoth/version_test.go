@@ -0,0 +1,77 @@
+// ABOUTME: Tests for the "mammoth version" subcommand and its --json build info.
+// ABOUTME: Checks the JSON shape in a test build and how -ldflags values and VCS build settings combine.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"runtime/debug"
+	"testing"
+)
+
+func TestParseVersionArgs(t *testing.T) {
+	if _, ok := parseVersionArgs([]string{"pipeline.dot"}); ok {
+		t.Error("non-version args should not be detected")
+	}
+	cfg, ok := parseVersionArgs([]string{"version", "--json"})
+	if !ok || !cfg.jsonOut {
+		t.Errorf("parseVersionArgs(version --json) = %+v, %v", cfg, ok)
+	}
+}
+
+func TestRunVersionPlain(t *testing.T) {
+	var out bytes.Buffer
+	if code := runVersion(&out, versionConfig{}); code != 0 {
+		t.Fatalf("exit code = %d", code)
+	}
+	if got, want := out.String(), "mammoth "+version+"\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestRunVersionJSON(t *testing.T) {
+	var out bytes.Buffer
+	if code := runVersion(&out, versionConfig{jsonOut: true}); code != 0 {
+		t.Fatalf("exit code = %d", code)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(out.Bytes(), &fields); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out.String())
+	}
+	for _, key := range []string{"version", "commit", "date", "go_version"} {
+		if s, ok := fields[key].(string); !ok || s == "" {
+			t.Errorf("%s = %v, want a non-empty string", key, fields[key])
+		}
+	}
+	if fields["go_version"] != runtime.Version() {
+		t.Errorf("go_version = %v, want %s", fields["go_version"], runtime.Version())
+	}
+}
+
+func TestCurrentBuildInfo(t *testing.T) {
+	vcs := &debug.BuildInfo{
+		Main: debug.Module{Version: "(devel)"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "abc123"},
+			{Key: "vcs.time", Value: "2026-10-01T12:00:00Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+	got := currentBuildInfo(vcs)
+	if got.Commit != "abc123" || got.Date != "2026-10-01T12:00:00Z" || !got.Modified || got.Version != version {
+		t.Errorf("from VCS settings = %+v", got)
+	}
+
+	origCommit, origDate := commit, date
+	defer func() { commit, date = origCommit, origDate }()
+	commit, date = "def456", "2026-10-02T00:00:00Z"
+	if got := currentBuildInfo(vcs); got.Commit != "def456" || got.Date != "2026-10-02T00:00:00Z" {
+		t.Errorf("ldflags should win over VCS settings, got %+v", got)
+	}
+
+	commit, date = "", ""
+	if got := currentBuildInfo(nil); got.Commit != unknownBuildField || got.Date != unknownBuildField {
+		t.Errorf("without build info = %+v, want unknown commit and date", got)
+	}
+}
@@ -4,6 +4,8 @@ package dot
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"sort"
 )
 
@@ -94,13 +96,12 @@ func (g *Graph) IncomingEdges(nodeID string) []*Edge {
 }
 
 // FindStartNode returns the start node, or nil if not found.
-// Recognized via shape=Mdiamond, node_type=start, or type=start.
+// Recognized via a start shape (shape=Mdiamond by default; see
+// ShapeMapping), node_type=start, or type=start.
 func (g *Graph) FindStartNode() *Node {
+	shapes := g.ShapeMapping()
 	for _, node := range g.Nodes {
-		if node.Attrs["shape"] == "Mdiamond" {
-			return node
-		}
-		if node.Attrs["node_type"] == "start" || node.Attrs["type"] == "start" {
+		if shapes.Is(node, "start") {
 			return node
 		}
 	}
@@ -108,13 +109,12 @@ func (g *Graph) FindStartNode() *Node {
 }
 
 // FindExitNode returns the exit/terminal node, or nil if not found.
-// Recognized via shape=Msquare, node_type=exit, or type=exit.
+// Recognized via an exit shape (shape=Msquare by default; see
+// ShapeMapping), node_type=exit, or type=exit.
 func (g *Graph) FindExitNode() *Node {
+	shapes := g.ShapeMapping()
 	for _, node := range g.Nodes {
-		if node.Attrs["shape"] == "Msquare" {
-			return node
-		}
-		if node.Attrs["node_type"] == "exit" || node.Attrs["type"] == "exit" {
+		if shapes.Is(node, "exit") {
 			return node
 		}
 	}
@@ -131,6 +131,45 @@ func (g *Graph) NodeIDs() []string {
 	return ids
 }
 
+// Clone returns a deep copy of the graph. Nodes, edges, subgraphs, and every
+// attribute map are copied, so mutating the clone never affects the original.
+func (g *Graph) Clone() *Graph {
+	if g == nil {
+		return nil
+	}
+	c := &Graph{
+		Name:         g.Name,
+		Attrs:        maps.Clone(g.Attrs),
+		NodeDefaults: maps.Clone(g.NodeDefaults),
+		EdgeDefaults: maps.Clone(g.EdgeDefaults),
+	}
+	if g.Nodes != nil {
+		c.Nodes = make(map[string]*Node, len(g.Nodes))
+		for id, n := range g.Nodes {
+			c.Nodes[id] = &Node{ID: n.ID, Attrs: maps.Clone(n.Attrs)}
+		}
+	}
+	if g.Edges != nil {
+		c.Edges = make([]*Edge, len(g.Edges))
+		for i, e := range g.Edges {
+			c.Edges[i] = &Edge{ID: e.ID, From: e.From, To: e.To, Attrs: maps.Clone(e.Attrs)}
+		}
+	}
+	if g.Subgraphs != nil {
+		c.Subgraphs = make([]*Subgraph, len(g.Subgraphs))
+		for i, sg := range g.Subgraphs {
+			c.Subgraphs[i] = &Subgraph{
+				ID:           sg.ID,
+				Name:         sg.Name,
+				Attrs:        maps.Clone(sg.Attrs),
+				NodeIDs:      slices.Clone(sg.NodeIDs),
+				NodeDefaults: maps.Clone(sg.NodeDefaults),
+			}
+		}
+	}
+	return c
+}
+
 // StableID returns a deterministic identifier for an edge based on its endpoints.
 // The format is "from->to", which is stable across parses of the same DOT source.
 func (e *Edge) StableID() string {
@@ -267,6 +267,33 @@ func TestIncomingEdges(t *testing.T) {
 
 // --- FindStartNode tests ---
 
+func TestNodeType(t *testing.T) {
+	tests := []struct {
+		name  string
+		attrs map[string]string
+		want  string
+	}{
+		{"start shape", map[string]string{"shape": "Mdiamond"}, "start"},
+		{"exit shape", map[string]string{"shape": "Msquare"}, "exit"},
+		{"box shape", map[string]string{"shape": "box"}, "codergen"},
+		{"tool shape", map[string]string{"shape": "parallelogram"}, "tool"},
+		{"explicit type overrides shape", map[string]string{"shape": "box", "type": "reviewer"}, "reviewer"},
+		{"node_type does not pick the handler", map[string]string{"shape": "box", "node_type": "tool"}, "codergen"},
+		{"unknown shape", map[string]string{"shape": "ellipse"}, "codergen"},
+		{"no attrs", nil, "codergen"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NodeType(&Node{ID: "n", Attrs: tt.attrs}); got != tt.want {
+				t.Errorf("NodeType = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if got := NodeType(nil); got != "" {
+		t.Errorf("NodeType(nil) = %q, want empty", got)
+	}
+}
+
 func TestFindStartNode(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -578,3 +605,93 @@ func buildTestGraph() *Graph {
 
 	return g
 }
+
+// --- Clone tests ---
+
+func cloneFixture() *Graph {
+	return &Graph{
+		Name:         "pipeline",
+		Attrs:        map[string]string{"goal": "ship"},
+		NodeDefaults: map[string]string{"shape": "box"},
+		EdgeDefaults: map[string]string{"weight": "1"},
+		Nodes: map[string]*Node{
+			"start": {ID: "start", Attrs: map[string]string{"shape": "Mdiamond"}},
+			"work":  {ID: "work", Attrs: map[string]string{"prompt": "do it"}},
+		},
+		Edges: []*Edge{
+			{ID: "start->work", From: "start", To: "work", Attrs: map[string]string{"label": "go"}},
+		},
+		Subgraphs: []*Subgraph{
+			{
+				ID:           "cluster_a",
+				Name:         "cluster_a",
+				Attrs:        map[string]string{"label": "Stage A"},
+				NodeIDs:      []string{"work"},
+				NodeDefaults: map[string]string{"fidelity": "full"},
+			},
+		},
+	}
+}
+
+func TestCloneCopiesEverything(t *testing.T) {
+	src := cloneFixture()
+	c := src.Clone()
+
+	if c == src {
+		t.Fatal("expected Clone to return a new graph")
+	}
+	if c.Name != "pipeline" || c.Attrs["goal"] != "ship" {
+		t.Errorf("graph fields not copied: name=%q attrs=%v", c.Name, c.Attrs)
+	}
+	if len(c.Nodes) != 2 || c.Nodes["work"].Attrs["prompt"] != "do it" {
+		t.Errorf("nodes not copied: %v", c.Nodes)
+	}
+	if len(c.Edges) != 1 || c.Edges[0].ID != "start->work" || c.Edges[0].Attrs["label"] != "go" {
+		t.Errorf("edges not copied: %+v", c.Edges)
+	}
+	if len(c.Subgraphs) != 1 || c.Subgraphs[0].NodeIDs[0] != "work" || c.Subgraphs[0].NodeDefaults["fidelity"] != "full" {
+		t.Errorf("subgraphs not copied: %+v", c.Subgraphs)
+	}
+}
+
+func TestCloneMutationsDoNotAffectSource(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(c *Graph)
+		check  func(src *Graph) bool
+	}{
+		{"graph attrs", func(c *Graph) { c.Attrs["goal"] = "changed" }, func(s *Graph) bool { return s.Attrs["goal"] == "ship" }},
+		{"node defaults", func(c *Graph) { c.NodeDefaults["shape"] = "oval" }, func(s *Graph) bool { return s.NodeDefaults["shape"] == "box" }},
+		{"edge defaults", func(c *Graph) { c.EdgeDefaults["weight"] = "9" }, func(s *Graph) bool { return s.EdgeDefaults["weight"] == "1" }},
+		{"node attrs", func(c *Graph) { c.Nodes["work"].Attrs["prompt"] = "x" }, func(s *Graph) bool { return s.Nodes["work"].Attrs["prompt"] == "do it" }},
+		{"node map", func(c *Graph) { c.AddNode(&Node{ID: "extra"}) }, func(s *Graph) bool { return s.FindNode("extra") == nil }},
+		{"edge attrs", func(c *Graph) { c.Edges[0].Attrs["label"] = "stop" }, func(s *Graph) bool { return s.Edges[0].Attrs["label"] == "go" }},
+		{"edge endpoints", func(c *Graph) { c.Edges[0].To = "elsewhere" }, func(s *Graph) bool { return s.Edges[0].To == "work" }},
+		{"edge slice", func(c *Graph) { c.AddEdge(&Edge{From: "work", To: "start"}) }, func(s *Graph) bool { return len(s.Edges) == 1 }},
+		{"subgraph attrs", func(c *Graph) { c.Subgraphs[0].Attrs["label"] = "B" }, func(s *Graph) bool { return s.Subgraphs[0].Attrs["label"] == "Stage A" }},
+		{"subgraph node ids", func(c *Graph) { c.Subgraphs[0].NodeIDs[0] = "start" }, func(s *Graph) bool { return s.Subgraphs[0].NodeIDs[0] == "work" }},
+		{"subgraph defaults", func(c *Graph) { c.Subgraphs[0].NodeDefaults["fidelity"] = "compact" }, func(s *Graph) bool { return s.Subgraphs[0].NodeDefaults["fidelity"] == "full" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := cloneFixture()
+			tt.mutate(src.Clone())
+			if !tt.check(src) {
+				t.Errorf("mutating clone's %s changed the source graph", tt.name)
+			}
+		})
+	}
+}
+
+func TestClonePreservesNilMaps(t *testing.T) {
+	c := (&Graph{Name: "empty"}).Clone()
+	if c.Nodes != nil || c.Edges != nil || c.Attrs != nil || c.Subgraphs != nil {
+		t.Errorf("expected nil collections to stay nil, got %+v", c)
+	}
+
+	var nilGraph *Graph
+	if nilGraph.Clone() != nil {
+		t.Error("expected Clone of nil graph to return nil")
+	}
+}
@@ -0,0 +1,67 @@
+// ABOUTME: Positioned syntax errors for the DOT lexer and parser.
+// ABOUTME: ParseError exposes the line and column of a failure plus a caret-annotated source snippet.
+package dot
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PositionedError is implemented by errors that know where in the DOT
+// source they occurred. Line and column are 1-based; column counts runes.
+type PositionedError interface {
+	error
+	Line() int
+	Col() int
+}
+
+// ParseError is a lex or parse failure at a known source position. Parse
+// fills in Snippet with the offending source line and a caret under Col.
+type ParseError struct {
+	msg     string
+	line    int
+	col     int
+	snippet string
+}
+
+// errorAt builds a ParseError at the given position.
+func errorAt(line, col int, format string, args ...any) error {
+	return &ParseError{msg: fmt.Sprintf(format, args...), line: line, col: col}
+}
+
+// Error returns the error message, which includes the position.
+func (e *ParseError) Error() string { return e.msg }
+
+// Line returns the 1-based line of the error.
+func (e *ParseError) Line() int { return e.line }
+
+// Col returns the 1-based column of the error.
+func (e *ParseError) Col() int { return e.col }
+
+// Snippet returns the offending source line followed by a line with a caret
+// under the error column, or "" when the position is outside the source.
+func (e *ParseError) Snippet() string { return e.snippet }
+
+// sourceSnippet renders the given line of input with a caret under col.
+// Tabs before the column are preserved so the caret lines up.
+func sourceSnippet(input string, line, col int) string {
+	lines := strings.Split(input, "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	src := strings.TrimRight(lines[line-1], "\r")
+
+	var caret strings.Builder
+	for i, r := range []rune(src) {
+		if i >= col-1 {
+			break
+		}
+		if r == '\t' {
+			caret.WriteByte('\t')
+		} else {
+			caret.WriteByte(' ')
+		}
+	}
+	caret.WriteByte('^')
+	return src + "\n" + caret.String()
+}
@@ -205,7 +205,7 @@ func (l *lexer) scan() error {
 			l.emit(TokenSemicolon, ";")
 			l.advance()
 		default:
-			return fmt.Errorf("unexpected character %q at line %d, col %d", string(ch), l.line, l.col)
+			return errorAt(l.line, l.col, "unexpected character %q at line %d, col %d", string(ch), l.line, l.col)
 		}
 	}
 
@@ -243,7 +243,7 @@ func (l *lexer) skipLineComment() {
 
 // skipBlockComment skips from /* to */ and returns an error for unterminated comments.
 func (l *lexer) skipBlockComment() error {
-	startLine := l.line
+	startLine, startCol := l.line, l.col
 	// Skip the /*
 	l.advance()
 	l.advance()
@@ -255,7 +255,7 @@ func (l *lexer) skipBlockComment() error {
 		}
 		l.advance()
 	}
-	return fmt.Errorf("unterminated block comment starting at line %d", startLine)
+	return errorAt(startLine, startCol, "unterminated block comment starting at line %d, col %d", startLine, startCol)
 }
 
 // lexString reads a double-quoted string with escape sequences.
@@ -271,7 +271,7 @@ func (l *lexer) lexString() error {
 		if ch == '\\' {
 			l.advance()
 			if l.pos >= len(l.input) {
-				return fmt.Errorf("unterminated string starting at line %d, col %d", startLine, startCol)
+				return errorAt(startLine, startCol, "unterminated string starting at line %d, col %d", startLine, startCol)
 			}
 			escaped := l.input[l.pos]
 			switch escaped {
@@ -301,7 +301,7 @@ func (l *lexer) lexString() error {
 		l.advance()
 	}
 
-	return fmt.Errorf("unterminated string starting at line %d, col %d", startLine, startCol)
+	return errorAt(startLine, startCol, "unterminated string starting at line %d, col %d", startLine, startCol)
 }
 
 // lexNumber reads an integer or float literal, with optional leading sign.
@@ -3,6 +3,7 @@
 package dot
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"unicode"
@@ -22,7 +23,7 @@ type parser struct {
 func Parse(input string) (*Graph, error) {
 	tokens, err := Lex(input)
 	if err != nil {
-		return nil, fmt.Errorf("lex error: %w", err)
+		return nil, fmt.Errorf("lex error: %w", withSnippet(err, input))
 	}
 
 	p := &parser{
@@ -41,7 +42,7 @@ func Parse(input string) (*Graph, error) {
 	}
 
 	if err := p.parseGraph(); err != nil {
-		return nil, err
+		return nil, withSnippet(err, input)
 	}
 
 	p.graph.AssignEdgeIDs()
@@ -49,6 +50,15 @@ func Parse(input string) (*Graph, error) {
 	return p.graph, nil
 }
 
+// withSnippet attaches the offending source line to a positioned error.
+func withSnippet(err error, input string) error {
+	var pe *ParseError
+	if errors.As(err, &pe) {
+		pe.snippet = sourceSnippet(input, pe.line, pe.col)
+	}
+	return err
+}
+
 // current returns the current token.
 func (p *parser) current() Token {
 	if p.pos >= len(p.tokens) {
@@ -77,7 +87,7 @@ func (p *parser) advance() Token {
 func (p *parser) expect(typ TokenType) (Token, error) {
 	tok := p.current()
 	if tok.Type != typ {
-		return tok, fmt.Errorf("expected %v but got %v (%q) at line %d, col %d",
+		return tok, errorAt(tok.Line, tok.Col, "expected %v but got %v (%q) at line %d, col %d",
 			typ, tok.Type, tok.Value, tok.Line, tok.Col)
 	}
 	p.advance()
@@ -95,8 +105,9 @@ func (p *parser) skipSemicolon() {
 func (p *parser) parseGraph() error {
 	// Check for and reject 'strict' modifier
 	if p.current().Type == TokenIdentifier && p.current().Value == "strict" {
-		return fmt.Errorf("strict modifier is not supported at line %d, col %d",
-			p.current().Line, p.current().Col)
+		tok := p.current()
+		return errorAt(tok.Line, tok.Col, "strict modifier is not supported at line %d, col %d",
+			tok.Line, tok.Col)
 	}
 
 	if _, err := p.expect(TokenDigraph); err != nil {
@@ -123,7 +134,9 @@ func (p *parser) parseGraph() error {
 
 	// Check for multiple digraphs
 	if p.current().Type == TokenDigraph {
-		return fmt.Errorf("multiple digraphs are not supported; only one digraph per file is allowed")
+		tok := p.current()
+		return errorAt(tok.Line, tok.Col, "multiple digraphs are not supported; only one digraph per file is allowed (second digraph at line %d, col %d)",
+			tok.Line, tok.Col)
 	}
 
 	// Apply graph-level node defaults to the graph struct
@@ -175,7 +188,7 @@ func (p *parser) parseStatement() error {
 		return nil
 
 	default:
-		return fmt.Errorf("unexpected token %v (%q) at line %d, col %d",
+		return errorAt(tok.Line, tok.Col, "unexpected token %v (%q) at line %d, col %d",
 			tok.Type, tok.Value, tok.Line, tok.Col)
 	}
 }
@@ -311,7 +324,7 @@ func (p *parser) parseSubgraph() error {
 		case TokenSemicolon:
 			p.advance()
 		default:
-			return fmt.Errorf("unexpected token %v (%q) in subgraph at line %d, col %d",
+			return errorAt(tok.Line, tok.Col, "unexpected token %v (%q) in subgraph at line %d, col %d",
 				tok.Type, tok.Value, tok.Line, tok.Col)
 		}
 	}
@@ -365,8 +378,9 @@ func deriveClassName(label string) string {
 func (p *parser) parseNodeOrEdgeStmt() error {
 	// Check for undirected edge operator --
 	if p.peek(1).Type == TokenMinus {
-		return fmt.Errorf("undirected edges (--) are not supported at line %d, col %d; use directed edges (->)",
-			p.peek(1).Line, p.peek(1).Col)
+		tok := p.peek(1)
+		return errorAt(tok.Line, tok.Col, "undirected edges (--) are not supported at line %d, col %d; use directed edges (->)",
+			tok.Line, tok.Col)
 	}
 
 	// Check for graph-level attribute declaration: identifier = value
@@ -419,7 +433,7 @@ func (p *parser) parseEdgeStmt(firstID string) error {
 		p.advance() // consume ->
 		tok := p.current()
 		if tok.Type != TokenIdentifier && tok.Type != TokenString {
-			return fmt.Errorf("expected identifier after -> at line %d, col %d", tok.Line, tok.Col)
+			return errorAt(tok.Line, tok.Col, "expected identifier after -> at line %d, col %d", tok.Line, tok.Col)
 		}
 		nodeIDs = append(nodeIDs, tok.Value)
 		p.advance()
@@ -549,7 +563,7 @@ func (p *parser) parseAttr() (string, string, error) {
 func (p *parser) parseKey() (string, error) {
 	tok := p.current()
 	if tok.Type != TokenIdentifier {
-		return "", fmt.Errorf("expected attribute key (identifier) but got %v (%q) at line %d, col %d",
+		return "", errorAt(tok.Line, tok.Col, "expected attribute key (identifier) but got %v (%q) at line %d, col %d",
 			tok.Type, tok.Value, tok.Line, tok.Col)
 	}
 	key := tok.Value
@@ -596,7 +610,7 @@ func (p *parser) parseValue() (string, error) {
 		return "-", nil
 
 	default:
-		return "", fmt.Errorf("expected value but got %v (%q) at line %d, col %d",
+		return "", errorAt(tok.Line, tok.Col, "expected value but got %v (%q) at line %d, col %d",
 			tok.Type, tok.Value, tok.Line, tok.Col)
 	}
 }
@@ -3,6 +3,7 @@
 package dot
 
 import (
+	"errors"
 	"strings"
 	"testing"
 )
@@ -433,6 +434,78 @@ func TestParseTrailingCommaInAttrs(t *testing.T) {
 	}
 }
 
+func TestParseTrailingCommaInEveryAttrList(t *testing.T) {
+	input := `digraph G {
+		graph [goal="ship it",]
+		node [shape=box, timeout=900s,]
+		edge [weight=2,]
+		a [label="A", prompt="Plan",]
+		a -> b [label="next", condition="outcome=success",]
+	}`
+	g, err := Parse(input)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if g.Attrs["goal"] != "ship it" {
+		t.Errorf("graph goal = %q, want %q", g.Attrs["goal"], "ship it")
+	}
+	if g.NodeDefaults["timeout"] != "900s" {
+		t.Errorf("node default timeout = %q, want %q", g.NodeDefaults["timeout"], "900s")
+	}
+	if g.EdgeDefaults["weight"] != "2" {
+		t.Errorf("edge default weight = %q, want %q", g.EdgeDefaults["weight"], "2")
+	}
+	if g.Nodes["a"].Attrs["prompt"] != "Plan" {
+		t.Errorf("node prompt = %q, want %q", g.Nodes["a"].Attrs["prompt"], "Plan")
+	}
+	if len(g.Edges) != 1 || g.Edges[0].Attrs["condition"] != "outcome=success" {
+		t.Fatalf("edges = %+v, want one edge carrying its condition", g.Edges)
+	}
+}
+
+func TestParseRejectsLeadingOrDoubledCommaInAttrs(t *testing.T) {
+	for _, input := range []string{
+		`digraph G { a [, shape=box] }`,
+		`digraph G { a [shape=box,, label="A"] }`,
+	} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", input)
+		}
+	}
+}
+
+func TestParseComments(t *testing.T) {
+	input := `// pipeline header comment
+digraph G {
+	/* block comment
+	   spanning lines */
+	start [shape=Mdiamond] // trailing line comment
+	fetch [
+		shape=box, // comment inside an attribute list
+		/* inline block */ prompt="Fetch http://example.com/a//b",
+	]
+	// start -> skipped
+	start -> fetch /* between edge and end */
+}
+// trailing comment at end of file`
+	g, err := Parse(input)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if len(g.Nodes) != 2 {
+		t.Errorf("expected 2 nodes, got %d", len(g.Nodes))
+	}
+	if got := g.Nodes["fetch"].Attrs["prompt"]; got != "Fetch http://example.com/a//b" {
+		t.Errorf("prompt = %q, want the // inside the string kept", got)
+	}
+	if g.Nodes["fetch"].Attrs["shape"] != "box" {
+		t.Errorf("fetch shape = %q, want box", g.Nodes["fetch"].Attrs["shape"])
+	}
+	if len(g.Edges) != 1 || g.Edges[0].From != "start" || g.Edges[0].To != "fetch" {
+		t.Errorf("edges = %+v, want only start -> fetch", g.Edges)
+	}
+}
+
 func TestParseSubgraphDerivedClass(t *testing.T) {
 	input := `digraph G {
 		subgraph cluster_loop {
@@ -495,3 +568,58 @@ func TestParserAcceptsDurationVariants(t *testing.T) {
 		})
 	}
 }
+
+func TestParseErrorReportsPosition(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantLine int
+		wantCol  int
+	}{
+		{
+			name:     "bad attribute value",
+			input:    "digraph g {\n  a [shape=box]\n  b [label=]\n}",
+			wantLine: 3,
+			wantCol:  12,
+		},
+		{
+			name:     "unexpected character",
+			input:    "digraph g {\n\ta -> b\n\tc @ d\n}",
+			wantLine: 3,
+			wantCol:  4,
+		},
+		{
+			name:     "unterminated string",
+			input:    "digraph g {\n  a [label=\"oops]\n}",
+			wantLine: 2,
+			wantCol:  12,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			if err == nil {
+				t.Fatal("expected parse error")
+			}
+			var pe PositionedError
+			if !errors.As(err, &pe) {
+				t.Fatalf("error %v does not expose a position", err)
+			}
+			if pe.Line() != tt.wantLine || pe.Col() != tt.wantCol {
+				t.Errorf("position = %d:%d, want %d:%d (%v)", pe.Line(), pe.Col(), tt.wantLine, tt.wantCol, err)
+			}
+		})
+	}
+}
+
+func TestParseErrorSnippet(t *testing.T) {
+	_, err := Parse("digraph g {\n\ta [label=]\n}")
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected *ParseError, got %v", err)
+	}
+	want := "\ta [label=]\n\t         ^"
+	if pe.Snippet() != want {
+		t.Errorf("snippet =\n%s\nwant\n%s", pe.Snippet(), want)
+	}
+}
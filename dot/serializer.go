@@ -1,5 +1,5 @@
 // ABOUTME: Serializer that converts a Graph AST back to a DOT-formatted source string.
-// ABOUTME: Also provides color-coding for pipeline visualization based on node shape conventions.
+// ABOUTME: Also provides non-destructive color-coding for pipeline visualization based on node shape conventions.
 package dot
 
 import (
@@ -120,9 +120,15 @@ func Serialize(g *Graph) string {
 	return b.String()
 }
 
-// ApplyColorCoding adds fillcolor and style attributes to nodes based on their shape,
-// and colors edges based on their label (success/fail conditions).
-func ApplyColorCoding(g *Graph) {
+// ApplyColorCoding returns a copy of g with fillcolor and style attributes added
+// to nodes based on their shape, and edges colored based on their label
+// (success/fail conditions). g itself is left unchanged.
+func ApplyColorCoding(g *Graph) *Graph {
+	if g == nil {
+		return nil
+	}
+	g = g.Clone()
+
 	// Shape-to-color mapping for pipeline visualization
 	shapeColors := map[string]string{
 		"Mdiamond":      "#90EE90", // start → green
@@ -156,6 +162,7 @@ func ApplyColorCoding(g *Graph) {
 			edge.Attrs["style"] = "dashed"
 		}
 	}
+	return g
 }
 
 // formatAttrs renders a map of key=value pairs as a comma-separated string with sorted keys.
@@ -374,7 +374,7 @@ func TestApplyColorCodingStartNode(t *testing.T) {
 			"start": {ID: "start", Attrs: map[string]string{"shape": "Mdiamond"}},
 		},
 	}
-	ApplyColorCoding(g)
+	g = ApplyColorCoding(g)
 
 	if g.Nodes["start"].Attrs["fillcolor"] != "#90EE90" {
 		t.Errorf("start node fillcolor = %q, want #90EE90", g.Nodes["start"].Attrs["fillcolor"])
@@ -390,7 +390,7 @@ func TestApplyColorCodingExitNode(t *testing.T) {
 			"end": {ID: "end", Attrs: map[string]string{"shape": "Msquare"}},
 		},
 	}
-	ApplyColorCoding(g)
+	g = ApplyColorCoding(g)
 
 	if g.Nodes["end"].Attrs["fillcolor"] != "#FFB6C1" {
 		t.Errorf("exit node fillcolor = %q, want #FFB6C1", g.Nodes["end"].Attrs["fillcolor"])
@@ -406,7 +406,7 @@ func TestApplyColorCodingBoxNode(t *testing.T) {
 			"code": {ID: "code", Attrs: map[string]string{"shape": "box"}},
 		},
 	}
-	ApplyColorCoding(g)
+	g = ApplyColorCoding(g)
 
 	if g.Nodes["code"].Attrs["fillcolor"] != "#ADD8E6" {
 		t.Errorf("box node fillcolor = %q, want #ADD8E6", g.Nodes["code"].Attrs["fillcolor"])
@@ -419,7 +419,7 @@ func TestApplyColorCodingDiamondNode(t *testing.T) {
 			"cond": {ID: "cond", Attrs: map[string]string{"shape": "diamond"}},
 		},
 	}
-	ApplyColorCoding(g)
+	g = ApplyColorCoding(g)
 
 	if g.Nodes["cond"].Attrs["fillcolor"] != "#FFFFE0" {
 		t.Errorf("diamond node fillcolor = %q, want #FFFFE0", g.Nodes["cond"].Attrs["fillcolor"])
@@ -432,7 +432,7 @@ func TestApplyColorCodingHexagonNode(t *testing.T) {
 			"human": {ID: "human", Attrs: map[string]string{"shape": "hexagon"}},
 		},
 	}
-	ApplyColorCoding(g)
+	g = ApplyColorCoding(g)
 
 	if g.Nodes["human"].Attrs["fillcolor"] != "#DDA0DD" {
 		t.Errorf("hexagon node fillcolor = %q, want #DDA0DD", g.Nodes["human"].Attrs["fillcolor"])
@@ -445,7 +445,7 @@ func TestApplyColorCodingParallelogramNode(t *testing.T) {
 			"tool": {ID: "tool", Attrs: map[string]string{"shape": "parallelogram"}},
 		},
 	}
-	ApplyColorCoding(g)
+	g = ApplyColorCoding(g)
 
 	if g.Nodes["tool"].Attrs["fillcolor"] != "#FFA500" {
 		t.Errorf("parallelogram node fillcolor = %q, want #FFA500", g.Nodes["tool"].Attrs["fillcolor"])
@@ -472,7 +472,7 @@ func TestApplyColorCodingAllShapes(t *testing.T) {
 					"n": {ID: "n", Attrs: map[string]string{"shape": tt.shape}},
 				},
 			}
-			ApplyColorCoding(g)
+			g = ApplyColorCoding(g)
 
 			if g.Nodes["n"].Attrs["fillcolor"] != tt.wantColor {
 				t.Errorf("shape %s: fillcolor = %q, want %q", tt.shape, g.Nodes["n"].Attrs["fillcolor"], tt.wantColor)
@@ -490,7 +490,7 @@ func TestApplyColorCodingUnknownShape(t *testing.T) {
 			"unknown": {ID: "unknown", Attrs: map[string]string{"shape": "ellipse"}},
 		},
 	}
-	ApplyColorCoding(g)
+	g = ApplyColorCoding(g)
 
 	// Unknown shapes should not get a fillcolor
 	if _, ok := g.Nodes["unknown"].Attrs["fillcolor"]; ok {
@@ -508,7 +508,7 @@ func TestApplyColorCodingSuccessEdge(t *testing.T) {
 			{From: "a", To: "b", Attrs: map[string]string{"label": "success"}},
 		},
 	}
-	ApplyColorCoding(g)
+	g = ApplyColorCoding(g)
 
 	if g.Edges[0].Attrs["color"] != "green" {
 		t.Errorf("success edge color = %q, want green", g.Edges[0].Attrs["color"])
@@ -525,7 +525,7 @@ func TestApplyColorCodingFailEdge(t *testing.T) {
 			{From: "a", To: "b", Attrs: map[string]string{"label": "fail"}},
 		},
 	}
-	ApplyColorCoding(g)
+	g = ApplyColorCoding(g)
 
 	if g.Edges[0].Attrs["color"] != "red" {
 		t.Errorf("fail edge color = %q, want red", g.Edges[0].Attrs["color"])
@@ -561,7 +561,7 @@ func TestApplyColorCodingEdgeConditionVariants(t *testing.T) {
 					{From: "a", To: "b", Attrs: map[string]string{"label": tt.label}},
 				},
 			}
-			ApplyColorCoding(g)
+			g = ApplyColorCoding(g)
 
 			gotColor := g.Edges[0].Attrs["color"]
 			if gotColor != tt.wantColor {
@@ -575,6 +575,28 @@ func TestApplyColorCodingEdgeConditionVariants(t *testing.T) {
 	}
 }
 
+func TestApplyColorCodingLeavesSourceUnchanged(t *testing.T) {
+	src := &Graph{
+		Nodes: map[string]*Node{
+			"start": {ID: "start", Attrs: map[string]string{"shape": "Mdiamond"}},
+		},
+		Edges: []*Edge{
+			{From: "start", To: "end", Attrs: map[string]string{"label": "fail"}},
+		},
+	}
+	colored := ApplyColorCoding(src)
+
+	if colored.Nodes["start"].Attrs["fillcolor"] != "#90EE90" {
+		t.Errorf("colored fillcolor = %q, want #90EE90", colored.Nodes["start"].Attrs["fillcolor"])
+	}
+	if _, ok := src.Nodes["start"].Attrs["fillcolor"]; ok {
+		t.Error("source node was colored, want it left unchanged")
+	}
+	if _, ok := src.Edges[0].Attrs["color"]; ok {
+		t.Error("source edge was colored, want it left unchanged")
+	}
+}
+
 func TestApplyColorCodingNilAttrs(t *testing.T) {
 	// Nodes with nil Attrs should not panic
 	g := &Graph{
@@ -585,7 +607,7 @@ func TestApplyColorCodingNilAttrs(t *testing.T) {
 			{From: "bare", To: "bare"},
 		},
 	}
-	ApplyColorCoding(g) // should not panic
+	g = ApplyColorCoding(g) // should not panic
 }
 
 func TestSerializeCompleteGraph(t *testing.T) {
@@ -0,0 +1,122 @@
+// ABOUTME: Shape-to-handler mapping for pipeline nodes, with the engine's defaults and per-graph overrides.
+// ABOUTME: A graph's shape_map attribute remaps shapes (e.g. doublecircle=start) for validation and execution alike.
+package dot
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ShapeMappingAttr is the graph attribute that overrides the default shape
+// mapping, as comma-separated shape=type entries: shape_map="doublecircle=start".
+const ShapeMappingAttr = "shape_map"
+
+// ShapeMapping maps node shapes to the handler type a node of that shape
+// resolves to when it has no explicit type attribute.
+type ShapeMapping map[string]string
+
+// DefaultNodeType is the handler type of a node with no type attribute whose
+// shape the mapping does not know.
+const DefaultNodeType = "codergen"
+
+// defaultShapeMapping mirrors the pipeline engine's shape table.
+var defaultShapeMapping = ShapeMapping{
+	"Mdiamond":      "start",
+	"Msquare":       "exit",
+	"box":           "codergen",
+	"hexagon":       "wait.human",
+	"diamond":       "conditional",
+	"component":     "parallel",
+	"tripleoctagon": "parallel.fan_in",
+	"parallelogram": "tool",
+	"house":         "stack.manager_loop",
+	"tab":           "subgraph",
+}
+
+// DefaultShapeMapping returns a copy of the engine's built-in shape mapping.
+func DefaultShapeMapping() ShapeMapping {
+	m := make(ShapeMapping, len(defaultShapeMapping))
+	for shape, typ := range defaultShapeMapping {
+		m[shape] = typ
+	}
+	return m
+}
+
+// ParseShapeMapping overlays comma-separated shape=type entries on the
+// default mapping. An entry with an empty type unmaps the shape, so
+// "doublecircle=start,Mdiamond=" makes doublecircle the only start shape.
+func ParseShapeMapping(spec string) (ShapeMapping, error) {
+	m := DefaultShapeMapping()
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		shape, typ, ok := strings.Cut(entry, "=")
+		shape, typ = strings.TrimSpace(shape), strings.TrimSpace(typ)
+		if !ok || shape == "" {
+			return nil, fmt.Errorf("invalid shape mapping entry %q (want shape=type)", entry)
+		}
+		if typ == "" {
+			delete(m, shape)
+			continue
+		}
+		m[shape] = typ
+	}
+	return m, nil
+}
+
+// GraphShapeMapping returns the mapping declared by a graph's attributes:
+// the defaults, overridden by its shape_map attribute if present.
+func GraphShapeMapping(attrs map[string]string) (ShapeMapping, error) {
+	m, err := ParseShapeMapping(attrs[ShapeMappingAttr])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ShapeMappingAttr, err)
+	}
+	return m, nil
+}
+
+// ShapeMapping returns the graph's shape mapping. An invalid shape_map
+// attribute falls back to the defaults; the validator reports it.
+func (g *Graph) ShapeMapping() ShapeMapping {
+	m, err := GraphShapeMapping(g.Attrs)
+	if err != nil {
+		return DefaultShapeMapping()
+	}
+	return m
+}
+
+// NodeType returns the handler type a node resolves to under m: its
+// explicit type attribute, else the type mapped from its shape, else
+// codergen, the engine's default for unmapped shapes. Returns "" for a nil
+// node.
+func (m ShapeMapping) NodeType(n *Node) string {
+	if n == nil {
+		return ""
+	}
+	if t := n.Attrs["type"]; t != "" {
+		return t
+	}
+	if t, ok := m[n.Attrs["shape"]]; ok {
+		return t
+	}
+	return DefaultNodeType
+}
+
+// Is reports whether n is a node of type typ, either through its shape or
+// through an explicit type (or node_type) attribute. Unlike NodeType, the
+// shape counts even when a type attribute names something else, matching
+// how the engine picks its start and exit nodes.
+func (m ShapeMapping) Is(n *Node, typ string) bool {
+	if n == nil || n.Attrs == nil {
+		return false
+	}
+	return m[n.Attrs["shape"]] == typ || n.Attrs["node_type"] == typ || n.Attrs["type"] == typ
+}
+
+// NodeType returns the handler type a node resolves to under the default
+// shape mapping. Use Graph.ShapeMapping().NodeType for a node whose graph
+// may declare its own mapping.
+func NodeType(n *Node) string {
+	return defaultShapeMapping.NodeType(n)
+}
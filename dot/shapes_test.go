@@ -0,0 +1,64 @@
+// ABOUTME: Tests for shape mappings: defaults, shape_map overrides and unmapping, and node type resolution.
+// ABOUTME: Also checks FindStartNode and FindExitNode follow a graph's declared mapping.
+package dot
+
+import "testing"
+
+func TestParseShapeMapping(t *testing.T) {
+	m, err := ParseShapeMapping(" doublecircle = start, Mdiamond=,box=tool ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m["doublecircle"] != "start" || m["box"] != "tool" || m["Msquare"] != "exit" {
+		t.Errorf("mapping = %v", m)
+	}
+	if _, ok := m["Mdiamond"]; ok {
+		t.Error("Mdiamond= should unmap the shape")
+	}
+	if DefaultShapeMapping()["box"] != "codergen" {
+		t.Error("overriding must not change the defaults")
+	}
+	for _, bad := range []string{"doublecircle", "=start"} {
+		if _, err := ParseShapeMapping(bad); err == nil {
+			t.Errorf("ParseShapeMapping(%q) should fail", bad)
+		}
+	}
+}
+
+func TestShapeMappingNodeType(t *testing.T) {
+	m := ShapeMapping{"doublecircle": "start"}
+	tests := []struct {
+		attrs map[string]string
+		want  string
+	}{
+		{map[string]string{"shape": "doublecircle"}, "start"},
+		{map[string]string{"shape": "doublecircle", "type": "codergen"}, "codergen"},
+		{map[string]string{"shape": "Mdiamond"}, "codergen"},
+		{map[string]string{"shape": "ellipse"}, "codergen"},
+		{nil, "codergen"},
+	}
+	for _, tt := range tests {
+		if got := m.NodeType(&Node{Attrs: tt.attrs}); got != tt.want {
+			t.Errorf("NodeType(%v) = %q, want %q", tt.attrs, got, tt.want)
+		}
+	}
+}
+
+func TestFindStartNodeCustomShape(t *testing.T) {
+	g := &Graph{Attrs: map[string]string{ShapeMappingAttr: "doublecircle=start,Mdiamond=,doubleoctagon=exit"}}
+	g.AddNode(&Node{ID: "old", Attrs: map[string]string{"shape": "Mdiamond"}})
+	g.AddNode(&Node{ID: "begin", Attrs: map[string]string{"shape": "doublecircle"}})
+	g.AddNode(&Node{ID: "finish", Attrs: map[string]string{"shape": "doubleoctagon"}})
+
+	if n := g.FindStartNode(); n == nil || n.ID != "begin" {
+		t.Errorf("FindStartNode = %v, want begin", n)
+	}
+	if n := g.FindExitNode(); n == nil || n.ID != "finish" {
+		t.Errorf("FindExitNode = %v, want finish", n)
+	}
+
+	g.Attrs[ShapeMappingAttr] = "doublecircle"
+	if n := g.FindStartNode(); n == nil || n.ID != "old" {
+		t.Errorf("invalid shape_map: FindStartNode = %v, want the default old", n)
+	}
+}
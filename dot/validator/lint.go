@@ -4,6 +4,8 @@ package validator
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -65,6 +67,7 @@ var knownHandlerTypes = map[string]bool{
 func Lint(g *dot.Graph) []dot.Diagnostic {
 	var diags []dot.Diagnostic
 
+	diags = append(diags, checkShapeMapping(g)...)
 	diags = append(diags, checkStartNodes(g)...)
 	diags = append(diags, checkExitNodes(g)...)
 	diags = append(diags, checkReachability(g)...)
@@ -76,6 +79,8 @@ func Lint(g *dot.Graph) []dot.Diagnostic {
 	diags = append(diags, checkPrompts(g)...)
 	diags = append(diags, checkConditions(g)...)
 	diags = append(diags, checkMaxRetries(g)...)
+	diags = append(diags, checkMaxTokens(g)...)
+	diags = append(diags, checkMaxArtifactBytes(g)...)
 	diags = append(diags, checkGoalGate(g)...)
 	diags = append(diags, checkIncompleteOutcomes(g)...)
 	diags = append(diags, checkWeights(g)...)
@@ -86,62 +91,116 @@ func Lint(g *dot.Graph) []dot.Diagnostic {
 	diags = append(diags, checkEdgeTargets(g)...)
 	diags = append(diags, checkTypeKnown(g)...)
 	diags = append(diags, checkGoalGateHasRetry(g)...)
+	diags = append(diags, checkTemplateRefs(g)...)
 
+	sortDiagnostics(diags)
 	return diags
 }
 
-// isStartNode returns true if the node is a start node.
-func isStartNode(n *dot.Node) bool {
-	if n.Attrs == nil {
-		return false
-	}
-	if n.Attrs["shape"] == "Mdiamond" {
-		return true
-	}
-	if n.Attrs["node_type"] == "start" || n.Attrs["type"] == "start" {
-		return true
+// ParseAndValidate parses DOT source and lints the resulting graph, the
+// sequence every entry point runs before accepting a pipeline. A parse
+// failure returns a nil graph, no diagnostics, and the parse error, which
+// keeps its position for callers that report it.
+func ParseAndValidate(source string) (*dot.Graph, []dot.Diagnostic, error) {
+	g, err := dot.Parse(source)
+	if err != nil {
+		return nil, nil, err
 	}
-	return false
+	return g, Lint(g), nil
 }
 
-// isExitNode returns true if the node is an exit/terminal node.
-func isExitNode(n *dot.Node) bool {
-	if n.Attrs == nil {
-		return false
-	}
-	if n.Attrs["shape"] == "Msquare" {
-		return true
+// severityRank orders severities from most to least severe.
+var severityRank = map[string]int{
+	"error":   0,
+	"warning": 1,
+	"info":    2,
+}
+
+// IsSeverity reports whether s is a known diagnostic severity.
+func IsSeverity(s string) bool {
+	_, ok := severityRank[s]
+	return ok
+}
+
+// SeverityAtLeast reports whether severity is as severe as threshold or more,
+// e.g. SeverityAtLeast("error", "warning") is true.
+func SeverityAtLeast(severity, threshold string) bool {
+	return rankSeverity(severity) <= rankSeverity(threshold)
+}
+
+// rankSeverity returns the sort rank of a severity; unknown severities sort last.
+func rankSeverity(s string) int {
+	if r, ok := severityRank[s]; ok {
+		return r
 	}
-	if n.Attrs["node_type"] == "exit" || n.Attrs["type"] == "exit" {
-		return true
+	return len(severityRank)
+}
+
+// sortDiagnostics orders diagnostics deterministically: graph-level
+// diagnostics first, then by node ID, severity, and message, so that each
+// node's diagnostics are grouped together.
+func sortDiagnostics(diags []dot.Diagnostic) {
+	sort.SliceStable(diags, func(i, j int) bool {
+		a, b := diags[i], diags[j]
+		if a.NodeID != b.NodeID {
+			return a.NodeID < b.NodeID
+		}
+		if ra, rb := rankSeverity(a.Severity), rankSeverity(b.Severity); ra != rb {
+			return ra < rb
+		}
+		if a.Message != b.Message {
+			return a.Message < b.Message
+		}
+		if a.EdgeID != b.EdgeID {
+			return a.EdgeID < b.EdgeID
+		}
+		return a.Rule < b.Rule
+	})
+}
+
+// checkShapeMapping verifies the graph's shape_map attribute parses. An
+// invalid mapping is ignored in favor of the defaults, so every other check
+// runs as if it were absent.
+func checkShapeMapping(g *dot.Graph) []dot.Diagnostic {
+	if _, err := dot.GraphShapeMapping(g.Attrs); err != nil {
+		return []dot.Diagnostic{{
+			Severity: "error",
+			Message:  err.Error(),
+			Rule:     "shape_map",
+		}}
 	}
-	return false
+	return nil
+}
+
+// isStartNode returns true if the node is a start node under shapes.
+func isStartNode(shapes dot.ShapeMapping, n *dot.Node) bool {
+	return shapes.Is(n, "start")
+}
+
+// isExitNode returns true if the node is an exit/terminal node under shapes.
+func isExitNode(shapes dot.ShapeMapping, n *dot.Node) bool {
+	return shapes.Is(n, "exit")
 }
 
 // isCodergenNode returns true if the node is a codergen/LLM node.
-func isCodergenNode(n *dot.Node) bool {
+func isCodergenNode(shapes dot.ShapeMapping, n *dot.Node) bool {
 	if n.Attrs == nil {
 		return false
 	}
 	if n.Attrs["type"] == "codergen" {
 		return true
 	}
-	// shape=box with no explicit type maps to codergen.
-	if n.Attrs["shape"] == "box" && n.Attrs["type"] == "" {
+	// A codergen shape (box by default) with no explicit type maps to codergen.
+	if shapes[n.Attrs["shape"]] == "codergen" && n.Attrs["type"] == "" {
 		return true
 	}
 	return false
 }
 
-// checkStartNodes verifies exactly one start node (shape=Mdiamond) exists.
+// checkStartNodes verifies a start node (shape=Mdiamond) exists. Several
+// start nodes only draw a warning: the runner picks one with -entry.
 func checkStartNodes(g *dot.Graph) []dot.Diagnostic {
-	var startIDs []string
-	for _, n := range g.Nodes {
-		if isStartNode(n) {
-			startIDs = append(startIDs, n.ID)
-		}
-	}
-
+	startIDs := startNodeIDs(g)
 	switch len(startIDs) {
 	case 0:
 		return []dot.Diagnostic{{
@@ -153,17 +212,30 @@ func checkStartNodes(g *dot.Graph) []dot.Diagnostic {
 		return nil
 	default:
 		return []dot.Diagnostic{{
-			Severity: "error",
-			Message:  fmt.Sprintf("graph has %d start nodes, expected exactly 1: %v", len(startIDs), startIDs),
+			Severity: "warning",
+			Message:  fmt.Sprintf("graph has %d start nodes %v; choose one with -entry when running it", len(startIDs), startIDs),
 			Rule:     "start_node",
 		}}
 	}
 }
 
+// startNodeIDs returns the IDs of every start node in g, sorted.
+func startNodeIDs(g *dot.Graph) []string {
+	shapes := g.ShapeMapping()
+	var ids []string
+	for _, id := range g.NodeIDs() {
+		if isStartNode(shapes, g.FindNode(id)) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
 // checkExitNodes verifies at least one exit node (shape=Msquare) exists.
 func checkExitNodes(g *dot.Graph) []dot.Diagnostic {
+	shapes := g.ShapeMapping()
 	for _, n := range g.Nodes {
-		if isExitNode(n) {
+		if isExitNode(shapes, n) {
 			return nil
 		}
 	}
@@ -174,16 +246,19 @@ func checkExitNodes(g *dot.Graph) []dot.Diagnostic {
 	}}
 }
 
-// checkReachability performs BFS from start and flags unreachable nodes.
+// checkReachability performs BFS from every start node and flags nodes
+// none of them reach.
 func checkReachability(g *dot.Graph) []dot.Diagnostic {
-	start := g.FindStartNode()
-	if start == nil {
+	startIDs := startNodeIDs(g)
+	if len(startIDs) == 0 {
 		return nil
 	}
 
 	visited := make(map[string]bool)
-	queue := []string{start.ID}
-	visited[start.ID] = true
+	queue := append([]string(nil), startIDs...)
+	for _, id := range startIDs {
+		visited[id] = true
+	}
 
 	for len(queue) > 0 {
 		current := queue[0]
@@ -197,12 +272,16 @@ func checkReachability(g *dot.Graph) []dot.Diagnostic {
 		}
 	}
 
+	from := fmt.Sprintf("start node %q", startIDs[0])
+	if len(startIDs) > 1 {
+		from = "any start node"
+	}
 	var diags []dot.Diagnostic
 	for _, id := range g.NodeIDs() {
 		if !visited[id] {
 			diags = append(diags, dot.Diagnostic{
 				Severity: "error",
-				Message:  fmt.Sprintf("node %q is not reachable from start node %q", id, start.ID),
+				Message:  fmt.Sprintf("node %q is not reachable from %s", id, from),
 				NodeID:   id,
 				Rule:     "reachability",
 			})
@@ -211,30 +290,28 @@ func checkReachability(g *dot.Graph) []dot.Diagnostic {
 	return diags
 }
 
-// checkStartIncoming verifies no incoming edges to the start node.
+// checkStartIncoming verifies no start node has incoming edges.
 func checkStartIncoming(g *dot.Graph) []dot.Diagnostic {
-	start := g.FindStartNode()
-	if start == nil {
-		return nil
-	}
-
-	incoming := g.IncomingEdges(start.ID)
-	if len(incoming) > 0 {
-		return []dot.Diagnostic{{
-			Severity: "error",
-			Message:  fmt.Sprintf("start node %q has %d incoming edge(s)", start.ID, len(incoming)),
-			NodeID:   start.ID,
-			Rule:     "start_no_incoming",
-		}}
+	var diags []dot.Diagnostic
+	for _, id := range startNodeIDs(g) {
+		if incoming := g.IncomingEdges(id); len(incoming) > 0 {
+			diags = append(diags, dot.Diagnostic{
+				Severity: "error",
+				Message:  fmt.Sprintf("start node %q has %d incoming edge(s)", id, len(incoming)),
+				NodeID:   id,
+				Rule:     "start_no_incoming",
+			})
+		}
 	}
-	return nil
+	return diags
 }
 
 // checkExitOutgoing verifies no outgoing edges from exit nodes.
 func checkExitOutgoing(g *dot.Graph) []dot.Diagnostic {
+	shapes := g.ShapeMapping()
 	var diags []dot.Diagnostic
 	for _, n := range g.Nodes {
-		if isExitNode(n) {
+		if isExitNode(shapes, n) {
 			outgoing := g.OutgoingEdges(n.ID)
 			if len(outgoing) > 0 {
 				diags = append(diags, dot.Diagnostic{
@@ -267,10 +344,11 @@ func checkSelfLoops(g *dot.Graph) []dot.Diagnostic {
 
 // checkDeadEnds flags non-exit nodes with no outgoing edges.
 func checkDeadEnds(g *dot.Graph) []dot.Diagnostic {
+	shapes := g.ShapeMapping()
 	var diags []dot.Diagnostic
 	for _, id := range g.NodeIDs() {
 		n := g.FindNode(id)
-		if n == nil || isExitNode(n) {
+		if n == nil || isExitNode(shapes, n) {
 			continue
 		}
 		outgoing := g.OutgoingEdges(id)
@@ -287,8 +365,10 @@ func checkDeadEnds(g *dot.Graph) []dot.Diagnostic {
 }
 
 // checkShapes validates that node shape attributes use recognized values.
+// Shapes the graph's shape_map assigns a type are recognized too.
 func checkShapes(g *dot.Graph) []dot.Diagnostic {
 	var diags []dot.Diagnostic
+	shapes := g.ShapeMapping()
 	for _, id := range g.NodeIDs() {
 		n := g.FindNode(id)
 		if n == nil || n.Attrs == nil {
@@ -298,7 +378,7 @@ func checkShapes(g *dot.Graph) []dot.Diagnostic {
 		if !ok || shape == "" {
 			continue
 		}
-		if !validShapes[shape] {
+		if !validShapes[shape] && shapes[shape] == "" {
 			diags = append(diags, dot.Diagnostic{
 				Severity: "warning",
 				Message:  fmt.Sprintf("node %q has unknown shape %q", id, shape),
@@ -312,10 +392,11 @@ func checkShapes(g *dot.Graph) []dot.Diagnostic {
 
 // checkPrompts verifies codergen (box) nodes have a prompt or label attribute.
 func checkPrompts(g *dot.Graph) []dot.Diagnostic {
+	shapes := g.ShapeMapping()
 	var diags []dot.Diagnostic
 	for _, id := range g.NodeIDs() {
 		n := g.FindNode(id)
-		if n == nil || !isCodergenNode(n) {
+		if n == nil || !isCodergenNode(shapes, n) {
 			continue
 		}
 		hasPrompt := n.Attrs["prompt"] != ""
@@ -423,8 +504,57 @@ func checkMaxRetries(g *dot.Graph) []dot.Diagnostic {
 	return diags
 }
 
+// checkMaxTokens validates max_tokens is a positive integer.
+func checkMaxTokens(g *dot.Graph) []dot.Diagnostic {
+	var diags []dot.Diagnostic
+	for _, id := range g.NodeIDs() {
+		n := g.FindNode(id)
+		if n == nil || n.Attrs == nil {
+			continue
+		}
+		mt, ok := n.Attrs["max_tokens"]
+		if !ok || mt == "" {
+			continue
+		}
+		if val, err := strconv.Atoi(strings.TrimSpace(mt)); err != nil || val <= 0 {
+			diags = append(diags, dot.Diagnostic{
+				Severity: "error",
+				Message:  fmt.Sprintf("node %q has invalid max_tokens %q (want a positive integer)", id, mt),
+				NodeID:   id,
+				Rule:     "max_tokens",
+			})
+		}
+	}
+	return diags
+}
+
+// checkMaxArtifactBytes validates max_artifact_bytes is a positive integer.
+func checkMaxArtifactBytes(g *dot.Graph) []dot.Diagnostic {
+	var diags []dot.Diagnostic
+	for _, id := range g.NodeIDs() {
+		n := g.FindNode(id)
+		if n == nil || n.Attrs == nil {
+			continue
+		}
+		v, ok := n.Attrs["max_artifact_bytes"]
+		if !ok || v == "" {
+			continue
+		}
+		if val, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err != nil || val <= 0 {
+			diags = append(diags, dot.Diagnostic{
+				Severity: "error",
+				Message:  fmt.Sprintf("node %q has invalid max_artifact_bytes %q (want a positive integer)", id, v),
+				NodeID:   id,
+				Rule:     "max_artifact_bytes",
+			})
+		}
+	}
+	return diags
+}
+
 // checkGoalGate verifies goal_gate is only set on codergen nodes.
 func checkGoalGate(g *dot.Graph) []dot.Diagnostic {
+	shapes := g.ShapeMapping()
 	var diags []dot.Diagnostic
 	for _, id := range g.NodeIDs() {
 		n := g.FindNode(id)
@@ -434,7 +564,7 @@ func checkGoalGate(g *dot.Graph) []dot.Diagnostic {
 		if n.Attrs["goal_gate"] != "true" {
 			continue
 		}
-		if !isCodergenNode(n) {
+		if !isCodergenNode(shapes, n) {
 			diags = append(diags, dot.Diagnostic{
 				Severity: "warning",
 				Message:  fmt.Sprintf("node %q has goal_gate=true but is not a codergen node", id),
@@ -448,13 +578,14 @@ func checkGoalGate(g *dot.Graph) []dot.Diagnostic {
 
 // checkIncompleteOutcomes verifies diamond (conditional) nodes have both success and fail edges.
 func checkIncompleteOutcomes(g *dot.Graph) []dot.Diagnostic {
+	shapes := g.ShapeMapping()
 	var diags []dot.Diagnostic
 	for _, id := range g.NodeIDs() {
 		n := g.FindNode(id)
 		if n == nil || n.Attrs == nil {
 			continue
 		}
-		if n.Attrs["shape"] != "diamond" {
+		if shapes[n.Attrs["shape"]] != "conditional" {
 			continue
 		}
 		outgoing := g.OutgoingEdges(id)
@@ -638,6 +769,28 @@ func checkTypeKnown(g *dot.Graph) []dot.Diagnostic {
 	return diags
 }
 
+// CheckHandlers verifies every node's resolved type (see dot.ShapeMapping) has a
+// handler, as reported by registered. Lint cannot know which handlers a
+// runtime provides, so callers holding a handler registry run this alongside
+// it.
+func CheckHandlers(g *dot.Graph, registered func(nodeType string) bool) []dot.Diagnostic {
+	var diags []dot.Diagnostic
+	shapes := g.ShapeMapping()
+	for _, id := range g.NodeIDs() {
+		typ := shapes.NodeType(g.FindNode(id))
+		if registered(typ) {
+			continue
+		}
+		diags = append(diags, dot.Diagnostic{
+			Severity: "error",
+			Message:  fmt.Sprintf("node %q resolves to type %q but no handler is registered for it", id, typ),
+			NodeID:   id,
+			Rule:     "handler_registered",
+		})
+	}
+	return diags
+}
+
 // checkGoalGateHasRetry verifies goal_gate=true nodes have a retry_target.
 func checkGoalGateHasRetry(g *dot.Graph) []dot.Diagnostic {
 	var diags []dot.Diagnostic
@@ -660,3 +813,111 @@ func checkGoalGateHasRetry(g *dot.Graph) []dot.Diagnostic {
 	}
 	return diags
 }
+
+// templateRef matches a {{.key}} placeholder in an attribute value. The
+// leading dot, a context. prefix, and {{- -}} trim markers are optional.
+var templateRef = regexp.MustCompile(`\{\{-?\s*\.?([A-Za-z_][A-Za-z0-9_.\-]*)\s*-?\}\}`)
+
+// ambientContextKeys are context keys available to every node regardless of
+// which nodes ran before it.
+var ambientContextKeys = map[string]bool{
+	"goal":            true,
+	"outcome":         true,
+	"preferred_label": true,
+	"failure_reason":  true,
+}
+
+// handlerContextKeys lists the context keys each handler type is known to
+// write. A node also owns any key with its ID as a dot-separated segment
+// (e.g. stream.<id>, served_by.<id>).
+var handlerContextKeys = map[string][]string{
+	"codergen":   {"last_response"},
+	"tool":       {"tool_stdout", "tool_stderr"},
+	"wait.human": {"human_response"},
+}
+
+// checkTemplateRefs warns when a node attribute contains a {{key}}
+// placeholder naming a context key that no upstream node is known to write.
+// The analysis is best-effort: it catches typos such as {{.bug_reprt}}
+// before they silently render empty at runtime.
+func checkTemplateRefs(g *dot.Graph) []dot.Diagnostic {
+	var diags []dot.Diagnostic
+	for _, id := range g.NodeIDs() {
+		n := g.FindNode(id)
+		if n == nil || n.Attrs == nil {
+			continue
+		}
+		var upstream map[string]bool
+		for _, attr := range sortedKeys(n.Attrs) {
+			for _, m := range templateRef.FindAllStringSubmatch(n.Attrs[attr], -1) {
+				key := strings.TrimPrefix(m[1], "context.")
+				if upstream == nil {
+					upstream = ancestors(g, id)
+				}
+				if contextKeyWritten(g, key, upstream) {
+					continue
+				}
+				diags = append(diags, dot.Diagnostic{
+					Severity: "warning",
+					Message:  fmt.Sprintf("node %q attribute %s references context key %q, which no upstream node writes", id, attr, key),
+					NodeID:   id,
+					Rule:     "template_ref",
+				})
+			}
+		}
+	}
+	return diags
+}
+
+// contextKeyWritten reports whether key is ambient, a graph attribute, or
+// written by one of the upstream nodes.
+func contextKeyWritten(g *dot.Graph, key string, upstream map[string]bool) bool {
+	if ambientContextKeys[key] {
+		return true
+	}
+	if _, ok := g.Attrs[key]; ok {
+		return true
+	}
+	segments := strings.Split(key, ".")
+	shapes := g.ShapeMapping()
+	for nodeID := range upstream {
+		for _, seg := range segments {
+			if seg == nodeID {
+				return true
+			}
+		}
+		for _, written := range handlerContextKeys[shapes.NodeType(g.FindNode(nodeID))] {
+			if key == written || strings.HasPrefix(key, written+".") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ancestors returns the IDs of every node with a path to nodeID.
+func ancestors(g *dot.Graph, nodeID string) map[string]bool {
+	seen := map[string]bool{}
+	queue := []string{nodeID}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, e := range g.IncomingEdges(cur) {
+			if !seen[e.From] {
+				seen[e.From] = true
+				queue = append(queue, e.From)
+			}
+		}
+	}
+	return seen
+}
+
+// sortedKeys returns the keys of attrs in sorted order.
+func sortedKeys(attrs map[string]string) []string {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,8 +1,11 @@
 // ABOUTME: Table-driven tests for the unified DOT graph lint rules covering structure, attributes, and semantics.
-// ABOUTME: Exercises all 24 check functions merged from mammoth-dot-editor and attractor/validate.go.
+// ABOUTME: Exercises all 25 check functions merged from mammoth-dot-editor and attractor/validate.go.
 package validator
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/2389-research/mammoth/dot"
@@ -90,8 +93,13 @@ func TestLint_MultipleStartNodes(t *testing.T) {
 	}
 
 	diags := Lint(g)
-	if !hasDiag(diags, "start_node", "error") {
-		t.Errorf("expected start_node error for multiple starts, got: %v", diags)
+	if !hasDiag(diags, "start_node", "warning") {
+		t.Errorf("expected start_node warning for multiple starts, got: %v", diags)
+	}
+	for _, d := range diags {
+		if d.Severity == "error" {
+			t.Errorf("unexpected error for a graph with several start nodes: %v", d)
+		}
 	}
 }
 
@@ -804,3 +812,263 @@ func TestLint_KnownTypes(t *testing.T) {
 		}
 	}
 }
+
+func TestLint_MaxTokens(t *testing.T) {
+	for _, tc := range []struct {
+		value   string
+		wantErr bool
+	}{
+		{"2048", false},
+		{"abc", true},
+		{"0", true},
+		{"-5", true},
+	} {
+		g := &dot.Graph{
+			Nodes: map[string]*dot.Node{
+				"start": {ID: "start", Attrs: map[string]string{"shape": "Mdiamond"}},
+				"work":  {ID: "work", Attrs: map[string]string{"shape": "box", "prompt": "do stuff", "max_tokens": tc.value}},
+				"exit":  {ID: "exit", Attrs: map[string]string{"shape": "Msquare"}},
+			},
+			Edges: []*dot.Edge{
+				{From: "start", To: "work", Attrs: map[string]string{}},
+				{From: "work", To: "exit", Attrs: map[string]string{}},
+			},
+			Attrs: map[string]string{"goal": "test"},
+		}
+		if got := hasDiag(Lint(g), "max_tokens", "error"); got != tc.wantErr {
+			t.Errorf("max_tokens=%q: error diagnostic = %v, want %v", tc.value, got, tc.wantErr)
+		}
+	}
+}
+
+func TestLint_TemplateRefs(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		prompt   string
+		wantWarn bool
+	}{
+		{"upstream human answer", "Fix {{.human_response}}", false},
+		{"upstream tool output", "Explain {{ .context.tool_stdout }}", false},
+		{"key owned by upstream node", "Use {{.stream.reproduce}}", false},
+		{"graph attribute", "Aim for {{.goal}}", false},
+		{"typo", "Fix {{.bug_reprt}}", true},
+		{"downstream writer", "Summarize {{.last_response}}", true},
+		{"no template", "Fix the bug", false},
+	} {
+		g := &dot.Graph{
+			Nodes: map[string]*dot.Node{
+				"start":     {ID: "start", Attrs: map[string]string{"shape": "Mdiamond"}},
+				"reproduce": {ID: "reproduce", Attrs: map[string]string{"shape": "parallelogram", "tool_command": "make repro"}},
+				"triage":    {ID: "triage", Attrs: map[string]string{"shape": "hexagon", "label": "Triage"}},
+				"fix":       {ID: "fix", Attrs: map[string]string{"shape": "box", "prompt": tc.prompt}},
+				"review":    {ID: "review", Attrs: map[string]string{"shape": "box", "prompt": "review"}},
+				"exit":      {ID: "exit", Attrs: map[string]string{"shape": "Msquare"}},
+			},
+			Edges: []*dot.Edge{
+				{From: "start", To: "reproduce", Attrs: map[string]string{}},
+				{From: "reproduce", To: "triage", Attrs: map[string]string{}},
+				{From: "triage", To: "fix", Attrs: map[string]string{}},
+				{From: "fix", To: "review", Attrs: map[string]string{}},
+				{From: "review", To: "exit", Attrs: map[string]string{}},
+			},
+			Attrs: map[string]string{"goal": "test"},
+		}
+		diags := Lint(g)
+		if got := hasDiag(diags, "template_ref", "warning"); got != tc.wantWarn {
+			t.Errorf("%s: template_ref warning = %v, want %v (diags: %+v)", tc.name, got, tc.wantWarn, diags)
+		}
+	}
+}
+
+func TestLint_DeterministicOrdering(t *testing.T) {
+	newGraph := func() *dot.Graph {
+		return &dot.Graph{
+			Nodes: map[string]*dot.Node{
+				"start":  {ID: "start", Attrs: map[string]string{"shape": "Mdiamond"}},
+				"zeta":   {ID: "zeta", Attrs: map[string]string{"shape": "box", "max_retries": "x", "fidelity": "bogus"}},
+				"alpha":  {ID: "alpha", Attrs: map[string]string{"shape": "box", "max_tokens": "0", "max_retries": "-1"}},
+				"orphan": {ID: "orphan", Attrs: map[string]string{"shape": "triangle"}},
+				"exit":   {ID: "exit", Attrs: map[string]string{"shape": "Msquare"}},
+			},
+			Edges: []*dot.Edge{
+				{From: "start", To: "zeta", Attrs: map[string]string{}},
+				{From: "zeta", To: "alpha", Attrs: map[string]string{}},
+				{From: "alpha", To: "exit", Attrs: map[string]string{}},
+			},
+		}
+	}
+
+	first := Lint(newGraph())
+	for i := 0; i < 20; i++ {
+		again := Lint(newGraph())
+		if fmt.Sprint(again) != fmt.Sprint(first) {
+			t.Fatalf("diagnostic order changed between runs:\n%v\n%v", first, again)
+		}
+	}
+
+	// Graph-level diagnostics come first, then each node's diagnostics are
+	// contiguous, in node ID order, errors before warnings.
+	seen := map[string]bool{}
+	prev := first[0]
+	if prev.NodeID != "" {
+		t.Errorf("expected graph-level diagnostic first, got %+v", prev)
+	}
+	for _, d := range first[1:] {
+		if d.NodeID != prev.NodeID {
+			if seen[d.NodeID] {
+				t.Errorf("diagnostics for node %q are not grouped: %v", d.NodeID, first)
+			}
+			if d.NodeID < prev.NodeID {
+				t.Errorf("node %q sorted after %q", d.NodeID, prev.NodeID)
+			}
+			seen[prev.NodeID] = true
+		} else if rankSeverity(d.Severity) < rankSeverity(prev.Severity) {
+			t.Errorf("node %q: %s sorted after %s", d.NodeID, d.Severity, prev.Severity)
+		}
+		prev = d
+	}
+}
+
+func TestSeverityAtLeast(t *testing.T) {
+	for _, tc := range []struct {
+		severity, threshold string
+		want                bool
+	}{
+		{"error", "error", true},
+		{"warning", "error", false},
+		{"error", "warning", true},
+		{"warning", "warning", true},
+		{"info", "warning", false},
+		{"info", "info", true},
+	} {
+		if got := SeverityAtLeast(tc.severity, tc.threshold); got != tc.want {
+			t.Errorf("SeverityAtLeast(%q, %q) = %v, want %v", tc.severity, tc.threshold, got, tc.want)
+		}
+	}
+}
+
+func TestCheckHandlers(t *testing.T) {
+	registered := func(typ string) bool {
+		return typ == "start" || typ == "exit" || typ == "codergen"
+	}
+
+	g := validGraph()
+	if diags := CheckHandlers(g, registered); len(diags) != 0 {
+		t.Fatalf("expected no diagnostics for shape-inferred types, got %v", diags)
+	}
+
+	// An explicit type on a box node overrides the inferred codergen handler.
+	g.Nodes["work"].Attrs["type"] = "reviewer"
+	diags := CheckHandlers(g, registered)
+	if countDiags(diags, "handler_registered") != 1 || !hasDiag(diags, "handler_registered", "error") {
+		t.Fatalf("expected one handler_registered error, got %v", diags)
+	}
+	if diags[0].NodeID != "work" {
+		t.Errorf("diagnostic node = %q, want work", diags[0].NodeID)
+	}
+
+	withReviewer := func(typ string) bool { return registered(typ) || typ == "reviewer" }
+	if diags := CheckHandlers(g, withReviewer); len(diags) != 0 {
+		t.Errorf("expected custom type to pass once registered, got %v", diags)
+	}
+}
+
+func TestParseAndValidateMatchesManualChain(t *testing.T) {
+	sources := []string{
+		`digraph ok {
+			graph [goal="ship it"]
+			start [shape=Mdiamond]
+			work [shape=box, prompt="do stuff"]
+			exit [shape=Msquare]
+			start -> work -> exit
+		}`,
+		`digraph bad {
+			start [shape=Mdiamond]
+			work [shape=box]
+			orphan [shape=box, prompt="never reached"]
+			start -> work
+			work -> start
+		}`,
+	}
+	for _, src := range sources {
+		g, diags, err := ParseAndValidate(src)
+		if err != nil {
+			t.Fatalf("ParseAndValidate: %v", err)
+		}
+		manual, err := dot.Parse(src)
+		if err != nil {
+			t.Fatalf("dot.Parse: %v", err)
+		}
+		if g.Name != manual.Name || len(g.Nodes) != len(manual.Nodes) || len(g.Edges) != len(manual.Edges) {
+			t.Errorf("graph %q differs from dot.Parse result", g.Name)
+		}
+		want := Lint(manual)
+		if !reflect.DeepEqual(diags, want) {
+			t.Errorf("graph %q: diagnostics = %v, want %v", g.Name, diags, want)
+		}
+	}
+}
+
+func TestParseAndValidateParseError(t *testing.T) {
+	src := "digraph broken {\n  a -> \n"
+	g, diags, err := ParseAndValidate(src)
+	if err == nil {
+		t.Fatal("expected a parse error")
+	}
+	if g != nil || diags != nil {
+		t.Errorf("graph = %v, diagnostics = %v, want nil on parse failure", g, diags)
+	}
+	_, wantErr := dot.Parse(src)
+	if wantErr == nil || err.Error() != wantErr.Error() {
+		t.Errorf("error = %v, want %v", err, wantErr)
+	}
+	var pe dot.PositionedError
+	if !errors.As(err, &pe) || pe.Line() == 0 {
+		t.Errorf("error %v lost its position", err)
+	}
+}
+
+func TestLint_CustomShapeMapping(t *testing.T) {
+	g, diags, err := ParseAndValidate(`digraph p {
+	graph [goal="test", shape_map="doublecircle=start, Mdiamond=, banana=codergen"]
+	begin [shape=doublecircle]
+	work [shape=banana]
+	done [shape=Msquare]
+	begin -> work -> done
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range diags {
+		if d.Severity == "error" || d.Rule == "valid_shape" {
+			t.Errorf("unexpected diagnostic: rule=%s message=%s", d.Rule, d.Message)
+		}
+	}
+	if start := g.FindStartNode(); start == nil || start.ID != "begin" {
+		t.Errorf("FindStartNode = %v, want begin", start)
+	}
+	// banana maps to codergen, so the prompt check applies to it.
+	if !hasDiag(diags, "prompt_required", "warning") {
+		t.Errorf("expected the codergen prompt warning for the banana node, got: %v", diags)
+	}
+
+	// Unmapping Mdiamond leaves a default-shaped start node without a start.
+	g.Attrs["shape_map"] = "Mdiamond="
+	g.Nodes["begin"].Attrs["shape"] = "Mdiamond"
+	if !hasDiag(Lint(g), "start_node", "error") {
+		t.Error("expected start_node error once Mdiamond is unmapped")
+	}
+}
+
+func TestLint_InvalidShapeMapping(t *testing.T) {
+	g := validGraph()
+	g.Attrs["shape_map"] = "doublecircle"
+	diags := Lint(g)
+	if !hasDiag(diags, "shape_map", "error") {
+		t.Errorf("expected shape_map error, got: %v", diags)
+	}
+	// The other checks fall back to the default shapes.
+	if hasDiag(diags, "start_node", "error") {
+		t.Errorf("unexpected start_node error with default shapes: %v", diags)
+	}
+}
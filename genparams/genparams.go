@@ -0,0 +1,91 @@
+// ABOUTME: Per-node generation parameters (max_tokens, stop) for codergen nodes.
+// ABOUTME: A codergen wrapper carries node attributes on the context; a Completer wrapper applies them to each Request.
+package genparams
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/2389-research/tracker/agent"
+	trackerllm "github.com/2389-research/tracker/llm"
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// params holds the generation settings a node requests. Zero values leave
+// the backend's defaults in place.
+type params struct {
+	maxTokens *int
+	stop      []string
+}
+
+// nodeParams reads max_tokens and stop from a node's attributes. stop is a
+// comma-separated list of sequences, e.g. stop="```,END".
+func nodeParams(node *pipeline.Node) (params, error) {
+	var p params
+	if raw := strings.TrimSpace(node.Attrs["max_tokens"]); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			return p, fmt.Errorf("node %q has invalid max_tokens %q (want a positive integer)", node.ID, raw)
+		}
+		p.maxTokens = &n
+	}
+	for _, seq := range strings.Split(node.Attrs["stop"], ",") {
+		if seq = strings.TrimSpace(seq); seq != "" {
+			p.stop = append(p.stop, seq)
+		}
+	}
+	return p, nil
+}
+
+type paramsKey struct{}
+
+// Hook wraps the codergen handler so each node's generation parameters
+// travel with the context into the backend. They reach the request only
+// through a client wrapped with Completer.
+func Hook(registry *pipeline.HandlerRegistry) {
+	if inner := registry.Get("codergen"); inner != nil {
+		registry.Register(&paramsHandler{inner: inner})
+	}
+}
+
+// paramsHandler attaches a node's generation parameters to the context
+// before delegating to the wrapped codergen handler.
+type paramsHandler struct {
+	inner pipeline.Handler
+}
+
+func (h *paramsHandler) Name() string { return h.inner.Name() }
+
+func (h *paramsHandler) Execute(ctx context.Context, node *pipeline.Node, pctx *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	p, err := nodeParams(node)
+	if err != nil {
+		return pipeline.Outcome{}, err
+	}
+	return h.inner.Execute(context.WithValue(ctx, paramsKey{}, p), node, pctx)
+}
+
+// Completer wraps inner so the generation parameters Hook put on the context
+// are applied to every request.
+func Completer(inner agent.Completer) agent.Completer {
+	return &paramsCompleter{inner: inner}
+}
+
+// paramsCompleter applies the generation parameters found on the context to
+// every request before passing it to the wrapped client.
+type paramsCompleter struct {
+	inner agent.Completer
+}
+
+func (c *paramsCompleter) Complete(ctx context.Context, req *trackerllm.Request) (*trackerllm.Response, error) {
+	if p, ok := ctx.Value(paramsKey{}).(params); ok {
+		if p.maxTokens != nil {
+			req.MaxTokens = p.maxTokens
+		}
+		if len(p.stop) > 0 {
+			req.StopSequences = p.stop
+		}
+	}
+	return c.inner.Complete(ctx, req)
+}
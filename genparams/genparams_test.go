@@ -0,0 +1,111 @@
+// ABOUTME: Tests for per-node generation parameters on codergen nodes.
+// ABOUTME: Covers attribute parsing and max_tokens/stop reaching the LLM Request.
+package genparams
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	trackerllm "github.com/2389-research/tracker/llm"
+	"github.com/2389-research/tracker/pipeline"
+	"github.com/2389-research/tracker/pipeline/handlers"
+)
+
+// requestCapturingCompleter records every request and answers with plain text.
+type requestCapturingCompleter struct {
+	mu       sync.Mutex
+	requests []trackerllm.Request
+}
+
+func (c *requestCapturingCompleter) Complete(_ context.Context, req *trackerllm.Request) (*trackerllm.Response, error) {
+	c.mu.Lock()
+	c.requests = append(c.requests, *req)
+	c.mu.Unlock()
+	return &trackerllm.Response{
+		Message:      trackerllm.AssistantMessage("done"),
+		FinishReason: trackerllm.FinishReason{Reason: "stop"},
+	}, nil
+}
+
+// runGraph runs source against client with the generation parameter hooks
+// installed and returns the run error.
+func runGraph(t *testing.T, source string, client *requestCapturingCompleter) error {
+	t.Helper()
+	g, err := pipeline.ParseDOT(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	registry := handlers.NewDefaultRegistry(g, handlers.WithLLMClient(Completer(client), dir))
+	Hook(registry)
+	_, err = pipeline.NewEngine(g, registry, pipeline.WithArtifactDir(dir)).Run(context.Background())
+	return err
+}
+
+func TestNodeParams(t *testing.T) {
+	params, err := nodeParams(&pipeline.Node{ID: "n", Attrs: map[string]string{
+		"max_tokens": "2048",
+		"stop":       "```, END,",
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if params.maxTokens == nil || *params.maxTokens != 2048 {
+		t.Errorf("maxTokens = %v, want 2048", params.maxTokens)
+	}
+	if !slices.Equal(params.stop, []string{"```", "END"}) {
+		t.Errorf("stop = %q, want [``` END]", params.stop)
+	}
+
+	for _, bad := range []string{"lots", "0", "-1"} {
+		if _, err := nodeParams(&pipeline.Node{ID: "n", Attrs: map[string]string{"max_tokens": bad}}); err == nil {
+			t.Errorf("max_tokens=%q: expected error", bad)
+		}
+	}
+}
+
+func TestGenerationParamsReachRequest(t *testing.T) {
+	source := `digraph p {
+		start [shape=Mdiamond]
+		limited [shape=box, prompt="write", max_tokens="2048", stop="` + "```" + `,END"]
+		plain [shape=box, prompt="write more"]
+		end [shape=Msquare]
+		start -> limited -> plain -> end
+	}`
+	client := &requestCapturingCompleter{}
+	if err := runGraph(t, source, client); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if len(client.requests) < 2 {
+		t.Fatalf("expected a request per codergen node, got %d", len(client.requests))
+	}
+	first, last := client.requests[0], client.requests[len(client.requests)-1]
+	if first.MaxTokens == nil || *first.MaxTokens != 2048 {
+		t.Errorf("limited node MaxTokens = %v, want 2048", first.MaxTokens)
+	}
+	if !slices.Equal(first.StopSequences, []string{"```", "END"}) {
+		t.Errorf("limited node StopSequences = %q", first.StopSequences)
+	}
+	if last.MaxTokens != nil || last.StopSequences != nil {
+		t.Errorf("plain node should keep backend defaults, got MaxTokens=%v StopSequences=%q", last.MaxTokens, last.StopSequences)
+	}
+}
+
+func TestGenerationParamsInvalidMaxTokensFailsNode(t *testing.T) {
+	source := `digraph p {
+		start [shape=Mdiamond]
+		bad [shape=box, prompt="write", max_tokens="many"]
+		end [shape=Msquare]
+		start -> bad -> end
+	}`
+	client := &requestCapturingCompleter{}
+	if err := runGraph(t, source, client); err == nil {
+		t.Error("expected run to fail on invalid max_tokens")
+	}
+	if len(client.requests) != 0 {
+		t.Errorf("backend should not be called, got %d requests", len(client.requests))
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/2389-research/mammoth/llm/sse"
 )
@@ -42,8 +43,25 @@ func WithAnthropicBaseURL(url string) AnthropicOption {
 // WithAnthropicTimeout sets custom timeout values for the adapter.
 func WithAnthropicTimeout(timeout AdapterTimeout) AnthropicOption {
 	return func(a *AnthropicAdapter) {
-		a.Timeout = timeout
-		a.HTTPClient = &http.Client{Timeout: timeout.Request}
+		a.setTimeout(timeout)
+	}
+}
+
+// WithAnthropicHTTPTimeout bounds each completion request, from connecting
+// through reading the whole response, to d. Streaming requests are bounded
+// only by their context. Default: 120s.
+func WithAnthropicHTTPTimeout(d time.Duration) AnthropicOption {
+	return func(a *AnthropicAdapter) {
+		a.setHTTPTimeout(d)
+	}
+}
+
+// WithAnthropicHTTPClient sends requests through client, e.g. one with a
+// proxy or custom TLS config. Its Timeout bounds completions; streaming
+// requests use a copy without it.
+func WithAnthropicHTTPClient(client *http.Client) AnthropicOption {
+	return func(a *AnthropicAdapter) {
+		a.setHTTPClient(client)
 	}
 }
 
@@ -54,6 +72,22 @@ func WithAnthropicVersion(version string) AnthropicOption {
 	}
 }
 
+// WithAnthropicHeaders adds headers sent on every request, such as a custom
+// User-Agent or gateway routing headers. They cannot replace auth headers.
+func WithAnthropicHeaders(headers map[string]string) AnthropicOption {
+	return func(a *AnthropicAdapter) {
+		a.addClientHeaders(headers)
+	}
+}
+
+// WithAnthropicRequestLogger installs a hook that receives the raw JSON request
+// and response bodies of every Anthropic API call. Off by default.
+func WithAnthropicRequestLogger(fn RequestLogger) AnthropicOption {
+	return func(a *AnthropicAdapter) {
+		a.setRequestLogger("anthropic", fn)
+	}
+}
+
 // NewAnthropicAdapter creates an AnthropicAdapter with the given API key and options.
 // Authentication uses x-api-key header instead of Bearer token, so the API key
 // is stored in DefaultHeaders rather than BaseAdapter.APIKey.
@@ -101,7 +135,7 @@ func (a *AnthropicAdapter) Complete(ctx context.Context, req Request) (*Response
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, a.parseError(resp.StatusCode, respBody)
+		return nil, a.parseError(resp.StatusCode, respBody, resp.Header)
 	}
 
 	return a.parseResponse(respBody, resp.Header)
@@ -111,7 +145,7 @@ func (a *AnthropicAdapter) Complete(ctx context.Context, req Request) (*Response
 func (a *AnthropicAdapter) Stream(ctx context.Context, req Request) (<-chan StreamEvent, error) {
 	body, headers := a.buildRequestBody(req, true)
 
-	resp, err := a.DoRequest(ctx, http.MethodPost, "/v1/messages", body, headers)
+	resp, err := a.DoStreamRequest(ctx, http.MethodPost, "/v1/messages", body, headers)
 	if err != nil {
 		return nil, err
 	}
@@ -122,7 +156,7 @@ func (a *AnthropicAdapter) Stream(ctx context.Context, req Request) (<-chan Stre
 		if readErr != nil {
 			return nil, fmt.Errorf("reading error response body: %w", readErr)
 		}
-		return nil, a.parseError(resp.StatusCode, respBody)
+		return nil, a.parseError(resp.StatusCode, respBody, resp.Header)
 	}
 
 	ch := make(chan StreamEvent, 64)
@@ -132,6 +166,12 @@ func (a *AnthropicAdapter) Stream(ctx context.Context, req Request) (<-chan Stre
 }
 
 // Close releases any resources held by the adapter.
+// Capabilities reports the features the Anthropic Messages API translation
+// supports. Response formats and reasoning effort have no Anthropic mapping.
+func (a *AnthropicAdapter) Capabilities() AdapterCapabilities {
+	return AdapterCapabilities{Tools: true, Thinking: true, Streaming: true, Images: true}
+}
+
 func (a *AnthropicAdapter) Close() error {
 	return nil
 }
@@ -515,6 +555,8 @@ func (a *AnthropicAdapter) mapStopReason(reason string) FinishReason {
 		unified = FinishToolCalls
 	case "stop_sequence":
 		unified = FinishStop
+	case "refusal":
+		unified = FinishContentFilter
 	default:
 		unified = FinishOther
 	}
@@ -535,11 +577,12 @@ type anthropicErrorResponse struct {
 }
 
 // parseError parses an Anthropic error response and returns the appropriate error type.
-func (a *AnthropicAdapter) parseError(statusCode int, body []byte) error {
+func (a *AnthropicAdapter) parseError(statusCode int, body []byte, headers http.Header) error {
+	retryAfter := parseRetryAfter(headers)
 	var errResp anthropicErrorResponse
 	if err := json.Unmarshal(body, &errResp); err != nil {
 		// If we can't parse the error, use a generic message
-		return ErrorFromStatusCode(statusCode, fmt.Sprintf("HTTP %d", statusCode), "anthropic", "", json.RawMessage(body), nil)
+		return ErrorFromStatusCode(statusCode, fmt.Sprintf("HTTP %d", statusCode), "anthropic", "", json.RawMessage(body), retryAfter)
 	}
 
 	return ErrorFromStatusCode(
@@ -548,7 +591,7 @@ func (a *AnthropicAdapter) parseError(statusCode int, body []byte) error {
 		"anthropic",
 		errResp.Error.Type,
 		json.RawMessage(body),
-		nil,
+		retryAfter,
 	)
 }
 
@@ -1508,3 +1508,102 @@ func TestAnthropicStreamingError(t *testing.T) {
 		t.Errorf("expected RateLimitError, got %T: %v", err, err)
 	}
 }
+
+// TestAnthropicRequestLogger verifies the logging hook receives the exact
+// translated request JSON and the raw response body, and never the API key.
+func TestAnthropicRequestLogger(t *testing.T) {
+	const respJSON = `{"id":"msg_log","type":"message","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"logged"}],"stop_reason":"end_turn","usage":{"input_tokens":3,"output_tokens":1}}`
+	var sent []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(respJSON))
+	}))
+	defer server.Close()
+
+	var calls int
+	var gotProvider string
+	var gotReq, gotResp []byte
+	logger := func(provider string, reqBody, respBody []byte) {
+		calls++
+		gotProvider, gotReq, gotResp = provider, reqBody, respBody
+	}
+	adapter := NewAnthropicAdapter("secret-key",
+		WithAnthropicBaseURL(server.URL),
+		WithAnthropicRequestLogger(logger),
+	)
+
+	_, err := adapter.Complete(context.Background(), Request{
+		Model:     "claude-sonnet-4-20250514",
+		Messages:  []Message{SystemMessage("be brief"), UserMessage("hi")},
+		MaxTokens: IntPtr(50),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("logger called %d times, want 1", calls)
+	}
+	if gotProvider != "anthropic" {
+		t.Errorf("provider = %q, want anthropic", gotProvider)
+	}
+	if string(gotReq) != string(sent) {
+		t.Errorf("logged request differs from sent request:\nlogged: %s\nsent:   %s", gotReq, sent)
+	}
+	var req map[string]any
+	if err := json.Unmarshal(gotReq, &req); err != nil {
+		t.Fatalf("logged request is not JSON: %v", err)
+	}
+	if req["system"] == nil || req["max_tokens"] != float64(50) {
+		t.Errorf("logged request is not the translated Anthropic body: %s", gotReq)
+	}
+	if string(gotResp) != respJSON {
+		t.Errorf("logged response = %s, want %s", gotResp, respJSON)
+	}
+	if strings.Contains(string(gotReq)+string(gotResp), "secret-key") {
+		t.Error("API key leaked to request logger")
+	}
+}
+
+// TestAnthropicRequestLoggerOffByDefault verifies no logging wrapper is
+// installed unless the option is given.
+func TestAnthropicRequestLoggerOffByDefault(t *testing.T) {
+	adapter := NewAnthropicAdapter("key")
+	if adapter.logExchange != nil {
+		t.Error("expected request logging to be disabled by default")
+	}
+}
+
+// TestAnthropicRequestLoggerStream verifies streamed responses are logged as
+// the raw SSE payload once the stream finishes.
+func TestAnthropicRequestLoggerStream(t *testing.T) {
+	const sse = "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_s\",\"model\":\"m\",\"usage\":{\"input_tokens\":1,\"output_tokens\":0}}}\n\n" +
+		"event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(sse))
+	}))
+	defer server.Close()
+
+	logged := make(chan []byte, 1)
+	adapter := NewAnthropicAdapter("key",
+		WithAnthropicBaseURL(server.URL),
+		WithAnthropicRequestLogger(func(_ string, reqBody, respBody []byte) {
+			if !strings.Contains(string(reqBody), `"stream":true`) {
+				t.Errorf("logged request missing stream flag: %s", reqBody)
+			}
+			logged <- respBody
+		}),
+	)
+
+	ch, err := adapter.Stream(context.Background(), Request{Model: "m", Messages: []Message{UserMessage("hi")}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for range ch {
+	}
+	if got := string(<-logged); got != sse {
+		t.Errorf("logged stream = %q, want raw SSE payload", got)
+	}
+}
@@ -0,0 +1,78 @@
+// ABOUTME: AdapterCapabilities describes which request features a provider adapter supports.
+// ABOUTME: The Client uses it to downgrade requests instead of sending features a provider would reject or drop.
+
+package llm
+
+// AdapterCapabilities reports the request features a ProviderAdapter
+// translates for its provider. A false field means the adapter cannot send
+// that feature; the Client strips it from requests rather than failing.
+type AdapterCapabilities struct {
+	// Tools: tool definitions and tool choice.
+	Tools bool
+	// Thinking: thinking and redacted-thinking blocks replayed in the
+	// conversation history.
+	Thinking bool
+	// ReasoningEffort: Request.ReasoningEffort.
+	ReasoningEffort bool
+	// Streaming: Stream returns incremental events.
+	Streaming bool
+	// Images: image content parts in messages.
+	Images bool
+	// JSONMode: Request.ResponseFormat (JSON object or JSON schema output).
+	JSONMode bool
+}
+
+// applyCapabilities returns req with every feature caps does not support
+// removed. Messages are copied before parts are dropped, so the caller's
+// request is never modified.
+func applyCapabilities(caps AdapterCapabilities, req Request) Request {
+	if !caps.Tools {
+		req.Tools = nil
+		req.ToolChoice = nil
+	}
+	if !caps.ReasoningEffort {
+		req.ReasoningEffort = ""
+	}
+	if !caps.JSONMode {
+		req.ResponseFormat = nil
+	}
+
+	drop := map[ContentKind]bool{}
+	if !caps.Thinking {
+		drop[ContentThinking] = true
+		drop[ContentRedactedThinking] = true
+	}
+	if !caps.Images {
+		drop[ContentImage] = true
+	}
+	if len(drop) == 0 {
+		return req
+	}
+
+	messages := make([]Message, len(req.Messages))
+	for i, msg := range req.Messages {
+		messages[i] = msg
+		if !hasContentKind(msg.Content, drop) {
+			continue
+		}
+		kept := make([]ContentPart, 0, len(msg.Content))
+		for _, part := range msg.Content {
+			if !drop[part.Kind] {
+				kept = append(kept, part)
+			}
+		}
+		messages[i].Content = kept
+	}
+	req.Messages = messages
+	return req
+}
+
+// hasContentKind reports whether any part's kind is in kinds.
+func hasContentKind(parts []ContentPart, kinds map[ContentKind]bool) bool {
+	for _, part := range parts {
+		if kinds[part.Kind] {
+			return true
+		}
+	}
+	return false
+}
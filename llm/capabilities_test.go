@@ -0,0 +1,85 @@
+// ABOUTME: Tests for adapter capability discovery and request downgrading in the Client.
+// ABOUTME: Verifies each adapter's reported capabilities and that unsupported features are stripped.
+
+package llm
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAdapterCapabilities(t *testing.T) {
+	anthropic := NewAnthropicAdapter("key")
+	tests := []struct {
+		name    string
+		adapter ProviderAdapter
+		want    AdapterCapabilities
+	}{
+		{"anthropic", anthropic, AdapterCapabilities{Tools: true, Thinking: true, Streaming: true, Images: true}},
+		{"openai", NewOpenAIAdapter("key"), AdapterCapabilities{Tools: true, ReasoningEffort: true, Streaming: true, Images: true, JSONMode: true}},
+		{"gemini", NewGeminiAdapter("key"), AdapterCapabilities{Tools: true, Streaming: true, Images: true}},
+		{"mux", NewMuxAdapter("mux", &stubMuxClient{}), AdapterCapabilities{Tools: true, Streaming: true}},
+		{"retrying", NewRetryingAdapter(anthropic, DefaultRetryPolicy()), anthropic.Capabilities()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.adapter.Capabilities(); got != tt.want {
+				t.Errorf("Capabilities() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientStripsUnsupportedFeatures(t *testing.T) {
+	adapter := newTestAdapter("plain")
+	adapter.caps = &AdapterCapabilities{Streaming: true}
+	client := NewClient(WithProvider("plain", adapter))
+
+	req := Request{
+		Model: "m",
+		Messages: []Message{
+			UserMessage("hi"),
+			{Role: RoleAssistant, Content: []ContentPart{ThinkingPart("hmm", "sig"), TextPart("hello")}},
+		},
+		Tools:           []ToolDefinition{{Name: "read_file"}},
+		ToolChoice:      &ToolChoice{Mode: ToolChoiceAuto},
+		ReasoningEffort: "high",
+		ResponseFormat:  &ResponseFormat{Type: "json"},
+	}
+	if _, err := client.Complete(context.Background(), req); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+
+	calls := adapter.getCompleteCalls()
+	if len(calls) != 1 {
+		t.Fatalf("adapter saw %d calls, want 1", len(calls))
+	}
+	got := calls[0]
+	if got.Tools != nil || got.ToolChoice != nil || got.ReasoningEffort != "" || got.ResponseFormat != nil {
+		t.Errorf("unsupported fields reached the adapter: %+v", got)
+	}
+	if parts := got.Messages[1].Content; len(parts) != 1 || parts[0].Kind != ContentText {
+		t.Errorf("assistant content = %+v, want only the text part", parts)
+	}
+	if len(req.Messages[1].Content) != 2 || req.Tools == nil {
+		t.Error("the caller's request was modified")
+	}
+}
+
+func TestClientPassesSupportedFeatures(t *testing.T) {
+	adapter := newTestAdapter("full")
+	client := NewClient(WithProvider("full", adapter))
+
+	req := Request{
+		Model:    "m",
+		Messages: []Message{{Role: RoleAssistant, Content: []ContentPart{ThinkingPart("hmm", "sig")}}},
+		Tools:    []ToolDefinition{{Name: "read_file"}},
+	}
+	if _, err := client.Complete(context.Background(), req); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	got := adapter.getCompleteCalls()[0]
+	if len(got.Tools) != 1 || len(got.Messages[0].Content) != 1 {
+		t.Errorf("supported features were stripped: %+v", got)
+	}
+}
@@ -208,7 +208,7 @@ func (c *Client) Complete(ctx context.Context, req Request) (*Response, error) {
 		if err != nil {
 			return nil, err
 		}
-		return adapter.Complete(ctx, req)
+		return adapter.Complete(ctx, applyCapabilities(adapter.Capabilities(), req))
 	}
 
 	// Wrap with middleware in reverse order so the first middleware registered
@@ -232,7 +232,7 @@ func (c *Client) Stream(ctx context.Context, req Request) (<-chan StreamEvent, e
 	if err != nil {
 		return nil, err
 	}
-	return adapter.Stream(ctx, req)
+	return adapter.Stream(ctx, applyCapabilities(adapter.Capabilities(), req))
 }
 
 // Close shuts down all registered provider adapters. Errors from individual
@@ -23,9 +23,13 @@ type testAdapter struct {
 	completeCalls []Request
 	streamCalls   []Request
 	closed        bool
+	caps          *AdapterCapabilities
 	mu            sync.Mutex
 }
 
+// fullCapabilities reports support for every request feature.
+var fullCapabilities = AdapterCapabilities{Tools: true, Thinking: true, ReasoningEffort: true, Streaming: true, Images: true, JSONMode: true}
+
 func newTestAdapter(name string) *testAdapter {
 	return &testAdapter{
 		name: name,
@@ -66,6 +70,15 @@ func (a *testAdapter) Stream(ctx context.Context, req Request) (<-chan StreamEve
 	return ch, nil
 }
 
+// Capabilities reports a.caps, or every feature when it is unset so requests
+// reach the adapter unchanged.
+func (a *testAdapter) Capabilities() AdapterCapabilities {
+	if a.caps != nil {
+		return *a.caps
+	}
+	return fullCapabilities
+}
+
 func (a *testAdapter) Close() error {
 	a.mu.Lock()
 	defer a.mu.Unlock()
@@ -0,0 +1,98 @@
+// ABOUTME: Shared test matrix for provider finish reason mapping into the unified FinishReason.
+// ABOUTME: Asserts every provider's raw stop reason maps consistently and is preserved in Raw.
+
+package llm
+
+import (
+	"testing"
+
+	muxllm "github.com/2389-research/mux/llm"
+	"github.com/openai/openai-go"
+)
+
+func TestFinishReasonMatrix(t *testing.T) {
+	anthropic := &AnthropicAdapter{}
+	gemini := &GeminiAdapter{}
+	oai := &OpenAIAdapter{}
+
+	// compat maps an OpenAI Chat Completions finish_reason through the
+	// compat client and mux adapter, as a real response would travel.
+	compat := func(raw string) FinishReason {
+		resp := convertCompatResponse(&openai.ChatCompletion{
+			Choices: []openai.ChatCompletionChoice{{FinishReason: raw}},
+		})
+		return mapStopReason(resp.StopReason)
+	}
+	responses := func(status, incomplete string) func(string) FinishReason {
+		return func(string) FinishReason {
+			var details *openaiIncomplete
+			if incomplete != "" {
+				details = &openaiIncomplete{Reason: incomplete}
+			}
+			return oai.mapFinishReason(status, details, false)
+		}
+	}
+	anthropicMap := func(raw string) FinishReason { return anthropic.mapStopReason(raw) }
+	geminiMap := func(raw string) FinishReason { return gemini.mapFinishReason(raw, false) }
+	muxMap := func(raw string) FinishReason { return mapStopReason(muxllm.StopReason(raw)) }
+
+	tests := []struct {
+		provider string
+		raw      string
+		mapFn    func(string) FinishReason
+		want     string
+		wantRaw  string
+	}{
+		{"anthropic", "end_turn", anthropicMap, FinishStop, "end_turn"},
+		{"anthropic", "stop_sequence", anthropicMap, FinishStop, "stop_sequence"},
+		{"anthropic", "max_tokens", anthropicMap, FinishLength, "max_tokens"},
+		{"anthropic", "tool_use", anthropicMap, FinishToolCalls, "tool_use"},
+		{"anthropic", "refusal", anthropicMap, FinishContentFilter, "refusal"},
+		{"anthropic", "pause_turn", anthropicMap, FinishOther, "pause_turn"},
+
+		{"gemini", "STOP", geminiMap, FinishStop, "STOP"},
+		{"gemini", "MAX_TOKENS", geminiMap, FinishLength, "MAX_TOKENS"},
+		{"gemini", "SAFETY", geminiMap, FinishContentFilter, "SAFETY"},
+		{"gemini", "RECITATION", geminiMap, FinishContentFilter, "RECITATION"},
+		{"gemini", "PROHIBITED_CONTENT", geminiMap, FinishContentFilter, "PROHIBITED_CONTENT"},
+		{"gemini", "MALFORMED_FUNCTION_CALL", geminiMap, FinishError, "MALFORMED_FUNCTION_CALL"},
+		{"gemini", "FINISH_REASON_UNSPECIFIED", geminiMap, FinishOther, "FINISH_REASON_UNSPECIFIED"},
+
+		{"openai", "completed", responses("completed", ""), FinishStop, "completed"},
+		{"openai", "max_output_tokens", responses("incomplete", "max_output_tokens"), FinishLength, "max_output_tokens"},
+		{"openai", "content_filter", responses("incomplete", "content_filter"), FinishContentFilter, "content_filter"},
+		{"openai", "failed", responses("failed", ""), FinishError, "failed"},
+
+		{"openai-compat", "stop", compat, FinishStop, "stop"},
+		{"openai-compat", "length", compat, FinishLength, "length"},
+		{"openai-compat", "tool_calls", compat, FinishToolCalls, "tool_calls"},
+		{"openai-compat", "function_call", compat, FinishToolCalls, "function_call"},
+		{"openai-compat", "content_filter", compat, FinishContentFilter, "content_filter"},
+		{"openai-compat", "unknown", compat, FinishOther, "unknown"},
+
+		{"mux", "end_turn", muxMap, FinishStop, "end_turn"},
+		{"mux", "tool_use", muxMap, FinishToolCalls, "tool_use"},
+		{"mux", "max_tokens", muxMap, FinishLength, "max_tokens"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider+"/"+tt.raw, func(t *testing.T) {
+			got := tt.mapFn(tt.raw)
+			if got.Reason != tt.want {
+				t.Errorf("Reason = %q, want %q", got.Reason, tt.want)
+			}
+			if got.Raw != tt.wantRaw {
+				t.Errorf("Raw = %q, want %q", got.Raw, tt.wantRaw)
+			}
+		})
+	}
+}
+
+func TestFinishReasonToolCallsOverride(t *testing.T) {
+	if got := (&GeminiAdapter{}).mapFinishReason("STOP", true); got.Reason != FinishToolCalls || got.Raw != "STOP" {
+		t.Errorf("gemini = %+v, want tool_calls preserving raw STOP", got)
+	}
+	if got := (&OpenAIAdapter{}).mapFinishReason("completed", nil, true); got.Reason != FinishToolCalls || got.Raw != "completed" {
+		t.Errorf("openai = %+v, want tool_calls preserving raw completed", got)
+	}
+}
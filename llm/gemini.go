@@ -12,6 +12,7 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/2389-research/mammoth/llm/sse"
 )
@@ -47,10 +48,41 @@ func WithGeminiBaseURL(url string) GeminiOption {
 // WithGeminiTimeout sets the timeout configuration for the adapter.
 func WithGeminiTimeout(timeout AdapterTimeout) GeminiOption {
 	return func(a *GeminiAdapter) {
-		a.base.Timeout = timeout
-		a.base.HTTPClient = &http.Client{
-			Timeout: timeout.Request,
-		}
+		a.base.setTimeout(timeout)
+	}
+}
+
+// WithGeminiHTTPTimeout bounds each completion request, from connecting
+// through reading the whole response, to d. Streaming requests are bounded
+// only by their context. Default: 120s.
+func WithGeminiHTTPTimeout(d time.Duration) GeminiOption {
+	return func(a *GeminiAdapter) {
+		a.base.setHTTPTimeout(d)
+	}
+}
+
+// WithGeminiHTTPClient sends requests through client, e.g. one with a proxy
+// or custom TLS config. Its Timeout bounds completions; streaming requests
+// use a copy without it.
+func WithGeminiHTTPClient(client *http.Client) GeminiOption {
+	return func(a *GeminiAdapter) {
+		a.base.setHTTPClient(client)
+	}
+}
+
+// WithGeminiHeaders adds headers sent on every request, such as a custom
+// User-Agent or gateway routing headers. They cannot replace auth headers.
+func WithGeminiHeaders(headers map[string]string) GeminiOption {
+	return func(a *GeminiAdapter) {
+		a.base.addClientHeaders(headers)
+	}
+}
+
+// WithGeminiRequestLogger installs a hook that receives the raw JSON request
+// and response bodies of every Gemini API call. Off by default.
+func WithGeminiRequestLogger(fn RequestLogger) GeminiOption {
+	return func(a *GeminiAdapter) {
+		a.base.setRequestLogger("gemini", fn)
 	}
 }
 
@@ -78,6 +110,11 @@ func (a *GeminiAdapter) Name() string {
 }
 
 // Close releases any resources held by the adapter.
+// Capabilities reports the features the Gemini translation supports.
+func (a *GeminiAdapter) Capabilities() AdapterCapabilities {
+	return AdapterCapabilities{Tools: true, Streaming: true, Images: true}
+}
+
 func (a *GeminiAdapter) Close() error {
 	return nil
 }
@@ -129,7 +166,7 @@ func (a *GeminiAdapter) Complete(ctx context.Context, req Request) (*Response, e
 	}
 
 	if httpResp.StatusCode != http.StatusOK {
-		return nil, a.parseErrorResponse(httpResp.StatusCode, respBody)
+		return nil, a.parseErrorResponse(httpResp.StatusCode, respBody, httpResp.Header)
 	}
 
 	return a.parseResponse(req.Model, respBody)
@@ -141,7 +178,7 @@ func (a *GeminiAdapter) Stream(ctx context.Context, req Request) (<-chan StreamE
 	basePath := fmt.Sprintf("/v1beta/models/%s:streamGenerateContent?alt=sse", req.Model)
 	path := a.authPath(basePath)
 
-	httpResp, err := a.base.DoRequest(ctx, http.MethodPost, path, body, a.authHeaders())
+	httpResp, err := a.base.DoStreamRequest(ctx, http.MethodPost, path, body, a.authHeaders())
 	if err != nil {
 		return nil, err
 	}
@@ -152,7 +189,7 @@ func (a *GeminiAdapter) Stream(ctx context.Context, req Request) (<-chan StreamE
 		if readErr != nil {
 			return nil, fmt.Errorf("reading error response: %w", readErr)
 		}
-		return nil, a.parseErrorResponse(httpResp.StatusCode, respBody)
+		return nil, a.parseErrorResponse(httpResp.StatusCode, respBody, httpResp.Header)
 	}
 
 	ch := make(chan StreamEvent, 64)
@@ -464,8 +501,10 @@ func (a *GeminiAdapter) mapFinishReason(geminiReason string, hasToolCalls bool)
 		reason = FinishStop
 	case "MAX_TOKENS":
 		reason = FinishLength
-	case "SAFETY":
+	case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII", "IMAGE_SAFETY":
 		reason = FinishContentFilter
+	case "MALFORMED_FUNCTION_CALL":
+		reason = FinishError
 	default:
 		reason = FinishOther
 	}
@@ -474,10 +513,11 @@ func (a *GeminiAdapter) mapFinishReason(geminiReason string, hasToolCalls bool)
 }
 
 // parseErrorResponse parses a Gemini error response and returns the appropriate error type.
-func (a *GeminiAdapter) parseErrorResponse(statusCode int, respBody []byte) error {
+func (a *GeminiAdapter) parseErrorResponse(statusCode int, respBody []byte, headers http.Header) error {
+	retryAfter := parseRetryAfter(headers)
 	var errResp geminiErrorResponse
 	if err := json.Unmarshal(respBody, &errResp); err != nil {
-		return ErrorFromStatusCode(statusCode, fmt.Sprintf("HTTP %d (unparseable body)", statusCode), "gemini", "", json.RawMessage(respBody), nil)
+		return ErrorFromStatusCode(statusCode, fmt.Sprintf("HTTP %d (unparseable body)", statusCode), "gemini", "", json.RawMessage(respBody), retryAfter)
 	}
 
 	return ErrorFromStatusCode(
@@ -486,7 +526,7 @@ func (a *GeminiAdapter) parseErrorResponse(statusCode int, respBody []byte) erro
 		"gemini",
 		errResp.Error.Status,
 		json.RawMessage(respBody),
-		nil,
+		retryAfter,
 	)
 }
 
@@ -56,6 +56,7 @@ type GenerateOptions struct {
 	MaxTokens       *int
 	StopSequences   []string
 	ReasoningEffort string
+	Seed            *int64
 	Provider        string
 	ProviderOptions map[string]any
 	MaxRetries      int // default 2
@@ -238,6 +239,7 @@ func buildRequest(opts GenerateOptions, messages []Message) Request {
 		MaxTokens:       opts.MaxTokens,
 		StopSequences:   opts.StopSequences,
 		ReasoningEffort: opts.ReasoningEffort,
+		Seed:            opts.Seed,
 		ProviderOptions: opts.ProviderOptions,
 	}
 
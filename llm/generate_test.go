@@ -75,6 +75,8 @@ func (a *generateTestAdapter) Stream(ctx context.Context, req Request) (<-chan S
 	return ch, nil
 }
 
+func (a *generateTestAdapter) Capabilities() AdapterCapabilities { return fullCapabilities }
+
 func (a *generateTestAdapter) Close() error {
 	a.mu.Lock()
 	defer a.mu.Unlock()
@@ -125,6 +125,12 @@ func (a *MuxAdapter) Stream(ctx context.Context, req Request) (<-chan StreamEven
 	return ch, nil
 }
 
+// Capabilities reports the features the mux translation supports. Thinking,
+// image, audio, and document parts have no mux equivalent.
+func (a *MuxAdapter) Capabilities() AdapterCapabilities {
+	return AdapterCapabilities{Tools: true, Streaming: true}
+}
+
 // Close releases any resources held by the adapter. The underlying mux client
 // does not expose a Close method, so this is a no-op.
 func (a *MuxAdapter) Close() error {
@@ -345,16 +351,31 @@ func convertResponse(resp *muxllm.Response, providerName string) *Response {
 	}
 }
 
-// mapStopReason translates a mux StopReason into a mammoth FinishReason.
+// Chat Completions finish_reason values. The compat client passes the
+// provider's value through as the mux StopReason, so it survives as the raw
+// finish reason.
+const (
+	stopReasonStop          muxllm.StopReason = "stop"
+	stopReasonLength        muxllm.StopReason = "length"
+	stopReasonToolCalls     muxllm.StopReason = "tool_calls"
+	stopReasonFunctionCall  muxllm.StopReason = "function_call"
+	stopReasonContentFilter muxllm.StopReason = "content_filter"
+)
+
+// mapStopReason translates a mux StopReason, or a Chat Completions
+// finish_reason passed through by the compat client, into a mammoth
+// FinishReason. Raw keeps the value as received.
 func mapStopReason(reason muxllm.StopReason) FinishReason {
 	raw := string(reason)
 	switch reason {
-	case muxllm.StopReasonEndTurn:
+	case muxllm.StopReasonEndTurn, stopReasonStop:
 		return FinishReason{Reason: FinishStop, Raw: raw}
-	case muxllm.StopReasonToolUse:
+	case muxllm.StopReasonToolUse, stopReasonToolCalls, stopReasonFunctionCall:
 		return FinishReason{Reason: FinishToolCalls, Raw: raw}
-	case muxllm.StopReasonMaxTokens:
+	case muxllm.StopReasonMaxTokens, stopReasonLength:
 		return FinishReason{Reason: FinishLength, Raw: raw}
+	case stopReasonContentFilter:
+		return FinishReason{Reason: FinishContentFilter, Raw: raw}
 	default:
 		return FinishReason{Reason: FinishOther, Raw: raw}
 	}
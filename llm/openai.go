@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/2389-research/mammoth/llm/sse"
 )
@@ -37,8 +38,25 @@ func WithOpenAIBaseURL(url string) OpenAIOption {
 // WithOpenAITimeout sets the timeout configuration for OpenAI API requests.
 func WithOpenAITimeout(timeout AdapterTimeout) OpenAIOption {
 	return func(a *OpenAIAdapter) {
-		a.Timeout = timeout
-		a.HTTPClient = &http.Client{Timeout: timeout.Request}
+		a.setTimeout(timeout)
+	}
+}
+
+// WithOpenAIHTTPTimeout bounds each completion request, from connecting
+// through reading the whole response, to d. Streaming requests are bounded
+// only by their context. Default: 120s.
+func WithOpenAIHTTPTimeout(d time.Duration) OpenAIOption {
+	return func(a *OpenAIAdapter) {
+		a.setHTTPTimeout(d)
+	}
+}
+
+// WithOpenAIHTTPClient sends requests through client, e.g. one with a proxy
+// or custom TLS config. Its Timeout bounds completions; streaming requests
+// use a copy without it.
+func WithOpenAIHTTPClient(client *http.Client) OpenAIOption {
+	return func(a *OpenAIAdapter) {
+		a.setHTTPClient(client)
 	}
 }
 
@@ -56,6 +74,22 @@ func WithOpenAIProject(project string) OpenAIOption {
 	}
 }
 
+// WithOpenAIHeaders adds headers sent on every request, such as a custom
+// User-Agent or gateway routing headers. They cannot replace auth headers.
+func WithOpenAIHeaders(headers map[string]string) OpenAIOption {
+	return func(a *OpenAIAdapter) {
+		a.addClientHeaders(headers)
+	}
+}
+
+// WithOpenAIRequestLogger installs a hook that receives the raw JSON request
+// and response bodies of every OpenAI API call. Off by default.
+func WithOpenAIRequestLogger(fn RequestLogger) OpenAIOption {
+	return func(a *OpenAIAdapter) {
+		a.setRequestLogger("openai", fn)
+	}
+}
+
 // NewOpenAIAdapter creates a new OpenAIAdapter with the given API key and options.
 //
 // Deprecated: Use NewMuxAdapter with the appropriate mux/llm client instead.
@@ -85,6 +119,12 @@ func (a *OpenAIAdapter) Name() string {
 }
 
 // Close releases resources held by the adapter.
+// Capabilities reports the features the OpenAI Responses API translation
+// supports. Thinking blocks from other providers cannot be replayed to it.
+func (a *OpenAIAdapter) Capabilities() AdapterCapabilities {
+	return AdapterCapabilities{Tools: true, ReasoningEffort: true, Streaming: true, Images: true, JSONMode: true}
+}
+
 func (a *OpenAIAdapter) Close() error {
 	return nil
 }
@@ -111,7 +151,7 @@ func (a *OpenAIAdapter) Stream(ctx context.Context, req Request) (<-chan StreamE
 	body := a.buildRequestBody(req)
 	body["stream"] = true
 
-	resp, err := a.DoRequest(ctx, http.MethodPost, "/v1/responses", body, nil)
+	resp, err := a.DoStreamRequest(ctx, http.MethodPost, "/v1/responses", body, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -155,6 +195,8 @@ func (a *OpenAIAdapter) buildRequestBody(req Request) map[string]any {
 	if len(req.StopSequences) > 0 {
 		body["stop"] = req.StopSequences
 	}
+	// req.Seed is not sent: the Responses API has no seed parameter and
+	// rejects requests carrying one.
 
 	// Reasoning effort
 	if req.ReasoningEffort != "" {
@@ -525,7 +567,7 @@ func (a *OpenAIAdapter) handleErrorResponse(resp *http.Response) error {
 		}
 	}
 
-	return ErrorFromStatusCode(resp.StatusCode, message, "openai", errorCode, json.RawMessage(body), nil)
+	return ErrorFromStatusCode(resp.StatusCode, message, "openai", errorCode, json.RawMessage(body), parseRetryAfter(resp.Header))
 }
 
 // processSSEStream reads SSE events from the response body and emits unified StreamEvents.
@@ -274,14 +274,11 @@ func convertCompatResponse(resp *openai.ChatCompletion) *muxllm.Response {
 
 	choice := resp.Choices[0]
 
-	switch choice.FinishReason {
-	case "stop":
-		result.StopReason = muxllm.StopReasonEndTurn
-	case "tool_calls":
-		result.StopReason = muxllm.StopReasonToolUse
-	case "length":
-		result.StopReason = muxllm.StopReasonMaxTokens
-	default:
+	// The provider's finish_reason is passed through unchanged so the mux
+	// adapter can report it as the raw finish reason; mapStopReason knows
+	// the Chat Completions values.
+	result.StopReason = muxllm.StopReason(choice.FinishReason)
+	if result.StopReason == "" {
 		result.StopReason = muxllm.StopReasonEndTurn
 	}
 
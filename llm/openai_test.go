@@ -1429,3 +1429,39 @@ func TestOpenAIStreamingErrorResponse(t *testing.T) {
 		t.Errorf("error type = %T, want *AuthenticationError", err)
 	}
 }
+
+func TestOpenAISeedNotSent(t *testing.T) {
+	// The Responses API has no seed parameter, so a seed on the request must
+	// not reach the body.
+	var body map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding body: %v", err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"id": "resp_123",
+			"model": "gpt-5.2",
+			"status": "completed",
+			"output": [{"type": "message", "role": "assistant", "content": [{"type": "output_text", "text": "OK"}]}],
+			"usage": {"input_tokens": 10, "output_tokens": 5, "total_tokens": 15}
+		}`))
+	}))
+	defer server.Close()
+
+	adapter := NewOpenAIAdapter("sk-test", WithOpenAIBaseURL(server.URL))
+	seed := int64(42)
+	req := Request{Model: "gpt-5.2", Messages: []Message{UserMessage("Hello")}, Seed: &seed}
+	if _, err := adapter.Complete(context.Background(), req); err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+
+	if body["model"] != "gpt-5.2" {
+		t.Fatalf("request body = %v, want the request translated", body)
+	}
+	if _, ok := body["seed"]; ok {
+		t.Errorf("seed should not be sent to the Responses API, got %v", body["seed"])
+	}
+}
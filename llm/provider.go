@@ -8,7 +8,10 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"strconv"
 	"strings"
@@ -22,6 +25,7 @@ type ProviderAdapter interface {
 	Name() string
 	Complete(ctx context.Context, req Request) (*Response, error)
 	Stream(ctx context.Context, req Request) (<-chan StreamEvent, error)
+	Capabilities() AdapterCapabilities
 	Close() error
 }
 
@@ -37,6 +41,11 @@ type ToolChoiceChecker interface {
 	SupportsToolChoice(mode string) bool
 }
 
+// UserAgent is the User-Agent header sent on every adapter request unless a
+// caller overrides it with WithXHeaders. The mammoth binary sets it to
+// "mammoth/<version>" at startup.
+var UserAgent = "mammoth/dev"
+
 // BaseAdapter provides common HTTP functionality shared across all provider adapters.
 // Provider-specific adapters embed BaseAdapter to reuse request building, header management,
 // and rate limit parsing.
@@ -46,32 +55,131 @@ type BaseAdapter struct {
 	DefaultHeaders map[string]string
 	Timeout        AdapterTimeout
 	HTTPClient     *http.Client
+
+	// streamClient sends streaming requests. It shares HTTPClient's
+	// transport but has no overall timeout, since a healthy stream may run
+	// far longer than any single completion; the request context still
+	// bounds it.
+	streamClient *http.Client
+
+	// clientHeaders are caller-supplied headers (WithXHeaders) sent on every
+	// request. They are applied before auth and provider headers so they can
+	// never replace credentials.
+	clientHeaders map[string]string
+
+	// logExchange, when set, receives the raw JSON request body and the raw
+	// response body of every request. Set via the adapters' WithXRequestLogger
+	// options.
+	logExchange func(reqBody, respBody []byte)
+}
+
+// RequestLogger receives the raw translated JSON an adapter sends to its
+// provider and the raw body it receives back. Streaming responses are passed
+// as the undecoded SSE payload once the stream is closed. API keys and other
+// headers are never passed to the logger.
+type RequestLogger func(provider string, reqBody, respBody []byte)
+
+// setRequestLogger installs fn as the exchange logger, tagging each call with
+// the provider name. A nil fn disables logging.
+func (b *BaseAdapter) setRequestLogger(provider string, fn RequestLogger) {
+	if fn == nil {
+		b.logExchange = nil
+		return
+	}
+	b.logExchange = func(reqBody, respBody []byte) { fn(provider, reqBody, respBody) }
+}
+
+// addClientHeaders merges headers into the caller-supplied header set.
+func (b *BaseAdapter) addClientHeaders(headers map[string]string) {
+	if b.clientHeaders == nil {
+		b.clientHeaders = make(map[string]string, len(headers))
+	}
+	for k, v := range headers {
+		b.clientHeaders[k] = v
+	}
 }
 
 // NewBaseAdapter creates a BaseAdapter with the given API key, base URL, and timeout config.
-// It initializes the HTTP client and default headers map.
+// It initializes the HTTP clients and default headers map.
 func NewBaseAdapter(apiKey, baseURL string, timeout AdapterTimeout) *BaseAdapter {
-	return &BaseAdapter{
+	b := &BaseAdapter{
 		APIKey:         apiKey,
 		BaseURL:        baseURL,
 		DefaultHeaders: make(map[string]string),
-		Timeout:        timeout,
-		HTTPClient: &http.Client{
-			Timeout: timeout.Request,
-		},
 	}
+	b.setTimeout(timeout)
+	return b
+}
+
+// newAdapterTransport returns a transport whose dials and TLS handshakes
+// give up after connect, so a provider that accepts no connections fails
+// fast instead of waiting out the whole request timeout.
+func newAdapterTransport(connect time.Duration) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if connect > 0 {
+		t.DialContext = (&net.Dialer{Timeout: connect, KeepAlive: 30 * time.Second}).DialContext
+		t.TLSHandshakeTimeout = connect
+	}
+	return t
+}
+
+// setTimeout applies a full timeout config: a fresh transport bounded by
+// timeout.Connect, with completions bounded by timeout.Request.
+func (b *BaseAdapter) setTimeout(timeout AdapterTimeout) {
+	b.Timeout = timeout
+	b.setHTTPClient(&http.Client{Transport: newAdapterTransport(timeout.Connect), Timeout: timeout.Request})
+}
+
+// setHTTPTimeout changes the overall timeout of completion requests,
+// keeping the current transport.
+func (b *BaseAdapter) setHTTPTimeout(d time.Duration) {
+	b.Timeout.Request = d
+	c := *b.HTTPClient
+	c.Timeout = d
+	b.setHTTPClient(&c)
+}
+
+// setHTTPClient sends completions through client and derives the streaming
+// client from it, minus its overall timeout.
+func (b *BaseAdapter) setHTTPClient(client *http.Client) {
+	b.HTTPClient = client
+	stream := *client
+	stream.Timeout = 0
+	b.streamClient = &stream
 }
 
 // DoRequest builds and executes an HTTP request against the provider's API.
-// It JSON-encodes the body (if non-nil), sets authorization and content type headers,
-// applies default headers, and then applies per-request header overrides.
-// The request respects the provided context for timeout and cancellation.
+// It JSON-encodes the body (if non-nil), sets the User-Agent and client headers,
+// then authorization and content type headers, applies default headers, and
+// finally applies per-request header overrides.
+// The request respects the provided context for timeout and cancellation, and
+// is bounded by HTTPClient's timeout; one that times out returns a
+// *RequestTimeoutError.
 func (b *BaseAdapter) DoRequest(ctx context.Context, method, path string, body any, headers map[string]string) (*http.Response, error) {
+	return b.do(ctx, b.HTTPClient, method, path, body, headers)
+}
+
+// DoStreamRequest is DoRequest for streaming responses: it has no overall
+// timeout, so only the context (and the transport's connect timeout) bound
+// how long the stream stays open.
+func (b *BaseAdapter) DoStreamRequest(ctx context.Context, method, path string, body any, headers map[string]string) (*http.Response, error) {
+	client := b.streamClient
+	if client == nil {
+		stream := *b.HTTPClient
+		stream.Timeout = 0
+		client = &stream
+	}
+	return b.do(ctx, client, method, path, body, headers)
+}
+
+func (b *BaseAdapter) do(ctx context.Context, client *http.Client, method, path string, body any, headers map[string]string) (*http.Response, error) {
 	url := b.BaseURL + path
 
 	var reqBody *bytes.Buffer
+	var encoded []byte
 	if body != nil {
-		encoded, err := json.Marshal(body)
+		var err error
+		encoded, err = json.Marshal(body)
 		if err != nil {
 			return nil, fmt.Errorf("encoding request body: %w", err)
 		}
@@ -89,6 +197,12 @@ func (b *BaseAdapter) DoRequest(ctx context.Context, method, path string, body a
 		return nil, fmt.Errorf("creating request: %w", err)
 	}
 
+	// Client headers go first so auth and provider headers always win
+	httpReq.Header.Set("User-Agent", UserAgent)
+	for k, v := range b.clientHeaders {
+		httpReq.Header.Set(k, v)
+	}
+
 	// Set authorization header
 	if b.APIKey != "" {
 		httpReq.Header.Set("Authorization", "Bearer "+b.APIKey)
@@ -109,14 +223,47 @@ func (b *BaseAdapter) DoRequest(ctx context.Context, method, path string, body a
 		httpReq.Header.Set(k, v)
 	}
 
-	resp, err := b.HTTPClient.Do(httpReq)
+	resp, err := client.Do(httpReq)
 	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() && ctx.Err() == nil {
+			return nil, &RequestTimeoutError{SDKError: SDKError{Message: "executing request", Cause: err}}
+		}
 		return nil, fmt.Errorf("executing request: %w", err)
 	}
 
+	if b.logExchange != nil {
+		resp.Body = &loggedBody{ReadCloser: resp.Body, reqBody: encoded, log: b.logExchange}
+	}
+
 	return resp, nil
 }
 
+// loggedBody captures everything read from a response body and hands the
+// request and response bytes to the exchange logger when the body is closed.
+type loggedBody struct {
+	io.ReadCloser
+	reqBody []byte
+	buf     bytes.Buffer
+	log     func(reqBody, respBody []byte)
+	closed  bool
+}
+
+func (l *loggedBody) Read(p []byte) (int, error) {
+	n, err := l.ReadCloser.Read(p)
+	l.buf.Write(p[:n])
+	return n, err
+}
+
+func (l *loggedBody) Close() error {
+	err := l.ReadCloser.Close()
+	if !l.closed {
+		l.closed = true
+		l.log(l.reqBody, l.buf.Bytes())
+	}
+	return err
+}
+
 // ParseRateLimitHeaders extracts rate limit information from provider response headers.
 // It parses the standard x-ratelimit-* headers and the retry-after header.
 // Returns nil if no rate limit headers are present.
@@ -166,6 +313,24 @@ func (b *BaseAdapter) ParseRateLimitHeaders(headers http.Header) *RateLimitInfo
 	return info
 }
 
+// parseRetryAfter reads the retry-after header as a number of seconds to
+// wait. Both delta-seconds and HTTP-date forms are accepted. Returns nil when
+// the header is absent or unparseable.
+func parseRetryAfter(headers http.Header) *float64 {
+	v := strings.TrimSpace(headers.Get("retry-after"))
+	if v == "" {
+		return nil
+	}
+	if seconds, err := strconv.ParseFloat(v, 64); err == nil && seconds >= 0 {
+		return &seconds
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		seconds := max(time.Until(at).Seconds(), 0)
+		return &seconds
+	}
+	return nil
+}
+
 // ExtractSystemMessages separates system and developer role messages from the rest.
 // It concatenates the text content of all system/developer messages (joined by newlines)
 // and returns them along with the remaining non-system messages.
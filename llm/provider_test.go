@@ -6,6 +6,7 @@ package llm
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -152,6 +153,63 @@ func TestBaseAdapterDoRequestPerRequestHeadersOverrideDefaults(t *testing.T) {
 	}
 }
 
+func TestAdapterClientHeaders(t *testing.T) {
+	custom := map[string]string{
+		"X-Gateway-Route": "team-a",
+		"User-Agent":      "mammoth-ci/1.0",
+	}
+	tests := []struct {
+		name    string
+		authKey string
+		authVal string
+		adapter func(baseURL string, headers map[string]string) ProviderAdapter
+	}{
+		{"anthropic", "X-Api-Key", "secret", func(u string, h map[string]string) ProviderAdapter {
+			return NewAnthropicAdapter("secret", WithAnthropicBaseURL(u), WithAnthropicHeaders(h))
+		}},
+		{"openai", "Authorization", "Bearer secret", func(u string, h map[string]string) ProviderAdapter {
+			return NewOpenAIAdapter("secret", WithOpenAIBaseURL(u), WithOpenAIHeaders(h))
+		}},
+		{"gemini", "X-Goog-Api-Key", "secret", func(u string, h map[string]string) ProviderAdapter {
+			return NewGeminiAdapter("secret", WithGeminiBaseURL(u), WithGeminiHeaders(h))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got http.Header
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Clone()
+				w.WriteHeader(http.StatusBadRequest)
+			}))
+			defer server.Close()
+
+			req := Request{Model: "m", Messages: []Message{UserMessage("hi")}}
+
+			// Without options, the default User-Agent is sent.
+			_, _ = tt.adapter(server.URL, nil).Complete(context.Background(), req)
+			if ua := got.Get("User-Agent"); ua != UserAgent {
+				t.Errorf("default User-Agent = %q, want %q", ua, UserAgent)
+			}
+
+			// Custom headers are added and may override User-Agent, but never auth.
+			withAuth := map[string]string{tt.authKey: "stolen"}
+			for k, v := range custom {
+				withAuth[k] = v
+			}
+			_, _ = tt.adapter(server.URL, withAuth).Complete(context.Background(), req)
+			for k, v := range custom {
+				if g := got.Get(k); g != v {
+					t.Errorf("%s = %q, want %q", k, g, v)
+				}
+			}
+			if g := got.Get(tt.authKey); g != tt.authVal {
+				t.Errorf("%s = %q, want %q (client headers must not replace auth)", tt.authKey, g, tt.authVal)
+			}
+		})
+	}
+}
+
 func TestBaseAdapterDoRequestNilBody(t *testing.T) {
 	var receivedBody []byte
 
@@ -587,3 +645,106 @@ func TestBaseAdapterDoRequestResponseBody(t *testing.T) {
 		t.Errorf("status = %v, want %q", result["status"], "success")
 	}
 }
+
+// slowServer answers after delay, or gives up when the client disconnects.
+func slowServer(t *testing.T, delay time.Duration) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHTTPTimeoutTripsOnSlowServer(t *testing.T) {
+	server := slowServer(t, 5*time.Second)
+	const timeout = 50 * time.Millisecond
+	adapters := map[string]ProviderAdapter{
+		"anthropic": NewAnthropicAdapter("key", WithAnthropicBaseURL(server.URL), WithAnthropicHTTPTimeout(timeout)),
+		"openai":    NewOpenAIAdapter("key", WithOpenAIBaseURL(server.URL), WithOpenAIHTTPTimeout(timeout)),
+		"gemini":    NewGeminiAdapter("key", WithGeminiBaseURL(server.URL), WithGeminiHTTPTimeout(timeout)),
+	}
+	for name, adapter := range adapters {
+		t.Run(name, func(t *testing.T) {
+			start := time.Now()
+			_, err := adapter.Complete(context.Background(), Request{Model: "m", Messages: []Message{UserMessage("hi")}})
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Fatalf("Complete took %v, want it cut off near the %v timeout", elapsed, timeout)
+			}
+			var timeoutErr *RequestTimeoutError
+			if !errors.As(err, &timeoutErr) {
+				t.Fatalf("err = %v (%T), want *RequestTimeoutError", err, err)
+			}
+			if !timeoutErr.IsRetryable() {
+				t.Error("timeouts should be retryable")
+			}
+		})
+	}
+}
+
+func TestWithHTTPClientIsUsed(t *testing.T) {
+	server := slowServer(t, 5*time.Second)
+	adapter := NewAnthropicAdapter("key",
+		WithAnthropicBaseURL(server.URL),
+		WithAnthropicHTTPClient(&http.Client{Timeout: 50 * time.Millisecond}))
+
+	_, err := adapter.Complete(context.Background(), Request{Model: "m", Messages: []Message{UserMessage("hi")}})
+	var timeoutErr *RequestTimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("err = %v, want the custom client's timeout", err)
+	}
+	if adapter.streamClient.Timeout != 0 {
+		t.Errorf("stream client timeout = %v, want none", adapter.streamClient.Timeout)
+	}
+}
+
+func TestStreamOutlivesHTTPTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		flusher := w.(http.Flusher)
+		_, _ = w.Write([]byte("event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"m\",\"model\":\"m\",\"usage\":{\"input_tokens\":1}}}\n\n"))
+		flusher.Flush()
+		time.Sleep(200 * time.Millisecond)
+		_, _ = w.Write([]byte("event: message_delta\ndata: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"end_turn\"},\"usage\":{\"output_tokens\":1}}\n\n" +
+			"event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n"))
+	}))
+	defer server.Close()
+
+	adapter := NewAnthropicAdapter("key", WithAnthropicBaseURL(server.URL), WithAnthropicHTTPTimeout(50*time.Millisecond))
+	ch, err := adapter.Stream(context.Background(), Request{Model: "m", Messages: []Message{UserMessage("hi")}})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	var finished bool
+	for evt := range ch {
+		if evt.Type == StreamErrorEvt {
+			t.Fatalf("stream error past the completion timeout: %v", evt.Error)
+		}
+		finished = finished || evt.Type == StreamFinish
+	}
+	if !finished {
+		t.Error("expected the stream to finish despite outlasting the completion timeout")
+	}
+}
+
+func TestStreamRespectsContext(t *testing.T) {
+	server := slowServer(t, 5*time.Second)
+	adapter := NewAnthropicAdapter("key", WithAnthropicBaseURL(server.URL))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := adapter.Stream(ctx, Request{Model: "m", Messages: []Message{UserMessage("hi")}}); err == nil {
+		t.Fatal("expected the cancelled stream request to fail")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Stream took %v after its context expired", elapsed)
+	}
+}
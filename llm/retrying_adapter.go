@@ -0,0 +1,66 @@
+// ABOUTME: RetryingAdapter decorates any ProviderAdapter with retry and exponential backoff.
+// ABOUTME: Retries retryable errors (rate limits, server errors), honoring Retry-After and context cancellation.
+
+package llm
+
+import "context"
+
+// RetryingAdapter wraps a ProviderAdapter so that retryable failures, such as
+// RateLimitError and ServerError, are retried according to a RetryPolicy.
+// It lets callers outside the pipeline engine use adapters directly without
+// writing their own retry loops. Retry-After hints on provider errors are
+// used as the minimum delay, and cancelling the context stops retrying.
+type RetryingAdapter struct {
+	inner  ProviderAdapter
+	policy RetryPolicy
+}
+
+// NewRetryingAdapter wraps inner with the given retry policy.
+func NewRetryingAdapter(inner ProviderAdapter, policy RetryPolicy) *RetryingAdapter {
+	return &RetryingAdapter{inner: inner, policy: policy}
+}
+
+// Name returns the wrapped adapter's name.
+func (r *RetryingAdapter) Name() string {
+	return r.inner.Name()
+}
+
+// Complete sends the request, retrying retryable failures.
+func (r *RetryingAdapter) Complete(ctx context.Context, req Request) (*Response, error) {
+	var resp *Response
+	err := Retry(ctx, r.policy, func() error {
+		var err error
+		resp, err = r.inner.Complete(ctx, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Stream opens a stream, retrying retryable failures while establishing it.
+// Errors that occur after the stream has started are delivered on the
+// channel and are not retried.
+func (r *RetryingAdapter) Stream(ctx context.Context, req Request) (<-chan StreamEvent, error) {
+	var ch <-chan StreamEvent
+	err := Retry(ctx, r.policy, func() error {
+		var err error
+		ch, err = r.inner.Stream(ctx, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+// Capabilities reports the wrapped adapter's capabilities.
+func (r *RetryingAdapter) Capabilities() AdapterCapabilities {
+	return r.inner.Capabilities()
+}
+
+// Close closes the wrapped adapter.
+func (r *RetryingAdapter) Close() error {
+	return r.inner.Close()
+}
@@ -0,0 +1,122 @@
+// ABOUTME: Tests for the RetryingAdapter decorator using an httptest-backed Anthropic adapter.
+// ABOUTME: Covers retry-then-success, non-retryable errors, Retry-After hints, and context cancellation.
+
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fastRetryPolicy retries quickly so tests do not sleep.
+func fastRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:        3,
+		BaseDelay:         time.Millisecond,
+		MaxDelay:          10 * time.Millisecond,
+		BackoffMultiplier: 2,
+	}
+}
+
+const retryTestSuccess = `{"id":"msg_ok","type":"message","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"finally"}],"stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":1}}`
+
+// TestRetryingAdapterRetriesServerErrors verifies two 503s are retried and
+// the third, successful response is returned.
+func TestRetryingAdapterRetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"type":"error","error":{"type":"overloaded_error","message":"busy"}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(retryTestSuccess))
+	}))
+	defer server.Close()
+
+	adapter := NewRetryingAdapter(NewAnthropicAdapter("key", WithAnthropicBaseURL(server.URL)), fastRetryPolicy())
+	resp, err := adapter.Complete(context.Background(), Request{Model: "claude-sonnet-4-20250514", Messages: []Message{UserMessage("hi")}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.TextContent() != "finally" {
+		t.Errorf("text = %q, want finally", resp.TextContent())
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("server called %d times, want 3", got)
+	}
+	if adapter.Name() != "anthropic" {
+		t.Errorf("Name() = %q, want the wrapped adapter's name", adapter.Name())
+	}
+}
+
+// TestRetryingAdapterDoesNotRetryClientErrors verifies non-retryable errors
+// are returned after a single attempt.
+func TestRetryingAdapterDoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"invalid_request_error","message":"bad"}}`))
+	}))
+	defer server.Close()
+
+	adapter := NewRetryingAdapter(NewAnthropicAdapter("key", WithAnthropicBaseURL(server.URL)), fastRetryPolicy())
+	_, err := adapter.Complete(context.Background(), Request{Model: "m", Messages: []Message{UserMessage("hi")}})
+	var invalid *InvalidRequestError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("error = %v, want InvalidRequestError", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("server called %d times, want 1", got)
+	}
+}
+
+// TestRetryingAdapterHonorsRetryAfterAndCancellation verifies the Retry-After
+// header sets the minimum delay and cancelling the context stops retrying.
+func TestRetryingAdapterHonorsRetryAfterAndCancellation(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"rate_limit_error","message":"slow down"}}`))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var delay time.Duration
+	policy := fastRetryPolicy()
+	policy.OnRetry = func(_ error, _ int, d time.Duration) {
+		delay = d
+		cancel()
+	}
+
+	adapter := NewRetryingAdapter(NewAnthropicAdapter("key", WithAnthropicBaseURL(server.URL)), policy)
+	start := time.Now()
+	_, err := adapter.Complete(ctx, Request{Model: "m", Messages: []Message{UserMessage("hi")}})
+
+	var rateLimit *RateLimitError
+	if !errors.As(err, &rateLimit) {
+		t.Fatalf("error = %v, want RateLimitError", err)
+	}
+	if rateLimit.RetryAfter == nil || *rateLimit.RetryAfter != 30 {
+		t.Errorf("RetryAfter = %v, want 30", rateLimit.RetryAfter)
+	}
+	if delay != 30*time.Second {
+		t.Errorf("retry delay = %v, want the Retry-After value", delay)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("cancellation did not stop the wait (took %v)", elapsed)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("server called %d times, want 1", got)
+	}
+}
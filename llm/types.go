@@ -390,6 +390,7 @@ type Request struct {
 	MaxTokens       *int              `json:"max_tokens,omitempty"`
 	StopSequences   []string          `json:"stop_sequences,omitempty"`
 	ReasoningEffort string            `json:"reasoning_effort,omitempty"` // "none", "low", "medium", "high"
+	Seed            *int64            `json:"seed,omitempty"`             // sampling seed; ignored by adapters whose API has none, such as OpenAI Responses
 	Metadata        map[string]string `json:"metadata,omitempty"`
 	ProviderOptions map[string]any    `json:"provider_options,omitempty"`
 }
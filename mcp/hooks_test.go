@@ -0,0 +1,275 @@
+// ABOUTME: Tests that node attributes implemented as handler hooks take effect in MCP runs, not only the CLI.
+// ABOUTME: Each test runs a pipeline through run_pipeline and checks the finished run or the requests its LLM client received.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	trackerllm "github.com/2389-research/tracker/llm"
+	"github.com/2389-research/tracker/pipeline"
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// requestCapturingCompleter records every request and answers with plain
+// text, reporting tokens as each response's usage.
+type requestCapturingCompleter struct {
+	tokens int
+
+	mu       sync.Mutex
+	requests []trackerllm.Request
+}
+
+func (c *requestCapturingCompleter) Complete(_ context.Context, req *trackerllm.Request) (*trackerllm.Response, error) {
+	c.mu.Lock()
+	c.requests = append(c.requests, *req)
+	c.mu.Unlock()
+	return &trackerllm.Response{
+		Message:      trackerllm.AssistantMessage("done"),
+		FinishReason: trackerllm.FinishReason{Reason: "stop"},
+		Usage:        trackerllm.Usage{TotalTokens: c.tokens},
+	}, nil
+}
+
+// runHookPipeline runs source through the run_pipeline tool and returns the
+// finished run.
+func runHookPipeline(t *testing.T, source string, opts ...ServerOption) *ActiveRun {
+	t.Helper()
+	cs, ms := connectTestServerWithTools(t, opts...)
+	result, err := cs.CallTool(context.Background(), &mcpsdk.CallToolParams{
+		Name:      "run_pipeline",
+		Arguments: map[string]any{"source": source},
+	})
+	if err != nil {
+		t.Fatalf("CallTool error: %v", err)
+	}
+	var output RunPipelineOutput
+	if err := json.Unmarshal([]byte(result.Content[0].(*mcpsdk.TextContent).Text), &output); err != nil {
+		t.Fatalf("unmarshal output: %v", err)
+	}
+	waitForRunCompletion(t, ms, output.RunID)
+	run, _ := ms.registry.Get(output.RunID)
+	return run
+}
+
+func TestRunPipeline_SkipIfSkipsNode(t *testing.T) {
+	// Run, the node succeeds and no edge matches; skipped, the skip edge leads on.
+	run := runHookPipeline(t, `digraph skip {
+	start [shape=Mdiamond]
+	optional [shape=diamond, skip_if="context.run_optional != yes"]
+	done [shape=Msquare]
+	start -> optional
+	optional -> done [condition="outcome = skipped"]
+}`)
+	run.mu.RLock()
+	defer run.mu.RUnlock()
+	if run.Status != StatusCompleted {
+		t.Fatalf("status = %q (%s), want completed", run.Status, run.Error)
+	}
+	if !slices.Equal(run.SkippedNodes, []string{"optional"}) {
+		t.Errorf("skipped nodes = %v, want [optional]", run.SkippedNodes)
+	}
+}
+
+func TestRunPipeline_SuccessIfFailsNode(t *testing.T) {
+	// The node succeeds, but the criterion is unmet, so the run takes the
+	// fail edge.
+	run := runHookPipeline(t, `digraph check {
+	start [shape=Mdiamond]
+	test [shape=diamond, success_if="context.tests_passed = true"]
+	fix [shape=diamond]
+	done [shape=Msquare]
+	start -> test
+	test -> done [condition="outcome = success"]
+	test -> fix [condition="outcome = fail"]
+	fix -> done
+}`)
+	run.mu.RLock()
+	defer run.mu.RUnlock()
+	if run.Status != StatusCompleted {
+		t.Fatalf("status = %q (%s), want completed", run.Status, run.Error)
+	}
+	if !slices.Contains(run.CompletedNodes, "fix") {
+		t.Errorf("completed nodes = %v, want the fail edge taken to fix", run.CompletedNodes)
+	}
+}
+
+func TestRunPipeline_GenerationParamsReachRequest(t *testing.T) {
+	client := &requestCapturingCompleter{}
+	run := runHookPipeline(t, `digraph gen {
+	start [shape=Mdiamond]
+	write [shape=box, prompt="write", max_tokens="2048", stop="END"]
+	done [shape=Msquare]
+	start -> write -> done
+}`, WithLLMClient(client))
+	run.mu.RLock()
+	defer run.mu.RUnlock()
+	if run.Status != StatusCompleted {
+		t.Fatalf("status = %q (%s), want completed", run.Status, run.Error)
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if len(client.requests) == 0 {
+		t.Fatal("expected a codergen request")
+	}
+	req := client.requests[0]
+	if req.MaxTokens == nil || *req.MaxTokens != 2048 {
+		t.Errorf("MaxTokens = %v, want 2048", req.MaxTokens)
+	}
+	if !slices.Equal(req.StopSequences, []string{"END"}) {
+		t.Errorf("StopSequences = %q, want [END]", req.StopSequences)
+	}
+}
+
+func TestRunPipeline_TokenBudgetFailsNode(t *testing.T) {
+	run := runHookPipeline(t, `digraph budget {
+	graph [token_budget="100"]
+	start [shape=Mdiamond]
+	write [shape=box, prompt="write"]
+	capped [shape=diamond]
+	done [shape=Msquare]
+	start -> write
+	write -> done [condition="outcome = success"]
+	write -> capped [condition="outcome = fail"]
+	capped -> done
+}`, WithLLMClient(&requestCapturingCompleter{tokens: 500}))
+	run.mu.RLock()
+	defer run.mu.RUnlock()
+	if run.Status != StatusCompleted {
+		t.Fatalf("status = %q (%s), want completed", run.Status, run.Error)
+	}
+	if !slices.Contains(run.CompletedNodes, "capped") {
+		t.Errorf("completed nodes = %v, want the node over its allocation to take the fail edge", run.CompletedNodes)
+	}
+}
+
+func TestRunPipeline_RetryBackoffRetriesNode(t *testing.T) {
+	// The tool fails its first run; only a retry reaches the success edge.
+	// Tool nodes need the exec environment that comes with an LLM client.
+	run := runHookPipeline(t, `digraph retry {
+	start [shape=Mdiamond]
+	flaky [shape=parallelogram, tool_command="test -f ran || { touch ran; exit 1; }", retry_backoff="linear", retry_base="1ms"]
+	done [shape=Msquare]
+	start -> flaky
+	flaky -> done [condition="outcome = success"]
+}`, WithLLMClient(&requestCapturingCompleter{}))
+	run.mu.RLock()
+	defer run.mu.RUnlock()
+	if run.Status != StatusCompleted {
+		t.Fatalf("status = %q (%s), want completed", run.Status, run.Error)
+	}
+	var retries int
+	for _, evt := range run.EventBuffer {
+		if evt.Type == string(pipeline.EventStageRetrying) {
+			retries++
+		}
+	}
+	if retries != 1 {
+		t.Errorf("stage_retrying events = %d, want 1", retries)
+	}
+}
+
+// streamingCompleter is a requestCapturingCompleter that can also stream,
+// counting the requests it streamed.
+type streamingCompleter struct {
+	requestCapturingCompleter
+	streamed atomic.Int32
+}
+
+func (c *streamingCompleter) Stream(_ context.Context, _ *trackerllm.Request) <-chan trackerllm.StreamEvent {
+	c.streamed.Add(1)
+	ch := make(chan trackerllm.StreamEvent, 2)
+	defer close(ch)
+	ch <- trackerllm.StreamEvent{Type: trackerllm.EventTextDelta, Delta: "done"}
+	ch <- trackerllm.StreamEvent{Type: trackerllm.EventFinish, FinishReason: &trackerllm.FinishReason{Reason: "stop"}}
+	return ch
+}
+
+func TestRunPipeline_StreamingNodeStreams(t *testing.T) {
+	client := &streamingCompleter{}
+	run := runHookPipeline(t, `digraph stream {
+	start [shape=Mdiamond]
+	write [shape=box, prompt="write", stream="true"]
+	done [shape=Msquare]
+	start -> write -> done
+}`, WithLLMClient(client))
+	run.mu.RLock()
+	defer run.mu.RUnlock()
+	if run.Status != StatusCompleted {
+		t.Fatalf("status = %q (%s), want completed", run.Status, run.Error)
+	}
+	if got := client.streamed.Load(); got != 1 {
+		t.Errorf("streamed requests = %d, want the streaming node to use Stream", got)
+	}
+}
+
+func TestRunPipeline_ProviderPinSetsRequestProvider(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "sk-test")
+	client := &requestCapturingCompleter{}
+	run := runHookPipeline(t, `digraph pin {
+	graph [provider="openai"]
+	start [shape=Mdiamond]
+	write [shape=box, prompt="write"]
+	done [shape=Msquare]
+	start -> write -> done
+}`, WithLLMClient(client))
+	run.mu.RLock()
+	defer run.mu.RUnlock()
+	if run.Status != StatusCompleted {
+		t.Fatalf("status = %q (%s), want completed", run.Status, run.Error)
+	}
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if len(client.requests) == 0 || client.requests[0].Provider != "openai" {
+		t.Errorf("requests = %+v, want the pinned provider openai", client.requests)
+	}
+}
+
+func TestRunPipeline_ProviderPinWithoutCredentialsFails(t *testing.T) {
+	t.Setenv("GEMINI_API_KEY", "")
+	t.Setenv("GOOGLE_API_KEY", "")
+	client := &requestCapturingCompleter{}
+	run := runHookPipeline(t, `digraph pin {
+	graph [provider="gemini"]
+	start [shape=Mdiamond]
+	write [shape=box, prompt="write"]
+	done [shape=Msquare]
+	start -> write -> done
+}`, WithLLMClient(client))
+	run.mu.RLock()
+	defer run.mu.RUnlock()
+	if run.Status != StatusFailed || !strings.Contains(run.Error, "GEMINI_API_KEY") {
+		t.Errorf("status = %q, error = %q; want a failure naming the missing key", run.Status, run.Error)
+	}
+	if len(client.requests) != 0 {
+		t.Errorf("backend called %d times before the preflight failed", len(client.requests))
+	}
+}
+
+func TestRunPipeline_ArtifactCapFailsNode(t *testing.T) {
+	// The tool writes into the run's working directory, as agents do.
+	run := runHookPipeline(t, `digraph cap {
+	start [shape=Mdiamond]
+	write [shape=parallelogram, tool_command="head -c 2000 /dev/zero > out.bin", max_artifact_bytes="1000"]
+	capped [shape=diamond]
+	done [shape=Msquare]
+	start -> write
+	write -> done [condition="outcome = success"]
+	write -> capped [condition="outcome = fail"]
+	capped -> done
+}`, WithLLMClient(&requestCapturingCompleter{}))
+	run.mu.RLock()
+	defer run.mu.RUnlock()
+	if run.Status != StatusCompleted {
+		t.Fatalf("status = %q (%s), want completed", run.Status, run.Error)
+	}
+	if !slices.Contains(run.CompletedNodes, "capped") {
+		t.Errorf("completed nodes = %v, want the node over its cap to take the fail edge", run.CompletedNodes)
+	}
+}
@@ -0,0 +1,53 @@
+// ABOUTME: Tests that MCP runs record their primary output and report it from get_run_status.
+// ABOUTME: Selection itself is covered by runstate's OutputLog tests.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestRunPipeline_RecordsPrimaryOutput(t *testing.T) {
+	run := runHookPipeline(t, `digraph p {
+	start [shape=Mdiamond]
+	write [shape=box, prompt="write"]
+	done [shape=Msquare]
+	start -> write -> done
+}`, WithLLMClient(&requestCapturingCompleter{}))
+	run.mu.RLock()
+	defer run.mu.RUnlock()
+	if run.Status != StatusCompleted {
+		t.Fatalf("status = %q (%s), want completed", run.Status, run.Error)
+	}
+	if run.ResultNode != "write" || run.PrimaryOutput != "done" {
+		t.Errorf("got (%q, %q), want the terminal node's output", run.ResultNode, run.PrimaryOutput)
+	}
+}
+
+func TestGetRunStatus_PrimaryOutput(t *testing.T) {
+	cs, ms := connectStatusServer(t)
+	run := ms.registry.Create(simplePipeline, RunConfig{})
+	run.mu.Lock()
+	run.Status = StatusCompleted
+	run.ResultNode = "answer"
+	run.PrimaryOutput = "42"
+	run.mu.Unlock()
+
+	res, err := cs.CallTool(context.Background(), &mcpsdk.CallToolParams{
+		Name:      "get_run_status",
+		Arguments: map[string]any{"run_id": run.ID},
+	})
+	if err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+	var raw map[string]any
+	if err := json.Unmarshal([]byte(res.Content[0].(*mcpsdk.TextContent).Text), &raw); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if raw["primary_output"] != "42" || raw["result_node"] != "answer" {
+		t.Errorf("status JSON = %v, want top-level primary_output and result_node", raw)
+	}
+}
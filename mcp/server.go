@@ -3,6 +3,7 @@
 package mcp
 
 import (
+	"github.com/2389-research/mammoth/nodelock"
 	"github.com/2389-research/tracker/agent"
 	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -14,6 +15,7 @@ type Server struct {
 	index     *RunIndex
 	dataDir   string
 	llmClient agent.Completer
+	nodeLocks *nodelock.Manager
 }
 
 // ServerOption configures a Server.
@@ -30,9 +32,10 @@ func WithLLMClient(client agent.Completer) ServerOption {
 // The directory is used to store run metadata, checkpoints, and artifacts.
 func NewServer(dataDir string, opts ...ServerOption) *Server {
 	s := &Server{
-		registry: NewRunRegistry(),
-		index:    NewRunIndex(dataDir),
-		dataDir:  dataDir,
+		registry:  NewRunRegistry(),
+		index:     NewRunIndex(dataDir),
+		dataDir:   dataDir,
+		nodeLocks: nodelock.New(),
 	}
 	for _, opt := range opts {
 		opt(s)
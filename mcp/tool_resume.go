@@ -10,6 +10,20 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/2389-research/mammoth/answerpattern"
+	"github.com/2389-research/mammoth/artifactcap"
+	"github.com/2389-research/mammoth/genparams"
+	"github.com/2389-research/mammoth/nextnode"
+	"github.com/2389-research/mammoth/nodelock"
+	"github.com/2389-research/mammoth/providerpin"
+	"github.com/2389-research/mammoth/retrybackoff"
+	"github.com/2389-research/mammoth/runstate"
+	"github.com/2389-research/mammoth/shapemap"
+	"github.com/2389-research/mammoth/skipif"
+	"github.com/2389-research/mammoth/streaming"
+	"github.com/2389-research/mammoth/successif"
+	"github.com/2389-research/mammoth/tokenbudget"
+	"github.com/2389-research/mammoth/toolguard"
 	"github.com/2389-research/tracker/pipeline"
 	"github.com/2389-research/tracker/agent/exec"
 	"github.com/2389-research/tracker/pipeline/handlers"
@@ -132,6 +146,20 @@ func (s *Server) resumePipeline(run *ActiveRun, checkpointPath string) {
 
 	// Parse the DOT source into a tracker pipeline graph.
 	graph, parseErr := pipeline.ParseDOT(run.Source)
+	if parseErr == nil {
+		parseErr = shapemap.Apply(graph)
+	}
+	// A pinned provider is checked only when the run talks to a real backend.
+	if parseErr == nil && s.llmClient != nil {
+		parseErr = providerpin.Check(graph)
+	}
+	if parseErr == nil {
+		providerpin.Apply(graph)
+	}
+	var tokenAllocs map[string]int64
+	if parseErr == nil {
+		tokenAllocs, parseErr = tokenbudget.Allocations(graph)
+	}
 	if parseErr != nil {
 		run.mu.Lock()
 		run.Status = StatusFailed
@@ -142,7 +170,7 @@ func (s *Server) resumePipeline(run *ActiveRun, checkpointPath string) {
 	}
 
 	// Load the checkpoint state and initialize engine context from it.
-	cp, cpErr := pipeline.LoadCheckpoint(checkpointPath)
+	cp, cpErr := runstate.LoadCheckpoint(checkpointPath)
 	if cpErr != nil {
 		run.mu.Lock()
 		run.Status = StatusFailed
@@ -156,23 +184,39 @@ func (s *Server) resumePipeline(run *ActiveRun, checkpointPath string) {
 	iv := &mcpInterviewer{run: run, ctx: ctx}
 
 	// Build the handler registry with the interviewer and LLM client wired in.
+	agentEvents := newAgentEventHandler(run)
+	pipelineEvents := newPipelineEventHandler(run)
 	registryOpts := []handlers.RegistryOption{
 		handlers.WithInterviewer(iv, graph),
-		handlers.WithAgentEventHandler(newAgentEventHandler(run)),
+		handlers.WithAgentEventHandler(agentEvents),
 	}
 	if s.llmClient != nil {
-		registryOpts = append(registryOpts, handlers.WithLLMClient(s.llmClient, run.ArtifactDir))
-		registryOpts = append(registryOpts, handlers.WithExecEnvironment(exec.NewLocalEnvironment(run.ArtifactDir)))
+		registryOpts = append(registryOpts, handlers.WithLLMClient(toolguard.Completer(tokenbudget.Completer(genparams.Completer(streaming.Completer(s.llmClient))), agentEvents), run.ArtifactDir))
+		registryOpts = append(registryOpts, handlers.WithExecEnvironment(toolguard.Environment(exec.NewLocalEnvironment(run.ArtifactDir))))
 	}
 	registry := handlers.NewDefaultRegistry(graph, registryOpts...)
+	genparams.Hook(registry)
+	streaming.Hook(registry)
+	retrybackoff.Hook("none", pipelineEvents)(registry)
+	artifactcap.Hook(run.ArtifactDir, 0)(registry)
+	answerpattern.Hook(graph)(registry)
+	tokenbudget.Hook(tokenAllocs)(registry)
+	successif.Hook(graph)(registry)
+	run.outputs.Hook(registry)
+	nodelock.Hook(graph, s.nodeLocks)(registry)
+	toolguard.Hook(graph)(registry)
+	skipif.Hook(graph)(registry)
+	nextnode.Hook(graph)(registry)
 
-	// Build engine options with checkpoint context for resume.
+	// Build engine options with checkpoint context for resume. The initial
+	// context is applied over the graph's attributes, so merge them first
+	// to let edited attributes win over the checkpoint's copies.
 	newCheckpointPath := filepath.Join(run.CheckpointDir, "checkpoint.json")
 	opts := []pipeline.EngineOption{
-		pipeline.WithPipelineEventHandler(newPipelineEventHandler(run)),
+		pipeline.WithPipelineEventHandler(pipelineEvents),
 		pipeline.WithCheckpointPath(newCheckpointPath),
 		pipeline.WithArtifactDir(run.ArtifactDir),
-		pipeline.WithInitialContext(cp.Context),
+		pipeline.WithInitialContext(runstate.ResumeContext(cp.Context, graph.Attrs)),
 	}
 
 	engine := pipeline.NewEngine(graph, registry, opts...)
@@ -185,6 +229,8 @@ func (s *Server) resumePipeline(run *ActiveRun, checkpointPath string) {
 	} else {
 		run.Status = StatusCompleted
 		run.Result = result
+		run.ResultNode, run.PrimaryOutput = run.outputs.Primary(graph, result)
+		_, run.SkippedNodes = skipif.Split(result)
 	}
 	run.mu.Unlock()
 
@@ -192,7 +238,8 @@ func (s *Server) resumePipeline(run *ActiveRun, checkpointPath string) {
 }
 
 // findLatestCheckpoint lists checkpoint*.json files in the given directory
-// and returns the one with the most recent modification time.
+// and returns the one with the most recent modification time. Files that do
+// not load, such as a checkpoint truncated by a crash mid-write, are skipped.
 func findLatestCheckpoint(dir string) (string, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
@@ -213,6 +260,9 @@ func findLatestCheckpoint(dir string) (string, error) {
 		if err != nil {
 			continue
 		}
+		if _, err := runstate.LoadCheckpoint(filepath.Join(dir, name)); err != nil {
+			continue
+		}
 		modTime := info.ModTime().UnixNano()
 		if latestName == "" || modTime > latestTime {
 			latestName = name
@@ -9,8 +9,20 @@ import (
 	"os"
 	"path/filepath"
 
-	"github.com/2389-research/mammoth/dot"
+	"github.com/2389-research/mammoth/answerpattern"
+	"github.com/2389-research/mammoth/artifactcap"
 	"github.com/2389-research/mammoth/dot/validator"
+	"github.com/2389-research/mammoth/genparams"
+	"github.com/2389-research/mammoth/nextnode"
+	"github.com/2389-research/mammoth/nodelock"
+	"github.com/2389-research/mammoth/providerpin"
+	"github.com/2389-research/mammoth/retrybackoff"
+	"github.com/2389-research/mammoth/shapemap"
+	"github.com/2389-research/mammoth/skipif"
+	"github.com/2389-research/mammoth/streaming"
+	"github.com/2389-research/mammoth/successif"
+	"github.com/2389-research/mammoth/tokenbudget"
+	"github.com/2389-research/mammoth/toolguard"
 	"github.com/2389-research/tracker/pipeline"
 	"github.com/2389-research/tracker/agent/exec"
 	"github.com/2389-research/tracker/pipeline/handlers"
@@ -49,7 +61,7 @@ func (s *Server) handleRunPipeline(_ context.Context, _ *mcpsdk.CallToolRequest,
 	}
 
 	// Pre-validate using mammoth's DOT parser and validator for immediate feedback.
-	graph, err := dot.Parse(src)
+	_, diags, err := validator.ParseAndValidate(src)
 	if err != nil {
 		return &mcpsdk.CallToolResult{
 			Content: []mcpsdk.Content{&mcpsdk.TextContent{Text: fmt.Sprintf("parse error: %v", err)}},
@@ -57,7 +69,6 @@ func (s *Server) handleRunPipeline(_ context.Context, _ *mcpsdk.CallToolRequest,
 		}, RunPipelineOutput{}, nil
 	}
 
-	diags := validator.Lint(graph)
 	for _, d := range diags {
 		if d.Severity == "error" {
 			return &mcpsdk.CallToolResult{
@@ -138,6 +149,20 @@ func (s *Server) executePipeline(run *ActiveRun) {
 
 	// Parse the DOT source into a tracker pipeline graph.
 	graph, parseErr := pipeline.ParseDOT(run.Source)
+	if parseErr == nil {
+		parseErr = shapemap.Apply(graph)
+	}
+	// A pinned provider is checked only when the run talks to a real backend.
+	if parseErr == nil && s.llmClient != nil {
+		parseErr = providerpin.Check(graph)
+	}
+	if parseErr == nil {
+		providerpin.Apply(graph)
+	}
+	var tokenAllocs map[string]int64
+	if parseErr == nil {
+		tokenAllocs, parseErr = tokenbudget.Allocations(graph)
+	}
 	if parseErr != nil {
 		run.mu.Lock()
 		run.Status = StatusFailed
@@ -151,20 +176,34 @@ func (s *Server) executePipeline(run *ActiveRun) {
 	iv := &mcpInterviewer{run: run, ctx: ctx}
 
 	// Build the handler registry with the interviewer and LLM client wired in.
+	agentEvents := newAgentEventHandler(run)
+	pipelineEvents := newPipelineEventHandler(run)
 	registryOpts := []handlers.RegistryOption{
 		handlers.WithInterviewer(iv, graph),
-		handlers.WithAgentEventHandler(newAgentEventHandler(run)),
+		handlers.WithAgentEventHandler(agentEvents),
 	}
 	if s.llmClient != nil {
-		registryOpts = append(registryOpts, handlers.WithLLMClient(s.llmClient, run.ArtifactDir))
-		registryOpts = append(registryOpts, handlers.WithExecEnvironment(exec.NewLocalEnvironment(run.ArtifactDir)))
+		registryOpts = append(registryOpts, handlers.WithLLMClient(toolguard.Completer(tokenbudget.Completer(genparams.Completer(streaming.Completer(s.llmClient))), agentEvents), run.ArtifactDir))
+		registryOpts = append(registryOpts, handlers.WithExecEnvironment(toolguard.Environment(exec.NewLocalEnvironment(run.ArtifactDir))))
 	}
 	registry := handlers.NewDefaultRegistry(graph, registryOpts...)
+	genparams.Hook(registry)
+	streaming.Hook(registry)
+	retrybackoff.Hook("none", pipelineEvents)(registry)
+	artifactcap.Hook(run.ArtifactDir, 0)(registry)
+	answerpattern.Hook(graph)(registry)
+	tokenbudget.Hook(tokenAllocs)(registry)
+	successif.Hook(graph)(registry)
+	run.outputs.Hook(registry)
+	nodelock.Hook(graph, s.nodeLocks)(registry)
+	toolguard.Hook(graph)(registry)
+	skipif.Hook(graph)(registry)
+	nextnode.Hook(graph)(registry)
 
 	// Build engine options.
 	checkpointPath := filepath.Join(run.CheckpointDir, "checkpoint.json")
 	opts := []pipeline.EngineOption{
-		pipeline.WithPipelineEventHandler(newPipelineEventHandler(run)),
+		pipeline.WithPipelineEventHandler(pipelineEvents),
 		pipeline.WithCheckpointPath(checkpointPath),
 		pipeline.WithArtifactDir(run.ArtifactDir),
 	}
@@ -179,6 +218,8 @@ func (s *Server) executePipeline(run *ActiveRun) {
 	} else {
 		run.Status = StatusCompleted
 		run.Result = result
+		run.ResultNode, run.PrimaryOutput = run.outputs.Primary(graph, result)
+		_, run.SkippedNodes = skipif.Split(result)
 	}
 	run.mu.Unlock()
 
@@ -42,7 +42,7 @@ const simplePipeline = `digraph pipeline {
 
 // connectTestServerWithTools creates an MCP server with run_pipeline and
 // get_run_status registered, returning a connected client session.
-func connectTestServerWithTools(t *testing.T) (*mcpsdk.ClientSession, *Server) {
+func connectTestServerWithTools(t *testing.T, opts ...ServerOption) (*mcpsdk.ClientSession, *Server) {
 	t.Helper()
 	ctx := context.Background()
 	srv := mcpsdk.NewServer(&mcpsdk.Implementation{
@@ -50,7 +50,7 @@ func connectTestServerWithTools(t *testing.T) (*mcpsdk.ClientSession, *Server) {
 		Version: "v0.0.1-test",
 	}, nil)
 
-	ms := NewServer(t.TempDir())
+	ms := NewServer(t.TempDir(), opts...)
 	ms.registerValidatePipeline(srv)
 	ms.registerRunPipeline(srv)
 
@@ -1,11 +1,12 @@
 // ABOUTME: get_run_status MCP tool handler for querying pipeline run state.
-// ABOUTME: Returns current status, node, activity, completed nodes, and pending question.
+// ABOUTME: Returns current status, node, activity, completed nodes, pending question, and primary output.
 package mcp
 
 import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 
 	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -22,7 +23,10 @@ type GetRunStatusOutput struct {
 	CurrentNode     string           `json:"current_node,omitempty"`
 	CurrentActivity string           `json:"current_activity,omitempty"`
 	CompletedNodes  []string         `json:"completed_nodes,omitempty"`
+	SkippedNodes    []string         `json:"skipped_nodes,omitempty"`
 	PendingQuestion *PendingQuestion `json:"pending_question,omitempty"`
+	ResultNode      string           `json:"result_node,omitempty"`
+	PrimaryOutput   string           `json:"primary_output,omitempty"`
 	Error           string           `json:"error,omitempty"`
 }
 
@@ -63,7 +67,10 @@ func (s *Server) handleGetRunStatus(_ context.Context, _ *mcpsdk.CallToolRequest
 		CurrentNode:     run.CurrentNode,
 		CurrentActivity: run.CurrentActivity,
 		CompletedNodes:  completedNodes,
+		SkippedNodes:    slices.Clone(run.SkippedNodes),
 		PendingQuestion: pq,
+		ResultNode:      run.ResultNode,
+		PrimaryOutput:   run.PrimaryOutput,
 		Error:           run.Error,
 	}
 	run.mu.RUnlock()
@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/2389-research/mammoth/dot"
 	"github.com/2389-research/mammoth/dot/validator"
 	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -63,7 +62,7 @@ func (s *Server) handleValidatePipeline(_ context.Context, _ *mcpsdk.CallToolReq
 		}, ValidatePipelineOutput{}, nil
 	}
 
-	graph, err := dot.Parse(src)
+	_, diags, err := validator.ParseAndValidate(src)
 	if err != nil {
 		return &mcpsdk.CallToolResult{
 			Content: []mcpsdk.Content{&mcpsdk.TextContent{Text: fmt.Sprintf("parse error: %v", err)}},
@@ -71,8 +70,6 @@ func (s *Server) handleValidatePipeline(_ context.Context, _ *mcpsdk.CallToolReq
 		}, ValidatePipelineOutput{}, nil
 	}
 
-	diags := validator.Lint(graph)
-
 	hasError := false
 	output := ValidatePipelineOutput{Valid: true}
 	for _, d := range diags {
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/2389-research/mammoth/runstate"
 	"github.com/2389-research/tracker/pipeline"
 )
 
@@ -52,9 +53,12 @@ type ActiveRun struct {
 	CurrentNode     string
 	CurrentActivity string
 	CompletedNodes  []string
+	SkippedNodes    []string
 	PendingQuestion *PendingQuestion
 	EventBuffer     []RunEvent
 	Result          *pipeline.EngineResult
+	ResultNode      string
+	PrimaryOutput   string
 	Error           string
 	CreatedAt       time.Time
 	ArtifactDir     string
@@ -63,6 +67,10 @@ type ActiveRun struct {
 	// cancel cancels the pipeline's context.
 	cancel context.CancelFunc
 
+	// outputs records node outputs in execution order for primary
+	// result resolution.
+	outputs runstate.OutputLog
+
 	// answerCh delivers human gate answers from answer_question tool calls.
 	answerCh chan string
 
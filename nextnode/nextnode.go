@@ -0,0 +1,72 @@
+// ABOUTME: Explicit routing: a handler names the successor to run next in the next_node context key.
+// ABOUTME: The choice becomes the outcome's suggested next node, which the engine's edge selection follows.
+package nextnode
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// Key is the context key a handler sets to choose the next node itself, e.g.
+// to dispatch to a stage named in the context. Handlers outside tracker
+// cannot extend its Outcome, so the choice travels in ContextUpdates.
+const Key = "next_node"
+
+// edgeChoiceHandlers read their node's outgoing edges as branches or
+// choices rather than routes, so they cannot set next_node.
+var edgeChoiceHandlers = map[string]bool{
+	"parallel":   true,
+	"wait.human": true,
+}
+
+// Hook wraps every handler in the graph so next_node is cleared before each
+// node runs, and checked and handed to the engine's edge selection after.
+func Hook(g *pipeline.Graph) func(*pipeline.HandlerRegistry) {
+	return func(registry *pipeline.HandlerRegistry) {
+		wrapped := map[string]bool{}
+		for _, n := range g.Nodes {
+			if wrapped[n.Handler] {
+				continue
+			}
+			if inner := registry.Get(n.Handler); inner != nil {
+				registry.Register(&routingHandler{inner: inner, graph: g})
+				wrapped[n.Handler] = true
+			}
+		}
+	}
+}
+
+// routingHandler keeps one node's next_node from routing the nodes after it,
+// fails the node when it names a node it cannot route to, and otherwise
+// suggests the named successor to the engine.
+type routingHandler struct {
+	inner pipeline.Handler
+	graph *pipeline.Graph
+}
+
+func (h *routingHandler) Name() string { return h.inner.Name() }
+
+func (h *routingHandler) Execute(ctx context.Context, node *pipeline.Node, pctx *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	if prev, _ := pctx.Get(Key); prev != "" {
+		pctx.Set(Key, "")
+	}
+	out, err := h.inner.Execute(ctx, node, pctx)
+	next := out.ContextUpdates[Key]
+	if err != nil || next == "" {
+		return out, err
+	}
+	if _, ok := h.graph.Nodes[next]; !ok {
+		return pipeline.Outcome{}, fmt.Errorf("node %q: next_node %q is not a node in the graph", node.ID, next)
+	}
+	if edgeChoiceHandlers[node.Handler] || node.ID == h.graph.ExitNode {
+		return pipeline.Outcome{}, fmt.Errorf("node %q: %s nodes cannot set next_node", node.ID, node.Handler)
+	}
+	if !slices.ContainsFunc(h.graph.OutgoingEdges(node.ID), func(e *pipeline.Edge) bool { return e.To == next }) {
+		return pipeline.Outcome{}, fmt.Errorf("node %q: next_node %q is not a successor", node.ID, next)
+	}
+	out.SuggestedNextNodes = []string{next}
+	return out, nil
+}
@@ -0,0 +1,146 @@
+// ABOUTME: Tests for next_node explicit routing: a handler picks which successor runs next.
+// ABOUTME: Covers choosing among successors, matching conditions still winning, and the errors for invalid targets.
+package nextnode
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/2389-research/tracker/pipeline"
+	"github.com/2389-research/tracker/pipeline/handlers"
+)
+
+// namedHandler is a stub handler that records the nodes it runs and applies
+// fixed context updates.
+type namedHandler struct {
+	name    string
+	updates map[string]string
+	ran     []string
+}
+
+func (h *namedHandler) Name() string { return h.name }
+
+func (h *namedHandler) Execute(_ context.Context, node *pipeline.Node, _ *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	h.ran = append(h.ran, node.ID)
+	return pipeline.Outcome{Status: pipeline.OutcomeSuccess, ContextUpdates: h.updates}, nil
+}
+
+// nextNodeDOT routes router to a by default, to b only through next_node,
+// and to c only on failure; d is not a successor of router.
+const nextNodeDOT = `digraph p {
+	start [shape=Mdiamond]
+	router [type="router"]
+	a [type="work"]
+	b [type="work"]
+	c [type="work"]
+	d [type="work"]
+	done [shape=Msquare]
+	start -> router
+	router -> a [weight=1]
+	router -> b
+	router -> c [condition="outcome = fail"]
+	a -> done
+	b -> done
+	c -> done
+	d -> done
+}`
+
+// runNextNode runs source with a router that sets next_node to target and
+// returns the work nodes that ran.
+func runNextNode(t *testing.T, source, target string) ([]string, error) {
+	t.Helper()
+	g, err := pipeline.ParseDOT(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	work := &namedHandler{name: "work"}
+	registry := handlers.NewDefaultRegistry(g)
+	registry.Register(&namedHandler{name: "router", updates: map[string]string{Key: target}})
+	registry.Register(work)
+	Hook(g)(registry)
+	_, err = pipeline.NewEngine(g, registry, pipeline.WithArtifactDir(t.TempDir())).Run(context.Background())
+	return work.ran, err
+}
+
+func TestNextNodeChoosesSuccessor(t *testing.T) {
+	for _, tt := range []struct {
+		target string
+		want   []string
+	}{
+		{target: "", want: []string{"a"}},
+		{target: "b", want: []string{"b"}},
+		// An edge whose condition does not hold is still a successor.
+		{target: "c", want: []string{"c"}},
+	} {
+		ran, err := runNextNode(t, nextNodeDOT, tt.target)
+		if err != nil {
+			t.Fatalf("next_node=%q: run: %v", tt.target, err)
+		}
+		if !slices.Equal(ran, tt.want) {
+			t.Errorf("next_node=%q: ran %v, want %v", tt.target, ran, tt.want)
+		}
+	}
+}
+
+func TestNextNodeYieldsToMatchingCondition(t *testing.T) {
+	source := strings.Replace(nextNodeDOT, `router -> a [weight=1]`, `router -> a [condition="outcome = success"]`, 1)
+	ran, err := runNextNode(t, source, "b")
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if !slices.Equal(ran, []string{"a"}) {
+		t.Errorf("ran %v, want the matching condition's choice [a]", ran)
+	}
+}
+
+func TestNextNodeInvalidTarget(t *testing.T) {
+	for _, tt := range []struct {
+		target  string
+		wantErr string
+	}{
+		{target: "d", wantErr: `next_node "d" is not a successor`},
+		{target: "nope", wantErr: `next_node "nope" is not a node in the graph`},
+	} {
+		ran, err := runNextNode(t, nextNodeDOT, tt.target)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("next_node=%s: err = %v, want it to contain %q", tt.target, err, tt.wantErr)
+		}
+		if len(ran) != 0 {
+			t.Errorf("next_node=%s: ran %v after the router failed", tt.target, ran)
+		}
+	}
+}
+
+func TestNextNodeDoesNotLeakToLaterNodes(t *testing.T) {
+	// a runs because router chose it, and must not see that choice as its own.
+	g, err := pipeline.ParseDOT(nextNodeDOT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var seen []string
+	registry := handlers.NewDefaultRegistry(g)
+	registry.Register(&namedHandler{name: "router", updates: map[string]string{Key: "a"}})
+	registry.Register(&peekHandler{seen: &seen})
+	Hook(g)(registry)
+	if _, err := pipeline.NewEngine(g, registry, pipeline.WithArtifactDir(t.TempDir())).Run(context.Background()); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if !slices.Equal(seen, []string{""}) {
+		t.Errorf("next_node seen by a = %q, want it cleared", seen)
+	}
+}
+
+// peekHandler records the next_node value visible when each node starts.
+type peekHandler struct {
+	seen *[]string
+}
+
+func (h *peekHandler) Name() string { return "work" }
+
+func (h *peekHandler) Execute(_ context.Context, _ *pipeline.Node, pctx *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	v, _ := pctx.Get(Key)
+	*h.seen = append(*h.seen, v)
+	return pipeline.Outcome{Status: pipeline.OutcomeSuccess}, nil
+}
@@ -0,0 +1,92 @@
+// ABOUTME: Named locks serializing pipeline nodes that share an external resource (mutex="<name>").
+// ABOUTME: A Manager is shared by every run it serves; Hook wraps node handlers to hold the lock while they execute.
+package nodelock
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// Attr is the node attribute naming the lock a node holds while it runs.
+const Attr = "mutex"
+
+// Manager hands out named locks. Nodes holding the same name never run at
+// the same time, whether they are parallel branches of one run or belong to
+// different runs using the same Manager. The zero value is not usable; use
+// New.
+type Manager struct {
+	mu    sync.Mutex
+	locks map[string]chan struct{}
+}
+
+// New returns an empty lock manager.
+func New() *Manager {
+	return &Manager{locks: map[string]chan struct{}{}}
+}
+
+// Lock blocks until the named lock is free or ctx is done. On success it
+// returns the function that releases the lock.
+func (m *Manager) Lock(ctx context.Context, name string) (func(), error) {
+	m.mu.Lock()
+	ch, ok := m.locks[name]
+	if !ok {
+		ch = make(chan struct{}, 1)
+		m.locks[name] = ch
+	}
+	m.mu.Unlock()
+
+	select {
+	case ch <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	var once sync.Once
+	return func() { once.Do(func() { <-ch }) }, nil
+}
+
+// Hook wraps the handlers of every node in g with a mutex attribute so the
+// node holds its lock from m for the whole of its execution. A nil m
+// installs nothing.
+func Hook(g *pipeline.Graph, m *Manager) func(*pipeline.HandlerRegistry) {
+	return func(registry *pipeline.HandlerRegistry) {
+		if m == nil {
+			return
+		}
+		wrapped := map[string]bool{}
+		for _, n := range g.Nodes {
+			if strings.TrimSpace(n.Attrs[Attr]) == "" || wrapped[n.Handler] {
+				continue
+			}
+			if inner := registry.Get(n.Handler); inner != nil {
+				registry.Register(&lockedHandler{inner: inner, locks: m})
+				wrapped[n.Handler] = true
+			}
+		}
+	}
+}
+
+// lockedHandler runs the wrapped handler while holding the node's named lock.
+// Nodes sharing the handler without a mutex attribute run unlocked.
+type lockedHandler struct {
+	inner pipeline.Handler
+	locks *Manager
+}
+
+func (h *lockedHandler) Name() string { return h.inner.Name() }
+
+func (h *lockedHandler) Execute(ctx context.Context, node *pipeline.Node, pctx *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	name := strings.TrimSpace(node.Attrs[Attr])
+	if name == "" {
+		return h.inner.Execute(ctx, node, pctx)
+	}
+	unlock, err := h.locks.Lock(ctx, name)
+	if err != nil {
+		return pipeline.Outcome{}, fmt.Errorf("node %q: waiting for mutex %q: %w", node.ID, name, err)
+	}
+	defer unlock()
+	return h.inner.Execute(ctx, node, pctx)
+}
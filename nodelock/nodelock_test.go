@@ -0,0 +1,144 @@
+// ABOUTME: Tests for named node locks: parallel branches and separate runs sharing a mutex never overlap.
+// ABOUTME: A stub handler tracks how many nodes run at once while the real parallel handler fans out.
+package nodelock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/2389-research/tracker/pipeline"
+	"github.com/2389-research/tracker/pipeline/handlers"
+)
+
+// overlapHandler records the peak number of executions in flight at once.
+type overlapHandler struct {
+	active atomic.Int32
+	peak   atomic.Int32
+}
+
+func (h *overlapHandler) Name() string { return "work" }
+
+func (h *overlapHandler) Execute(ctx context.Context, _ *pipeline.Node, _ *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	n := h.active.Add(1)
+	defer h.active.Add(-1)
+	for {
+		peak := h.peak.Load()
+		if n <= peak || h.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	select {
+	case <-time.After(20 * time.Millisecond):
+	case <-ctx.Done():
+		return pipeline.Outcome{}, ctx.Err()
+	}
+	return pipeline.Outcome{Status: pipeline.OutcomeSuccess}, nil
+}
+
+const fanOutDOT = `digraph p {
+	start [shape=Mdiamond]
+	fan [shape=component]
+	a [type="work", mutex="%s"]
+	b [type="work", mutex="%s"]
+	c [type="work", mutex="%s"]
+	done [shape=Msquare]
+	start -> fan
+	fan -> a
+	fan -> b
+	fan -> c
+	a -> done
+	b -> done
+	c -> done
+}`
+
+// runFanOut executes the fan node of a graph whose three branches use the
+// given mutex names and returns the peak branch concurrency.
+func runFanOut(t *testing.T, m *Manager, a, b, c string) int32 {
+	t.Helper()
+	g, err := pipeline.ParseDOT(fmt.Sprintf(fanOutDOT, a, b, c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	work := &overlapHandler{}
+	registry := pipeline.NewHandlerRegistry()
+	registry.Register(work)
+	registry.Register(handlers.NewParallelHandler(g, registry))
+	Hook(g, m)(registry)
+
+	out, err := registry.Execute(context.Background(), g.Nodes["fan"], pipeline.NewPipelineContext())
+	if err != nil || out.Status != pipeline.OutcomeSuccess {
+		t.Fatalf("fan out = %+v, %v; want success", out, err)
+	}
+	return work.peak.Load()
+}
+
+func TestParallelBranchesSharingMutexDoNotOverlap(t *testing.T) {
+	if peak := runFanOut(t, New(), "db", "db", "db"); peak != 1 {
+		t.Errorf("peak concurrency = %d, want 1 for branches sharing a mutex", peak)
+	}
+}
+
+func TestBranchesWithoutSharedMutexStillOverlap(t *testing.T) {
+	// Branch c has no mutex and the others use distinct names, so nothing
+	// holds them back; 20ms each leaves ample time to overlap.
+	if peak := runFanOut(t, New(), "db", "deploy", ""); peak < 2 {
+		t.Errorf("peak concurrency = %d, want branches with different mutexes to overlap", peak)
+	}
+}
+
+func TestRunsSharingManagerDoNotOverlap(t *testing.T) {
+	m := New()
+	work := &overlapHandler{}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		g, err := pipeline.ParseDOT(`digraph p {
+			start [shape=Mdiamond]
+			deploy [type="work", mutex="deploy-target"]
+			done [shape=Msquare]
+			start -> deploy -> done
+		}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		registry := pipeline.NewHandlerRegistry()
+		registry.Register(work)
+		Hook(g, m)(registry)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := registry.Execute(context.Background(), g.Nodes["deploy"], pipeline.NewPipelineContext()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if peak := work.peak.Load(); peak != 1 {
+		t.Errorf("peak concurrency across runs = %d, want 1", peak)
+	}
+}
+
+func TestLockHonorsContext(t *testing.T) {
+	m := New()
+	unlock, err := m.Lock(context.Background(), "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := m.Lock(ctx, "db"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Lock on a held mutex = %v, want deadline exceeded", err)
+	}
+
+	unlock()
+	unlock() // releasing twice is harmless
+	again, err := m.Lock(context.Background(), "db")
+	if err != nil {
+		t.Fatalf("Lock after release: %v", err)
+	}
+	again()
+}
@@ -3,7 +3,10 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"math"
 	"math/rand"
 	"os"
@@ -18,17 +21,36 @@ import (
 
 // Game constants
 const (
-	targetFPS      = 30
-	frameDuration  = time.Second / targetFPS
-	paddleHeight   = 5
-	paddleChar     = "█"
-	ballChar       = "●"
-	winScore       = 11
-	initialBallSpd = 30.0 // characters per second
-	speedIncrement = 2.0  // speed boost per paddle hit
-	maxBallSpeed   = 80.0
+	targetFPS       = 30
+	frameDuration   = time.Second / targetFPS
+	paddleHeight    = 5
+	paddleChar      = "█"
+	ballChar        = "●"
+	defaultWinScore = 11
+	initialBallSpd  = 30.0 // characters per second
+	speedIncrement  = 2.0  // speed boost per paddle hit
+	maxBallSpeed    = 80.0
+	serveCountdown  = 3.0 // seconds from a reset to the serve
 )
 
+// Board and score limits applied to both auto-sized and overridden values.
+const (
+	minFieldW   = 20
+	maxFieldW   = 120
+	minFieldH   = 10
+	maxFieldH   = 40
+	minWinScore = 1
+	maxWinScore = 99
+)
+
+// gameConfig holds user overrides from the command line. A zero width or
+// height means "size to the terminal".
+type gameConfig struct {
+	width    int
+	height   int
+	winScore int
+}
+
 // Direction represents ball movement direction.
 type Direction struct {
 	dx, dy float64
@@ -53,13 +75,19 @@ type GameState struct {
 
 	// Scores
 	score1, score2 int
+	winScore       int
 
 	// Game flow
-	paused    bool // waiting to serve
+	paused    bool    // waiting to serve
+	countdown float64 // seconds until the serve while paused
 	gameOver  bool
 	winner    int // 1 or 2
 	quitting  bool
 	serveSide int // 1 = left serves, 2 = right serves
+
+	// Sound
+	bell     bool // ring the terminal bell on paddle hits and points
+	ringBell bool // a hit or point happened since the last frame
 }
 
 // InputEvent represents a keyboard input.
@@ -73,9 +101,18 @@ const (
 	InputDown
 	InputQuit
 	InputRestart
+	InputBell
 )
 
 func main() {
+	cfg, err := parseFlags(os.Args[1:], os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		os.Exit(2)
+	}
+
 	// Put terminal into raw mode.
 	oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
 	if err != nil {
@@ -102,7 +139,7 @@ func main() {
 	go readInput(inputCh)
 
 	// Initialize game.
-	game := newGame()
+	game := newGame(cfg)
 
 	ticker := time.NewTicker(frameDuration)
 	defer ticker.Stop()
@@ -126,14 +163,20 @@ func main() {
 					return
 				}
 				if game.gameOver && inp == InputRestart {
-					game = newGame()
+					bell := game.bell
+					game = newGame(cfg)
+					game.bell = bell
 					continue
 				}
 				handleInput(&game, inp, dt)
 			}
 
-			if !game.gameOver && !game.paused {
-				updateBall(&game, dt)
+			if !game.gameOver {
+				if game.paused {
+					updateServe(&game, dt)
+				} else {
+					updateBall(&game, dt)
+				}
 			}
 
 			render(&game)
@@ -141,28 +184,73 @@ func main() {
 	}
 }
 
-// newGame creates a fresh game state sized to the current terminal.
-func newGame() GameState {
-	w, h := getTermSize()
+// parseFlags reads --width, --height, and --win-score from args. Negative
+// dimensions and non-positive win scores fall back to their defaults with a
+// warning written to stderr. An error is returned only for unparseable flags.
+func parseFlags(args []string, stderr io.Writer) (gameConfig, error) {
+	fs := flag.NewFlagSet("pong", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var cfg gameConfig
+	fs.IntVar(&cfg.width, "width", 0, fmt.Sprintf("field width in columns (%d-%d, 0 = fit terminal)", minFieldW, maxFieldW))
+	fs.IntVar(&cfg.height, "height", 0, fmt.Sprintf("field height in rows (%d-%d, 0 = fit terminal)", minFieldH, maxFieldH))
+	fs.IntVar(&cfg.winScore, "win-score", defaultWinScore, fmt.Sprintf("points needed to win (%d-%d)", minWinScore, maxWinScore))
+	if err := fs.Parse(args); err != nil {
+		return gameConfig{}, err
+	}
 
-	// Field is inside the border, so subtract 2 for left/right walls
-	// and 2 for top border + score line and bottom border.
-	fieldW := w - 2
-	fieldH := h - 3 // 1 for score line, 1 for top border, 1 for bottom border
+	if cfg.width < 0 {
+		fmt.Fprintf(stderr, "warning: invalid --width %d, sizing to terminal\n", cfg.width)
+		cfg.width = 0
+	}
+	if cfg.height < 0 {
+		fmt.Fprintf(stderr, "warning: invalid --height %d, sizing to terminal\n", cfg.height)
+		cfg.height = 0
+	}
+	if cfg.winScore <= 0 {
+		fmt.Fprintf(stderr, "warning: invalid --win-score %d, using %d\n", cfg.winScore, defaultWinScore)
+		cfg.winScore = defaultWinScore
+	}
+	return cfg, nil
+}
 
-	// Clamp to reasonable sizes.
-	if fieldW > 120 {
-		fieldW = 120
+// clampInt restricts v to the inclusive range [lo, hi].
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
 	}
-	if fieldH > 40 {
-		fieldH = 40
+	if v > hi {
+		return hi
 	}
-	if fieldW < 20 {
-		fieldW = 20
+	return v
+}
+
+// newGame creates a fresh game state. Dimensions not overridden by cfg are
+// sized to the current terminal; all values are clamped to sane ranges.
+func newGame(cfg gameConfig) GameState {
+	fieldW, fieldH := cfg.width, cfg.height
+	if fieldW == 0 || fieldH == 0 {
+		w, h := getTermSize()
+
+		// Field is inside the border, so subtract 2 for left/right walls
+		// and 2 for top border + score line and bottom border.
+		if fieldW == 0 {
+			fieldW = w - 2
+		}
+		if fieldH == 0 {
+			fieldH = h - 3 // 1 for score line, 1 for top border, 1 for bottom border
+		}
 	}
-	if fieldH < 10 {
-		fieldH = 10
+
+	// Clamp to reasonable sizes.
+	fieldW = clampInt(fieldW, minFieldW, maxFieldW)
+	fieldH = clampInt(fieldH, minFieldH, maxFieldH)
+
+	winScore := cfg.winScore
+	if winScore == 0 {
+		winScore = defaultWinScore
 	}
+	winScore = clampInt(winScore, minWinScore, maxWinScore)
 
 	game := GameState{
 		fieldW:    fieldW,
@@ -170,6 +258,7 @@ func newGame() GameState {
 		paddle1Y:  fieldH/2 - paddleHeight/2,
 		paddle2Y:  fieldH/2 - paddleHeight/2,
 		ballSpeed: initialBallSpd,
+		winScore:  winScore,
 		paused:    true,
 		serveSide: 1,
 	}
@@ -178,16 +267,30 @@ func newGame() GameState {
 	return game
 }
 
-// resetBall places the ball at center and gives it a random-ish direction.
+// resetBall places the ball at center and starts the serve countdown.
 func resetBall(g *GameState) {
 	g.ballX = float64(g.fieldW) / 2.0
 	g.ballY = float64(g.fieldH) / 2.0
 	g.ballSpeed = initialBallSpd
 	g.paused = true
+	g.countdown = serveCountdown
 
 	// Direction will be set when serve happens.
 }
 
+// updateServe advances the serve countdown by dt seconds and serves the ball
+// once it runs out.
+func updateServe(g *GameState, dt float64) {
+	if !g.paused {
+		return
+	}
+	g.countdown -= dt
+	if g.countdown <= 0 {
+		g.countdown = 0
+		serveBall(g)
+	}
+}
+
 // serveBall starts the ball moving toward the appropriate side.
 func serveBall(g *GameState) {
 	angle := (rand.Float64()*0.8 - 0.4) * math.Pi // -0.4π to 0.4π range
@@ -206,15 +309,14 @@ func serveBall(g *GameState) {
 	g.paused = false
 }
 
-// handleInput processes a single input event for paddle movement.
+// handleInput processes a single input event for paddle movement and the
+// bell toggle. Paddles can move during the serve countdown.
 func handleInput(g *GameState, inp InputEvent, dt float64) {
-	if g.paused && (inp == InputW || inp == InputS || inp == InputUp || inp == InputDown) {
-		serveBall(g)
-	}
-
 	paddleSpeed := 2 // rows per input event
 
 	switch inp {
+	case InputBell:
+		g.bell = !g.bell
 	case InputW:
 		g.paddle1Y -= paddleSpeed
 		if g.paddle1Y < 0 {
@@ -269,6 +371,7 @@ func updateBall(g *GameState, dt float64) {
 			if g.ballSpeed > maxBallSpeed {
 				g.ballSpeed = maxBallSpeed
 			}
+			g.ringBell = true
 		}
 	}
 
@@ -288,6 +391,7 @@ func updateBall(g *GameState, dt float64) {
 			if g.ballSpeed > maxBallSpeed {
 				g.ballSpeed = maxBallSpeed
 			}
+			g.ringBell = true
 		}
 	}
 
@@ -295,7 +399,8 @@ func updateBall(g *GameState, dt float64) {
 	if g.ballX < 0 {
 		g.score2++
 		g.serveSide = 2
-		if g.score2 >= winScore {
+		g.ringBell = true
+		if g.score2 >= g.winScore {
 			g.gameOver = true
 			g.winner = 2
 		} else {
@@ -307,7 +412,8 @@ func updateBall(g *GameState, dt float64) {
 	if g.ballX >= float64(g.fieldW) {
 		g.score1++
 		g.serveSide = 1
-		if g.score1 >= winScore {
+		g.ringBell = true
+		if g.score1 >= g.winScore {
 			g.gameOver = true
 			g.winner = 1
 		} else {
@@ -340,6 +446,12 @@ func render(g *GameState) {
 	// Move cursor to top-left, clear wouldn't be needed since we overwrite everything.
 	buf.WriteString("\033[H")
 
+	// At most one bell per frame, however many hits or points it covered.
+	if g.ringBell && g.bell {
+		buf.WriteString("\a")
+	}
+	g.ringBell = false
+
 	centerLine := g.fieldW / 2
 
 	for row := 0; row < frameH; row++ {
@@ -439,7 +551,7 @@ func render(g *GameState) {
 		buf.WriteString(msg)
 		buf.WriteString("\033[0m")
 	} else if g.paused {
-		msg := "Press any movement key to serve..."
+		msg := fmt.Sprintf("Serving in %d...", int(math.Ceil(g.countdown)))
 		pad := (frameW - len(msg)) / 2
 		if pad < 0 {
 			pad = 0
@@ -450,7 +562,7 @@ func render(g *GameState) {
 		buf.WriteString("\033[0m")
 	} else {
 		// Show controls.
-		msg := "P1: W/S  |  P2: ↑/↓  |  Q: Quit"
+		msg := "P1: W/S  |  P2: ↑/↓  |  B: Bell  |  Q: Quit"
 		pad := (frameW - len(msg)) / 2
 		if pad < 0 {
 			pad = 0
@@ -507,6 +619,10 @@ func readInput(ch chan<- InputEvent) {
 				ch <- InputRestart
 				i++
 
+			case b == 'b' || b == 'B':
+				ch <- InputBell
+				i++
+
 			case b == 3: // Ctrl+C
 				ch <- InputQuit
 				i++
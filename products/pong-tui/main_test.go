@@ -0,0 +1,208 @@
+// ABOUTME: Tests for pong game setup and flow: command-line overrides, newGame sizing, and serving.
+// ABOUTME: Covers flag parsing, fallback warnings, range clamping, the serve countdown, and the bell toggle.
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		want     gameConfig
+		wantWarn string
+	}{
+		{
+			name: "defaults",
+			args: nil,
+			want: gameConfig{winScore: defaultWinScore},
+		},
+		{
+			name: "all overrides",
+			args: []string{"--width", "40", "--height", "15", "--win-score", "5"},
+			want: gameConfig{width: 40, height: 15, winScore: 5},
+		},
+		{
+			name:     "negative width falls back",
+			args:     []string{"--width", "-3"},
+			want:     gameConfig{winScore: defaultWinScore},
+			wantWarn: "invalid --width -3",
+		},
+		{
+			name:     "negative height falls back",
+			args:     []string{"--height", "-1"},
+			want:     gameConfig{winScore: defaultWinScore},
+			wantWarn: "invalid --height -1",
+		},
+		{
+			name:     "zero win score falls back",
+			args:     []string{"--win-score", "0"},
+			want:     gameConfig{winScore: defaultWinScore},
+			wantWarn: "invalid --win-score 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			got, err := parseFlags(tt.args, &stderr)
+			if err != nil {
+				t.Fatalf("parseFlags: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("config = %+v, want %+v", got, tt.want)
+			}
+			if tt.wantWarn == "" && stderr.Len() > 0 {
+				t.Errorf("unexpected warning: %q", stderr.String())
+			}
+			if tt.wantWarn != "" && !strings.Contains(stderr.String(), tt.wantWarn) {
+				t.Errorf("stderr = %q, want it to contain %q", stderr.String(), tt.wantWarn)
+			}
+		})
+	}
+}
+
+func TestParseFlagsRejectsNonNumeric(t *testing.T) {
+	var stderr bytes.Buffer
+	if _, err := parseFlags([]string{"--width", "wide"}, &stderr); err == nil {
+		t.Fatal("expected error for non-numeric --width")
+	}
+}
+
+func TestNewGameHonorsOverrides(t *testing.T) {
+	g := newGame(gameConfig{width: 40, height: 15, winScore: 5})
+
+	if g.fieldW != 40 || g.fieldH != 15 {
+		t.Errorf("field = %dx%d, want 40x15", g.fieldW, g.fieldH)
+	}
+	if g.winScore != 5 {
+		t.Errorf("winScore = %d, want 5", g.winScore)
+	}
+	if g.paddle1Y != 15/2-paddleHeight/2 {
+		t.Errorf("paddle1Y = %d, want paddle centered in 15 rows", g.paddle1Y)
+	}
+}
+
+func TestNewGameClampsOutOfRange(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          gameConfig
+		wantW, wantH int
+		wantWinScore int
+	}{
+		{
+			name:         "too small",
+			cfg:          gameConfig{width: 5, height: 2, winScore: 1},
+			wantW:        minFieldW,
+			wantH:        minFieldH,
+			wantWinScore: 1,
+		},
+		{
+			name:         "too large",
+			cfg:          gameConfig{width: 500, height: 300, winScore: 1000},
+			wantW:        maxFieldW,
+			wantH:        maxFieldH,
+			wantWinScore: maxWinScore,
+		},
+		{
+			name:         "zero win score uses default",
+			cfg:          gameConfig{width: 50, height: 20},
+			wantW:        50,
+			wantH:        20,
+			wantWinScore: defaultWinScore,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newGame(tt.cfg)
+			if g.fieldW != tt.wantW || g.fieldH != tt.wantH {
+				t.Errorf("field = %dx%d, want %dx%d", g.fieldW, g.fieldH, tt.wantW, tt.wantH)
+			}
+			if g.winScore != tt.wantWinScore {
+				t.Errorf("winScore = %d, want %d", g.winScore, tt.wantWinScore)
+			}
+		})
+	}
+}
+
+func TestWinScoreEndsGame(t *testing.T) {
+	g := newGame(gameConfig{width: 40, height: 15, winScore: 2})
+	g.score1 = 1
+	g.paused = false
+	g.ballX = float64(g.fieldW) + 1
+	g.ballY = 0 // away from the right paddle
+	g.ballDir = Direction{dx: 1}
+
+	updateBall(&g, 0)
+
+	if !g.gameOver || g.winner != 1 {
+		t.Errorf("gameOver = %v winner = %d, want player 1 to win at 2 points", g.gameOver, g.winner)
+	}
+}
+
+func TestServeCountdown(t *testing.T) {
+	g := newGame(gameConfig{width: 40, height: 15})
+	if !g.paused || g.countdown != serveCountdown {
+		t.Fatalf("new game paused=%v countdown=%v, want paused with a %v s countdown", g.paused, g.countdown, serveCountdown)
+	}
+
+	// Movement during the countdown moves the paddle but does not serve.
+	handleInput(&g, InputW, 0)
+	if !g.paused {
+		t.Fatal("a movement key should no longer serve the ball")
+	}
+
+	frame := float64(frameDuration) / float64(time.Second)
+	frames := 0
+	for g.paused {
+		updateServe(&g, frame)
+		frames++
+		if frames > 10*targetFPS {
+			t.Fatal("countdown never served the ball")
+		}
+	}
+	if frames < int(serveCountdown*targetFPS)-1 {
+		t.Errorf("served after %d frames, want about %d", frames, int(serveCountdown*targetFPS))
+	}
+	if g.ballDir.dx >= 0 {
+		t.Errorf("ballDir.dx = %v, want a serve toward player 1", g.ballDir.dx)
+	}
+
+	// A point resets to paused and restarts the countdown.
+	g.ballX = -1
+	g.ballY = 0
+	updateBall(&g, 0)
+	if !g.paused || g.countdown != serveCountdown {
+		t.Errorf("after a point paused=%v countdown=%v, want a fresh countdown", g.paused, g.countdown)
+	}
+}
+
+func TestBellToggle(t *testing.T) {
+	g := newGame(gameConfig{width: 40, height: 15})
+	if g.bell {
+		t.Fatal("bell should be off by default")
+	}
+	handleInput(&g, InputBell, 0)
+	if !g.bell {
+		t.Fatal("B should turn the bell on")
+	}
+
+	// A paddle hit flags the bell for the next frame.
+	g.paused = false
+	g.ballX, g.ballY = 2, float64(g.paddle1Y+1)
+	g.ballDir = Direction{dx: -1}
+	updateBall(&g, 0)
+	if !g.ringBell {
+		t.Fatal("a paddle hit should ring the bell")
+	}
+
+	handleInput(&g, InputBell, 0)
+	if g.bell {
+		t.Error("B again should turn the bell off")
+	}
+}
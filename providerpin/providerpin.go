@@ -0,0 +1,78 @@
+// ABOUTME: Graph-level provider pin: provider="<name>" sets the LLM provider for every codergen node in a run.
+// ABOUTME: Overrides auto-detection, yields to per-node provider attributes, and fails preflight without credentials.
+package providerpin
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// KeyEnv maps provider names to the environment variables holding their API
+// keys, in the same priority order the tracker client uses to pick its
+// default provider.
+var KeyEnv = []struct {
+	Provider string
+	EnvVars  []string
+}{
+	{"anthropic", []string{"ANTHROPIC_API_KEY"}},
+	{"openai", []string{"OPENAI_API_KEY"}},
+	{"gemini", []string{"GEMINI_API_KEY", "GOOGLE_API_KEY"}},
+}
+
+// pinnedProvider returns the provider named by the graph's provider
+// attribute, lowercased, or "" when the graph does not pin one.
+func pinnedProvider(g *pipeline.Graph) string {
+	return strings.ToLower(strings.TrimSpace(g.Attrs["provider"]))
+}
+
+// Check reports a clear error when g pins a provider that is unknown or has
+// none of its API key variables set. Runs whose codergen nodes never reach a
+// real backend, such as replays, need no credentials and should skip it.
+func Check(g *pipeline.Graph) error {
+	if provider := pinnedProvider(g); provider != "" {
+		return checkCredentials(provider)
+	}
+	return nil
+}
+
+// checkCredentials reports a clear error when provider is not a known
+// provider or none of its API key variables are set.
+func checkCredentials(provider string) error {
+	var known []string
+	for _, p := range KeyEnv {
+		known = append(known, p.Provider)
+		if p.Provider != provider {
+			continue
+		}
+		for _, env := range p.EnvVars {
+			if os.Getenv(env) != "" {
+				return nil
+			}
+		}
+		return fmt.Errorf("pipeline pins provider %q (graph attribute provider) but %s is not set; set it or run 'mammoth setup'",
+			provider, strings.Join(p.EnvVars, " or "))
+	}
+	return fmt.Errorf("pipeline pins unknown provider %q (graph attribute provider; want one of %s)", provider, strings.Join(known, ", "))
+}
+
+// Apply resolves each codergen node's llm_provider: the node's own
+// llm_provider, then its provider attribute, then the graph's pinned provider.
+// Nodes left without one use the auto-detected provider as before.
+func Apply(g *pipeline.Graph) {
+	pinned := pinnedProvider(g)
+	for _, n := range g.Nodes {
+		if n.Handler != "codergen" || strings.TrimSpace(n.Attrs["llm_provider"]) != "" {
+			continue
+		}
+		provider := strings.ToLower(strings.TrimSpace(n.Attrs["provider"]))
+		if provider == "" {
+			provider = pinned
+		}
+		if provider != "" {
+			n.Attrs["llm_provider"] = provider
+		}
+	}
+}
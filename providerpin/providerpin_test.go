@@ -0,0 +1,66 @@
+// ABOUTME: Tests for the graph-level provider pin: node resolution order and the credentials preflight.
+// ABOUTME: Graphs are parsed from DOT and checked directly, without running them.
+package providerpin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/2389-research/tracker/pipeline"
+)
+
+func parse(t *testing.T, source string) *pipeline.Graph {
+	t.Helper()
+	g, err := pipeline.ParseDOT(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestApplyResolvesNodeProviders(t *testing.T) {
+	g := parse(t, `digraph p {
+		graph [provider="OpenAI"]
+		start [shape=Mdiamond]
+		pinned [shape=box, prompt="a"]
+		own_llm [shape=box, prompt="b", llm_provider="gemini"]
+		own_short [shape=box, prompt="c", provider="Anthropic"]
+		done [shape=Msquare]
+		start -> pinned -> own_llm -> own_short -> done
+	}`)
+	Apply(g)
+	for id, want := range map[string]string{"pinned": "openai", "own_llm": "gemini", "own_short": "anthropic", "start": ""} {
+		if got := g.Nodes[id].Attrs["llm_provider"]; got != want {
+			t.Errorf("%s: llm_provider = %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "sk-test")
+	t.Setenv("GEMINI_API_KEY", "")
+	t.Setenv("GOOGLE_API_KEY", "")
+	for _, tt := range []struct {
+		provider string
+		wantErr  string
+	}{
+		{provider: ""},
+		{provider: "openai"},
+		{provider: "gemini", wantErr: "GEMINI_API_KEY or GOOGLE_API_KEY is not set"},
+		// A misspelled provider is reported as unknown rather than as a missing key.
+		{provider: "mistral", wantErr: "unknown provider"},
+	} {
+		g := parse(t, `digraph p { start [shape=Mdiamond]; done [shape=Msquare]; start -> done }`)
+		g.Attrs["provider"] = tt.provider
+		err := Check(g)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("provider=%q: unexpected error: %v", tt.provider, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("provider=%q: err = %v, want it to contain %q", tt.provider, err, tt.wantErr)
+		}
+	}
+}
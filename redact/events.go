@@ -0,0 +1,80 @@
+// ABOUTME: Redaction for tracker pipeline and agent events, and for node outcomes before they reach the context.
+// ABOUTME: Event handler wrappers and a registry hook shared by the CLI runner and the web build bridge.
+package redact
+
+import (
+	"context"
+
+	"github.com/2389-research/tracker/agent"
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// PipelineHandler returns a handler that masks secrets in each event's
+// message and error before forwarding it to h. Returns h when either is nil.
+func PipelineHandler(r *Redactor, h pipeline.PipelineEventHandler) pipeline.PipelineEventHandler {
+	if r == nil || h == nil {
+		return h
+	}
+	return pipeline.PipelineEventHandlerFunc(func(evt pipeline.PipelineEvent) {
+		evt.Message = r.String(evt.Message)
+		evt.Err = r.Error(evt.Err)
+		h.HandlePipelineEvent(evt)
+	})
+}
+
+// AgentHandler returns a handler that masks secrets in each agent event's
+// text, tool input/output, and error before forwarding it to h. Returns h
+// when either is nil.
+func AgentHandler(r *Redactor, h agent.EventHandler) agent.EventHandler {
+	if r == nil || h == nil {
+		return h
+	}
+	return agent.EventHandlerFunc(func(evt agent.Event) {
+		evt.Text = r.String(evt.Text)
+		evt.ToolInput = r.String(evt.ToolInput)
+		evt.ToolOutput = r.String(evt.ToolOutput)
+		evt.ToolError = r.String(evt.ToolError)
+		evt.Preview = r.String(evt.Preview)
+		evt.Err = r.Error(evt.Err)
+		h.HandleEvent(evt)
+	})
+}
+
+// Hook wraps the handler of every node in g so the outcome's context updates
+// (failure reasons, tool output, responses) and any error are masked before
+// the engine merges them into the pipeline context and checkpoint. Install it
+// after every other hook so it sees the final outcome. A nil r installs
+// nothing.
+func Hook(g *pipeline.Graph, r *Redactor) func(*pipeline.HandlerRegistry) {
+	return func(registry *pipeline.HandlerRegistry) {
+		if r == nil {
+			return
+		}
+		wrapped := map[string]bool{}
+		for _, n := range g.Nodes {
+			if wrapped[n.Handler] {
+				continue
+			}
+			if inner := registry.Get(n.Handler); inner != nil {
+				registry.Register(&redactingHandler{inner: inner, r: r})
+				wrapped[n.Handler] = true
+			}
+		}
+	}
+}
+
+// redactingHandler masks secrets in the wrapped handler's outcome and error.
+type redactingHandler struct {
+	inner pipeline.Handler
+	r     *Redactor
+}
+
+func (h *redactingHandler) Name() string { return h.inner.Name() }
+
+func (h *redactingHandler) Execute(ctx context.Context, node *pipeline.Node, pctx *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	out, err := h.inner.Execute(ctx, node, pctx)
+	if len(out.ContextUpdates) > 0 {
+		out.ContextUpdates = h.r.StringMap(out.ContextUpdates)
+	}
+	return out, h.r.Error(err)
+}
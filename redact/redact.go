@@ -0,0 +1,167 @@
+// ABOUTME: Secret redaction for event streams, persisted state, and logs.
+// ABOUTME: A Redactor masks regex matches (common API key formats) and known secret values from the environment.
+package redact
+
+import (
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Mask replaces every redacted secret.
+const Mask = "[REDACTED]"
+
+// DefaultPatterns match common credential formats: OpenAI/Anthropic style
+// "sk-" keys, GitHub tokens, AWS access key IDs, Google API keys, and Slack
+// tokens.
+var DefaultPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`sk-[A-Za-z0-9_-]{16,}`),
+	regexp.MustCompile(`gh[pousr]_[A-Za-z0-9]{20,}`),
+	regexp.MustCompile(`github_pat_[A-Za-z0-9_]{20,}`),
+	regexp.MustCompile(`AKIA[0-9A-Z]{16}`),
+	regexp.MustCompile(`AIza[0-9A-Za-z_-]{35}`),
+	regexp.MustCompile(`xox[abprs]-[A-Za-z0-9-]{10,}`),
+}
+
+// secretEnvSuffixes identify environment variables whose values are secrets.
+var secretEnvSuffixes = []string{"_KEY", "_TOKEN", "_SECRET", "_PASSWORD"}
+
+// minSecretLen is the shortest literal value that is redacted; shorter
+// values would mask ordinary words.
+const minSecretLen = 8
+
+// Redactor masks secrets in strings. A nil *Redactor is valid and leaves
+// input unchanged.
+type Redactor struct {
+	patterns []*regexp.Regexp
+	values   []string
+}
+
+// New returns a Redactor that masks matches of patterns and every
+// occurrence of the given literal values. Values shorter than eight
+// characters are ignored.
+func New(patterns []*regexp.Regexp, values []string) *Redactor {
+	r := &Redactor{patterns: patterns}
+	for _, v := range values {
+		if len(v) >= minSecretLen {
+			r.values = append(r.values, v)
+		}
+	}
+	// Longest first so a secret containing another is masked whole.
+	sort.Slice(r.values, func(i, j int) bool { return len(r.values[i]) > len(r.values[j]) })
+	return r
+}
+
+// Default returns a Redactor using DefaultPatterns plus the values of
+// secret-looking environment variables (names ending in _KEY, _TOKEN,
+// _SECRET, or _PASSWORD).
+func Default() *Redactor {
+	return New(DefaultPatterns, SecretEnvValues(os.Environ()))
+}
+
+// SecretEnvValues returns the values of secret-looking variables in env,
+// given as KEY=value pairs.
+func SecretEnvValues(env []string) []string {
+	var values []string
+	for _, kv := range env {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || value == "" {
+			continue
+		}
+		upper := strings.ToUpper(name)
+		for _, suffix := range secretEnvSuffixes {
+			if strings.HasSuffix(upper, suffix) {
+				values = append(values, value)
+				break
+			}
+		}
+	}
+	return values
+}
+
+// String returns s with every secret replaced by Mask.
+func (r *Redactor) String(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	for _, v := range r.values {
+		s = strings.ReplaceAll(s, v, Mask)
+	}
+	for _, p := range r.patterns {
+		s = p.ReplaceAllString(s, Mask)
+	}
+	return s
+}
+
+// Map returns a copy of m with every string value redacted, descending into
+// nested maps and slices. Non-string values are copied as-is.
+func (r *Redactor) Map(m map[string]any) map[string]any {
+	if r == nil || m == nil {
+		return m
+	}
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = r.value(v)
+	}
+	return out
+}
+
+// StringMap returns a copy of m with every value redacted, such as a
+// pipeline context.
+func (r *Redactor) StringMap(m map[string]string) map[string]string {
+	if r == nil || m == nil {
+		return m
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = r.String(v)
+	}
+	return out
+}
+
+func (r *Redactor) value(v any) any {
+	switch t := v.(type) {
+	case string:
+		return r.String(t)
+	case map[string]any:
+		return r.Map(t)
+	case []any:
+		out := make([]any, len(t))
+		for i, e := range t {
+			out[i] = r.value(e)
+		}
+		return out
+	case []string:
+		out := make([]string, len(t))
+		for i, e := range t {
+			out[i] = r.String(e)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// Error returns err with its message redacted. The original error remains
+// reachable through errors.Unwrap, so errors.Is and errors.As still work.
+// Returns err unchanged when its message contains no secrets.
+func (r *Redactor) Error(err error) error {
+	if r == nil || err == nil {
+		return err
+	}
+	msg := err.Error()
+	if redacted := r.String(msg); redacted != msg {
+		return &redactedError{msg: redacted, err: err}
+	}
+	return err
+}
+
+// redactedError carries a redacted message in place of the wrapped error's.
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
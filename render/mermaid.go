@@ -0,0 +1,169 @@
+// ABOUTME: Converts DOT Graph structures to Mermaid flowchart text for docs and graphviz-free viewers.
+// ABOUTME: Provides ToMermaid and ToMermaidWithStatus (fill colors by execution outcome).
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/2389-research/mammoth/dot"
+)
+
+// Mermaid class definitions for the node kinds that get distinct styling.
+const (
+	mermaidClassStart    = "start"
+	mermaidClassTerminal = "terminal"
+	mermaidClassGoalGate = "goalGate"
+)
+
+var mermaidClassDefs = []string{
+	"classDef " + mermaidClassStart + " fill:#E3F2FD,stroke:#1565C0,stroke-width:2px",
+	"classDef " + mermaidClassTerminal + " fill:#ECEFF1,stroke:#37474F,stroke-width:2px",
+	"classDef " + mermaidClassGoalGate + " stroke:#FF9800,stroke-width:3px",
+}
+
+// ToMermaid serializes a Graph into a Mermaid flowchart. Node labels come
+// from the label attribute (falling back to the ID); edge labels from the
+// label attribute, falling back to the condition. Start, terminal, and
+// goal-gate nodes get distinct shapes and classes. Node order is
+// deterministic (sorted by ID).
+func ToMermaid(g *dot.Graph) string {
+	return toMermaid(g, nil)
+}
+
+// ToMermaidWithStatus serializes a Graph to a Mermaid flowchart with each
+// node filled by its execution outcome, using the same colors as
+// ToDOTWithStatus.
+func ToMermaidWithStatus(g *dot.Graph, outcomes map[string]*Outcome) string {
+	if outcomes == nil {
+		outcomes = map[string]*Outcome{}
+	}
+	return toMermaid(g, outcomes)
+}
+
+// toMermaid writes the flowchart; a non-nil outcomes map adds status fills.
+func toMermaid(g *dot.Graph, outcomes map[string]*Outcome) string {
+	if g == nil {
+		return ""
+	}
+
+	direction := "TD"
+	if rd := strings.ToUpper(g.Attrs["rankdir"]); rd == "LR" || rd == "RL" || rd == "BT" {
+		direction = rd
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "flowchart %s\n", direction)
+	for _, def := range mermaidClassDefs {
+		fmt.Fprintf(&buf, "  %s\n", def)
+	}
+
+	nodeIDs := g.NodeIDs()
+	ids := mermaidIDs(nodeIDs)
+	shapes := g.ShapeMapping()
+	for _, id := range nodeIDs {
+		node := g.Nodes[id]
+		open, closing := mermaidShape(shapes, node)
+		fmt.Fprintf(&buf, "  %s%s\"%s\"%s\n", ids[id], open, mermaidEscape(nodeLabelText(node)), closing)
+		if class := mermaidClass(shapes, node); class != "" {
+			fmt.Fprintf(&buf, "  class %s %s\n", ids[id], class)
+		}
+	}
+
+	for _, edge := range g.Edges {
+		from, to := mermaidNodeRef(ids, edge.From), mermaidNodeRef(ids, edge.To)
+		label := edge.Attrs["label"]
+		if label == "" {
+			label = edge.Attrs["condition"]
+		}
+		if label == "" {
+			fmt.Fprintf(&buf, "  %s --> %s\n", from, to)
+			continue
+		}
+		fmt.Fprintf(&buf, "  %s -->|\"%s\"| %s\n", from, mermaidEscape(label), to)
+	}
+
+	if outcomes != nil {
+		for _, id := range nodeIDs {
+			color := statusAttrsForNode(id, outcomes)["fillcolor"]
+			fmt.Fprintf(&buf, "  style %s fill:%s\n", ids[id], color)
+		}
+	}
+
+	return buf.String()
+}
+
+// mermaidShape returns the opening and closing delimiters for a node's shape.
+func mermaidShape(shapes dot.ShapeMapping, node *dot.Node) (string, string) {
+	switch shapes.NodeType(node) {
+	case "start":
+		return "([", "])"
+	case "exit":
+		return "[[", "]]"
+	case "conditional":
+		return "{", "}"
+	case "wait.human":
+		return "{{", "}}"
+	case "tool":
+		return "[/", "/]"
+	default:
+		return "[", "]"
+	}
+}
+
+// mermaidClass returns the class name for nodes with distinct styling, or "".
+func mermaidClass(shapes dot.ShapeMapping, node *dot.Node) string {
+	switch {
+	case shapes.NodeType(node) == "start":
+		return mermaidClassStart
+	case shapes.NodeType(node) == "exit":
+		return mermaidClassTerminal
+	case node.Attrs["goal_gate"] == "true":
+		return mermaidClassGoalGate
+	default:
+		return ""
+	}
+}
+
+// mermaidIDs maps DOT node IDs to Mermaid-safe identifiers. Bare identifiers
+// are kept as-is; other characters become "_", and Mermaid's reserved "end"
+// gets a suffix.
+func mermaidIDs(nodeIDs []string) map[string]string {
+	ids := make(map[string]string, len(nodeIDs))
+	for _, id := range nodeIDs {
+		safe := []rune(id)
+		for i, c := range safe {
+			if !isIDChar(c) {
+				safe[i] = '_'
+			}
+		}
+		s := string(safe)
+		if strings.EqualFold(s, "end") {
+			s += "_"
+		}
+		ids[id] = s
+	}
+	return ids
+}
+
+// mermaidNodeRef returns the Mermaid identifier for an edge endpoint, falling
+// back to the raw ID for edges that reference undeclared nodes.
+func mermaidNodeRef(ids map[string]string, id string) string {
+	if ref, ok := ids[id]; ok {
+		return ref
+	}
+	return mermaidIDs([]string{id})[id]
+}
+
+// mermaidEscape makes text safe inside a quoted Mermaid label.
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "\n", " ", `\n`, " ").Replace(s)
+}
+
+// nodeLabelText returns a node's label attribute, falling back to its ID.
+func nodeLabelText(node *dot.Node) string {
+	if label := node.Attrs["label"]; label != "" {
+		return label
+	}
+	return node.ID
+}
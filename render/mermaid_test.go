@@ -0,0 +1,108 @@
+// ABOUTME: Tests for Mermaid flowchart export covering node IDs, labels, conditional edges, and status fills.
+// ABOUTME: Validates ToMermaid, ToMermaidWithStatus, and Render's "mermaid" format.
+package render
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/2389-research/mammoth/dot"
+)
+
+// buildBranchingGraph constructs a graph with a conditional branch and a goal gate.
+func buildBranchingGraph() *dot.Graph {
+	return &dot.Graph{
+		Name: "branching",
+		Nodes: map[string]*dot.Node{
+			"start":     {ID: "start", Attrs: map[string]string{"shape": "Mdiamond"}},
+			"implement": {ID: "implement", Attrs: map[string]string{"shape": "box", "label": "Implement \"it\"", "goal_gate": "true"}},
+			"check":     {ID: "check", Attrs: map[string]string{"shape": "diamond"}},
+			"end":       {ID: "end", Attrs: map[string]string{"shape": "Msquare"}},
+		},
+		Edges: []*dot.Edge{
+			{From: "start", To: "implement", Attrs: map[string]string{}},
+			{From: "implement", To: "check", Attrs: map[string]string{}},
+			{From: "check", To: "end", Attrs: map[string]string{"condition": "outcome=success"}},
+			{From: "check", To: "implement", Attrs: map[string]string{"label": "retry", "condition": "outcome=fail"}},
+		},
+		Attrs: map[string]string{},
+	}
+}
+
+func TestToMermaid_NodesAndConditionalEdges(t *testing.T) {
+	out := ToMermaid(buildBranchingGraph())
+
+	for _, want := range []string{
+		"flowchart TD",
+		`start(["start"])`,
+		`implement["Implement #quot;it#quot;"]`,
+		`check{"check"}`,
+		`end_[["end"]]`,
+		`check -->|"outcome=success"| end_`,
+		`check -->|"retry"| implement`,
+		"start --> implement",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestToMermaid_StylesStartTerminalAndGoalGate(t *testing.T) {
+	out := ToMermaid(buildBranchingGraph())
+
+	for _, want := range []string{
+		"class start start",
+		"class end_ terminal",
+		"class implement goalGate",
+		"classDef goalGate",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "style ") {
+		t.Errorf("plain export should not include status fills:\n%s", out)
+	}
+}
+
+func TestToMermaid_RankdirAndNil(t *testing.T) {
+	g := buildTestGraph()
+	if out := ToMermaid(g); !strings.HasPrefix(out, "flowchart LR\n") {
+		t.Errorf("expected rankdir=LR to set direction, got:\n%s", out)
+	}
+	if ToMermaid(nil) != "" {
+		t.Error("expected empty output for nil graph")
+	}
+}
+
+func TestToMermaidWithStatus_ColorsByOutcome(t *testing.T) {
+	outcomes := map[string]*Outcome{
+		"start":     {Status: StatusSuccess},
+		"implement": {Status: StatusFail},
+		"check":     {Status: StatusRetry},
+	}
+	out := ToMermaidWithStatus(buildBranchingGraph(), outcomes)
+
+	for _, want := range []string{
+		"style start fill:" + StatusColorSuccess,
+		"style implement fill:" + StatusColorFailed,
+		"style check fill:" + StatusColorRunning,
+		"style end_ fill:" + StatusColorPending,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestRender_MermaidFormat(t *testing.T) {
+	out, err := Render(context.Background(), buildBranchingGraph(), "mermaid")
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if !strings.HasPrefix(string(out), "flowchart ") {
+		t.Errorf("expected Mermaid flowchart, got:\n%s", out)
+	}
+}
@@ -1,5 +1,5 @@
 // ABOUTME: Converts DOT Graph structures to DOT text and renders to SVG/PNG via graphviz.
-// ABOUTME: Provides ToDOT, ToDOTWithStatus (with execution status color overlay), and Render functions.
+// ABOUTME: Provides ToDOT, ToDOTWithStatus (with execution status color overlay and optional legend), and Render functions.
 package render
 
 import (
@@ -37,8 +37,28 @@ const (
 	StatusColorPending = "#9E9E9E" // gray
 )
 
+// statusFontColors gives each status fill a readable label color, so a
+// rendered graph does not depend on a viewer's stylesheet.
+var statusFontColors = map[string]string{
+	StatusColorSuccess: "white",
+	StatusColorFailed:  "white",
+	StatusColorRunning: "black",
+	StatusColorPending: "black",
+}
+
+// legendEntries are the rows of the status legend, in display order.
+var legendEntries = []struct {
+	id, label, color string
+}{
+	{"success", "succeeded", StatusColorSuccess},
+	{"fail", "failed", StatusColorFailed},
+	{"running", "retrying", StatusColorRunning},
+	{"pending", "pending / skipped", StatusColorPending},
+}
+
 // ToDOT serializes a Graph back into valid DOT digraph text.
 // Node order is deterministic (sorted by ID) for reproducible output.
+// Conditional edges are labeled with their condition.
 func ToDOT(g *dot.Graph) string {
 	if g == nil {
 		return ""
@@ -75,7 +95,7 @@ func ToDOT(g *dot.Graph) string {
 
 	// Edges
 	for _, edge := range g.Edges {
-		writeEdge(&buf, edge)
+		writeEdge(&buf, edge, nil)
 	}
 
 	buf.WriteString("}\n")
@@ -85,7 +105,21 @@ func ToDOT(g *dot.Graph) string {
 // ToDOTWithStatus serializes a Graph to DOT text with color overlays based on execution status.
 // Nodes with outcomes are colored: green for success/partial_success, red for fail,
 // yellow for retry (running), and gray for pending (no outcome or skipped).
+// Edges out of a finished node are drawn bold green when their target ran
+// (the branch taken) and dimmed when it did not.
 func ToDOTWithStatus(g *dot.Graph, outcomes map[string]*Outcome) string {
+	return toDOTWithStatus(g, outcomes, false)
+}
+
+// ToDOTWithStatusLegend is ToDOTWithStatus plus a legend cluster explaining
+// the status colors, so a rendered SVG or PNG reads on its own. Small graphs
+// may prefer ToDOTWithStatus to keep the picture uncluttered.
+func ToDOTWithStatusLegend(g *dot.Graph, outcomes map[string]*Outcome) string {
+	return toDOTWithStatus(g, outcomes, true)
+}
+
+// toDOTWithStatus writes the status-colored graph, with a legend when asked.
+func toDOTWithStatus(g *dot.Graph, outcomes map[string]*Outcome, legend bool) string {
 	if g == nil {
 		return ""
 	}
@@ -124,17 +158,65 @@ func ToDOTWithStatus(g *dot.Graph, outcomes map[string]*Outcome) string {
 		writeNode(&buf, node, statusAttrs)
 	}
 
-	// Edges
+	// Edges styled by whether the run took them
 	for _, edge := range g.Edges {
-		writeEdge(&buf, edge)
+		writeEdge(&buf, edge, statusAttrsForEdge(edge, outcomes))
+	}
+
+	if legend {
+		writeLegend(&buf, g)
 	}
 
 	buf.WriteString("}\n")
 	return buf.String()
 }
 
+// writeLegend writes a cluster with one filled swatch per status color,
+// chained by invisible edges so the swatches stack in order. Its styles are
+// all explicit, so graph and node defaults do not restyle it.
+func writeLegend(buf *strings.Builder, g *dot.Graph) {
+	prefix := "legend_"
+	for {
+		clash := false
+		for _, e := range legendEntries {
+			if _, ok := g.Nodes[prefix+e.id]; ok {
+				clash = true
+			}
+		}
+		if !clash {
+			break
+		}
+		prefix = "_" + prefix
+	}
+
+	buf.WriteString("  subgraph cluster_legend {\n")
+	writeAttrsBlock(buf, map[string]string{
+		"label":    "Legend",
+		"fontname": "Helvetica",
+		"fontsize": "12",
+		"style":    "rounded",
+		"color":    StatusColorPending,
+	}, "  ")
+	for _, e := range legendEntries {
+		fmt.Fprintf(buf, "    %s [%s]\n", quoteID(prefix+e.id), formatAttrs(map[string]string{
+			"label":     e.label,
+			"shape":     "box",
+			"style":     "filled,rounded",
+			"fillcolor": e.color,
+			"fontcolor": statusFontColors[e.color],
+			"fontname":  "Helvetica",
+			"fontsize":  "10",
+		}))
+	}
+	for i := 1; i < len(legendEntries); i++ {
+		fmt.Fprintf(buf, "    %s -> %s [style=\"invis\"]\n", quoteID(prefix+legendEntries[i-1].id), quoteID(prefix+legendEntries[i].id))
+	}
+	buf.WriteString("  }\n")
+}
+
 // Render produces rendered output from a Graph in the specified format.
-// Supported formats: "dot" (returns DOT text), "svg", "png" (shell out to graphviz dot command).
+// Supported formats: "dot" (returns DOT text), "mermaid" (returns a Mermaid flowchart),
+// "svg", "png" (shell out to graphviz dot command).
 // Returns an error if the format is unsupported or graphviz is not installed for svg/png.
 func Render(ctx context.Context, g *dot.Graph, format string) ([]byte, error) {
 	if g == nil {
@@ -144,10 +226,12 @@ func Render(ctx context.Context, g *dot.Graph, format string) ([]byte, error) {
 	switch format {
 	case "dot":
 		return []byte(ToDOT(g)), nil
+	case "mermaid":
+		return []byte(ToMermaid(g)), nil
 	case "svg", "png":
 		return renderWithGraphviz(ctx, g, format)
 	default:
-		return nil, fmt.Errorf("unsupported format %q: supported formats are dot, svg, png", format)
+		return nil, fmt.Errorf("unsupported format %q: supported formats are dot, mermaid, svg, png", format)
 	}
 }
 
@@ -211,7 +295,7 @@ func renderDOTSourceWithGraphviz(ctx context.Context, dotText string, format str
 	return stdout.Bytes(), nil
 }
 
-// statusAttrsForNode returns fill color and style attributes based on the node's execution outcome.
+// statusAttrsForNode returns fill, font color, and style attributes based on the node's execution outcome.
 func statusAttrsForNode(nodeID string, outcomes map[string]*Outcome) map[string]string {
 	color := StatusColorPending
 
@@ -233,6 +317,52 @@ func statusAttrsForNode(nodeID string, outcomes map[string]*Outcome) map[string]
 	return map[string]string{
 		"style":     "filled",
 		"fillcolor": color,
+		"fontcolor": statusFontColors[color],
+	}
+}
+
+// statusAttrsForEdge styles an edge by whether the run traversed it. Outcomes
+// only record which nodes ran, so an edge counts as taken when its source
+// finished and its target has an outcome, and as not taken when its source
+// finished but its target never ran. Edges out of nodes that have not
+// finished are left unstyled.
+func statusAttrsForEdge(edge *dot.Edge, outcomes map[string]*Outcome) map[string]string {
+	from, ok := outcomes[edge.From]
+	if !ok {
+		return nil
+	}
+	switch from.Status {
+	case StatusSuccess, StatusPartialSuccess, StatusFail:
+	default:
+		return nil
+	}
+
+	if _, ran := outcomes[edge.To]; ran {
+		return map[string]string{
+			"color":    StatusColorSuccess,
+			"penwidth": "2",
+			"style":    "bold",
+		}
+	}
+	return map[string]string{
+		"color":     StatusColorPending,
+		"fontcolor": StatusColorPending,
+		"style":     "dashed",
+	}
+}
+
+// conditionLabel returns the attributes that show an edge's condition: its
+// label when the edge has none, otherwise an xlabel beside the edge's own
+// label, which the engine may match against outcomes and is kept as is.
+func conditionLabel(attrs map[string]string) map[string]string {
+	cond := strings.TrimSpace(attrs["condition"])
+	switch label := attrs["label"]; {
+	case cond == "" || cond == label:
+		return nil
+	case label == "":
+		return map[string]string{"label": cond}
+	default:
+		return map[string]string{"xlabel": cond}
 	}
 }
 
@@ -255,14 +385,26 @@ func writeNode(buf *strings.Builder, node *dot.Node, extraAttrs map[string]strin
 	fmt.Fprintf(buf, "  %s [%s]\n", quoteID(node.ID), formatAttrs(merged))
 }
 
-// writeEdge writes an edge declaration to the buffer.
-func writeEdge(buf *strings.Builder, edge *dot.Edge) {
-	if len(edge.Attrs) == 0 {
+// writeEdge writes an edge declaration to the buffer, labeling conditional
+// edges and merging any extra attributes (e.g. traversal styling).
+func writeEdge(buf *strings.Builder, edge *dot.Edge, extraAttrs map[string]string) {
+	merged := make(map[string]string)
+	for k, v := range edge.Attrs {
+		merged[k] = v
+	}
+	for k, v := range conditionLabel(edge.Attrs) {
+		merged[k] = v
+	}
+	for k, v := range extraAttrs {
+		merged[k] = v
+	}
+
+	if len(merged) == 0 {
 		fmt.Fprintf(buf, "  %s -> %s\n", quoteID(edge.From), quoteID(edge.To))
 		return
 	}
 
-	fmt.Fprintf(buf, "  %s -> %s [%s]\n", quoteID(edge.From), quoteID(edge.To), formatAttrs(edge.Attrs))
+	fmt.Fprintf(buf, "  %s -> %s [%s]\n", quoteID(edge.From), quoteID(edge.To), formatAttrs(merged))
 }
 
 // writeSubgraph writes a subgraph block to the buffer.
@@ -296,6 +296,63 @@ func TestToDOTWithStatus_PreservesOriginalAttributes(t *testing.T) {
 	}
 }
 
+func TestToDOTWithStatus_SetsReadableFontColor(t *testing.T) {
+	g := buildTestGraph()
+	dot := ToDOTWithStatus(g, map[string]*Outcome{"work": {Status: StatusFail}})
+
+	for _, line := range strings.Split(dot, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "work [") && !strings.Contains(line, `fontcolor="white"`) {
+			t.Errorf("expected white label on the red work node: %s", line)
+		}
+	}
+	if strings.Contains(dot, "cluster_legend") {
+		t.Errorf("ToDOTWithStatus should not add a legend:\n%s", dot)
+	}
+}
+
+func TestToDOTWithStatusLegend_IncludesLegend(t *testing.T) {
+	g := buildTestGraph()
+	dot := ToDOTWithStatusLegend(g, map[string]*Outcome{"work": {Status: StatusSuccess}})
+
+	if !strings.Contains(dot, "subgraph cluster_legend {") || !strings.Contains(dot, `label="Legend"`) {
+		t.Fatalf("expected a legend cluster:\n%s", dot)
+	}
+	for _, want := range []struct{ id, color string }{
+		{"legend_success", StatusColorSuccess},
+		{"legend_fail", StatusColorFailed},
+		{"legend_running", StatusColorRunning},
+		{"legend_pending", StatusColorPending},
+	} {
+		found := false
+		for _, line := range strings.Split(dot, "\n") {
+			if strings.HasPrefix(strings.TrimSpace(line), want.id+" [") {
+				found = strings.Contains(line, `fillcolor="`+want.color+`"`) &&
+					strings.Contains(line, `style="filled,rounded"`) &&
+					strings.Contains(line, "fontcolor=")
+			}
+		}
+		if !found {
+			t.Errorf("expected legend node %s filled with %s:\n%s", want.id, want.color, dot)
+		}
+	}
+	if !strings.Contains(dot, `legend_success -> legend_fail [style="invis"]`) {
+		t.Errorf("expected invisible edges ordering the legend:\n%s", dot)
+	}
+	if !strings.HasSuffix(dot, "  }\n}\n") {
+		t.Errorf("expected the legend to close the graph:\n%s", dot)
+	}
+}
+
+func TestToDOTWithStatusLegend_AvoidsNodeIDClash(t *testing.T) {
+	g := buildMinimalGraph()
+	g.Nodes["legend_fail"] = &dot.Node{ID: "legend_fail", Attrs: map[string]string{}}
+	out := ToDOTWithStatusLegend(g, nil)
+
+	if !strings.Contains(out, "\n  legend_fail [") || !strings.Contains(out, "\n    _legend_fail [") {
+		t.Errorf("expected legend node IDs to move aside for the graph's legend_fail:\n%s", out)
+	}
+}
+
 func TestRender_DOTFormat(t *testing.T) {
 	g := buildTestGraph()
 	data, err := Render(context.Background(), g, "dot")
@@ -578,3 +635,84 @@ func min(a, b int) int {
 	}
 	return b
 }
+
+// buildBranchGraph constructs a graph with a conditional branch after work.
+func buildBranchGraph() *dot.Graph {
+	return &dot.Graph{
+		Name: "branch",
+		Nodes: map[string]*dot.Node{
+			"start": {ID: "start", Attrs: map[string]string{"shape": "Mdiamond"}},
+			"work":  {ID: "work", Attrs: map[string]string{"shape": "box"}},
+			"ship":  {ID: "ship", Attrs: map[string]string{"shape": "box"}},
+			"fix":   {ID: "fix", Attrs: map[string]string{"shape": "box"}},
+			"done":  {ID: "done", Attrs: map[string]string{"shape": "Msquare"}},
+		},
+		Edges: []*dot.Edge{
+			{From: "start", To: "work", Attrs: map[string]string{}},
+			{From: "work", To: "ship", Attrs: map[string]string{"condition": "outcome=success"}},
+			{From: "work", To: "fix", Attrs: map[string]string{"condition": "outcome=fail", "label": "Fix"}},
+			{From: "ship", To: "done", Attrs: map[string]string{}},
+			{From: "fix", To: "done", Attrs: map[string]string{}},
+		},
+		Attrs:        map[string]string{},
+		NodeDefaults: map[string]string{},
+		EdgeDefaults: map[string]string{},
+	}
+}
+
+// edgeLine returns the DOT line declaring the edge from -> to.
+func edgeLine(t *testing.T, dotText, from, to string) string {
+	t.Helper()
+	for _, line := range strings.Split(dotText, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), from+" -> "+to) {
+			return line
+		}
+	}
+	t.Fatalf("no edge %s -> %s in:\n%s", from, to, dotText)
+	return ""
+}
+
+func TestToDOT_LabelsConditionalEdges(t *testing.T) {
+	out := ToDOT(buildBranchGraph())
+
+	if line := edgeLine(t, out, "work", "ship"); !strings.Contains(line, `label="outcome=success"`) {
+		t.Errorf("conditional edge without a label should be labeled with its condition: %s", line)
+	}
+	line := edgeLine(t, out, "work", "fix")
+	if !strings.Contains(line, `label="Fix"`) || !strings.Contains(line, `xlabel="outcome=fail"`) {
+		t.Errorf("labeled conditional edge should keep its label and show the condition as xlabel: %s", line)
+	}
+	if line := edgeLine(t, out, "start", "work"); strings.Contains(line, "label") {
+		t.Errorf("unconditional edge should stay unlabeled: %s", line)
+	}
+	if _, err := dot.Parse(out); err != nil {
+		t.Fatalf("labeled output does not parse: %v\n%s", err, out)
+	}
+}
+
+func TestToDOTWithStatus_StylesTraversedEdges(t *testing.T) {
+	out := ToDOTWithStatus(buildBranchGraph(), map[string]*Outcome{
+		"start": {Status: StatusSuccess},
+		"work":  {Status: StatusSuccess},
+		"ship":  {Status: StatusRetry},
+	})
+
+	taken := edgeLine(t, out, "work", "ship")
+	if !strings.Contains(taken, `color="`+StatusColorSuccess+`"`) || !strings.Contains(taken, `style="bold"`) {
+		t.Errorf("taken edge should be bold green: %s", taken)
+	}
+	if !strings.Contains(taken, `label="outcome=success"`) {
+		t.Errorf("taken edge lost its condition label: %s", taken)
+	}
+	notTaken := edgeLine(t, out, "work", "fix")
+	if !strings.Contains(notTaken, `color="`+StatusColorPending+`"`) || !strings.Contains(notTaken, `style="dashed"`) {
+		t.Errorf("edge not taken should be dimmed: %s", notTaken)
+	}
+	if taken == notTaken {
+		t.Error("taken and not-taken edges are styled the same")
+	}
+	// ship is still running, so its outgoing edge has no verdict yet.
+	if pending := edgeLine(t, out, "ship", "done"); strings.Contains(pending, "color") {
+		t.Errorf("edge out of an unfinished node should be unstyled: %s", pending)
+	}
+}
@@ -0,0 +1,167 @@
+// ABOUTME: Per-node retry backoff: retry_backoff, retry_base, and retry_max attributes build a node-specific RetryPolicy.
+// ABOUTME: Unset attributes fall back to the -retry policy; a failed attempt is retried after the policy's delay, emitting stage_retrying.
+package retrybackoff
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/2389-research/mammoth/llm"
+	"github.com/2389-research/mammoth/redact"
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// retryableHandlers lists the handler names whose nodes may set a retry
+// backoff: the ones that call out to an LLM or a shell and fail transiently.
+var retryableHandlers = []string{"codergen", "tool"}
+
+// retryMaxDelay caps the delay of every named policy.
+const retryMaxDelay = 60 * time.Second
+
+// Policies are the named policies selectable with the retry_backoff
+// attribute and as a runner's default policy, such as the CLI's -retry flag.
+// MaxRetries counts retries after the first attempt.
+var Policies = map[string]llm.RetryPolicy{
+	"none":       {MaxRetries: 0, BaseDelay: 200 * time.Millisecond, MaxDelay: retryMaxDelay, BackoffMultiplier: 2},
+	"standard":   {MaxRetries: 4, BaseDelay: 200 * time.Millisecond, MaxDelay: retryMaxDelay, BackoffMultiplier: 2, Jitter: true},
+	"aggressive": {MaxRetries: 4, BaseDelay: 500 * time.Millisecond, MaxDelay: retryMaxDelay, BackoffMultiplier: 2, Jitter: true},
+	"linear":     {MaxRetries: 2, BaseDelay: 500 * time.Millisecond, MaxDelay: retryMaxDelay, BackoffMultiplier: 1},
+	"patient":    {MaxRetries: 2, BaseDelay: 2 * time.Second, MaxDelay: retryMaxDelay, BackoffMultiplier: 3, Jitter: true},
+}
+
+// hasBackoff reports whether the node overrides any part of the
+// default retry policy.
+func hasBackoff(node *pipeline.Node) bool {
+	return node.Attrs["retry_backoff"] != "" || node.Attrs["retry_base"] != "" || node.Attrs["retry_max"] != ""
+}
+
+// nodePolicy builds the retry policy for a node: the policy named by its
+// retry_backoff attribute (else defaultPolicy), with retry_base and retry_max
+// replacing the base and maximum delays when set.
+func nodePolicy(node *pipeline.Node, defaultPolicy string) (llm.RetryPolicy, error) {
+	name := strings.ToLower(strings.TrimSpace(node.Attrs["retry_backoff"]))
+	if name == "" {
+		name = defaultPolicy
+	}
+	if name == "" {
+		name = "none"
+	}
+	policy, ok := Policies[name]
+	if !ok {
+		return llm.RetryPolicy{}, fmt.Errorf("node %q: unknown retry_backoff %q (want none, standard, aggressive, linear, or patient)", node.ID, name)
+	}
+	for attr, dst := range map[string]*time.Duration{"retry_base": &policy.BaseDelay, "retry_max": &policy.MaxDelay} {
+		raw := strings.TrimSpace(node.Attrs[attr])
+		if raw == "" {
+			continue
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil || d <= 0 {
+			return llm.RetryPolicy{}, fmt.Errorf("node %q: %s must be a positive duration, got %q", node.ID, attr, raw)
+		}
+		*dst = d
+	}
+	if policy.MaxRetries == 0 && (node.Attrs["retry_base"] != "" || node.Attrs["retry_max"] != "") {
+		return llm.RetryPolicy{}, fmt.Errorf("node %q: retry_base and retry_max need a policy that retries, but %q never does (set retry_backoff)", node.ID, name)
+	}
+	if policy.MaxDelay < policy.BaseDelay {
+		return llm.RetryPolicy{}, fmt.Errorf("node %q: retry_max %s is shorter than retry_base %s", node.ID, policy.MaxDelay, policy.BaseDelay)
+	}
+	return policy, nil
+}
+
+// Hook wraps the retryable handlers so nodes with retry attributes are
+// retried under their own policy, falling back to defaultPolicy for the
+// parts they leave unset. Each retry is reported to events (secrets masked)
+// as the engine reports its own: stage_retrying, then stage_started for the
+// next attempt.
+func Hook(defaultPolicy string, events pipeline.PipelineEventHandler) func(*pipeline.HandlerRegistry) {
+	return hook(defaultPolicy, events, sleepContext)
+}
+
+// hook is Hook with sleep waiting between attempts.
+func hook(defaultPolicy string, events pipeline.PipelineEventHandler, sleep func(context.Context, time.Duration) error) func(*pipeline.HandlerRegistry) {
+	events = redact.PipelineHandler(redact.Default(), events)
+	return func(registry *pipeline.HandlerRegistry) {
+		for _, name := range retryableHandlers {
+			if inner := registry.Get(name); inner != nil {
+				registry.Register(&backoffHandler{inner: inner, defaultPolicy: defaultPolicy, events: events, sleep: sleep})
+			}
+		}
+	}
+}
+
+// backoffHandler re-runs a failed node, waiting out the node's backoff
+// between attempts, until an attempt succeeds or its retries run out. Nodes
+// without retry attributes run once, as before. The node's retries replace
+// the engine's: an attempt still asking for a retry once they run out fails
+// the node instead of starting another round under the engine's policy.
+type backoffHandler struct {
+	inner         pipeline.Handler
+	defaultPolicy string
+	events        pipeline.PipelineEventHandler
+	sleep         func(context.Context, time.Duration) error
+}
+
+func (h *backoffHandler) Name() string { return h.inner.Name() }
+
+func (h *backoffHandler) Execute(ctx context.Context, node *pipeline.Node, pctx *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	if !hasBackoff(node) {
+		return h.inner.Execute(ctx, node, pctx)
+	}
+	policy, err := nodePolicy(node, h.defaultPolicy)
+	if err != nil {
+		return pipeline.Outcome{}, err
+	}
+	for attempt := 0; ; attempt++ {
+		out, err := h.inner.Execute(ctx, node, pctx)
+		if !attemptFailed(out, err) || ctx.Err() != nil {
+			return out, err
+		}
+		if attempt >= policy.MaxRetries {
+			if out.Status == pipeline.OutcomeRetry {
+				out.Status = pipeline.OutcomeFail
+			}
+			return out, err
+		}
+		if sleepErr := h.sleep(ctx, policy.CalculateDelay(attempt)); sleepErr != nil {
+			return out, err
+		}
+		h.emit(pipeline.PipelineEvent{
+			Type:    pipeline.EventStageRetrying,
+			NodeID:  node.ID,
+			Message: fmt.Sprintf("retrying node %q (attempt %d/%d, retry_backoff)", node.ID, attempt+1, policy.MaxRetries),
+			Err:     err,
+		})
+		h.emit(pipeline.PipelineEvent{Type: pipeline.EventStageStarted, NodeID: node.ID})
+	}
+}
+
+// emit timestamps evt and sends it to the handler's events, if any.
+func (h *backoffHandler) emit(evt pipeline.PipelineEvent) {
+	if h.events == nil {
+		return
+	}
+	evt.Timestamp = time.Now()
+	h.events.HandlePipelineEvent(evt)
+}
+
+// attemptFailed reports whether a handler attempt should be retried.
+func attemptFailed(out pipeline.Outcome, err error) bool {
+	return err != nil || out.Status == pipeline.OutcomeFail || out.Status == "retry"
+}
+
+// sleepContext waits for d, returning early with the context's error if it
+// is cancelled first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
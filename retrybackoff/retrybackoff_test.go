@@ -0,0 +1,200 @@
+// ABOUTME: Tests for per-node retry backoff via the retry_backoff, retry_base, and retry_max attributes.
+// ABOUTME: Covers policy construction, longer delays for a patient node, the retry events it emits, and not stacking engine retries.
+package retrybackoff
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/2389-research/mammoth/runstate"
+	"github.com/2389-research/tracker/pipeline"
+	"github.com/2389-research/tracker/pipeline/handlers"
+)
+
+// flakyHandler is a codergen stand-in that fails its first `failures` calls,
+// with status if set and OutcomeFail otherwise.
+type flakyHandler struct {
+	mu       sync.Mutex
+	failures int
+	status   string
+	calls    int
+}
+
+func (h *flakyHandler) Name() string { return "codergen" }
+
+func (h *flakyHandler) Execute(context.Context, *pipeline.Node, *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.calls++
+	if h.calls <= h.failures {
+		if h.status != "" {
+			return pipeline.Outcome{Status: h.status}, nil
+		}
+		return pipeline.Outcome{Status: pipeline.OutcomeFail}, nil
+	}
+	return pipeline.Outcome{Status: pipeline.OutcomeSuccess}, nil
+}
+
+// runGraph runs source with backend registered and hook installed, sending
+// the engine's events to events when it is not nil.
+func runGraph(t *testing.T, source string, backend pipeline.Handler, events pipeline.PipelineEventHandler, hook func(*pipeline.HandlerRegistry)) {
+	t.Helper()
+	g, err := pipeline.ParseDOT(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := handlers.NewDefaultRegistry(g)
+	registry.Register(backend)
+	hook(registry)
+	opts := []pipeline.EngineOption{pipeline.WithArtifactDir(t.TempDir())}
+	if events != nil {
+		opts = append(opts, pipeline.WithPipelineEventHandler(events))
+	}
+	if _, err := pipeline.NewEngine(g, registry, opts...).Run(context.Background()); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+}
+
+// runWithBackoff runs source against a flaky backend under the default
+// policy, returning the backend's call count and the delays slept.
+func runWithBackoff(t *testing.T, source, defaultPolicy string, failures int) (int, []time.Duration) {
+	t.Helper()
+	backend := &flakyHandler{failures: failures}
+	var delays []time.Duration
+	sleep := func(_ context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	runGraph(t, source, backend, nil, hook(defaultPolicy, nil, sleep))
+	return backend.calls, delays
+}
+
+func TestRetryBackoffPatientNode(t *testing.T) {
+	const plain = `digraph r {
+		start [shape=Mdiamond]
+		call [shape=box, prompt="flaky"]
+		done [shape=Msquare]
+		start -> call -> done
+	}`
+	const patient = `digraph r {
+		start [shape=Mdiamond]
+		call [shape=box, prompt="flaky", retry_backoff="patient"]
+		done [shape=Msquare]
+		start -> call -> done
+	}`
+
+	calls, delays := runWithBackoff(t, plain, "none", 2)
+	if calls != 1 || len(delays) != 0 {
+		t.Errorf("default none: calls = %d, delays = %v; want one attempt and no waiting", calls, delays)
+	}
+
+	calls, delays = runWithBackoff(t, patient, "none", 2)
+	if calls != 3 || len(delays) != 2 {
+		t.Fatalf("patient: calls = %d, delays = %v; want 3 attempts with 2 waits", calls, delays)
+	}
+	for i, limit := range []time.Duration{2 * time.Second, 6 * time.Second} {
+		if delays[i] > limit {
+			t.Errorf("patient delay %d = %v, over its %v backoff", i, delays[i], limit)
+		}
+	}
+
+	policy, err := nodePolicy(&pipeline.Node{ID: "call", Attrs: map[string]string{"retry_backoff": "patient"}}, "none")
+	if err != nil {
+		t.Fatal(err)
+	}
+	none := Policies["none"]
+	if policy.BaseDelay <= none.BaseDelay || policy.BackoffMultiplier <= none.BackoffMultiplier || policy.MaxRetries <= none.MaxRetries {
+		t.Errorf("patient policy %+v should back off longer than none %+v", policy, none)
+	}
+}
+
+func TestNodePolicyOverrides(t *testing.T) {
+	node := &pipeline.Node{ID: "n", Attrs: map[string]string{"retry_backoff": "linear", "retry_base": "2s", "retry_max": "30s"}}
+	policy, err := nodePolicy(node, "none")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.BaseDelay != 2*time.Second || policy.MaxDelay != 30*time.Second || policy.BackoffMultiplier != 1 || policy.MaxRetries != 2 {
+		t.Errorf("policy = %+v, want linear with 2s base and 30s max", policy)
+	}
+	if got := policy.CalculateDelay(3); got != 2*time.Second {
+		t.Errorf("linear delay = %v, want constant 2s", got)
+	}
+
+	// Without retry_backoff the engine default supplies the shape.
+	policy, err = nodePolicy(&pipeline.Node{ID: "n", Attrs: map[string]string{"retry_base": "1s"}}, "aggressive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.MaxRetries != Policies["aggressive"].MaxRetries || policy.BaseDelay != time.Second {
+		t.Errorf("policy = %+v, want aggressive with a 1s base", policy)
+	}
+
+	for _, attrs := range []map[string]string{
+		{"retry_backoff": "eventually"},
+		{"retry_base": "soon"},
+		{"retry_max": "-1s"},
+		{"retry_base": "10s", "retry_max": "1s"},
+	} {
+		if _, err := nodePolicy(&pipeline.Node{ID: "n", Attrs: attrs}, "none"); err == nil {
+			t.Errorf("attrs %v: expected error", attrs)
+		}
+	}
+}
+
+func TestRetryBackoffRecordsEachAttempt(t *testing.T) {
+	const source = `digraph r {
+		start [shape=Mdiamond]
+		call [shape=box, prompt="flaky", retry_backoff="linear", retry_base="1ms"]
+		done [shape=Msquare]
+		start -> call -> done
+	}`
+	backend := &flakyHandler{failures: 2}
+	attempts := &runstate.AttemptLog{}
+	var retrying []string
+	events := pipeline.PipelineEventHandlerFunc(func(evt pipeline.PipelineEvent) {
+		attempts.Observe(evt)
+		if evt.Type == pipeline.EventStageRetrying {
+			retrying = append(retrying, evt.Message)
+		}
+	})
+	runGraph(t, source, backend, events, Hook("none", events))
+
+	if len(retrying) != 2 || !strings.Contains(retrying[1], "attempt 2/2") {
+		t.Errorf("stage_retrying messages = %q, want two, the last for attempt 2/2", retrying)
+	}
+	records := attempts.Records()["call"]
+	var statuses []string
+	for _, r := range records {
+		statuses = append(statuses, r.Status)
+	}
+	if want := "retry,retry,success"; strings.Join(statuses, ",") != want {
+		t.Errorf("call attempts = %v, want %s", statuses, want)
+	}
+}
+
+func TestRetryBackoffDoesNotStackEngineRetries(t *testing.T) {
+	// The engine's default policy retries a "retry" outcome on its own; the
+	// node's linear backoff owns its retries, so the backend runs 1+2 times.
+	const source = `digraph r {
+		start [shape=Mdiamond]
+		call [shape=box, prompt="flaky", retry_backoff="linear", retry_base="1ms"]
+		done [shape=Msquare]
+		start -> call -> done
+	}`
+	backend := &flakyHandler{failures: 100, status: pipeline.OutcomeRetry}
+	runGraph(t, source, backend, nil, Hook("none", nil))
+	if backend.calls != 3 {
+		t.Errorf("backend ran %d times, want 3", backend.calls)
+	}
+}
+
+func TestNodePolicyRejectsDelaysWithoutRetries(t *testing.T) {
+	_, err := nodePolicy(&pipeline.Node{ID: "n", Attrs: map[string]string{"retry_base": "1s"}}, "none")
+	if err == nil || !strings.Contains(err.Error(), "never does") {
+		t.Errorf("err = %v, want retry_base rejected under the none policy", err)
+	}
+}
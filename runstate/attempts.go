@@ -0,0 +1,111 @@
+// ABOUTME: Per-node attempt history: each execution of a node, with its status, reason, and duration.
+// ABOUTME: AttemptLog assembles the history from the engine's stage events (or direct start/finish calls) as a run progresses.
+package runstate
+
+import (
+	"sync"
+	"time"
+
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// AttemptRecord describes one execution of a node. Status is the stage
+// outcome ("success", "fail", or "retry"); Reason carries the failure or
+// retry message, if any.
+type AttemptRecord struct {
+	Status    string        `json:"status"`
+	Reason    string        `json:"reason,omitempty"`
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration"`
+}
+
+// AttemptLog accumulates attempt records per node. It is safe for
+// concurrent use; the zero value is ready to use.
+type AttemptLog struct {
+	mu       sync.Mutex
+	attempts map[string][]AttemptRecord
+	open     map[string]time.Time
+}
+
+// NewAttemptLog returns a log seeded with prior history, such as the attempts
+// recorded before a run was resumed.
+func NewAttemptLog(prior map[string][]AttemptRecord) *AttemptLog {
+	l := &AttemptLog{}
+	for node, records := range prior {
+		for _, r := range records {
+			l.add(node, r)
+		}
+	}
+	return l
+}
+
+// Start marks the beginning of an attempt at nodeID.
+func (l *AttemptLog) Start(nodeID string, at time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.open == nil {
+		l.open = make(map[string]time.Time)
+	}
+	l.open[nodeID] = at
+}
+
+// Finish closes the open attempt at nodeID with the given status and reason.
+// A finish with no open attempt is ignored: resumed runs replay completion
+// for nodes that did not execute again.
+func (l *AttemptLog) Finish(nodeID, status, reason string, at time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	started, ok := l.open[nodeID]
+	if !ok {
+		return
+	}
+	delete(l.open, nodeID)
+	l.add(nodeID, AttemptRecord{Status: status, Reason: reason, StartedAt: started, Duration: at.Sub(started)})
+}
+
+// Observe feeds a stage lifecycle event into the log. The engine emits a
+// fresh stage_started for every retry, so each execution becomes one record,
+// closed by the node's next completed, failed, or retrying event. A failure
+// or retry keeps the handler's error as its reason, else the event message.
+func (l *AttemptLog) Observe(evt pipeline.PipelineEvent) {
+	if evt.NodeID == "" {
+		return
+	}
+	reason := evt.Message
+	if evt.Err != nil {
+		reason = evt.Err.Error()
+	}
+	switch evt.Type {
+	case pipeline.EventStageStarted:
+		l.Start(evt.NodeID, evt.Timestamp)
+	case pipeline.EventStageCompleted:
+		l.Finish(evt.NodeID, pipeline.OutcomeSuccess, "", evt.Timestamp)
+	case pipeline.EventStageFailed:
+		l.Finish(evt.NodeID, pipeline.OutcomeFail, reason, evt.Timestamp)
+	case pipeline.EventStageRetrying:
+		l.Finish(evt.NodeID, pipeline.OutcomeRetry, reason, evt.Timestamp)
+	}
+}
+
+// Records returns a copy of the attempt history keyed by node ID, or nil if
+// no attempts have been recorded.
+func (l *AttemptLog) Records() map[string][]AttemptRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.attempts) == 0 {
+		return nil
+	}
+	out := make(map[string][]AttemptRecord, len(l.attempts))
+	for node, records := range l.attempts {
+		out[node] = append([]AttemptRecord(nil), records...)
+	}
+	return out
+}
+
+// add appends a record; callers hold l.mu or own l exclusively.
+func (l *AttemptLog) add(nodeID string, r AttemptRecord) {
+	if l.attempts == nil {
+		l.attempts = make(map[string][]AttemptRecord)
+	}
+	l.attempts[nodeID] = append(l.attempts[nodeID], r)
+}
@@ -0,0 +1,125 @@
+// ABOUTME: Tests for AttemptLog and the NodeAttempts history persisted with a run.
+// ABOUTME: A node retried twice must end with three ordered attempt records, whether logged directly or from stage events.
+package runstate
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/2389-research/tracker/pipeline"
+)
+
+func TestAttemptLogRetriedTwiceRecordsThreeAttempts(t *testing.T) {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(s int) time.Time { return base.Add(time.Duration(s) * time.Second) }
+
+	l := &AttemptLog{}
+	l.Start("build", at(0))
+	l.Finish("build", "retry", "compile error", at(2))
+	l.Start("build", at(2))
+	l.Finish("build", "retry", "test failure", at(5))
+	l.Start("build", at(5))
+	l.Finish("build", "success", "", at(6))
+	l.Start("ship", at(6))
+	l.Finish("ship", "success", "", at(7))
+	l.Finish("ship", "success", "previously completed", at(8))
+
+	got := l.Records()
+	build := got["build"]
+	if len(build) != 3 {
+		t.Fatalf("build attempts = %d, want 3: %+v", len(build), build)
+	}
+	want := []AttemptRecord{
+		{Status: "retry", Reason: "compile error", StartedAt: at(0), Duration: 2 * time.Second},
+		{Status: "retry", Reason: "test failure", StartedAt: at(2), Duration: 3 * time.Second},
+		{Status: "success", StartedAt: at(5), Duration: time.Second},
+	}
+	for i, w := range want {
+		if build[i] != w {
+			t.Errorf("attempt %d = %+v, want %+v", i, build[i], w)
+		}
+	}
+	if len(got["ship"]) != 1 {
+		t.Errorf("ship attempts = %d, want 1: a finish without a start is not an attempt", len(got["ship"]))
+	}
+
+	// Records hands out a copy.
+	got["build"][0].Status = "tampered"
+	if l.Records()["build"][0].Status != "retry" {
+		t.Error("Records should not expose the log's internal slices")
+	}
+}
+
+func TestAttemptLogSeededAndPersisted(t *testing.T) {
+	if (&AttemptLog{}).Records() != nil {
+		t.Error("empty log should report nil records")
+	}
+
+	start := time.Now().Truncate(time.Millisecond)
+	prior := map[string][]AttemptRecord{"build": {{Status: "fail", Reason: "boom", StartedAt: start, Duration: time.Second}}}
+	l := NewAttemptLog(prior)
+	l.Start("build", start.Add(time.Minute))
+	l.Finish("build", "success", "", start.Add(2*time.Minute))
+
+	store := newTestStore(t)
+	state := newTestRunState(t)
+	if err := store.Create(state); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	state.NodeAttempts = l.Records()
+	if err := store.Update(state); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	got, err := store.Get(state.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	build := got.NodeAttempts["build"]
+	if len(build) != 2 || build[0].Reason != "boom" || build[1].Status != "success" || build[1].Duration != time.Minute {
+		t.Errorf("persisted attempts = %+v, want the seeded failure then a one-minute success", build)
+	}
+}
+
+func TestAttemptLogObserveStageEvents(t *testing.T) {
+	l := &AttemptLog{}
+	ts := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(s int) time.Time { return ts.Add(time.Duration(s) * time.Second) }
+
+	// The engine emits stage_started for every attempt, then one of
+	// stage_retrying, stage_failed, or stage_completed.
+	for _, evt := range []pipeline.PipelineEvent{
+		{Type: pipeline.EventPipelineStarted, Timestamp: at(0)},
+		{Type: pipeline.EventStageStarted, NodeID: "start", Timestamp: at(0)},
+		{Type: pipeline.EventStageCompleted, NodeID: "start", Timestamp: at(0)},
+		{Type: pipeline.EventStageStarted, NodeID: "build", Timestamp: at(0)},
+		{Type: pipeline.EventStageRetrying, NodeID: "build", Message: "retrying node \"build\" (attempt 1/2, policy=standard)", Timestamp: at(1)},
+		{Type: pipeline.EventStageStarted, NodeID: "build", Timestamp: at(1)},
+		{Type: pipeline.EventStageRetrying, NodeID: "build", Message: "retrying node \"build\" (attempt 2/2, policy=standard)", Timestamp: at(3)},
+		{Type: pipeline.EventStageStarted, NodeID: "build", Timestamp: at(3)},
+		{Type: pipeline.EventStageCompleted, NodeID: "build", Timestamp: at(4)},
+		{Type: pipeline.EventCheckpointSaved, NodeID: "build", Timestamp: at(4)},
+		{Type: pipeline.EventStageStarted, NodeID: "deploy", Timestamp: at(4)},
+		{Type: pipeline.EventStageFailed, NodeID: "deploy", Message: "handler error", Err: errors.New("exit status 1"), Timestamp: at(6)},
+	} {
+		l.Observe(evt)
+	}
+
+	records := l.Records()
+	build := records["build"]
+	if len(build) != 3 {
+		t.Fatalf("build attempts = %d, want 3: %+v", len(build), build)
+	}
+	for i, want := range []string{pipeline.OutcomeRetry, pipeline.OutcomeRetry, pipeline.OutcomeSuccess} {
+		if build[i].Status != want {
+			t.Errorf("attempt %d status = %q, want %q", i, build[i].Status, want)
+		}
+	}
+	if build[1].Duration != 2*time.Second || build[1].Reason == "" {
+		t.Errorf("second attempt = %+v, want a 2s retry with a reason", build[1])
+	}
+	deploy := records["deploy"]
+	if len(deploy) != 1 || deploy[0].Status != pipeline.OutcomeFail || deploy[0].Reason != "exit status 1" {
+		t.Errorf("deploy attempts = %+v, want one failure carrying the handler error", deploy)
+	}
+}
@@ -0,0 +1,49 @@
+// ABOUTME: Crash-safe checkpoint persistence: atomic saves and loads that report corrupt files distinctly.
+// ABOUTME: A checkpoint truncated by a crash mid-write yields ErrCorruptCheckpoint instead of blocking resume discovery.
+package runstate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// ErrCorruptCheckpoint is wrapped by LoadCheckpoint for a checkpoint file
+// that exists but does not hold a checkpoint, such as truncated JSON.
+var ErrCorruptCheckpoint = errors.New("corrupt checkpoint")
+
+// SaveCheckpoint writes cp to path as JSON, creating directories as needed.
+// Unlike pipeline.SaveCheckpoint the write is atomic, so an interrupted save
+// keeps the previous checkpoint intact.
+func SaveCheckpoint(cp *pipeline.Checkpoint, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create checkpoint directory: %w", err)
+	}
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal checkpoint: %w", err)
+	}
+	if err := WriteFileAtomic(path, data, 0o600); err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// LoadCheckpoint reads the checkpoint at path. Read errors are returned as
+// is, so a missing file still matches fs.ErrNotExist; unparseable content
+// wraps ErrCorruptCheckpoint.
+func LoadCheckpoint(path string) (*pipeline.Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read checkpoint: %w", err)
+	}
+	var cp pipeline.Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("%w %s: %v", ErrCorruptCheckpoint, path, err)
+	}
+	return &cp, nil
+}
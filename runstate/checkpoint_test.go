@@ -0,0 +1,92 @@
+// ABOUTME: Tests for crash-safe checkpoint persistence and resume discovery past corrupt checkpoints.
+// ABOUTME: Writes truncated checkpoint files and checks FindResumable skips them while still finding a valid run.
+package runstate
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/2389-research/tracker/pipeline"
+)
+
+func TestSaveCheckpointAtomic(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nested")
+	path := filepath.Join(dir, "checkpoint.json")
+	cp := &pipeline.Checkpoint{RunID: "run-1", CurrentNode: "build", CompletedNodes: []string{"start"}}
+	if err := SaveCheckpoint(cp, path); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.RunID != "run-1" || got.CurrentNode != "build" {
+		t.Errorf("loaded %+v", got)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("checkpoint dir holds %d entries, want only checkpoint.json (no temp files left behind)", len(entries))
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("checkpoint mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestLoadCheckpointCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	for _, content := range []string{`{"run_id":"run-1","current_node":"bu`, ""} {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadCheckpoint(path); !errors.Is(err, ErrCorruptCheckpoint) {
+			t.Errorf("LoadCheckpoint(%q) err = %v, want ErrCorruptCheckpoint", content, err)
+		}
+	}
+
+	_, err := LoadCheckpoint(filepath.Join(t.TempDir(), "missing.json"))
+	if !errors.Is(err, os.ErrNotExist) || errors.Is(err, ErrCorruptCheckpoint) {
+		t.Errorf("missing checkpoint err = %v, want not-exist", err)
+	}
+}
+
+func TestFindResumableSkipsTruncatedCheckpoint(t *testing.T) {
+	store := newTestStore(t)
+
+	valid := newTestRunState(t)
+	valid.Status = "failed"
+	valid.SourceHash = "hash"
+	valid.StartedAt = time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+	if err := store.Create(valid); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveCheckpoint(&pipeline.Checkpoint{RunID: valid.ID, CurrentNode: "build"}, store.CheckpointPath(valid.ID)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The newer run was killed while its checkpoint was being written.
+	truncated := newTestRunState(t)
+	truncated.Status = "failed"
+	truncated.SourceHash = "hash"
+	if err := store.Create(truncated); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(store.CheckpointPath(truncated.ID), []byte(`{"run_id":"`+truncated.ID+`","compl`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.FindResumable("hash")
+	if err != nil {
+		t.Fatalf("FindResumable failed: %v", err)
+	}
+	if got == nil || got.ID != valid.ID {
+		t.Fatalf("FindResumable = %+v, want the run with the valid checkpoint %s", got, valid.ID)
+	}
+}
@@ -0,0 +1,82 @@
+// ABOUTME: Pipeline context merging with caller-controlled conflict resolution.
+// ABOUTME: Resuming a run merges checkpoint context with fresh graph attributes, keeping run state but taking new graph.* values.
+package runstate
+
+import (
+	"errors"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// graphAttrPrefix is the context key prefix under which the engine mirrors
+// the graph's attributes (graph.goal and the like).
+const graphAttrPrefix = "graph."
+
+// FailureReasonKey is the context key explaining why a node failed a limit
+// mammoth enforces, such as the artifact cap or a token allocation.
+// Pipelines can route on it like any other context key.
+const FailureReasonKey = "failure_reason"
+
+// MergeContext writes updates into pctx. A key already holding a different
+// value is a conflict: onConflict(key, old, new) decides the value kept.
+// With a nil onConflict updates overwrite, like PipelineContext.Merge. Keys
+// are merged in sorted order so a resolver sees conflicts deterministically.
+func MergeContext(pctx *pipeline.PipelineContext, updates map[string]string, onConflict func(key, old, new string) string) {
+	keys := make([]string, 0, len(updates))
+	for k := range updates {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := updates[k]
+		if old, ok := pctx.Get(k); ok && old != v && onConflict != nil {
+			v = onConflict(k, old, v)
+		}
+		pctx.Set(k, v)
+	}
+}
+
+// PreferFreshGraphAttrs resolves resume conflicts between a checkpoint's
+// context (old) and the current graph's attributes (new): graph.* keys take
+// the fresh value so edits to the pipeline's attributes apply, and every
+// other key keeps the checkpoint's run state.
+func PreferFreshGraphAttrs(key, old, new string) string {
+	if strings.HasPrefix(key, graphAttrPrefix) {
+		return new
+	}
+	return old
+}
+
+// ResumeContext returns the context a resumed run starts from: the
+// checkpoint's values merged with graphAttrs (as graph.<name> keys) using
+// PreferFreshGraphAttrs.
+func ResumeContext(checkpoint, graphAttrs map[string]string) map[string]string {
+	pctx := pipeline.NewPipelineContext()
+	pctx.Merge(checkpoint)
+	fresh := make(map[string]string, len(graphAttrs))
+	for k, v := range graphAttrs {
+		fresh[graphAttrPrefix+k] = v
+	}
+	MergeContext(pctx, fresh, PreferFreshGraphAttrs)
+	return pctx.Snapshot()
+}
+
+// RefreshCheckpointContext rewrites the checkpoint at path so its context is
+// ResumeContext of the stored values and graphAttrs. The engine restores a
+// checkpoint's context over the graph's attributes, so without this a resumed
+// run would keep the attribute values it started with. A missing checkpoint
+// is not an error.
+func RefreshCheckpointContext(path string, graphAttrs map[string]string) error {
+	cp, err := LoadCheckpoint(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	cp.Context = ResumeContext(cp.Context, graphAttrs)
+	return SaveCheckpoint(cp, path)
+}
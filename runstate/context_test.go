@@ -0,0 +1,114 @@
+// ABOUTME: Tests for merging pipeline context with a conflict callback.
+// ABOUTME: Covers plain merges, overwrite by default, a custom max resolver, and the resume merge of checkpoint and graph attributes.
+package runstate
+
+import (
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/2389-research/tracker/pipeline"
+)
+
+func contextWith(values map[string]string) *pipeline.PipelineContext {
+	pctx := pipeline.NewPipelineContext()
+	pctx.Merge(values)
+	return pctx
+}
+
+func TestMergeContextWithoutConflicts(t *testing.T) {
+	pctx := contextWith(map[string]string{"a": "1"})
+	called := false
+	MergeContext(pctx, map[string]string{"a": "1", "b": "2"}, func(string, string, string) string {
+		called = true
+		return ""
+	})
+	if called {
+		t.Error("resolver called without a conflict")
+	}
+	if want := map[string]string{"a": "1", "b": "2"}; !reflect.DeepEqual(pctx.Snapshot(), want) {
+		t.Errorf("context = %v, want %v", pctx.Snapshot(), want)
+	}
+}
+
+func TestMergeContextNilResolverOverwrites(t *testing.T) {
+	pctx := contextWith(map[string]string{"a": "old"})
+	MergeContext(pctx, map[string]string{"a": "new"}, nil)
+	if got, _ := pctx.Get("a"); got != "new" {
+		t.Errorf("a = %q, want new", got)
+	}
+}
+
+func TestMergeContextCustomResolverKeepsMax(t *testing.T) {
+	pctx := contextWith(map[string]string{"retries": "3", "score": "9", "name": "x"})
+	var conflicts []string
+	maxResolver := func(key, old, new string) string {
+		conflicts = append(conflicts, key)
+		o, _ := strconv.Atoi(old)
+		n, _ := strconv.Atoi(new)
+		if n > o {
+			return new
+		}
+		return old
+	}
+	MergeContext(pctx, map[string]string{"retries": "5", "score": "2", "fresh": "1"}, maxResolver)
+
+	want := map[string]string{"retries": "5", "score": "9", "name": "x", "fresh": "1"}
+	if !reflect.DeepEqual(pctx.Snapshot(), want) {
+		t.Errorf("context = %v, want %v", pctx.Snapshot(), want)
+	}
+	if !reflect.DeepEqual(conflicts, []string{"retries", "score"}) {
+		t.Errorf("conflicts = %v, want retries and score in key order", conflicts)
+	}
+}
+
+func TestResumeContextPrefersFreshGraphAttrs(t *testing.T) {
+	checkpoint := map[string]string{
+		"graph.goal":     "old goal",
+		"graph.model":    "old-model",
+		"outcome":        "success",
+		"human_response": "yes",
+	}
+	got := ResumeContext(checkpoint, map[string]string{"goal": "new goal", "retries": "2"})
+	want := map[string]string{
+		"graph.goal":     "new goal",
+		"graph.model":    "old-model",
+		"graph.retries":  "2",
+		"outcome":        "success",
+		"human_response": "yes",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("resume context = %v, want %v", got, want)
+	}
+}
+
+func TestRefreshCheckpointContext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	if err := RefreshCheckpointContext(path, map[string]string{"goal": "g"}); err != nil {
+		t.Fatalf("missing checkpoint: %v", err)
+	}
+
+	cp := &pipeline.Checkpoint{
+		RunID:          "run-1",
+		CurrentNode:    "build",
+		CompletedNodes: []string{"start", "plan"},
+		Context:        map[string]string{"graph.goal": "old", "last_response": "plan text"},
+	}
+	if err := pipeline.SaveCheckpoint(cp, path); err != nil {
+		t.Fatal(err)
+	}
+	if err := RefreshCheckpointContext(path, map[string]string{"goal": "new"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := pipeline.LoadCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Context["graph.goal"] != "new" || got.Context["last_response"] != "plan text" {
+		t.Errorf("checkpoint context = %v, want fresh goal and kept run state", got.Context)
+	}
+	if got.CurrentNode != "build" || !reflect.DeepEqual(got.CompletedNodes, cp.CompletedNodes) {
+		t.Errorf("checkpoint progress changed: %+v", got)
+	}
+}
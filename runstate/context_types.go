@@ -0,0 +1,61 @@
+// ABOUTME: Typed reads of the string-valued pipeline context: ints, floats, and bools in ContextValue's canonical form.
+// ABOUTME: Context persists as strings, so a value written with ContextValue reads back the same after a store or checkpoint round trip.
+package runstate
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// The pipeline context, the run store's context.json, and checkpoints all
+// hold strings, so JSON persistence never turns an integer into a float64 or
+// a struct into a map: each value comes back as the exact text written. The
+// accessors below give that text a type, accepting everything ContextValue
+// produces for the type and the loose forms ParseContextValue accepts from
+// the command line. A missing key or a value of another type reports false.
+
+// ContextInt returns the integer stored at key. Integral numbers in any
+// JSON form (42, 4.2e1, 42.0) are accepted if they fit in an int64.
+func ContextInt(ctx map[string]string, key string) (int64, bool) {
+	raw, ok := contextNumber(ctx, key)
+	if !ok {
+		return 0, false
+	}
+	if n, err := strconv.ParseInt(string(raw), 10, 64); err == nil {
+		return n, true
+	}
+	f, err := raw.Float64()
+	if err != nil || f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+// ContextFloat returns the number stored at key.
+func ContextFloat(ctx map[string]string, key string) (float64, bool) {
+	raw, ok := contextNumber(ctx, key)
+	if !ok {
+		return 0, false
+	}
+	f, err := raw.Float64()
+	return f, err == nil
+}
+
+// ContextBool returns the bool stored at key: true or false in any case.
+func ContextBool(ctx map[string]string, key string) (bool, bool) {
+	v, ok := ParseContextValue(ctx[key]).(bool)
+	return v, ok
+}
+
+// contextNumber returns the value at key if ParseContextValue reads it as a
+// number.
+func contextNumber(ctx map[string]string, key string) (json.Number, bool) {
+	raw, ok := ctx[key]
+	if !ok {
+		return "", false
+	}
+	n, ok := ParseContextValue(strings.TrimSpace(raw)).(json.Number)
+	return n, ok
+}
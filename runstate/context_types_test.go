@@ -0,0 +1,105 @@
+// ABOUTME: Tests for typed context reads, including round trips through the run store and checkpoints.
+// ABOUTME: Values written with ContextValue must come back with the same type and value after being reloaded.
+package runstate
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// typedContext renders typed values the way runs write them.
+func typedContext(t *testing.T) map[string]string {
+	t.Helper()
+	ctx, err := InitialContext(map[string]any{
+		"retries":  3,
+		"big":      int64(math.MaxInt64),
+		"ratio":    0.25,
+		"whole":    2.0,
+		"dry_run":  true,
+		"verbose":  false,
+		"ticket":   "0042",
+		"negative": -17,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctx
+}
+
+// assertTypedContext checks every typed accessor against typedContext.
+func assertTypedContext(t *testing.T, ctx map[string]string) {
+	t.Helper()
+	for key, want := range map[string]int64{"retries": 3, "big": math.MaxInt64, "whole": 2, "negative": -17} {
+		if got, ok := ContextInt(ctx, key); !ok || got != want {
+			t.Errorf("ContextInt(%s) = %d, %v; want %d", key, got, ok, want)
+		}
+	}
+	if got, ok := ContextFloat(ctx, "ratio"); !ok || got != 0.25 {
+		t.Errorf("ContextFloat(ratio) = %v, %v; want 0.25", got, ok)
+	}
+	if got, ok := ContextFloat(ctx, "retries"); !ok || got != 3 {
+		t.Errorf("ContextFloat(retries) = %v, %v; want 3", got, ok)
+	}
+	if got, ok := ContextBool(ctx, "dry_run"); !ok || !got {
+		t.Errorf("ContextBool(dry_run) = %v, %v; want true", got, ok)
+	}
+	if got, ok := ContextBool(ctx, "verbose"); !ok || got {
+		t.Errorf("ContextBool(verbose) = %v, %v; want false", got, ok)
+	}
+	for _, key := range []string{"ratio", "ticket", "dry_run", "missing"} {
+		if _, ok := ContextInt(ctx, key); ok {
+			t.Errorf("ContextInt(%s) should not read as an int", key)
+		}
+	}
+	if _, ok := ContextBool(ctx, "retries"); ok {
+		t.Error("ContextBool(retries) should not read as a bool")
+	}
+	if ctx["ticket"] != "0042" {
+		t.Errorf("ticket = %q, want the string 0042 unchanged", ctx["ticket"])
+	}
+}
+
+func TestContextTypedAccessors(t *testing.T) {
+	assertTypedContext(t, typedContext(t))
+
+	loose := map[string]string{"n": " 4.2e1 ", "b": "TRUE", "huge": "1e19"}
+	if got, ok := ContextInt(loose, "n"); !ok || got != 42 {
+		t.Errorf("ContextInt(4.2e1) = %d, %v; want 42", got, ok)
+	}
+	if got, ok := ContextBool(loose, "b"); !ok || !got {
+		t.Errorf("ContextBool(TRUE) = %v, %v; want true", got, ok)
+	}
+	if _, ok := ContextInt(loose, "huge"); ok {
+		t.Error("ContextInt(1e19) should overflow")
+	}
+}
+
+func TestContextTypesSurviveStoreRoundTrip(t *testing.T) {
+	store := newTestStore(t)
+	state := newTestRunState(t)
+	state.Context = typedContext(t)
+	if err := store.Create(state); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := store.Get(state.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertTypedContext(t, loaded.Context)
+}
+
+func TestContextTypesSurviveCheckpointRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	cp := &pipeline.Checkpoint{RunID: "r1", Context: typedContext(t)}
+	if err := SaveCheckpoint(cp, path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertTypedContext(t, loaded.Context)
+}
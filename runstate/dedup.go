@@ -0,0 +1,104 @@
+// ABOUTME: Content-addressed artifact deduplication: identical artifact files share one blob on disk.
+// ABOUTME: Files are replaced by hard links to <blobDir>/<sha256>, so paths, listing, and reads are unchanged.
+package runstate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// DedupArtifacts content-addresses every regular file under dir: each file's
+// bytes are stored once as <blobDir>/<hash[:2]>/<hash> and the file is
+// replaced by a hard link to that blob. Identical content written by several
+// nodes or runs sharing blobDir therefore occupies disk once, while every
+// logical path still resolves to the same bytes. Artifacts must not be
+// modified in place afterwards, since linked paths share storage. Files that
+// cannot be linked (for example across filesystems) are left as they are.
+// Returns the number of bytes saved.
+func DedupArtifacts(dir, blobDir string) (int64, error) {
+	absBlobs, err := filepath.Abs(blobDir)
+	if err != nil {
+		return 0, err
+	}
+
+	var saved int64
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if abs, _ := filepath.Abs(path); d.IsDir() && abs == absBlobs {
+			return filepath.SkipDir
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		n, err := dedupFile(path, absBlobs)
+		saved += n
+		return err
+	})
+	if err != nil {
+		return saved, fmt.Errorf("dedup artifacts in %s: %w", dir, err)
+	}
+	return saved, nil
+}
+
+// dedupFile links path to its content blob, creating the blob from path if
+// it is the first file with that content. Returns the bytes saved.
+func dedupFile(path, blobDir string) (int64, error) {
+	sum, size, err := hashFile(path)
+	if err != nil {
+		return 0, err
+	}
+	blob := filepath.Join(blobDir, sum[:2], sum)
+
+	blobInfo, err := os.Stat(blob)
+	if os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(blob), 0o755); err != nil {
+			return 0, err
+		}
+		// The first copy of this content becomes the blob.
+		_ = os.Link(path, blob)
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	if os.SameFile(info, blobInfo) {
+		return 0, nil
+	}
+
+	tmp := path + ".dedup"
+	if err := os.Link(blob, tmp); err != nil {
+		return 0, nil
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return 0, err
+	}
+	return size, nil
+}
+
+// hashFile returns the hex SHA-256 of the file's contents and its size.
+func hashFile(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
@@ -0,0 +1,86 @@
+// ABOUTME: Tests for content-addressed artifact deduplication.
+// ABOUTME: Identical files from different nodes and runs must share a single blob while reading unchanged.
+package runstate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// countBlobs returns the number of blob files under blobDir.
+func countBlobs(t *testing.T, blobDir string) int {
+	t.Helper()
+	n := 0
+	err := filepath.WalkDir(blobDir, func(_ string, d os.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			n++
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func writeArtifact(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDedupArtifactsIdenticalContent(t *testing.T) {
+	root := t.TempDir()
+	runDir := filepath.Join(root, "run1")
+	blobDir := filepath.Join(root, ".blobs")
+	writeArtifact(t, filepath.Join(runDir, "plan", "response.md"), "same bytes")
+	writeArtifact(t, filepath.Join(runDir, "build", "response.md"), "same bytes")
+	writeArtifact(t, filepath.Join(runDir, "build", "prompt.md"), "different")
+
+	saved, err := DedupArtifacts(runDir, blobDir)
+	if err != nil {
+		t.Fatalf("DedupArtifacts: %v", err)
+	}
+	if saved != int64(len("same bytes")) {
+		t.Errorf("saved = %d, want %d", saved, len("same bytes"))
+	}
+	if got := countBlobs(t, blobDir); got != 2 {
+		t.Errorf("blob count = %d, want 2 (one per distinct content)", got)
+	}
+
+	a, _ := os.Stat(filepath.Join(runDir, "plan", "response.md"))
+	b, _ := os.Stat(filepath.Join(runDir, "build", "response.md"))
+	if !os.SameFile(a, b) {
+		t.Error("identical artifacts should share one underlying blob")
+	}
+	data, err := os.ReadFile(filepath.Join(runDir, "build", "response.md"))
+	if err != nil || string(data) != "same bytes" {
+		t.Errorf("read back %q (err %v), want unchanged content", data, err)
+	}
+}
+
+func TestDedupArtifactsAcrossRuns(t *testing.T) {
+	root := t.TempDir()
+	blobDir := filepath.Join(root, ".blobs")
+	writeArtifact(t, filepath.Join(root, "run1", "n", "response.md"), "shared")
+	writeArtifact(t, filepath.Join(root, "run2", "n", "response.md"), "shared")
+
+	for _, run := range []string{"run1", "run2"} {
+		if _, err := DedupArtifacts(filepath.Join(root, run), blobDir); err != nil {
+			t.Fatalf("dedup %s: %v", run, err)
+		}
+	}
+	if got := countBlobs(t, blobDir); got != 1 {
+		t.Errorf("blob count = %d, want 1", got)
+	}
+
+	// Running again is a no-op.
+	if saved, err := DedupArtifacts(filepath.Join(root, "run2"), blobDir); err != nil || saved != 0 {
+		t.Errorf("second pass saved %d (err %v), want 0", saved, err)
+	}
+}
@@ -0,0 +1,119 @@
+// ABOUTME: Typed initial context supplied when a run starts (the CLI's -set, the server's initial_context).
+// ABOUTME: Values are read as bools, numbers, or strings and stored in the string-valued pipeline context in canonical form.
+package runstate
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseContextValue types a value given on the command line: true and false
+// (in any case) are bools, JSON numbers are numbers, a JSON-quoted string is
+// the string it quotes, and anything else is kept as the raw string. Numbers
+// with leading zeros, such as ticket IDs like 0042, stay strings.
+func ParseContextValue(raw string) any {
+	trimmed := strings.TrimSpace(raw)
+	switch strings.ToLower(trimmed) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if trimmed == "" || !strings.ContainsAny(trimmed[:1], `"-0123456789`) {
+		return raw
+	}
+	dec := json.NewDecoder(strings.NewReader(trimmed))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil || dec.InputOffset() != int64(len(trimmed)) {
+		return raw
+	}
+	switch v := v.(type) {
+	case json.Number, string:
+		return v
+	}
+	return raw
+}
+
+// ParseContextAssignment splits a "key=value" assignment and types its
+// value with ParseContextValue.
+func ParseContextAssignment(s string) (string, any, error) {
+	key, value, ok := strings.Cut(s, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return "", nil, fmt.Errorf("invalid context assignment %q (want key=value)", s)
+	}
+	return key, ParseContextValue(value), nil
+}
+
+// ContextValue renders a typed value as the string the pipeline context
+// holds: bools as true or false, integers in decimal (so 1e3 becomes 1000),
+// other numbers in their shortest JSON form, strings unchanged, null as
+// empty, and objects or arrays as compact JSON.
+func ContextValue(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case json.Number:
+		if !strings.ContainsAny(string(v), ".eE") {
+			// A JSON integer is already canonical, even past int64.
+			return string(v), nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return "", fmt.Errorf("invalid number %q", v)
+		}
+		return ContextValue(f)
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return strconv.FormatInt(int64(v), 10), nil
+		}
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// InitialContext converts typed values into pipeline context entries. Empty
+// keys are rejected, as are graph.* keys, which the engine fills from the
+// pipeline's graph attributes.
+func InitialContext(values map[string]any) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	ctx := make(map[string]string, len(values))
+	for _, k := range keys {
+		if strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("initial context key must not be empty")
+		}
+		if strings.HasPrefix(k, graphAttrPrefix) {
+			return nil, fmt.Errorf("initial context key %q: %s* keys come from the pipeline's graph attributes", k, graphAttrPrefix)
+		}
+		v, err := ContextValue(values[k])
+		if err != nil {
+			return nil, fmt.Errorf("initial context key %q: %w", k, err)
+		}
+		ctx[k] = v
+	}
+	return ctx, nil
+}
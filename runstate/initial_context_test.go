@@ -0,0 +1,88 @@
+// ABOUTME: Tests for typed initial context: parsing -set values and rendering them into the pipeline context.
+// ABOUTME: Covers bools, numbers, quoted and plain strings, JSON values, and rejected keys.
+package runstate
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseContextValue(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want any
+	}{
+		{"true", true},
+		{"FALSE", false},
+		{"42", json.Number("42")},
+		{"-1.5", json.Number("-1.5")},
+		{"0042", "0042"},
+		{`"true"`, "true"},
+		{"main", "main"},
+		{"feature/x=1", "feature/x=1"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := ParseContextValue(tt.raw); got != tt.want {
+			t.Errorf("ParseContextValue(%q) = %#v, want %#v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestParseContextAssignment(t *testing.T) {
+	key, value, err := ParseContextAssignment("branch=release=2")
+	if err != nil || key != "branch" || value != "release=2" {
+		t.Errorf("ParseContextAssignment = %q, %#v, %v; want branch, release=2", key, value, err)
+	}
+	for _, bad := range []string{"branch", "=main"} {
+		if _, _, err := ParseContextAssignment(bad); err == nil {
+			t.Errorf("ParseContextAssignment(%q) should fail", bad)
+		}
+	}
+}
+
+func TestInitialContext(t *testing.T) {
+	var body struct {
+		InitialContext map[string]any `json:"initial_context"`
+	}
+	dec := json.NewDecoder(strings.NewReader(`{"initial_context": {
+		"ticket": "ENG-12", "dry_run": true, "retries": 3, "ratio": 0.25,
+		"big": 12345678901234567890, "labels": ["a", "b"], "note": null
+	}}`))
+	dec.UseNumber()
+	if err := dec.Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	body.InitialContext["from_cli"] = ParseContextValue("1e3")
+
+	got, err := InitialContext(body.InitialContext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"ticket":   "ENG-12",
+		"dry_run":  "true",
+		"retries":  "3",
+		"ratio":    "0.25",
+		"big":      "12345678901234567890",
+		"labels":   `["a","b"]`,
+		"note":     "",
+		"from_cli": "1000",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("InitialContext = %v, want %v", got, want)
+	}
+}
+
+func TestInitialContextRejectsKeys(t *testing.T) {
+	for _, values := range []map[string]any{
+		{"": "x"},
+		{"graph.goal": "override"},
+	} {
+		if _, err := InitialContext(values); err == nil {
+			t.Errorf("InitialContext(%v) should fail", values)
+		}
+	}
+}
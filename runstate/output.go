@@ -0,0 +1,111 @@
+// ABOUTME: Primary run output: captures each node's output as it succeeds and picks the run's canonical answer.
+// ABOUTME: Nodes marked result="true" win; otherwise the last terminal node (one with an edge into the exit) that produced output.
+package runstate
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// outputHandlers lists the handler names whose outputs OutputLog captures.
+var outputHandlers = []string{"codergen", "tool", "wait.human"}
+
+// outputContextKeys lists, in priority order, the context keys a handler's
+// output is read from.
+var outputContextKeys = []string{
+	pipeline.ContextKeyLastResponse,
+	pipeline.ContextKeyToolStdout,
+	pipeline.ContextKeyHumanResponse,
+}
+
+// nodeOutput is the output a node produced on one execution.
+type nodeOutput struct {
+	nodeID string
+	output string
+}
+
+// OutputLog records the outputs of successful node executions in order. It
+// is safe for concurrent use; the zero value is ready to use.
+type OutputLog struct {
+	mu      sync.Mutex
+	outputs []nodeOutput
+}
+
+// Hook wraps the registry's output-producing handlers so every successful
+// execution is recorded in the log.
+func (l *OutputLog) Hook(registry *pipeline.HandlerRegistry) {
+	for _, name := range outputHandlers {
+		if inner := registry.Get(name); inner != nil {
+			registry.Register(&outputHandler{inner: inner, log: l})
+		}
+	}
+}
+
+// Primary picks the run's canonical output: the most recent output of a node
+// marked result="true", falling back to the most recent output of a terminal
+// node, and finally to last_response in the final context. It returns the
+// producing node ID ("" when taken from context) and the output.
+func (l *OutputLog) Primary(g *pipeline.Graph, result *pipeline.EngineResult) (nodeID, output string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, pick := range []func(string) bool{
+		func(id string) bool { return isResultNode(g.Nodes[id]) },
+		func(id string) bool { return isTerminalNode(g, id) },
+	} {
+		for i := len(l.outputs) - 1; i >= 0; i-- {
+			if pick(l.outputs[i].nodeID) {
+				return l.outputs[i].nodeID, l.outputs[i].output
+			}
+		}
+	}
+	if result != nil {
+		return "", result.Context[pipeline.ContextKeyLastResponse]
+	}
+	return "", ""
+}
+
+func (l *OutputLog) add(nodeID, output string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.outputs = append(l.outputs, nodeOutput{nodeID: nodeID, output: output})
+}
+
+// isResultNode reports whether a node is marked as the pipeline's result.
+func isResultNode(n *pipeline.Node) bool {
+	return n != nil && strings.EqualFold(strings.TrimSpace(n.Attrs["result"]), "true")
+}
+
+// isTerminalNode reports whether nodeID has an edge into the graph's exit.
+func isTerminalNode(g *pipeline.Graph, nodeID string) bool {
+	for _, e := range g.OutgoingEdges(nodeID) {
+		if e.To == g.ExitNode {
+			return true
+		}
+	}
+	return false
+}
+
+// outputHandler records the output of the wrapped handler.
+type outputHandler struct {
+	inner pipeline.Handler
+	log   *OutputLog
+}
+
+func (h *outputHandler) Name() string { return h.inner.Name() }
+
+func (h *outputHandler) Execute(ctx context.Context, node *pipeline.Node, pctx *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	out, err := h.inner.Execute(ctx, node, pctx)
+	if err != nil || out.Status == pipeline.OutcomeFail {
+		return out, err
+	}
+	for _, key := range outputContextKeys {
+		if v, ok := out.ContextUpdates[key]; ok {
+			h.log.add(node.ID, v)
+			break
+		}
+	}
+	return out, err
+}
@@ -0,0 +1,125 @@
+// ABOUTME: Tests for OutputLog and primary output selection from result-marked and terminal nodes.
+// ABOUTME: Covers an explicit result node, the terminal-node and final-context fallbacks, and persistence with the run.
+package runstate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/2389-research/tracker/pipeline"
+	"github.com/2389-research/tracker/pipeline/handlers"
+)
+
+// runWithOutputLog executes source with a codergen stub that responds
+// "answer from <node>" and returns the graph, the log and the result.
+func runWithOutputLog(t *testing.T, source string) (*pipeline.Graph, *OutputLog, *pipeline.EngineResult) {
+	t.Helper()
+	g, err := pipeline.ParseDOT(source)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	stub := func(_ context.Context, node *pipeline.Node, _ *pipeline.PipelineContext) (pipeline.Outcome, error) {
+		return pipeline.Outcome{
+			Status:         pipeline.OutcomeSuccess,
+			ContextUpdates: map[string]string{pipeline.ContextKeyLastResponse: "answer from " + node.ID},
+		}, nil
+	}
+	registry := handlers.NewDefaultRegistry(g, handlers.WithCodergenFunc(stub))
+	outputs := &OutputLog{}
+	outputs.Hook(registry)
+	result, err := pipeline.NewEngine(g, registry, pipeline.WithArtifactDir(t.TempDir())).Run(context.Background())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	return g, outputs, result
+}
+
+func TestPrimaryOutputFromResultNode(t *testing.T) {
+	g, outputs, result := runWithOutputLog(t, `digraph p {
+		start [shape=Mdiamond]
+		draft [shape=box, prompt="draft"]
+		answer [shape=box, prompt="answer", result="true"]
+		review [shape=box, prompt="review"]
+		end [shape=Msquare]
+		start -> draft -> answer -> review -> end
+	}`)
+
+	node, out := outputs.Primary(g, result)
+	if node != "answer" || out != "answer from answer" {
+		t.Errorf("got (%q, %q), want the marked node's output", node, out)
+	}
+}
+
+func TestPrimaryOutputFallsBackToTerminalNode(t *testing.T) {
+	g, outputs, result := runWithOutputLog(t, `digraph p {
+		start [shape=Mdiamond]
+		draft [shape=box, prompt="draft"]
+		review [shape=box, prompt="review"]
+		end [shape=Msquare]
+		start -> draft -> review -> end
+	}`)
+
+	node, out := outputs.Primary(g, result)
+	if node != "review" || out != "answer from review" {
+		t.Errorf("got (%q, %q), want the output of the node before exit", node, out)
+	}
+}
+
+func TestPrimaryOutputSkipsNonTerminalNodes(t *testing.T) {
+	// notes finishes after answer, as a parallel branch might, but does not
+	// lead to the exit.
+	g, err := pipeline.ParseDOT(`digraph p {
+		start [shape=Mdiamond]
+		answer [shape=box, prompt="answer"]
+		notes [shape=box, prompt="notes"]
+		end [shape=Msquare]
+		start -> answer -> end
+		start -> notes -> answer
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs := &OutputLog{}
+	outputs.add("answer", "42")
+	outputs.add("notes", "scratch")
+
+	node, out := outputs.Primary(g, nil)
+	if node != "answer" || out != "42" {
+		t.Errorf("got (%q, %q), want the terminal node's output", node, out)
+	}
+}
+
+func TestPrimaryOutputFallsBackToContext(t *testing.T) {
+	g, err := pipeline.ParseDOT(`digraph p {
+		start [shape=Mdiamond]
+		end [shape=Msquare]
+		start -> end
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := &pipeline.EngineResult{Context: map[string]string{pipeline.ContextKeyLastResponse: "from checkpoint"}}
+	node, out := (&OutputLog{}).Primary(g, result)
+	if node != "" || out != "from checkpoint" {
+		t.Errorf("got (%q, %q), want context last_response", node, out)
+	}
+}
+
+func TestPrimaryOutputPersistedWithRun(t *testing.T) {
+	store := newTestStore(t)
+	state := newTestRunState(t)
+	if err := store.Create(state); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	state.ResultNode, state.PrimaryOutput = "answer", "42"
+	if err := store.Update(state); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	got, err := store.Get(state.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.ResultNode != "answer" || got.PrimaryOutput != "42" {
+		t.Errorf("persisted (%q, %q), want (answer, 42)", got.ResultNode, got.PrimaryOutput)
+	}
+}
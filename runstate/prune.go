@@ -0,0 +1,79 @@
+// ABOUTME: Artifact pruning for successful runs: removes a run's artifact files and leaves a manifest.
+// ABOUTME: Keeps disk usage bounded when artifacts are only needed to debug failures.
+package runstate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ArtifactManifestFile is the name of the manifest PruneArtifacts leaves in
+// place of a run's artifacts.
+const ArtifactManifestFile = "manifest.json"
+
+// PrunedFile records one artifact removed by PruneArtifacts.
+type PrunedFile struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// ArtifactManifest lists the artifacts removed from a run directory.
+type ArtifactManifest struct {
+	PrunedAt string       `json:"pruned_at"`
+	Files    []PrunedFile `json:"files"`
+}
+
+// PruneArtifacts deletes everything under dir and writes an
+// ArtifactManifestFile listing what was removed, so the directory remains as
+// a record of the run. A missing dir is not an error.
+func PruneArtifacts(dir string) error {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil
+	}
+
+	manifest := ArtifactManifest{PrunedAt: time.Now().UTC().Format(timeFormat), Files: []PrunedFile{}}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if rel == ArtifactManifestFile {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, PrunedFile{Path: filepath.ToSlash(rel), Size: info.Size()})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("scan artifacts in %s: %w", dir, err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("read artifact dir %s: %w", dir, err)
+	}
+	for _, e := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, e.Name())); err != nil {
+			return fmt.Errorf("remove artifact %s: %w", e.Name(), err)
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal artifact manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ArtifactManifestFile), data, 0o644); err != nil {
+		return fmt.Errorf("write artifact manifest: %w", err)
+	}
+	return nil
+}
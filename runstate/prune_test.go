@@ -0,0 +1,49 @@
+// ABOUTME: Tests for PruneArtifacts, which replaces a run's artifacts with a manifest.
+// ABOUTME: Covers nested files, the manifest contents, and a missing directory.
+package runstate
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPruneArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "build"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "build", "response.md"), []byte("hello"), 0o644)
+	os.WriteFile(filepath.Join(dir, "activity.jsonl"), []byte("{}\n"), 0o644)
+
+	if err := PruneArtifacts(dir); err != nil {
+		t.Fatalf("PruneArtifacts: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != ArtifactManifestFile {
+		t.Fatalf("dir entries = %v, want only %s", entries, ArtifactManifestFile)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ArtifactManifestFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m ArtifactManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("decode manifest: %v", err)
+	}
+	want := []PrunedFile{{Path: "activity.jsonl", Size: 3}, {Path: "build/response.md", Size: 5}}
+	if len(m.Files) != len(want) || m.Files[0] != want[0] || m.Files[1] != want[1] || m.PrunedAt == "" {
+		t.Errorf("manifest = %+v, want files %+v", m, want)
+	}
+}
+
+func TestPruneArtifactsMissingDir(t *testing.T) {
+	if err := PruneArtifacts(filepath.Join(t.TempDir(), "absent")); err != nil {
+		t.Errorf("PruneArtifacts on missing dir: %v", err)
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 	"sort"
@@ -35,9 +37,32 @@ type RunState struct {
 	CompletedAt    *time.Time        `json:"completed_at,omitempty"`
 	CurrentNode    string            `json:"current_node"`
 	CompletedNodes []string          `json:"completed_nodes"`
+	SkippedNodes   []string          `json:"skipped_nodes,omitempty"`
 	Context        map[string]string `json:"context"` // string values, matching tracker model
 	Events         []RunEvent        `json:"events"`
 	Error          string            `json:"error,omitempty"`
+
+	// Seed is the run's -seed value, if any. SeedUnsupported lists the
+	// providers that served requests but do not accept a seed, so their
+	// output was not made reproducible.
+	Seed            *int64   `json:"seed,omitempty"`
+	SeedUnsupported []string `json:"seed_unsupported,omitempty"`
+
+	// NodeAttempts records every execution of each node, in order, so a
+	// node that retried twice has three records.
+	NodeAttempts map[string][]AttemptRecord `json:"node_attempts,omitempty"`
+
+	// Usage is the run's token usage, LLM turns, and tool calls summed
+	// across its codergen nodes; NodeUsage breaks it down per node. Both are
+	// nil for runs that made no LLM calls.
+	Usage     *Usage           `json:"usage,omitempty"`
+	NodeUsage map[string]Usage `json:"node_usage,omitempty"`
+
+	// PrimaryOutput is the run's canonical answer, chosen by
+	// OutputLog.Primary; ResultNode is the node that produced it, empty when
+	// it came from the final context.
+	ResultNode    string `json:"result_node,omitempty"`
+	PrimaryOutput string `json:"primary_output,omitempty"`
 }
 
 // RunStateStore is the interface for persisting and retrieving pipeline run state.
@@ -78,7 +103,19 @@ type runManifest struct {
 	CompletedAt    *string  `json:"completed_at,omitempty"`
 	CurrentNode    string   `json:"current_node"`
 	CompletedNodes []string `json:"completed_nodes"`
+	SkippedNodes   []string `json:"skipped_nodes,omitempty"`
 	Error          string   `json:"error,omitempty"`
+
+	Seed            *int64   `json:"seed,omitempty"`
+	SeedUnsupported []string `json:"seed_unsupported,omitempty"`
+
+	NodeAttempts map[string][]AttemptRecord `json:"node_attempts,omitempty"`
+
+	Usage     *Usage           `json:"usage,omitempty"`
+	NodeUsage map[string]Usage `json:"node_usage,omitempty"`
+
+	ResultNode    string `json:"result_node,omitempty"`
+	PrimaryOutput string `json:"primary_output,omitempty"`
 }
 
 // Compile-time check that FSRunStateStore implements RunStateStore.
@@ -196,9 +233,18 @@ func (s *FSRunStateStore) getUnlocked(id string) (*RunState, error) {
 		SourceHash:     manifest.SourceHash,
 		CurrentNode:    manifest.CurrentNode,
 		CompletedNodes: manifest.CompletedNodes,
+		SkippedNodes:   manifest.SkippedNodes,
 		Context:        ctx,
 		Events:         events,
 		Error:          manifest.Error,
+
+		Seed:            manifest.Seed,
+		SeedUnsupported: manifest.SeedUnsupported,
+		NodeAttempts:    manifest.NodeAttempts,
+		Usage:           manifest.Usage,
+		NodeUsage:       manifest.NodeUsage,
+		ResultNode:      manifest.ResultNode,
+		PrimaryOutput:   manifest.PrimaryOutput,
 	}
 
 	// Parse timestamps
@@ -271,8 +317,10 @@ func (s *FSRunStateStore) List() ([]*RunState, error) {
 }
 
 // FindResumable returns the most recent non-completed run whose SourceHash
-// matches the given hash AND has a checkpoint.json file in its run directory.
-// Returns nil if no matching run is found.
+// matches the given hash AND has a readable checkpoint.json file in its run
+// directory. Runs whose checkpoint is corrupt, such as one truncated by a
+// crash mid-write, are logged and skipped. Returns nil if no matching run is
+// found.
 func (s *FSRunStateStore) FindResumable(sourceHash string) (*RunState, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -312,7 +360,11 @@ func (s *FSRunStateStore) FindResumable(sourceHash string) (*RunState, error) {
 		}
 
 		cpPath := filepath.Join(s.baseDir, state.ID, "checkpoint.json")
-		if _, err := os.Stat(cpPath); os.IsNotExist(err) {
+		if _, err := LoadCheckpoint(cpPath); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			log.Printf("component=runstate action=skip_unreadable_checkpoint run_id=%s err=%v", state.ID, err)
 			continue
 		}
 
@@ -381,7 +433,16 @@ func (s *FSRunStateStore) writeManifest(runDir string, state *RunState) error {
 		StartedAt:      state.StartedAt.Format(timeFormat),
 		CurrentNode:    state.CurrentNode,
 		CompletedNodes: state.CompletedNodes,
+		SkippedNodes:   state.SkippedNodes,
 		Error:          state.Error,
+
+		Seed:            state.Seed,
+		SeedUnsupported: state.SeedUnsupported,
+		NodeAttempts:    state.NodeAttempts,
+		Usage:           state.Usage,
+		NodeUsage:       state.NodeUsage,
+		ResultNode:      state.ResultNode,
+		PrimaryOutput:   state.PrimaryOutput,
 	}
 
 	if state.CompletedAt != nil {
@@ -466,7 +527,13 @@ func writeJSONAtomic(path string, v any) error {
 	if err != nil {
 		return err
 	}
+	return WriteFileAtomic(path, data, 0o600)
+}
 
+// WriteFileAtomic writes data to path through a temp file in the same
+// directory and a rename, so a crash mid-write leaves either the old file or
+// the new one, never a truncated mix.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
 	dir := filepath.Dir(path)
 	tmp, err := os.CreateTemp(dir, ".tmp-*")
 	if err != nil {
@@ -479,6 +546,11 @@ func writeJSONAtomic(path string, v any) error {
 		os.Remove(tmpPath)
 		return fmt.Errorf("write temp file: %w", err)
 	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("chmod temp file: %w", err)
+	}
 	if err := tmp.Close(); err != nil {
 		os.Remove(tmpPath)
 		return fmt.Errorf("close temp file: %w", err)
@@ -0,0 +1,175 @@
+// ABOUTME: Token, LLM round-trip, and tool call usage for a run: per-node counts from codergen outcome context and their run-wide sum.
+// ABOUTME: UsageLog accumulates usage as codergen nodes finish so the final run state can report both.
+package runstate
+
+import (
+	"strconv"
+	"sync"
+)
+
+// Usage context keys set on a codergen node's outcome.
+const (
+	UsageInputTokensKey      = "codergen.input_tokens"
+	UsageOutputTokensKey     = "codergen.output_tokens"
+	UsageTotalTokensKey      = "codergen.total_tokens"
+	UsageCacheReadTokensKey  = "codergen.cache_read_tokens"
+	UsageCacheWriteTokensKey = "codergen.cache_write_tokens"
+	UsageReasoningTokensKey  = "codergen.reasoning_tokens"
+	UsageLLMTurnsKey         = "codergen.llm_turns"
+	UsageToolCallsKey        = "codergen.tool_calls"
+)
+
+// Usage is a token count for one node or a whole run, along with how many
+// LLM round-trips (turns) it took and how many tool calls the model made.
+type Usage struct {
+	InputTokens      int64 `json:"input_tokens"`
+	OutputTokens     int64 `json:"output_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+	CacheReadTokens  int64 `json:"cache_read_tokens,omitempty"`
+	CacheWriteTokens int64 `json:"cache_write_tokens,omitempty"`
+	ReasoningTokens  int64 `json:"reasoning_tokens,omitempty"`
+	LLMTurns         int64 `json:"llm_turns,omitempty"`
+	ToolCalls        int64 `json:"tool_calls,omitempty"`
+}
+
+// Add returns the field-wise sum of u and o.
+func (u Usage) Add(o Usage) Usage {
+	return Usage{
+		InputTokens:      u.InputTokens + o.InputTokens,
+		OutputTokens:     u.OutputTokens + o.OutputTokens,
+		TotalTokens:      u.TotalTokens + o.TotalTokens,
+		CacheReadTokens:  u.CacheReadTokens + o.CacheReadTokens,
+		CacheWriteTokens: u.CacheWriteTokens + o.CacheWriteTokens,
+		ReasoningTokens:  u.ReasoningTokens + o.ReasoningTokens,
+		LLMTurns:         u.LLMTurns + o.LLMTurns,
+		ToolCalls:        u.ToolCalls + o.ToolCalls,
+	}
+}
+
+// IsZero reports whether u counts nothing at all.
+func (u Usage) IsZero() bool {
+	return u == Usage{}
+}
+
+// UsageFromContext reads the codergen.*_tokens, codergen.llm_turns, and
+// codergen.tool_calls keys from an outcome's context updates. ok is false when none are present or parseable. A
+// missing total is taken to be input plus output.
+func UsageFromContext(updates map[string]string) (u Usage, ok bool) {
+	fields := []struct {
+		key string
+		dst *int64
+	}{
+		{UsageInputTokensKey, &u.InputTokens},
+		{UsageOutputTokensKey, &u.OutputTokens},
+		{UsageTotalTokensKey, &u.TotalTokens},
+		{UsageCacheReadTokensKey, &u.CacheReadTokens},
+		{UsageCacheWriteTokensKey, &u.CacheWriteTokens},
+		{UsageReasoningTokensKey, &u.ReasoningTokens},
+		{UsageLLMTurnsKey, &u.LLMTurns},
+		{UsageToolCallsKey, &u.ToolCalls},
+	}
+	for _, f := range fields {
+		raw, present := updates[f.key]
+		if !present {
+			continue
+		}
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			continue
+		}
+		*f.dst = n
+		ok = true
+	}
+	if ok && u.TotalTokens == 0 {
+		u.TotalTokens = u.InputTokens + u.OutputTokens
+	}
+	return u, ok
+}
+
+// ContextUpdates returns u as codergen.* context keys, the inverse of
+// UsageFromContext. Cache, reasoning, turn, and tool call keys are set only
+// when non-zero.
+func (u Usage) ContextUpdates() map[string]string {
+	out := map[string]string{
+		UsageInputTokensKey:  strconv.FormatInt(u.InputTokens, 10),
+		UsageOutputTokensKey: strconv.FormatInt(u.OutputTokens, 10),
+		UsageTotalTokensKey:  strconv.FormatInt(u.TotalTokens, 10),
+	}
+	for key, n := range map[string]int64{
+		UsageCacheReadTokensKey:  u.CacheReadTokens,
+		UsageCacheWriteTokensKey: u.CacheWriteTokens,
+		UsageReasoningTokensKey:  u.ReasoningTokens,
+		UsageLLMTurnsKey:         u.LLMTurns,
+		UsageToolCallsKey:        u.ToolCalls,
+	} {
+		if n != 0 {
+			out[key] = strconv.FormatInt(n, 10)
+		}
+	}
+	return out
+}
+
+// UsageLog accumulates usage per node. A node that runs more than once
+// (retries, loops) accumulates the usage of every execution. It is safe for
+// concurrent use; the zero value is ready to use.
+type UsageLog struct {
+	mu    sync.Mutex
+	nodes map[string]Usage
+}
+
+// NewUsageLog returns a log seeded with prior per-node usage, such as the
+// usage recorded before a run was resumed.
+func NewUsageLog(prior map[string]Usage) *UsageLog {
+	l := &UsageLog{}
+	for node, u := range prior {
+		l.Record(node, u)
+	}
+	return l
+}
+
+// Record adds u to nodeID's usage.
+func (l *UsageLog) Record(nodeID string, u Usage) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.nodes == nil {
+		l.nodes = make(map[string]Usage)
+	}
+	l.nodes[nodeID] = l.nodes[nodeID].Add(u)
+}
+
+// RecordOutcome records the usage carried by a node outcome's context
+// updates, if any.
+func (l *UsageLog) RecordOutcome(nodeID string, updates map[string]string) {
+	if u, ok := UsageFromContext(updates); ok {
+		l.Record(nodeID, u)
+	}
+}
+
+// Nodes returns a copy of the per-node usage, or nil if nothing has been
+// recorded.
+func (l *UsageLog) Nodes() map[string]Usage {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.nodes) == 0 {
+		return nil
+	}
+	out := make(map[string]Usage, len(l.nodes))
+	for node, u := range l.nodes {
+		out[node] = u
+	}
+	return out
+}
+
+// Total returns the run-wide usage, or nil if nothing has been recorded.
+func (l *UsageLog) Total() *Usage {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.nodes) == 0 {
+		return nil
+	}
+	var total Usage
+	for _, u := range l.nodes {
+		total = total.Add(u)
+	}
+	return &total
+}
@@ -0,0 +1,104 @@
+// ABOUTME: Tests for Usage parsing from codergen outcome context and the run-wide UsageLog aggregation.
+// ABOUTME: Checks the summed usage matches the per-node codergen.total_tokens and survives a store round trip.
+package runstate
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestUsageFromContext(t *testing.T) {
+	u, ok := UsageFromContext(map[string]string{
+		"codergen.input_tokens":      "120",
+		"codergen.output_tokens":     "30",
+		"codergen.cache_read_tokens": "100",
+		"codergen.reasoning_tokens":  "7",
+		"codergen.llm_turns":         "3",
+		"codergen.tool_calls":        "2",
+		"codergen.model":             "stub-model",
+	})
+	if !ok {
+		t.Fatal("expected usage to be found")
+	}
+	want := Usage{InputTokens: 120, OutputTokens: 30, TotalTokens: 150, CacheReadTokens: 100, ReasoningTokens: 7, LLMTurns: 3, ToolCalls: 2}
+	if u != want {
+		t.Errorf("usage = %+v, want %+v (total defaults to input+output)", u, want)
+	}
+
+	if _, ok := UsageFromContext(map[string]string{"last_response": "hi", "codergen.total_tokens": "many"}); ok {
+		t.Error("context without parseable token keys should report no usage")
+	}
+
+	back, _ := UsageFromContext(want.ContextUpdates())
+	if back != want {
+		t.Errorf("round trip through ContextUpdates = %+v, want %+v", back, want)
+	}
+}
+
+func TestUsageLogSumsPerNode(t *testing.T) {
+	outcomes := []struct {
+		node    string
+		updates map[string]string
+	}{
+		{"plan", map[string]string{"codergen.input_tokens": "40", "codergen.output_tokens": "10", "codergen.total_tokens": "50"}},
+		{"build", map[string]string{"codergen.input_tokens": "90", "codergen.output_tokens": "35", "codergen.total_tokens": "125", "codergen.cache_read_tokens": "60"}},
+		// A retry of build accumulates onto the node.
+		{"build", map[string]string{"codergen.input_tokens": "80", "codergen.output_tokens": "20", "codergen.total_tokens": "100", "codergen.llm_turns": "2", "codergen.tool_calls": "1"}},
+		// Tool nodes carry no usage.
+		{"test", map[string]string{"tool_stdout": "ok"}},
+	}
+
+	l := &UsageLog{}
+	var want int64
+	for _, o := range outcomes {
+		l.RecordOutcome(o.node, o.updates)
+		if raw, ok := o.updates["codergen.total_tokens"]; ok {
+			n, _ := strconv.ParseInt(raw, 10, 64)
+			want += n
+		}
+	}
+
+	total := l.Total()
+	if total == nil || total.TotalTokens != want {
+		t.Fatalf("total = %+v, want TotalTokens %d (sum of per-node codergen.total_tokens)", total, want)
+	}
+	nodes := l.Nodes()
+	if len(nodes) != 2 || nodes["build"].TotalTokens != 225 || nodes["build"].CacheReadTokens != 60 || nodes["build"].LLMTurns != 2 {
+		t.Errorf("per-node usage = %+v", nodes)
+	}
+	var sum Usage
+	for _, u := range nodes {
+		sum = sum.Add(u)
+	}
+	if sum != *total {
+		t.Errorf("sum of per-node usage %+v != total %+v", sum, *total)
+	}
+
+	if (&UsageLog{}).Total() != nil || (&UsageLog{}).Nodes() != nil {
+		t.Error("an empty log should report nil usage")
+	}
+}
+
+func TestUsagePersisted(t *testing.T) {
+	l := NewUsageLog(map[string]Usage{"plan": {InputTokens: 4, OutputTokens: 1, TotalTokens: 5}})
+	l.Record("plan", Usage{InputTokens: 6, OutputTokens: 4, TotalTokens: 10})
+
+	store := newTestStore(t)
+	state := newTestRunState(t)
+	if err := store.Create(state); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	state.Usage, state.NodeUsage = l.Total(), l.Nodes()
+	if err := store.Update(state); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	got, err := store.Get(state.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	want := Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15}
+	if got.Usage == nil || *got.Usage != want || !reflect.DeepEqual(got.NodeUsage, map[string]Usage{"plan": want}) {
+		t.Errorf("persisted usage = %+v / %+v, want %+v for plan", got.Usage, got.NodeUsage, want)
+	}
+}
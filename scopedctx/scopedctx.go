@@ -0,0 +1,114 @@
+// ABOUTME: Prefix-scoped view over a pipeline context for composed sub-pipelines.
+// ABOUTME: A sub-flow reads and writes unprefixed keys that land under "<prefix>." in the parent context.
+package scopedctx
+
+import (
+	"context"
+	"maps"
+	"strings"
+
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// View is a view of a PipelineContext that namespaces every key under a
+// prefix, so an included sub-flow can use plain keys such as "result" while
+// the parent sees "sub.result". The view holds no state of its own: reads
+// and writes go straight through to the parent.
+type View struct {
+	parent *pipeline.PipelineContext
+	prefix string // always ends in "."
+}
+
+// WithPrefix returns a view of pctx scoped under prefix. A trailing "." on
+// prefix is optional.
+func WithPrefix(pctx *pipeline.PipelineContext, prefix string) *View {
+	return &View{parent: pctx, prefix: strings.TrimSuffix(prefix, ".") + "."}
+}
+
+// WithPrefix returns a view nested under this one, e.g. "sub" then "inner"
+// scopes keys under "sub.inner.".
+func (v *View) WithPrefix(prefix string) *View {
+	return WithPrefix(v.parent, v.prefix+prefix)
+}
+
+// Prefix returns the full key prefix of the view, including the trailing ".".
+func (v *View) Prefix() string { return v.prefix }
+
+// Get reads key from within the scope.
+func (v *View) Get(key string) (string, bool) {
+	return v.parent.Get(v.prefix + key)
+}
+
+// Set writes key within the scope.
+func (v *View) Set(key, value string) {
+	v.parent.Set(v.prefix+key, value)
+}
+
+// Merge writes all updates within the scope.
+func (v *View) Merge(updates map[string]string) {
+	if len(updates) > 0 {
+		v.parent.Merge(v.scoped(updates))
+	}
+}
+
+// Snapshot returns the values within the scope, keyed without the prefix.
+// Keys outside the scope are not visible.
+func (v *View) Snapshot() map[string]string {
+	snap := map[string]string{}
+	for k, val := range v.parent.Snapshot() {
+		if rest, ok := strings.CutPrefix(k, v.prefix); ok {
+			snap[rest] = val
+		}
+	}
+	return snap
+}
+
+// scoped returns updates with every key moved under the prefix.
+func (v *View) scoped(updates map[string]string) map[string]string {
+	out := make(map[string]string, len(updates))
+	for k, val := range updates {
+		out[v.prefix+k] = val
+	}
+	return out
+}
+
+// Handler wraps inner so it runs inside the scope named by prefix: it sees
+// only the scoped keys, unprefixed, and everything it writes, directly or as
+// context updates, lands under the prefix. Wrapping the subgraph handler
+// this way keeps an included sub-pipeline's outputs from colliding with the
+// parent's.
+func Handler(inner pipeline.Handler, prefix string) pipeline.Handler {
+	return &scopedHandler{inner: inner, prefix: prefix}
+}
+
+// scopedHandler runs the wrapped handler against a child context holding
+// the scope's keys, then copies what the handler changed back into the scope.
+type scopedHandler struct {
+	inner  pipeline.Handler
+	prefix string
+}
+
+func (h *scopedHandler) Name() string { return h.inner.Name() }
+
+func (h *scopedHandler) Execute(ctx context.Context, node *pipeline.Node, pctx *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	view := WithPrefix(pctx, h.prefix)
+	before := view.Snapshot()
+	child := pipeline.NewPipelineContext()
+	child.Merge(before)
+	if dir, ok := pctx.GetInternal(pipeline.InternalKeyArtifactDir); ok {
+		child.SetInternal(pipeline.InternalKeyArtifactDir, dir)
+	}
+
+	out, err := h.inner.Execute(ctx, node, child)
+
+	changed := child.Snapshot()
+	maps.DeleteFunc(changed, func(k, val string) bool {
+		prev, ok := before[k]
+		return ok && prev == val
+	})
+	view.Merge(changed)
+	if len(out.ContextUpdates) > 0 {
+		out.ContextUpdates = view.scoped(out.ContextUpdates)
+	}
+	return out, err
+}
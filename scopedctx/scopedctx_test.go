@@ -0,0 +1,123 @@
+// ABOUTME: Tests for the prefix-scoped pipeline context view and the scoped handler wrapper.
+// ABOUTME: Asserts scoped writes land under the prefix in the parent and reads resolve within the scope.
+package scopedctx
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/2389-research/tracker/pipeline"
+	"github.com/2389-research/tracker/pipeline/handlers"
+)
+
+func TestViewWritesLandUnderPrefix(t *testing.T) {
+	parent := pipeline.NewPipelineContext()
+	parent.Set("result", "parent result")
+
+	sub := WithPrefix(parent, "sub")
+	sub.Set("result", "sub result")
+	sub.Merge(map[string]string{"last_response": "ok"})
+
+	want := map[string]string{
+		"result":            "parent result",
+		"sub.result":        "sub result",
+		"sub.last_response": "ok",
+	}
+	if got := parent.Snapshot(); !maps.Equal(got, want) {
+		t.Errorf("parent snapshot = %v, want %v", got, want)
+	}
+}
+
+func TestViewReadsResolveWithinScope(t *testing.T) {
+	parent := pipeline.NewPipelineContext()
+	parent.Set("result", "parent result")
+	parent.Set("sub.result", "sub result")
+	parent.Set("subway", "not in scope")
+
+	sub := WithPrefix(parent, "sub.")
+	if v, ok := sub.Get("result"); !ok || v != "sub result" {
+		t.Errorf("Get(result) = %q, %v; want sub result", v, ok)
+	}
+	if _, ok := sub.Get("subway"); ok {
+		t.Error("keys outside the scope should not be visible")
+	}
+	if got, want := sub.Snapshot(), map[string]string{"result": "sub result"}; !maps.Equal(got, want) {
+		t.Errorf("scoped snapshot = %v, want %v", got, want)
+	}
+}
+
+func TestViewNesting(t *testing.T) {
+	parent := pipeline.NewPipelineContext()
+	inner := WithPrefix(parent, "sub").WithPrefix("inner")
+	inner.Set("result", "deep")
+
+	if inner.Prefix() != "sub.inner." {
+		t.Errorf("Prefix() = %q, want sub.inner.", inner.Prefix())
+	}
+	if v, _ := parent.Get("sub.inner.result"); v != "deep" {
+		t.Errorf("parent sub.inner.result = %q, want deep", v)
+	}
+	if v, _ := WithPrefix(parent, "sub").Get("inner.result"); v != "deep" {
+		t.Errorf("outer scope inner.result = %q, want deep", v)
+	}
+}
+
+// workHandler is a stub that sets result to its node's value attribute and
+// records the input it read.
+type workHandler struct {
+	inputs []string
+}
+
+func (h *workHandler) Name() string { return "work" }
+
+func (h *workHandler) Execute(_ context.Context, node *pipeline.Node, pctx *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	input, _ := pctx.Get("input")
+	h.inputs = append(h.inputs, input)
+	return pipeline.Outcome{Status: pipeline.OutcomeSuccess, ContextUpdates: map[string]string{"result": node.Attrs["value"]}}, nil
+}
+
+func TestHandlerScopesIncludedSubPipeline(t *testing.T) {
+	parent, err := pipeline.ParseDOT(`digraph parent {
+		start [shape=Mdiamond]
+		setup [type="work", value="parent"]
+		include [shape=tab, subgraph_ref="child"]
+		done [shape=Msquare]
+		start -> setup -> include -> done
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, err := pipeline.ParseDOT(`digraph child {
+		start [shape=Mdiamond]
+		build [type="work", value="child"]
+		done [shape=Msquare]
+		start -> build -> done
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	work := &workHandler{}
+	registry := handlers.NewDefaultRegistry(parent)
+	registry.Register(work)
+	registry.Register(Handler(pipeline.NewSubgraphHandler(map[string]*pipeline.Graph{"child": child}, registry), "sub"))
+	engine := pipeline.NewEngine(parent, registry,
+		pipeline.WithArtifactDir(t.TempDir()),
+		pipeline.WithInitialContext(map[string]string{"input": "parent input", "sub.input": "sub input"}))
+	result, err := engine.Run(context.Background())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if got := result.Context["result"]; got != "parent" {
+		t.Errorf("result = %q, want the parent's own output untouched", got)
+	}
+	if got := result.Context["sub.result"]; got != "child" {
+		t.Errorf("sub.result = %q, want the sub-pipeline's output under the prefix", got)
+	}
+	if want := []string{"parent input", "sub input"}; !slices.Equal(work.inputs, want) {
+		t.Errorf("inputs read = %q, want %q", work.inputs, want)
+	}
+}
@@ -0,0 +1,56 @@
+// ABOUTME: Applies a graph's shape_map attribute to a parsed tracker graph before it runs.
+// ABOUTME: The engine's parser only knows the default shapes, so handlers and start/exit nodes are re-resolved here, with unknown shapes running as codergen.
+package shapemap
+
+import (
+	"slices"
+
+	"github.com/2389-research/mammoth/dot"
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// Apply resolves node handlers and the start and exit nodes through the
+// graph's shape_map attribute (see dot.ShapeMapping), so a graph declaring
+// shape_map="doublecircle=start" runs from its doublecircle node. As with the
+// default shapes, an explicit type attribute still picks the handler, and a
+// node whose shape maps to nothing runs as codergen. Graphs without shape_map
+// keep the parser's start and exit nodes.
+func Apply(g *pipeline.Graph) error {
+	if g.Attrs[dot.ShapeMappingAttr] == "" {
+		for _, n := range g.Nodes {
+			if n.Handler == "" {
+				n.Handler = dot.DefaultNodeType
+			}
+		}
+		return nil
+	}
+	shapes, err := dot.GraphShapeMapping(g.Attrs)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(g.Nodes))
+	for id := range g.Nodes {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	g.StartNode, g.ExitNode = "", ""
+	for _, id := range ids {
+		n := g.Nodes[id]
+		typ, ok := shapes[n.Shape]
+		if !ok {
+			typ = dot.DefaultNodeType
+		}
+		if n.Attrs["type"] == "" {
+			n.Handler = typ
+		}
+		switch {
+		case typ == "start" && g.StartNode == "":
+			g.StartNode = id
+		case typ == "exit" && g.ExitNode == "":
+			g.ExitNode = id
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+// ABOUTME: Tests for shape_map on tracker graphs: a custom start shape runs, defaults are untouched, bad specs fail.
+// ABOUTME: Runs a real engine over a graph whose start and codergen nodes use remapped shapes.
+package shapemap
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/2389-research/tracker/pipeline"
+	"github.com/2389-research/tracker/pipeline/handlers"
+)
+
+// visitHandler stands in for codergen and records the nodes it ran.
+type visitHandler struct {
+	visited []string
+}
+
+func (h *visitHandler) Name() string { return "codergen" }
+
+func (h *visitHandler) Execute(_ context.Context, node *pipeline.Node, _ *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	h.visited = append(h.visited, node.ID)
+	return pipeline.Outcome{Status: pipeline.OutcomeSuccess}, nil
+}
+
+const customShapesDOT = `digraph p {
+	graph [shape_map="doublecircle=start, Mdiamond=, ellipse=codergen, doubleoctagon=exit"]
+	begin [shape=doublecircle]
+	legacy [shape=Mdiamond, type="codergen"]
+	plan [shape=ellipse, prompt="plan it"]
+	finish [shape=doubleoctagon]
+	begin -> legacy -> plan -> finish
+}`
+
+func TestApplyRunsCustomStartShape(t *testing.T) {
+	g, err := pipeline.ParseDOT(customShapesDOT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Apply(g); err != nil {
+		t.Fatal(err)
+	}
+	if g.StartNode != "begin" || g.ExitNode != "finish" {
+		t.Fatalf("start, exit = %q, %q; want begin, finish", g.StartNode, g.ExitNode)
+	}
+	if h := g.Nodes["plan"].Handler; h != "codergen" {
+		t.Errorf("plan handler = %q, want codergen", h)
+	}
+
+	registry := handlers.NewDefaultRegistry(g)
+	visits := &visitHandler{}
+	registry.Register(visits)
+	result, err := pipeline.NewEngine(g, registry).Run(context.Background())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if result.Status != pipeline.OutcomeSuccess {
+		t.Errorf("status = %q, want success", result.Status)
+	}
+	if want := []string{"legacy", "plan"}; !slices.Equal(visits.visited, want) {
+		t.Errorf("codergen ran %v, want %v", visits.visited, want)
+	}
+	if !slices.Contains(result.CompletedNodes, "begin") || !slices.Contains(result.CompletedNodes, "finish") {
+		t.Errorf("completed = %v, want begin and finish", result.CompletedNodes)
+	}
+}
+
+func TestApplyWithoutShapeMap(t *testing.T) {
+	g, err := pipeline.ParseDOT(`digraph p {
+	start [shape=Mdiamond]
+	done [shape=Msquare]
+	start -> done
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Apply(g); err != nil {
+		t.Fatal(err)
+	}
+	if g.StartNode != "start" || g.ExitNode != "done" {
+		t.Errorf("start, exit = %q, %q; want start, done", g.StartNode, g.ExitNode)
+	}
+}
+
+func TestApplyDefaultsUnknownShapesToCodergen(t *testing.T) {
+	for name, src := range map[string]string{
+		"default shapes": `digraph p {
+	start [shape=Mdiamond]
+	work [shape=ellipse, prompt="do it"]
+	done [shape=Msquare]
+	start -> work -> done
+}`,
+		"custom shapes": `digraph p {
+	graph [shape_map="doublecircle=start"]
+	start [shape=doublecircle]
+	work [shape=ellipse, prompt="do it"]
+	done [shape=Msquare]
+	start -> work -> done
+}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			g, err := pipeline.ParseDOT(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := Apply(g); err != nil {
+				t.Fatal(err)
+			}
+			if h := g.Nodes["work"].Handler; h != "codergen" {
+				t.Errorf("work handler = %q, want codergen", h)
+			}
+		})
+	}
+}
+
+func TestApplyInvalidShapeMap(t *testing.T) {
+	g, err := pipeline.ParseDOT(`digraph p {
+	graph [shape_map="doublecircle"]
+	start [shape=Mdiamond]
+	done [shape=Msquare]
+	start -> done
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Apply(g); err == nil {
+		t.Error("expected an error for a shape_map entry without a type")
+	}
+}
@@ -0,0 +1,81 @@
+// ABOUTME: Skipped node outcome: a skip_if="<condition>" attribute skips a node instead of running it.
+// ABOUTME: Skipped nodes route via condition="outcome = skipped" and are reported apart from completed ones.
+package skipif
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// Attr is the node attribute holding the condition under which the node is
+// skipped, e.g. skip_if="context.fast = true".
+const Attr = "skip_if"
+
+// Status is the outcome status of a node that was skipped rather than run.
+// Any handler may return it; the engine stores it in the outcome context key
+// like any other status, so edges select it with condition="outcome = skipped".
+const Status = "skipped"
+
+// Hook wraps the handlers of every node with a skip_if attribute so the
+// condition is evaluated against the pipeline context before the node runs.
+func Hook(g *pipeline.Graph) func(*pipeline.HandlerRegistry) {
+	return func(registry *pipeline.HandlerRegistry) {
+		wrapped := map[string]bool{}
+		for _, n := range g.Nodes {
+			if n.Attrs[Attr] == "" || wrapped[n.Handler] {
+				continue
+			}
+			if inner := registry.Get(n.Handler); inner != nil {
+				registry.Register(&skipHandler{inner: inner})
+				wrapped[n.Handler] = true
+			}
+		}
+	}
+}
+
+// skipHandler returns a skipped outcome for nodes whose skip_if condition
+// holds and runs the wrapped handler otherwise.
+type skipHandler struct {
+	inner pipeline.Handler
+}
+
+func (h *skipHandler) Name() string { return h.inner.Name() }
+
+func (h *skipHandler) Execute(ctx context.Context, node *pipeline.Node, pctx *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	if cond := node.Attrs[Attr]; cond != "" {
+		skip, err := pipeline.EvaluateCondition(cond, pctx)
+		if err != nil {
+			return pipeline.Outcome{}, fmt.Errorf("node %q: evaluate skip_if: %w", node.ID, err)
+		}
+		if skip {
+			return pipeline.Outcome{Status: Status}, nil
+		}
+	}
+	return h.inner.Execute(ctx, node, pctx)
+}
+
+// Split separates a run's skipped nodes from its completed ones. The engine
+// marks skipped nodes completed, so a node counts as skipped when its last
+// execution in the trace returned Status.
+func Split(result *pipeline.EngineResult) (completed, skipped []string) {
+	if result == nil {
+		return nil, nil
+	}
+	if result.Trace == nil {
+		return result.CompletedNodes, nil
+	}
+	last := map[string]string{}
+	for _, entry := range result.Trace.Entries {
+		last[entry.NodeID] = entry.Status
+	}
+	for _, id := range result.CompletedNodes {
+		if last[id] == Status {
+			skipped = append(skipped, id)
+		} else {
+			completed = append(completed, id)
+		}
+	}
+	return completed, skipped
+}
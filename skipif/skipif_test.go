@@ -0,0 +1,101 @@
+// ABOUTME: Tests for skip_if skipped outcomes and routing down condition="outcome = skipped" edges.
+// ABOUTME: Covers the handler wrapper, a full engine run, and separating skipped from completed nodes.
+package skipif
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/2389-research/tracker/pipeline"
+	"github.com/2389-research/tracker/pipeline/handlers"
+)
+
+// namedHandler is a stub handler that records the nodes it runs and applies
+// fixed context updates.
+type namedHandler struct {
+	name    string
+	updates map[string]string
+	ran     []string
+}
+
+func (h *namedHandler) Name() string { return h.name }
+
+func (h *namedHandler) Execute(_ context.Context, node *pipeline.Node, _ *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	h.ran = append(h.ran, node.ID)
+	return pipeline.Outcome{Status: pipeline.OutcomeSuccess, ContextUpdates: h.updates}, nil
+}
+
+func TestSkipIfHandler(t *testing.T) {
+	inner := &namedHandler{name: "work"}
+	h := &skipHandler{inner: inner}
+	node := &pipeline.Node{ID: "optional", Attrs: map[string]string{"skip_if": "context.foo = true"}}
+
+	pctx := pipeline.NewPipelineContext()
+	pctx.Set("foo", "true")
+	out, err := h.Execute(context.Background(), node, pctx)
+	if err != nil || out.Status != Status {
+		t.Fatalf("Execute = %+v, %v; want skipped", out, err)
+	}
+	if len(inner.ran) != 0 {
+		t.Errorf("skipped node should not run, ran %v", inner.ran)
+	}
+
+	pctx.Set("foo", "false")
+	if out, _ = h.Execute(context.Background(), node, pctx); out.Status != pipeline.OutcomeSuccess {
+		t.Errorf("status = %q, want success when skip_if is false", out.Status)
+	}
+	if !slices.Equal(inner.ran, []string{"optional"}) {
+		t.Errorf("ran = %v, want [optional]", inner.ran)
+	}
+}
+
+func TestSkipIfRoutesDownSkipEdge(t *testing.T) {
+	source := `digraph p {
+		start [shape=Mdiamond]
+		flag [type="setflag"]
+		optional [type="work", skip_if="context.foo = true"]
+		fallback [type="work"]
+		done [shape=Msquare]
+		start -> flag -> optional
+		optional -> done [condition="outcome = success"]
+		optional -> fallback [condition="outcome = skipped"]
+		fallback -> done
+	}`
+
+	for _, tt := range []struct {
+		flag        string
+		wantRan     []string
+		wantSkipped []string
+	}{
+		{flag: "true", wantRan: []string{"fallback"}, wantSkipped: []string{"optional"}},
+		{flag: "false", wantRan: []string{"optional"}},
+	} {
+		t.Run("foo="+tt.flag, func(t *testing.T) {
+			work := &namedHandler{name: "work"}
+			g, err := pipeline.ParseDOT(source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			registry := handlers.NewDefaultRegistry(g)
+			registry.Register(&namedHandler{name: "setflag", updates: map[string]string{"foo": tt.flag}})
+			registry.Register(work)
+			Hook(g)(registry)
+			result, err := pipeline.NewEngine(g, registry, pipeline.WithArtifactDir(t.TempDir())).Run(context.Background())
+			if err != nil {
+				t.Fatalf("run: %v", err)
+			}
+
+			if !slices.Equal(work.ran, tt.wantRan) {
+				t.Errorf("work ran %v, want %v", work.ran, tt.wantRan)
+			}
+			completed, skipped := Split(result)
+			if !slices.Equal(skipped, tt.wantSkipped) {
+				t.Errorf("skipped = %v, want %v", skipped, tt.wantSkipped)
+			}
+			if slices.Contains(completed, "optional") == (tt.flag == "true") {
+				t.Errorf("completed = %v: optional should be listed only when it ran", completed)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+// ABOUTME: Opt-in streaming of codergen output (stream="true"): assistant text deltas are published as they arrive.
+// ABOUTME: A codergen wrapper marks the node on the context; a Completer wrapper consumes the backend's Stream and publishes deltas.
+package streaming
+
+import (
+	"context"
+	"strings"
+
+	"github.com/2389-research/tracker/agent"
+	trackerllm "github.com/2389-research/tracker/llm"
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// ContextPrefix prefixes the context key holding a streaming node's text so
+// far: "stream.<nodeID>". Nodes running concurrently (for example in
+// a parallel branch) can read it while the writer is still generating.
+const ContextPrefix = "stream."
+
+// nodeStream publishes one streaming node's deltas into the pipeline context.
+type nodeStream struct {
+	key  string
+	pctx *pipeline.PipelineContext
+	text strings.Builder
+}
+
+// publish appends delta to the node's text and exposes the text so far.
+func (s *nodeStream) publish(delta string) {
+	s.text.WriteString(delta)
+	s.pctx.Set(s.key, s.text.String())
+}
+
+type nodeStreamKey struct{}
+
+// Hook wraps the codergen handler so nodes marked stream="true" publish
+// their assistant text as it is generated. The text is streamed only through
+// a client wrapped with Completer.
+func Hook(registry *pipeline.HandlerRegistry) {
+	if inner := registry.Get("codergen"); inner != nil {
+		registry.Register(&streamingHandler{inner: inner})
+	}
+}
+
+// streamingHandler attaches a nodeStream to the context of streaming nodes
+// before delegating to the wrapped codergen handler.
+type streamingHandler struct {
+	inner pipeline.Handler
+}
+
+func (h *streamingHandler) Name() string { return h.inner.Name() }
+
+func (h *streamingHandler) Execute(ctx context.Context, node *pipeline.Node, pctx *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	if !strings.EqualFold(strings.TrimSpace(node.Attrs["stream"]), "true") {
+		return h.inner.Execute(ctx, node, pctx)
+	}
+	stream := &nodeStream{key: ContextPrefix + node.ID, pctx: pctx}
+	return h.inner.Execute(context.WithValue(ctx, nodeStreamKey{}, stream), node, pctx)
+}
+
+// Streamer is implemented by backends that can stream a completion, such as
+// the tracker LLM client.
+type Streamer interface {
+	Stream(ctx context.Context, req *trackerllm.Request) <-chan trackerllm.StreamEvent
+}
+
+// Completer wraps inner so requests from streaming nodes are streamed. inner
+// must be the backend itself, or a wrapper that forwards Stream.
+func Completer(inner agent.Completer) agent.Completer {
+	return &streamingCompleter{inner: inner}
+}
+
+// streamingCompleter serves requests from streaming nodes through the wrapped
+// backend's Stream method, publishing text deltas as they arrive and
+// returning the accumulated response. Other requests, and backends that
+// cannot stream, use Complete. Client middleware does not apply to streamed
+// requests.
+type streamingCompleter struct {
+	inner agent.Completer
+}
+
+func (c *streamingCompleter) Complete(ctx context.Context, req *trackerllm.Request) (*trackerllm.Response, error) {
+	stream, ok := ctx.Value(nodeStreamKey{}).(*nodeStream)
+	s, canStream := c.inner.(Streamer)
+	if !ok || !canStream {
+		return c.inner.Complete(ctx, req)
+	}
+
+	// The stream is drained even after an error so wrappers forwarding it
+	// from their own goroutines are never left blocked on a send.
+	acc := trackerllm.NewStreamAccumulator()
+	var full *trackerllm.Response
+	var streamErr error
+	for evt := range s.Stream(ctx, req) {
+		if streamErr != nil {
+			continue
+		}
+		if evt.Err != nil {
+			streamErr = evt.Err
+			continue
+		}
+		if evt.Type == trackerllm.EventTextDelta && evt.Delta != "" {
+			stream.publish(evt.Delta)
+		}
+		if evt.FullResponse != nil {
+			full = evt.FullResponse
+		}
+		acc.Process(evt)
+	}
+	if streamErr != nil {
+		return nil, streamErr
+	}
+	resp := acc.Response()
+	if full != nil {
+		resp = *full
+	}
+	if resp.Model == "" {
+		resp.Model = req.Model
+	}
+	return &resp, nil
+}
+
+// ResponseStream replays a finished completion as a stream: its text as one
+// delta, then a finish event carrying the full response. Wrappers use it to
+// stream a response that did not come from a backend stream, such as a cache
+// hit or a backend without Stream.
+func ResponseStream(resp *trackerllm.Response, err error) <-chan trackerllm.StreamEvent {
+	ch := make(chan trackerllm.StreamEvent, 3)
+	defer close(ch)
+	if err != nil {
+		ch <- trackerllm.StreamEvent{Type: trackerllm.EventError, Err: err}
+		return ch
+	}
+	if resp == nil {
+		return ch
+	}
+	if text := resp.Text(); text != "" {
+		ch <- trackerllm.StreamEvent{Type: trackerllm.EventTextDelta, Delta: text}
+	}
+	ch <- trackerllm.StreamEvent{
+		Type:         trackerllm.EventFinish,
+		FinishReason: &resp.FinishReason,
+		Usage:        &resp.Usage,
+		FullResponse: resp,
+	}
+	return ch
+}
+
+// TeeStream forwards every event of in to the returned channel, calling
+// observe on each first and done once in is exhausted. The caller must drain
+// the returned channel, as Completer does.
+func TeeStream(in <-chan trackerllm.StreamEvent, observe func(trackerllm.StreamEvent), done func()) <-chan trackerllm.StreamEvent {
+	out := make(chan trackerllm.StreamEvent)
+	go func() {
+		defer close(out)
+		for evt := range in {
+			observe(evt)
+			out <- evt
+		}
+		done()
+	}()
+	return out
+}
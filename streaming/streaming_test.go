@@ -0,0 +1,110 @@
+// ABOUTME: Tests for opt-in streaming of codergen output into the pipeline context.
+// ABOUTME: A streaming stub backend checks that deltas are published before the node completes.
+package streaming
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	trackerllm "github.com/2389-research/tracker/llm"
+	"github.com/2389-research/tracker/pipeline"
+	"github.com/2389-research/tracker/pipeline/handlers"
+)
+
+// requestCapturingCompleter records every request and answers with plain text.
+type requestCapturingCompleter struct {
+	mu       sync.Mutex
+	requests []trackerllm.Request
+}
+
+func (c *requestCapturingCompleter) Complete(_ context.Context, req *trackerllm.Request) (*trackerllm.Response, error) {
+	c.mu.Lock()
+	c.requests = append(c.requests, *req)
+	c.mu.Unlock()
+	return &trackerllm.Response{
+		Message:      trackerllm.AssistantMessage("done"),
+		FinishReason: trackerllm.FinishReason{Reason: "stop"},
+	}, nil
+}
+
+// streamingStub is a backend that streams "Hel", "lo" and records what the
+// writer's stream context key held between the two deltas.
+type streamingStub struct {
+	requestCapturingCompleter
+
+	streamMu sync.Mutex
+	streamed int
+	midway   string
+}
+
+func (s *streamingStub) Stream(ctx context.Context, _ *trackerllm.Request) <-chan trackerllm.StreamEvent {
+	s.streamMu.Lock()
+	s.streamed++
+	s.streamMu.Unlock()
+
+	// Unbuffered, so each send completes only once the previous event has
+	// been consumed and published.
+	ch := make(chan trackerllm.StreamEvent)
+	go func() {
+		defer close(ch)
+		ch <- trackerllm.StreamEvent{Type: trackerllm.EventTextStart, TextID: "t"}
+		ch <- trackerllm.StreamEvent{Type: trackerllm.EventTextDelta, TextID: "t", Delta: "Hel"}
+		ch <- trackerllm.StreamEvent{Type: trackerllm.EventTextDelta, TextID: "t", Delta: "lo"}
+		if stream, ok := ctx.Value(nodeStreamKey{}).(*nodeStream); ok {
+			s.streamMu.Lock()
+			s.midway, _ = stream.pctx.Get(stream.key)
+			s.streamMu.Unlock()
+		}
+		ch <- trackerllm.StreamEvent{Type: trackerllm.EventTextEnd, TextID: "t"}
+		ch <- trackerllm.StreamEvent{Type: trackerllm.EventFinish, FinishReason: &trackerllm.FinishReason{Reason: "stop"}}
+	}()
+	return ch
+}
+
+func TestStreamingNodePublishesDeltas(t *testing.T) {
+	g, err := pipeline.ParseDOT(`digraph p {
+		start [shape=Mdiamond]
+		writer [shape=box, prompt="write", stream="true"]
+		reviewer [shape=box, prompt="review"]
+		end [shape=Msquare]
+		start -> writer -> reviewer -> end
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &streamingStub{}
+	dir := t.TempDir()
+	registry := handlers.NewDefaultRegistry(g, handlers.WithLLMClient(Completer(client), dir))
+	Hook(registry)
+	result, err := pipeline.NewEngine(g, registry, pipeline.WithArtifactDir(dir)).Run(context.Background())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if client.streamed == 0 {
+		t.Fatal("expected the streaming node to use the backend's Stream method")
+	}
+	if client.midway != "Hel" {
+		t.Errorf("stream context mid-generation = %q, want the first delta published before completion", client.midway)
+	}
+	if got := result.Context["stream.writer"]; got != "Hello" {
+		t.Errorf("stream.writer = %q, want the full streamed text", got)
+	}
+	if _, ok := result.Context["stream.reviewer"]; ok {
+		t.Error("non-streaming node should not publish a stream key")
+	}
+	if len(client.requests) == 0 {
+		t.Error("non-streaming node should use Complete")
+	}
+}
+
+func TestStreamingCompleterFallsBackWithoutStream(t *testing.T) {
+	inner := &requestCapturingCompleter{}
+	c := Completer(inner)
+	ctx := context.WithValue(context.Background(), nodeStreamKey{}, &nodeStream{key: "stream.n"})
+	resp, err := c.Complete(ctx, &trackerllm.Request{})
+	if err != nil || resp.Text() != "done" || len(inner.requests) != 1 {
+		t.Errorf("expected fallback to Complete, got resp=%v err=%v", resp, err)
+	}
+}
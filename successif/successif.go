@@ -0,0 +1,63 @@
+// ABOUTME: Per-node success criteria: a success_if="<condition>" attribute is checked after the handler returns.
+// ABOUTME: A successful outcome whose criterion does not hold becomes a failure, so fail edges and goal gates apply.
+package successif
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// Attr is the node attribute holding the condition a successful outcome
+// must also meet, e.g. success_if="context.tests_passed = true".
+const Attr = "success_if"
+
+// Hook wraps the handlers of every node with a success_if attribute so the
+// handler's reported success is checked against the condition.
+func Hook(g *pipeline.Graph) func(*pipeline.HandlerRegistry) {
+	return func(registry *pipeline.HandlerRegistry) {
+		wrapped := map[string]bool{}
+		for _, n := range g.Nodes {
+			if n.Attrs[Attr] == "" || wrapped[n.Handler] {
+				continue
+			}
+			if inner := registry.Get(n.Handler); inner != nil {
+				registry.Register(&checkedHandler{inner: inner})
+				wrapped[n.Handler] = true
+			}
+		}
+	}
+}
+
+// checkedHandler runs the wrapped handler and downgrades a successful
+// outcome to a failure when the node's success_if condition does not hold.
+type checkedHandler struct {
+	inner pipeline.Handler
+}
+
+func (h *checkedHandler) Name() string { return h.inner.Name() }
+
+func (h *checkedHandler) Execute(ctx context.Context, node *pipeline.Node, pctx *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	outcome, err := h.inner.Execute(ctx, node, pctx)
+	cond := node.Attrs[Attr]
+	if err != nil || cond == "" {
+		return outcome, err
+	}
+	if outcome.Status != pipeline.OutcomeSuccess && outcome.Status != "partial_success" {
+		return outcome, nil
+	}
+
+	// The engine merges the node's context updates only after it returns, so
+	// the condition sees them applied to a copy of the pipeline context.
+	view := pipeline.NewPipelineContextFrom(pctx.Snapshot())
+	view.Merge(outcome.ContextUpdates)
+	met, err := pipeline.EvaluateCondition(cond, view)
+	if err != nil {
+		return pipeline.Outcome{}, fmt.Errorf("node %q: evaluate success_if: %w", node.ID, err)
+	}
+	if !met {
+		outcome.Status = pipeline.OutcomeFail
+	}
+	return outcome, nil
+}
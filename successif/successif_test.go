@@ -0,0 +1,120 @@
+// ABOUTME: Tests for success_if criteria: a succeeding handler whose criterion fails is treated as a failure.
+// ABOUTME: Covers the handler wrapper, fail-edge routing in a full run, and goal gates.
+package successif
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/2389-research/tracker/pipeline"
+	"github.com/2389-research/tracker/pipeline/handlers"
+)
+
+// namedHandler is a stub handler that records the nodes it runs and applies
+// fixed context updates.
+type namedHandler struct {
+	name    string
+	updates map[string]string
+	ran     []string
+}
+
+func (h *namedHandler) Name() string { return h.name }
+
+func (h *namedHandler) Execute(_ context.Context, node *pipeline.Node, _ *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	h.ran = append(h.ran, node.ID)
+	return pipeline.Outcome{Status: pipeline.OutcomeSuccess, ContextUpdates: h.updates}, nil
+}
+
+// runGraph runs source with the given stub handlers registered and success_if
+// hooked in.
+func runGraph(t *testing.T, source string, stubs ...*namedHandler) *pipeline.EngineResult {
+	t.Helper()
+	g, err := pipeline.ParseDOT(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := handlers.NewDefaultRegistry(g)
+	for _, h := range stubs {
+		registry.Register(h)
+	}
+	Hook(g)(registry)
+	result, err := pipeline.NewEngine(g, registry, pipeline.WithArtifactDir(t.TempDir())).Run(context.Background())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	return result
+}
+
+func TestSuccessIfHandler(t *testing.T) {
+	node := &pipeline.Node{ID: "test", Attrs: map[string]string{"success_if": "context.tests_passed = true"}}
+
+	for _, tt := range []struct {
+		name    string
+		updates map[string]string
+		want    string
+	}{
+		{"criterion met by the node's own output", map[string]string{"tests_passed": "true"}, pipeline.OutcomeSuccess},
+		{"criterion not met", map[string]string{"tests_passed": "false"}, pipeline.OutcomeFail},
+		{"criterion key missing", nil, pipeline.OutcomeFail},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &checkedHandler{inner: &namedHandler{name: "work", updates: tt.updates}}
+			pctx := pipeline.NewPipelineContext()
+			out, err := h.Execute(context.Background(), node, pctx)
+			if err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			if out.Status != tt.want {
+				t.Errorf("status = %q, want %q", out.Status, tt.want)
+			}
+			if _, ok := pctx.Get("tests_passed"); ok {
+				t.Error("the pipeline context should not be modified by the check")
+			}
+		})
+	}
+
+	bad := &pipeline.Node{ID: "bad", Attrs: map[string]string{"success_if": "tests_passed"}}
+	h := &checkedHandler{inner: &namedHandler{name: "work"}}
+	if _, err := h.Execute(context.Background(), bad, pipeline.NewPipelineContext()); err == nil {
+		t.Error("expected an error for an invalid success_if condition")
+	}
+}
+
+func TestSuccessIfRoutesDownFailEdge(t *testing.T) {
+	source := `digraph p {
+		start [shape=Mdiamond]
+		test [type="work", success_if="context.tests_passed = true"]
+		fix [type="fixer"]
+		done [shape=Msquare]
+		start -> test
+		test -> done [condition="outcome = success"]
+		test -> fix [condition="outcome = fail"]
+		fix -> done
+	}`
+
+	work := &namedHandler{name: "work", updates: map[string]string{"tests_passed": "false"}}
+	fixer := &namedHandler{name: "fixer"}
+	result := runGraph(t, source, work, fixer)
+	if !slices.Equal(fixer.ran, []string{"fix"}) {
+		t.Errorf("fixer ran %v: a failed criterion should route down the fail edge", fixer.ran)
+	}
+	for _, entry := range result.Trace.Entries {
+		if entry.NodeID == "test" && entry.Status != pipeline.OutcomeFail {
+			t.Errorf("trace status for test = %q, want fail", entry.Status)
+		}
+	}
+}
+
+func TestSuccessIfFailsGoalGate(t *testing.T) {
+	source := `digraph p {
+		start [shape=Mdiamond]
+		test [type="work", goal_gate=true, success_if="context.tests_passed = true"]
+		done [shape=Msquare]
+		start -> test -> done
+	}`
+	result := runGraph(t, source, &namedHandler{name: "work", updates: map[string]string{"tests_passed": "false"}})
+	if result.Status == pipeline.OutcomeSuccess {
+		t.Errorf("run status = %q: an unmet success_if on a goal gate should fail the run", result.Status)
+	}
+}
@@ -0,0 +1,156 @@
+// ABOUTME: Run-wide token budget (token_budget graph attribute) split across codergen nodes by token_weight.
+// ABOUTME: Each node gets a sub-budget proportional to its weight and fails once its LLM usage exceeds it.
+package tokenbudget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/2389-research/mammoth/runstate"
+	"github.com/2389-research/tracker/agent"
+	trackerllm "github.com/2389-research/tracker/llm"
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// errNodeTokenBudget is returned to the agent loop when a node asks for
+// another completion after spending its allocation.
+var errNodeTokenBudget = errors.New("node token budget exceeded")
+
+// Allocations splits the graph's token_budget across its codergen nodes
+// in proportion to each node's token_weight (default 1). Shares are rounded
+// down, so the allocations never sum to more than the budget. Returns nil
+// when the graph sets no budget.
+func Allocations(g *pipeline.Graph) (map[string]int64, error) {
+	raw := strings.TrimSpace(g.Attrs["token_budget"])
+	if raw == "" {
+		return nil, nil
+	}
+	budget, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || budget <= 0 {
+		return nil, fmt.Errorf("graph token_budget must be a positive integer, got %q", raw)
+	}
+
+	weights := map[string]float64{}
+	var total float64
+	for id, n := range g.Nodes {
+		if n.Handler != "codergen" {
+			continue
+		}
+		w := 1.0
+		if raw := strings.TrimSpace(n.Attrs["token_weight"]); raw != "" {
+			w, err = strconv.ParseFloat(raw, 64)
+			if err != nil || w <= 0 {
+				return nil, fmt.Errorf("node %q: token_weight must be a positive number, got %q", id, raw)
+			}
+		}
+		weights[id] = w
+		total += w
+	}
+
+	allocs := make(map[string]int64, len(weights))
+	for id, w := range weights {
+		allocs[id] = int64(float64(budget) * w / total)
+	}
+	return allocs, nil
+}
+
+// Hook wraps the codergen handler so each node runs with a meter for its
+// allocation, as computed by Allocations. With no allocations it installs
+// nothing. Usage is only metered through a client wrapped with Completer.
+func Hook(allocs map[string]int64) func(*pipeline.HandlerRegistry) {
+	return func(registry *pipeline.HandlerRegistry) {
+		if len(allocs) == 0 {
+			return
+		}
+		if inner := registry.Get("codergen"); inner != nil {
+			registry.Register(&budgetHandler{inner: inner, allocs: allocs})
+		}
+	}
+}
+
+// tokenMeter counts the tokens one node execution has spent.
+type tokenMeter struct {
+	limit int64
+
+	mu   sync.Mutex
+	used int64
+}
+
+type tokenMeterKey struct{}
+
+func (m *tokenMeter) add(n int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.used += n
+}
+
+func (m *tokenMeter) spent() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.used
+}
+
+// budgetHandler carries a node's meter on the context and fails the
+// node when its usage ends up over the allocation.
+type budgetHandler struct {
+	inner  pipeline.Handler
+	allocs map[string]int64
+}
+
+func (h *budgetHandler) Name() string { return h.inner.Name() }
+
+func (h *budgetHandler) Execute(ctx context.Context, node *pipeline.Node, pctx *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	limit, ok := h.allocs[node.ID]
+	if !ok {
+		return h.inner.Execute(ctx, node, pctx)
+	}
+	meter := &tokenMeter{limit: limit}
+	out, err := h.inner.Execute(context.WithValue(ctx, tokenMeterKey{}, meter), node, pctx)
+	used := meter.spent()
+	if used <= limit && !errors.Is(err, errNodeTokenBudget) {
+		return out, err
+	}
+	updates := make(map[string]string, len(out.ContextUpdates)+1)
+	for k, v := range out.ContextUpdates {
+		updates[k] = v
+	}
+	updates[runstate.FailureReasonKey] = fmt.Sprintf("node %q token budget exceeded: used %d of its %d-token allocation", node.ID, used, limit)
+	return pipeline.Outcome{Status: pipeline.OutcomeFail, ContextUpdates: updates}, nil
+}
+
+// Completer wraps inner so the responses of a node running under Hook are
+// charged to its allocation.
+func Completer(inner agent.Completer) agent.Completer {
+	return &budgetCompleter{inner: inner}
+}
+
+// budgetCompleter charges every response to the node meter found on the
+// context and refuses further requests once the allocation is spent.
+type budgetCompleter struct {
+	inner agent.Completer
+}
+
+func (c *budgetCompleter) Complete(ctx context.Context, req *trackerllm.Request) (*trackerllm.Response, error) {
+	meter, _ := ctx.Value(tokenMeterKey{}).(*tokenMeter)
+	if meter != nil && meter.spent() >= meter.limit {
+		return nil, errNodeTokenBudget
+	}
+	resp, err := c.inner.Complete(ctx, req)
+	if meter != nil && resp != nil {
+		meter.add(ResponseTokens(resp.Usage))
+	}
+	return resp, err
+}
+
+// ResponseTokens returns the tokens a response consumed, summing input and
+// output when the provider reports no total.
+func ResponseTokens(u trackerllm.Usage) int64 {
+	if u.TotalTokens > 0 {
+		return int64(u.TotalTokens)
+	}
+	return int64(u.InputTokens + u.OutputTokens)
+}
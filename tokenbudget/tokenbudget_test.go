@@ -0,0 +1,161 @@
+// ABOUTME: Tests for the token_budget graph attribute and token_weight node sub-budgets.
+// ABOUTME: Covers the allocation math and that a greedy node is capped while others keep their share.
+package tokenbudget
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/2389-research/mammoth/runstate"
+	trackerllm "github.com/2389-research/tracker/llm"
+	"github.com/2389-research/tracker/pipeline"
+	"github.com/2389-research/tracker/pipeline/handlers"
+)
+
+func TestTokenAllocations(t *testing.T) {
+	g, err := pipeline.ParseDOT(`digraph p {
+		graph [token_budget="1000"]
+		start [shape=Mdiamond]
+		light [shape=box, prompt="a"]
+		heavy [shape=box, prompt="b", token_weight="3"]
+		check [shape=parallelogram, tool_command="true"]
+		done [shape=Msquare]
+		start -> light -> heavy -> check -> done
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	allocs, err := Allocations(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if allocs["light"] != 250 || allocs["heavy"] != 750 {
+		t.Errorf("allocations = %v, want light=250 heavy=750", allocs)
+	}
+	if _, ok := allocs["check"]; ok {
+		t.Error("non-codergen nodes should not get an allocation")
+	}
+}
+
+func TestTokenAllocationsRoundsDown(t *testing.T) {
+	g := &pipeline.Graph{
+		Attrs: map[string]string{"token_budget": "100"},
+		Nodes: map[string]*pipeline.Node{
+			"a": {ID: "a", Handler: "codergen", Attrs: map[string]string{}},
+			"b": {ID: "b", Handler: "codergen", Attrs: map[string]string{}},
+			"c": {ID: "c", Handler: "codergen", Attrs: map[string]string{}},
+		},
+	}
+	allocs, err := Allocations(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sum int64
+	for _, n := range allocs {
+		if n != 33 {
+			t.Errorf("allocation = %d, want 33", n)
+		}
+		sum += n
+	}
+	if sum > 100 {
+		t.Errorf("allocations sum to %d, over the budget", sum)
+	}
+}
+
+func TestTokenAllocationsInvalid(t *testing.T) {
+	for _, attrs := range []struct{ budget, weight string }{
+		{budget: "lots"},
+		{budget: "0"},
+		{budget: "100", weight: "0"},
+		{budget: "100", weight: "heavy"},
+	} {
+		g := &pipeline.Graph{
+			Attrs: map[string]string{"token_budget": attrs.budget},
+			Nodes: map[string]*pipeline.Node{
+				"a": {ID: "a", Handler: "codergen", Attrs: map[string]string{"token_weight": attrs.weight}},
+			},
+		}
+		if _, err := Allocations(g); err == nil {
+			t.Errorf("token_budget=%q token_weight=%q: expected error", attrs.budget, attrs.weight)
+		}
+	}
+	if allocs, err := Allocations(&pipeline.Graph{Attrs: map[string]string{}}); err != nil || allocs != nil {
+		t.Errorf("no budget: got %v, %v", allocs, err)
+	}
+}
+
+// fixedUsageCompleter answers every request with a fixed usage, charging more to
+// requests whose prompt mentions "greedy".
+type fixedUsageCompleter struct{}
+
+func (fixedUsageCompleter) Complete(_ context.Context, req *trackerllm.Request) (*trackerllm.Response, error) {
+	tokens := 40
+	for _, msg := range req.Messages {
+		if strings.Contains(msg.Text(), "greedy") {
+			tokens = 500
+		}
+	}
+	return &trackerllm.Response{
+		Message:      trackerllm.AssistantMessage("done"),
+		FinishReason: trackerllm.FinishReason{Reason: "stop"},
+		Usage:        trackerllm.Usage{TotalTokens: tokens},
+	}, nil
+}
+
+// nodeStatus returns the status of nodeID's first execution in the trace.
+func nodeStatus(result *pipeline.EngineResult, nodeID string) string {
+	for _, entry := range result.Trace.Entries {
+		if entry.NodeID == nodeID {
+			return entry.Status
+		}
+	}
+	return ""
+}
+
+func TestTokenBudgetCapsGreedyNode(t *testing.T) {
+	source := `digraph p {
+		graph [token_budget="300"]
+		start [shape=Mdiamond]
+		modest [shape=box, prompt="write a little"]
+		greedy [shape=box, prompt="be greedy", token_weight="2"]
+		done [shape=Msquare]
+		start -> modest -> greedy -> done
+	}`
+	g, err := pipeline.ParseDOT(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	allocs, err := Allocations(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	registry := handlers.NewDefaultRegistry(g, handlers.WithLLMClient(Completer(fixedUsageCompleter{}), dir))
+	Hook(allocs)(registry)
+	result, err := pipeline.NewEngine(g, registry, pipeline.WithArtifactDir(dir)).Run(context.Background())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if got := nodeStatus(result, "modest"); got != pipeline.OutcomeSuccess {
+		t.Errorf("modest status = %q, want success within its share", got)
+	}
+	if got := nodeStatus(result, "greedy"); got != pipeline.OutcomeFail {
+		t.Errorf("greedy status = %q, want fail over its allocation", got)
+	}
+	reason := result.Context[runstate.FailureReasonKey]
+	if !strings.Contains(reason, `node "greedy" token budget exceeded`) || !strings.Contains(reason, "200-token allocation") {
+		t.Errorf("failure reason = %q, want it to name the node and its allocation", reason)
+	}
+}
+
+func TestTokenBudgetCompleterRefusesWhenSpent(t *testing.T) {
+	meter := &tokenMeter{limit: 100}
+	meter.add(100)
+	ctx := context.WithValue(context.Background(), tokenMeterKey{}, meter)
+	c := Completer(fixedUsageCompleter{})
+	if _, err := c.Complete(ctx, &trackerllm.Request{}); err != errNodeTokenBudget {
+		t.Errorf("err = %v, want errNodeTokenBudget", err)
+	}
+}
@@ -0,0 +1,195 @@
+// ABOUTME: Minimal JSON Schema validation of tool-call arguments against a tool's declared parameters.
+// ABOUTME: Supports the subset used by tool definitions: type, properties, required, enum, items, additionalProperties.
+package toolguard
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// toolSchema is the subset of JSON Schema understood by validateArguments.
+type toolSchema struct {
+	Type                 any                    `json:"type"`
+	Properties           map[string]*toolSchema `json:"properties"`
+	Required             []string               `json:"required"`
+	Enum                 []any                  `json:"enum"`
+	Items                *toolSchema            `json:"items"`
+	AdditionalProperties any                    `json:"additionalProperties"`
+}
+
+// validateArguments checks parsed tool-call arguments against the tool's
+// JSON Schema parameters. An empty schema accepts anything. Keywords outside
+// the supported subset are ignored. The returned error lists every violation
+// found, so the model can correct all of them in a single retry.
+func validateArguments(schema json.RawMessage, args map[string]any) error {
+	if len(schema) == 0 {
+		return nil
+	}
+	var s toolSchema
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return fmt.Errorf("invalid tool schema: %w", err)
+	}
+
+	var problems []string
+	validateSchemaValue(&s, args, "", &problems)
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s", strings.Join(problems, "; "))
+}
+
+// validateSchemaValue appends a description of each way value violates s to
+// problems. path is the dotted location of value within the arguments.
+func validateSchemaValue(s *toolSchema, value any, path string, problems *[]string) {
+	if s == nil {
+		return
+	}
+	where := path
+	if where == "" {
+		where = "arguments"
+	}
+
+	if types := schemaTypes(s.Type); len(types) > 0 {
+		matched := false
+		for _, t := range types {
+			if valueHasType(value, t) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			*problems = append(*problems, fmt.Sprintf("%s must be %s, got %s", where, strings.Join(types, " or "), jsonTypeName(value)))
+			return
+		}
+	}
+
+	if len(s.Enum) > 0 && !enumContains(s.Enum, value) {
+		allowed := make([]string, len(s.Enum))
+		for i, e := range s.Enum {
+			b, _ := json.Marshal(e)
+			allowed[i] = string(b)
+		}
+		*problems = append(*problems, fmt.Sprintf("%s must be one of %s", where, strings.Join(allowed, ", ")))
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for _, name := range s.Required {
+			if field, ok := v[name]; !ok || field == nil {
+				*problems = append(*problems, fmt.Sprintf("missing required parameter: %s", joinSchemaPath(path, name)))
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if v[k] == nil {
+				continue
+			}
+			if prop, ok := s.Properties[k]; ok {
+				validateSchemaValue(prop, v[k], joinSchemaPath(path, k), problems)
+				continue
+			}
+			if allowed, ok := s.AdditionalProperties.(bool); ok && !allowed {
+				*problems = append(*problems, fmt.Sprintf("unknown parameter: %s", joinSchemaPath(path, k)))
+			}
+		}
+	case []any:
+		if s.Items != nil {
+			for i, item := range v {
+				validateSchemaValue(s.Items, item, fmt.Sprintf("%s[%d]", where, i), problems)
+			}
+		}
+	}
+}
+
+// schemaTypes normalizes the "type" keyword, which may be a string or a list.
+func schemaTypes(t any) []string {
+	switch v := t.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// valueHasType reports whether a decoded JSON value matches a JSON Schema type.
+func valueHasType(value any, t string) bool {
+	switch t {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == math.Trunc(f)
+	case "null":
+		return value == nil
+	}
+	// Unknown type keywords are not enforced.
+	return true
+}
+
+// jsonTypeName describes a decoded JSON value for error messages.
+func jsonTypeName(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// enumContains reports whether value deep-equals any enum entry.
+func enumContains(enum []any, value any) bool {
+	for _, e := range enum {
+		if reflect.DeepEqual(e, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// joinSchemaPath appends a property name to a dotted argument path.
+func joinSchemaPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
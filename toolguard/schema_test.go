@@ -0,0 +1,110 @@
+// ABOUTME: Tests for JSON Schema validation of tool-call arguments.
+// ABOUTME: Covers types, required fields, enums, nested objects, arrays, and additionalProperties.
+package toolguard
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/2389-research/tracker/agent/exec"
+	"github.com/2389-research/tracker/agent/tools"
+)
+
+func TestValidateArguments(t *testing.T) {
+	schema := json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"path": {"type": "string"},
+			"limit": {"type": "integer"},
+			"ratio": {"type": "number"},
+			"force": {"type": "boolean"},
+			"mode": {"type": "string", "enum": ["fast", "slow"]},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"opts": {
+				"type": "object",
+				"properties": {"depth": {"type": "integer"}},
+				"required": ["depth"],
+				"additionalProperties": false
+			}
+		},
+		"required": ["path"]
+	}`)
+
+	tests := []struct {
+		name    string
+		args    string
+		wantErr string
+	}{
+		{name: "minimal valid", args: `{"path":"a.go"}`},
+		{name: "all fields valid", args: `{"path":"a.go","limit":10,"ratio":0.5,"force":true,"mode":"fast","tags":["x"],"opts":{"depth":2}}`},
+		{name: "null optional ignored", args: `{"path":"a.go","limit":null}`},
+		{name: "unknown top-level allowed", args: `{"path":"a.go","extra":1}`},
+		{name: "missing required", args: `{}`, wantErr: "missing required parameter: path"},
+		{name: "wrong string type", args: `{"path":5}`, wantErr: "path must be string, got integer"},
+		{name: "fractional integer", args: `{"path":"a","limit":1.5}`, wantErr: "limit must be integer, got number"},
+		{name: "wrong boolean type", args: `{"path":"a","force":"yes"}`, wantErr: "force must be boolean, got string"},
+		{name: "enum mismatch", args: `{"path":"a","mode":"medium"}`, wantErr: `mode must be one of "fast", "slow"`},
+		{name: "array item type", args: `{"path":"a","tags":["x",2]}`, wantErr: "tags[1] must be string"},
+		{name: "nested required", args: `{"path":"a","opts":{}}`, wantErr: "missing required parameter: opts.depth"},
+		{name: "nested additionalProperties", args: `{"path":"a","opts":{"depth":1,"bogus":true}}`, wantErr: "unknown parameter: opts.bogus"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args map[string]any
+			if err := json.Unmarshal([]byte(tt.args), &args); err != nil {
+				t.Fatalf("bad test args: %v", err)
+			}
+			err := validateArguments(schema, args)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateArgumentsReportsAllProblems(t *testing.T) {
+	schema := json.RawMessage(`{"type":"object","properties":{"a":{"type":"string"},"b":{"type":"integer"}},"required":["a","b"]}`)
+	err := validateArguments(schema, map[string]any{"b": "two"})
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, want := range []string{"missing required parameter: a", "b must be integer, got string"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q missing %q", err, want)
+		}
+	}
+}
+
+func TestValidateArgumentsEmptySchema(t *testing.T) {
+	if err := validateArguments(nil, map[string]any{"anything": 1}); err != nil {
+		t.Errorf("empty schema should accept anything, got %v", err)
+	}
+}
+
+func TestBuiltInToolSchemasParse(t *testing.T) {
+	env := exec.NewLocalEnvironment(t.TempDir())
+	builtins := []tools.Tool{
+		tools.NewReadTool(env),
+		tools.NewWriteTool(env),
+		tools.NewEditTool(env),
+		tools.NewApplyPatchTool(env),
+		tools.NewGlobTool(env),
+		tools.NewGrepSearchTool(env),
+		tools.NewBashTool(env, time.Second, time.Minute),
+	}
+	for _, tool := range builtins {
+		var s toolSchema
+		if err := json.Unmarshal(tool.Parameters(), &s); err != nil {
+			t.Errorf("tool %s: schema does not parse: %v", tool.Name(), err)
+		}
+	}
+}
@@ -0,0 +1,396 @@
+// ABOUTME: Guards on the tool calls codergen agents make: argument validation plus limits scoped to each node by a hook.
+// ABOUTME: Completer checks arguments and cuts oversized tool results; Environment bounds how long each tool operation runs.
+package toolguard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/2389-research/tracker/agent"
+	"github.com/2389-research/tracker/agent/exec"
+	"github.com/2389-research/tracker/llm"
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// MaxResultBytesAttr caps the bytes of each tool result a codergen node's
+// agent sends back to the model. Set on a node, or on the graph as the
+// default for every codergen node.
+const MaxResultBytesAttr = "max_tool_result_bytes"
+
+// TimeoutAttr bounds how long each tool call of a codergen node's agent may
+// run, as a Go duration. Set on a node, or on the graph as the default.
+const TimeoutAttr = "tool_timeout"
+
+// resultsDir is the node artifact subdirectory holding the full output of
+// every tool result that was cut.
+const resultsDir = "tool_results"
+
+// limitAttrs lists the attributes Hook resolves for each codergen node.
+var limitAttrs = []string{MaxResultBytesAttr, TimeoutAttr}
+
+// limits are the tool-call limits of one codergen node execution.
+type limits struct {
+	maxResultBytes int
+	timeout        time.Duration
+	// dir receives the full output of cut tool results; empty when the run
+	// has no artifact directory.
+	dir string
+
+	mu    sync.Mutex
+	saved map[string]string // tool call ID -> artifact path
+}
+
+type limitsKey struct{}
+
+// Hook wraps the codergen handler so each node runs with the tool-call
+// limits its attributes set, falling back to the graph's. When neither g nor
+// any of its codergen nodes sets a limit it installs nothing.
+func Hook(g *pipeline.Graph) func(*pipeline.HandlerRegistry) {
+	return func(registry *pipeline.HandlerRegistry) {
+		if !hasLimits(g) {
+			return
+		}
+		if inner := registry.Get("codergen"); inner != nil {
+			registry.Register(&limitedHandler{inner: inner, graphAttrs: g.Attrs})
+		}
+	}
+}
+
+// hasLimits reports whether g or any of its codergen nodes sets a limit.
+func hasLimits(g *pipeline.Graph) bool {
+	for _, key := range limitAttrs {
+		if strings.TrimSpace(g.Attrs[key]) != "" {
+			return true
+		}
+		for _, n := range g.Nodes {
+			if n.Handler == "codergen" && strings.TrimSpace(n.Attrs[key]) != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// limitedHandler carries a node's limits on the context its agent session
+// runs with, where Completer and Environment find them.
+type limitedHandler struct {
+	inner      pipeline.Handler
+	graphAttrs map[string]string
+}
+
+func (h *limitedHandler) Name() string { return h.inner.Name() }
+
+func (h *limitedHandler) Execute(ctx context.Context, node *pipeline.Node, pctx *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	l, err := nodeLimits(node, h.graphAttrs)
+	if err != nil {
+		return pipeline.Outcome{}, err
+	}
+	if runDir, ok := pctx.GetInternal(pipeline.InternalKeyArtifactDir); ok && runDir != "" {
+		l.dir = filepath.Join(runDir, node.ID, resultsDir)
+	}
+	return h.inner.Execute(context.WithValue(ctx, limitsKey{}, l), node, pctx)
+}
+
+// nodeLimits resolves node's limits from its attributes and the graph's.
+func nodeLimits(node *pipeline.Node, graphAttrs map[string]string) (*limits, error) {
+	l := &limits{saved: map[string]string{}}
+	if raw := attr(node, graphAttrs, MaxResultBytesAttr); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("node %q: %s must be a positive integer, got %q", node.ID, MaxResultBytesAttr, raw)
+		}
+		l.maxResultBytes = n
+	}
+	if raw := attr(node, graphAttrs, TimeoutAttr); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("node %q: %s must be a positive duration, got %q", node.ID, TimeoutAttr, raw)
+		}
+		l.timeout = d
+	}
+	return l, nil
+}
+
+// attr returns the node's value for key, or the graph's when the node sets
+// none.
+func attr(node *pipeline.Node, graphAttrs map[string]string, key string) string {
+	if v := strings.TrimSpace(node.Attrs[key]); v != "" {
+		return v
+	}
+	return strings.TrimSpace(graphAttrs[key])
+}
+
+// maxArgumentRetries bounds how many times Completer sends a response's
+// invalid tool calls back to the model before handing the response over
+// as it is.
+const maxArgumentRetries = 2
+
+// Completer wraps inner for a codergen agent session. Tool calls whose
+// arguments do not match the tool's parameter schema never reach the tool:
+// the model gets a tool error result describing every problem and is asked
+// again, and events receives a tool_call_end event with ToolError
+// "validation failed" for each rejected call. After maxArgumentRetries the
+// response goes to the session as it is.
+//
+// Inside a node run under Hook, tool results longer than the node's
+// max_tool_result_bytes are also cut before the request reaches the model.
+// Only the copy sent to the model is cut: the agent session keeps the full
+// output in its history and tool events. The full output of each cut result
+// is saved under the node's artifact directory and the model is told where
+// to find it. events may be nil.
+func Completer(inner agent.Completer, events agent.EventHandler) agent.Completer {
+	if events == nil {
+		events = agent.NoopHandler
+	}
+	return &completer{inner: inner, events: events}
+}
+
+type completer struct {
+	inner  agent.Completer
+	events agent.EventHandler
+}
+
+func (c *completer) Complete(ctx context.Context, req *llm.Request) (*llm.Response, error) {
+	if l, _ := ctx.Value(limitsKey{}).(*limits); l != nil && l.maxResultBytes > 0 {
+		req = l.capResults(req)
+	}
+	resp, err := c.inner.Complete(ctx, req)
+	for retry := 0; err == nil && retry < maxArgumentRetries; retry++ {
+		results := c.checkArguments(req.Tools, resp)
+		if results == nil {
+			break
+		}
+		next := *req
+		next.Messages = append(slices.Clone(req.Messages), resp.Message, llm.Message{Role: llm.RoleTool, Content: results})
+		req = &next
+		resp, err = c.inner.Complete(ctx, req)
+	}
+	return resp, err
+}
+
+// checkArguments validates each tool call in resp against its definition in
+// defs. When any call is invalid it returns a tool result for every call in
+// the response, since each call needs one: an error describing the problems
+// for the invalid calls, and a note that the others were not run. It
+// returns nil when every call is valid. Calls to tools missing from defs are
+// left for the session to reject.
+func (c *completer) checkArguments(defs []llm.ToolDefinition, resp *llm.Response) []llm.ContentPart {
+	calls := resp.ToolCalls()
+	problems := make([]error, len(calls))
+	invalid := false
+	for i, call := range calls {
+		for _, def := range defs {
+			if def.Name == call.Name {
+				problems[i] = checkCall(def, call)
+				invalid = invalid || problems[i] != nil
+				break
+			}
+		}
+	}
+	if !invalid {
+		return nil
+	}
+
+	results := make([]llm.ContentPart, len(calls))
+	for i, call := range calls {
+		res := &llm.ToolResultData{ToolCallID: call.ID, Name: call.Name, IsError: true}
+		if problems[i] != nil {
+			res.Content = fmt.Sprintf("Tool error (%s): invalid arguments: %s. Fix the arguments to match the tool's parameter schema and call it again.", call.Name, problems[i])
+			c.events.HandleEvent(agent.Event{Type: agent.EventToolCallStart, Timestamp: time.Now(), ToolName: call.Name, ToolInput: string(call.Arguments)})
+			c.events.HandleEvent(agent.Event{Type: agent.EventToolCallEnd, Timestamp: time.Now(), ToolName: call.Name, ToolOutput: res.Content, ToolError: "validation failed"})
+		} else {
+			res.Content = fmt.Sprintf("Tool error (%s): not run because another tool call in this turn had invalid arguments. Call it again if it is still needed.", call.Name)
+		}
+		results[i] = llm.ContentPart{Kind: llm.KindToolResult, ToolResult: res}
+	}
+	return results
+}
+
+// checkCall validates one tool call's arguments against def's parameters.
+func checkCall(def llm.ToolDefinition, call llm.ToolCallData) error {
+	args := map[string]any{}
+	if len(call.Arguments) > 0 {
+		if err := json.Unmarshal(call.Arguments, &args); err != nil {
+			return fmt.Errorf("arguments are not a JSON object: %v", err)
+		}
+	}
+	return validateArguments(def.Parameters, args)
+}
+
+// capResults returns req with every oversized tool result cut, copying the
+// messages it changes so the session's history is left alone. It returns req
+// itself when nothing needs cutting.
+func (l *limits) capResults(req *llm.Request) *llm.Request {
+	var messages []llm.Message
+	for i, m := range req.Messages {
+		cloned := false
+		for j, part := range m.Content {
+			res := part.ToolResult
+			if res == nil || len(res.Content) <= l.maxResultBytes {
+				continue
+			}
+			if messages == nil {
+				messages = slices.Clone(req.Messages)
+			}
+			if !cloned {
+				messages[i].Content = slices.Clone(m.Content)
+				cloned = true
+			}
+			cut := *res
+			cut.Content = l.cut(res)
+			messages[i].Content[j].ToolResult = &cut
+		}
+	}
+	if messages == nil {
+		return req
+	}
+	out := *req
+	out.Messages = messages
+	return &out
+}
+
+// cut returns res's content trimmed to the byte limit, noting where the
+// full output was saved.
+func (l *limits) cut(res *llm.ToolResultData) string {
+	kept := truncateBytes(res.Content, l.maxResultBytes)
+	if path, err := l.save(res); err == nil && path != "" {
+		kept += "\nFull output saved to " + path
+	}
+	return kept
+}
+
+// save writes res's full output to the node's tool_results directory as
+// <tool>-<call id>.txt, once per call, and returns the file's path. It
+// returns "" when the run has no artifact directory.
+func (l *limits) save(res *llm.ToolResultData) (string, error) {
+	if l.dir == "" {
+		return "", nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if path, ok := l.saved[res.ToolCallID]; ok {
+		return path, nil
+	}
+	if err := os.MkdirAll(l.dir, 0o755); err != nil {
+		return "", err
+	}
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, res.Name+"-"+res.ToolCallID) + ".txt"
+	path := filepath.Join(l.dir, name)
+	if err := os.WriteFile(path, []byte(res.Content), 0o644); err != nil {
+		return "", err
+	}
+	l.saved[res.ToolCallID] = path
+	return path, nil
+}
+
+// truncateBytes keeps at most maxBytes of s, cut back to a UTF-8 rune
+// boundary, followed by a "[truncated N bytes]" marker.
+func truncateBytes(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + fmt.Sprintf("\n[truncated %d bytes]", len(s)-cut)
+}
+
+// errTimeout marks a tool operation abandoned at the node's tool_timeout. It
+// reaches the model as the tool's error result and shows in the
+// tool_call_end event's output.
+var errTimeout = errors.New("tool call timed out")
+
+// Environment wraps inner so that, inside a node run under Hook with a
+// tool_timeout, each command and file operation a tool makes is abandoned
+// once the timeout elapses and fails with a "tool call timed out" error.
+// Commands are also given the timeout so their process group is killed.
+// Operations outside such a node, including tool nodes, run unbounded.
+func Environment(inner exec.ExecutionEnvironment) exec.ExecutionEnvironment {
+	return &boundedEnv{inner: inner}
+}
+
+type boundedEnv struct {
+	inner exec.ExecutionEnvironment
+}
+
+func (e *boundedEnv) WorkingDir() string { return e.inner.WorkingDir() }
+
+func (e *boundedEnv) ReadFile(ctx context.Context, path string) (string, error) {
+	return bounded(ctx, func(ctx context.Context) (string, error) {
+		return e.inner.ReadFile(ctx, path)
+	})
+}
+
+func (e *boundedEnv) WriteFile(ctx context.Context, path string, content string) error {
+	_, err := bounded(ctx, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, e.inner.WriteFile(ctx, path, content)
+	})
+	return err
+}
+
+func (e *boundedEnv) ExecCommand(ctx context.Context, command string, args []string, timeout time.Duration) (exec.CommandResult, error) {
+	if l, _ := ctx.Value(limitsKey{}).(*limits); l != nil && l.timeout > 0 && timeout > l.timeout {
+		timeout = l.timeout
+	}
+	return bounded(ctx, func(ctx context.Context) (exec.CommandResult, error) {
+		return e.inner.ExecCommand(ctx, command, args, timeout)
+	})
+}
+
+func (e *boundedEnv) Glob(ctx context.Context, pattern string) ([]string, error) {
+	return bounded(ctx, func(ctx context.Context) ([]string, error) {
+		return e.inner.Glob(ctx, pattern)
+	})
+}
+
+// bounded runs op under the tool_timeout of the node on ctx, if any. When
+// the timeout elapses first it returns errTimeout without waiting for op,
+// which keeps running until it notices its context is done.
+func bounded[T any](ctx context.Context, op func(context.Context) (T, error)) (T, error) {
+	l, _ := ctx.Value(limitsKey{}).(*limits)
+	if l == nil || l.timeout <= 0 {
+		return op(ctx)
+	}
+	opCtx, cancel := context.WithTimeout(ctx, l.timeout)
+	defer cancel()
+
+	type result struct {
+		v   T
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		v, err := op(opCtx)
+		done <- result{v, err}
+	}()
+
+	var zero T
+	select {
+	case r := <-done:
+		if r.err != nil && ctx.Err() == nil && errors.Is(opCtx.Err(), context.DeadlineExceeded) {
+			return zero, fmt.Errorf("%w after %s", errTimeout, l.timeout)
+		}
+		return r.v, r.err
+	case <-opCtx.Done():
+		if err := ctx.Err(); err != nil {
+			return zero, err
+		}
+		return zero, fmt.Errorf("%w after %s", errTimeout, l.timeout)
+	}
+}
@@ -0,0 +1,276 @@
+// ABOUTME: Tests for tool-call limits: a real codergen agent session runs tools through the wrapped completer and environment.
+// ABOUTME: A scripted model issues tool calls and records the requests it receives.
+package toolguard
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/2389-research/tracker/agent"
+	"github.com/2389-research/tracker/agent/exec"
+	"github.com/2389-research/tracker/llm"
+	"github.com/2389-research/tracker/pipeline"
+	"github.com/2389-research/tracker/pipeline/handlers"
+)
+
+// scriptedModel replies with one queued response per request and records
+// every request it receives. Once the script runs out it ends the session.
+type scriptedModel struct {
+	mu       sync.Mutex
+	script   []*llm.Response
+	requests []*llm.Request
+}
+
+func (m *scriptedModel) Complete(_ context.Context, req *llm.Request) (*llm.Response, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, req)
+	if len(m.script) == 0 {
+		return &llm.Response{Message: llm.AssistantMessage("done")}, nil
+	}
+	resp := m.script[0]
+	m.script = m.script[1:]
+	return resp, nil
+}
+
+// toolCall returns a response asking for one call of the named tool.
+func toolCall(id, name string, args any) *llm.Response {
+	raw, _ := json.Marshal(args)
+	return &llm.Response{Message: llm.Message{
+		Role: llm.RoleAssistant,
+		Content: []llm.ContentPart{{
+			Kind:     llm.KindToolCall,
+			ToolCall: &llm.ToolCallData{ID: id, Name: name, Arguments: raw},
+		}},
+	}}
+}
+
+// toolResults returns every tool result in the request's messages.
+func toolResults(req *llm.Request) []*llm.ToolResultData {
+	var out []*llm.ToolResultData
+	for _, m := range req.Messages {
+		for _, part := range m.Content {
+			if part.ToolResult != nil {
+				out = append(out, part.ToolResult)
+			}
+		}
+	}
+	return out
+}
+
+// runAgentNode runs node "work" of dot through the real codergen handler
+// with model behind Completer, tools in Environment, and Hook installed. It
+// returns the run's artifact directory and the agent's tool-call end events.
+func runAgentNode(t *testing.T, dot string, model agent.Completer) (string, []agent.Event) {
+	t.Helper()
+	g, err := pipeline.ParseDOT(dot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	workDir := t.TempDir()
+	runDir := t.TempDir()
+
+	var mu sync.Mutex
+	var ends []agent.Event
+	events := agent.EventHandlerFunc(func(evt agent.Event) {
+		if evt.Type == agent.EventToolCallEnd {
+			mu.Lock()
+			ends = append(ends, evt)
+			mu.Unlock()
+		}
+	})
+	registry := handlers.NewDefaultRegistry(g,
+		handlers.WithLLMClient(Completer(model, events), workDir),
+		handlers.WithExecEnvironment(Environment(exec.NewLocalEnvironment(workDir))),
+		handlers.WithAgentEventHandler(events),
+	)
+	Hook(g)(registry)
+
+	pctx := pipeline.NewPipelineContext()
+	pctx.SetInternal(pipeline.InternalKeyArtifactDir, runDir)
+	out, err := registry.Execute(context.Background(), g.Nodes["work"], pctx)
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if out.Status != pipeline.OutcomeSuccess {
+		t.Fatalf("status = %q, want success", out.Status)
+	}
+	return runDir, ends
+}
+
+func TestCompleterCutsLargeToolResults(t *testing.T) {
+	model := &scriptedModel{script: []*llm.Response{
+		toolCall("call-1", "bash", map[string]string{"command": "printf 'x%.0s' $(seq 1 500)"}),
+	}}
+	runDir, ends := runAgentNode(t, `digraph p {
+		work [shape=box, prompt="dump", max_tool_result_bytes="100"]
+	}`, model)
+
+	if len(model.requests) != 2 {
+		t.Fatalf("model got %d requests, want 2", len(model.requests))
+	}
+	results := toolResults(model.requests[1])
+	if len(results) != 1 {
+		t.Fatalf("second request has %d tool results, want 1", len(results))
+	}
+	sent := results[0].Content
+	if !strings.HasPrefix(sent, strings.Repeat("x", 100)+"\n[truncated 400 bytes]") {
+		t.Errorf("model got %q, want 100 bytes and a truncation marker", sent)
+	}
+
+	artifact := filepath.Join(runDir, "work", "tool_results", "bash-call-1.txt")
+	if !strings.Contains(sent, artifact) {
+		t.Errorf("model got %q, want it to name %s", sent, artifact)
+	}
+	full, err := os.ReadFile(artifact)
+	if err != nil {
+		t.Fatalf("read artifact: %v", err)
+	}
+	if string(full) != strings.Repeat("x", 500) {
+		t.Errorf("artifact holds %d bytes, want the 500-byte output", len(full))
+	}
+
+	if len(ends) != 1 || ends[0].ToolOutput != strings.Repeat("x", 500) {
+		t.Errorf("tool_call_end events = %+v, want one carrying the full output", ends)
+	}
+}
+
+func TestGraphMaxResultBytesAppliesToEveryNode(t *testing.T) {
+	model := &scriptedModel{script: []*llm.Response{
+		toolCall("call-1", "bash", map[string]string{"command": "printf 'x%.0s' $(seq 1 50)"}),
+	}}
+	runAgentNode(t, `digraph p {
+		graph [max_tool_result_bytes="10"]
+		work [shape=box, prompt="dump"]
+	}`, model)
+
+	sent := toolResults(model.requests[1])[0].Content
+	if !strings.HasPrefix(sent, "xxxxxxxxxx\n[truncated 40 bytes]") {
+		t.Errorf("model got %q, want the graph's 10-byte cap", sent)
+	}
+}
+
+func TestCompleterLeavesSmallResultsAlone(t *testing.T) {
+	model := &scriptedModel{script: []*llm.Response{
+		toolCall("call-1", "bash", map[string]string{"command": "echo hi"}),
+	}}
+	runDir, _ := runAgentNode(t, `digraph p {
+		work [shape=box, prompt="greet", max_tool_result_bytes="100"]
+	}`, model)
+
+	if sent := toolResults(model.requests[1])[0].Content; sent != "hi\n" {
+		t.Errorf("model got %q, want the output unchanged", sent)
+	}
+	if _, err := os.Stat(filepath.Join(runDir, "work", "tool_results")); !os.IsNotExist(err) {
+		t.Errorf("tool_results dir exists (err=%v), want none for uncut output", err)
+	}
+}
+
+func TestHookRejectsInvalidMaxResultBytes(t *testing.T) {
+	g, err := pipeline.ParseDOT(`digraph p {
+		work [shape=box, prompt="dump", max_tool_result_bytes="lots"]
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := handlers.NewDefaultRegistry(g, handlers.WithLLMClient(Completer(&scriptedModel{}, nil), t.TempDir()))
+	Hook(g)(registry)
+
+	_, err = registry.Execute(context.Background(), g.Nodes["work"], pipeline.NewPipelineContext())
+	if err == nil || !strings.Contains(err.Error(), "max_tool_result_bytes must be a positive integer") {
+		t.Fatalf("err = %v, want an invalid max_tool_result_bytes error", err)
+	}
+}
+
+func TestToolTimeoutCancelsHungCommand(t *testing.T) {
+	model := &scriptedModel{script: []*llm.Response{
+		toolCall("call-1", "bash", map[string]any{"command": "sleep 30", "timeout": 60}),
+	}}
+	start := time.Now()
+	_, ends := runAgentNode(t, `digraph p {
+		work [shape=box, prompt="wait", tool_timeout="200ms"]
+	}`, model)
+
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("node took %s, want the hung command cut off at 200ms", elapsed)
+	}
+	if len(model.requests) != 2 {
+		t.Fatalf("model got %d requests, want the agent to continue after the timeout", len(model.requests))
+	}
+	res := toolResults(model.requests[1])[0]
+	if !res.IsError || !strings.Contains(res.Content, "tool call timed out after 200ms") {
+		t.Errorf("tool result = %+v, want a timeout error", res)
+	}
+	if len(ends) != 1 || ends[0].ToolError == "" || !strings.Contains(ends[0].ToolOutput, "tool call timed out") {
+		t.Errorf("tool_call_end events = %+v, want one marked as timed out", ends)
+	}
+}
+
+func TestHookRejectsInvalidToolTimeout(t *testing.T) {
+	g, err := pipeline.ParseDOT(`digraph p {
+		graph [tool_timeout="soon"]
+		work [shape=box, prompt="wait"]
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := handlers.NewDefaultRegistry(g, handlers.WithLLMClient(Completer(&scriptedModel{}, nil), t.TempDir()))
+	Hook(g)(registry)
+
+	_, err = registry.Execute(context.Background(), g.Nodes["work"], pipeline.NewPipelineContext())
+	if err == nil || !strings.Contains(err.Error(), "tool_timeout must be a positive duration") {
+		t.Fatalf("err = %v, want an invalid tool_timeout error", err)
+	}
+}
+
+func TestCompleterSendsInvalidArgumentsBackToTheModel(t *testing.T) {
+	model := &scriptedModel{script: []*llm.Response{
+		toolCall("call-1", "bash", map[string]string{"cmd": "echo hi"}),
+		toolCall("call-2", "bash", map[string]string{"command": "echo hi"}),
+	}}
+	_, ends := runAgentNode(t, `digraph p {
+		work [shape=box, prompt="greet"]
+	}`, model)
+
+	if len(model.requests) != 3 {
+		t.Fatalf("model got %d requests, want 3", len(model.requests))
+	}
+	corrective := toolResults(model.requests[1])
+	if len(corrective) != 1 || !corrective[0].IsError || corrective[0].ToolCallID != "call-1" ||
+		!strings.Contains(corrective[0].Content, "missing required parameter: command") {
+		t.Fatalf("corrective results = %+v, want a missing-command error for call-1", corrective)
+	}
+
+	// The session only saw the corrected call, which ran.
+	results := toolResults(model.requests[2])
+	if len(results) != 1 || results[0].ToolCallID != "call-2" || results[0].Content != "hi\n" {
+		t.Errorf("session results = %+v, want only call-2's output", results)
+	}
+
+	if len(ends) != 2 || ends[0].ToolError != "validation failed" || ends[1].ToolError != "" {
+		t.Errorf("tool_call_end events = %+v, want a validation failure then a success", ends)
+	}
+}
+
+func TestCompleterHandsOverCallsThatStayInvalid(t *testing.T) {
+	bad := toolCall("call-1", "bash", map[string]string{"cmd": "echo hi"})
+	model := &scriptedModel{script: []*llm.Response{bad, bad, bad}}
+	_, ends := runAgentNode(t, `digraph p {
+		work [shape=box, prompt="greet"]
+	}`, model)
+
+	// Two corrective rounds, then the session runs the call and the tool
+	// itself rejects it.
+	if len(model.requests) != 4 {
+		t.Fatalf("model got %d requests, want 4", len(model.requests))
+	}
+	if len(ends) != 3 || ends[2].ToolError != "true" {
+		t.Errorf("tool_call_end events = %+v, want two validation failures then the tool's own error", ends)
+	}
+}
@@ -0,0 +1,290 @@
+// ABOUTME: OpenTelemetry spans for pipeline runs: a root span per run and a child span per node with attempt events.
+// ABOUTME: Spans follow the engine's stage events; a handler hook and LLM completer wrapper add context and token counts.
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/2389-research/tracker/agent"
+	trackerllm "github.com/2389-research/tracker/llm"
+	"github.com/2389-research/tracker/pipeline"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// instrumentationName identifies mammoth's tracer to the TracerProvider.
+const instrumentationName = "github.com/2389-research/mammoth/tracing"
+
+// Span attribute keys. Token counts use the OpenTelemetry GenAI names.
+const (
+	AttrRunID        = attribute.Key("mammoth.run.id")
+	AttrPipeline     = attribute.Key("mammoth.pipeline")
+	AttrRunStatus    = attribute.Key("mammoth.run.status")
+	AttrNodeID       = attribute.Key("mammoth.node.id")
+	AttrNodeType     = attribute.Key("mammoth.node.type")
+	AttrNodeStatus   = attribute.Key("mammoth.node.status")
+	AttrNodeAttempts = attribute.Key("mammoth.node.attempts")
+	AttrInputTokens  = attribute.Key("gen_ai.usage.input_tokens")
+	AttrOutputTokens = attribute.Key("gen_ai.usage.output_tokens")
+)
+
+// Tracer starts run spans from a TracerProvider.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// New returns a Tracer using tp, or a no-op tracer when tp is nil.
+func New(tp trace.TracerProvider) *Tracer {
+	if tp == nil {
+		tp = noop.NewTracerProvider()
+	}
+	return &Tracer{tracer: tp.Tracer(instrumentationName)}
+}
+
+// RequestContext returns a background context carrying the W3C trace
+// context (traceparent header) of r, so a run started by the request joins
+// the caller's trace without being cancelled when the response is sent.
+func RequestContext(r *http.Request) context.Context {
+	return propagation.TraceContext{}.Extract(context.Background(), propagation.HeaderCarrier(r.Header))
+}
+
+// StartRun starts the root span of a pipeline run as a child of any span in
+// ctx. The returned context carries the run span.
+func (t *Tracer) StartRun(ctx context.Context, runID, pipelineName string) (context.Context, *Run) {
+	ctx, span := t.tracer.Start(ctx, "pipeline "+pipelineName, trace.WithAttributes(
+		AttrRunID.String(runID),
+		AttrPipeline.String(pipelineName),
+	))
+	return ctx, &Run{tracer: t.tracer, ctx: ctx, span: span, nodes: map[string]*nodeSpan{}}
+}
+
+// Run holds the spans of one pipeline run. It is a pipeline event handler:
+// stage_started opens a node span (or adds an attempt to the open one) and
+// stage_completed or stage_failed ends it. A nil *Run does nothing.
+type Run struct {
+	tracer trace.Tracer
+	ctx    context.Context
+	span   trace.Span
+
+	mu    sync.Mutex
+	nodes map[string]*nodeSpan
+	types map[string]string
+}
+
+// nodeSpan is the open span of one node and what its attempts reported.
+type nodeSpan struct {
+	span     trace.Span
+	attempts int
+	status   string
+	usage    *usage
+}
+
+// usage counts the LLM tokens a node has spent.
+type usage struct {
+	input, output atomic.Int64
+}
+
+type usageKey struct{}
+
+// HandlePipelineEvent updates the node spans for a stage event.
+func (r *Run) HandlePipelineEvent(evt pipeline.PipelineEvent) {
+	if r == nil || evt.NodeID == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ns := r.nodes[evt.NodeID]
+	switch evt.Type {
+	case pipeline.EventStageStarted:
+		if ns == nil {
+			_, span := r.tracer.Start(r.ctx, "node "+evt.NodeID, trace.WithTimestamp(evt.Timestamp), trace.WithAttributes(
+				AttrNodeID.String(evt.NodeID),
+				AttrNodeType.String(r.types[evt.NodeID]),
+			))
+			ns = &nodeSpan{span: span, usage: &usage{}}
+			r.nodes[evt.NodeID] = ns
+		}
+		ns.attempts++
+		ns.span.AddEvent("attempt", trace.WithTimestamp(evt.Timestamp), trace.WithAttributes(attribute.Int("attempt", ns.attempts)))
+	case pipeline.EventStageRetrying:
+		if ns != nil {
+			ns.span.AddEvent("retry", trace.WithTimestamp(evt.Timestamp), trace.WithAttributes(
+				attribute.Int("attempt", ns.attempts),
+				attribute.String("reason", eventReason(evt)),
+			))
+		}
+	case pipeline.EventStageCompleted:
+		// A resumed node is reported completed without ever starting; it
+		// has no span.
+		if ns != nil {
+			ns.end(pipeline.OutcomeSuccess, nil, evt.Timestamp)
+			delete(r.nodes, evt.NodeID)
+		}
+	case pipeline.EventStageFailed:
+		if ns != nil {
+			ns.end(pipeline.OutcomeFail, failure(evt), evt.Timestamp)
+			delete(r.nodes, evt.NodeID)
+		}
+	}
+}
+
+// End ends the run span with the run's final status; err or a fail status
+// marks the span as an error. Node spans still open, such as the node
+// running when the run was cancelled, end with it.
+func (r *Run) End(status string, err error) {
+	if r == nil {
+		return
+	}
+	now := time.Now()
+	r.mu.Lock()
+	for id, ns := range r.nodes {
+		ns.end(status, err, now)
+		delete(r.nodes, id)
+	}
+	r.mu.Unlock()
+
+	r.span.SetAttributes(AttrRunStatus.String(status))
+	if err != nil {
+		r.span.RecordError(err)
+		r.span.SetStatus(codes.Error, err.Error())
+	} else if status == pipeline.OutcomeFail {
+		r.span.SetStatus(codes.Error, "run failed")
+	}
+	r.span.End(trace.WithTimestamp(now))
+}
+
+// Hook wraps the handlers of g's nodes so each execution runs with its node
+// span and token counter on the context. Nodes run outside the engine's
+// stage events, such as parallel branches, get a span of their own nested
+// under the node that ran them. A nil *Run installs nothing.
+func (r *Run) Hook(g *pipeline.Graph) func(*pipeline.HandlerRegistry) {
+	return func(registry *pipeline.HandlerRegistry) {
+		if r == nil {
+			return
+		}
+		r.mu.Lock()
+		r.types = make(map[string]string, len(g.Nodes))
+		for id, n := range g.Nodes {
+			r.types[id] = n.Handler
+		}
+		r.mu.Unlock()
+
+		wrapped := map[string]bool{}
+		for _, n := range g.Nodes {
+			if wrapped[n.Handler] {
+				continue
+			}
+			if inner := registry.Get(n.Handler); inner != nil {
+				registry.Register(&tracedHandler{inner: inner, run: r})
+				wrapped[n.Handler] = true
+			}
+		}
+	}
+}
+
+// tracedHandler runs the wrapped handler inside its node's span.
+type tracedHandler struct {
+	inner pipeline.Handler
+	run   *Run
+}
+
+func (h *tracedHandler) Name() string { return h.inner.Name() }
+
+func (h *tracedHandler) Execute(ctx context.Context, node *pipeline.Node, pctx *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	h.run.mu.Lock()
+	ns := h.run.nodes[node.ID]
+	h.run.mu.Unlock()
+	if ns != nil {
+		ctx = context.WithValue(trace.ContextWithSpan(ctx, ns.span), usageKey{}, ns.usage)
+		out, err := h.inner.Execute(ctx, node, pctx)
+		h.run.mu.Lock()
+		ns.status = out.Status
+		h.run.mu.Unlock()
+		return out, err
+	}
+
+	ctx, span := h.run.tracer.Start(ctx, "node "+node.ID, trace.WithAttributes(
+		AttrNodeID.String(node.ID),
+		AttrNodeType.String(node.Handler),
+	))
+	ns = &nodeSpan{span: span, attempts: 1, usage: &usage{}}
+	span.AddEvent("attempt", trace.WithAttributes(attribute.Int("attempt", 1)))
+	out, err := h.inner.Execute(context.WithValue(ctx, usageKey{}, ns.usage), node, pctx)
+	ns.end(out.Status, err, time.Now())
+	return out, err
+}
+
+// end records the node's final attributes and ends its span. The status a
+// handler returned wins over the one implied by the stage event, so skipped
+// and partial outcomes are reported as such.
+func (ns *nodeSpan) end(status string, err error, at time.Time) {
+	if ns.status != "" && err == nil {
+		status = ns.status
+	}
+	ns.span.SetAttributes(
+		AttrNodeStatus.String(status),
+		AttrNodeAttempts.Int(ns.attempts),
+		AttrInputTokens.Int64(ns.usage.input.Load()),
+		AttrOutputTokens.Int64(ns.usage.output.Load()),
+	)
+	if err != nil {
+		ns.span.RecordError(err)
+		ns.span.SetStatus(codes.Error, err.Error())
+	} else if status == pipeline.OutcomeFail {
+		ns.span.SetStatus(codes.Error, "node failed")
+	}
+	ns.span.End(trace.WithTimestamp(at))
+}
+
+// failure returns the error a stage_failed event carries, or one built from
+// its message when the node failed without a handler error.
+func failure(evt pipeline.PipelineEvent) error {
+	if evt.Err != nil {
+		return evt.Err
+	}
+	return stageError(evt.Message)
+}
+
+// eventReason explains a retry, preferring the handler's error over the
+// engine's summary message.
+func eventReason(evt pipeline.PipelineEvent) string {
+	if evt.Err != nil {
+		return evt.Err.Error()
+	}
+	return evt.Message
+}
+
+// stageError is a node failure reported only by the engine's message.
+type stageError string
+
+func (e stageError) Error() string { return string(e) }
+
+// Completer wraps an LLM client so every response's token usage is added to
+// the node span on the request context.
+func Completer(inner agent.Completer) agent.Completer {
+	if inner == nil {
+		return nil
+	}
+	return &tokenCompleter{inner: inner}
+}
+
+// tokenCompleter charges response usage to the node found on the context.
+type tokenCompleter struct {
+	inner agent.Completer
+}
+
+func (c *tokenCompleter) Complete(ctx context.Context, req *trackerllm.Request) (*trackerllm.Response, error) {
+	resp, err := c.inner.Complete(ctx, req)
+	if u, ok := ctx.Value(usageKey{}).(*usage); ok && resp != nil {
+		u.input.Add(int64(resp.Usage.InputTokens))
+		u.output.Add(int64(resp.Usage.OutputTokens))
+	}
+	return resp, err
+}
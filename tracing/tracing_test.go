@@ -0,0 +1,269 @@
+// ABOUTME: Tests for run and node spans, recorded with an in-memory span exporter during real engine runs.
+// ABOUTME: Covers the root/child span tree, attempt and retry events, token counts, and parallel branch nesting.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	trackerllm "github.com/2389-research/tracker/llm"
+	"github.com/2389-research/tracker/pipeline"
+	"github.com/2389-research/tracker/pipeline/handlers"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// fixedCompleter answers every request with the same token usage.
+type fixedCompleter struct{ in, out int }
+
+func (c fixedCompleter) Complete(context.Context, *trackerllm.Request) (*trackerllm.Response, error) {
+	return &trackerllm.Response{Usage: trackerllm.Usage{InputTokens: c.in, OutputTokens: c.out}}, nil
+}
+
+// workHandler makes one LLM call per execution and fails the first
+// failFirst executions of each node with a retry outcome.
+type workHandler struct {
+	llm       *tokenCompleter
+	failFirst int
+
+	mu   sync.Mutex
+	runs map[string]int
+}
+
+func (h *workHandler) Name() string { return "work" }
+
+func (h *workHandler) Execute(ctx context.Context, node *pipeline.Node, _ *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	if _, err := h.llm.Complete(ctx, &trackerllm.Request{}); err != nil {
+		return pipeline.Outcome{}, err
+	}
+	h.mu.Lock()
+	h.runs[node.ID]++
+	n := h.runs[node.ID]
+	h.mu.Unlock()
+	if n <= h.failFirst {
+		return pipeline.Outcome{Status: pipeline.OutcomeRetry}, nil
+	}
+	return pipeline.Outcome{Status: pipeline.OutcomeSuccess}, nil
+}
+
+// runTraced executes source with tracing into an in-memory exporter and
+// returns the finished spans.
+func runTraced(t *testing.T, source string, work *workHandler) tracetest.SpanStubs {
+	t.Helper()
+	g, err := pipeline.ParseDOT(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	_, run := New(tp).StartRun(context.Background(), "run-1", g.Name)
+	registry := handlers.NewDefaultRegistry(g)
+	work.llm = Completer(fixedCompleter{in: 10, out: 3}).(*tokenCompleter)
+	work.runs = map[string]int{}
+	registry.Register(work)
+	run.Hook(g)(registry)
+
+	result, err := pipeline.NewEngine(g, registry, pipeline.WithPipelineEventHandler(run)).Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	run.End(result.Status, nil)
+	return exporter.GetSpans()
+}
+
+// findSpan returns the first span named name, failing the test when there
+// is none.
+func findSpan(t *testing.T, spans tracetest.SpanStubs, name string) tracetest.SpanStub {
+	t.Helper()
+	for _, s := range spans {
+		if s.Name == name {
+			return s
+		}
+	}
+	t.Fatalf("no span %q among %v", name, spanNames(spans))
+	return tracetest.SpanStub{}
+}
+
+func attr(s tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range s.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func eventCount(s tracetest.SpanStub, name string) int {
+	n := 0
+	for _, e := range s.Events {
+		if e.Name == name {
+			n++
+		}
+	}
+	return n
+}
+
+func TestRunProducesRootSpanWithNodeChildren(t *testing.T) {
+	spans := runTraced(t, `digraph build {
+		start [shape=Mdiamond]
+		plan [type="work"]
+		code [type="work"]
+		done [shape=Msquare]
+		start -> plan -> code -> done
+	}`, &workHandler{})
+
+	root := findSpan(t, spans, "pipeline build")
+	if root.Parent.IsValid() {
+		t.Errorf("run span has parent %v, want a root span", root.Parent)
+	}
+	if got := attr(root, AttrRunStatus).AsString(); got != pipeline.OutcomeSuccess {
+		t.Errorf("run status = %q, want success", got)
+	}
+
+	for _, id := range []string{"start", "plan", "code", "done"} {
+		node := findSpan(t, spans, "node "+id)
+		if node.Parent.SpanID() != root.SpanContext.SpanID() || node.SpanContext.TraceID() != root.SpanContext.TraceID() {
+			t.Errorf("node %s span is not a child of the run span", id)
+		}
+		if got := attr(node, AttrNodeStatus).AsString(); got != pipeline.OutcomeSuccess {
+			t.Errorf("node %s status = %q, want success", id, got)
+		}
+	}
+
+	plan := findSpan(t, spans, "node plan")
+	if got := attr(plan, AttrNodeType).AsString(); got != "work" {
+		t.Errorf("plan node type = %q, want work", got)
+	}
+	if in, out := attr(plan, AttrInputTokens).AsInt64(), attr(plan, AttrOutputTokens).AsInt64(); in != 10 || out != 3 {
+		t.Errorf("plan tokens = %d in, %d out; want 10 and 3", in, out)
+	}
+	if in := attr(findSpan(t, spans, "node start"), AttrInputTokens).AsInt64(); in != 0 {
+		t.Errorf("start node input tokens = %d, want 0", in)
+	}
+}
+
+func TestRetriedNodeRecordsAttemptEvents(t *testing.T) {
+	spans := runTraced(t, `digraph flaky {
+		start [shape=Mdiamond]
+		work [type="work", max_retries="3", retry_policy="none"]
+		done [shape=Msquare]
+		start -> work -> done
+	}`, &workHandler{failFirst: 2})
+
+	work := findSpan(t, spans, "node work")
+	if got := eventCount(work, "attempt"); got != 3 {
+		t.Errorf("attempt events = %d, want 3", got)
+	}
+	if got := eventCount(work, "retry"); got != 2 {
+		t.Errorf("retry events = %d, want 2", got)
+	}
+	if got := attr(work, AttrNodeAttempts).AsInt64(); got != 3 {
+		t.Errorf("attempts attribute = %d, want 3", got)
+	}
+	if in := attr(work, AttrInputTokens).AsInt64(); in != 30 {
+		t.Errorf("input tokens = %d, want 30 across three attempts", in)
+	}
+}
+
+func TestParallelBranchesNestUnderFanOutNode(t *testing.T) {
+	spans := runTraced(t, `digraph fan {
+		start [shape=Mdiamond]
+		fan [shape=component]
+		a [type="work"]
+		b [type="work"]
+		join [shape=tripleoctagon]
+		done [shape=Msquare]
+		start -> fan
+		fan -> a
+		fan -> b
+		a -> join
+		b -> join
+		join -> done
+	}`, &workHandler{})
+
+	// The engine also follows the fan node's first edge after the parallel
+	// handler returns, so only the branch executions nest under fan.
+	fan := findSpan(t, spans, "node fan")
+	branches := map[string]tracetest.SpanStub{}
+	for _, s := range spans {
+		if s.Parent.SpanID() == fan.SpanContext.SpanID() {
+			branches[s.Name] = s
+		}
+	}
+	for _, id := range []string{"a", "b"} {
+		branch, ok := branches["node "+id]
+		if !ok {
+			t.Errorf("no span for branch %s under fan; got %v", id, spanNames(spans))
+			continue
+		}
+		if in := attr(branch, AttrInputTokens).AsInt64(); in != 10 {
+			t.Errorf("branch %s input tokens = %d, want 10", id, in)
+		}
+	}
+}
+
+func TestFailedRunMarksSpansAsErrors(t *testing.T) {
+	g, _ := pipeline.ParseDOT(`digraph p {
+		start [shape=Mdiamond]
+		done [shape=Msquare]
+		start -> done
+	}`)
+	exporter := tracetest.NewInMemoryExporter()
+	_, run := New(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))).StartRun(context.Background(), "run-1", g.Name)
+	run.HandlePipelineEvent(pipeline.PipelineEvent{Type: pipeline.EventStageStarted, NodeID: "start"})
+	run.HandlePipelineEvent(pipeline.PipelineEvent{Type: pipeline.EventStageFailed, NodeID: "start", Err: errors.New("boom")})
+	run.End(pipeline.OutcomeFail, nil)
+
+	for _, s := range exporter.GetSpans() {
+		if s.Status.Code != codes.Error {
+			t.Errorf("span %q status = %v, want error", s.Name, s.Status.Code)
+		}
+	}
+	if len(exporter.GetSpans()) != 2 {
+		t.Errorf("spans = %d, want run and node", len(exporter.GetSpans()))
+	}
+}
+
+func TestRequestContextJoinsCallerTrace(t *testing.T) {
+	req := httptest.NewRequest("POST", "/pipelines", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	exporter := tracetest.NewInMemoryExporter()
+	_, run := New(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))).StartRun(RequestContext(req), "run-1", "p")
+	run.End(pipeline.OutcomeSuccess, nil)
+
+	root := exporter.GetSpans()[0]
+	if got := root.SpanContext.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace ID = %s, want the caller's", got)
+	}
+	if got := root.Parent.SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Errorf("parent span = %s, want the caller's span", got)
+	}
+}
+
+func TestNoopDefault(t *testing.T) {
+	ctx, run := New(nil).StartRun(context.Background(), "run-1", "p")
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		t.Error("no-op tracer should not produce a recording span context")
+	}
+	run.HandlePipelineEvent(pipeline.PipelineEvent{Type: pipeline.EventStageStarted, NodeID: "a"})
+	run.End(pipeline.OutcomeSuccess, nil)
+
+	var nilRun *Run
+	nilRun.HandlePipelineEvent(pipeline.PipelineEvent{Type: pipeline.EventStageStarted, NodeID: "a"})
+	nilRun.End(pipeline.OutcomeSuccess, nil)
+}
+
+func spanNames(spans tracetest.SpanStubs) []string {
+	var names []string
+	for _, s := range spans {
+		names = append(names, s.Name)
+	}
+	return names
+}
@@ -298,6 +298,20 @@ func (m AppModel) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// Group navigation applies while the graph panel has focus.
+	if m.focus == FocusGraph {
+		switch msg.String() {
+		case "[":
+			m.graph.SelectNextGroup(-1)
+		case "]":
+			m.graph.SelectNextGroup(1)
+		case " ", "enter":
+			if group := m.graph.SelectedGroup(); group != "" {
+				m.graph.ToggleGroup(group)
+			}
+		}
+	}
+
 	return m, nil
 }
 
@@ -327,6 +341,7 @@ func (m AppModel) buildNodeDetail(nodeID string, status NodeStatus) NodeDetail {
 				detail.Name = label
 			}
 			detail.HandlerType = shapeToHandlerType(node.Attrs["shape"])
+			detail.Description = node.Attrs["description"]
 		}
 	}
 
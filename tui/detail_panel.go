@@ -1,5 +1,5 @@
 // ABOUTME: Bubble Tea sub-model for displaying detailed information about the active pipeline node.
-// ABOUTME: Renders node name, handler type, status, duration, model, tool calls, tokens, and output.
+// ABOUTME: Renders node name, handler type, description, status, duration, model, tool calls, tokens, and output.
 package tui
 
 import (
@@ -12,6 +12,7 @@ import (
 type NodeDetail struct {
 	Name        string
 	HandlerType string
+	Description string // human-facing description attribute; never sent to the LLM
 	Status      NodeStatus
 	Duration    time.Duration
 	Model       string // LLM model name (from codergen outcome)
@@ -80,6 +81,9 @@ func (m DetailPanelModel) View() string {
 		lines = append(lines, title)
 		lines = append(lines, row("Name:", d.Name))
 		lines = append(lines, row("Handler:", d.HandlerType))
+		if d.Description != "" {
+			lines = append(lines, row("About:", d.Description))
+		}
 		lines = append(lines, LabelStyle.Render("Status:")+statusStr)
 		lines = append(lines, row("Model:", d.Model))
 		lines = append(lines, row("Tools:", fmt.Sprintf("%d calls", d.ToolCalls)))
@@ -3,9 +3,12 @@
 package tui
 
 import (
+	"context"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/2389-research/mammoth/dot"
 )
 
 func TestDetailPanel_NewDetailPanelModel(t *testing.T) {
@@ -300,3 +303,31 @@ func TestDetailPanel_View_ExactlyEightyCharsNotTruncated(t *testing.T) {
 		t.Errorf("expected exactly 80-char output without truncation, got:\n%s", view)
 	}
 }
+
+func TestDetailPanel_View_ShowsDescriptionFromDOT(t *testing.T) {
+	g, err := dot.Parse(`digraph p {
+		start [shape=Mdiamond]
+		review [shape=box, label="Review", prompt="Review the diff", description="Checks the change against the style guide"]
+		done [shape=Msquare]
+		start -> review -> done
+	}`)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	app := NewAppModel(g, nil, context.Background())
+
+	detail := app.buildNodeDetail("review", NodeRunning)
+	if detail.Description != "Checks the change against the style guide" {
+		t.Fatalf("Description = %q", detail.Description)
+	}
+	m := NewDetailPanelModel()
+	m.SetActiveNode(detail)
+	if view := m.View(); !strings.Contains(view, "About:") || !strings.Contains(view, "style guide") {
+		t.Errorf("expected description in view, got:\n%s", view)
+	}
+
+	m.SetActiveNode(app.buildNodeDetail("done", NodeRunning))
+	if view := m.View(); strings.Contains(view, "About:") {
+		t.Errorf("nodes without a description should not show an About row, got:\n%s", view)
+	}
+}
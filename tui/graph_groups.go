@@ -0,0 +1,157 @@
+// ABOUTME: Node grouping for the graph panel: sections from a node's group attribute or its DOT cluster.
+// ABOUTME: Groups can be selected and collapsed, leaving a one-line "name: done/total done" summary.
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/2389-research/mammoth/dot"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// GroupHeaderStyle styles group section headers; the selected group uses
+// SelectedGroupStyle.
+var (
+	GroupHeaderStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("110")).Bold(true)
+	SelectedGroupStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("170")).Bold(true)
+)
+
+// nodeGroups assigns each node to a group: its group attribute if set,
+// otherwise the label (or name, minus the "cluster" prefix) of the cluster
+// subgraph containing it. Groups are returned in the order their first node
+// appears in the topological levels.
+func nodeGroups(g *dot.Graph, levels [][]string) (map[string]string, []string) {
+	if g == nil {
+		return nil, nil
+	}
+
+	byNode := map[string]string{}
+	for _, sg := range g.Subgraphs {
+		if !strings.HasPrefix(sg.Name, "cluster") {
+			continue
+		}
+		name := sg.Attrs["label"]
+		if name == "" {
+			name = strings.TrimLeft(strings.TrimPrefix(sg.Name, "cluster"), "_-")
+		}
+		if name == "" {
+			continue
+		}
+		for _, id := range sg.NodeIDs {
+			byNode[id] = name
+		}
+	}
+	for id, n := range g.Nodes {
+		if group := n.Attrs["group"]; group != "" {
+			byNode[id] = group
+		}
+	}
+
+	var order []string
+	seen := map[string]bool{}
+	for _, level := range levels {
+		for _, id := range level {
+			if group := byNode[id]; group != "" && !seen[group] {
+				seen[group] = true
+				order = append(order, group)
+			}
+		}
+	}
+	return byNode, order
+}
+
+// Groups returns the panel's group names in display order.
+func (m GraphPanelModel) Groups() []string {
+	return m.groups
+}
+
+// SelectedGroup returns the name of the selected group, or "" if the graph
+// has no groups.
+func (m GraphPanelModel) SelectedGroup() string {
+	if len(m.groups) == 0 {
+		return ""
+	}
+	return m.groups[m.selectedGroup]
+}
+
+// SelectNextGroup moves the group selection by delta, wrapping around.
+func (m *GraphPanelModel) SelectNextGroup(delta int) {
+	if len(m.groups) == 0 {
+		return
+	}
+	n := len(m.groups)
+	m.selectedGroup = ((m.selectedGroup+delta)%n + n) % n
+}
+
+// ToggleGroup collapses an expanded group or expands a collapsed one.
+func (m *GraphPanelModel) ToggleGroup(name string) {
+	m.collapsed[name] = !m.collapsed[name]
+}
+
+// IsGroupCollapsed reports whether the named group is collapsed.
+func (m GraphPanelModel) IsGroupCollapsed(name string) bool {
+	return m.collapsed[name]
+}
+
+// renderGrouped writes nodes as sections: ungrouped nodes in topological
+// order, and each group as a header followed by its nodes (omitted when the
+// group is collapsed) at the position of the group's first node.
+func (m GraphPanelModel) renderGrouped(b *strings.Builder, levels [][]string) {
+	levelOf := map[string]int{}
+	var order []string
+	for i, level := range levels {
+		for _, id := range level {
+			levelOf[id] = i
+			order = append(order, id)
+		}
+	}
+	last := len(levels) - 1
+
+	rendered := map[string]bool{}
+	for _, id := range order {
+		group := m.nodeGroup[id]
+		if group == "" {
+			m.renderNode(b, id, levelOf[id] < last)
+			continue
+		}
+		if rendered[group] {
+			continue
+		}
+		rendered[group] = true
+
+		var members []string
+		for _, other := range order {
+			if m.nodeGroup[other] == group {
+				members = append(members, other)
+			}
+		}
+		b.WriteString(m.groupHeader(group, members))
+		b.WriteString("\n")
+		if m.collapsed[group] {
+			continue
+		}
+		for _, member := range members {
+			m.renderNode(b, member, levelOf[member] < last)
+		}
+	}
+}
+
+// groupHeader renders a group's summary line, e.g. "▾ build: 3/5 done".
+func (m GraphPanelModel) groupHeader(group string, members []string) string {
+	done := 0
+	for _, id := range members {
+		if m.GetNodeStatus(id) == NodeCompleted {
+			done++
+		}
+	}
+	arrow := "▾"
+	if m.collapsed[group] {
+		arrow = "▸"
+	}
+	style, marker := GroupHeaderStyle, " "
+	if group == m.SelectedGroup() {
+		style, marker = SelectedGroupStyle, ">"
+	}
+	return style.Render(fmt.Sprintf("%s%s %s: %d/%d done", marker, arrow, group, done, len(members)))
+}
@@ -0,0 +1,106 @@
+// ABOUTME: Tests for graph panel node grouping by cluster or group attribute.
+// ABOUTME: Covers group resolution, collapse toggling via AppModel keys, and the done/total summary line.
+package tui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/2389-research/mammoth/dot"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// groupedGraph returns start -> plan -> code -> test -> done, with code and
+// test in a "build" cluster and plan in a "design" group attribute.
+func groupedGraph() *dot.Graph {
+	return &dot.Graph{
+		Name: "grouped",
+		Nodes: map[string]*dot.Node{
+			"start": {ID: "start", Attrs: map[string]string{"shape": "Mdiamond"}},
+			"plan":  {ID: "plan", Attrs: map[string]string{"shape": "box", "label": "Plan", "group": "design"}},
+			"code":  {ID: "code", Attrs: map[string]string{"shape": "box", "label": "Code"}},
+			"test":  {ID: "test", Attrs: map[string]string{"shape": "box", "label": "Test"}},
+			"done":  {ID: "done", Attrs: map[string]string{"shape": "Msquare"}},
+		},
+		Edges: []*dot.Edge{
+			{From: "start", To: "plan"},
+			{From: "plan", To: "code"},
+			{From: "code", To: "test"},
+			{From: "test", To: "done"},
+		},
+		Subgraphs: []*dot.Subgraph{
+			{Name: "cluster_build", Attrs: map[string]string{}, NodeIDs: []string{"code", "test"}},
+		},
+	}
+}
+
+func TestGraphPanelGroupsFromClustersAndAttrs(t *testing.T) {
+	m := NewGraphPanelModel(groupedGraph())
+
+	groups := m.Groups()
+	if len(groups) != 2 || groups[0] != "design" || groups[1] != "build" {
+		t.Fatalf("groups = %v, want [design build] in topological order", groups)
+	}
+	if m.nodeGroup["code"] != "build" || m.nodeGroup["plan"] != "design" || m.nodeGroup["start"] != "" {
+		t.Errorf("unexpected node groups: %v", m.nodeGroup)
+	}
+
+	// A cluster label names the group, and a group attribute beats the cluster.
+	g := groupedGraph()
+	g.Subgraphs[0].Attrs["label"] = "Build Phase"
+	g.Nodes["test"].Attrs["group"] = "verify"
+	m = NewGraphPanelModel(g)
+	if m.nodeGroup["code"] != "Build Phase" || m.nodeGroup["test"] != "verify" {
+		t.Errorf("unexpected node groups: %v", m.nodeGroup)
+	}
+}
+
+func TestGraphPanelUngroupedGraphHasNoSections(t *testing.T) {
+	m := NewGraphPanelModel(testGraph())
+	if len(m.Groups()) != 0 {
+		t.Fatalf("expected no groups, got %v", m.Groups())
+	}
+	if strings.Contains(m.View(), "▾") {
+		t.Error("flat graph should not render group summaries")
+	}
+}
+
+func TestAppModelToggleGroupCollapse(t *testing.T) {
+	m := NewAppModel(groupedGraph(), nil, context.Background())
+	m.graph.SetNodeStatus("code", NodeCompleted)
+
+	view := m.graph.View()
+	for _, want := range []string{"design: 0/1 done", "build: 1/2 done", "Code", "Test"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("expanded view missing %q:\n%s", want, view)
+		}
+	}
+
+	// Select the second group ("build") and collapse it.
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("]")})
+	m = updated.(AppModel)
+	if m.graph.SelectedGroup() != "build" {
+		t.Fatalf("selected group = %q, want build", m.graph.SelectedGroup())
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	m = updated.(AppModel)
+	if !m.graph.IsGroupCollapsed("build") {
+		t.Fatal("expected build to be collapsed")
+	}
+
+	view = m.graph.View()
+	if strings.Contains(view, "Code (codergen)") || strings.Contains(view, "Test (codergen)") {
+		t.Errorf("collapsed group's nodes should be hidden:\n%s", view)
+	}
+	if !strings.Contains(view, "build: 1/2 done") || !strings.Contains(view, "Plan") {
+		t.Errorf("collapsed view should keep the summary and other nodes:\n%s", view)
+	}
+
+	// Toggling again restores the nodes.
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(AppModel)
+	if view = m.graph.View(); !strings.Contains(view, "Code (codergen)") || !strings.Contains(view, "Test (codergen)") {
+		t.Errorf("expanded view should list the group's nodes again:\n%s", view)
+	}
+}
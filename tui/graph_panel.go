@@ -17,14 +17,24 @@ type GraphPanelModel struct {
 	statuses     map[string]NodeStatus
 	spinnerIndex int
 	width        int
+
+	// Node grouping (see graph_groups.go). groups is empty for graphs with
+	// no clusters or group attributes, which render as a flat list.
+	nodeGroup     map[string]string
+	groups        []string
+	collapsed     map[string]bool
+	selectedGroup int
 }
 
 // NewGraphPanelModel creates a new graph panel for the given pipeline graph.
 func NewGraphPanelModel(g *dot.Graph) GraphPanelModel {
-	return GraphPanelModel{
-		graph:    g,
-		statuses: make(map[string]NodeStatus),
+	m := GraphPanelModel{
+		graph:     g,
+		statuses:  make(map[string]NodeStatus),
+		collapsed: make(map[string]bool),
 	}
+	m.nodeGroup, m.groups = nodeGroups(g, m.topologicalLevels())
+	return m
 }
 
 // SetNodeStatus updates a node's visual status.
@@ -69,45 +79,14 @@ func (m GraphPanelModel) View() string {
 
 	levels := m.topologicalLevels()
 
-	for levelIdx, level := range levels {
-		for _, nodeID := range level {
-			node := m.graph.FindNode(nodeID)
-			if node == nil {
-				continue
-			}
-
-			status := m.GetNodeStatus(nodeID)
-			style := StyleForStatus(status)
-			icon := status.Icon()
-			label := nodeLabel(node)
-			handlerType := shapeToHandlerType(node.Attrs["shape"])
-
-			var line string
-			if status == NodeRunning {
-				frame := SpinnerFrames[m.spinnerIndex%len(SpinnerFrames)]
-				line = fmt.Sprintf("  %s %s (%s) %s", icon, label, handlerType, frame)
-			} else {
-				line = fmt.Sprintf("  %s %s (%s)", icon, label, handlerType)
-			}
-
-			b.WriteString(style.Render(line))
-			b.WriteString("\n")
-
-			// Render outgoing edges (only to nodes in subsequent levels)
-			if levelIdx < len(levels)-1 {
-				outgoing := m.graph.OutgoingEdges(nodeID)
-				for _, edge := range outgoing {
-					targetNode := m.graph.FindNode(edge.To)
-					targetLabel := edge.To
-					if targetNode != nil {
-						targetLabel = nodeLabel(targetNode)
-					}
-					edgeLine := fmt.Sprintf("    --> %s", targetLabel)
-					b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render(edgeLine))
-					b.WriteString("\n")
-				}
+	if len(m.groups) == 0 {
+		for levelIdx, level := range levels {
+			for _, nodeID := range level {
+				m.renderNode(&b, nodeID, levelIdx < len(levels)-1)
 			}
 		}
+	} else {
+		m.renderGrouped(&b, levels)
 	}
 
 	content := b.String()
@@ -117,6 +96,47 @@ func (m GraphPanelModel) View() string {
 	return BorderStyle.Render(content)
 }
 
+// renderNode writes a node's status line and, when showEdges is set, its
+// outgoing edges.
+func (m GraphPanelModel) renderNode(b *strings.Builder, nodeID string, showEdges bool) {
+	node := m.graph.FindNode(nodeID)
+	if node == nil {
+		return
+	}
+
+	status := m.GetNodeStatus(nodeID)
+	style := StyleForStatus(status)
+	icon := status.Icon()
+	label := nodeLabel(node)
+	handlerType := shapeToHandlerType(node.Attrs["shape"])
+
+	var line string
+	if status == NodeRunning {
+		frame := SpinnerFrames[m.spinnerIndex%len(SpinnerFrames)]
+		line = fmt.Sprintf("  %s %s (%s) %s", icon, label, handlerType, frame)
+	} else {
+		line = fmt.Sprintf("  %s %s (%s)", icon, label, handlerType)
+	}
+
+	b.WriteString(style.Render(line))
+	b.WriteString("\n")
+
+	// Render outgoing edges (only to nodes in subsequent levels)
+	if showEdges {
+		outgoing := m.graph.OutgoingEdges(nodeID)
+		for _, edge := range outgoing {
+			targetNode := m.graph.FindNode(edge.To)
+			targetLabel := edge.To
+			if targetNode != nil {
+				targetLabel = nodeLabel(targetNode)
+			}
+			edgeLine := fmt.Sprintf("    --> %s", targetLabel)
+			b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render(edgeLine))
+			b.WriteString("\n")
+		}
+	}
+}
+
 // topologicalLevels computes topological levels using Kahn's algorithm (BFS).
 // Each level contains nodes that can run concurrently. Nodes within a level are sorted
 // alphabetically for deterministic output.
@@ -19,6 +19,10 @@ import (
 // maxAgentLines limits the number of agent log lines retained per node.
 const maxAgentLines = 20
 
+// forceQuitWindow is how soon after the first Ctrl+C a second one must come
+// to force an immediate cancel instead of waiting for the pause to finish.
+const forceQuitWindow = 2 * time.Second
+
 // ResumeInfo holds state from a previous run that is being resumed.
 type ResumeInfo struct {
 	ResumedFrom   string   // node label where we're resuming from
@@ -44,6 +48,21 @@ func WithResumeInfo(info *ResumeInfo) StreamOption {
 	}
 }
 
+// WithPause configures how the first Ctrl+C pauses the run. pause receives
+// the model's cancel func and should stop the engine so that it saves a
+// checkpoint before returning (e.g. via a cancellation grace window);
+// checkpointPath is shown so the user knows where the resumable state lives.
+// Without this option the first Ctrl+C cancels the engine's context and waits
+// for it to return.
+func WithPause(pause func(cancel context.CancelFunc), checkpointPath string) StreamOption {
+	return func(m *StreamModel) {
+		if pause != nil {
+			m.pause = pause
+		}
+		m.checkpointPath = checkpointPath
+	}
+}
+
 // StreamModel is an inline (non-alt-screen) Bubble Tea model that displays
 // pipeline progress as a streaming list of nodes with status indicators,
 // elapsed times, and an optional verbose agent event feed.
@@ -95,6 +114,13 @@ type StreamModel struct {
 	resumeInfo *ResumeInfo    // non-nil when resuming from a previous run
 	resumeCmd  func() tea.Cmd // override pipeline command for resume
 
+	// Interrupt state: the first Ctrl+C pauses, a second within
+	// forceQuitWindow cancels immediately.
+	pause          func(cancel context.CancelFunc)
+	checkpointPath string
+	pausing        bool
+	pausedAt       time.Time
+
 	width int
 }
 
@@ -140,6 +166,7 @@ func NewStreamModel(
 		nodeToolCalls: make(map[string]int),
 		total:         total,
 		resultCh:      make(chan PipelineResultMsg, 1),
+		pause:         func(cancel context.CancelFunc) { cancel() },
 	}
 
 	for _, opt := range opts {
@@ -408,15 +435,36 @@ func (m StreamModel) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	switch msg.String() {
 	case "ctrl+c":
+		return m.handleInterrupt()
+	}
+
+	return m, nil
+}
+
+// handleInterrupt pauses the run on the first Ctrl+C, leaving the program
+// running until the engine has checkpointed and returned. A second Ctrl+C
+// within forceQuitWindow cancels and quits immediately.
+func (m StreamModel) handleInterrupt() (tea.Model, tea.Cmd) {
+	now := time.Now()
+	if m.pausing && now.Sub(m.pausedAt) < forceQuitWindow {
 		m.cancel()
 		m.done = true
 		m.err = context.Canceled
 		return m, tea.Quit
 	}
-
+	if !m.pausing {
+		m.pausing = true
+		m.pause(m.cancel)
+	}
+	m.pausedAt = now
 	return m, nil
 }
 
+// Pausing reports whether a Ctrl+C pause has been requested.
+func (m StreamModel) Pausing() bool {
+	return m.pausing
+}
+
 // renderNodeLine renders a single node's status line.
 func (m StreamModel) renderNodeLine(id, label string, status NodeStatus) string {
 	switch status {
@@ -495,6 +543,10 @@ func (m StreamModel) renderProgressLine() string {
 	}
 
 	if m.done {
+		if m.err != nil && m.pausing {
+			return LogRetryStyle.Render(
+				fmt.Sprintf("  ⏸ %d/%d complete · %s · paused%s", completed, m.total, elapsedStr, m.checkpointSuffix()))
+		}
 		if m.err != nil {
 			return FailedStyle.Render(
 				fmt.Sprintf("  ✗ %d/%d complete · %s · FAILED: %v", completed, m.total, elapsedStr, m.err))
@@ -503,6 +555,15 @@ func (m StreamModel) renderProgressLine() string {
 			fmt.Sprintf("  ✓ %d/%d complete · %s%s", completed, m.total, elapsedStr, tokenSuffix))
 	}
 
+	if m.pausing {
+		hint := ""
+		if time.Since(m.pausedAt) < forceQuitWindow {
+			hint = " · press Ctrl+C again to force quit"
+		}
+		return LogRetryStyle.Render(
+			fmt.Sprintf("  ⏸ pausing after %d/%d complete%s%s", completed, m.total, m.checkpointSuffix(), hint))
+	}
+
 	nodeSuffix := ""
 	if label := m.currentNodeLabel(); label != "" {
 		nodeSuffix = fmt.Sprintf(" · %s", label)
@@ -512,6 +573,15 @@ func (m StreamModel) renderProgressLine() string {
 		fmt.Sprintf("  %d/%d complete · %s elapsed%s%s", completed, m.total, elapsedStr, tokenSuffix, nodeSuffix))
 }
 
+// checkpointSuffix names the checkpoint a paused run can resume from, or is
+// empty when no checkpoint path was configured.
+func (m StreamModel) checkpointSuffix() string {
+	if m.checkpointPath == "" {
+		return ""
+	}
+	return fmt.Sprintf(" · checkpoint %s", m.checkpointPath)
+}
+
 // renderSummary renders the post-run summary block with node counts, models,
 // tokens, tool calls, and total duration.
 func (m StreamModel) renderSummary() string {
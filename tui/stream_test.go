@@ -567,18 +567,81 @@ func TestStreamModelResultChannel(t *testing.T) {
 	}
 }
 
-func TestStreamModelCtrlCQuits(t *testing.T) {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
+func TestStreamModelCtrlCPausesThenForceQuits(t *testing.T) {
 	g := testStreamGraph()
-	m := NewStreamModel(g, nil, "test.dot", ctx, false)
+	paused := 0
+	m := NewStreamModel(g, nil, "test.dot", context.Background(), false,
+		WithPause(func(context.CancelFunc) { paused++ }, "/runs/abc/checkpoint.json"))
 
-	msg := tea.KeyMsg{Type: tea.KeyCtrlC}
-	_, cmd := m.Update(msg)
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	m = updated.(StreamModel)
+	if cmd != nil {
+		t.Fatal("expected the first ctrl+c to pause without quitting")
+	}
+	if paused != 1 || !m.Pausing() {
+		t.Fatalf("expected pause to be requested once, got %d (pausing=%v)", paused, m.Pausing())
+	}
+	if m.ctx.Err() != nil {
+		t.Fatal("expected the run context to stay live while pausing")
+	}
+	view := m.View()
+	if !strings.Contains(view, "press Ctrl+C again to force quit") {
+		t.Errorf("expected force-quit hint, got:\n%s", view)
+	}
+	if !strings.Contains(view, "/runs/abc/checkpoint.json") {
+		t.Errorf("expected checkpoint path in view, got:\n%s", view)
+	}
 
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	m = updated.(StreamModel)
 	if cmd == nil {
-		t.Fatal("expected quit command on ctrl+c")
+		t.Fatal("expected quit command on the second ctrl+c")
+	}
+	if paused != 1 {
+		t.Errorf("expected pause not to be requested again, got %d", paused)
+	}
+	if m.ctx.Err() == nil {
+		t.Error("expected the second ctrl+c to cancel the run context")
+	}
+	if m.err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", m.err)
+	}
+}
+
+func TestStreamModelCtrlCAfterWindowDoesNotForceQuit(t *testing.T) {
+	m := testStreamModel()
+	m.pause = func(context.CancelFunc) {}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	m = updated.(StreamModel)
+	m.pausedAt = time.Now().Add(-forceQuitWindow - time.Second)
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	m = updated.(StreamModel)
+	if cmd != nil {
+		t.Fatal("expected a late ctrl+c to re-arm the force-quit window instead of quitting")
+	}
+	if m.ctx.Err() != nil {
+		t.Error("expected the run context to stay live")
+	}
+}
+
+func TestStreamModelDefaultPauseCancelsAndWaitsForResult(t *testing.T) {
+	m := testStreamModel()
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	m = updated.(StreamModel)
+	if cmd != nil {
+		t.Fatal("expected the first ctrl+c to wait for the engine to return")
+	}
+	if m.ctx.Err() == nil {
+		t.Fatal("expected the default pause to cancel the run context")
+	}
+
+	updated, _ = m.Update(PipelineResultMsg{Err: context.Canceled})
+	m = updated.(StreamModel)
+	if !strings.Contains(m.View(), "paused") {
+		t.Errorf("expected paused summary, got:\n%s", m.View())
 	}
 }
 
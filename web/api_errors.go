@@ -0,0 +1,59 @@
+// ABOUTME: Machine-readable error codes for JSON error responses from the web server.
+// ABOUTME: Errors are written as {"error": "<message>", "code": "<code>"} so clients branch on codes, not prose.
+package web
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Error codes returned in the "code" field of JSON error responses. They are
+// stable: messages may be reworded, codes may not.
+const (
+	ErrCodeBadRequest       = "bad_request"
+	ErrCodeParse            = "parse_error"
+	ErrCodeValidation       = "validation_error"
+	ErrCodeNotFound         = "not_found"
+	ErrCodeConflict         = "conflict"
+	ErrCodeUnsupportedMedia = "unsupported_media_type"
+	ErrCodeTooLarge         = "request_too_large"
+	ErrCodeInternal         = "internal_error"
+	ErrCodeUnavailable      = "unavailable"
+)
+
+// Sentinel errors wrapped by TransitionEditorToBuild so callers can tell a
+// DOT that failed to parse from one that parsed but failed validation.
+var (
+	errDOTParse      = errors.New("DOT parse failed")
+	errDOTValidation = errors.New("DOT has validation errors")
+)
+
+// apiError is the JSON body of an error response.
+type apiError struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+// writeJSONError writes a JSON error response with the given status, code,
+// and human-readable message.
+func writeJSONError(w http.ResponseWriter, status int, code, msg string) {
+	writeSpecJSON(w, status, apiError{Error: msg, Code: code})
+}
+
+// writeError writes a JSON error response for API clients and a plain-text
+// one for browsers, as decided by wantsJSON.
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, msg string) {
+	if !wantsJSON(r) {
+		http.Error(w, msg, status)
+		return
+	}
+	writeJSONError(w, status, code, msg)
+}
+
+// dotErrorCode returns the error code for a TransitionEditorToBuild failure.
+func dotErrorCode(err error) string {
+	if errors.Is(err, errDOTParse) {
+		return ErrCodeParse
+	}
+	return ErrCodeValidation
+}
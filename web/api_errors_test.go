@@ -0,0 +1,82 @@
+// ABOUTME: Tests for the machine-readable "code" field on JSON error responses.
+// ABOUTME: Covers invalid DOT, a pipeline with no start node, unknown IDs, and browser plain-text fallback.
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// decodeAPIError decodes a JSON error response body.
+func decodeAPIError(t *testing.T, rec *httptest.ResponseRecorder) apiError {
+	t.Helper()
+	var resp apiError
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode error response: %v; body = %s", err, rec.Body.String())
+	}
+	if resp.Error == "" {
+		t.Errorf("error response has no message: %s", rec.Body.String())
+	}
+	return resp
+}
+
+func TestPipelineSubmitErrorCodes(t *testing.T) {
+	srv := newTestServer(t)
+
+	tests := []struct {
+		name   string
+		source string
+		status int
+		code   string
+	}{
+		{"invalid DOT", "digraph broken {", http.StatusUnprocessableEntity, ErrCodeParse},
+		{"missing start node", "digraph nostart {\n work [shape=box]\n done [shape=Msquare]\n work -> done\n}", http.StatusUnprocessableEntity, ErrCodeValidation},
+		{"empty source", "   ", http.StatusBadRequest, ErrCodeBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := postPipeline(srv, "text/plain", bytes.NewBufferString(tt.source))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d; body = %s", rec.Code, tt.status, rec.Body.String())
+			}
+			if got := decodeAPIError(t, rec).Code; got != tt.code {
+				t.Errorf("code = %q, want %q", got, tt.code)
+			}
+		})
+	}
+}
+
+func TestUnknownIDErrorCodes(t *testing.T) {
+	srv := newTestServer(t)
+
+	for _, path := range []string{"/projects/does-not-exist", "/projects/does-not-exist/build/events/summary"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept", "application/json")
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("%s: status = %d, want 404", path, rec.Code)
+		}
+		if got := decodeAPIError(t, rec).Code; got != ErrCodeNotFound {
+			t.Errorf("%s: code = %q, want %q", path, got, ErrCodeNotFound)
+		}
+	}
+}
+
+func TestWriteErrorPlainTextForBrowsers(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "text/html")
+	rec := httptest.NewRecorder()
+	writeError(rec, req, http.StatusNotFound, ErrCodeNotFound, "project not found")
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Content-Type = %q, want text/plain", ct)
+	}
+	if strings.TrimSpace(rec.Body.String()) != "project not found" {
+		t.Errorf("body = %q", rec.Body.String())
+	}
+}
@@ -6,8 +6,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/2389-research/mammoth/runstate"
 )
 
 // RunState tracks the lifecycle state of a pipeline run within the web layer.
@@ -19,7 +23,17 @@ type RunState struct {
 	CompletedAt    *time.Time `json:"completed_at,omitempty"`
 	CurrentNode    string     `json:"current_node"`
 	CompletedNodes []string   `json:"completed_nodes"`
+	SkippedNodes   []string   `json:"skipped_nodes,omitempty"`
 	Error          string     `json:"error,omitempty"`
+
+	// NodeAttempts holds each node's executions so far; a node with more
+	// than one record was retried.
+	NodeAttempts map[string][]runstate.AttemptRecord `json:"node_attempts,omitempty"`
+
+	// PrimaryOutput is the completed run's canonical answer and ResultNode
+	// the node that produced it; see runstate.OutputLog.Primary.
+	ResultNode    string `json:"result_node,omitempty"`
+	PrimaryOutput string `json:"primary_output,omitempty"`
 }
 
 // BuildRun holds all state for an active build, including the cancellation
@@ -30,32 +44,66 @@ type BuildRun struct {
 	Cancel context.CancelFunc
 	Ctx    context.Context
 
+	// Interviewer answers the build's human gates; nil until the build starts.
+	Interviewer *ChannelInterviewer
+
 	mu          sync.Mutex
 	subscribers map[int]chan SSEEvent
 	nextSubID   int
 	startOnce   sync.Once
 	closed      bool
 	history     []SSEEvent
+	dropped     int
+
+	// MaxHistory caps the events kept in memory for SSE replay; zero means
+	// DefaultMaxEventHistory. See trimHistory for what is kept.
+	MaxHistory int
 }
 
+// DefaultMaxEventHistory is how many events a build keeps for SSE replay
+// when no cap is configured.
+const DefaultMaxEventHistory = 300
+
+// subscriberBuffer is how many events a subscriber may fall behind before the
+// fanout drops it.
+const subscriberBuffer = 128
+
 // EnsureFanoutStarted starts a background broadcaster that fans Events out to
 // all subscribers. Safe to call multiple times.
+//
+// The broadcaster never blocks on a subscriber: one whose buffer is full is
+// dropped (its channel closed) with a logged warning, so a slow client cannot
+// stall the pipeline or other subscribers. A dropped SSE client sees the
+// stream end and can reconnect, replaying history.
 func (r *BuildRun) EnsureFanoutStarted() {
 	r.startOnce.Do(func() {
 		if r.Events == nil {
 			r.Events = make(chan SSEEvent, 100)
 		}
+		runID := ""
+		if r.State != nil {
+			runID = r.State.ID
+		}
 		go func() {
 			for evt := range r.Events {
 				r.mu.Lock()
 				r.history = append(r.history, evt)
-				if len(r.history) > 300 {
-					r.history = r.history[len(r.history)-300:]
+				limit := r.MaxHistory
+				if limit <= 0 {
+					limit = DefaultMaxEventHistory
+				}
+				if len(r.history) > limit {
+					var n int
+					r.history, n = trimHistory(r.history, limit)
+					r.dropped += n
 				}
-				for _, ch := range r.subscribers {
+				for id, ch := range r.subscribers {
 					select {
 					case ch <- evt:
 					default:
+						close(ch)
+						delete(r.subscribers, id)
+						log.Printf("component=web.build action=drop_slow_subscriber run_id=%s subscriber=%d buffer=%d", runID, id, subscriberBuffer)
 					}
 				}
 				r.mu.Unlock()
@@ -76,9 +124,53 @@ func (r *BuildRun) EnsureFanoutStarted() {
 func (r *BuildRun) HistorySnapshot() []SSEEvent {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	out := make([]SSEEvent, len(r.history))
-	copy(out, r.history)
-	return out
+	return r.historyLocked()
+}
+
+// historyLocked copies the replay history. Once events have been trimmed it
+// starts with a history.truncated event counting them; the full log stays in
+// the run's progress.ndjson. Callers hold r.mu.
+func (r *BuildRun) historyLocked() []SSEEvent {
+	out := make([]SSEEvent, 0, len(r.history)+1)
+	if r.dropped > 0 {
+		out = append(out, SSEEvent{
+			Event: "history.truncated",
+			Data:  fmt.Sprintf(`{"dropped":%d}`, r.dropped),
+		})
+	}
+	return append(out, r.history...)
+}
+
+// trimHistory shrinks history to at most limit events and reports how many
+// it dropped. The newest half of the cap is always kept; older events are
+// kept only if they are lifecycle events (see isLifecycleEvent), and if
+// those alone overflow the cap the oldest of them go too.
+func trimHistory(history []SSEEvent, limit int) ([]SSEEvent, int) {
+	before := len(history)
+	tailStart := before - limit/2
+	kept := history[:0]
+	for _, evt := range history[:tailStart] {
+		if isLifecycleEvent(evt.Event) {
+			kept = append(kept, evt)
+		}
+	}
+	kept = append(kept, history[tailStart:]...)
+	if len(kept) > limit {
+		kept = kept[len(kept)-limit:]
+	}
+	return kept, before - len(kept)
+}
+
+// isLifecycleEvent reports whether an SSE event marks a run or node state
+// change that a replaying client needs to rebuild the build view, as opposed
+// to agent chatter such as text deltas and tool calls.
+func isLifecycleEvent(name string) bool {
+	for _, prefix := range []string{"pipeline.", "stage.", "parallel.", "loop.", "human_gate."} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
 }
 
 // Subscribe registers a subscriber channel that receives all future events.
@@ -87,7 +179,7 @@ func (r *BuildRun) Subscribe() (<-chan SSEEvent, func()) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	ch := make(chan SSEEvent, 128)
+	ch := make(chan SSEEvent, subscriberBuffer)
 	if r.closed {
 		close(ch)
 		return ch, func() {}
@@ -115,10 +207,9 @@ func (r *BuildRun) SubscribeWithHistory() ([]SSEEvent, <-chan SSEEvent, func())
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	history := make([]SSEEvent, len(r.history))
-	copy(history, r.history)
+	history := r.historyLocked()
 
-	ch := make(chan SSEEvent, 128)
+	ch := make(chan SSEEvent, subscriberBuffer)
 	if r.closed {
 		close(ch)
 		return history, ch, func() {}
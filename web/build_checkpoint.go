@@ -0,0 +1,152 @@
+// ABOUTME: HTTP handlers for exporting and importing a build's raw tracker checkpoint.
+// ABOUTME: Enables moving a run between machines: download the checkpoint, import it elsewhere, and resume.
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/2389-research/mammoth/dot"
+	"github.com/2389-research/mammoth/runstate"
+	"github.com/2389-research/tracker/pipeline"
+	"github.com/go-chi/chi/v5"
+)
+
+// maxCheckpointUpload caps the size of an imported checkpoint.
+const maxCheckpointUpload = 8 << 20
+
+// checkpointPath returns the checkpoint file of a project's run.
+func (s *Server) checkpointPath(projectID, runID string) string {
+	return filepath.Join(s.workspace.CheckpointDir(projectID, runID), "checkpoint.json")
+}
+
+// handleBuildCheckpoint serves the raw checkpoint JSON of the project's
+// current run. Responds 404 when the run has not written a checkpoint.
+func (s *Server) handleBuildCheckpoint(w http.ResponseWriter, r *http.Request) {
+	projectID := chi.URLParam(r, "projectID")
+	p, ok := s.store.Get(projectID)
+	if !ok {
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project not found")
+		return
+	}
+	if p.RunID == "" {
+		http.Error(w, "no checkpoint", http.StatusNotFound)
+		return
+	}
+
+	data, err := os.ReadFile(s.checkpointPath(projectID, p.RunID))
+	if errors.Is(err, os.ErrNotExist) {
+		http.Error(w, "no checkpoint", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("component=web.build action=read_checkpoint_failed project_id=%s run_id=%s err=%v", projectID, p.RunID, err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", p.RunID+"-checkpoint.json"))
+	_, _ = w.Write(data)
+}
+
+// handleBuildCheckpointImport accepts a raw checkpoint JSON body and creates
+// a new run for the project seeded with it. The run is resumed the next time
+// the build view is opened.
+func (s *Server) handleBuildCheckpointImport(w http.ResponseWriter, r *http.Request) {
+	projectID := chi.URLParam(r, "projectID")
+	p, ok := s.store.Get(projectID)
+	if !ok {
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project not found")
+		return
+	}
+
+	s.buildsMu.RLock()
+	existing, running := s.builds[projectID]
+	running = running && existing.State != nil && existing.State.Status == "running"
+	s.buildsMu.RUnlock()
+	if running {
+		writeError(w, r, http.StatusConflict, ErrCodeConflict, "build is running")
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCheckpointUpload))
+	if err != nil {
+		http.Error(w, "checkpoint too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err := validateCheckpoint(data, p.DOT); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	runID, err := runstate.GenerateRunID()
+	if err != nil {
+		log.Printf("component=web.build action=generate_run_id_failed project_id=%s err=%v", projectID, err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	cpPath := s.checkpointPath(projectID, runID)
+	if err := os.MkdirAll(filepath.Dir(cpPath), 0o755); err != nil {
+		log.Printf("component=web.build action=create_checkpoint_dir_failed project_id=%s run_id=%s err=%v", projectID, runID, err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	if err := runstate.WriteFileAtomic(cpPath, data, 0o600); err != nil {
+		log.Printf("component=web.build action=write_checkpoint_failed project_id=%s run_id=%s err=%v", projectID, runID, err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	p.Phase = PhaseBuild
+	p.RunID = runID
+	p.Diagnostics = nil
+	if err := s.store.Update(p); err != nil {
+		log.Printf("component=web.build action=update_project_failed project_id=%s phase=build err=%v", projectID, err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("component=web.build action=checkpoint_imported project_id=%s run_id=%s", projectID, runID)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]string{"run_id": runID})
+}
+
+// validateCheckpoint checks that data is a checkpoint this version of the
+// tracker engine can resume for the given pipeline. Tracker checkpoints carry
+// no explicit version number, so the format is checked strictly: unknown
+// fields indicate a checkpoint written by an incompatible engine.
+func validateCheckpoint(data []byte, source string) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var cp pipeline.Checkpoint
+	if err := dec.Decode(&cp); err != nil {
+		return fmt.Errorf("unsupported checkpoint format: %v", err)
+	}
+	if cp.RunID == "" {
+		return fmt.Errorf("unsupported checkpoint format: missing run_id")
+	}
+
+	g, err := dot.Parse(source)
+	if err != nil {
+		return fmt.Errorf("project pipeline does not parse: %v", err)
+	}
+	nodes := append([]string{}, cp.CompletedNodes...)
+	if cp.CurrentNode != "" {
+		nodes = append(nodes, cp.CurrentNode)
+	}
+	for _, id := range nodes {
+		if g.FindNode(id) == nil {
+			return fmt.Errorf("checkpoint references node %q, which is not in the project pipeline", id)
+		}
+	}
+	return nil
+}
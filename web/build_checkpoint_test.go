@@ -0,0 +1,147 @@
+// ABOUTME: Tests for exporting and importing a build's raw tracker checkpoint over HTTP.
+// ABOUTME: Covers download, missing checkpoints, format validation, and an import that resumes to completion.
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const checkpointTestDOT = `digraph p {
+	start [shape=Mdiamond]
+	work [shape=box, prompt="do the work"]
+	done [shape=Msquare]
+	start -> work -> done
+}`
+
+// newCheckpointTestProject creates a project whose pipeline is checkpointTestDOT.
+func newCheckpointTestProject(t *testing.T, srv *Server) *Project {
+	t.Helper()
+	p, err := srv.store.Create("checkpoint-test")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	p.DOT = checkpointTestDOT
+	if err := srv.store.Update(p); err != nil {
+		t.Fatalf("update project: %v", err)
+	}
+	return p
+}
+
+func TestBuildCheckpointDownload(t *testing.T) {
+	srv := newTestServer(t)
+	p := newCheckpointTestProject(t, srv)
+	p.RunID = "run-1"
+	if err := srv.store.Update(p); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/projects/"+p.ID+"/build/checkpoint", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status before checkpoint = %d, want 404", rec.Code)
+	}
+
+	raw := `{"run_id":"run-1","current_node":"work","completed_nodes":["start"],"retry_counts":{},"context":{"k":"v"}}`
+	cpPath := srv.checkpointPath(p.ID, "run-1")
+	if err := os.MkdirAll(filepath.Dir(cpPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cpPath, []byte(raw), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/projects/"+p.ID+"/build/checkpoint", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if rec.Body.String() != raw {
+		t.Errorf("body = %s, want the raw checkpoint", rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+}
+
+func TestBuildCheckpointImportRejectsInvalid(t *testing.T) {
+	srv := newTestServer(t)
+	p := newCheckpointTestProject(t, srv)
+
+	for name, body := range map[string]string{
+		"not json":     `nope`,
+		"unknown keys": `{"run_id":"r","version":2}`,
+		"no run id":    `{"current_node":"work"}`,
+		"foreign node": `{"run_id":"r","current_node":"elsewhere"}`,
+	} {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/projects/"+p.ID+"/build/checkpoint", strings.NewReader(body)))
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: status = %d, want 422", name, rec.Code)
+		}
+	}
+	if got, _ := srv.store.Get(p.ID); got.RunID != "" {
+		t.Errorf("rejected import should not create a run, got run %q", got.RunID)
+	}
+}
+
+func TestBuildCheckpointImportRoundTrip(t *testing.T) {
+	srv := newTestServer(t)
+	p := newCheckpointTestProject(t, srv)
+
+	// The work node is already complete, so resuming goes straight to the exit
+	// without needing an LLM backend.
+	raw := `{"run_id":"exported-run","current_node":"done","completed_nodes":["start","work"],"retry_counts":{},"context":{"last_response":"built"}}`
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/projects/"+p.ID+"/build/checkpoint", strings.NewReader(raw)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("import status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	imported, _ := srv.store.Get(p.ID)
+	if imported.Phase != PhaseBuild || imported.RunID == "" {
+		t.Fatalf("imported project = phase %q run %q, want a pending build", imported.Phase, imported.RunID)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/projects/"+p.ID+"/build/checkpoint", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != raw {
+		t.Fatalf("download after import = %d %s, want the imported checkpoint", rec.Code, rec.Body.String())
+	}
+
+	// Opening the build view resumes the pending run from the checkpoint.
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/projects/"+p.ID+"/build", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("build view status = %d", rec.Code)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		srv.buildsMu.RLock()
+		run := srv.builds[p.ID]
+		status, errMsg := run.State.Status, run.State.Error
+		completed := append([]string{}, run.State.CompletedNodes...)
+		srv.buildsMu.RUnlock()
+		if status == "completed" {
+			for _, id := range completed {
+				if id == "work" {
+					t.Errorf("work was re-executed on resume: %v", completed)
+				}
+			}
+			return
+		}
+		if status != "running" {
+			t.Fatalf("resumed build status = %q (%s), want completed", status, errMsg)
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the resumed build to finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -5,6 +5,7 @@ package web
 import (
 	"time"
 
+	"github.com/2389-research/mammoth/redact"
 	"github.com/2389-research/tracker/agent"
 	"github.com/2389-research/tracker/pipeline"
 )
@@ -35,6 +36,11 @@ const (
 	BuildEventSessionStart  BuildEventType = "session_start"
 	BuildEventSessionEnd    BuildEventType = "session_end"
 	BuildEventAgentError    BuildEventType = "agent_error"
+	BuildEventLLMTurn       BuildEventType = "llm_turn" // one LLM round-trip, with its token usage
+
+	// BuildEventReasoning carries the model's reasoning text. It is only
+	// emitted when the server is started with ShowReasoning.
+	BuildEventReasoning BuildEventType = "llm_reasoning"
 
 	// Human gates.
 	BuildEventHumanGateChoice   BuildEventType = "human_gate_choice"
@@ -63,6 +69,8 @@ var sseEventNameMap = map[BuildEventType]string{
 	BuildEventSessionStart:      "agent.session.start",
 	BuildEventSessionEnd:        "agent.session.end",
 	BuildEventAgentError:        "agent.error",
+	BuildEventLLMTurn:           "agent.llm_turn",
+	BuildEventReasoning:         "agent.reasoning",
 	BuildEventHumanGateChoice:   "human_gate.choice",
 	BuildEventHumanGateFreeform: "human_gate.freeform",
 	BuildEventHumanGateAnswered: "human_gate.answered",
@@ -128,6 +136,7 @@ var agentEventMap = map[agent.EventType]BuildEventType{
 	agent.EventSessionStart:  BuildEventSessionStart,
 	agent.EventSessionEnd:    BuildEventSessionEnd,
 	agent.EventError:         BuildEventAgentError,
+	agent.EventLLMFinish:     BuildEventLLMTurn,
 }
 
 // buildEventFromAgent maps a tracker agent.Event to a BuildEvent.
@@ -168,9 +177,41 @@ func buildEventFromAgent(evt agent.Event) BuildEvent {
 		if evt.Err != nil {
 			data["error"] = evt.Err.Error()
 		}
+	case agent.EventLLMFinish:
+		total := evt.Usage.TotalTokens
+		if total == 0 {
+			total = evt.Usage.InputTokens + evt.Usage.OutputTokens
+		}
+		data["input_tokens"] = evt.Usage.InputTokens
+		data["output_tokens"] = evt.Usage.OutputTokens
+		data["total_tokens"] = total
 	}
 	if len(data) > 0 {
 		be.Data = data
 	}
 	return be
 }
+
+// reasoningEventFromAgent maps a tracker reasoning event to a BuildEvent
+// whose data holds the reasoning text, with secrets masked by r. Reasoning
+// can echo prompts and tool output verbatim, so callers only use this when
+// reasoning display was opted into. Returns a zero-value BuildEvent for other
+// event types or empty reasoning.
+func reasoningEventFromAgent(evt agent.Event, r *redact.Redactor) BuildEvent {
+	if evt.Type != agent.EventLLMReasoning || evt.Preview == "" {
+		return BuildEvent{}
+	}
+	data := map[string]any{"reasoning": r.String(evt.Preview)}
+	if evt.Provider != "" {
+		data["provider"] = evt.Provider
+	}
+	if evt.Model != "" {
+		data["model"] = evt.Model
+	}
+	return BuildEvent{
+		Type:      BuildEventReasoning,
+		Timestamp: evt.Timestamp,
+		NodeID:    evt.SessionID,
+		Data:      data,
+	}
+}
@@ -3,10 +3,13 @@
 package web
 
 import (
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/2389-research/mammoth/redact"
 	"github.com/2389-research/tracker/agent"
+	"github.com/2389-research/tracker/llm"
 	"github.com/2389-research/tracker/pipeline"
 )
 
@@ -83,3 +86,50 @@ func TestBuildEventFromAgent_DroppedType(t *testing.T) {
 		t.Errorf("expected empty type for dropped event, got %q", be.Type)
 	}
 }
+
+func TestReasoningEventFromAgent_FlowsFromStreamWithRedaction(t *testing.T) {
+	const secret = "sk-ant-REDACTED"
+	// The adapter's reasoning delta becomes a trace event...
+	tb := llm.NewTraceBuilder(llm.TraceOptions{Provider: "anthropic", Model: "claude-sonnet-4-5"})
+	tb.Process(llm.StreamEvent{Type: llm.EventReasoningDelta, ReasoningDelta: "The key " + secret + " must not leak; try the cache first."})
+	traces := tb.Events()
+	if len(traces) != 1 || traces[0].Kind != llm.TraceReasoning {
+		t.Fatalf("trace events = %+v, want one reasoning event", traces)
+	}
+	// ...which the agent session forwards as an llm_reasoning event.
+	evt := agent.Event{
+		Type:      agent.EventLLMReasoning,
+		SessionID: "plan",
+		Provider:  traces[0].Provider,
+		Model:     traces[0].Model,
+		Preview:   traces[0].Preview,
+	}
+
+	be := reasoningEventFromAgent(evt, redact.New(redact.DefaultPatterns, nil))
+	if be.Type != BuildEventReasoning || be.NodeID != "plan" {
+		t.Fatalf("event = %+v, want a reasoning event for node plan", be)
+	}
+	reasoning, _ := be.Data["reasoning"].(string)
+	if !strings.Contains(reasoning, "try the cache first") {
+		t.Errorf("reasoning = %q, want the model's text", reasoning)
+	}
+	if strings.Contains(reasoning, secret) || !strings.Contains(reasoning, redact.Mask) {
+		t.Errorf("reasoning = %q, want the key redacted", reasoning)
+	}
+	if be.Data["provider"] != "anthropic" {
+		t.Errorf("provider = %v", be.Data["provider"])
+	}
+	if got := be.Type.SSEEventName(); got != "agent.reasoning" {
+		t.Errorf("SSE name = %q, want agent.reasoning", got)
+	}
+}
+
+func TestReasoningEventFromAgent_OffByDefault(t *testing.T) {
+	evt := agent.Event{Type: agent.EventLLMReasoning, Preview: "thinking"}
+	if be := buildEventFromAgent(evt); be.Type != "" {
+		t.Errorf("buildEventFromAgent surfaced reasoning without opt-in: %+v", be)
+	}
+	if be := reasoningEventFromAgent(agent.Event{Type: agent.EventTextDelta, Text: "hi"}, nil); be.Type != "" {
+		t.Errorf("non-reasoning event mapped to %+v", be)
+	}
+}
@@ -0,0 +1,46 @@
+// ABOUTME: ?events= subscription filter for the build SSE stream, so light clients receive only the events they use.
+// ABOUTME: Entries match an event name exactly or a whole family by prefix (stage matches stage.started).
+package web
+
+import (
+	"net/url"
+	"strings"
+)
+
+// sseEventFilter selects which SSE events a build stream subscriber gets.
+// A nil filter passes every event.
+type sseEventFilter map[string]bool
+
+// parseSSEEventFilter reads the events query parameter: event names or
+// families, comma-separated or repeated, e.g. ?events=pipeline,stage.failed.
+// An absent or empty parameter selects everything.
+func parseSSEEventFilter(values url.Values) sseEventFilter {
+	var f sseEventFilter
+	for _, raw := range values["events"] {
+		for _, name := range strings.Split(raw, ",") {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			if f == nil {
+				f = make(sseEventFilter)
+			}
+			f[name] = true
+		}
+	}
+	return f
+}
+
+// matches reports whether an event named name passes the filter: the name
+// itself is selected, or one of its dot-separated prefixes is, so
+// "agent.tool_call" selects agent.tool_call.start and agent.tool_call.end.
+func (f sseEventFilter) matches(name string) bool {
+	if f == nil || f[name] {
+		return true
+	}
+	for i := strings.LastIndex(name, "."); i > 0; i = strings.LastIndex(name[:i], ".") {
+		if f[name[:i]] {
+			return true
+		}
+	}
+	return false
+}
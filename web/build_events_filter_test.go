@@ -0,0 +1,80 @@
+// ABOUTME: Tests for the build SSE stream's ?events= filter.
+// ABOUTME: Checks name and family matching and that a filtered stream carries only the requested events.
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSSEEventFilterMatches(t *testing.T) {
+	f := parseSSEEventFilter(url.Values{"events": {"pipeline, stage.failed", "agent.tool_call"}})
+	tests := map[string]bool{
+		"pipeline.started":       true,
+		"pipeline.failed":        true,
+		"stage.failed":           true,
+		"stage.started":          false,
+		"agent.tool_call.start":  true,
+		"agent.tool_call":        true,
+		"agent.text_delta":       false,
+		"pipelines.started":      false,
+		"history.truncated":      false,
+		"agent.tool_call_result": false,
+	}
+	for name, want := range tests {
+		if got := f.matches(name); got != want {
+			t.Errorf("matches(%q) = %v, want %v", name, got, want)
+		}
+	}
+
+	for _, values := range []url.Values{nil, {"events": {""}}, {"events": {" , "}}} {
+		if f := parseSSEEventFilter(values); f != nil || !f.matches("anything.at_all") {
+			t.Errorf("parseSSEEventFilter(%v) should select everything", values)
+		}
+	}
+}
+
+func TestBuildEventsStreamSubset(t *testing.T) {
+	srv := newTestServer(t)
+	p, err := srv.store.Create("sse-subset")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan SSEEvent, 10)
+	srv.buildsMu.Lock()
+	srv.builds[p.ID] = &BuildRun{State: &RunState{ID: "subset-run", Status: "running"}, Events: events}
+	srv.buildsMu.Unlock()
+	for _, name := range []string{
+		"pipeline.started",
+		"stage.started",
+		"agent.text_delta",
+		"agent.tool_call.start",
+		"stage.failed",
+		"pipeline.failed",
+	} {
+		events <- SSEEvent{Event: name, Data: `{}`}
+	}
+	close(events)
+
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+p.ID+"/build/events?events=pipeline,stage.failed", nil)
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+
+	var got []string
+	for _, line := range strings.Split(rec.Body.String(), "\n") {
+		if name, ok := strings.CutPrefix(line, "event: "); ok {
+			got = append(got, name)
+		}
+	}
+	if want := []string{"pipeline.started", "stage.failed", "pipeline.failed"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("streamed events = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,254 @@
+// ABOUTME: Filtered query over a build's stored progress events, optionally counted per time bucket.
+// ABOUTME: Matches any of several event types (OR) combined with node and time-range filters (AND).
+package web
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// EventQueryResponse is the JSON body returned by GET /build/events/query.
+// Events are the matching progress.ndjson entries, unchanged and in order.
+type EventQueryResponse struct {
+	Total  int               `json:"total"`
+	Events []json.RawMessage `json:"events"`
+}
+
+// EventBucketsResponse is the JSON body returned by GET
+// /build/events/query with a bucket parameter. Buckets run contiguously from
+// the first matching event's bucket to the last one's (or across since and
+// until when given), so empty buckets appear with zero counts.
+type EventBucketsResponse struct {
+	Total   int           `json:"total"`
+	Bucket  string        `json:"bucket"`
+	Buckets []EventBucket `json:"buckets"`
+}
+
+// EventBucket counts the matching events whose timestamps fall in
+// [Start, Start+bucket). Counts are keyed by normalized event type.
+type EventBucket struct {
+	Start  time.Time      `json:"start"`
+	Total  int            `json:"total"`
+	Counts map[string]int `json:"counts"`
+}
+
+// maxEventBuckets bounds the buckets one query may return, so a tiny bucket
+// over a long run cannot produce an enormous response.
+const maxEventBuckets = 1000
+
+// eventQuery selects progress events. Zero-valued fields match everything.
+// A non-zero bucket asks for counts per time bucket instead of the events.
+type eventQuery struct {
+	types  map[string]bool
+	node   string
+	since  time.Time
+	until  time.Time
+	bucket time.Duration
+}
+
+// parseEventQuery reads the query parameters:
+//
+//	type   event type; repeat the parameter or comma-separate to match any
+//	node   node ID
+//	since  RFC 3339 timestamp, inclusive
+//	until  RFC 3339 timestamp, inclusive
+//	bucket Go duration such as 1m or 30s; count events per bucket instead
+//
+// Types are compared after normalization, so stage_started and
+// stage.started are interchangeable.
+func parseEventQuery(values url.Values) (eventQuery, error) {
+	var q eventQuery
+	for _, raw := range values["type"] {
+		for _, typ := range strings.Split(raw, ",") {
+			if typ = strings.TrimSpace(typ); typ == "" {
+				continue
+			}
+			if q.types == nil {
+				q.types = make(map[string]bool)
+			}
+			q.types[normalizeTimelineEventType(typ)] = true
+		}
+	}
+	q.node = strings.TrimSpace(values.Get("node"))
+	for _, bound := range []struct {
+		name string
+		dst  *time.Time
+	}{{"since", &q.since}, {"until", &q.until}} {
+		raw := strings.TrimSpace(values.Get(bound.name))
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return q, fmt.Errorf("invalid %s %q: want an RFC 3339 timestamp", bound.name, raw)
+		}
+		*bound.dst = t
+	}
+	if raw := strings.TrimSpace(values.Get("bucket")); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < time.Second {
+			return q, fmt.Errorf("invalid bucket %q: want a duration of at least 1s, such as 1m", raw)
+		}
+		q.bucket = d
+	}
+	return q, nil
+}
+
+// matches reports whether an event passes every filter in q.
+func (q eventQuery) matches(typ, nodeID string, ts time.Time) bool {
+	if q.types != nil && !q.types[normalizeTimelineEventType(typ)] {
+		return false
+	}
+	if q.node != "" && nodeID != q.node {
+		return false
+	}
+	if !q.since.IsZero() && (ts.IsZero() || ts.Before(q.since)) {
+		return false
+	}
+	if !q.until.IsZero() && (ts.IsZero() || ts.After(q.until)) {
+		return false
+	}
+	return true
+}
+
+// handleBuildEventsQuery returns the project's stored progress events that
+// match the query. Projects without a run or without stored events get an
+// empty result rather than an error.
+func (s *Server) handleBuildEventsQuery(w http.ResponseWriter, r *http.Request) {
+	projectID := chi.URLParam(r, "projectID")
+	p, ok := s.store.Get(projectID)
+	if !ok {
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project not found")
+		return
+	}
+	q, err := parseEventQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+		return
+	}
+	events := []json.RawMessage{}
+	if p.RunID != "" {
+		progressPath := filepath.Join(s.workspace.ProgressLogDir(projectID, p.RunID), "progress.ndjson")
+		f, err := os.Open(progressPath)
+		if err != nil && !os.IsNotExist(err) {
+			http.Error(w, "failed to open events", http.StatusInternalServerError)
+			return
+		}
+		if err == nil {
+			defer f.Close()
+			if events, err = queryProgressEvents(f, q); err != nil {
+				http.Error(w, "failed to read events", http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+
+	if q.bucket == 0 {
+		writeSpecJSON(w, http.StatusOK, EventQueryResponse{Total: len(events), Events: events})
+		return
+	}
+	resp, err := bucketProgressEvents(events, q)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+		return
+	}
+	writeSpecJSON(w, http.StatusOK, resp)
+}
+
+// queryProgressEvents returns the progress.ndjson lines matching q.
+// Malformed lines are skipped, matching the summary and timeline.
+func queryProgressEvents(r io.Reader, q eventQuery) ([]json.RawMessage, error) {
+	events := []json.RawMessage{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var evt struct {
+			Timestamp string `json:"timestamp"`
+			Type      string `json:"type"`
+			NodeID    string `json:"node_id"`
+		}
+		if err := json.Unmarshal([]byte(line), &evt); err != nil {
+			continue
+		}
+		if q.matches(evt.Type, evt.NodeID, parseRFC3339(evt.Timestamp)) {
+			events = append(events, json.RawMessage(line))
+		}
+	}
+	return events, scanner.Err()
+}
+
+// bucketProgressEvents counts matched events per q.bucket. Buckets are
+// aligned to multiples of the bucket duration since the Unix epoch (so 1m
+// buckets start on the minute) and span since to until when those are set,
+// otherwise the first to the last event. Events without a timestamp cannot
+// be placed and are left out of the total.
+func bucketProgressEvents(events []json.RawMessage, q eventQuery) (EventBucketsResponse, error) {
+	resp := EventBucketsResponse{Bucket: q.bucket.String(), Buckets: []EventBucket{}}
+	type placed struct {
+		start time.Time
+		typ   string
+	}
+	var (
+		items       []placed
+		first, last time.Time
+	)
+	for _, raw := range events {
+		var evt struct {
+			Timestamp string `json:"timestamp"`
+			Type      string `json:"type"`
+		}
+		if err := json.Unmarshal(raw, &evt); err != nil {
+			continue
+		}
+		ts := parseRFC3339(evt.Timestamp)
+		if ts.IsZero() {
+			continue
+		}
+		start := ts.UTC().Truncate(q.bucket)
+		if first.IsZero() || start.Before(first) {
+			first = start
+		}
+		if last.IsZero() || start.After(last) {
+			last = start
+		}
+		items = append(items, placed{start: start, typ: normalizeTimelineEventType(evt.Type)})
+	}
+	if !q.since.IsZero() {
+		first = q.since.UTC().Truncate(q.bucket)
+	}
+	if !q.until.IsZero() {
+		last = q.until.UTC().Truncate(q.bucket)
+	}
+	if first.IsZero() || last.IsZero() || last.Before(first) {
+		return resp, nil
+	}
+
+	n := int(last.Sub(first)/q.bucket) + 1
+	if n > maxEventBuckets {
+		return resp, fmt.Errorf("bucket %s splits the range into %d buckets (max %d): use a larger bucket or narrow since/until", q.bucket, n, maxEventBuckets)
+	}
+	resp.Buckets = make([]EventBucket, n)
+	for i := range resp.Buckets {
+		resp.Buckets[i] = EventBucket{Start: first.Add(time.Duration(i) * q.bucket), Counts: map[string]int{}}
+	}
+	for _, item := range items {
+		b := &resp.Buckets[item.start.Sub(first)/q.bucket]
+		b.Total++
+		b.Counts[item.typ]++
+	}
+	resp.Total = len(items)
+	return resp, nil
+}
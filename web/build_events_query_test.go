@@ -0,0 +1,210 @@
+// ABOUTME: Tests for the build events query endpoint.
+// ABOUTME: Covers multi-type OR matching, composition with node and time filters, time buckets, and bad input.
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var queryTestEvents = []string{
+	`{"timestamp":"2026-02-14T19:30:00Z","type":"pipeline.started"}`,
+	`{"timestamp":"2026-02-14T19:30:01Z","type":"stage.started","node_id":"build"}`,
+	`{"timestamp":"2026-02-14T19:30:02Z","type":"stage.failed","node_id":"build"}`,
+	`{"timestamp":"2026-02-14T19:30:03Z","type":"agent.loop_detected","node_id":"test"}`,
+	`{"timestamp":"2026-02-14T19:30:04Z","type":"stage_failed","node_id":"test"}`,
+	`{"timestamp":"2026-02-14T19:30:05Z","type":"pipeline.failed"}`,
+}
+
+// queryEvents runs a query and returns the matching events' type/node pairs.
+func queryEvents(t *testing.T, srv *Server, projectID, rawQuery string) []string {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+projectID+"/build/events/query?"+rawQuery, nil)
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("query %q: status %d: %s", rawQuery, rec.Code, rec.Body.String())
+	}
+	var resp EventQueryResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Total != len(resp.Events) {
+		t.Errorf("total = %d, but %d events returned", resp.Total, len(resp.Events))
+	}
+	var got []string
+	for _, raw := range resp.Events {
+		var evt struct {
+			Type   string `json:"type"`
+			NodeID string `json:"node_id"`
+		}
+		if err := json.Unmarshal(raw, &evt); err != nil {
+			t.Fatalf("decode event: %v", err)
+		}
+		got = append(got, evt.Type+"@"+evt.NodeID)
+	}
+	return got
+}
+
+func TestBuildEventsQueryMultipleTypes(t *testing.T) {
+	srv, projectID := setupServerWithEvents(t, queryTestEvents...)
+	want := []string{
+		"stage.failed@build",
+		"agent.loop_detected@test",
+		"stage_failed@test",
+		"pipeline.failed@",
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"repeated", "type=stage.failed&type=pipeline.failed&type=agent.loop_detected", want},
+		{"comma separated", "type=stage.failed,pipeline.failed,agent.loop_detected", want},
+		{"mixed", "type=stage.failed,pipeline.failed&type=agent.loop_detected", want},
+		{"single", "type=pipeline.started", []string{"pipeline.started@"}},
+		{"no filter", "", []string{
+			"pipeline.started@", "stage.started@build", "stage.failed@build",
+			"agent.loop_detected@test", "stage_failed@test", "pipeline.failed@",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := queryEvents(t, srv, projectID, tt.query)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildEventsQueryComposesWithNodeAndTime(t *testing.T) {
+	srv, projectID := setupServerWithEvents(t, queryTestEvents...)
+
+	got := queryEvents(t, srv, projectID, "type=stage.failed&type=agent.loop_detected&node=test")
+	if len(got) != 2 || got[0] != "agent.loop_detected@test" || got[1] != "stage_failed@test" {
+		t.Errorf("types AND node = %v, want test's loop and failure", got)
+	}
+
+	got = queryEvents(t, srv, projectID, "type=stage.failed,pipeline.failed&until=2026-02-14T19:30:04Z")
+	if len(got) != 2 || got[0] != "stage.failed@build" || got[1] != "stage_failed@test" {
+		t.Errorf("types AND until = %v, want the two stage failures", got)
+	}
+
+	got = queryEvents(t, srv, projectID, "type=stage.failed&node=build&since=2026-02-14T19:30:03Z")
+	if len(got) != 0 {
+		t.Errorf("filters that exclude everything returned %v", got)
+	}
+}
+
+func TestBuildEventsQueryInvalidTime(t *testing.T) {
+	srv, projectID := setupServerWithEvents(t, queryTestEvents...)
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+projectID+"/build/events/query?since=yesterday", nil)
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+	var body apiError
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || body.Code != ErrCodeBadRequest {
+		t.Errorf("body = %+v (%v), want code %q", body, err, ErrCodeBadRequest)
+	}
+}
+
+// queryBuckets runs a bucketed query and decodes the response.
+func queryBuckets(t *testing.T, srv *Server, projectID, rawQuery string) EventBucketsResponse {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+projectID+"/build/events/query?"+rawQuery, nil)
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("query %q: status %d: %s", rawQuery, rec.Code, rec.Body.String())
+	}
+	var resp EventBucketsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return resp
+}
+
+var bucketTestEvents = []string{
+	`{"timestamp":"2026-02-14T19:30:10Z","type":"pipeline.started"}`,
+	`{"timestamp":"2026-02-14T19:30:20Z","type":"stage.started","node_id":"build"}`,
+	`{"timestamp":"2026-02-14T19:30:59Z","type":"stage_completed","node_id":"build"}`,
+	`{"timestamp":"2026-02-14T19:31:00Z","type":"stage.started","node_id":"test"}`,
+	`{"timestamp":"2026-02-14T19:33:30Z","type":"stage.completed","node_id":"test"}`,
+	`{"type":"stage.started","node_id":"untimed"}`,
+}
+
+func TestBuildEventsQueryBuckets(t *testing.T) {
+	srv, projectID := setupServerWithEvents(t, bucketTestEvents...)
+
+	resp := queryBuckets(t, srv, projectID, "bucket=1m")
+	if resp.Bucket != "1m0s" || resp.Total != 5 {
+		t.Errorf("bucket, total = %q, %d; want 1m0s, 5", resp.Bucket, resp.Total)
+	}
+	want := []struct {
+		start  string
+		counts map[string]int
+	}{
+		{"2026-02-14T19:30:00Z", map[string]int{"pipeline.started": 1, "stage.started": 1, "stage.completed": 1}},
+		{"2026-02-14T19:31:00Z", map[string]int{"stage.started": 1}},
+		{"2026-02-14T19:32:00Z", map[string]int{}},
+		{"2026-02-14T19:33:00Z", map[string]int{"stage.completed": 1}},
+	}
+	if len(resp.Buckets) != len(want) {
+		t.Fatalf("buckets = %+v, want %d", resp.Buckets, len(want))
+	}
+	for i, w := range want {
+		b := resp.Buckets[i]
+		total := 0
+		for _, c := range w.counts {
+			total += c
+		}
+		if got := b.Start.Format(time.RFC3339); got != w.start || b.Total != total || !reflect.DeepEqual(b.Counts, w.counts) {
+			t.Errorf("bucket %d = %s %d %v, want %s %d %v", i, got, b.Total, b.Counts, w.start, total, w.counts)
+		}
+	}
+}
+
+func TestBuildEventsQueryBucketsWithFilters(t *testing.T) {
+	srv, projectID := setupServerWithEvents(t, bucketTestEvents...)
+
+	// since and until fix the range, so leading and trailing empty buckets appear.
+	resp := queryBuckets(t, srv, projectID, "bucket=2m&type=stage.started&since=2026-02-14T19:28:00Z&until=2026-02-14T19:35:00Z")
+	var got []string
+	for _, b := range resp.Buckets {
+		got = append(got, fmt.Sprintf("%s=%d", b.Start.Format("15:04"), b.Counts["stage.started"]))
+	}
+	if want := []string{"19:28=0", "19:30=2", "19:32=0", "19:34=0"}; !reflect.DeepEqual(got, want) || resp.Total != 2 {
+		t.Errorf("buckets = %v (total %d), want %v (total 2)", got, resp.Total, want)
+	}
+
+	resp = queryBuckets(t, srv, projectID, "bucket=1m&node=nope")
+	if resp.Total != 0 || len(resp.Buckets) != 0 {
+		t.Errorf("no matches: %+v, want no buckets", resp)
+	}
+}
+
+func TestBuildEventsQueryInvalidBucket(t *testing.T) {
+	srv, projectID := setupServerWithEvents(t, bucketTestEvents...)
+	for _, query := range []string{"bucket=soon", "bucket=500ms", "bucket=1s&since=2026-01-01T00:00:00Z"} {
+		req := httptest.NewRequest(http.MethodGet, "/projects/"+projectID+"/build/events/query?"+query, nil)
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, rec.Code)
+		}
+	}
+}
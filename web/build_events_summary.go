@@ -0,0 +1,147 @@
+// ABOUTME: Aggregate summary of a build's stored progress events.
+// ABOUTME: Reports event counts by type and node, LLM turn and tool call totals, per-node active time, and the run's wall-clock span.
+package web
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// EventSummaryResponse is the JSON body returned by GET /build/events/summary.
+// Durations are computed from the stored event timestamps: a node's active
+// time is the sum of every stage.started → stage.completed/stage.failed pair
+// for that node, so retried nodes accumulate each attempt. LLMTurns and
+// ToolCalls count the run's agent.llm_turn and agent.tool_call.start events:
+// every LLM round-trip and every tool call the agents made.
+type EventSummaryResponse struct {
+	Total           int              `json:"total"`
+	ByType          map[string]int   `json:"by_type"`
+	ByNode          map[string]int   `json:"by_node"`
+	LLMTurns        int              `json:"llm_turns"`
+	ToolCalls       int              `json:"tool_calls"`
+	NodeDurationsMS map[string]int64 `json:"node_durations_ms"`
+	StartedAt       string           `json:"started_at,omitempty"`
+	CompletedAt     string           `json:"completed_at,omitempty"`
+	WallClockMS     int64            `json:"wall_clock_ms"`
+}
+
+// handleBuildEventsSummary returns an EventSummaryResponse for the project's
+// current run. Projects without a run or without stored events get an empty
+// summary rather than an error.
+func (s *Server) handleBuildEventsSummary(w http.ResponseWriter, r *http.Request) {
+	projectID := chi.URLParam(r, "projectID")
+	p, ok := s.store.Get(projectID)
+	if !ok {
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project not found")
+		return
+	}
+	if p.RunID == "" {
+		writeSpecJSON(w, http.StatusOK, newEventSummary())
+		return
+	}
+
+	progressPath := filepath.Join(s.workspace.ProgressLogDir(projectID, p.RunID), "progress.ndjson")
+	f, err := os.Open(progressPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			writeSpecJSON(w, http.StatusOK, newEventSummary())
+			return
+		}
+		http.Error(w, "failed to open events", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+
+	summary, err := summarizeProgressEvents(f)
+	if err != nil {
+		http.Error(w, "failed to read events", http.StatusInternalServerError)
+		return
+	}
+	writeSpecJSON(w, http.StatusOK, summary)
+}
+
+func newEventSummary() EventSummaryResponse {
+	return EventSummaryResponse{
+		ByType:          map[string]int{},
+		ByNode:          map[string]int{},
+		NodeDurationsMS: map[string]int64{},
+	}
+}
+
+// summarizeProgressEvents folds a progress.ndjson stream into an
+// EventSummaryResponse. Malformed lines are skipped, matching the timeline.
+func summarizeProgressEvents(r io.Reader) (EventSummaryResponse, error) {
+	summary := newEventSummary()
+
+	var first, last time.Time
+	startedAt := map[string]time.Time{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var evt struct {
+			Timestamp string `json:"timestamp"`
+			Type      string `json:"type"`
+			NodeID    string `json:"node_id"`
+		}
+		if err := json.Unmarshal([]byte(line), &evt); err != nil {
+			continue
+		}
+
+		summary.Total++
+		summary.ByType[evt.Type]++
+		if evt.NodeID != "" {
+			summary.ByNode[evt.NodeID]++
+		}
+		switch evt.Type {
+		case BuildEventLLMTurn.SSEEventName():
+			summary.LLMTurns++
+		case BuildEventToolCallStart.SSEEventName():
+			summary.ToolCalls++
+		}
+
+		ts := parseRFC3339(evt.Timestamp)
+		if ts.IsZero() {
+			continue
+		}
+		if first.IsZero() || ts.Before(first) {
+			first = ts
+		}
+		if ts.After(last) {
+			last = ts
+		}
+
+		if evt.NodeID == "" {
+			continue
+		}
+		switch normalizeTimelineEventType(evt.Type) {
+		case "stage.started":
+			startedAt[evt.NodeID] = ts
+		case "stage.completed", "stage.failed":
+			if start, ok := startedAt[evt.NodeID]; ok {
+				summary.NodeDurationsMS[evt.NodeID] += ts.Sub(start).Milliseconds()
+				delete(startedAt, evt.NodeID)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return summary, err
+	}
+
+	if !first.IsZero() {
+		summary.StartedAt = first.Format(time.RFC3339Nano)
+		summary.CompletedAt = last.Format(time.RFC3339Nano)
+		summary.WallClockMS = last.Sub(first).Milliseconds()
+	}
+	return summary, nil
+}
@@ -0,0 +1,155 @@
+// ABOUTME: Tests for the build events summary endpoint.
+// ABOUTME: Verifies counts, LLM turn and tool call totals, per-node active durations, and wall-clock span from stored progress events.
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/2389-research/tracker/agent"
+	"github.com/2389-research/tracker/llm"
+)
+
+// setupServerWithEvents creates a project with a run whose progress log holds
+// the given NDJSON event lines, returning the server and project ID.
+func setupServerWithEvents(t *testing.T, lines ...string) (*Server, string) {
+	t.Helper()
+	srv := newTestServer(t)
+
+	p, err := srv.store.Create("events-project")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	p.Phase = PhaseDone
+	p.RunID = "run-events-1"
+	if err := srv.store.Update(p); err != nil {
+		t.Fatalf("update project: %v", err)
+	}
+
+	base := srv.workspace.ProgressLogDir(p.ID, p.RunID)
+	if err := os.MkdirAll(base, 0o755); err != nil {
+		t.Fatalf("mkdir progress dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(base, "progress.ndjson"), []byte(strings.Join(lines, "\n")), 0o644); err != nil {
+		t.Fatalf("write progress: %v", err)
+	}
+	return srv, p.ID
+}
+
+func getEventSummary(t *testing.T, srv *Server, projectID string) EventSummaryResponse {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+projectID+"/build/events/summary", nil)
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("summary status: got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp EventSummaryResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode summary: %v", err)
+	}
+	return resp
+}
+
+func TestBuildEventsSummaryTiming(t *testing.T) {
+	srv, projectID := setupServerWithEvents(t,
+		`{"timestamp":"2026-02-14T19:30:00Z","type":"pipeline.started"}`,
+		`{"timestamp":"2026-02-14T19:30:01Z","type":"stage.started","node_id":"node_a"}`,
+		`{"timestamp":"2026-02-14T19:30:03.500Z","type":"stage.completed","node_id":"node_a"}`,
+		`{"timestamp":"2026-02-14T19:30:04Z","type":"stage.started","node_id":"node_b"}`,
+		`{"timestamp":"2026-02-14T19:30:05Z","type":"agent.tool_call.start","node_id":"node_b"}`,
+		`{"timestamp":"2026-02-14T19:30:09Z","type":"stage.completed","node_id":"node_b"}`,
+		`{"timestamp":"2026-02-14T19:30:10Z","type":"pipeline.completed"}`,
+	)
+
+	resp := getEventSummary(t, srv, projectID)
+
+	if resp.Total != 7 {
+		t.Errorf("total = %d, want 7", resp.Total)
+	}
+	if resp.ByType["stage.started"] != 2 || resp.ByNode["node_b"] != 3 {
+		t.Errorf("unexpected counts: by_type=%v by_node=%v", resp.ByType, resp.ByNode)
+	}
+	if got := resp.NodeDurationsMS["node_a"]; got != 2500 {
+		t.Errorf("node_a duration = %dms, want 2500", got)
+	}
+	if got := resp.NodeDurationsMS["node_b"]; got != 5000 {
+		t.Errorf("node_b duration = %dms, want 5000", got)
+	}
+	if resp.WallClockMS != 10000 {
+		t.Errorf("wall clock = %dms, want 10000", resp.WallClockMS)
+	}
+}
+
+func TestBuildEventsSummaryAccumulatesRetries(t *testing.T) {
+	srv, projectID := setupServerWithEvents(t,
+		`{"timestamp":"2026-02-14T19:30:00Z","type":"stage_started","node_id":"flaky"}`,
+		`{"timestamp":"2026-02-14T19:30:02Z","type":"stage_failed","node_id":"flaky"}`,
+		`{"timestamp":"2026-02-14T19:30:05Z","type":"stage_started","node_id":"flaky"}`,
+		`{"timestamp":"2026-02-14T19:30:06Z","type":"stage_completed","node_id":"flaky"}`,
+		`{"timestamp":"2026-02-14T19:30:07Z","type":"stage_started","node_id":"unfinished"}`,
+	)
+
+	resp := getEventSummary(t, srv, projectID)
+
+	if got := resp.NodeDurationsMS["flaky"]; got != 3000 {
+		t.Errorf("flaky duration = %dms, want 3000 (both attempts, excluding the gap)", got)
+	}
+	if _, ok := resp.NodeDurationsMS["unfinished"]; ok {
+		t.Error("node without a completion event should have no duration")
+	}
+	if resp.WallClockMS != 7000 {
+		t.Errorf("wall clock = %dms, want 7000", resp.WallClockMS)
+	}
+}
+
+func TestBuildEventsSummaryCountsTurnsAndToolCalls(t *testing.T) {
+	srv, projectID := setupServerWithEvents(t)
+	p, _ := srv.store.Get(projectID)
+	progress, err := openProgressLog(srv.workspace.ProgressLogDir(p.ID, p.RunID))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	emitted := []agent.Event{
+		{Type: agent.EventLLMFinish, Usage: llm.Usage{InputTokens: 100, OutputTokens: 20}},
+		{Type: agent.EventToolCallStart, ToolName: "bash"},
+		{Type: agent.EventToolCallEnd, ToolName: "bash"},
+		{Type: agent.EventToolCallStart, ToolName: "read"},
+		{Type: agent.EventToolCallEnd, ToolName: "read"},
+		{Type: agent.EventLLMFinish, Usage: llm.Usage{InputTokens: 150, OutputTokens: 10, TotalTokens: 160}},
+		{Type: agent.EventTextDelta, Text: "done"},
+	}
+	for _, evt := range emitted {
+		if err := progress.Append(buildEventFromAgent(evt)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	progress.Close()
+
+	resp := getEventSummary(t, srv, projectID)
+	if resp.LLMTurns != 2 || resp.ToolCalls != 2 {
+		t.Errorf("llm_turns = %d, tool_calls = %d; want 2 and 2 from the emitted events", resp.LLMTurns, resp.ToolCalls)
+	}
+	if resp.ByType["agent.llm_turn"] != resp.LLMTurns {
+		t.Errorf("by_type = %v, want llm_turns to match agent.llm_turn", resp.ByType)
+	}
+}
+
+func TestBuildEventsSummaryNoRun(t *testing.T) {
+	srv := newTestServer(t)
+	p, err := srv.store.Create("no-run")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+
+	resp := getEventSummary(t, srv, p.ID)
+	if resp.Total != 0 || resp.WallClockMS != 0 || len(resp.NodeDurationsMS) != 0 {
+		t.Errorf("expected empty summary, got %+v", resp)
+	}
+}
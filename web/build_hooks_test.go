@@ -0,0 +1,272 @@
+// ABOUTME: Tests that node attributes implemented as handler hooks take effect in web builds, not only the CLI.
+// ABOUTME: Each test submits a pipeline and checks the finished build's state or the requests its LLM client received.
+package web
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/2389-research/tracker/llm"
+)
+
+// runHookBuild submits source as a pipeline and returns the finished
+// build's state.
+func runHookBuild(t *testing.T, source string) RunState {
+	t.Helper()
+	return runHookBuildOn(t, newTestServer(t), source)
+}
+
+// runHookBuildOn is runHookBuild on a given server, such as one whose LLM
+// client was replaced.
+func runHookBuildOn(t *testing.T, srv *Server, source string) RunState {
+	t.Helper()
+	rec := postPipeline(srv, "text/plain", bytes.NewBufferString(source))
+	p := assertRunCreatedFrom(t, srv, rec, source)
+	return waitForBuildStatus(t, srv, p.ID)
+}
+
+// requestCapturingCompleter records every request and answers with plain
+// text, reporting tokens as each response's usage.
+type requestCapturingCompleter struct {
+	tokens int
+
+	mu       sync.Mutex
+	requests []llm.Request
+}
+
+func (c *requestCapturingCompleter) Complete(_ context.Context, req *llm.Request) (*llm.Response, error) {
+	c.mu.Lock()
+	c.requests = append(c.requests, *req)
+	c.mu.Unlock()
+	return &llm.Response{
+		Message:      llm.AssistantMessage("done"),
+		FinishReason: llm.FinishReason{Reason: "stop"},
+		Usage:        llm.Usage{TotalTokens: c.tokens},
+	}, nil
+}
+
+func TestBuildSkipIfSkipsNode(t *testing.T) {
+	// Run, the tool fails and no edge matches; skipped, the skip edge leads on.
+	state := runHookBuild(t, `digraph skip {
+	start [shape=Mdiamond]
+	flag [shape=parallelogram, tool_command="printf fast"]
+	optional [shape=parallelogram, tool_command="exit 1", skip_if="context.tool_stdout = fast"]
+	done [shape=Msquare]
+	start -> flag -> optional
+	optional -> done [condition="outcome = skipped"]
+}`)
+	if state.Status != "completed" {
+		t.Fatalf("status = %q (%s), want completed", state.Status, state.Error)
+	}
+	if !slices.Equal(state.SkippedNodes, []string{"optional"}) {
+		t.Errorf("skipped nodes = %v, want [optional]", state.SkippedNodes)
+	}
+}
+
+func TestBuildSuccessIfFailsNode(t *testing.T) {
+	// The tool succeeds, but its output misses the criterion, so the build
+	// takes the fail edge.
+	state := runHookBuild(t, `digraph check {
+	start [shape=Mdiamond]
+	test [shape=parallelogram, tool_command="printf no", success_if="context.tool_stdout = yes"]
+	fix [shape=parallelogram, tool_command="true"]
+	done [shape=Msquare]
+	start -> test
+	test -> done [condition="outcome = success"]
+	test -> fix [condition="outcome = fail"]
+	fix -> done
+}`)
+	if state.Status != "completed" {
+		t.Fatalf("status = %q (%s), want completed", state.Status, state.Error)
+	}
+	if !slices.Contains(state.CompletedNodes, "fix") {
+		t.Errorf("completed nodes = %v, want the fail edge taken to fix", state.CompletedNodes)
+	}
+}
+
+func TestBuildGenerationParamsReachRequest(t *testing.T) {
+	srv := newTestServer(t)
+	client := &requestCapturingCompleter{}
+	srv.llmClient = client
+	state := runHookBuildOn(t, srv, `digraph gen {
+	start [shape=Mdiamond]
+	write [shape=box, prompt="write", max_tokens="2048", stop="END"]
+	done [shape=Msquare]
+	start -> write -> done
+}`)
+	if state.Status != "completed" {
+		t.Fatalf("status = %q (%s), want completed", state.Status, state.Error)
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if len(client.requests) == 0 {
+		t.Fatal("expected a codergen request")
+	}
+	req := client.requests[0]
+	if req.MaxTokens == nil || *req.MaxTokens != 2048 {
+		t.Errorf("MaxTokens = %v, want 2048", req.MaxTokens)
+	}
+	if !slices.Equal(req.StopSequences, []string{"END"}) {
+		t.Errorf("StopSequences = %q, want [END]", req.StopSequences)
+	}
+}
+
+func TestBuildTokenBudgetFailsNode(t *testing.T) {
+	srv := newTestServer(t)
+	srv.llmClient = &requestCapturingCompleter{tokens: 500}
+	state := runHookBuildOn(t, srv, `digraph budget {
+	graph [token_budget="100"]
+	start [shape=Mdiamond]
+	write [shape=box, prompt="write"]
+	capped [shape=parallelogram, tool_command="true"]
+	done [shape=Msquare]
+	start -> write
+	write -> done [condition="outcome = success"]
+	write -> capped [condition="outcome = fail"]
+	capped -> done
+}`)
+	if state.Status != "completed" {
+		t.Fatalf("status = %q (%s), want completed", state.Status, state.Error)
+	}
+	if !slices.Contains(state.CompletedNodes, "capped") {
+		t.Errorf("completed nodes = %v, want the node over its allocation to take the fail edge", state.CompletedNodes)
+	}
+}
+
+func TestBuildRetryBackoffRetriesNode(t *testing.T) {
+	// The tool fails its first run; only a retry reaches the success edge.
+	state := runHookBuild(t, `digraph retry {
+	start [shape=Mdiamond]
+	flaky [shape=parallelogram, tool_command="test -f ran || { touch ran; exit 1; }", retry_backoff="linear", retry_base="1ms"]
+	done [shape=Msquare]
+	start -> flaky
+	flaky -> done [condition="outcome = success"]
+}`)
+	if state.Status != "completed" {
+		t.Fatalf("status = %q (%s), want completed", state.Status, state.Error)
+	}
+	if got := len(state.NodeAttempts["flaky"]); got != 2 {
+		t.Errorf("flaky attempts = %d, want 2", got)
+	}
+}
+
+// streamingCompleter is a requestCapturingCompleter that can also stream,
+// counting the requests it streamed.
+type streamingCompleter struct {
+	requestCapturingCompleter
+	streamed atomic.Int32
+}
+
+func (c *streamingCompleter) Stream(_ context.Context, _ *llm.Request) <-chan llm.StreamEvent {
+	c.streamed.Add(1)
+	ch := make(chan llm.StreamEvent, 2)
+	defer close(ch)
+	ch <- llm.StreamEvent{Type: llm.EventTextDelta, Delta: "done"}
+	ch <- llm.StreamEvent{Type: llm.EventFinish, FinishReason: &llm.FinishReason{Reason: "stop"}}
+	return ch
+}
+
+func TestBuildStreamingNodeStreams(t *testing.T) {
+	srv := newTestServer(t)
+	client := &streamingCompleter{}
+	srv.llmClient = client
+	state := runHookBuildOn(t, srv, `digraph stream {
+	start [shape=Mdiamond]
+	write [shape=box, prompt="write", stream="true"]
+	done [shape=Msquare]
+	start -> write -> done
+}`)
+	if state.Status != "completed" {
+		t.Fatalf("status = %q (%s), want completed", state.Status, state.Error)
+	}
+	if got := client.streamed.Load(); got != 1 {
+		t.Errorf("streamed requests = %d, want the streaming node to use Stream", got)
+	}
+}
+
+func TestBuildProviderPinSetsRequestProvider(t *testing.T) {
+	srv := newTestServer(t)
+	t.Setenv("OPENAI_API_KEY", "sk-test")
+	client := &requestCapturingCompleter{}
+	srv.llmClient = client
+	state := runHookBuildOn(t, srv, `digraph pin {
+	graph [provider="openai"]
+	start [shape=Mdiamond]
+	write [shape=box, prompt="write"]
+	done [shape=Msquare]
+	start -> write -> done
+}`)
+	if state.Status != "completed" {
+		t.Fatalf("status = %q (%s), want completed", state.Status, state.Error)
+	}
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if len(client.requests) == 0 || client.requests[0].Provider != "openai" {
+		t.Errorf("requests = %+v, want the pinned provider openai", client.requests)
+	}
+}
+
+func TestBuildProviderPinWithoutCredentialsFails(t *testing.T) {
+	srv := newTestServer(t)
+	t.Setenv("GEMINI_API_KEY", "")
+	t.Setenv("GOOGLE_API_KEY", "")
+	client := &requestCapturingCompleter{}
+	srv.llmClient = client
+	state := runHookBuildOn(t, srv, `digraph pin {
+	graph [provider="gemini"]
+	start [shape=Mdiamond]
+	write [shape=box, prompt="write"]
+	done [shape=Msquare]
+	start -> write -> done
+}`)
+	if state.Status != "failed" || !strings.Contains(state.Error, "GEMINI_API_KEY") {
+		t.Errorf("status = %q, error = %q; want a failure naming the missing key", state.Status, state.Error)
+	}
+	if len(client.requests) != 0 {
+		t.Errorf("backend called %d times before the preflight failed", len(client.requests))
+	}
+}
+
+func TestBuildArtifactCapFailsNode(t *testing.T) {
+	// The tool writes into the build's working directory, as agents do.
+	state := runHookBuild(t, `digraph cap {
+	start [shape=Mdiamond]
+	write [shape=parallelogram, tool_command="head -c 2000 /dev/zero > out.bin", max_artifact_bytes="1000"]
+	capped [shape=parallelogram, tool_command="true"]
+	done [shape=Msquare]
+	start -> write
+	write -> done [condition="outcome = success"]
+	write -> capped [condition="outcome = fail"]
+	capped -> done
+}`)
+	if state.Status != "completed" {
+		t.Fatalf("status = %q (%s), want completed", state.Status, state.Error)
+	}
+	if !slices.Contains(state.CompletedNodes, "capped") {
+		t.Errorf("completed nodes = %v, want the node over its cap to take the fail edge", state.CompletedNodes)
+	}
+}
+
+func TestBuildRecordsPrimaryOutput(t *testing.T) {
+	srv := newTestServer(t)
+	srv.llmClient = &requestCapturingCompleter{}
+	state := runHookBuildOn(t, srv, `digraph result {
+	start [shape=Mdiamond]
+	answer [shape=box, prompt="answer", result="true"]
+	tidy [shape=parallelogram, tool_command="echo tidied"]
+	done [shape=Msquare]
+	start -> answer -> tidy -> done
+}`)
+	if state.Status != "completed" {
+		t.Fatalf("status = %q (%s), want completed", state.Status, state.Error)
+	}
+	if state.ResultNode != "answer" || state.PrimaryOutput != "done" {
+		t.Errorf("got (%q, %q), want the result-marked node's output", state.ResultNode, state.PrimaryOutput)
+	}
+}
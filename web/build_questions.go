@@ -0,0 +1,128 @@
+// ABOUTME: HTTP handlers for answering a build's pending human gate questions.
+// ABOUTME: Lists questions in the order asked (JSON or HTMX fragment) and accepts answers in sequence.
+package web
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// questionView is a pending gate as rendered by the build_questions fragment.
+type questionView struct {
+	PendingGate
+	Position   int
+	Total      int
+	Answerable bool
+}
+
+// questionsFragmentData is the template data for build_questions.html.
+type questionsFragmentData struct {
+	ProjectID string
+	Questions []questionView
+	Error     string
+}
+
+// questionViews annotates pending gates with their position in their node's
+// sequence. Only the first unanswered question of each node is answerable.
+func questionViews(gates []PendingGate) []questionView {
+	totals := make(map[string]int)
+	for _, g := range gates {
+		if g.NodeID != "" {
+			totals[g.NodeID]++
+		}
+	}
+	seen := make(map[string]int)
+	views := make([]questionView, 0, len(gates))
+	for _, g := range gates {
+		v := questionView{PendingGate: g, Position: 1, Total: 1, Answerable: true}
+		if g.NodeID != "" {
+			seen[g.NodeID]++
+			v.Position = seen[g.NodeID]
+			v.Total = totals[g.NodeID]
+			v.Answerable = v.Position == 1
+		}
+		views = append(views, v)
+	}
+	return views
+}
+
+// buildInterviewer returns the interviewer of the project's build, or nil.
+func (s *Server) buildInterviewer(projectID string) *ChannelInterviewer {
+	s.buildsMu.RLock()
+	defer s.buildsMu.RUnlock()
+	run, ok := s.builds[projectID]
+	if !ok || run == nil {
+		return nil
+	}
+	return run.Interviewer
+}
+
+// handleBuildQuestions lists the build's pending human gate questions in the
+// order they were asked, as JSON or as an HTMX fragment.
+func (s *Server) handleBuildQuestions(w http.ResponseWriter, r *http.Request) {
+	projectID := chi.URLParam(r, "projectID")
+	if _, ok := s.store.Get(projectID); !ok {
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project not found")
+		return
+	}
+	s.writeQuestions(w, r, projectID, "", http.StatusOK)
+}
+
+// handleBuildAnswer answers one pending question. Questions belonging to the
+// same node must be answered in order; answering out of order is a conflict.
+func (s *Server) handleBuildAnswer(w http.ResponseWriter, r *http.Request) {
+	projectID := chi.URLParam(r, "projectID")
+	if _, ok := s.store.Get(projectID); !ok {
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project not found")
+		return
+	}
+	iv := s.buildInterviewer(projectID)
+	if iv == nil {
+		http.Error(w, "no active build", http.StatusNotFound)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 64<<10)
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+
+	gateID := chi.URLParam(r, "gateID")
+	if err := iv.RespondAs(gateID, r.FormValue("answer"), s.requestIdentity(r)); err != nil {
+		log.Printf("component=web.build action=answer_rejected project_id=%s gate_id=%s err=%v", projectID, gateID, err)
+		s.writeQuestions(w, r, projectID, err.Error(), http.StatusConflict)
+		return
+	}
+	s.writeQuestions(w, r, projectID, "", http.StatusOK)
+}
+
+// writeQuestions renders the pending questions with an optional error.
+func (s *Server) writeQuestions(w http.ResponseWriter, r *http.Request, projectID, errMsg string, status int) {
+	gates := []PendingGate{}
+	if iv := s.buildInterviewer(projectID); iv != nil {
+		gates = iv.Pending()
+	}
+
+	if wantsJSON(r) {
+		resp := map[string]any{"questions": gates}
+		if errMsg != "" {
+			resp["error"] = errMsg
+		}
+		writeSpecJSON(w, status, resp)
+		return
+	}
+
+	// htmx does not swap error responses, so report problems inline.
+	if r.Header.Get("HX-Request") != "" {
+		status = http.StatusOK
+	}
+	data := questionsFragmentData{ProjectID: projectID, Questions: questionViews(gates), Error: errMsg}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := s.templates.RenderStandaloneTo(w, "build_questions.html", data); err != nil {
+		log.Printf("component=web.server action=render_failed view=build_questions err=%v", err)
+	}
+}
@@ -0,0 +1,266 @@
+// ABOUTME: Tests for multi-turn human gates: follow-up questions asked in sequence through the HTTP interviewer.
+// ABOUTME: Covers pending-question listing, in-order answering, conditional follow-ups, answer patterns, and the HTMX fragment.
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/2389-research/mammoth/answerpattern"
+	"github.com/2389-research/tracker/pipeline"
+	"github.com/2389-research/tracker/pipeline/handlers"
+)
+
+// fixedAnswerHandler is a wait.human stand-in that always picks the same edge.
+type fixedAnswerHandler struct {
+	answer string
+}
+
+func (h *fixedAnswerHandler) Name() string { return "wait.human" }
+
+func (h *fixedAnswerHandler) Execute(context.Context, *pipeline.Node, *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	return pipeline.Outcome{Status: pipeline.OutcomeSuccess, PreferredLabel: h.answer}, nil
+}
+
+// newQuestionsTestServer registers a running build with a ChannelInterviewer
+// and returns the server, project ID, and interviewer.
+func newQuestionsTestServer(t *testing.T) (*Server, string, *ChannelInterviewer) {
+	t.Helper()
+	srv := newTestServer(t)
+	p, err := srv.store.Create("questions-test")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	iv := NewChannelInterviewer(ctx, func(BuildEvent) {})
+	srv.buildsMu.Lock()
+	srv.builds[p.ID] = &BuildRun{
+		State:       &RunState{ID: "questions-run", Status: "running"},
+		Cancel:      cancel,
+		Ctx:         ctx,
+		Interviewer: iv,
+	}
+	srv.buildsMu.Unlock()
+	return srv, p.ID, iv
+}
+
+func getQuestions(t *testing.T, srv *Server, projectID string) []PendingGate {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+projectID+"/build/questions", nil)
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET questions status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Questions []PendingGate `json:"questions"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode questions: %v", err)
+	}
+	return resp.Questions
+}
+
+func postAnswer(srv *Server, projectID, gateID, answer string) *httptest.ResponseRecorder {
+	form := url.Values{"answer": {answer}}
+	req := httptest.NewRequest(http.MethodPost, "/projects/"+projectID+"/build/questions/"+gateID, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	return rec
+}
+
+func waitForQuestions(t *testing.T, srv *Server, projectID string, n int) []PendingGate {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if qs := getQuestions(t, srv, projectID); len(qs) == n {
+			return qs
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d pending questions", n)
+	return nil
+}
+
+func TestFollowUpQuestionsAnsweredInOrderOverHTTP(t *testing.T) {
+	srv, projectID, iv := newQuestionsTestServer(t)
+	handler := &followUpHumanHandler{inner: &fixedAnswerHandler{answer: "yes"}, interviewer: iv}
+	node := &pipeline.Node{
+		ID: "deploy",
+		Attrs: map[string]string{
+			"followup_1":      "Which region?",
+			"followup_1_when": "Yes",
+			"followup_2":      "Any release notes?",
+		},
+	}
+
+	type result struct {
+		out pipeline.Outcome
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		out, err := handler.Execute(context.Background(), node, pipeline.NewPipelineContext())
+		done <- result{out, err}
+	}()
+
+	qs := waitForQuestions(t, srv, projectID, 2)
+	if qs[0].Prompt != "Which region?" || qs[1].Prompt != "Any release notes?" {
+		t.Fatalf("questions out of order: %+v", qs)
+	}
+	if qs[0].NodeID != "deploy" || qs[1].NodeID != "deploy" {
+		t.Errorf("expected both questions to belong to node deploy: %+v", qs)
+	}
+
+	if rec := postAnswer(srv, projectID, qs[1].ID, "ship it"); rec.Code != http.StatusConflict {
+		t.Fatalf("answering second question first: status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if rec := postAnswer(srv, projectID, qs[0].ID, "us-east-1"); rec.Code != http.StatusOK {
+		t.Fatalf("answer first: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if remaining := getQuestions(t, srv, projectID); len(remaining) != 1 || remaining[0].ID != qs[1].ID {
+		t.Fatalf("after first answer, pending = %+v, want only the second question", remaining)
+	}
+	if rec := postAnswer(srv, projectID, qs[1].ID, "ship it"); rec.Code != http.StatusOK {
+		t.Fatalf("answer second: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	select {
+	case res := <-done:
+		if res.err != nil {
+			t.Fatalf("handler error: %v", res.err)
+		}
+		if res.out.PreferredLabel != "yes" {
+			t.Errorf("PreferredLabel = %q, want first answer preserved", res.out.PreferredLabel)
+		}
+		if got := res.out.ContextUpdates["human_response.followup_1"]; got != "us-east-1" {
+			t.Errorf("followup_1 = %q, want us-east-1", got)
+		}
+		if got := res.out.ContextUpdates["human_response.followup_2"]; got != "ship it" {
+			t.Errorf("followup_2 = %q, want ship it", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("handler did not return after all questions were answered")
+	}
+}
+
+func TestFollowUpSkippedWhenConditionDoesNotMatch(t *testing.T) {
+	iv := NewChannelInterviewer(context.Background(), func(BuildEvent) {})
+	handler := &followUpHumanHandler{inner: &fixedAnswerHandler{answer: "no"}, interviewer: iv}
+	node := &pipeline.Node{
+		ID:    "deploy",
+		Attrs: map[string]string{"followup_1": "Which region?", "followup_1_when": "yes"},
+	}
+
+	out, err := handler.Execute(context.Background(), node, pipeline.NewPipelineContext())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.PreferredLabel != "no" || len(out.ContextUpdates) != 0 {
+		t.Errorf("expected first answer only, got %+v", out)
+	}
+}
+
+func TestBuildQuestionsFragmentRendersSequence(t *testing.T) {
+	srv, projectID, iv := newQuestionsTestServer(t)
+	go func() { _, _ = iv.AskSequence("deploy", []string{"Which region?", "Any release notes?"}) }()
+	waitForQuestions(t, srv, projectID, 2)
+
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+projectID+"/build/questions", nil)
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("HX-Request", "true")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	body := rec.Body.String()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	first := strings.Index(body, "Which region?")
+	second := strings.Index(body, "Any release notes?")
+	if first < 0 || second < 0 || first > second {
+		t.Fatalf("expected both questions in order in fragment:\n%s", body)
+	}
+	if !strings.Contains(body, "question 1 of 2") || !strings.Contains(body, "Answer the previous question first.") {
+		t.Errorf("expected sequence markers in fragment:\n%s", body)
+	}
+	if strings.Count(body, "hx-post=") != 1 {
+		t.Errorf("expected only the first question to be answerable:\n%s", body)
+	}
+}
+
+func TestBuildAnswerNoActiveBuild(t *testing.T) {
+	srv := newTestServer(t)
+	p, err := srv.store.Create("no-build")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec := postAnswer(srv, p.ID, "nope", "x"); rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+}
+
+func TestAnswerPatternReasksOverHTTP(t *testing.T) {
+	srv, projectID, iv := newQuestionsTestServer(t)
+	g, err := pipeline.ParseDOT(`digraph release {
+		start [shape=Mdiamond]
+		version [shape=hexagon, mode="freeform", label="Which version?", answer_pattern="^v[0-9]+[.][0-9]+[.][0-9]+$"]
+		done [shape=Msquare]
+		start -> version -> done
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := handlers.NewDefaultRegistry(g, handlers.WithInterviewer(iv, g))
+	answerpattern.Hook(g)(registry)
+
+	done := make(chan pipeline.Outcome, 1)
+	go func() {
+		out, err := registry.Execute(context.Background(), g.Nodes["version"], pipeline.NewPipelineContext())
+		if err != nil {
+			t.Errorf("handler error: %v", err)
+		}
+		done <- out
+	}()
+
+	first := waitForQuestions(t, srv, projectID, 1)[0]
+	if rec := postAnswer(srv, projectID, first.ID, "latest"); rec.Code != http.StatusOK {
+		t.Fatalf("answer: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	var again PendingGate
+	deadline := time.Now().Add(2 * time.Second)
+	for again.ID == "" || again.ID == first.ID {
+		if time.Now().After(deadline) {
+			t.Fatal("invalid answer was not asked again")
+		}
+		if qs := getQuestions(t, srv, projectID); len(qs) == 1 {
+			again = qs[0]
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !strings.Contains(again.Prompt, `answer "latest" does not match`) || !strings.Contains(again.Prompt, "Which version?") {
+		t.Errorf("re-asked prompt = %q, want the validation error and the question", again.Prompt)
+	}
+	if rec := postAnswer(srv, projectID, again.ID, "v2.0.1"); rec.Code != http.StatusOK {
+		t.Fatalf("second answer: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	select {
+	case out := <-done:
+		if got := out.ContextUpdates[pipeline.ContextKeyHumanResponse]; got != "v2.0.1" {
+			t.Errorf("human_response = %q, want v2.0.1", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("gate did not finish after a valid answer")
+	}
+}
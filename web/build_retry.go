@@ -0,0 +1,112 @@
+// ABOUTME: HTTP handler for retrying a project's last build as a new run.
+// ABOUTME: Starts fresh from the project's pipeline source, or resumes from the previous run's checkpoint.
+package web
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/2389-research/mammoth/runstate"
+	"github.com/2389-research/mammoth/tracing"
+	"github.com/go-chi/chi/v5"
+)
+
+// handleBuildRetry starts a new run for a project whose last build has
+// finished (typically failed or cancelled). By default the new run starts
+// from scratch with the project's pipeline source; with ?from=checkpoint it
+// resumes from a copy of the previous run's checkpoint instead. The previous
+// run's artifacts are left untouched. Responds with the new run ID as JSON,
+// or redirects browsers to the build view.
+func (s *Server) handleBuildRetry(w http.ResponseWriter, r *http.Request) {
+	projectID := chi.URLParam(r, "projectID")
+	p, ok := s.store.Get(projectID)
+	if !ok {
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project not found")
+		return
+	}
+	if p.RunID == "" {
+		http.Error(w, "no previous run to retry", http.StatusNotFound)
+		return
+	}
+	if s.refuseWhileStopping(w, r) {
+		return
+	}
+
+	s.buildsMu.RLock()
+	existing, running := s.builds[projectID]
+	running = running && existing.State != nil && existing.State.Status == "running"
+	s.buildsMu.RUnlock()
+	if running {
+		writeError(w, r, http.StatusConflict, ErrCodeConflict, "build is running")
+		return
+	}
+
+	from := r.URL.Query().Get("from")
+	if from != "" && from != "checkpoint" {
+		http.Error(w, "from must be \"checkpoint\" or omitted", http.StatusBadRequest)
+		return
+	}
+	resume := from == "checkpoint"
+
+	var checkpoint []byte
+	if resume {
+		data, err := os.ReadFile(s.checkpointPath(projectID, p.RunID))
+		if errors.Is(err, os.ErrNotExist) {
+			http.Error(w, "previous run has no checkpoint", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Printf("component=web.build action=read_checkpoint_failed project_id=%s run_id=%s err=%v", projectID, p.RunID, err)
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+		checkpoint = data
+	}
+
+	runID, err := runstate.GenerateRunID()
+	if err != nil {
+		log.Printf("component=web.build action=generate_run_id_failed project_id=%s err=%v", projectID, err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	if resume {
+		cpPath := s.checkpointPath(projectID, runID)
+		if err := os.MkdirAll(filepath.Dir(cpPath), 0o755); err == nil {
+			err = os.WriteFile(cpPath, checkpoint, 0o600)
+		}
+		if err != nil {
+			log.Printf("component=web.build action=copy_checkpoint_failed project_id=%s run_id=%s err=%v", projectID, runID, err)
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	previousRunID := p.RunID
+	p.Phase = PhaseBuild
+	p.RunID = runID
+	p.Diagnostics = nil
+	if err := s.store.Update(p); err != nil {
+		log.Printf("component=web.build action=update_project_failed project_id=%s phase=build err=%v", projectID, err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("component=web.build action=retry project_id=%s run_id=%s previous_run_id=%s resume=%t", projectID, runID, previousRunID, resume)
+	if err := s.startBuildExecution(tracing.RequestContext(r), projectID, p, runID, resume); err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, ErrCodeUnavailable, err.Error())
+		return
+	}
+
+	if !wantsJSON(r) {
+		http.Redirect(w, r, "/projects/"+projectID+"/build", http.StatusSeeOther)
+		return
+	}
+	writeSpecJSON(w, http.StatusCreated, map[string]any{
+		"run_id":          runID,
+		"previous_run_id": previousRunID,
+		"resumed":         resume,
+	})
+}
@@ -0,0 +1,139 @@
+// ABOUTME: Tests for retrying a project's last build as a new run over HTTP.
+// ABOUTME: Covers a fresh retry after a failed run, resuming from the previous checkpoint, and conflicts.
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// waitForBuildStatus polls until the project's build leaves the running state
+// and returns its final state.
+func waitForBuildStatus(t *testing.T, srv *Server, projectID string) RunState {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		srv.buildsMu.RLock()
+		run := srv.builds[projectID]
+		var state RunState
+		if run != nil && run.State != nil {
+			state = *run.State
+		}
+		srv.buildsMu.RUnlock()
+		if state.Status != "" && state.Status != "running" {
+			return state
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("timed out waiting for build to finish")
+	return RunState{}
+}
+
+func postRetry(srv *Server, projectID, query string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/projects/"+projectID+"/build/retry"+query, nil)
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestBuildRetryStartsFreshRun(t *testing.T) {
+	srv := newTestServer(t)
+	p, err := srv.store.Create("retry-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.DOT = `digraph p {
+		start [shape=Mdiamond]
+		check [shape=parallelogram, command="exit 1"]
+		done [shape=Msquare]
+		start -> check -> done
+	}`
+	p.RunID = "failed-run"
+	p.Phase = PhaseBuild
+	if err := srv.store.Update(p); err != nil {
+		t.Fatal(err)
+	}
+	srv.startBuildExecution(context.Background(), p.ID, p, "failed-run", false)
+	if state := waitForBuildStatus(t, srv, p.ID); state.Status != "failed" {
+		t.Fatalf("first run status = %q, want failed", state.Status)
+	}
+
+	rec := postRetry(srv, p.ID, "")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("retry status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		RunID         string `json:"run_id"`
+		PreviousRunID string `json:"previous_run_id"`
+		Resumed       bool   `json:"resumed"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.RunID == "" || resp.RunID == "failed-run" {
+		t.Errorf("run_id = %q, want a new distinct run", resp.RunID)
+	}
+	if resp.PreviousRunID != "failed-run" || resp.Resumed {
+		t.Errorf("response = %+v, want a fresh retry of failed-run", resp)
+	}
+
+	state := waitForBuildStatus(t, srv, p.ID)
+	if state.ID != resp.RunID {
+		t.Errorf("active build run = %q, want %q", state.ID, resp.RunID)
+	}
+	retried, _ := srv.store.Get(p.ID)
+	if retried.RunID != resp.RunID || retried.DOT != p.DOT {
+		t.Errorf("project after retry = run %q, want run %q with the same source", retried.RunID, resp.RunID)
+	}
+}
+
+func TestBuildRetryFromCheckpoint(t *testing.T) {
+	srv := newTestServer(t)
+	p := newCheckpointTestProject(t, srv)
+	p.RunID = "old-run"
+	p.Phase = PhaseBuild
+	p.Diagnostics = []string{"Build failed."}
+	if err := srv.store.Update(p); err != nil {
+		t.Fatal(err)
+	}
+
+	if rec := postRetry(srv, p.ID, "?from=checkpoint"); rec.Code != http.StatusNotFound {
+		t.Fatalf("retry without checkpoint status = %d, want 404", rec.Code)
+	}
+
+	raw := `{"run_id":"old-run","current_node":"done","completed_nodes":["start","work"],"retry_counts":{},"context":{}}`
+	cpPath := srv.checkpointPath(p.ID, "old-run")
+	if err := os.MkdirAll(filepath.Dir(cpPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cpPath, []byte(raw), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := postRetry(srv, p.ID, "?from=checkpoint")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("retry status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	state := waitForBuildStatus(t, srv, p.ID)
+	if state.ID == "old-run" {
+		t.Error("retry reused the previous run ID")
+	}
+	if state.Status != "completed" {
+		t.Errorf("resumed run status = %q (%s), want completed without rerunning work", state.Status, state.Error)
+	}
+}
+
+func TestBuildRetryRejectsWithoutPreviousRun(t *testing.T) {
+	srv := newTestServer(t)
+	p := newCheckpointTestProject(t, srv)
+	if rec := postRetry(srv, p.ID, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+}
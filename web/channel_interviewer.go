@@ -6,18 +6,44 @@ import (
 	"context"
 	"crypto/rand"
 	"fmt"
+	"log"
+	"sort"
 	"sync"
 	"time"
 )
 
+// PendingGate describes a human gate question awaiting an answer. Gates
+// belonging to the same node form a sequence that must be answered in order.
+type PendingGate struct {
+	ID        string    `json:"id"`
+	NodeID    string    `json:"node_id,omitempty"`
+	Prompt    string    `json:"prompt"`
+	Choices   []string  `json:"choices,omitempty"`
+	Default   string    `json:"default,omitempty"`
+	Freeform  bool      `json:"freeform"`
+	Seq       int       `json:"seq"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// pendingGate pairs a gate's public description with its answer channel.
+type pendingGate struct {
+	PendingGate
+	ch       chan string
+	answered bool
+}
+
 // ChannelInterviewer implements handlers.Interviewer and handlers.FreeformInterviewer.
 // When the pipeline hits a human gate, it broadcasts a BuildEvent and blocks
 // until Respond() is called with the user's answer or the context is cancelled.
 type ChannelInterviewer struct {
 	broadcast func(BuildEvent)
-	pending   map[string]chan string
+	pending   map[string]*pendingGate
+	nextSeq   int
 	mu        sync.Mutex
 	ctx       context.Context
+
+	// decisions, when set, receives every answered gate for auditing.
+	decisions *DecisionLog
 }
 
 // NewChannelInterviewer creates a ChannelInterviewer that broadcasts gate events
@@ -25,7 +51,7 @@ type ChannelInterviewer struct {
 func NewChannelInterviewer(ctx context.Context, broadcast func(BuildEvent)) *ChannelInterviewer {
 	return &ChannelInterviewer{
 		broadcast: broadcast,
-		pending:   make(map[string]chan string),
+		pending:   make(map[string]*pendingGate),
 		ctx:       ctx,
 	}
 }
@@ -36,89 +62,175 @@ func (iv *ChannelInterviewer) Ask(prompt string, choices []string, defaultChoice
 	if err := iv.ctx.Err(); err != nil {
 		return "", err
 	}
+	gate := iv.open("", PendingGate{Prompt: prompt, Choices: choices, Default: defaultChoice})
+	defer iv.close(gate.ID)
+	return iv.wait(gate)
+}
 
-	gateID := generateGateID()
-	ch := make(chan string, 1)
+// AskFreeform presents an open-ended text input gate. Blocks until Respond()
+// is called or the context is cancelled.
+func (iv *ChannelInterviewer) AskFreeform(prompt string) (string, error) {
+	if err := iv.ctx.Err(); err != nil {
+		return "", err
+	}
+	gate := iv.open("", PendingGate{Prompt: prompt, Freeform: true})
+	defer iv.close(gate.ID)
+	return iv.wait(gate)
+}
 
-	iv.mu.Lock()
-	iv.pending[gateID] = ch
-	iv.mu.Unlock()
+// AskSequence presents several freeform questions for one node at once and
+// blocks until all of them are answered. Respond only accepts answers for a
+// node's questions in the order they were asked. Answers are returned in
+// prompt order.
+func (iv *ChannelInterviewer) AskSequence(nodeID string, prompts []string) ([]string, error) {
+	if err := iv.ctx.Err(); err != nil {
+		return nil, err
+	}
 
+	gates := make([]*pendingGate, len(prompts))
+	for i, prompt := range prompts {
+		gates[i] = iv.open(nodeID, PendingGate{Prompt: prompt, Freeform: true})
+	}
 	defer func() {
-		iv.mu.Lock()
-		delete(iv.pending, gateID)
-		iv.mu.Unlock()
+		for _, g := range gates {
+			iv.close(g.ID)
+		}
 	}()
 
-	iv.broadcast(BuildEvent{
-		Type:      BuildEventHumanGateChoice,
-		Timestamp: time.Now(),
-		Message:   prompt,
-		Data: map[string]any{
-			"gate_id": gateID,
-			"choices": choices,
-			"default": defaultChoice,
-		},
-	})
-
-	select {
-	case answer := <-ch:
-		return answer, nil
-	case <-iv.ctx.Done():
-		return "", iv.ctx.Err()
+	answers := make([]string, 0, len(gates))
+	for _, g := range gates {
+		answer, err := iv.wait(g)
+		if err != nil {
+			return nil, err
+		}
+		answers = append(answers, answer)
 	}
+	return answers, nil
 }
 
-// AskFreeform presents an open-ended text input gate. Blocks until Respond()
-// is called or the context is cancelled.
-func (iv *ChannelInterviewer) AskFreeform(prompt string) (string, error) {
-	if err := iv.ctx.Err(); err != nil {
-		return "", err
+// Pending returns the gates awaiting an answer, in the order they were asked.
+func (iv *ChannelInterviewer) Pending() []PendingGate {
+	iv.mu.Lock()
+	defer iv.mu.Unlock()
+	out := make([]PendingGate, 0, len(iv.pending))
+	for _, g := range iv.pending {
+		if !g.answered {
+			out = append(out, g.PendingGate)
+		}
 	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Seq < out[j].Seq })
+	return out
+}
 
-	gateID := generateGateID()
-	ch := make(chan string, 1)
-
+// SetDecisionLog records every answered gate in l. Must be called before
+// the pipeline starts asking questions.
+func (iv *ChannelInterviewer) SetDecisionLog(l *DecisionLog) {
 	iv.mu.Lock()
-	iv.pending[gateID] = ch
+	iv.decisions = l
 	iv.mu.Unlock()
+}
 
-	defer func() {
-		iv.mu.Lock()
-		delete(iv.pending, gateID)
+// Respond delivers the user's answer to a pending gate. It returns an error
+// if the gate ID is unknown, the gate has already been answered, or an
+// earlier question for the same node is still unanswered.
+func (iv *ChannelInterviewer) Respond(gateID, answer string) error {
+	return iv.RespondAs(gateID, answer, "")
+}
+
+// RespondAs is Respond with the identity of whoever answered, recorded in
+// the decision log when one is set. answeredBy may be empty.
+func (iv *ChannelInterviewer) RespondAs(gateID, answer, answeredBy string) error {
+	iv.mu.Lock()
+	g, ok := iv.pending[gateID]
+	if !ok {
 		iv.mu.Unlock()
-	}()
+		return fmt.Errorf("no pending gate %q", gateID)
+	}
+	if g.answered {
+		iv.mu.Unlock()
+		return fmt.Errorf("gate %q already answered", gateID)
+	}
+	if g.NodeID != "" {
+		for _, other := range iv.pending {
+			if other.NodeID == g.NodeID && other.Seq < g.Seq && !other.answered {
+				iv.mu.Unlock()
+				return fmt.Errorf("gate %q must be answered before gate %q", other.ID, gateID)
+			}
+		}
+	}
+	g.answered = true
+	g.ch <- answer
+	decisions := iv.decisions
+	iv.mu.Unlock()
+
+	if decisions != nil {
+		d := GateDecision{
+			GateID:     g.ID,
+			NodeID:     g.NodeID,
+			Question:   g.Prompt,
+			Options:    g.Choices,
+			Freeform:   g.Freeform,
+			Answer:     answer,
+			AnsweredBy: answeredBy,
+			AskedAt:    g.CreatedAt,
+			AnsweredAt: time.Now(),
+		}
+		if err := decisions.Append(d); err != nil {
+			log.Printf("component=web.build action=record_decision_failed gate_id=%s err=%v", gateID, err)
+		}
+	}
+	return nil
+}
+
+// open registers a new pending gate and broadcasts it to the browser.
+func (iv *ChannelInterviewer) open(nodeID string, info PendingGate) *pendingGate {
+	info.ID = generateGateID()
+	info.NodeID = nodeID
+	info.CreatedAt = time.Now()
 
+	iv.mu.Lock()
+	iv.nextSeq++
+	info.Seq = iv.nextSeq
+	g := &pendingGate{PendingGate: info, ch: make(chan string, 1)}
+	iv.pending[info.ID] = g
+	iv.mu.Unlock()
+
+	evtType := BuildEventHumanGateChoice
+	data := map[string]any{"gate_id": info.ID}
+	if info.Freeform {
+		evtType = BuildEventHumanGateFreeform
+	} else {
+		data["choices"] = info.Choices
+		data["default"] = info.Default
+	}
+	if nodeID != "" {
+		data["node_id"] = nodeID
+	}
 	iv.broadcast(BuildEvent{
-		Type:      BuildEventHumanGateFreeform,
-		Timestamp: time.Now(),
-		Message:   prompt,
-		Data:      map[string]any{"gate_id": gateID},
+		Type:      evtType,
+		Timestamp: info.CreatedAt,
+		NodeID:    nodeID,
+		Message:   info.Prompt,
+		Data:      data,
 	})
+	return g
+}
 
+// wait blocks until the gate is answered or the context is cancelled.
+func (iv *ChannelInterviewer) wait(g *pendingGate) (string, error) {
 	select {
-	case answer := <-ch:
+	case answer := <-g.ch:
 		return answer, nil
 	case <-iv.ctx.Done():
 		return "", iv.ctx.Err()
 	}
 }
 
-// Respond delivers the user's answer to a pending gate. It returns an error
-// if the gate ID is unknown or the gate has already been answered.
-func (iv *ChannelInterviewer) Respond(gateID, answer string) error {
+// close removes a gate from the pending set.
+func (iv *ChannelInterviewer) close(gateID string) {
 	iv.mu.Lock()
-	ch, ok := iv.pending[gateID]
+	delete(iv.pending, gateID)
 	iv.mu.Unlock()
-	if !ok {
-		return fmt.Errorf("no pending gate %q", gateID)
-	}
-	select {
-	case ch <- answer:
-		return nil
-	default:
-		return fmt.Errorf("gate %q already answered", gateID)
-	}
 }
 
 func generateGateID() string {
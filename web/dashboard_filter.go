@@ -0,0 +1,97 @@
+// ABOUTME: Server-side filtering of the project dashboard by run status and a search term.
+// ABOUTME: Reads ?status= and ?q= so a busy server's listing stays manageable in the browser and the JSON API.
+package web
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// dashboardStatuses are the status filter choices offered on the dashboard:
+// the outcome of a project's latest build, then the wizard phases of
+// projects without one.
+var dashboardStatuses = []string{"running", "completed", "failed", "cancelled", string(PhaseSpec), string(PhaseEdit), string(PhaseBuild)}
+
+// dotGraphName matches the name of a DOT digraph, quoted or not.
+var dotGraphName = regexp.MustCompile(`^\s*(?:strict\s+)?digraph\s+"?([^"{\s]+)`)
+
+// ProjectFilter narrows the project list. Status matches a project's status
+// (see projectStatus) or its phase; Query is a case-insensitive substring of
+// the project name or its pipeline's graph name. Empty fields match
+// everything.
+type ProjectFilter struct {
+	Status string
+	Query  string
+}
+
+// parseProjectFilter reads the filter from the request's query string.
+func parseProjectFilter(r *http.Request) ProjectFilter {
+	q := r.URL.Query()
+	return ProjectFilter{
+		Status: strings.ToLower(strings.TrimSpace(q.Get("status"))),
+		Query:  strings.TrimSpace(q.Get("q")),
+	}
+}
+
+// Active reports whether the filter excludes anything.
+func (f ProjectFilter) Active() bool {
+	return f.Status != "" || f.Query != ""
+}
+
+// projectStatus is the status the dashboard shows for p: its live build's
+// status, else the outcome its last build left on the project, else its
+// phase.
+func (s *Server) projectStatus(p *Project) string {
+	s.buildsMu.RLock()
+	run, ok := s.builds[p.ID]
+	var status string
+	if ok && run.State != nil {
+		status = run.State.Status
+	}
+	s.buildsMu.RUnlock()
+	if status != "" {
+		return status
+	}
+
+	if p.Phase == PhaseDone {
+		return "completed"
+	}
+	if len(p.Diagnostics) > 0 {
+		switch {
+		case strings.HasPrefix(p.Diagnostics[0], "Build failed"):
+			return "failed"
+		case strings.HasPrefix(p.Diagnostics[0], "Build cancelled"):
+			return "cancelled"
+		}
+	}
+	return string(p.Phase)
+}
+
+// filterProjects returns the projects matching f, in order, along with each
+// project's status keyed by project ID.
+func (s *Server) filterProjects(projects []*Project, f ProjectFilter) ([]*Project, map[string]string) {
+	query := strings.ToLower(f.Query)
+	matched := make([]*Project, 0, len(projects))
+	statuses := make(map[string]string, len(projects))
+	for _, p := range projects {
+		status := s.projectStatus(p)
+		if f.Status != "" && f.Status != status && f.Status != string(p.Phase) {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(p.Name), query) && !strings.Contains(strings.ToLower(pipelineName(p.DOT)), query) {
+			continue
+		}
+		matched = append(matched, p)
+		statuses[p.ID] = status
+	}
+	return matched, statuses
+}
+
+// pipelineName returns the graph name of a DOT source, or "" if it has none.
+func pipelineName(source string) string {
+	if m := dotGraphName.FindStringSubmatch(source); m != nil {
+		return m[1]
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+// ABOUTME: Tests for dashboard filtering by ?status= and ?q= on the project list.
+// ABOUTME: Seeds projects in different states and checks the HTML and JSON listings include only matching ones.
+package web
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// seedDashboardProjects creates one project per status the dashboard
+// distinguishes and returns them by name.
+func seedDashboardProjects(t *testing.T, srv *Server) map[string]*Project {
+	t.Helper()
+	projects := map[string]*Project{}
+	for _, name := range []string{"deploy-api", "deploy-web", "docs-site", "billing"} {
+		p, err := srv.store.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		projects[name] = p
+	}
+
+	failed := projects["deploy-api"]
+	failed.Phase, failed.Diagnostics = PhaseBuild, []string{"Build failed: exit status 1"}
+	done := projects["docs-site"]
+	done.Phase = PhaseDone
+	// The pipeline's graph name is searchable too.
+	edit := projects["billing"]
+	edit.Phase, edit.DOT = PhaseEdit, "digraph deploy_invoices {\n  start -> done\n}"
+	for _, p := range []*Project{failed, done, edit} {
+		if err := srv.store.Update(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv.buildsMu.Lock()
+	srv.builds[projects["deploy-web"].ID] = &BuildRun{State: &RunState{Status: "running"}}
+	srv.buildsMu.Unlock()
+	return projects
+}
+
+func getDashboard(t *testing.T, srv *Server, target string) string {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Header.Set("Accept", "text/html")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET %s: status %d", target, rec.Code)
+	}
+	body, _ := io.ReadAll(rec.Body)
+	return string(body)
+}
+
+func TestDashboardFilter(t *testing.T) {
+	srv := newTestServer(t)
+	seedDashboardProjects(t, srv)
+
+	tests := []struct {
+		target string
+		want   []string
+	}{
+		{"/?status=failed&q=deploy", []string{"deploy-api"}},
+		{"/?q=DEPLOY", []string{"deploy-api", "deploy-web", "billing"}},
+		{"/?status=running", []string{"deploy-web"}},
+		{"/?status=completed", []string{"docs-site"}},
+		{"/?status=edit", []string{"billing"}},
+		{"/", []string{"deploy-api", "deploy-web", "docs-site", "billing"}},
+	}
+	all := []string{"deploy-api", "deploy-web", "docs-site", "billing"}
+	for _, tt := range tests {
+		body := getDashboard(t, srv, tt.target)
+		for _, name := range all {
+			want := false
+			for _, w := range tt.want {
+				want = want || w == name
+			}
+			if got := strings.Contains(body, "<h3 style=\"margin: 0;\">"+name+"</h3>"); got != want {
+				t.Errorf("GET %s: listed %s = %v, want %v", tt.target, name, got, want)
+			}
+		}
+	}
+}
+
+func TestDashboardFilterNoMatches(t *testing.T) {
+	srv := newTestServer(t)
+	seedDashboardProjects(t, srv)
+
+	body := getDashboard(t, srv, "/?status=cancelled")
+	if !strings.Contains(body, "No projects match these filters.") {
+		t.Error("expected the no-match message")
+	}
+	if !strings.Contains(body, `<option value="cancelled" selected>`) || !strings.Contains(body, `href="/" class="btn">Clear`) {
+		t.Error("expected the filter controls to reflect the active filter")
+	}
+}
+
+func TestProjectListJSONFilter(t *testing.T) {
+	srv := newTestServer(t)
+	seedDashboardProjects(t, srv)
+
+	req := httptest.NewRequest(http.MethodGet, "/projects?status=build&q=api", nil)
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	var projects []Project
+	if err := json.NewDecoder(rec.Body).Decode(&projects); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(projects) != 1 || projects[0].Name != "deploy-api" {
+		t.Errorf("projects = %+v, want only deploy-api", projects)
+	}
+}
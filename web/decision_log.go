@@ -0,0 +1,186 @@
+// ABOUTME: Opt-in audit log of human gate decisions, appended as JSON lines beside the run's checkpoint.
+// ABOUTME: Records each question, its options, the answer, who gave it (basic auth or a trusted proxy), and when; served at /build/decisions.
+package web
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// decisionLogFile is the audit log's file name within a run's checkpoint directory.
+const decisionLogFile = "decisions.jsonl"
+
+// GateDecision is one answered human gate question as recorded in the audit log.
+type GateDecision struct {
+	GateID     string    `json:"gate_id"`
+	NodeID     string    `json:"node_id,omitempty"`
+	Question   string    `json:"question"`
+	Options    []string  `json:"options,omitempty"`
+	Freeform   bool      `json:"freeform"`
+	Answer     string    `json:"answer"`
+	AnsweredBy string    `json:"answered_by,omitempty"`
+	AskedAt    time.Time `json:"asked_at"`
+	AnsweredAt time.Time `json:"answered_at"`
+}
+
+// DecisionLog appends gate decisions to a JSON-lines file. It is kept apart
+// from the event stream so its history is never trimmed.
+type DecisionLog struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewDecisionLog returns a log that appends to path, creating it on first use.
+func NewDecisionLog(path string) *DecisionLog {
+	return &DecisionLog{path: path}
+}
+
+// Append writes one decision to the end of the log.
+func (l *DecisionLog) Append(d GateDecision) error {
+	line, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("encode decision: %w", err)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return fmt.Errorf("create decision log dir: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open decision log: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("write decision log: %w", err)
+	}
+	return f.Close()
+}
+
+// ReadDecisions returns the decisions recorded at path in the order they
+// were made. A missing file means no decisions yet.
+func ReadDecisions(path string) ([]GateDecision, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []GateDecision{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	decisions := []GateDecision{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	for scanner.Scan() {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var d GateDecision
+		if err := json.Unmarshal(scanner.Bytes(), &d); err != nil {
+			return nil, fmt.Errorf("decode decision: %w", err)
+		}
+		decisions = append(decisions, d)
+	}
+	return decisions, scanner.Err()
+}
+
+// requestIdentity returns who sent an answer, when the request says: the
+// basic-auth user, or the X-Forwarded-User header when the request comes
+// from one of the server's trusted proxies. Anyone can set the header, so
+// it is ignored from every other address.
+func (s *Server) requestIdentity(r *http.Request) string {
+	if user, _, ok := r.BasicAuth(); ok && user != "" {
+		return user
+	}
+	if !s.fromTrustedProxy(r) {
+		return ""
+	}
+	return strings.TrimSpace(r.Header.Get("X-Forwarded-User"))
+}
+
+// fromTrustedProxy reports whether r's peer address is in one of the
+// server's trusted proxy ranges.
+func (s *Server) fromTrustedProxy(r *http.Request) bool {
+	if len(s.trustedProxies) == 0 {
+		return false
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range s.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTrustedProxies parses proxy addresses and CIDR ranges, such as
+// "127.0.0.1" or "10.0.0.0/8", into prefixes.
+func parseTrustedProxies(specs []string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		if strings.Contains(spec, "/") {
+			prefix, err := netip.ParsePrefix(spec)
+			if err != nil {
+				return nil, fmt.Errorf("trusted proxy %q: %w", spec, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(spec)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q: %w", spec, err)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// handleBuildDecisions serves the audit log of the project's current run as
+// JSON. The list is empty when the run has made no decisions or the server
+// was started without decision auditing.
+func (s *Server) handleBuildDecisions(w http.ResponseWriter, r *http.Request) {
+	projectID := chi.URLParam(r, "projectID")
+	p, ok := s.store.Get(projectID)
+	if !ok {
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project not found")
+		return
+	}
+	decisions := []GateDecision{}
+	if p.RunID != "" {
+		var err error
+		decisions, err = ReadDecisions(filepath.Join(s.workspace.CheckpointDir(projectID, p.RunID), decisionLogFile))
+		if err != nil {
+			log.Printf("component=web.build action=read_decisions_failed project_id=%s run_id=%s err=%v", projectID, p.RunID, err)
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+	writeSpecJSON(w, http.StatusOK, map[string]any{"run_id": p.RunID, "decisions": decisions})
+}
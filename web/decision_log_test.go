@@ -0,0 +1,181 @@
+// ABOUTME: Tests for the human gate decision audit log and the /build/decisions endpoint.
+// ABOUTME: Verifies choice and free-text answers are recorded with question, options, answer, identity, and timestamps.
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecisionLogRecordsChoiceAndFreeformAnswers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run", decisionLogFile)
+	iv := NewChannelInterviewer(context.Background(), func(BuildEvent) {})
+	iv.SetDecisionLog(NewDecisionLog(path))
+
+	before := time.Now()
+	answers := make(chan string, 2)
+	go func() {
+		a, _ := iv.Ask("Deploy to production?", []string{"yes", "no"}, "no")
+		answers <- a
+		a, _ = iv.AskFreeform("Release notes?")
+		answers <- a
+	}()
+
+	gate := waitForPending(t, iv)
+	if err := iv.RespondAs(gate.ID, "yes", "alice"); err != nil {
+		t.Fatalf("respond: %v", err)
+	}
+	<-answers
+	gate = waitForPending(t, iv)
+	if err := iv.Respond(gate.ID, "Fixes the login bug."); err != nil {
+		t.Fatalf("respond: %v", err)
+	}
+	<-answers
+
+	decisions, err := ReadDecisions(path)
+	if err != nil {
+		t.Fatalf("read decisions: %v", err)
+	}
+	if len(decisions) != 2 {
+		t.Fatalf("got %d decisions, want 2: %+v", len(decisions), decisions)
+	}
+
+	choice := decisions[0]
+	if choice.Question != "Deploy to production?" || !slices.Equal(choice.Options, []string{"yes", "no"}) {
+		t.Errorf("choice decision = %+v, want the question and its options", choice)
+	}
+	if choice.Answer != "yes" || choice.AnsweredBy != "alice" || choice.Freeform {
+		t.Errorf("choice decision = %+v, want answer yes by alice", choice)
+	}
+	if choice.AskedAt.Before(before) || choice.AnsweredAt.Before(choice.AskedAt) {
+		t.Errorf("choice timestamps asked=%v answered=%v out of order", choice.AskedAt, choice.AnsweredAt)
+	}
+
+	text := decisions[1]
+	if text.Question != "Release notes?" || !text.Freeform || text.Answer != "Fixes the login bug." || text.AnsweredBy != "" {
+		t.Errorf("freeform decision = %+v", text)
+	}
+}
+
+func TestReadDecisionsMissingFile(t *testing.T) {
+	decisions, err := ReadDecisions(filepath.Join(t.TempDir(), decisionLogFile))
+	if err != nil || len(decisions) != 0 {
+		t.Errorf("got %v, %v; want an empty log", decisions, err)
+	}
+}
+
+func TestBuildDecisionsEndpoint(t *testing.T) {
+	srv, projectID, iv := newQuestionsTestServer(t)
+	// httptest requests come from 192.0.2.1.
+	proxies, err := parseTrustedProxies([]string{"192.0.2.0/24"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.trustedProxies = proxies
+	p, _ := srv.store.Get(projectID)
+	p.RunID = "questions-run"
+	if err := srv.store.Update(p); err != nil {
+		t.Fatal(err)
+	}
+	iv.SetDecisionLog(NewDecisionLog(filepath.Join(srv.workspace.CheckpointDir(projectID, p.RunID), decisionLogFile)))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = iv.Ask("Ship it?", []string{"ship", "hold"}, "")
+	}()
+	qs := waitForQuestions(t, srv, projectID, 1)
+
+	body := url.Values{"answer": {"ship"}}
+	answer := httptest.NewRequest(http.MethodPost, "/projects/"+projectID+"/build/questions/"+qs[0].ID, strings.NewReader(body.Encode()))
+	answer.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	answer.Header.Set("X-Forwarded-User", "bob")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, answer)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("answer status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	<-done
+
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+projectID+"/build/decisions", nil)
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET decisions status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		RunID     string         `json:"run_id"`
+		Decisions []GateDecision `json:"decisions"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.RunID != "questions-run" || len(resp.Decisions) != 1 {
+		t.Fatalf("response = %+v, want one decision for questions-run", resp)
+	}
+	d := resp.Decisions[0]
+	if d.Question != "Ship it?" || d.Answer != "ship" || d.AnsweredBy != "bob" || d.AnsweredAt.IsZero() {
+		t.Errorf("decision = %+v", d)
+	}
+}
+
+// waitForPending returns the interviewer's first pending gate once one exists.
+func waitForPending(t *testing.T, iv *ChannelInterviewer) PendingGate {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if pending := iv.Pending(); len(pending) > 0 {
+			return pending[0]
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("timed out waiting for a pending gate")
+	return PendingGate{}
+}
+
+func TestRequestIdentityTrustsOnlyConfiguredProxies(t *testing.T) {
+	proxies, err := parseTrustedProxies([]string{"10.0.0.0/8", " 127.0.0.1 ", ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	trusting := &Server{trustedProxies: proxies}
+	tests := []struct {
+		name   string
+		srv    *Server
+		remote string
+		basic  string
+		want   string
+	}{
+		{"trusted range", trusting, "10.1.2.3:5000", "", "bob"},
+		{"trusted address", trusting, "127.0.0.1:5000", "", "bob"},
+		{"untrusted peer", trusting, "203.0.113.9:5000", "", ""},
+		{"no proxies configured", &Server{}, "127.0.0.1:5000", "", ""},
+		{"basic auth wins", trusting, "10.1.2.3:5000", "alice", "alice"},
+		{"basic auth without proxy", &Server{}, "203.0.113.9:5000", "alice", "alice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			r.RemoteAddr = tt.remote
+			r.Header.Set("X-Forwarded-User", "bob")
+			if tt.basic != "" {
+				r.SetBasicAuth(tt.basic, "secret")
+			}
+			if got := tt.srv.requestIdentity(r); got != tt.want {
+				t.Errorf("requestIdentity = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := parseTrustedProxies([]string{"proxy.internal"}); err == nil {
+		t.Error("expected an error for a host name")
+	}
+}
@@ -26,7 +26,7 @@ func (s *Server) handleProjectEditorEntry(w http.ResponseWriter, r *http.Request
 	projectID := chi.URLParam(r, "projectID")
 	p, ok := s.store.Get(projectID)
 	if !ok {
-		http.Error(w, "project not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project not found")
 		return
 	}
 
@@ -47,7 +47,7 @@ func (s *Server) editorProxyHandler() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		projectID := chi.URLParam(r, "projectID")
 		if _, ok := s.store.Get(projectID); !ok {
-			http.Error(w, "project not found", http.StatusNotFound)
+			writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project not found")
 			return
 		}
 
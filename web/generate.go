@@ -14,8 +14,20 @@ import (
 	"strings"
 	"time"
 
+	"github.com/2389-research/mammoth/answerpattern"
+	"github.com/2389-research/mammoth/artifactcap"
+	"github.com/2389-research/mammoth/genparams"
+	"github.com/2389-research/mammoth/nextnode"
+	"github.com/2389-research/mammoth/nodelock"
+	"github.com/2389-research/mammoth/providerpin"
+	"github.com/2389-research/mammoth/retrybackoff"
+	"github.com/2389-research/mammoth/skipif"
 	"github.com/2389-research/mammoth/spec/core"
 	"github.com/2389-research/mammoth/spec/export"
+	"github.com/2389-research/mammoth/streaming"
+	"github.com/2389-research/mammoth/successif"
+	"github.com/2389-research/mammoth/tokenbudget"
+	"github.com/2389-research/mammoth/toolguard"
 	"github.com/2389-research/tracker/agent"
 	"github.com/2389-research/tracker/agent/exec"
 	"github.com/2389-research/tracker/pipeline"
@@ -45,10 +57,11 @@ func (s *Server) startGenerationBuild(projectID, specMarkdown string) string {
 	}
 
 	run := &BuildRun{
-		State:  state,
-		Events: events,
-		Cancel: cancel,
-		Ctx:    ctx,
+		State:      state,
+		Events:     events,
+		Cancel:     cancel,
+		Ctx:        ctx,
+		MaxHistory: s.maxEventHistory,
 	}
 	run.EnsureFanoutStarted()
 
@@ -140,6 +153,17 @@ func (s *Server) startGenerationBuild(projectID, specMarkdown string) string {
 
 		// Parse the embedded meta-pipeline DOT.
 		graph, parseErr := pipeline.ParseDOT(metaPipelineDOT)
+		// A pinned provider is checked only when the run talks to a real backend.
+		if parseErr == nil && s.llmClient != nil {
+			parseErr = providerpin.Check(graph)
+		}
+		if parseErr == nil {
+			providerpin.Apply(graph)
+		}
+		var tokenAllocs map[string]int64
+		if parseErr == nil {
+			tokenAllocs, parseErr = tokenbudget.Allocations(graph)
+		}
 		if parseErr != nil {
 			s.buildsMu.Lock()
 			completedAt := time.Now()
@@ -161,11 +185,22 @@ func (s *Server) startGenerationBuild(projectID, specMarkdown string) string {
 			handlers.WithInterviewer(interviewer, graph),
 		}
 		if s.llmClient != nil {
-			registryOpts = append(registryOpts, handlers.WithLLMClient(s.llmClient, workDir))
-			registryOpts = append(registryOpts, handlers.WithExecEnvironment(exec.NewLocalEnvironment(workDir)))
+			registryOpts = append(registryOpts, handlers.WithLLMClient(toolguard.Completer(tokenbudget.Completer(genparams.Completer(streaming.Completer(s.llmClient))), agentHandler), workDir))
+			registryOpts = append(registryOpts, handlers.WithExecEnvironment(toolguard.Environment(exec.NewLocalEnvironment(workDir))))
 			registryOpts = append(registryOpts, handlers.WithAgentEventHandler(agentHandler))
 		}
 		registry := handlers.NewDefaultRegistry(graph, registryOpts...)
+		genparams.Hook(registry)
+		streaming.Hook(registry)
+		retrybackoff.Hook("none", pipelineHandler)(registry)
+		artifactcap.Hook(workDir, 0)(registry)
+		answerpattern.Hook(graph)(registry)
+		tokenbudget.Hook(tokenAllocs)(registry)
+		successif.Hook(graph)(registry)
+		nodelock.Hook(graph, s.nodeLocks)(registry)
+		toolguard.Hook(graph)(registry)
+		skipif.Hook(graph)(registry)
+		nextnode.Hook(graph)(registry)
 		engine := pipeline.NewEngine(graph, registry, opts...)
 
 		_, runErr := engine.Run(ctx)
@@ -217,7 +252,7 @@ func (s *Server) handleGeneratePipeline(w http.ResponseWriter, r *http.Request)
 	projectID := chi.URLParam(r, "projectID")
 	p, ok := s.store.Get(projectID)
 	if !ok {
-		http.Error(w, "project not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project not found")
 		return
 	}
 
@@ -227,7 +262,7 @@ func (s *Server) handleGeneratePipeline(w http.ResponseWriter, r *http.Request)
 	runningNow := hasRun && existingRun != nil && existingRun.State != nil && existingRun.State.Status == "running"
 	s.buildsMu.RUnlock()
 	if runningNow {
-		http.Error(w, "a build is already running for this project", http.StatusConflict)
+		writeError(w, r, http.StatusConflict, ErrCodeConflict, "a build is already running for this project")
 		return
 	}
 
@@ -1,11 +1,87 @@
-// ABOUTME: Creates ChannelInterviewer instances for web build execution.
+// ABOUTME: Creates ChannelInterviewer instances for web build execution and wraps human gates with follow-ups.
 // ABOUTME: The interviewer bridges pipeline human gates to SSE events for the browser.
 package web
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/2389-research/tracker/pipeline"
+)
 
 // newBuildInterviewer creates a ChannelInterviewer wired to the given
 // build run's SSE broadcast function. The context is used for cancellation.
 func newBuildInterviewer(ctx context.Context, broadcast func(BuildEvent)) *ChannelInterviewer {
 	return NewChannelInterviewer(ctx, broadcast)
 }
+
+// followUpHumanHandler wraps the wait.human handler so a gate can ask
+// follow-up questions after its first answer. Follow-ups are declared with
+// numbered node attributes:
+//
+//	deploy [shape=hexagon, label="Deploy?",
+//	        followup_1="Which region?", followup_1_when="yes",
+//	        followup_2="Any notes for the release?"]
+//
+// followup_N_when restricts a question to a particular first answer
+// (case-insensitive). All applicable follow-ups are posted together and must
+// be answered in order; each answer is stored in context as
+// "human_response.followup_N".
+type followUpHumanHandler struct {
+	inner       pipeline.Handler
+	interviewer *ChannelInterviewer
+}
+
+func (h *followUpHumanHandler) Name() string { return h.inner.Name() }
+
+func (h *followUpHumanHandler) Execute(ctx context.Context, node *pipeline.Node, pctx *pipeline.PipelineContext) (pipeline.Outcome, error) {
+	out, err := h.inner.Execute(ctx, node, pctx)
+	if err != nil {
+		return out, err
+	}
+
+	first := out.PreferredLabel
+	if first == "" {
+		first = out.ContextUpdates[pipeline.ContextKeyHumanResponse]
+	}
+
+	var keys, prompts []string
+	for i := 1; ; i++ {
+		key := fmt.Sprintf("followup_%d", i)
+		prompt, ok := node.Attrs[key]
+		if !ok {
+			break
+		}
+		if when := node.Attrs[key+"_when"]; when != "" && !strings.EqualFold(strings.TrimSpace(when), strings.TrimSpace(first)) {
+			continue
+		}
+		keys = append(keys, key)
+		prompts = append(prompts, prompt)
+	}
+	if len(prompts) == 0 {
+		return out, nil
+	}
+
+	answers, err := h.interviewer.AskSequence(node.ID, prompts)
+	if err != nil {
+		return pipeline.Outcome{}, fmt.Errorf("human gate follow-up failed for node %q: %w", node.ID, err)
+	}
+	updates := make(map[string]string, len(out.ContextUpdates)+len(answers))
+	for k, v := range out.ContextUpdates {
+		updates[k] = v
+	}
+	for i, answer := range answers {
+		updates[pipeline.ContextKeyHumanResponse+"."+keys[i]] = answer
+	}
+	out.ContextUpdates = updates
+	return out, nil
+}
+
+// wrapHumanFollowUps installs follow-up support around the registry's
+// wait.human handler, if one is registered.
+func wrapHumanFollowUps(registry *pipeline.HandlerRegistry, interviewer *ChannelInterviewer) {
+	if inner := registry.Get("wait.human"); inner != nil {
+		registry.Register(&followUpHumanHandler{inner: inner, interviewer: interviewer})
+	}
+}
@@ -0,0 +1,39 @@
+// ABOUTME: Server-wide cap on concurrent in-flight LLM requests shared by every build.
+// ABOUTME: Requests beyond the cap queue until a slot frees or their context is cancelled.
+package web
+
+import (
+	"context"
+
+	"github.com/2389-research/tracker/agent"
+	"github.com/2389-research/tracker/llm"
+)
+
+// limitedCompleter bounds how many Complete calls run at once across all
+// callers sharing it. Calls over the limit wait for a slot rather than fail,
+// so bursts from parallel agents and concurrent builds stay under provider
+// rate limits.
+type limitedCompleter struct {
+	inner agent.Completer
+	slots chan struct{}
+}
+
+// newLimitedCompleter wraps inner so at most limit requests are in flight.
+// A limit of zero or less returns inner unchanged.
+func newLimitedCompleter(inner agent.Completer, limit int) agent.Completer {
+	if limit <= 0 || inner == nil {
+		return inner
+	}
+	return &limitedCompleter{inner: inner, slots: make(chan struct{}, limit)}
+}
+
+// Complete waits for a free slot, then delegates to the wrapped client.
+func (c *limitedCompleter) Complete(ctx context.Context, req *llm.Request) (*llm.Response, error) {
+	select {
+	case c.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-c.slots }()
+	return c.inner.Complete(ctx, req)
+}
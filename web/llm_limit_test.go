@@ -0,0 +1,90 @@
+// ABOUTME: Tests for the server-wide LLM concurrency limiter.
+// ABOUTME: Verifies calls are serialized at limit 1, queued calls honour cancellation, and zero disables the cap.
+package web
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/2389-research/tracker/llm"
+)
+
+// concurrencyProbe records the peak number of overlapping Complete calls.
+type concurrencyProbe struct {
+	inFlight atomic.Int32
+	peak     atomic.Int32
+	delay    time.Duration
+}
+
+func (p *concurrencyProbe) Complete(ctx context.Context, req *llm.Request) (*llm.Response, error) {
+	n := p.inFlight.Add(1)
+	defer p.inFlight.Add(-1)
+	for {
+		peak := p.peak.Load()
+		if n <= peak || p.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(p.delay)
+	return &llm.Response{Message: llm.AssistantMessage("ok")}, nil
+}
+
+func runConcurrently(c interface {
+	Complete(context.Context, *llm.Request) (*llm.Response, error)
+}, n int) {
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = c.Complete(context.Background(), &llm.Request{})
+		}()
+	}
+	wg.Wait()
+}
+
+func TestLimitedCompleterSerializes(t *testing.T) {
+	probe := &concurrencyProbe{delay: 30 * time.Millisecond}
+	limited := newLimitedCompleter(probe, 1)
+
+	start := time.Now()
+	runConcurrently(limited, 2)
+
+	if peak := probe.peak.Load(); peak != 1 {
+		t.Errorf("peak concurrent calls = %d, want 1", peak)
+	}
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("two calls finished in %s; expected them to run back to back", elapsed)
+	}
+}
+
+func TestLimitedCompleterQueuedCallHonoursCancel(t *testing.T) {
+	probe := &concurrencyProbe{delay: 200 * time.Millisecond}
+	limited := newLimitedCompleter(probe, 1)
+
+	go func() { _, _ = limited.Complete(context.Background(), &llm.Request{}) }()
+	for probe.inFlight.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := limited.Complete(ctx, &llm.Request{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("queued call err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestLimitedCompleterUnlimited(t *testing.T) {
+	probe := &concurrencyProbe{delay: 30 * time.Millisecond}
+	if c := newLimitedCompleter(probe, 0); c != probe {
+		t.Fatal("limit 0 should return the client unchanged")
+	}
+	runConcurrently(probe, 2)
+	if peak := probe.peak.Load(); peak != 2 {
+		t.Errorf("peak concurrent calls = %d, want 2 without a limit", peak)
+	}
+}
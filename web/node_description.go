@@ -0,0 +1,44 @@
+// ABOUTME: Human-facing node descriptions read from the description attribute of a pipeline's DOT.
+// ABOUTME: Descriptions document nodes in events and the build UI; they are never part of a prompt.
+package web
+
+import (
+	"strings"
+
+	"github.com/2389-research/mammoth/dot"
+)
+
+// nodeDescriptions maps node IDs to their description attribute. It returns
+// nil when the source does not parse or no node has a description.
+func nodeDescriptions(source string) map[string]string {
+	g, err := dot.Parse(source)
+	if err != nil {
+		return nil
+	}
+	var out map[string]string
+	for id, n := range g.Nodes {
+		desc := strings.TrimSpace(n.Attrs["description"])
+		if desc == "" {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]string)
+		}
+		out[id] = desc
+	}
+	return out
+}
+
+// withNodeDescription adds the node's description to a stage.started event so
+// the build view can show what the running node is for.
+func withNodeDescription(be BuildEvent, descriptions map[string]string) BuildEvent {
+	desc := descriptions[be.NodeID]
+	if desc == "" || be.Type != BuildEventNodeStarted {
+		return be
+	}
+	if be.Data == nil {
+		be.Data = map[string]any{}
+	}
+	be.Data["description"] = desc
+	return be
+}
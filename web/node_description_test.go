@@ -0,0 +1,66 @@
+// ABOUTME: Tests for node description attributes in build events and the build state JSON.
+// ABOUTME: Verifies descriptions round-trip from DOT and ride only on stage.started events.
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const describedTestDOT = `digraph test {
+	start [shape=Mdiamond]
+	work [label="Do work", prompt="Execute task", description="Implements the feature end to end"]
+	done [shape=Msquare]
+	start -> work -> done
+}`
+
+func TestBuildStateIncludesNodeDescriptions(t *testing.T) {
+	srv := newTestServer(t)
+	p, err := srv.store.Create("described")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p.Phase = PhaseEdit
+	p.DOT = describedTestDOT
+	if err := srv.store.Update(p); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+p.ID+"/build/state", nil)
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	var body struct {
+		NodeDescriptions map[string]string `json:"node_descriptions"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(body.NodeDescriptions) != 1 || body.NodeDescriptions["work"] != "Implements the feature end to end" {
+		t.Errorf("node_descriptions = %v, want only work's description", body.NodeDescriptions)
+	}
+}
+
+func TestWithNodeDescription(t *testing.T) {
+	descriptions := nodeDescriptions(describedTestDOT)
+
+	started := withNodeDescription(BuildEvent{Type: BuildEventNodeStarted, NodeID: "work"}, descriptions)
+	if sse := buildEventToSSE(started); !strings.Contains(sse.Data, `"description":"Implements the feature end to end"`) {
+		t.Errorf("stage.started data = %s, want the description", sse.Data)
+	}
+
+	completed := withNodeDescription(BuildEvent{Type: BuildEventNodeCompleted, NodeID: "work"}, descriptions)
+	if completed.Data != nil {
+		t.Errorf("only stage.started should carry the description, got %v", completed.Data)
+	}
+	other := withNodeDescription(BuildEvent{Type: BuildEventNodeStarted, NodeID: "done"}, descriptions)
+	if other.Data != nil {
+		t.Errorf("nodes without a description should be unchanged, got %v", other.Data)
+	}
+}
@@ -0,0 +1,234 @@
+// ABOUTME: Optional per-node webhooks that POST node_started and node_completed callbacks to a configured URL.
+// ABOUTME: Deliveries run on a background worker with bounded retries so a slow receiver never blocks a build.
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// Node webhook event names.
+const (
+	NodeWebhookStarted   = "node_started"
+	NodeWebhookCompleted = "node_completed"
+)
+
+const (
+	// nodeWebhookQueueSize bounds the deliveries waiting to be sent. Past it
+	// new callbacks are dropped rather than blocking the pipeline.
+	nodeWebhookQueueSize = 256
+	// nodeWebhookAttempts is how many times a delivery is tried before it is
+	// given up on.
+	nodeWebhookAttempts = 3
+	// nodeWebhookTimeout bounds each delivery attempt.
+	nodeWebhookTimeout = 10 * time.Second
+)
+
+// NodeWebhookPayload is the JSON body POSTed to ServerConfig.NodeWebhook.
+// Status is "running" for node_started and "success", "fail", or "retry"
+// for node_completed.
+type NodeWebhookPayload struct {
+	Event       string     `json:"event"`
+	ProjectID   string     `json:"project_id"`
+	RunID       string     `json:"run_id"`
+	NodeID      string     `json:"node_id"`
+	Status      string     `json:"status"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	DurationMS  int64      `json:"duration_ms,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// nodeWebhook delivers node callbacks to one URL from a single background
+// worker, so callbacks for a run arrive in the order they happened.
+type nodeWebhook struct {
+	url     string
+	client  *http.Client
+	backoff time.Duration
+
+	mu     sync.Mutex
+	closed bool
+	queue  chan NodeWebhookPayload
+	done   chan struct{}
+}
+
+// newNodeWebhook starts a delivery worker for url. An empty url disables
+// node webhooks and returns nil, which every method accepts.
+func newNodeWebhook(url string) *nodeWebhook {
+	if url == "" {
+		return nil
+	}
+	w := &nodeWebhook{
+		url:     url,
+		client:  &http.Client{Timeout: nodeWebhookTimeout},
+		backoff: time.Second,
+		queue:   make(chan NodeWebhookPayload, nodeWebhookQueueSize),
+		done:    make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// send queues a delivery without blocking. A full queue drops it.
+func (w *nodeWebhook) send(p NodeWebhookPayload) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	select {
+	case w.queue <- p:
+	default:
+		log.Printf("component=web.node_webhook action=drop_delivery run_id=%s node_id=%s event=%s reason=queue_full", p.RunID, p.NodeID, p.Event)
+	}
+}
+
+// Close stops accepting deliveries and waits until the queued ones have been
+// sent or given up on, or ctx is done.
+func (w *nodeWebhook) Close(ctx context.Context) error {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *nodeWebhook) run() {
+	defer close(w.done)
+	for p := range w.queue {
+		if err := w.deliver(p); err != nil {
+			log.Printf("component=web.node_webhook action=delivery_failed run_id=%s node_id=%s event=%s err=%v", p.RunID, p.NodeID, p.Event, err)
+		}
+	}
+}
+
+// deliver POSTs p, retrying network errors and 5xx responses up to
+// nodeWebhookAttempts times with a doubling backoff.
+func (w *nodeWebhook) deliver(p NodeWebhookPayload) error {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	backoff := w.backoff
+	for attempt := 1; ; attempt++ {
+		err = w.post(body)
+		if err == nil || attempt >= nodeWebhookAttempts {
+			return err
+		}
+		var permanent permanentWebhookError
+		if errors.As(err, &permanent) {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// permanentWebhookError is a 4xx response, which a retry will not fix.
+type permanentWebhookError struct{ status int }
+
+func (e permanentWebhookError) Error() string {
+	return fmt.Sprintf("receiver responded %d", e.status)
+}
+
+func (w *nodeWebhook) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 500:
+		return fmt.Errorf("receiver responded %d", resp.StatusCode)
+	case resp.StatusCode >= 400:
+		return permanentWebhookError{status: resp.StatusCode}
+	}
+	return nil
+}
+
+// nodeWebhookRun turns one build's stage events into node callbacks, pairing
+// each completion with its node's start time.
+type nodeWebhookRun struct {
+	hook      *nodeWebhook
+	projectID string
+	runID     string
+
+	mu      sync.Mutex
+	started map[string]time.Time
+}
+
+// forRun returns the callback source for one build, or nil when node
+// webhooks are disabled.
+func (w *nodeWebhook) forRun(projectID, runID string) *nodeWebhookRun {
+	if w == nil {
+		return nil
+	}
+	return &nodeWebhookRun{hook: w, projectID: projectID, runID: runID, started: make(map[string]time.Time)}
+}
+
+// nodeWebhookStatus is the node_completed status for each closing stage event.
+var nodeWebhookStatus = map[pipeline.PipelineEventType]string{
+	pipeline.EventStageCompleted: pipeline.OutcomeSuccess,
+	pipeline.EventStageFailed:    pipeline.OutcomeFail,
+	pipeline.EventStageRetrying:  pipeline.OutcomeRetry,
+}
+
+// HandlePipelineEvent sends a callback for stage start, completion, failure,
+// and retry events; other events are ignored.
+func (r *nodeWebhookRun) HandlePipelineEvent(evt pipeline.PipelineEvent) {
+	if r == nil || evt.NodeID == "" {
+		return
+	}
+	p := NodeWebhookPayload{ProjectID: r.projectID, RunID: r.runID, NodeID: evt.NodeID}
+	at := evt.Timestamp
+
+	r.mu.Lock()
+	switch evt.Type {
+	case pipeline.EventStageStarted:
+		r.started[evt.NodeID] = at
+		p.Event, p.Status, p.StartedAt = NodeWebhookStarted, "running", &at
+	case pipeline.EventStageCompleted, pipeline.EventStageFailed, pipeline.EventStageRetrying:
+		p.Event, p.Status, p.CompletedAt = NodeWebhookCompleted, nodeWebhookStatus[evt.Type], &at
+		if start, ok := r.started[evt.NodeID]; ok {
+			p.StartedAt, p.DurationMS = &start, at.Sub(start).Milliseconds()
+			delete(r.started, evt.NodeID)
+		}
+		if evt.Err != nil {
+			p.Error = evt.Err.Error()
+		} else if evt.Type != pipeline.EventStageCompleted {
+			p.Error = evt.Message
+		}
+	}
+	r.mu.Unlock()
+
+	if p.Event != "" {
+		r.hook.send(p)
+	}
+}
@@ -0,0 +1,162 @@
+// ABOUTME: Tests for per-node webhooks: payloads built from stage events, retries, and end-to-end delivery.
+// ABOUTME: An httptest receiver checks node_started and node_completed callbacks arrive for each node of a run.
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/2389-research/tracker/pipeline"
+)
+
+// webhookReceiver records the payloads POSTed to it. The first failFirst
+// requests get a 503.
+type webhookReceiver struct {
+	mu        sync.Mutex
+	failFirst int
+	requests  int
+	payloads  []NodeWebhookPayload
+}
+
+func (rcv *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	rcv.requests++
+	if rcv.requests <= rcv.failFirst {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	var p NodeWebhookPayload
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	rcv.payloads = append(rcv.payloads, p)
+}
+
+func (rcv *webhookReceiver) received() []NodeWebhookPayload {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	return append([]NodeWebhookPayload(nil), rcv.payloads...)
+}
+
+// closeWebhook flushes the queued deliveries.
+func closeWebhook(t *testing.T, hook *nodeWebhook) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := hook.Close(ctx); err != nil {
+		t.Fatalf("flush node webhook: %v", err)
+	}
+}
+
+func TestNodeWebhookPayloads(t *testing.T) {
+	rcv := &webhookReceiver{}
+	receiver := httptest.NewServer(rcv)
+	defer receiver.Close()
+
+	hook := newNodeWebhook(receiver.URL)
+	run := hook.forRun("proj-1", "run-1")
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	run.HandlePipelineEvent(pipeline.PipelineEvent{Type: pipeline.EventStageStarted, NodeID: "build", Timestamp: start})
+	run.HandlePipelineEvent(pipeline.PipelineEvent{Type: pipeline.EventCheckpointSaved, NodeID: "build", Timestamp: start})
+	run.HandlePipelineEvent(pipeline.PipelineEvent{Type: pipeline.EventStageFailed, NodeID: "build", Timestamp: start.Add(1500 * time.Millisecond), Err: errors.New("exit status 1")})
+	closeWebhook(t, hook)
+
+	got := rcv.received()
+	if len(got) != 2 {
+		t.Fatalf("received %d callbacks, want 2: %+v", len(got), got)
+	}
+	if got[0].Event != NodeWebhookStarted || got[0].Status != "running" || !got[0].StartedAt.Equal(start) {
+		t.Errorf("start callback = %+v", got[0])
+	}
+	done := got[1]
+	if done.Event != NodeWebhookCompleted || done.Status != "fail" || done.Error != "exit status 1" {
+		t.Errorf("completion callback = %+v", done)
+	}
+	if done.ProjectID != "proj-1" || done.RunID != "run-1" || done.NodeID != "build" || done.DurationMS != 1500 {
+		t.Errorf("completion identity/timing = %+v", done)
+	}
+}
+
+func TestNodeWebhookRetriesServerErrors(t *testing.T) {
+	rcv := &webhookReceiver{failFirst: 2}
+	receiver := httptest.NewServer(rcv)
+	defer receiver.Close()
+
+	hook := newNodeWebhook(receiver.URL)
+	hook.backoff = time.Millisecond
+	hook.send(NodeWebhookPayload{Event: NodeWebhookStarted, RunID: "r", NodeID: "n"})
+	closeWebhook(t, hook)
+
+	if got := rcv.received(); len(got) != 1 || rcv.requests != 3 {
+		t.Errorf("requests = %d, delivered = %d; want 3 attempts and one delivery", rcv.requests, len(got))
+	}
+}
+
+func TestNodeWebhookDisabledIsNil(t *testing.T) {
+	hook := newNodeWebhook("")
+	if hook != nil {
+		t.Fatal("empty URL should disable node webhooks")
+	}
+	// Every method tolerates the disabled webhook.
+	hook.forRun("p", "r").HandlePipelineEvent(pipeline.PipelineEvent{Type: pipeline.EventStageStarted, NodeID: "n"})
+	if err := hook.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNodeWebhookEachNodeOfRun(t *testing.T) {
+	rcv := &webhookReceiver{}
+	receiver := httptest.NewServer(rcv)
+	defer receiver.Close()
+
+	srv := newTestServer(t)
+	srv.nodeWebhook = newNodeWebhook(receiver.URL)
+
+	p := assertRunCreated(t, srv, postPipeline(srv, "text/plain", bytes.NewBufferString(submitTestDOT)))
+	waitForBuildStatus(t, srv, p.ID)
+	closeWebhook(t, srv.nodeWebhook)
+
+	started := map[string]bool{}
+	completed := map[string]NodeWebhookPayload{}
+	for _, cb := range rcv.received() {
+		if cb.RunID != p.RunID || cb.ProjectID != p.ID {
+			t.Errorf("callback for run %s/%s, want %s/%s", cb.ProjectID, cb.RunID, p.ID, p.RunID)
+		}
+		switch cb.Event {
+		case NodeWebhookStarted:
+			started[cb.NodeID] = true
+		case NodeWebhookCompleted:
+			if !started[cb.NodeID] {
+				t.Errorf("node %s completed before it started", cb.NodeID)
+			}
+			completed[cb.NodeID] = cb
+		}
+	}
+	for _, node := range []string{"start", "check"} {
+		if !started[node] {
+			t.Errorf("no node_started callback for %s", node)
+		}
+		cb, ok := completed[node]
+		if !ok {
+			t.Errorf("no node_completed callback for %s", node)
+			continue
+		}
+		if cb.Status != "success" || cb.StartedAt == nil || cb.CompletedAt == nil {
+			t.Errorf("completion for %s = %+v", node, cb)
+		}
+	}
+	for node := range started {
+		if _, ok := completed[node]; !ok {
+			t.Errorf("node %s started but never completed", node)
+		}
+	}
+}
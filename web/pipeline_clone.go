@@ -0,0 +1,55 @@
+// ABOUTME: HTTP handler for POST /pipelines/{projectID}/clone, which starts a fresh run from a project's DOT source.
+// ABOUTME: An optional body replaces the source or name; the original project and its runs are left untouched.
+package web
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// handlePipelineClone creates a new project from an existing project's
+// pipeline and starts building it from a clean state. The body is optional
+// and accepts the same forms as POST /pipelines: a source replaces the
+// stored DOT and a name replaces the original's. Responds like POST
+// /pipelines with the new project and run IDs.
+func (s *Server) handlePipelineClone(w http.ResponseWriter, r *http.Request) {
+	orig, ok := s.store.Get(chi.URLParam(r, "projectID"))
+	if !ok {
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project not found")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxPipelineSubmission)
+	sub, err := readPipelineSubmission(r)
+	switch {
+	case errors.Is(err, io.EOF):
+		// An empty JSON body clones the project as is.
+	case errors.Is(err, errUnsupportedMediaType):
+		writeError(w, r, http.StatusUnsupportedMediaType, ErrCodeUnsupportedMedia, "unsupported content type")
+		return
+	case isMaxBytesError(err):
+		writeError(w, r, http.StatusRequestEntityTooLarge, ErrCodeTooLarge, "request body too large")
+		return
+	case err != nil:
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "bad request")
+		return
+	}
+
+	source := sub.Source
+	if source == "" {
+		source = strings.TrimSpace(orig.DOT)
+	}
+	if source == "" {
+		writeError(w, r, http.StatusConflict, ErrCodeConflict, "project has no pipeline source to clone")
+		return
+	}
+	name := sub.Name
+	if name == "" {
+		name = orig.Name
+	}
+	s.createAndBuildPipeline(w, r, name, source, sub.InitialContext)
+}
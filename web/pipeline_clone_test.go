@@ -0,0 +1,111 @@
+// ABOUTME: Tests for POST /pipelines/{projectID}/clone starting a fresh run from a project's source.
+// ABOUTME: Covers identical and modified clones, leaving the original untouched, and unknown projects.
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func postClone(srv *Server, projectID, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/pipelines/"+projectID+"/clone", strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	return rec
+}
+
+// submitOriginal submits submitTestDOT and waits for its build to finish.
+func submitOriginal(t *testing.T, srv *Server) *Project {
+	t.Helper()
+	body := `{"source": ` + mustJSON(t, submitTestDOT) + `, "name": "original"}`
+	return assertRunCreated(t, srv, postPipeline(srv, "application/json", bytes.NewBufferString(body)))
+}
+
+func mustJSON(t *testing.T, v any) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestPipelineCloneIdenticalSource(t *testing.T) {
+	srv := newTestServer(t)
+	orig := submitOriginal(t, srv)
+
+	for _, tc := range []struct{ contentType, body string }{
+		{"", ""},
+		{"application/json", ""},
+	} {
+		clone := assertRunCreated(t, srv, postClone(srv, orig.ID, tc.contentType, tc.body))
+		if clone.ID == orig.ID || clone.RunID == orig.RunID {
+			t.Errorf("clone reused project %s / run %s", clone.ID, clone.RunID)
+		}
+		if clone.Name != "original" {
+			t.Errorf("clone name = %q, want the original's", clone.Name)
+		}
+	}
+
+	after, _ := srv.store.Get(orig.ID)
+	if after.RunID != orig.RunID || after.DOT != submitTestDOT {
+		t.Errorf("original changed: run %s -> %s", orig.RunID, after.RunID)
+	}
+}
+
+func TestPipelineCloneModifiedSource(t *testing.T) {
+	srv := newTestServer(t)
+	orig := submitOriginal(t, srv)
+
+	edited := strings.Replace(submitTestDOT, `command="true"`, `command="echo edited"`, 1)
+	body := `{"source": ` + mustJSON(t, edited) + `, "name": "edited"}`
+	rec := postClone(srv, orig.ID, "application/json", body)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201; body = %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		ProjectID string `json:"project_id"`
+		RunID     string `json:"run_id"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if state := waitForBuildStatus(t, srv, resp.ProjectID); state.ID != resp.RunID {
+		t.Errorf("clone build = %+v, want run %s", state, resp.RunID)
+	}
+	clone, _ := srv.store.Get(resp.ProjectID)
+	if clone.DOT != edited || clone.Name != "edited" {
+		t.Errorf("clone = {Name:%q DOT:%q}, want the edited source and name", clone.Name, clone.DOT)
+	}
+	if after, _ := srv.store.Get(orig.ID); after.DOT != submitTestDOT {
+		t.Errorf("original DOT changed to %q", after.DOT)
+	}
+}
+
+func TestPipelineCloneRejections(t *testing.T) {
+	srv := newTestServer(t)
+	if rec := postClone(srv, "missing", "", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown project: status = %d, want 404", rec.Code)
+	}
+
+	empty, err := srv.store.Create("empty")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec := postClone(srv, empty.ID, "", ""); rec.Code != http.StatusConflict {
+		t.Errorf("project without DOT: status = %d, want 409", rec.Code)
+	}
+
+	orig := submitOriginal(t, srv)
+	if rec := postClone(srv, orig.ID, "text/plain", "digraph broken {"); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("invalid edited source: status = %d, want 422", rec.Code)
+	}
+}
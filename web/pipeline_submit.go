@@ -0,0 +1,198 @@
+// ABOUTME: HTTP handler for submitting a DOT pipeline and starting its build in one request.
+// ABOUTME: Picks the body parser from the Content-Type: raw text, JSON, form-encoded, or multipart upload.
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/2389-research/mammoth/runstate"
+	"github.com/2389-research/mammoth/tracing"
+)
+
+// maxPipelineSubmission caps the size of a submitted pipeline request body.
+const maxPipelineSubmission = 1 << 20
+
+// errUnsupportedMediaType is returned by readPipelineSubmission for a
+// Content-Type it cannot parse.
+var errUnsupportedMediaType = errors.New("unsupported content type")
+
+// pipelineSubmission is a pipeline source submitted to POST /pipelines.
+type pipelineSubmission struct {
+	Name   string            `json:"name"`
+	Source string            `json:"source"`
+	Params map[string]string `json:"params"`
+	// InitialContext seeds the run's pipeline context; see
+	// runstate.InitialContext for how typed values are stored.
+	InitialContext map[string]any `json:"initial_context"`
+	fileName       string
+}
+
+// readPipelineSubmission parses the request body according to its
+// Content-Type:
+//
+//   - text/plain, text/vnd.graphviz, or none: the body is the DOT source
+//   - application/json: {"source": "...", "name": "...", "params": {...}, "initial_context": {...}}
+//   - application/x-www-form-urlencoded: source=...&name=...
+//   - multipart/form-data: a "source" file upload (or text field) and optional name
+func readPipelineSubmission(r *http.Request) (pipelineSubmission, error) {
+	var sub pipelineSubmission
+	mediaType := "text/plain"
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mt, _, err := mime.ParseMediaType(ct)
+		if err != nil {
+			return sub, errUnsupportedMediaType
+		}
+		mediaType = mt
+	}
+
+	switch mediaType {
+	case "text/plain", "text/vnd.graphviz":
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			return sub, err
+		}
+		sub.Source = string(b)
+	case "application/json":
+		dec := json.NewDecoder(r.Body)
+		// Numbers stay json.Number so large integers reach the context intact.
+		dec.UseNumber()
+		if err := dec.Decode(&sub); err != nil {
+			return sub, err
+		}
+	case "application/x-www-form-urlencoded":
+		if err := r.ParseForm(); err != nil {
+			return sub, err
+		}
+		sub.Name, sub.Source = r.PostFormValue("name"), r.PostFormValue("source")
+	case "multipart/form-data":
+		if err := r.ParseMultipartForm(maxPipelineSubmission); err != nil {
+			return sub, err
+		}
+		sub.Name, sub.Source = r.PostFormValue("name"), r.PostFormValue("source")
+		if f, h, err := r.FormFile("source"); err == nil {
+			defer f.Close()
+			b, err := io.ReadAll(f)
+			if err != nil {
+				return sub, err
+			}
+			sub.Source, sub.fileName = string(b), h.Filename
+		}
+	default:
+		return sub, errUnsupportedMediaType
+	}
+
+	sub.Name = strings.TrimSpace(sub.Name)
+	sub.Source = strings.TrimSpace(sub.Source)
+	return sub, nil
+}
+
+// handlePipelineSubmit creates a project from a submitted DOT pipeline and
+// starts building it. A pipeline that fails validation leaves the project in
+// the edit phase and responds 422 with the diagnostics. On success responds
+// 201 with the project and run IDs as JSON, or redirects browsers to the
+// build view. JSON errors carry an ErrCode* code. With ?template=<name> the
+// pipeline comes from the template library instead of the body.
+func (s *Server) handlePipelineSubmit(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxPipelineSubmission)
+	tmpl := r.URL.Query().Get("template")
+	sub, err := readPipelineSubmission(r)
+	switch {
+	case errors.Is(err, io.EOF) && tmpl != "":
+		// An empty JSON body instantiates the template from the query string.
+	case errors.Is(err, errUnsupportedMediaType):
+		writeError(w, r, http.StatusUnsupportedMediaType, ErrCodeUnsupportedMedia, "unsupported content type")
+		return
+	case isMaxBytesError(err):
+		writeError(w, r, http.StatusRequestEntityTooLarge, ErrCodeTooLarge, "request body too large")
+		return
+	case err != nil:
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "bad request")
+		return
+	}
+	if tmpl != "" {
+		s.createFromTemplate(w, r, tmpl, sub)
+		return
+	}
+	if sub.Source == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "pipeline source is required")
+		return
+	}
+
+	name := sub.Name
+	if name == "" {
+		name = projectNameFromInputs("", sub.fileName, sub.Source)
+	}
+	s.createAndBuildPipeline(w, r, name, sub.Source, sub.InitialContext)
+}
+
+// createAndBuildPipeline creates a project named name from source and starts
+// its build with initialContext seeded into the pipeline context, writing the
+// response for POST /pipelines and its variants.
+func (s *Server) createAndBuildPipeline(w http.ResponseWriter, r *http.Request, name, source string, initialContext map[string]any) {
+	if s.refuseWhileStopping(w, r) {
+		return
+	}
+	seed, err := runstate.InitialContext(initialContext)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+		return
+	}
+	p, err := s.store.Create(name)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+	p.DOT = source
+	p.InitialContext = seed
+
+	if err := TransitionEditorToBuild(p); err != nil {
+		if updateErr := s.store.Update(p); updateErr != nil {
+			log.Printf("component=web.build action=update_project_failed project_id=%s phase=edit err=%v", p.ID, updateErr)
+		}
+		if !wantsJSON(r) {
+			http.Redirect(w, r, "/projects/"+p.ID, http.StatusSeeOther)
+			return
+		}
+		writeSpecJSON(w, http.StatusUnprocessableEntity, map[string]any{
+			"error":       err.Error(),
+			"code":        dotErrorCode(err),
+			"project_id":  p.ID,
+			"diagnostics": p.Diagnostics,
+		})
+		return
+	}
+
+	runID, err := runstate.GenerateRunID()
+	if err != nil {
+		log.Printf("component=web.build action=generate_run_id_failed project_id=%s err=%v", p.ID, err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "internal server error")
+		return
+	}
+	p.RunID = runID
+	p.Diagnostics = nil
+	if err := s.store.Update(p); err != nil {
+		log.Printf("component=web.build action=update_project_failed project_id=%s phase=build err=%v", p.ID, err)
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "internal server error")
+		return
+	}
+
+	if err := s.startBuildExecution(tracing.RequestContext(r), p.ID, p, runID, false); err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, ErrCodeUnavailable, err.Error())
+		return
+	}
+
+	if !wantsJSON(r) {
+		http.Redirect(w, r, "/projects/"+p.ID+"/build", http.StatusSeeOther)
+		return
+	}
+	writeSpecJSON(w, http.StatusCreated, map[string]any{
+		"project_id": p.ID,
+		"run_id":     runID,
+	})
+}
@@ -0,0 +1,165 @@
+// ABOUTME: Tests for POST /pipelines content negotiation and build start.
+// ABOUTME: Submits the same pipeline as text, JSON, form-encoded, and multipart and asserts each creates a run, seeding initial_context.
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/2389-research/mammoth/runstate"
+)
+
+const submitTestDOT = `digraph submit {
+	start [shape=Mdiamond]
+	check [shape=parallelogram, tool_command="true"]
+	done [shape=Msquare]
+	start -> check -> done
+}`
+
+func postPipeline(srv *Server, contentType string, body *bytes.Buffer) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/pipelines", body)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	return rec
+}
+
+// assertRunCreated checks for a 201 response naming a project whose build
+// was started under the returned run ID.
+func assertRunCreated(t *testing.T, srv *Server, rec *httptest.ResponseRecorder) *Project {
+	t.Helper()
+	return assertRunCreatedFrom(t, srv, rec, submitTestDOT)
+}
+
+// assertRunCreatedFrom is assertRunCreated for a run whose stored source is
+// wantDOT rather than submitTestDOT.
+func assertRunCreatedFrom(t *testing.T, srv *Server, rec *httptest.ResponseRecorder, wantDOT string) *Project {
+	t.Helper()
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201; body = %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		ProjectID string `json:"project_id"`
+		RunID     string `json:"run_id"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.ProjectID == "" || resp.RunID == "" {
+		t.Fatalf("response missing ids: %s", rec.Body.String())
+	}
+	if state := waitForBuildStatus(t, srv, resp.ProjectID); state.ID != resp.RunID {
+		t.Errorf("build state = %+v, want run %s", state, resp.RunID)
+	}
+	p, ok := srv.store.Get(resp.ProjectID)
+	if !ok {
+		t.Fatalf("project %s not found", resp.ProjectID)
+	}
+	if p.RunID != resp.RunID || p.DOT != wantDOT {
+		t.Errorf("project = {RunID:%q DOT:%q}, want submitted source and run", p.RunID, p.DOT)
+	}
+	return p
+}
+
+func TestPipelineSubmitFormEncoded(t *testing.T) {
+	srv := newTestServer(t)
+	form := url.Values{"source": {submitTestDOT}, "name": {"form-pipeline"}}
+	rec := postPipeline(srv, "application/x-www-form-urlencoded", bytes.NewBufferString(form.Encode()))
+	if p := assertRunCreated(t, srv, rec); p.Name != "form-pipeline" {
+		t.Errorf("name = %q, want form-pipeline", p.Name)
+	}
+}
+
+func TestPipelineSubmitMultipartFile(t *testing.T) {
+	srv := newTestServer(t)
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("source", "deploy.dot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.Write([]byte(submitTestDOT))
+	mw.Close()
+
+	rec := postPipeline(srv, mw.FormDataContentType(), &body)
+	if p := assertRunCreated(t, srv, rec); p.Name != "deploy" {
+		t.Errorf("name = %q, want name taken from the uploaded file", p.Name)
+	}
+}
+
+func TestPipelineSubmitTextAndJSON(t *testing.T) {
+	srv := newTestServer(t)
+	assertRunCreated(t, srv, postPipeline(srv, "text/plain; charset=utf-8", bytes.NewBufferString(submitTestDOT)))
+
+	payload, _ := json.Marshal(map[string]string{"source": submitTestDOT})
+	assertRunCreated(t, srv, postPipeline(srv, "application/json", bytes.NewBuffer(payload)))
+}
+
+func TestPipelineSubmitInitialContext(t *testing.T) {
+	srv := newTestServer(t)
+	payload := `{"source": ` + strconv.Quote(submitTestDOT) + `, "initial_context": {"ticket": "ENG-12", "dry_run": true, "retries": 3}}`
+	p := assertRunCreated(t, srv, postPipeline(srv, "application/json", bytes.NewBufferString(payload)))
+
+	want := map[string]string{"ticket": "ENG-12", "dry_run": "true", "retries": "3"}
+	if !reflect.DeepEqual(p.InitialContext, want) {
+		t.Errorf("project initial context = %v, want %v", p.InitialContext, want)
+	}
+	cp, err := runstate.LoadCheckpoint(filepath.Join(srv.workspace.CheckpointDir(p.ID, p.RunID), "checkpoint.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range want {
+		if got := cp.Context[k]; got != v {
+			t.Errorf("final context %s = %q, want %q", k, got, v)
+		}
+	}
+
+	bad := `{"source": ` + strconv.Quote(submitTestDOT) + `, "initial_context": {"graph.goal": "x"}}`
+	if rec := postPipeline(srv, "application/json", bytes.NewBufferString(bad)); rec.Code != http.StatusBadRequest {
+		t.Errorf("graph.* initial context: status = %d, want 400", rec.Code)
+	}
+}
+
+func TestPipelineSubmitRejections(t *testing.T) {
+	srv := newTestServer(t)
+	if rec := postPipeline(srv, "application/xml", bytes.NewBufferString("<x/>")); rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("xml: status = %d, want 415", rec.Code)
+	}
+	if rec := postPipeline(srv, "application/x-www-form-urlencoded", bytes.NewBufferString("name=empty")); rec.Code != http.StatusBadRequest {
+		t.Errorf("missing source: status = %d, want 400", rec.Code)
+	}
+	rec := postPipeline(srv, "text/plain", bytes.NewBufferString("digraph broken {"))
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("invalid DOT: status = %d, want 422; body = %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "build_blocked") {
+		t.Errorf("expected diagnostics in body: %s", rec.Body.String())
+	}
+}
+
+func TestPipelineSubmitBrowserRedirect(t *testing.T) {
+	srv := newTestServer(t)
+	form := url.Values{"source": {submitTestDOT}}
+	req := httptest.NewRequest(http.MethodPost, "/pipelines", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "text/html")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusSeeOther || !strings.HasSuffix(rec.Header().Get("Location"), "/build") {
+		t.Fatalf("status = %d, location = %q; want redirect to build view", rec.Code, rec.Header().Get("Location"))
+	}
+	projectID := strings.TrimSuffix(strings.TrimPrefix(rec.Header().Get("Location"), "/projects/"), "/build")
+	waitForBuildStatus(t, srv, projectID)
+}
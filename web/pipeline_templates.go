@@ -0,0 +1,234 @@
+// ABOUTME: Server-side library of reusable pipeline templates stored as .dot files under the data dir.
+// ABOUTME: GET /templates lists them; POST /pipelines?template=<name> fills in {{param}} placeholders and starts a run.
+package web
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// templateExt is the file extension of a template in the library.
+const templateExt = ".dot"
+
+// errTemplateNotFound is returned by loadTemplate for a name with no file.
+var errTemplateNotFound = errors.New("template not found")
+
+var (
+	// templateName restricts template names so they always resolve to a file
+	// directly inside the templates directory.
+	templateName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+	// templatePlaceholder matches a {{name}} parameter placeholder. Double
+	// braces keep placeholders distinct from DOT's own block braces.
+	templatePlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+	// templateParamDoc matches a "// @param name[=default]: description"
+	// comment documenting a placeholder.
+	templateParamDoc = regexp.MustCompile(`^//\s*@param\s+([A-Za-z_][A-Za-z0-9_]*)(?:=(\S*))?\s*(?::\s*(.*))?$`)
+
+	// templateDescriptionDoc matches the "// @description ..." comment.
+	templateDescriptionDoc = regexp.MustCompile(`^//\s*@description\s+(.*)$`)
+)
+
+// PipelineTemplate is a DOT pipeline in the template library.
+//
+// A template documents itself with comments:
+//
+//	// @description Review a pull request
+//	// @param repo: Repository to review
+//	// @param branch=main: Branch to check out
+//
+// A parameter with a default is optional. Placeholders without an @param
+// comment are still parameters, just required and undocumented.
+type PipelineTemplate struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Params      []TemplateParam `json:"params"`
+	source      string
+}
+
+// TemplateParam is a {{name}} placeholder a template declares.
+type TemplateParam struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Default     string `json:"default,omitempty"`
+	Required    bool   `json:"required"`
+}
+
+// parseTemplate reads the description and parameters of a template source.
+func parseTemplate(name, source string) PipelineTemplate {
+	t := PipelineTemplate{Name: name, Params: []TemplateParam{}, source: source}
+	seen := map[string]bool{}
+	add := func(p TemplateParam) {
+		if seen[p.Name] {
+			return
+		}
+		seen[p.Name] = true
+		t.Params = append(t.Params, p)
+	}
+
+	for _, line := range strings.Split(source, "\n") {
+		line = strings.TrimSpace(line)
+		if m := templateDescriptionDoc.FindStringSubmatch(line); m != nil {
+			t.Description = strings.TrimSpace(m[1])
+		} else if m := templateParamDoc.FindStringSubmatchIndex(line); m != nil {
+			group := func(i int) string {
+				if m[2*i] < 0 {
+					return ""
+				}
+				return line[m[2*i]:m[2*i+1]]
+			}
+			add(TemplateParam{
+				Name:        group(1),
+				Default:     group(2),
+				Description: strings.TrimSpace(group(3)),
+				Required:    m[4] < 0,
+			})
+		}
+	}
+	for _, m := range templatePlaceholder.FindAllStringSubmatch(source, -1) {
+		add(TemplateParam{Name: m[1], Required: true})
+	}
+	return t
+}
+
+// Instantiate returns the template's DOT with every placeholder replaced by
+// its value from values, or its default. Values are escaped for use inside
+// a quoted DOT string, which is where placeholders belong. Missing required
+// parameters and values for parameters the template does not declare are
+// errors.
+func (t PipelineTemplate) Instantiate(values map[string]string) (string, error) {
+	resolved := make(map[string]string, len(t.Params))
+	var missing []string
+	for _, p := range t.Params {
+		v, ok := values[p.Name]
+		switch {
+		case ok:
+			resolved[p.Name] = v
+		case !p.Required:
+			resolved[p.Name] = p.Default
+		default:
+			missing = append(missing, p.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("template %q: missing parameter(s) %s", t.Name, strings.Join(missing, ", "))
+	}
+
+	var unknown []string
+	for k := range values {
+		if _, ok := resolved[k]; !ok {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return "", fmt.Errorf("template %q: unknown parameter(s) %s", t.Name, strings.Join(unknown, ", "))
+	}
+
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return templatePlaceholder.ReplaceAllStringFunc(t.source, func(m string) string {
+		name := templatePlaceholder.FindStringSubmatch(m)[1]
+		return escaper.Replace(resolved[name])
+	}), nil
+}
+
+// listTemplates returns the templates in dir sorted by name. A missing
+// directory is an empty library.
+func listTemplates(dir string) ([]PipelineTemplate, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []PipelineTemplate{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading templates directory: %w", err)
+	}
+
+	templates := []PipelineTemplate{}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), templateExt)
+		if entry.IsDir() || !ok || !templateName.MatchString(name) {
+			continue
+		}
+		t, err := loadTemplate(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates, nil
+}
+
+// loadTemplate reads the template called name from dir.
+func loadTemplate(dir, name string) (PipelineTemplate, error) {
+	if !templateName.MatchString(name) {
+		return PipelineTemplate{}, errTemplateNotFound
+	}
+	b, err := os.ReadFile(filepath.Join(dir, name+templateExt))
+	if errors.Is(err, fs.ErrNotExist) {
+		return PipelineTemplate{}, errTemplateNotFound
+	}
+	if err != nil {
+		return PipelineTemplate{}, fmt.Errorf("reading template %q: %w", name, err)
+	}
+	return parseTemplate(name, string(b)), nil
+}
+
+// createFromTemplate instantiates the named template and starts building it
+// for POST /pipelines?template=<name>. Parameter values come from the query
+// string and, for JSON bodies, a "params" object, which wins on conflicts.
+// The project is named after the template unless the body names it.
+func (s *Server) createFromTemplate(w http.ResponseWriter, r *http.Request, name string, sub pipelineSubmission) {
+	if sub.Source != "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "a template submission cannot include a pipeline source")
+		return
+	}
+	t, err := loadTemplate(s.workspace.TemplatesDir(), name)
+	if errors.Is(err, errTemplateNotFound) {
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "template not found")
+		return
+	}
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+
+	values := map[string]string{}
+	for k, v := range r.URL.Query() {
+		if k != "template" && len(v) > 0 {
+			values[k] = v[0]
+		}
+	}
+	for k, v := range sub.Params {
+		values[k] = v
+	}
+	source, err := t.Instantiate(values)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+		return
+	}
+
+	projectName := sub.Name
+	if projectName == "" {
+		projectName = t.Name
+	}
+	s.createAndBuildPipeline(w, r, projectName, strings.TrimSpace(source), sub.InitialContext)
+}
+
+// handleTemplateList lists the template library as JSON.
+func (s *Server) handleTemplateList(w http.ResponseWriter, r *http.Request) {
+	templates, err := listTemplates(s.workspace.TemplatesDir())
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+	writeSpecJSON(w, http.StatusOK, map[string]any{"templates": templates})
+}
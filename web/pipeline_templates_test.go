@@ -0,0 +1,174 @@
+// ABOUTME: Tests for the pipeline template library: parsing documented placeholders, listing, and instantiation.
+// ABOUTME: Covers GET /templates and POST /pipelines?template=<name> starting a run with substituted parameters.
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const reviewTemplate = `// @description Review a branch of a repository
+// @param repo: Repository to review
+// @param branch=main: Branch to check out
+digraph review {
+	start [shape=Mdiamond]
+	check [shape=parallelogram, tool_command="echo {{repo}}@{{branch}} {{ note }}"]
+	done [shape=Msquare]
+	start -> check -> done
+}`
+
+// writeTemplate stores a template in the server's library.
+func writeTemplate(t *testing.T, srv *Server, name, source string) {
+	t.Helper()
+	dir := srv.workspace.TemplatesDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+templateExt), []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseTemplateParams(t *testing.T) {
+	tmpl := parseTemplate("review", reviewTemplate)
+	if tmpl.Description != "Review a branch of a repository" {
+		t.Errorf("description = %q", tmpl.Description)
+	}
+	want := []TemplateParam{
+		{Name: "repo", Description: "Repository to review", Required: true},
+		{Name: "branch", Description: "Branch to check out", Default: "main"},
+		{Name: "note", Required: true},
+	}
+	if !reflect.DeepEqual(tmpl.Params, want) {
+		t.Errorf("params = %+v, want %+v", tmpl.Params, want)
+	}
+}
+
+func TestTemplateInstantiate(t *testing.T) {
+	tmpl := parseTemplate("review", reviewTemplate)
+
+	got, err := tmpl.Instantiate(map[string]string{"repo": "mammoth", "note": `say "hi"`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, `command="echo mammoth@main say \"hi\""`) {
+		t.Errorf("instantiated = %s", got)
+	}
+
+	if _, err := tmpl.Instantiate(map[string]string{"note": "x"}); err == nil || !strings.Contains(err.Error(), "missing parameter(s) repo") {
+		t.Errorf("missing repo: err = %v", err)
+	}
+	if _, err := tmpl.Instantiate(map[string]string{"repo": "r", "note": "n", "extra": "x"}); err == nil || !strings.Contains(err.Error(), "unknown parameter(s) extra") {
+		t.Errorf("unknown param: err = %v", err)
+	}
+}
+
+func TestTemplateListEndpoint(t *testing.T) {
+	srv := newTestServer(t)
+
+	get := func() []PipelineTemplate {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/templates", nil)
+		req.Header.Set("Accept", "application/json")
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+		}
+		var resp struct {
+			Templates []PipelineTemplate `json:"templates"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp.Templates
+	}
+
+	if got := get(); len(got) != 0 {
+		t.Fatalf("empty library listed %+v", got)
+	}
+
+	writeTemplate(t, srv, "review", reviewTemplate)
+	writeTemplate(t, srv, "basic", submitTestDOT)
+	if err := os.WriteFile(filepath.Join(srv.workspace.TemplatesDir(), "notes.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := get()
+	if len(got) != 2 || got[0].Name != "basic" || got[1].Name != "review" {
+		t.Fatalf("templates = %+v, want basic and review", got)
+	}
+	if len(got[0].Params) != 0 || len(got[1].Params) != 3 || got[1].Params[1].Default != "main" {
+		t.Errorf("params = %+v / %+v", got[0].Params, got[1].Params)
+	}
+}
+
+func postTemplate(srv *Server, query, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/pipelines?"+query, bytes.NewBufferString(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestPipelineSubmitFromTemplate(t *testing.T) {
+	srv := newTestServer(t)
+	writeTemplate(t, srv, "review", reviewTemplate)
+
+	p := assertRunCreatedFrom(t, srv, postTemplate(srv, "template=review&repo=mammoth&branch=dev", `{"params": {"note": "first pass"}}`),
+		renderReviewTemplate(t, map[string]string{"repo": "mammoth", "branch": "dev", "note": "first pass"}))
+	if p.Name != "review" {
+		t.Errorf("project name = %q, want the template name", p.Name)
+	}
+	if !strings.Contains(p.DOT, `command="echo mammoth@dev first pass"`) || strings.Contains(p.DOT, "{{") {
+		t.Errorf("project DOT = %s", p.DOT)
+	}
+
+	named := assertRunCreatedFrom(t, srv, postTemplate(srv, "template=review", `{"name": "nightly", "params": {"repo": "tracker", "note": "n"}}`),
+		renderReviewTemplate(t, map[string]string{"repo": "tracker", "note": "n"}))
+	if named.Name != "nightly" || !strings.Contains(named.DOT, "tracker@main") {
+		t.Errorf("named run = {Name:%q DOT:%q}", named.Name, named.DOT)
+	}
+}
+
+// renderReviewTemplate instantiates reviewTemplate the way a template
+// submission would, giving the source the started run should have stored.
+func renderReviewTemplate(t *testing.T, params map[string]string) string {
+	t.Helper()
+	source, err := parseTemplate("review", reviewTemplate).Instantiate(params)
+	if err != nil {
+		t.Fatalf("instantiate review: %v", err)
+	}
+	return source
+}
+
+func TestPipelineSubmitFromTemplateRejections(t *testing.T) {
+	srv := newTestServer(t)
+	writeTemplate(t, srv, "review", reviewTemplate)
+
+	tests := []struct {
+		name, query, body string
+		want              int
+	}{
+		{"unknown template", "template=missing", "", http.StatusNotFound},
+		{"path traversal", "template=..%2Freview", "", http.StatusNotFound},
+		{"missing parameter", "template=review&note=n", "", http.StatusBadRequest},
+		{"unknown parameter", "template=review&repo=r&note=n&colour=red", "", http.StatusBadRequest},
+		{"source and template", "template=review", `{"source": "digraph x {}"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if rec := postTemplate(srv, tt.query, tt.body); rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d; body = %s", tt.name, rec.Code, tt.want, rec.Body.String())
+		}
+	}
+}
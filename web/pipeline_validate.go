@@ -0,0 +1,113 @@
+// ABOUTME: HTTP handler that validates a posted DOT pipeline and returns its diagnostics without running it.
+// ABOUTME: Accepts the same body formats as POST /pipelines and always answers 200 with the lint results.
+package web
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/2389-research/mammoth/dot"
+	"github.com/2389-research/mammoth/dot/validator"
+)
+
+// validateDiagnostic is one finding in a POST /validate response. Line and
+// Col are set only for parse errors.
+type validateDiagnostic struct {
+	Severity string `json:"severity"`
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+	Node     string `json:"node,omitempty"`
+	Edge     string `json:"edge,omitempty"`
+	Fix      string `json:"fix,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Col      int    `json:"col,omitempty"`
+}
+
+// validateResponse is the body of a POST /validate response. Valid is false
+// when any diagnostic has severity "error"; such a pipeline would be
+// rejected by POST /pipelines.
+type validateResponse struct {
+	Valid       bool                 `json:"valid"`
+	Errors      int                  `json:"errors"`
+	Warnings    int                  `json:"warnings"`
+	Diagnostics []validateDiagnostic `json:"diagnostics"`
+}
+
+// lintFixHints suggests a fix for the lint rules whose remedy does not
+// depend on the pipeline's intent.
+var lintFixHints = map[string]string{
+	"parse":               "correct the DOT syntax at the reported line and column",
+	"start_node":          "give exactly one node shape=Mdiamond",
+	"exit_node":           "add a node with shape=Msquare",
+	"start_no_incoming":   "remove the edges that lead into the start node",
+	"exit_no_outgoing":    "remove the edges that leave the exit node",
+	"reachability":        "add an edge into the node from a node reachable from start, or delete it",
+	"dead_end":            "add an outgoing edge, for example to the exit node",
+	"edge_target_exists":  "declare the missing node or fix the edge's node name",
+	"retry_target":        "point retry_target at an existing node",
+	"prompt_required":     "add a prompt attribute describing the node's task",
+	"graph_goal":          `add graph [goal="..."] describing what the pipeline builds`,
+	"goal_gate_has_retry": "add a retry_target for the goal gate to route back to",
+	"valid_weight":        "use a positive integer weight",
+	"max_retries":         "use a non-negative integer for max_retries",
+}
+
+// handlePipelineValidate parses and lints a posted pipeline and responds 200
+// with its diagnostics, including when the pipeline has errors. Nothing is
+// created or run. Malformed requests get the same errors as POST /pipelines.
+func (s *Server) handlePipelineValidate(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxPipelineSubmission)
+	sub, err := readPipelineSubmission(r)
+	switch {
+	case errors.Is(err, errUnsupportedMediaType):
+		writeError(w, r, http.StatusUnsupportedMediaType, ErrCodeUnsupportedMedia, "unsupported content type")
+		return
+	case isMaxBytesError(err):
+		writeError(w, r, http.StatusRequestEntityTooLarge, ErrCodeTooLarge, "request body too large")
+		return
+	case err != nil:
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "bad request")
+		return
+	}
+	if sub.Source == "" {
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "pipeline source is required")
+		return
+	}
+	writeSpecJSON(w, http.StatusOK, validatePipelineSource(sub.Source))
+}
+
+// validatePipelineSource runs the checks POST /pipelines applies before a
+// build: a parse, then the lint rules.
+func validatePipelineSource(source string) validateResponse {
+	resp := validateResponse{Diagnostics: []validateDiagnostic{}}
+	_, diags, err := validator.ParseAndValidate(source)
+	if err != nil {
+		d := validateDiagnostic{Severity: "error", Rule: "parse", Message: err.Error(), Fix: lintFixHints["parse"]}
+		var pe dot.PositionedError
+		if errors.As(err, &pe) {
+			d.Line, d.Col = pe.Line(), pe.Col()
+		}
+		resp.Diagnostics = append(resp.Diagnostics, d)
+		resp.Errors = 1
+		return resp
+	}
+
+	for _, d := range diags {
+		resp.Diagnostics = append(resp.Diagnostics, validateDiagnostic{
+			Severity: d.Severity,
+			Rule:     d.Rule,
+			Message:  d.Message,
+			Node:     d.NodeID,
+			Edge:     d.EdgeID,
+			Fix:      lintFixHints[d.Rule],
+		})
+		switch d.Severity {
+		case "error":
+			resp.Errors++
+		case "warning":
+			resp.Warnings++
+		}
+	}
+	resp.Valid = resp.Errors == 0
+	return resp
+}
@@ -0,0 +1,96 @@
+// ABOUTME: Tests for POST /validate, which lints posted DOT without creating a project or run.
+// ABOUTME: Valid, invalid, and unparseable pipelines all answer 200 with their diagnostics in the body.
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func postValidate(t *testing.T, srv *Server, contentType, body string) validateResponse {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/validate", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+	var resp validateResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return resp
+}
+
+func TestValidateValidPipeline(t *testing.T) {
+	srv := newTestServer(t)
+	resp := postValidate(t, srv, "text/vnd.graphviz", submitTestDOT)
+	if !resp.Valid || resp.Errors != 0 {
+		t.Errorf("response = %+v, want valid with no errors", resp)
+	}
+	if len(resp.Diagnostics) != resp.Warnings {
+		t.Errorf("diagnostics = %+v, want only warnings", resp.Diagnostics)
+	}
+	if resp.Diagnostics == nil {
+		t.Error("diagnostics should be an empty list, not null")
+	}
+	if projects := srv.store.List(); len(projects) != 0 {
+		t.Errorf("validate created %d project(s), want none", len(projects))
+	}
+}
+
+func TestValidateInvalidPipeline(t *testing.T) {
+	srv := newTestServer(t)
+	body, _ := json.Marshal(map[string]string{"source": `digraph bad {
+		start [shape=Mdiamond]
+		work [shape=box, prompt="Do it"]
+		start -> work
+		work -> missing
+	}`})
+	resp := postValidate(t, srv, "application/json", string(body))
+	if resp.Valid || resp.Errors == 0 {
+		t.Fatalf("response = %+v, want invalid with errors", resp)
+	}
+	rules := map[string]validateDiagnostic{}
+	for _, d := range resp.Diagnostics {
+		rules[d.Rule] = d
+	}
+	exit, ok := rules["exit_node"]
+	if !ok || exit.Severity != "error" || exit.Fix == "" {
+		t.Errorf("exit_node diagnostic = %+v, want an error with a fix", exit)
+	}
+	if dead := rules["dead_end"]; dead.Severity != "warning" || dead.Node != "missing" {
+		t.Errorf("dead_end diagnostic = %+v, want a warning naming node missing", dead)
+	}
+	if resp.Warnings == 0 {
+		t.Errorf("warnings = 0, want the dead end and missing goal counted")
+	}
+}
+
+func TestValidateParseError(t *testing.T) {
+	srv := newTestServer(t)
+	resp := postValidate(t, srv, "text/plain", "digraph broken {\n  a -> \n")
+	if resp.Valid || len(resp.Diagnostics) != 1 {
+		t.Fatalf("response = %+v, want one parse diagnostic", resp)
+	}
+	d := resp.Diagnostics[0]
+	if d.Rule != "parse" || d.Severity != "error" || d.Line == 0 {
+		t.Errorf("diagnostic = %+v, want a positioned parse error", d)
+	}
+}
+
+func TestValidateRejectsEmptySource(t *testing.T) {
+	srv := newTestServer(t)
+	req := httptest.NewRequest(http.MethodPost, "/validate", bytes.NewBufferString("  "))
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("empty source: status = %d, want 400", rec.Code)
+	}
+}
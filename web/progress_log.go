@@ -0,0 +1,76 @@
+// ABOUTME: Append-only progress.ndjson store holding every event of a build run.
+// ABOUTME: The events query, summary, and timeline read it, so it stays complete while the SSE replay history is capped.
+package web
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// progressLogFile is the name of a run's event store inside its progress
+// log directory.
+const progressLogFile = "progress.ndjson"
+
+// progressLine is one line of progress.ndjson.
+type progressLine struct {
+	Timestamp string         `json:"timestamp"`
+	Type      string         `json:"type"`
+	NodeID    string         `json:"node_id,omitempty"`
+	Message   string         `json:"message,omitempty"`
+	Data      map[string]any `json:"data,omitempty"`
+}
+
+// progressLog appends build events to a run's progress.ndjson. It is safe
+// for concurrent use; a nil *progressLog discards events.
+type progressLog struct {
+	mu  sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+}
+
+// openProgressLog opens (or creates) the progress.ndjson in dir for
+// appending, so a resumed run extends its earlier events.
+func openProgressLog(dir string) (*progressLog, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, progressLogFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &progressLog{f: f, enc: json.NewEncoder(f)}, nil
+}
+
+// Append writes evt as one progress.ndjson line under its dotted SSE name.
+func (l *progressLog) Append(evt BuildEvent) error {
+	if l == nil {
+		return nil
+	}
+	ts := evt.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	entry := progressLine{
+		Timestamp: ts.UTC().Format(time.RFC3339Nano),
+		Type:      evt.Type.SSEEventName(),
+		NodeID:    evt.NodeID,
+		Message:   evt.Message,
+		Data:      evt.Data,
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.enc.Encode(entry)
+}
+
+// Close closes the underlying file.
+func (l *progressLog) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.f.Close()
+}
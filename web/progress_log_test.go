@@ -0,0 +1,134 @@
+// ABOUTME: Tests for the capped SSE replay history and the progress.ndjson store behind it.
+// ABOUTME: A long run keeps a bounded history with its lifecycle events while the events query still returns every event.
+package web
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// noisyRunEvents is a run whose nodes each stream many text deltas.
+func noisyRunEvents(nodes, deltasPerNode int) []BuildEvent {
+	ts := time.Date(2026, 2, 14, 19, 30, 0, 0, time.UTC)
+	next := func() time.Time { ts = ts.Add(time.Millisecond); return ts }
+	events := []BuildEvent{{Type: BuildEventPipelineStarted, Timestamp: next()}}
+	for i := 0; i < nodes; i++ {
+		id := fmt.Sprintf("n%d", i)
+		events = append(events, BuildEvent{Type: BuildEventNodeStarted, NodeID: id, Timestamp: next()})
+		for j := 0; j < deltasPerNode; j++ {
+			events = append(events, BuildEvent{Type: BuildEventTextDelta, NodeID: id, Timestamp: next(), Data: map[string]any{"text": "x"}})
+		}
+		events = append(events, BuildEvent{Type: BuildEventNodeCompleted, NodeID: id, Timestamp: next()})
+	}
+	return append(events, BuildEvent{Type: BuildEventPipelineCompleted, Timestamp: next()})
+}
+
+// waitFanoutClosed waits for the run's broadcaster to drain Events.
+func waitFanoutClosed(t *testing.T, run *BuildRun) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		run.mu.Lock()
+		closed := run.closed
+		run.mu.Unlock()
+		if closed {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("fanout did not finish")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHistoryBoundedWhileStoreKeepsAllEvents(t *testing.T) {
+	srv, projectID := setupServerWithEvents(t)
+	p, _ := srv.store.Get(projectID)
+	progress, err := openProgressLog(srv.workspace.ProgressLogDir(projectID, p.RunID))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const limit = 20
+	run := &BuildRun{Events: make(chan SSEEvent), MaxHistory: limit}
+	run.EnsureFanoutStarted()
+	events := noisyRunEvents(4, 30)
+	for _, evt := range events {
+		if err := progress.Append(evt); err != nil {
+			t.Fatal(err)
+		}
+		run.Events <- buildEventToSSE(evt)
+	}
+	close(run.Events)
+	waitFanoutClosed(t, run)
+	if err := progress.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	history := run.HistorySnapshot()
+	if len(history) > limit+1 {
+		t.Fatalf("history has %d events, want at most %d plus the truncation marker", len(history), limit)
+	}
+	if history[0].Event != "history.truncated" {
+		t.Errorf("first replayed event = %q, want history.truncated", history[0].Event)
+	}
+	kept := map[string]int{}
+	for _, evt := range history[1:] {
+		kept[evt.Event]++
+	}
+	if kept["pipeline.started"] != 1 || kept["pipeline.completed"] != 1 {
+		t.Errorf("pipeline events not kept: %v", kept)
+	}
+	if kept["stage.started"] != 4 || kept["stage.completed"] != 4 {
+		t.Errorf("stage events not kept: %v", kept)
+	}
+	if last := history[len(history)-1].Event; last != "pipeline.completed" {
+		t.Errorf("last replayed event = %q, want pipeline.completed", last)
+	}
+
+	if got := queryEvents(t, srv, projectID, ""); len(got) != len(events) {
+		t.Errorf("events query returned %d events, want all %d", len(got), len(events))
+	}
+	if got := queryEvents(t, srv, projectID, "type=agent.text_delta&node=n0"); len(got) != 30 {
+		t.Errorf("text deltas for n0 = %d, want 30", len(got))
+	}
+}
+
+func TestTrimHistoryKeepsLifecycleAndTail(t *testing.T) {
+	var history []SSEEvent
+	for _, name := range []string{
+		"pipeline.started", "stage.started", "agent.text_delta", "agent.tool_call.start",
+		"agent.tool_call.end", "human_gate.choice", "agent.text_delta", "agent.text_delta",
+		"checkpoint.saved", "stage.completed", "agent.text_delta", "agent.text_delta",
+	} {
+		history = append(history, SSEEvent{Event: name})
+	}
+
+	kept, dropped := trimHistory(history, 8)
+	var names []string
+	for _, evt := range kept {
+		names = append(names, evt.Event)
+	}
+	want := "pipeline.started stage.started human_gate.choice checkpoint.saved stage.completed agent.text_delta agent.text_delta"
+	if got := strings.Join(names, " "); got != want {
+		t.Errorf("kept %s\nwant %s", got, want)
+	}
+	if dropped != 5 {
+		t.Errorf("dropped = %d, want 5", dropped)
+	}
+}
+
+func TestHistoryDefaultCap(t *testing.T) {
+	run := &BuildRun{Events: make(chan SSEEvent)}
+	run.EnsureFanoutStarted()
+	for i := 0; i < DefaultMaxEventHistory+50; i++ {
+		run.Events <- SSEEvent{Event: "agent.text_delta", Data: "{}"}
+	}
+	close(run.Events)
+	waitFanoutClosed(t, run)
+	if got := len(run.HistorySnapshot()); got > DefaultMaxEventHistory+1 {
+		t.Errorf("history has %d events, want at most %d", got, DefaultMaxEventHistory+1)
+	}
+}
@@ -38,6 +38,10 @@ type Project struct {
 	Diagnostics []string     `json:"diagnostics,omitempty"`
 	RunID       string       `json:"run_id,omitempty"`
 	DataDir     string       `json:"-"`
+
+	// InitialContext seeds each build's pipeline context before the start
+	// node runs (the initial_context of POST /pipelines).
+	InitialContext map[string]string `json:"initial_context,omitempty"`
 }
 
 // ProjectStore provides in-memory storage with filesystem persistence for projects.
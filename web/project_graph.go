@@ -0,0 +1,139 @@
+// ABOUTME: HTTP handler exporting a project's pipeline graph as SVG, PNG, DOT, or Mermaid.
+// ABOUTME: The format comes from ?format= or the Accept header; a project's build colors nodes by outcome.
+package web
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/2389-research/mammoth/dot"
+	"github.com/2389-research/mammoth/render"
+	"github.com/go-chi/chi/v5"
+)
+
+// graphMediaTypes maps the media types the graph endpoint negotiates on to
+// the format each selects.
+var graphMediaTypes = map[string]string{
+	"image/svg+xml":     "svg",
+	"image/png":         "png",
+	"text/vnd.graphviz": "dot",
+}
+
+// graphFormat picks the output format for a graph request: an explicit
+// ?format= wins, then the first Accept media type the endpoint can produce,
+// then SVG.
+func graphFormat(r *http.Request) string {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format
+	}
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, _ := strings.Cut(part, ";")
+		if format, ok := graphMediaTypes[strings.ToLower(strings.TrimSpace(mediaType))]; ok {
+			return format
+		}
+	}
+	return "svg"
+}
+
+// handleProjectGraph serves GET /projects/{projectID}/graph in the format
+// chosen by graphFormat: svg (the default), png, dot, or mermaid. Projects
+// with a build get the status-colored variant, with a color legend when
+// ?legend=true. Without Graphviz installed, SVG falls back to DOT text and
+// PNG is unavailable.
+func (s *Server) handleProjectGraph(w http.ResponseWriter, r *http.Request) {
+	projectID := chi.URLParam(r, "projectID")
+	p, ok := s.store.Get(projectID)
+	if !ok {
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project not found")
+		return
+	}
+	if p.DOT == "" {
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project has no pipeline")
+		return
+	}
+	g, err := dot.Parse(p.DOT)
+	if err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, ErrCodeParse, "invalid DOT: "+err.Error())
+		return
+	}
+
+	w.Header().Add("Vary", "Accept")
+	outcomes := s.buildOutcomes(projectID)
+	dotText := render.ToDOT(g)
+	if outcomes != nil {
+		if r.URL.Query().Get("legend") == "true" {
+			dotText = render.ToDOTWithStatusLegend(g, outcomes)
+		} else {
+			dotText = render.ToDOTWithStatus(g, outcomes)
+		}
+	}
+
+	var out []byte
+	var contentType string
+	switch format := graphFormat(r); format {
+	case "dot":
+		contentType = "text/vnd.graphviz; charset=utf-8"
+		out = []byte(dotText)
+	case "mermaid":
+		contentType = "text/plain; charset=utf-8"
+		if outcomes != nil {
+			out = []byte(render.ToMermaidWithStatus(g, outcomes))
+		} else {
+			out = []byte(render.ToMermaid(g))
+		}
+	case "svg", "png":
+		if !render.GraphvizAvailable() {
+			if format == "png" {
+				writeError(w, r, http.StatusServiceUnavailable, ErrCodeInternal, "graphviz is not installed: PNG rendering is unavailable")
+				return
+			}
+			contentType = "text/vnd.graphviz; charset=utf-8"
+			out = []byte(dotText)
+			break
+		}
+		out, err = render.RenderDOTSource(r.Context(), dotText, format)
+		if err != nil {
+			log.Printf("component=web.graph action=render_failed project_id=%s format=%s err=%v", projectID, format, err)
+			writeError(w, r, http.StatusInternalServerError, ErrCodeInternal, "failed to render graph")
+			return
+		}
+		contentType = "image/svg+xml"
+		if format == "png" {
+			contentType = "image/png"
+		}
+	default:
+		writeError(w, r, http.StatusBadRequest, ErrCodeBadRequest, "format must be \"svg\", \"png\", \"dot\", or \"mermaid\"")
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(out)
+}
+
+// buildOutcomes maps the project's in-memory run state to render outcomes:
+// completed nodes succeeded, and the current node is running or, if the run
+// failed, failed. Returns nil when the project has no build.
+func (s *Server) buildOutcomes(projectID string) map[string]*render.Outcome {
+	s.buildsMu.RLock()
+	defer s.buildsMu.RUnlock()
+	run, ok := s.builds[projectID]
+	if !ok || run == nil || run.State == nil {
+		return nil
+	}
+
+	outcomes := map[string]*render.Outcome{}
+	for _, id := range run.State.CompletedNodes {
+		outcomes[id] = &render.Outcome{Status: render.StatusSuccess}
+	}
+	if id := run.State.CurrentNode; id != "" {
+		switch run.State.Status {
+		case "running":
+			outcomes[id] = &render.Outcome{Status: render.StatusRetry}
+		case "failed":
+			outcomes[id] = &render.Outcome{Status: render.StatusFail}
+		}
+	}
+	return outcomes
+}
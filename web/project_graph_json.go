@@ -0,0 +1,117 @@
+// ABOUTME: HTTP handler exporting a project's pipeline graph as structured JSON for custom frontends.
+// ABOUTME: Nodes carry their run status and edges are marked traversed when the run took them.
+package web
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/2389-research/mammoth/dot"
+	"github.com/go-chi/chi/v5"
+)
+
+// GraphJSON is the JSON body returned by GET /projects/{projectID}/graph.json.
+// Status is the run status ("running", "completed", "failed", "cancelled"),
+// or "pending" when the project has no build.
+type GraphJSON struct {
+	Status string          `json:"status"`
+	Nodes  []GraphJSONNode `json:"nodes"`
+	Edges  []GraphJSONEdge `json:"edges"`
+}
+
+// GraphJSONNode is one node of a GraphJSON. Status is "success", "running",
+// "failed", or "pending".
+type GraphJSONNode struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Label  string `json:"label"`
+	Status string `json:"status"`
+}
+
+// GraphJSONEdge is one edge of a GraphJSON. Traversed is true when the run
+// completed the edge's source and went on to reach its target.
+type GraphJSONEdge struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Condition string `json:"condition,omitempty"`
+	Traversed bool   `json:"traversed"`
+}
+
+// handleProjectGraphJSON serves GET /projects/{projectID}/graph.json. Nodes
+// are sorted by ID; edges keep their order in the DOT source.
+func (s *Server) handleProjectGraphJSON(w http.ResponseWriter, r *http.Request) {
+	projectID := chi.URLParam(r, "projectID")
+	p, ok := s.store.Get(projectID)
+	if !ok {
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project not found")
+		return
+	}
+	if p.DOT == "" {
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project has no pipeline")
+		return
+	}
+	g, err := dot.Parse(p.DOT)
+	if err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, ErrCodeParse, "invalid DOT: "+err.Error())
+		return
+	}
+	writeSpecJSON(w, http.StatusOK, graphJSON(g, s.buildRunSnapshot(projectID)))
+}
+
+// buildRunSnapshot returns a copy of the project's run state, or nil when
+// the project has no build.
+func (s *Server) buildRunSnapshot(projectID string) *RunState {
+	s.buildsMu.RLock()
+	defer s.buildsMu.RUnlock()
+	run, ok := s.builds[projectID]
+	if !ok || run == nil || run.State == nil {
+		return nil
+	}
+	state := *run.State
+	state.CompletedNodes = slices.Clone(run.State.CompletedNodes)
+	return &state
+}
+
+// graphJSON builds the structured graph for g with the statuses in state,
+// which may be nil for a project that has not been built.
+func graphJSON(g *dot.Graph, state *RunState) GraphJSON {
+	out := GraphJSON{Status: "pending", Nodes: []GraphJSONNode{}, Edges: []GraphJSONEdge{}}
+	completed := map[string]bool{}
+	current := ""
+	if state != nil {
+		out.Status = state.Status
+		for _, id := range state.CompletedNodes {
+			completed[id] = true
+		}
+		current = state.CurrentNode
+	}
+
+	shapes := g.ShapeMapping()
+	for _, id := range g.NodeIDs() {
+		n := g.Nodes[id]
+		label := n.Attrs["label"]
+		if label == "" {
+			label = id
+		}
+		status := "pending"
+		switch {
+		case completed[id]:
+			status = "success"
+		case id == current && state.Status == "running":
+			status = "running"
+		case id == current && state.Status == "failed":
+			status = "failed"
+		}
+		out.Nodes = append(out.Nodes, GraphJSONNode{ID: id, Type: shapes.NodeType(n), Label: label, Status: status})
+	}
+
+	for _, e := range g.Edges {
+		out.Edges = append(out.Edges, GraphJSONEdge{
+			From:      e.From,
+			To:        e.To,
+			Condition: e.Attrs["condition"],
+			Traversed: completed[e.From] && (completed[e.To] || e.To == current),
+		})
+	}
+	return out
+}
@@ -0,0 +1,146 @@
+// ABOUTME: Tests for GET /projects/{id}/graph.json, the structured graph export.
+// ABOUTME: Covers node statuses from the run state, traversed edges on a completed run, and unbuilt projects.
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const graphJSONTestDOT = `digraph g {
+	start [shape=Mdiamond]
+	check [shape=diamond, label="Check it"]
+	fix [shape=box, prompt="fix"]
+	done [shape=Msquare]
+	start -> check
+	check -> done [condition="outcome=success"]
+	check -> fix [condition="outcome=fail"]
+	fix -> check
+}`
+
+func getProjectGraphJSON(t *testing.T, srv *Server, projectID string) GraphJSON {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+projectID+"/graph.json", nil)
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var g GraphJSON
+	if err := json.NewDecoder(rec.Body).Decode(&g); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return g
+}
+
+func newGraphJSONProject(t *testing.T, srv *Server) string {
+	t.Helper()
+	p, err := srv.store.Create("graph-json")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	p.DOT = graphJSONTestDOT
+	if err := srv.store.Update(p); err != nil {
+		t.Fatalf("update project: %v", err)
+	}
+	return p.ID
+}
+
+func TestProjectGraphJSONCompletedRun(t *testing.T) {
+	srv := newTestServer(t)
+	projectID := newGraphJSONProject(t, srv)
+	srv.buildsMu.Lock()
+	srv.builds[projectID] = &BuildRun{State: &RunState{
+		ID:             "graph-run",
+		Status:         "completed",
+		CompletedNodes: []string{"start", "check", "done"},
+	}}
+	srv.buildsMu.Unlock()
+
+	g := getProjectGraphJSON(t, srv, projectID)
+	if g.Status != "completed" {
+		t.Errorf("status = %q, want completed", g.Status)
+	}
+
+	nodes := map[string]GraphJSONNode{}
+	for _, n := range g.Nodes {
+		nodes[n.ID] = n
+	}
+	wantNodes := map[string]GraphJSONNode{
+		"start": {ID: "start", Type: "start", Label: "start", Status: "success"},
+		"check": {ID: "check", Type: "conditional", Label: "Check it", Status: "success"},
+		"fix":   {ID: "fix", Type: "codergen", Label: "fix", Status: "pending"},
+		"done":  {ID: "done", Type: "exit", Label: "done", Status: "success"},
+	}
+	for id, want := range wantNodes {
+		if nodes[id] != want {
+			t.Errorf("node %s = %+v, want %+v", id, nodes[id], want)
+		}
+	}
+
+	wantEdges := []GraphJSONEdge{
+		{From: "start", To: "check", Traversed: true},
+		{From: "check", To: "done", Condition: "outcome=success", Traversed: true},
+		{From: "check", To: "fix", Condition: "outcome=fail", Traversed: false},
+		{From: "fix", To: "check", Traversed: false},
+	}
+	if len(g.Edges) != len(wantEdges) {
+		t.Fatalf("edges = %+v, want %+v", g.Edges, wantEdges)
+	}
+	for i, want := range wantEdges {
+		if g.Edges[i] != want {
+			t.Errorf("edge %d = %+v, want %+v", i, g.Edges[i], want)
+		}
+	}
+}
+
+func TestProjectGraphJSONRunningAndUnbuilt(t *testing.T) {
+	srv := newTestServer(t)
+	projectID := newGraphJSONProject(t, srv)
+
+	g := getProjectGraphJSON(t, srv, projectID)
+	if g.Status != "pending" {
+		t.Errorf("unbuilt status = %q, want pending", g.Status)
+	}
+	for _, n := range g.Nodes {
+		if n.Status != "pending" {
+			t.Errorf("unbuilt node %s status = %q, want pending", n.ID, n.Status)
+		}
+	}
+	for _, e := range g.Edges {
+		if e.Traversed {
+			t.Errorf("unbuilt edge %s->%s is traversed", e.From, e.To)
+		}
+	}
+
+	srv.buildsMu.Lock()
+	srv.builds[projectID] = &BuildRun{State: &RunState{
+		ID:             "graph-run",
+		Status:         "running",
+		CurrentNode:    "check",
+		CompletedNodes: []string{"start"},
+	}}
+	srv.buildsMu.Unlock()
+
+	g = getProjectGraphJSON(t, srv, projectID)
+	for _, n := range g.Nodes {
+		if n.ID == "check" && n.Status != "running" {
+			t.Errorf("current node status = %q, want running", n.Status)
+		}
+	}
+	if !g.Edges[0].Traversed || g.Edges[1].Traversed {
+		t.Errorf("edges = %+v: only start->check should be traversed", g.Edges)
+	}
+}
+
+func TestProjectGraphJSONNotFound(t *testing.T) {
+	srv := newTestServer(t)
+	req := httptest.NewRequest(http.MethodGet, "/projects/missing/graph.json", nil)
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+}
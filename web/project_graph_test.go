@@ -0,0 +1,183 @@
+// ABOUTME: Tests for GET /projects/{id}/graph exporting the pipeline as SVG, PNG, DOT, or Mermaid.
+// ABOUTME: Covers ?format= and Accept negotiation, the status overlay from the run state, and bad requests.
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/2389-research/mammoth/render"
+)
+
+const graphTestDOT = `digraph g {
+	start [shape=Mdiamond]
+	check [shape=diamond]
+	done [shape=Msquare]
+	start -> check
+	check -> done [condition="outcome=success"]
+}`
+
+func getProjectGraph(t *testing.T, srv *Server, projectID, query string) *httptest.ResponseRecorder {
+	t.Helper()
+	return getProjectGraphAccept(t, srv, projectID, query, "")
+}
+
+func getProjectGraphAccept(t *testing.T, srv *Server, projectID, query, accept string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+projectID+"/graph"+query, nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestProjectGraphFormats(t *testing.T) {
+	srv := newTestServer(t)
+	p, err := srv.store.Create("graph-project")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	p.DOT = graphTestDOT
+	if err := srv.store.Update(p); err != nil {
+		t.Fatalf("update project: %v", err)
+	}
+
+	rec := getProjectGraph(t, srv, p.ID, "?format=mermaid")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("mermaid: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	body := rec.Body.String()
+	for _, want := range []string{"flowchart TD", "start", "check", `-->|"outcome=success"| done`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("mermaid output missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "style ") {
+		t.Errorf("project without a build should not be status-colored:\n%s", body)
+	}
+
+	rec = getProjectGraph(t, srv, p.ID, "?format=dot")
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Body.String(), "digraph g {") {
+		t.Errorf("dot format: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	if rec = getProjectGraph(t, srv, p.ID, "?format=gif"); rec.Code != http.StatusBadRequest {
+		t.Errorf("unsupported format: status = %d, want 400", rec.Code)
+	}
+}
+
+func TestProjectGraphStatusOverlay(t *testing.T) {
+	srv := newTestServer(t)
+	p, err := srv.store.Create("graph-status")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	p.DOT = graphTestDOT
+	if err := srv.store.Update(p); err != nil {
+		t.Fatalf("update project: %v", err)
+	}
+	srv.buildsMu.Lock()
+	srv.builds[p.ID] = &BuildRun{State: &RunState{
+		ID:             "graph-run",
+		Status:         "failed",
+		CurrentNode:    "check",
+		CompletedNodes: []string{"start"},
+	}}
+	srv.buildsMu.Unlock()
+
+	body := getProjectGraph(t, srv, p.ID, "?format=mermaid").Body.String()
+	for _, want := range []string{
+		"style start fill:" + render.StatusColorSuccess,
+		"style check fill:" + render.StatusColorFailed,
+		"style done fill:" + render.StatusColorPending,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in output:\n%s", want, body)
+		}
+	}
+
+	if body := getProjectGraph(t, srv, p.ID, "?format=dot").Body.String(); strings.Contains(body, "cluster_legend") {
+		t.Errorf("legend should be opt-in:\n%s", body)
+	}
+	if body := getProjectGraph(t, srv, p.ID, "?format=dot&legend=true").Body.String(); !strings.Contains(body, "subgraph cluster_legend {") {
+		t.Errorf("expected a legend with ?legend=true:\n%s", body)
+	}
+}
+
+func TestProjectGraphAcceptNegotiation(t *testing.T) {
+	srv := newTestServer(t)
+	p, err := srv.store.Create("graph-accept")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	p.DOT = graphTestDOT
+	if err := srv.store.Update(p); err != nil {
+		t.Fatalf("update project: %v", err)
+	}
+
+	tests := []struct {
+		name, query, accept string
+		graphviz            bool
+		contentType         string
+		bodyPrefix          string
+	}{
+		{name: "graphviz accept", accept: "text/vnd.graphviz", contentType: "text/vnd.graphviz", bodyPrefix: "digraph g {"},
+		{name: "svg accept", accept: "image/svg+xml", graphviz: true, contentType: "image/svg+xml"},
+		{name: "png accept", accept: "image/png", graphviz: true, contentType: "image/png", bodyPrefix: "\x89PNG"},
+		{name: "first supported type wins", accept: "text/html, text/vnd.graphviz;q=0.9, image/png", contentType: "text/vnd.graphviz", bodyPrefix: "digraph g {"},
+		{name: "default is svg", graphviz: true, contentType: "image/svg+xml"},
+		{name: "wildcard is svg", accept: "*/*", graphviz: true, contentType: "image/svg+xml"},
+		{name: "query beats accept", query: "?format=mermaid", accept: "image/png", contentType: "text/plain", bodyPrefix: "flowchart TD"},
+		{name: "dot query beats svg accept", query: "?format=dot", accept: "image/svg+xml", contentType: "text/vnd.graphviz", bodyPrefix: "digraph g {"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.graphviz && !render.GraphvizAvailable() {
+				t.Skip("graphviz not installed")
+			}
+			rec := getProjectGraphAccept(t, srv, p.ID, tt.query, tt.accept)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+			}
+			if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, tt.contentType) {
+				t.Errorf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+			if !strings.HasPrefix(rec.Body.String(), tt.bodyPrefix) {
+				t.Errorf("body does not start with %q:\n%.200s", tt.bodyPrefix, rec.Body.String())
+			}
+			if tt.contentType == "image/svg+xml" && !strings.Contains(rec.Body.String(), "<svg") {
+				t.Errorf("expected SVG markup:\n%.200s", rec.Body.String())
+			}
+			if !strings.Contains(rec.Header().Get("Vary"), "Accept") {
+				t.Errorf("Vary = %q, want Accept", rec.Header().Get("Vary"))
+			}
+		})
+	}
+}
+
+func TestProjectGraphWithoutGraphviz(t *testing.T) {
+	if render.GraphvizAvailable() {
+		t.Skip("graphviz is installed")
+	}
+	srv := newTestServer(t)
+	p, err := srv.store.Create("graph-no-graphviz")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	p.DOT = graphTestDOT
+	if err := srv.store.Update(p); err != nil {
+		t.Fatalf("update project: %v", err)
+	}
+
+	rec := getProjectGraphAccept(t, srv, p.ID, "", "image/svg+xml")
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/vnd.graphviz") {
+		t.Errorf("svg fallback: status = %d, Content-Type = %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if rec = getProjectGraphAccept(t, srv, p.ID, "", "image/png"); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("png without graphviz: status = %d, want 503", rec.Code)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"io/fs"
 	"log"
 	"net/http"
+	"net/netip"
 	"os"
 	"path"
 	"path/filepath"
@@ -20,12 +21,27 @@ import (
 	"sync"
 	"time"
 
+	"github.com/2389-research/mammoth/answerpattern"
+	"github.com/2389-research/mammoth/artifactcap"
 	"github.com/2389-research/mammoth/editor"
+	"github.com/2389-research/mammoth/genparams"
 	"github.com/2389-research/mammoth/llm"
+	"github.com/2389-research/mammoth/nextnode"
+	"github.com/2389-research/mammoth/nodelock"
+	"github.com/2389-research/mammoth/providerpin"
+	"github.com/2389-research/mammoth/redact"
+	"github.com/2389-research/mammoth/retrybackoff"
 	"github.com/2389-research/mammoth/runstate"
+	"github.com/2389-research/mammoth/shapemap"
+	"github.com/2389-research/mammoth/skipif"
 	"github.com/2389-research/mammoth/spec/core"
 	"github.com/2389-research/mammoth/spec/server"
 	specweb "github.com/2389-research/mammoth/spec/web"
+	"github.com/2389-research/mammoth/streaming"
+	"github.com/2389-research/mammoth/successif"
+	"github.com/2389-research/mammoth/tokenbudget"
+	"github.com/2389-research/mammoth/toolguard"
+	"github.com/2389-research/mammoth/tracing"
 	"github.com/2389-research/tracker/agent"
 	"github.com/2389-research/tracker/agent/exec"
 	"github.com/2389-research/tracker/pipeline"
@@ -33,6 +49,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/oklog/ulid/v2"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Server is the unified mammoth HTTP server that provides the wizard flow:
@@ -70,6 +87,44 @@ type Server struct {
 	// llmClient is the tracker LLM client for pipeline execution.
 	// If nil, codergen nodes will run without LLM support.
 	llmClient agent.Completer
+
+	// auditDecisions records every human gate answer in the run's decision log.
+	auditDecisions bool
+
+	// trustedProxies are the peer ranges whose X-Forwarded-User header is
+	// recorded as who answered a human gate.
+	trustedProxies []netip.Prefix
+
+	// redactor masks secrets in build events, persisted progress, node
+	// outcomes, and run errors.
+	redactor *redact.Redactor
+
+	// reasoning masks secrets in model reasoning forwarded to build events;
+	// nil unless ShowReasoning was set, in which case reasoning is dropped.
+	reasoning *redact.Redactor
+
+	// nodeLocks serializes nodes sharing a mutex attribute across every
+	// build this server runs.
+	nodeLocks *nodelock.Manager
+
+	// tracer creates the run and node spans of every build.
+	tracer *tracing.Tracer
+
+	// maxEventHistory caps each build's in-memory SSE replay history.
+	maxEventHistory int
+
+	// nodeWebhook delivers per-node callbacks; nil when not configured.
+	nodeWebhook *nodeWebhook
+
+	// stopping is set by Stop (under buildsMu) so no new builds start;
+	// inflight counts the build goroutines Stop drains.
+	stopping bool
+	inflight sync.WaitGroup
+
+	// stopEditorCleanup ends the editor session cleanup loop; llmCloser
+	// releases the backend client's connections. Both run once, from Stop.
+	stopEditorCleanup func()
+	llmCloser         io.Closer
 }
 
 // ServerConfig holds the configuration for the unified web server.
@@ -77,6 +132,41 @@ type ServerConfig struct {
 	Addr      string          // listen address (default: "127.0.0.1:2389")
 	Workspace Workspace       // workspace for path resolution
 	LLMClient agent.Completer // tracker LLM client for pipeline execution (optional)
+
+	// MaxConcurrentLLM caps in-flight LLM requests across every build on the
+	// server; excess requests queue. Zero means unlimited.
+	MaxConcurrentLLM int
+
+	// AuditDecisions appends every human gate decision to a decisions.jsonl
+	// audit log beside each run's checkpoint.
+	AuditDecisions bool
+
+	// TrustedProxies lists the addresses or CIDR ranges of authenticating
+	// proxies in front of the server. A decision answered through one of
+	// them records its X-Forwarded-User header; from anywhere else the
+	// header is ignored and only a basic-auth user is recorded.
+	TrustedProxies []string
+
+	// ShowReasoning forwards the model's reasoning text, with secrets
+	// redacted, to build events and the build view's console. Reasoning can
+	// repeat sensitive prompt content, so it is off by default.
+	ShowReasoning bool
+
+	// TracerProvider receives an OpenTelemetry span per build run with a
+	// child span per node. Nil uses a no-op tracer.
+	TracerProvider trace.TracerProvider
+
+	// MaxEventHistory caps the events each build keeps in memory for SSE
+	// replay. Past the cap, older agent events are dropped while lifecycle
+	// events and the newest events stay; every event is still written to the
+	// run's progress.ndjson. Zero means DefaultMaxEventHistory.
+	MaxEventHistory int
+
+	// NodeWebhook, when set, is a URL that receives a JSON POST as each node
+	// starts and completes, carrying the run ID, node ID, status, and timing.
+	// Deliveries are asynchronous with bounded retries and never block a
+	// build.
+	NodeWebhook string
 }
 
 // NewServer creates a new Server with the given configuration. It initializes
@@ -97,6 +187,11 @@ func NewServer(cfg ServerConfig) (*Server, error) {
 		return nil, fmt.Errorf("loading projects: %w", err)
 	}
 
+	trustedProxies, err := parseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
 	tmpl, err := NewTemplateEngine()
 	if err != nil {
 		return nil, fmt.Errorf("initializing templates: %w", err)
@@ -116,7 +211,7 @@ func NewServer(cfg ServerConfig) (*Server, error) {
 	}
 
 	editorStore := editor.NewStore(200, 24*time.Hour)
-	editorStore.StartCleanup(15 * time.Minute)
+	stopEditorCleanup := editorStore.StartCleanup(15 * time.Minute)
 
 	// Build model options from catalog for editor dropdown.
 	catalog := llm.DefaultCatalog()
@@ -141,7 +236,24 @@ func NewServer(cfg ServerConfig) (*Server, error) {
 		editorStore:  editorStore,
 		editorByProj: make(map[string]string),
 		builds:       make(map[string]*BuildRun),
-		llmClient:    cfg.LLMClient,
+		llmClient:    newLimitedCompleter(cfg.LLMClient, cfg.MaxConcurrentLLM),
+
+		auditDecisions: cfg.AuditDecisions,
+		trustedProxies: trustedProxies,
+		nodeLocks:      nodelock.New(),
+		tracer:         tracing.New(cfg.TracerProvider),
+
+		maxEventHistory: cfg.MaxEventHistory,
+		nodeWebhook:     newNodeWebhook(cfg.NodeWebhook),
+
+		stopEditorCleanup: stopEditorCleanup,
+		redactor:          redact.Default(),
+	}
+	if cfg.ShowReasoning {
+		s.reasoning = s.redactor
+	}
+	if closer, ok := cfg.LLMClient.(io.Closer); ok {
+		s.llmCloser = closer
 	}
 	s.dotFixer = s.fixDOTWithAgent
 
@@ -196,6 +308,12 @@ func (s *Server) buildRouter() chi.Router {
 		r.Handle("/static/*", http.StripPrefix("/static/", http.FileServer(http.FS(webStaticFS))))
 	}
 
+	// One-shot pipeline submission: create a project from DOT and build it.
+	r.Post("/pipelines", s.handlePipelineSubmit)
+	r.Post("/pipelines/{projectID}/clone", s.handlePipelineClone)
+	r.Post("/validate", s.handlePipelineValidate)
+	r.Get("/templates", s.handleTemplateList)
+
 	// Project routes
 	r.Route("/projects", func(r chi.Router) {
 		r.Get("/", s.handleProjectList)
@@ -205,6 +323,8 @@ func (s *Server) buildRouter() chi.Router {
 		r.Route("/{projectID}", func(r chi.Router) {
 			r.Get("/", s.handleProjectOverview)
 			r.Get("/validate", s.handleValidate)
+			r.Get("/graph", s.handleProjectGraph)
+			r.Get("/graph.json", s.handleProjectGraphJSON)
 
 			// Spec builder phase (delegates to spec/web handlers via adapter middleware)
 			r.Route("/spec", s.specRouter)
@@ -219,8 +339,16 @@ func (s *Server) buildRouter() chi.Router {
 			r.Post("/build/start", s.handleBuildStart)
 			r.Get("/build", s.handleBuildView)
 			r.Get("/build/events", s.handleBuildEvents)
+			r.Get("/build/events/summary", s.handleBuildEventsSummary)
+			r.Get("/build/events/query", s.handleBuildEventsQuery)
 			r.Get("/build/state", s.handleBuildState)
 			r.Post("/build/stop", s.handleBuildStop)
+			r.Post("/build/retry", s.handleBuildRetry)
+			r.Get("/build/questions", s.handleBuildQuestions)
+			r.Post("/build/questions/{gateID}", s.handleBuildAnswer)
+			r.Get("/build/decisions", s.handleBuildDecisions)
+			r.Get("/build/checkpoint", s.handleBuildCheckpoint)
+			r.Post("/build/checkpoint", s.handleBuildCheckpointImport)
 			r.Get("/final", s.handleFinalView)
 			r.Get("/final/timeline", s.handleFinalTimeline)
 			r.Get("/artifacts/list", s.handleArtifactList)
@@ -239,9 +367,11 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 }
 
 // handleProjectList returns all projects as JSON for API clients, or renders
-// the project list page as HTML when the browser requests text/html.
+// the project list page as HTML when the browser requests text/html. The
+// ?status= and ?q= query parameters filter both.
 func (s *Server) handleProjectList(w http.ResponseWriter, r *http.Request) {
-	projects := s.store.List()
+	filter := parseProjectFilter(r)
+	projects, statuses := s.filterProjects(s.store.List(), filter)
 	if wantsJSON(r) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
@@ -250,9 +380,12 @@ func (s *Server) handleProjectList(w http.ResponseWriter, r *http.Request) {
 	}
 	ws := s.workspace
 	data := PageData{
-		Title:     "Projects",
-		Projects:  projects,
-		Workspace: &ws,
+		Title:           "Projects",
+		Projects:        projects,
+		Workspace:       &ws,
+		Filter:          filter,
+		ProjectStatuses: statuses,
+		StatusOptions:   dashboardStatuses,
 	}
 	if err := s.templates.Render(w, "home.html", data); err != nil {
 		log.Printf("component=web.server action=render_failed view=home err=%v", err)
@@ -503,7 +636,7 @@ func (s *Server) handleProjectOverview(w http.ResponseWriter, r *http.Request) {
 	projectID := chi.URLParam(r, "projectID")
 	p, ok := s.store.Get(projectID)
 	if !ok {
-		http.Error(w, "project not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project not found")
 		return
 	}
 
@@ -536,7 +669,7 @@ func (s *Server) handleValidate(w http.ResponseWriter, r *http.Request) {
 	projectID := chi.URLParam(r, "projectID")
 	_, ok := s.store.Get(projectID)
 	if !ok {
-		http.Error(w, "project not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project not found")
 		return
 	}
 
@@ -555,7 +688,7 @@ func (s *Server) handleSpecContinueToEditor(w http.ResponseWriter, r *http.Reque
 	projectID := chi.URLParam(r, "projectID")
 	p, ok := s.store.Get(projectID)
 	if !ok {
-		http.Error(w, "project not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project not found")
 		return
 	}
 
@@ -584,7 +717,12 @@ func (s *Server) handleBuildStart(w http.ResponseWriter, r *http.Request) {
 	projectID := chi.URLParam(r, "projectID")
 	p, ok := s.store.Get(projectID)
 	if !ok {
-		http.Error(w, "project not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project not found")
+		return
+	}
+	// Refuse before the project is touched, so a stopping server does not
+	// persist a run that never starts.
+	if s.refuseWhileStopping(w, r) {
 		return
 	}
 
@@ -627,7 +765,10 @@ func (s *Server) handleBuildStart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.startBuildExecution(projectID, p, runID, false)
+	if err := s.startBuildExecution(tracing.RequestContext(r), projectID, p, runID, false); err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, ErrCodeUnavailable, err.Error())
+		return
+	}
 
 	http.Redirect(w, r, "/projects/"+projectID+"/build", http.StatusSeeOther)
 }
@@ -659,14 +800,19 @@ func (s *Server) maybeResumeBuild(projectID string, p *Project) {
 		return
 	}
 	log.Printf("component=web.build action=resume_pending project_id=%s run_id=%s", projectID, p.RunID)
-	s.startBuildExecution(projectID, p, p.RunID, true)
+	if err := s.startBuildExecution(context.Background(), projectID, p, p.RunID, true); err != nil {
+		log.Printf("component=web.build action=resume_pending_skipped project_id=%s run_id=%s err=%v", projectID, p.RunID, err)
+	}
 }
 
 // startBuildExecution creates in-memory run tracking and launches the tracker
 // pipeline engine. When resumeFromCheckpoint is true, checkpoint state is
 // loaded from the run's checkpoint directory automatically by the engine.
-func (s *Server) startBuildExecution(projectID string, p *Project, runID string, resumeFromCheckpoint bool) {
-	ctx, cancel := context.WithCancel(context.Background())
+// The run's span joins any trace in parent, which must outlive the request
+// that started the build (see tracing.RequestContext).
+// Returns errServerStopping once Stop has been called.
+func (s *Server) startBuildExecution(parent context.Context, projectID string, p *Project, runID string, resumeFromCheckpoint bool) error {
+	ctx, cancel := context.WithCancel(parent)
 	events := make(chan SSEEvent, 100)
 	now := time.Now()
 	state := &RunState{
@@ -677,16 +823,23 @@ func (s *Server) startBuildExecution(projectID string, p *Project, runID string,
 	}
 
 	run := &BuildRun{
-		State:  state,
-		Events: events,
-		Cancel: cancel,
-		Ctx:    ctx,
+		State:      state,
+		Events:     events,
+		Cancel:     cancel,
+		Ctx:        ctx,
+		MaxHistory: s.maxEventHistory,
 	}
-	run.EnsureFanoutStarted()
 
 	s.buildsMu.Lock()
+	if s.stopping {
+		s.buildsMu.Unlock()
+		cancel()
+		return errServerStopping
+	}
 	s.builds[projectID] = run
+	s.inflight.Add(1)
 	s.buildsMu.Unlock()
+	run.EnsureFanoutStarted()
 
 	artifactDir := s.workspace.ArtifactDir(projectID, runID)
 	checkpointDir := s.workspace.CheckpointDir(projectID, runID)
@@ -697,8 +850,21 @@ func (s *Server) startBuildExecution(projectID string, p *Project, runID string,
 		log.Printf("component=web.build action=create_checkpoint_dir_failed project_id=%s run_id=%s err=%v", projectID, runID, err)
 	}
 
-	// Create the broadcast function for events.
+	// Every event goes to the run's progress.ndjson, which keeps the full
+	// log while the SSE replay history is capped.
+	progress, err := openProgressLog(s.workspace.ProgressLogDir(projectID, runID))
+	if err != nil {
+		log.Printf("component=web.build action=open_progress_log_failed project_id=%s run_id=%s err=%v", projectID, runID, err)
+	}
+
+	// Create the broadcast function for events. Secrets are masked before
+	// the event is persisted or streamed.
 	broadcastEvent := func(be BuildEvent) {
+		be.Message = s.redactor.String(be.Message)
+		be.Data = s.redactor.Map(be.Data)
+		if err := progress.Append(be); err != nil {
+			log.Printf("component=web.build action=append_progress_log_failed project_id=%s run_id=%s err=%v", projectID, runID, err)
+		}
 		sseEvt := buildEventToSSE(be)
 		select {
 		case events <- sseEvt:
@@ -709,10 +875,22 @@ func (s *Server) startBuildExecution(projectID string, p *Project, runID string,
 
 	// Create the interviewer for human gates.
 	interviewer := newBuildInterviewer(ctx, broadcastEvent)
+	if s.auditDecisions {
+		interviewer.SetDecisionLog(NewDecisionLog(filepath.Join(checkpointDir, decisionLogFile)))
+	}
+	s.buildsMu.Lock()
+	run.Interviewer = interviewer
+	s.buildsMu.Unlock()
 
-	// Pipeline event handler bridges tracker events to SSE.
+	// Pipeline event handler bridges tracker events to SSE, node webhooks,
+	// and the run trace, none of which see an unmasked secret.
+	descriptions := nodeDescriptions(p.DOT)
+	attempts := &runstate.AttemptLog{}
+	nodeHooks := s.nodeWebhook.forRun(projectID, runID)
 	pipelineHandler := pipeline.PipelineEventHandlerFunc(func(evt pipeline.PipelineEvent) {
-		be := buildEventFromPipeline(evt)
+		be := withNodeDescription(buildEventFromPipeline(evt), descriptions)
+		attempts.Observe(evt)
+		nodeHooks.HandlePipelineEvent(evt)
 
 		s.buildsMu.Lock()
 		if evt.NodeID != "" {
@@ -721,6 +899,7 @@ func (s *Server) startBuildExecution(projectID string, p *Project, runID string,
 		if evt.Type == pipeline.EventStageCompleted {
 			state.CompletedNodes = append(state.CompletedNodes, evt.NodeID)
 		}
+		state.NodeAttempts = attempts.Records()
 		s.buildsMu.Unlock()
 
 		broadcastEvent(be)
@@ -729,12 +908,17 @@ func (s *Server) startBuildExecution(projectID string, p *Project, runID string,
 	// Agent event handler bridges tracker agent events to SSE.
 	agentHandler := agent.EventHandlerFunc(func(evt agent.Event) {
 		be := buildEventFromAgent(evt)
+		if be.Type == "" && s.reasoning != nil {
+			be = reasoningEventFromAgent(evt, s.reasoning)
+		}
 		if be.Type != "" {
 			broadcastEvent(be)
 		}
 	})
 	go func() {
+		defer s.inflight.Done()
 		defer close(events)
+		defer progress.Close()
 		defer func() {
 			if rec := recover(); rec != nil {
 				s.buildsMu.Lock()
@@ -750,6 +934,20 @@ func (s *Server) startBuildExecution(projectID string, p *Project, runID string,
 
 		// Parse the DOT source into a tracker pipeline graph.
 		graph, parseErr := pipeline.ParseDOT(p.DOT)
+		if parseErr == nil {
+			parseErr = shapemap.Apply(graph)
+		}
+		// A pinned provider is checked only when the run talks to a real backend.
+		if parseErr == nil && s.llmClient != nil {
+			parseErr = providerpin.Check(graph)
+		}
+		if parseErr == nil {
+			providerpin.Apply(graph)
+		}
+		var tokenAllocs map[string]int64
+		if parseErr == nil {
+			tokenAllocs, parseErr = tokenbudget.Allocations(graph)
+		}
 		if parseErr != nil {
 			s.buildsMu.Lock()
 			completedAt := time.Now()
@@ -761,44 +959,90 @@ func (s *Server) startBuildExecution(projectID string, p *Project, runID string,
 			return
 		}
 
+		// The run's spans follow the same stage events as the SSE stream.
+		runCtx, runTrace := s.tracer.StartRun(ctx, runID, graph.Name)
+		tracedHandler := redact.PipelineHandler(s.redactor, pipeline.PipelineEventHandlerFunc(func(evt pipeline.PipelineEvent) {
+			runTrace.HandlePipelineEvent(evt)
+			pipelineHandler(evt)
+		}))
+
 		// Build engine options.
 		checkpointPath := filepath.Join(checkpointDir, "checkpoint.json")
+		if resumeFromCheckpoint {
+			if err := runstate.RefreshCheckpointContext(checkpointPath, graph.Attrs); err != nil {
+				log.Printf("component=web.build action=refresh_checkpoint_context_failed project_id=%s run_id=%s err=%v", projectID, runID, err)
+			}
+		}
 		opts := []pipeline.EngineOption{
-			pipeline.WithPipelineEventHandler(pipelineHandler),
+			pipeline.WithPipelineEventHandler(tracedHandler),
 			pipeline.WithCheckpointPath(checkpointPath),
 			pipeline.WithArtifactDir(artifactDir),
 		}
+		if len(p.InitialContext) > 0 {
+			opts = append(opts, pipeline.WithInitialContext(p.InitialContext))
+		}
 
+		// Tool nodes only need a local shell, so the exec environment is
+		// registered even when no LLM backend is configured.
 		registryOpts := []handlers.RegistryOption{
 			handlers.WithInterviewer(interviewer, graph),
+			handlers.WithExecEnvironment(toolguard.Environment(exec.NewLocalEnvironment(artifactDir))),
 		}
 		if s.llmClient != nil {
-			registryOpts = append(registryOpts, handlers.WithLLMClient(s.llmClient, artifactDir))
-			registryOpts = append(registryOpts, handlers.WithExecEnvironment(exec.NewLocalEnvironment(artifactDir)))
-			registryOpts = append(registryOpts, handlers.WithAgentEventHandler(agentHandler))
+			agentEvents := redact.AgentHandler(s.redactor, agentHandler)
+			registryOpts = append(registryOpts, handlers.WithLLMClient(toolguard.Completer(tokenbudget.Completer(genparams.Completer(tracing.Completer(streaming.Completer(s.llmClient)))), agentEvents), artifactDir))
+			registryOpts = append(registryOpts, handlers.WithAgentEventHandler(agentEvents))
 		}
 		registry := handlers.NewDefaultRegistry(graph, registryOpts...)
+		genparams.Hook(registry)
+		streaming.Hook(registry)
+		retrybackoff.Hook("none", tracedHandler)(registry)
+		artifactcap.Hook(artifactDir, 0)(registry)
+		answerpattern.Hook(graph)(registry)
+		wrapHumanFollowUps(registry, interviewer)
+		tokenbudget.Hook(tokenAllocs)(registry)
+		successif.Hook(graph)(registry)
+		outputs := &runstate.OutputLog{}
+		outputs.Hook(registry)
+		nodelock.Hook(graph, s.nodeLocks)(registry)
+		toolguard.Hook(graph)(registry)
+		skipif.Hook(graph)(registry)
+		nextnode.Hook(graph)(registry)
+		runTrace.Hook(graph)(registry)
+		redact.Hook(graph, s.redactor)(registry)
 		engine := pipeline.NewEngine(graph, registry, opts...)
 
-		result, runErr := engine.Run(ctx)
-		_ = result // result available for future use
+		result, runErr := engine.Run(runCtx)
+		// clean_on_success="true" prunes the run's artifacts once it
+		// succeeds; failed runs keep them for debugging.
+		if runErr == nil && result != nil && result.Status == pipeline.OutcomeSuccess &&
+			strings.EqualFold(strings.TrimSpace(graph.Attrs["clean_on_success"]), "true") {
+			if err := runstate.PruneArtifacts(artifactDir); err != nil {
+				log.Printf("component=web.build action=prune_artifacts_failed project_id=%s run_id=%s err=%v", projectID, runID, err)
+			}
+		}
 
 		s.buildsMu.Lock()
 		completedAt := time.Now()
 		state.CompletedAt = &completedAt
+		_, state.SkippedNodes = skipif.Split(result)
 		if runErr != nil {
 			if ctx.Err() != nil {
 				state.Status = "cancelled"
 			} else {
 				state.Status = "failed"
-				state.Error = runErr.Error()
+				state.Error = s.redactor.String(runErr.Error())
 			}
 		} else {
 			state.Status = "completed"
+			resultNode, primary := outputs.Primary(graph, result)
+			state.ResultNode, state.PrimaryOutput = resultNode, s.redactor.String(primary)
 		}
 		s.buildsMu.Unlock()
+		runTrace.End(state.Status, runErr)
 		s.persistBuildOutcome(projectID, state)
 	}()
+	return nil
 }
 
 // handleBuildView renders the build progress page for a project.
@@ -806,7 +1050,7 @@ func (s *Server) handleBuildView(w http.ResponseWriter, r *http.Request) {
 	projectID := chi.URLParam(r, "projectID")
 	p, ok := s.store.Get(projectID)
 	if !ok {
-		http.Error(w, "project not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project not found")
 		return
 	}
 
@@ -832,7 +1076,7 @@ func (s *Server) handleFinalView(w http.ResponseWriter, r *http.Request) {
 	projectID := chi.URLParam(r, "projectID")
 	p, ok := s.store.Get(projectID)
 	if !ok {
-		http.Error(w, "project not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project not found")
 		return
 	}
 
@@ -869,7 +1113,7 @@ func (s *Server) handleFinalTimeline(w http.ResponseWriter, r *http.Request) {
 	projectID := chi.URLParam(r, "projectID")
 	p, ok := s.store.Get(projectID)
 	if !ok {
-		http.Error(w, "project not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project not found")
 		return
 	}
 	if p.RunID == "" {
@@ -1075,11 +1319,13 @@ func timelineOperationSummary(typ, nodeID string, data map[string]any) string {
 
 // handleBuildEvents streams server-sent events for an active build.
 // It sets the appropriate SSE headers and writes events as they arrive
-// from the build's event channel.
+// from the build's event channel. ?events= limits the stream, history
+// included, to the named events or families (see parseSSEEventFilter).
 func (s *Server) handleBuildEvents(w http.ResponseWriter, r *http.Request) {
 	projectID := chi.URLParam(r, "projectID")
+	filter := parseSSEEventFilter(r.URL.Query())
 	if _, ok := s.store.Get(projectID); !ok {
-		http.Error(w, "project not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project not found")
 		return
 	}
 
@@ -1095,31 +1341,34 @@ func (s *Server) handleBuildEvents(w http.ResponseWriter, r *http.Request) {
 	history, eventsCh, unsubscribe := run.SubscribeWithHistory()
 	defer unsubscribe()
 
-	// Set SSE headers.
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
-	w.WriteHeader(http.StatusOK)
-
-	flusher, canFlush := w.(http.Flusher)
+	stream := newSSEWriter(w)
 	for _, evt := range history {
-		fmt.Fprint(w, evt.Format())
+		if !filter.matches(evt.Event) {
+			continue
+		}
+		if _, err := io.WriteString(w, evt.Format()); err != nil {
+			return
+		}
 	}
-	if canFlush {
-		flusher.Flush()
+	if err := stream.Flush(); err != nil {
+		return
 	}
 
-	// Stream events until the channel is closed or the client disconnects.
+	// Stream events until the channel is closed (build done, or this client
+	// fell too far behind and was dropped) or the client disconnects. A
+	// failed write means the client is gone or stalled past the deadline.
 	for {
 		select {
 		case evt, ok := <-eventsCh:
 			if !ok {
-				// Channel closed; build is done.
 				return
 			}
-			fmt.Fprint(w, evt.Format())
-			if canFlush {
-				flusher.Flush()
+			if !filter.matches(evt.Event) {
+				continue
+			}
+			if err := stream.Send(evt); err != nil {
+				log.Printf("component=web.build action=sse_write_failed project_id=%s err=%v", projectID, err)
+				return
 			}
 		case <-r.Context().Done():
 			// Client disconnected.
@@ -1132,29 +1381,31 @@ func (s *Server) handleBuildState(w http.ResponseWriter, r *http.Request) {
 	projectID := chi.URLParam(r, "projectID")
 	p, ok := s.store.Get(projectID)
 	if !ok {
-		http.Error(w, "project not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project not found")
 		return
 	}
 	s.maybeResumeBuild(projectID, p)
 
 	type buildStateResponse struct {
-		ProjectID   string     `json:"project_id"`
-		RunID       string     `json:"run_id,omitempty"`
-		Phase       string     `json:"phase"`
-		Active      bool       `json:"active"`
-		Status      string     `json:"status"`
-		Diagnostics []string   `json:"diagnostics,omitempty"`
-		RunState    *RunState  `json:"run_state,omitempty"`
-		Recent      []SSEEvent `json:"recent_events,omitempty"`
+		ProjectID        string            `json:"project_id"`
+		RunID            string            `json:"run_id,omitempty"`
+		Phase            string            `json:"phase"`
+		Active           bool              `json:"active"`
+		Status           string            `json:"status"`
+		Diagnostics      []string          `json:"diagnostics,omitempty"`
+		RunState         *RunState         `json:"run_state,omitempty"`
+		Recent           []SSEEvent        `json:"recent_events,omitempty"`
+		NodeDescriptions map[string]string `json:"node_descriptions,omitempty"`
 	}
 
 	resp := buildStateResponse{
-		ProjectID:   projectID,
-		RunID:       p.RunID,
-		Phase:       string(p.Phase),
-		Active:      false,
-		Status:      "idle",
-		Diagnostics: p.Diagnostics,
+		ProjectID:        projectID,
+		RunID:            p.RunID,
+		Phase:            string(p.Phase),
+		Active:           false,
+		Status:           "idle",
+		Diagnostics:      p.Diagnostics,
+		NodeDescriptions: nodeDescriptions(p.DOT),
 	}
 
 	s.buildsMu.RLock()
@@ -1195,7 +1446,7 @@ func (s *Server) handleBuildStop(w http.ResponseWriter, r *http.Request) {
 	projectID := chi.URLParam(r, "projectID")
 	_, ok := s.store.Get(projectID)
 	if !ok {
-		http.Error(w, "project not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project not found")
 		return
 	}
 
@@ -1251,7 +1502,7 @@ func (s *Server) handleArtifactList(w http.ResponseWriter, r *http.Request) {
 	projectID := chi.URLParam(r, "projectID")
 	p, ok := s.store.Get(projectID)
 	if !ok {
-		http.Error(w, "project not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project not found")
 		return
 	}
 	if p.RunID == "" {
@@ -1359,7 +1610,7 @@ func (s *Server) handleArtifactFile(w http.ResponseWriter, r *http.Request) {
 	projectID := chi.URLParam(r, "projectID")
 	p, ok := s.store.Get(projectID)
 	if !ok {
-		http.Error(w, "project not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project not found")
 		return
 	}
 	if p.RunID == "" {
@@ -0,0 +1,103 @@
+// ABOUTME: Graceful shutdown for the web server: refuse new builds, drain in-flight ones, release resources.
+// ABOUTME: Builds still running when the deadline passes are cancelled so their checkpoints can resume them later.
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// errServerStopping is returned when a build is started after Stop.
+var errServerStopping = errors.New("server is shutting down: no new builds are accepted")
+
+// stopCancelWait bounds how long Stop waits, after cancelling the builds
+// still running at its deadline, for them to write their checkpoints.
+const stopCancelWait = 10 * time.Second
+
+// Stop shuts the server's pipeline execution down. New builds are refused
+// from the moment it is called; in-flight builds are given until ctx is done
+// to finish. Builds still running then are cancelled and given up to
+// stopCancelWait to record their checkpoints, and Stop returns an error
+// wrapping ctx.Err(). Either way, the editor cleanup loop, the spec
+// agents, and the backend LLM client are released, and queued node webhook
+// deliveries get until ctx is done to go out. Stop is safe to call more
+// than once; later calls only wait for the drain.
+func (s *Server) Stop(ctx context.Context) error {
+	s.buildsMu.Lock()
+	first := !s.stopping
+	s.stopping = true
+	s.buildsMu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		s.inflight.Wait()
+		close(drained)
+	}()
+
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		n := s.cancelRunningBuilds()
+		log.Printf("component=web.server action=stop_deadline cancelled_builds=%d", n)
+		err = fmt.Errorf("stop: %d build(s) still running at the deadline were cancelled: %w", n, ctx.Err())
+		select {
+		case <-drained:
+		case <-time.After(stopCancelWait):
+			log.Printf("component=web.server action=stop_cancel_wait_expired wait=%s", stopCancelWait)
+		}
+	}
+
+	if first {
+		if s.stopEditorCleanup != nil {
+			s.stopEditorCleanup()
+		}
+		s.specState.StopAllSwarms()
+		s.specState.StopAllEventPersisters()
+		if hookErr := s.nodeWebhook.Close(ctx); hookErr != nil {
+			log.Printf("component=web.server action=stop_node_webhook err=%v", hookErr)
+		}
+		if s.llmCloser != nil {
+			if closeErr := s.llmCloser.Close(); closeErr != nil {
+				err = errors.Join(err, fmt.Errorf("close LLM client: %w", closeErr))
+			}
+		}
+	}
+	return err
+}
+
+// isStopping reports whether Stop has been called.
+func (s *Server) isStopping() bool {
+	s.buildsMu.RLock()
+	defer s.buildsMu.RUnlock()
+	return s.stopping
+}
+
+// refuseWhileStopping responds 503 and returns true once Stop has been
+// called, for handlers to check before they change any project state.
+func (s *Server) refuseWhileStopping(w http.ResponseWriter, r *http.Request) bool {
+	if !s.isStopping() {
+		return false
+	}
+	writeError(w, r, http.StatusServiceUnavailable, ErrCodeUnavailable, errServerStopping.Error())
+	return true
+}
+
+// cancelRunningBuilds cancels every build still running and returns how many
+// there were.
+func (s *Server) cancelRunningBuilds() int {
+	s.buildsMu.RLock()
+	defer s.buildsMu.RUnlock()
+	n := 0
+	for _, run := range s.builds {
+		if run != nil && run.State != nil && run.State.Status == "running" && run.Cancel != nil {
+			run.Cancel()
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,149 @@
+// ABOUTME: Tests for Server.Stop: new builds are refused and in-flight builds drain within the deadline.
+// ABOUTME: A build parked at a human gate stands in for long-running work.
+package web
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+const gatedBuildDOT = `digraph p {
+	start [shape=Mdiamond]
+	approve [shape=hexagon, label="Ship it?"]
+	done [shape=Msquare]
+	start -> approve
+	approve -> done [label="ship"]
+	approve -> done [label="hold"]
+}`
+
+// startGatedBuild starts a build that waits at a human gate and returns the
+// project ID and the build's interviewer once the gate is pending.
+func startGatedBuild(t *testing.T, srv *Server) (string, *ChannelInterviewer) {
+	t.Helper()
+	p, err := srv.store.Create("stop-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.DOT = gatedBuildDOT
+	p.Phase = PhaseBuild
+	p.RunID = "gated-run"
+	if err := srv.store.Update(p); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.startBuildExecution(context.Background(), p.ID, p, "gated-run", false); err != nil {
+		t.Fatalf("start build: %v", err)
+	}
+	iv := srv.buildInterviewer(p.ID)
+	waitForPending(t, iv)
+	return p.ID, iv
+}
+
+func TestStopDrainsInFlightBuildAndRefusesNewOnes(t *testing.T) {
+	srv := newTestServer(t)
+	projectID, iv := startGatedBuild(t, srv)
+
+	stopped := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		stopped <- srv.Stop(ctx)
+	}()
+
+	// Once Stop is under way new builds are refused, while the gated one
+	// keeps running.
+	deadline := time.Now().Add(2 * time.Second)
+	for !srv.isStopping() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for Stop to begin")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	p, _ := srv.store.Get(projectID)
+	if err := srv.startBuildExecution(context.Background(), "other-project", p, "new-run", false); !errors.Is(err, errServerStopping) {
+		t.Fatalf("start after Stop: err = %v, want errServerStopping", err)
+	}
+	select {
+	case err := <-stopped:
+		t.Fatalf("Stop returned %v before the in-flight build finished", err)
+	default:
+	}
+
+	gate := waitForPending(t, iv)
+	if err := iv.Respond(gate.ID, "ship"); err != nil {
+		t.Fatalf("respond: %v", err)
+	}
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Fatalf("Stop = %v, want nil after the build drained", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop did not return after the build finished")
+	}
+	if state := waitForBuildStatus(t, srv, projectID); state.Status != "completed" {
+		t.Errorf("drained build status = %q, want completed", state.Status)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/projects/"+projectID+"/build/retry", nil)
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("retry after Stop: status = %d, want 503", rec.Code)
+	}
+}
+
+func TestStopCancelsBuildsAtDeadline(t *testing.T) {
+	srv := newTestServer(t)
+	projectID, _ := startGatedBuild(t, srv)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := srv.Stop(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Stop = %v, want a deadline error", err)
+	}
+	// Stop waits for the cancelled build to wind down, so its final state
+	// and checkpoint are written by the time it returns.
+	srv.buildsMu.RLock()
+	status := srv.builds[projectID].State.Status
+	srv.buildsMu.RUnlock()
+	if status != "cancelled" {
+		t.Errorf("build status = %q, want cancelled once Stop returns", status)
+	}
+	if _, err := os.Stat(srv.checkpointPath(projectID, "gated-run")); err != nil {
+		t.Errorf("checkpoint: %v, want it written once Stop returns", err)
+	}
+}
+
+func TestBuildStartAfterStopLeavesProjectUntouched(t *testing.T) {
+	srv := newTestServer(t)
+	p, err := srv.store.Create("stopped")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.DOT = gatedBuildDOT
+	if err := srv.store.Update(p); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/projects/"+p.ID+"/build/start", nil)
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("build start after Stop: status = %d, want 503", rec.Code)
+	}
+	got, _ := srv.store.Get(p.ID)
+	if got.RunID != "" || got.Phase != p.Phase {
+		t.Errorf("project run ID, phase = %q, %q; want it left as %q, %q", got.RunID, got.Phase, "", p.Phase)
+	}
+}
@@ -908,6 +908,26 @@ func newTestServer(t *testing.T) *Server {
 	t.Cleanup(func() {
 		srv.specState.StopAllEventPersisters()
 		srv.specState.StopAllSwarms()
+		// Builds a test started may still be writing to the workspace;
+		// let them finish before TempDir removes it.
+		waitInflight(t, srv, 5*time.Second)
 	})
 	return srv
 }
+
+// waitInflight waits up to d for srv's builds to finish, cancelling them
+// first so none is left waiting at a gate.
+func waitInflight(t *testing.T, srv *Server, d time.Duration) {
+	t.Helper()
+	srv.cancelRunningBuilds()
+	done := make(chan struct{})
+	go func() {
+		srv.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(d):
+		t.Errorf("builds still running %s after the test", d)
+	}
+}
@@ -91,12 +91,12 @@ func (s *Server) specRouter(r chi.Router) {
 func (s *Server) handleSpecOptionsGet(w http.ResponseWriter, r *http.Request) {
 	projectID, _ := r.Context().Value(ctxKeyProjectID).(string)
 	if projectID == "" {
-		http.Error(w, "project not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project not found")
 		return
 	}
 	opts, err := s.readProjectSpecOptions(projectID)
 	if err != nil {
-		writeSpecJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		writeJSONError(w, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 	writeSpecJSON(w, http.StatusOK, map[string]any{
@@ -109,7 +109,7 @@ func (s *Server) handleSpecOptionsGet(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleSpecOptionsUpdate(w http.ResponseWriter, r *http.Request) {
 	projectID, _ := r.Context().Value(ctxKeyProjectID).(string)
 	if projectID == "" {
-		http.Error(w, "project not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project not found")
 		return
 	}
 	if err := r.ParseForm(); err != nil {
@@ -118,7 +118,7 @@ func (s *Server) handleSpecOptionsUpdate(w http.ResponseWriter, r *http.Request)
 	}
 	opts := parseSpecBuilderOptions(r.FormValue)
 	if err := s.applySpecBuilderOptions(projectID, opts); err != nil {
-		writeSpecJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		writeJSONError(w, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 	writeSpecJSON(w, http.StatusOK, map[string]any{
@@ -218,7 +218,7 @@ func (s *Server) specContextMiddleware(next http.Handler) http.Handler {
 		projectID := chi.URLParam(r, "projectID")
 		p, ok := s.store.Get(projectID)
 		if !ok {
-			http.Error(w, "project not found", http.StatusNotFound)
+			writeError(w, r, http.StatusNotFound, ErrCodeNotFound, "project not found")
 			return
 		}
 
@@ -716,31 +716,31 @@ func (s *Server) handleSpecCommands(w http.ResponseWriter, r *http.Request) {
 	specIDStr := chi.URLParam(r, "id")
 	specID, err := ulid.Parse(specIDStr)
 	if err != nil {
-		writeSpecJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid spec ID"})
+		writeJSONError(w, http.StatusBadRequest, ErrCodeBadRequest, "invalid spec ID")
 		return
 	}
 
 	handle := s.specState.GetActor(specID)
 	if handle == nil {
-		writeSpecJSON(w, http.StatusNotFound, map[string]string{"error": "spec not found"})
+		writeJSONError(w, http.StatusNotFound, ErrCodeNotFound, "spec not found")
 		return
 	}
 
 	var rawJSON json.RawMessage
 	if err := json.NewDecoder(r.Body).Decode(&rawJSON); err != nil {
-		writeSpecJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		writeJSONError(w, http.StatusBadRequest, ErrCodeParse, "invalid request body")
 		return
 	}
 
 	cmd, err := core.UnmarshalCommand(rawJSON)
 	if err != nil {
-		writeSpecJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid command: %v", err)})
+		writeJSONError(w, http.StatusBadRequest, ErrCodeParse, fmt.Sprintf("invalid command: %v", err))
 		return
 	}
 
 	events, err := handle.SendCommand(cmd)
 	if err != nil {
-		writeSpecJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		writeJSONError(w, http.StatusBadRequest, ErrCodeValidation, err.Error())
 		return
 	}
 
@@ -0,0 +1,51 @@
+// ABOUTME: SSE response writer that flushes after every event and bounds how long a write may block.
+// ABOUTME: A client that stops reading fails the write instead of pinning the handler goroutine.
+package web
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+// sseWriteTimeout bounds how long a single event write may block on a client
+// that has stopped reading.
+const sseWriteTimeout = 10 * time.Second
+
+// sseWriter writes server-sent events, flushing each one to the client.
+type sseWriter struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+// newSSEWriter sets the SSE headers, writes the status line, and returns a
+// writer for the stream.
+func newSSEWriter(w http.ResponseWriter) *sseWriter {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	return &sseWriter{w: w, rc: http.NewResponseController(w)}
+}
+
+// Send writes evt and flushes it. Each call extends the write deadline by
+// sseWriteTimeout where the underlying connection supports deadlines.
+func (s *sseWriter) Send(evt SSEEvent) error {
+	if err := s.rc.SetWriteDeadline(time.Now().Add(sseWriteTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	if _, err := io.WriteString(s.w, evt.Format()); err != nil {
+		return err
+	}
+	return s.Flush()
+}
+
+// Flush sends buffered data to the client. Writers that cannot flush are
+// tolerated; their data goes out when the handler returns.
+func (s *sseWriter) Flush() error {
+	if err := s.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+// ABOUTME: Tests for SSE backpressure: slow subscribers are dropped instead of stalling the build.
+// ABOUTME: Covers the fanout in isolation and a live server with one fast and one stalled client.
+package web
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sendWithin pushes evt into events, failing the test if the send blocks.
+func sendWithin(t *testing.T, events chan<- SSEEvent, evt SSEEvent) {
+	t.Helper()
+	select {
+	case events <- evt:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("sending %s blocked: the pipeline would stall", evt.Event)
+	}
+}
+
+func TestFanoutDropsSlowSubscriber(t *testing.T) {
+	run := &BuildRun{State: &RunState{ID: "fanout-run"}, Events: make(chan SSEEvent)}
+	run.EnsureFanoutStarted()
+
+	slow, unsubscribeSlow := run.Subscribe()
+	defer unsubscribeSlow()
+	fast, unsubscribeFast := run.Subscribe()
+	defer unsubscribeFast()
+
+	total := subscriberBuffer + 10
+	for i := range total {
+		sendWithin(t, run.Events, SSEEvent{Event: "stage.started", Data: fmt.Sprintf(`{"i":%d}`, i)})
+		select {
+		case <-fast:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("fast subscriber missed event %d", i)
+		}
+	}
+
+	received := 0
+	for range slow {
+		received++
+	}
+	if received != subscriberBuffer {
+		t.Errorf("slow subscriber got %d events before being dropped, want %d", received, subscriberBuffer)
+	}
+	close(run.Events)
+}
+
+func TestBuildEventsSlowClientDoesNotBlockOthers(t *testing.T) {
+	srv := newTestServer(t)
+	p, err := srv.store.Create("slow-sse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan SSEEvent)
+	run := &BuildRun{State: &RunState{ID: "slow-run", Status: "running"}, Events: events}
+	srv.buildsMu.Lock()
+	srv.builds[p.ID] = run
+	srv.buildsMu.Unlock()
+
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	connect := func() *http.Response {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/projects/"+p.ID+"/build/events", nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("connect: %v", err)
+		}
+		return resp
+	}
+
+	// The stalled client never reads its body.
+	stalled := connect()
+	defer stalled.Body.Close()
+	fast := connect()
+	defer fast.Body.Close()
+
+	received := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(fast.Body)
+		scanner.Buffer(make([]byte, 64*1024), 64*1024)
+		for scanner.Scan() {
+			if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+				received <- data
+			}
+		}
+		close(received)
+	}()
+
+	// Both handlers must be subscribed before events flow.
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		run.mu.Lock()
+		n := len(run.subscribers)
+		run.mu.Unlock()
+		if n == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("subscribers = %d, want 2", n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Large payloads fill the stalled connection's socket buffers so its
+	// handler blocks on write and its subscription falls behind.
+	padding := strings.Repeat("x", 16*1024)
+	total := 4 * subscriberBuffer
+	for i := range total {
+		data := fmt.Sprintf(`{"i":%d,"pad":%q}`, i, padding)
+		sendWithin(t, events, SSEEvent{Event: "agent.text", Data: data})
+		select {
+		case got := <-received:
+			if got != data {
+				t.Fatalf("fast client event %d out of order", i)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("fast client stalled at event %d", i)
+		}
+	}
+
+	run.mu.Lock()
+	remaining := len(run.subscribers)
+	run.mu.Unlock()
+	if remaining != 1 {
+		t.Errorf("subscribers after stall = %d, want only the fast client", remaining)
+	}
+
+	close(events)
+	if _, ok := <-received; ok {
+		t.Error("fast client stream should end when the build's events close")
+	}
+	_, _ = io.Copy(io.Discard, stalled.Body)
+}
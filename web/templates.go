@@ -23,6 +23,12 @@ type PageData struct {
 	ActivePhase string // current wizard phase for highlighting
 	Diagnostics DiagnosticsView
 	Workspace   *Workspace // workspace info for display on project list
+
+	// Project list filtering: the active filter, each listed project's
+	// status keyed by ID, and the statuses offered in the filter control.
+	Filter          ProjectFilter
+	ProjectStatuses map[string]string
+	StatusOptions   []string
 }
 
 // TemplateEngine loads and renders embedded HTML templates.
@@ -71,7 +77,9 @@ func NewTemplateEngine() (*TemplateEngine, error) {
 
 	// Standalone templates are rendered without the layout wrapper.
 	// Used for pages that need full control of their HTML.
-	standalonePages := []string{}
+	standalonePages := []string{
+		"build_questions.html",
+	}
 
 	for _, page := range standalonePages {
 		t, err := template.New(page).Funcs(funcs).ParseFS(
@@ -14,23 +14,22 @@ import (
 // If the DOT has parse or lint errors, it stays in the edit phase and returns an error.
 // If the DOT is clean, it transitions to the build phase.
 func TransitionEditorToBuild(project *Project) error {
-	g, err := dot.Parse(project.DOT)
+	_, diags, err := validator.ParseAndValidate(project.DOT)
 	if err != nil {
 		project.Diagnostics = []string{
 			"error: [build_blocked] build did not start because DOT parsing failed",
 			fmt.Sprintf("error: [parse] %s", err),
 		}
 		project.Phase = PhaseEdit
-		return fmt.Errorf("editor to build: DOT parse failed: %w", err)
+		return fmt.Errorf("editor to build: %w: %w", errDOTParse, err)
 	}
 
-	diags := validator.Lint(g)
 	project.Diagnostics = formatDiagnostics(diags)
 
 	if hasErrors(diags) {
 		project.Diagnostics = prependBuildBlockedSummary(project.Diagnostics, countSeverity(diags, "error"), countSeverity(diags, "warning"))
 		project.Phase = PhaseEdit
-		return fmt.Errorf("editor to build: DOT has validation errors")
+		return fmt.Errorf("editor to build: %w", errDOTValidation)
 	}
 
 	project.Phase = PhaseBuild
@@ -74,3 +74,9 @@ func (w Workspace) CheckpointDir(projectID, runID string) string {
 func (w Workspace) ProgressLogDir(projectID, runID string) string {
 	return filepath.Join(w.StateDir, projectID, "artifacts", runID)
 }
+
+// TemplatesDir returns where the shared pipeline template library lives.
+// Always under the state directory regardless of mode.
+func (w Workspace) TemplatesDir() string {
+	return filepath.Join(w.StateDir, "templates")
+}
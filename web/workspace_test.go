@@ -1,5 +1,5 @@
 // ABOUTME: Tests for the Workspace type that resolves paths for local vs global mode.
-// ABOUTME: Verifies path construction for project store, artifacts, checkpoints, and progress logs.
+// ABOUTME: Verifies path construction for project store, artifacts, checkpoints, progress logs, and templates.
 
 package web
 
@@ -79,3 +79,12 @@ func TestProgressLogDir(t *testing.T) {
 		t.Fatalf("expected %s, got %s", expected, got)
 	}
 }
+
+func TestTemplatesDir(t *testing.T) {
+	ws := NewLocalWorkspace("/home/user/projects/app")
+	got := ws.TemplatesDir()
+	expected := filepath.Join("/home/user/projects/app", ".mammoth", "templates")
+	if got != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+}